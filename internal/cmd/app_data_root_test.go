@@ -180,9 +180,11 @@ func TestAppDataRootPlacementSurfacesUseSingleRoot(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, filepath.Join(root, "jobs", "index-build"), jobsRoot)
 
-	opts, err := serveServerOptions(context.Background(), "127.0.0.1")
+	opts, err := serveServerOptions(context.Background(), "127.0.0.1", serveListener{})
 	require.NoError(t, err)
 	require.Equal(t, jobsRoot, opts.JobsRoot)
+	require.NotNil(t, opts.Indexes)
+	require.Equal(t, indexRoot, opts.Indexes.IndexesRoot)
 
 	store, err := openDefaultOperationCheckpointStore(context.Background())
 	require.NoError(t, err)
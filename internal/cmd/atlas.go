@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"github.com/fulmenhq/gofulmen/foundry"
 	"github.com/spf13/cobra"
 
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
 	"github.com/3leaps/gonimbus/pkg/atlas"
 	"github.com/3leaps/gonimbus/pkg/indexreader"
@@ -47,6 +49,11 @@ var atlasBuildCmd = &cobra.Command{
 	Short: "Build a local atlas artifact from an index run",
 	Long: `Build a local atlas artifact from one completed index run.
 
+On a durable-v2 index, --run may name any retained published run: durable runs
+are immutable snapshots, so the atlas sees the objects exactly as that run
+recorded them. SQLite indexes keep only current objects, so there --run must be
+the latest run.
+
 The builder reads object bytes from the source provider, computes SHA256
 content hashes, extracts configured typed dimensions, and writes per-shard
 JSONL plus an atlas header. Hub export, lower-trust export transforms, drift,
@@ -96,67 +103,40 @@ func runAtlasBuild(cmd *cobra.Command, args []string) (err error) {
 	gcpProject, _ := cmd.Flags().GetString("gcp-project")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 
-	reader, err := openIndexReader(ctx, "", strings.TrimSpace(indexID), "")
-	if err != nil {
-		return err
-	}
-	defer func() { err = errors.Join(err, reader.Close()) }()
-	if reader.Meta().Format != indexreader.FormatSQLiteV1 || reader.SQLiteDB() == nil {
-		return fmt.Errorf("atlas build requires a sqlite-v1 index")
-	}
-	db := reader.SQLiteDB()
-	sets, err := indexstore.ListIndexSets(ctx, db, "")
+	recipe, err := atlas.LoadRecipeFile(recipePath)
 	if err != nil {
-		return fmt.Errorf("list index sets: %w", err)
-	}
-	var indexSet *indexstore.IndexSet
-	for i := range sets {
-		if sets[i].IndexSetID == reader.Meta().IndexSetID {
-			indexSet = &sets[i]
-			break
-		}
-	}
-	if indexSet == nil {
-		return fmt.Errorf("resolved SQLite index set is missing")
+		return exitError(foundry.ExitInvalidArgument, "Invalid atlas recipe", err)
 	}
 
-	run, err := indexstore.GetIndexRun(ctx, db, strings.TrimSpace(runID))
+	reader, err := openIndexReader(ctx, "", strings.TrimSpace(indexID), "")
 	if err != nil {
 		return err
 	}
-	if run.IndexSetID != indexSet.IndexSetID {
-		return fmt.Errorf("run %s belongs to %s, not %s", run.RunID, run.IndexSetID, indexSet.IndexSetID)
-	}
-	if run.Status != indexstore.RunStatusSuccess && run.Status != indexstore.RunStatusPartial {
-		return fmt.Errorf("atlas build requires a completed successful or partial run, got %s", run.Status)
-	}
-	runs, err := indexstore.ListIndexRuns(ctx, db, indexSet.IndexSetID)
-	if err != nil {
-		return fmt.Errorf("list index runs: %w", err)
-	}
-	if len(runs) == 0 || runs[0].RunID != run.RunID {
-		return fmt.Errorf("atlas Phase A requires the selected run to be the latest local index run; local indexes do not retain historical object snapshots")
-	}
-
-	recipe, err := atlas.LoadRecipeFile(recipePath)
-	if err != nil {
-		return exitError(foundry.ExitInvalidArgument, "Invalid atlas recipe", err)
+	defer func() { err = errors.Join(err, reader.Close()) }()
+	runID = strings.TrimSpace(runID)
+	var (
+		indexSet      *indexstore.IndexSet
+		sourceObjects []atlas.SourceObject
+		scopeDigest   string
+	)
+	switch meta := reader.Meta(); meta.Format {
+	case indexreader.FormatDurableV2:
+		indexSet, sourceObjects, err = atlasDurableSource(meta, runID)
+		if meta.IdentityDir != "" {
+			scopeDigest = readIdentityScopeDigest(filepath.Join(meta.IdentityDir, "identity.json"))
+		}
+	case indexreader.FormatSQLiteV1:
+		if reader.SQLiteDB() == nil {
+			return fmt.Errorf("atlas build requires a sqlite-v1 or durable-v2 index")
+		}
+		indexSet, sourceObjects, err = atlasSQLiteSource(ctx, reader.SQLiteDB(), meta.IndexSetID, runID)
+		scopeDigest = readIndexScopeDigest(strings.TrimSpace(indexID))
+	default:
+		return fmt.Errorf("atlas build requires a sqlite-v1 or durable-v2 index")
 	}
-
-	objects, err := indexstore.ListObjectsForRun(ctx, db, indexSet.IndexSetID, run.RunID)
 	if err != nil {
 		return err
 	}
-	sourceObjects := make([]atlas.SourceObject, 0, len(objects))
-	for _, obj := range objects {
-		sourceObjects = append(sourceObjects, atlas.SourceObject{
-			RelKey:        obj.RelKey,
-			SizeBytes:     obj.SizeBytes,
-			ETag:          obj.ETag,
-			LastSeenRunID: obj.LastSeenRunID,
-			LastSeenAt:    obj.LastSeenAt,
-		})
-	}
 
 	parsed, err := uri.ParseURI(indexSet.BaseURI)
 	if err != nil {
@@ -185,11 +165,10 @@ func runAtlasBuild(cmd *cobra.Command, args []string) (err error) {
 	}
 	defer func() { _ = prov.Close() }()
 
-	scopeDigest := readIndexScopeDigest(strings.TrimSpace(indexID))
 	result, err := atlas.Build(ctx, atlas.BuildOptions{
 		Source: atlas.SourceRun{
 			IndexSetID:  indexSet.IndexSetID,
-			RunID:       run.RunID,
+			RunID:       runID,
 			BaseURI:     indexSet.BaseURI,
 			ScopeDigest: scopeDigest,
 			Coverage:    recipe.Coverage,
@@ -239,6 +218,107 @@ func runAtlasStats(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// atlasSQLiteSource lists the objects of runID from a SQLite index. SQLite
+// keeps only current objects, so runID must be the latest run.
+func atlasSQLiteSource(ctx context.Context, db *sql.DB, indexSetID, runID string) (*indexstore.IndexSet, []atlas.SourceObject, error) {
+	sets, err := indexstore.ListIndexSets(ctx, db, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("list index sets: %w", err)
+	}
+	var indexSet *indexstore.IndexSet
+	for i := range sets {
+		if sets[i].IndexSetID == indexSetID {
+			indexSet = &sets[i]
+			break
+		}
+	}
+	if indexSet == nil {
+		return nil, nil, fmt.Errorf("resolved SQLite index set is missing")
+	}
+
+	run, err := indexstore.GetIndexRun(ctx, db, runID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run.IndexSetID != indexSet.IndexSetID {
+		return nil, nil, fmt.Errorf("run %s belongs to %s, not %s", run.RunID, run.IndexSetID, indexSet.IndexSetID)
+	}
+	if run.Status != indexstore.RunStatusSuccess && run.Status != indexstore.RunStatusPartial {
+		return nil, nil, fmt.Errorf("atlas build requires a completed successful or partial run, got %s", run.Status)
+	}
+	runs, err := indexstore.ListIndexRuns(ctx, db, indexSet.IndexSetID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list index runs: %w", err)
+	}
+	if len(runs) == 0 || runs[0].RunID != run.RunID {
+		return nil, nil, fmt.Errorf("atlas build on a sqlite-v1 index requires the latest run; SQLite keeps only current objects, so build against a durable-v2 index to use an earlier retained run")
+	}
+
+	objects, err := indexstore.ListObjectsForRun(ctx, db, indexSet.IndexSetID, run.RunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceObjects := make([]atlas.SourceObject, 0, len(objects))
+	for _, obj := range objects {
+		sourceObjects = append(sourceObjects, atlas.SourceObject{
+			RelKey:        obj.RelKey,
+			SizeBytes:     obj.SizeBytes,
+			ETag:          obj.ETag,
+			LastSeenRunID: obj.LastSeenRunID,
+			LastSeenAt:    obj.LastSeenAt,
+		})
+	}
+	return indexSet, sourceObjects, nil
+}
+
+// atlasDurableSource lists the live objects of any retained durable run. The
+// run's snapshot is opened by its complete marker, so it is read exactly as
+// published whatever latest.json now names.
+func atlasDurableSource(meta indexreader.Meta, runID string) (*indexstore.IndexSet, []atlas.SourceObject, error) {
+	runs, err := listRetainedDurableRuns(meta)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := findRetainedDurableRun(runs, runID); err != nil {
+		return nil, nil, err
+	}
+	opts, err := indexReaderResolveOptions()
+	if err != nil {
+		return nil, nil, err
+	}
+	segmentRoot, err := indexSubstrateSegmentCacheDir(meta.IndexSetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	completePath := filepath.Join(segmentRoot, "runs", runID, "complete.json")
+	snap, err := indexsubstrate.OpenPublishedRunSnapshotBounded(completePath, meta.IndexSetID, runID, opts.MaxMarkerBytes, opts.MaxManifestBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open durable run %s: %w", runID, err)
+	}
+	var sourceObjects []atlas.SourceObject
+	err = indexsubstrate.WalkManifestRows(snap.SegmentDir, snap.Manifest, func(row indexsubstrate.CurrentObjectRow) error {
+		if row.DeletedAt != nil {
+			return nil
+		}
+		sourceObjects = append(sourceObjects, atlas.SourceObject{
+			RelKey:        row.RelKey,
+			SizeBytes:     row.SizeBytes,
+			ETag:          row.ETag,
+			LastSeenRunID: row.LastSeenRunID,
+			LastSeenAt:    row.LastSeenAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("read durable run %s: %w", runID, err)
+	}
+	indexSet := durableIdentityIndexSet(meta)
+	if strings.TrimSpace(indexSet.BaseURI) == "" {
+		return nil, nil, fmt.Errorf("atlas build: base_uri is required for index set %s", meta.IndexSetID)
+	}
+	return indexSet, sourceObjects, nil
+}
+
 func readIndexScopeDigest(indexID string) string {
 	rootDir, err := indexRootDir()
 	if err != nil {
@@ -248,7 +328,11 @@ func readIndexScopeDigest(indexID string) string {
 	if err != nil {
 		return ""
 	}
-	data, err := os.ReadFile(filepath.Join(filepath.Dir(match.DBPath), "identity.json"))
+	return readIdentityScopeDigest(filepath.Join(filepath.Dir(match.DBPath), "identity.json"))
+}
+
+func readIdentityScopeDigest(path string) string {
+	data, err := os.ReadFile(path) // #nosec G304 -- identity.json under the local index root.
 	if err != nil {
 		return ""
 	}
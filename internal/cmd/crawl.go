@@ -16,6 +16,7 @@ import (
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
+	"github.com/3leaps/gonimbus/internal/metrics"
 	"github.com/3leaps/gonimbus/internal/observability"
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
 	"github.com/3leaps/gonimbus/pkg/crawler"
@@ -24,6 +25,7 @@ import (
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/preflight"
 	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/shard"
 	"github.com/3leaps/gonimbus/pkg/uri"
 )
 
@@ -203,6 +205,9 @@ func showCrawlPlan(m *manifest.Manifest) error {
 	if m.Crawl.RateLimit > 0 {
 		fmt.Printf("Rate Limit:  %.1f req/s\n", m.Crawl.RateLimit)
 	}
+	if m.Crawl.KeyRanges > 1 {
+		fmt.Printf("Key Ranges:  %d per prefix\n", m.Crawl.KeyRanges)
+	}
 	if m.Crawl.Preflight.Mode != "" {
 		fmt.Printf("Preflight:   %s\n", m.Crawl.Preflight.Mode)
 	}
@@ -286,6 +291,14 @@ func executeCrawl(ctx context.Context, m *manifest.Manifest) error {
 	if filter != nil {
 		c.WithFilter(filter)
 	}
+	if m.Crawl.KeyRanges > 1 {
+		ranges, err := splitCrawlKeyRanges(ctx, prov, matcher.Prefixes(), m.Crawl.KeyRanges, m.Crawl.Concurrency)
+		if err != nil {
+			observability.CLILogger.Error("Key range split failed", zap.Error(err))
+			return exitError(foundry.ExitExternalServiceUnavailable, "Key range split failed", err)
+		}
+		c.WithKeyRanges(ranges)
+	}
 
 	observability.CLILogger.Info("Starting crawl",
 		zap.String("job_id", jobID),
@@ -293,6 +306,11 @@ func executeCrawl(ctx context.Context, m *manifest.Manifest) error {
 		zap.Int("concurrency", cfg.Concurrency))
 
 	summary, err := c.Run(ctx)
+	metrics.RecordCrawlSummary(metrics.JobLabels{
+		Provider: m.Connection.Provider,
+		Bucket:   m.Connection.Bucket,
+		JobType:  metrics.JobTypeCrawl,
+	}, summary, err == nil)
 	if err != nil {
 		if ctx.Err() != nil {
 			observability.CLILogger.Warn("Crawl cancelled",
@@ -560,3 +578,19 @@ func createWriter(m *manifest.Manifest, jobID string) (output.Writer, func(), er
 func exitError(code int, message string, err error) error {
 	return fmt.Errorf("%s: %w (exit code %d)", message, err, code)
 }
+
+// splitCrawlKeyRanges splits each crawl prefix into sampled key ranges.
+func splitCrawlKeyRanges(ctx context.Context, prov provider.Provider, prefixes []string, n, concurrency int) ([]shard.KeyRange, error) {
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	var out []shard.KeyRange
+	for _, prefix := range prefixes {
+		ranges, err := shard.SplitKeyRange(ctx, prov, prefix, shard.RangeConfig{Ranges: n, ListConcurrency: concurrency})
+		if err != nil {
+			return nil, fmt.Errorf("split %q into key ranges: %w", prefix, err)
+		}
+		out = append(out, ranges...)
+	}
+	return out, nil
+}
@@ -21,6 +21,9 @@ import (
 //   - msg: Human-readable error message
 //   - err: The underlying error (can be nil)
 func ExitWithCode(logger *logging.Logger, exitCode foundry.ExitCode, msg string, err error) {
+	// os.Exit skips deferred calls; write terminal metrics and traces first.
+	flushRunTelemetry(exitCode != foundry.ExitSuccess)
+
 	// Get exit code metadata from foundry catalog
 	info, ok := foundry.GetExitCodeInfo(exitCode)
 	if !ok {
@@ -86,6 +89,8 @@ func ExitWithCode(logger *logging.Logger, exitCode foundry.ExitCode, msg string,
 // ExitWithCodeWriter exits with a semantic foundry exit code, writing plain
 // diagnostic output to the supplied writer instead of using the CLI logger.
 func ExitWithCodeWriter(w io.Writer, exitCode foundry.ExitCode, msg string, err error) {
+	flushRunTelemetry(exitCode != foundry.ExitSuccess)
+
 	if w == nil {
 		w = io.Discard
 	}
@@ -128,6 +133,8 @@ func ExitWithCodeWriter(w io.Writer, exitCode foundry.ExitCode, msg string, err
 //   - msg: Human-readable error message
 //   - err: The underlying error (can be nil)
 func ExitWithCodeStderr(exitCode foundry.ExitCode, msg string, err error) {
+	flushRunTelemetry(exitCode != foundry.ExitSuccess)
+
 	info, ok := foundry.GetExitCodeInfo(exitCode)
 	if !ok {
 		// Fallback if we can't get exit code info
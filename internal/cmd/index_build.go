@@ -30,6 +30,7 @@ import (
 	"github.com/3leaps/gonimbus/pkg/manifest"
 	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/opcheckpoint"
+	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/provider"
 	"github.com/3leaps/gonimbus/pkg/reflow"
 	"github.com/3leaps/gonimbus/pkg/scope"
@@ -67,9 +68,10 @@ SQLite compatibility builds still use:
 
 Local consumer note: query, list, stats, doctor, and enrich-with-head are
 format-aware (durable or SQLite). Use --format sqlite or --format both when you
-still need SQLite-only surfaces: query --since-run, stats --prefixes, or full
---resume-run checkpoint recovery. Durable hydrate restores
-manifest+segments, not index.db.
+still need SQLite-only surfaces: query --since-run or stats --prefixes.
+--resume-run resumes a failed-resumable build in the format it was built with;
+a durable resume lists only the lanes that did not seal. Durable hydrate
+restores manifest+segments, not index.db.
 
 The index build process:
 1. Loads and validates the index manifest
@@ -94,12 +96,57 @@ rediscover the just-built set from index list. --json is rejected with
 }
 
 func runIndexBuildCommand(cmd *cobra.Command, args []string) error {
+	managedJobID := strings.TrimSpace(indexBuildManagedJobID)
+	if managedJobID != "" {
+		indexBuildJobEvents = openManagedIndexBuildEvents(managedJobID)
+		defer func() {
+			_ = indexBuildJobEvents.closeFile()
+			indexBuildJobEvents = nil
+			indexBuildManagedHooks = nil
+		}()
+	}
 	err := runIndexBuild(cmd, args)
-	if err == nil || strings.TrimSpace(indexBuildManagedJobID) == "" {
+	if managedJobID == "" {
 		return err
 	}
-	persistManagedIndexBuildFailure(strings.TrimSpace(indexBuildManagedJobID))
-	return sanitizeManagedIndexBuildError(err)
+	var sanitized error
+	if err != nil {
+		sanitized = sanitizeManagedIndexBuildError(err)
+		// The error record lands before the terminal state so a stream that sees
+		// the job end has already read it.
+		if indexBuildJobEvents != nil {
+			_ = indexBuildJobEvents.WriteError(context.Background(), &output.ErrorRecord{
+				Code:    output.ErrCodeInternal,
+				Message: sanitized.Error(),
+			})
+		}
+		persistManagedIndexBuildFailure(managedJobID)
+	}
+	// Runs after the terminal state is persisted, so this job's slot is free
+	// for the next queued job, and before the terminal hooks so a slow receiver
+	// does not hold the slot.
+	admitQueuedIndexJobs()
+	var detail string
+	if sanitized != nil {
+		detail = sanitized.Error()
+	}
+	indexBuildManagedHooks.finish(managedJobID, err, detail)
+	return sanitized
+}
+
+// openManagedIndexBuildEvents opens the managed job's events file. A failure
+// only loses the live feed, so it is reported on stderr and the build goes on.
+func openManagedIndexBuildEvents(jobID string) *jobEventsWriter {
+	jobsRoot, err := indexJobsRootDir()
+	if err != nil {
+		return nil
+	}
+	events, err := openJobEventsWriter(jobregistry.NewStore(jobsRoot), jobID)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: job events unavailable: %v\n", err)
+		return nil
+	}
+	return events
 }
 
 func persistManagedIndexBuildFailure(jobID string) {
@@ -112,6 +159,10 @@ func persistManagedIndexBuildFailure(jobID string) {
 	if err != nil || (rec.State != jobregistry.JobStateQueued && rec.State != jobregistry.JobStateRunning) {
 		return
 	}
+	// A job still waiting in the queue was never handed to this process.
+	if rec.AwaitingAdmission() {
+		return
+	}
 	now := time.Now().UTC()
 	rec.State = jobregistry.JobStateFailed
 	rec.EndedAt = &now
@@ -119,6 +170,10 @@ func persistManagedIndexBuildFailure(jobID string) {
 	_ = store.Write(rec)
 }
 
+// errIndexBuildFailedResumable marks a build that stopped with a resumable
+// checkpoint.
+var errIndexBuildFailedResumable = errors.New("index build failed resumable")
+
 func sanitizeManagedIndexBuildError(err error) error {
 	return fmt.Errorf("managed index build failed: %s", reflow.SanitizeOperationCauseMessage(err))
 }
@@ -148,6 +203,8 @@ var (
 	indexBuildSpillWorkspaceMax  string
 	indexBuildSpillRecordMax     string
 	indexBuildSpillRoot          string
+	indexBuildPriority           string
+	indexBuildReserveMemory      string
 	// indexBuildSpillResolved is the spill configuration resolved across CLI flag >
 	// env > config > default, set by resolveIndexBuildSpill before the crawl.
 	indexBuildSpillResolved indexBuildSpillResolution
@@ -164,6 +221,8 @@ func init() {
 	indexBuildCmd.Flags().BoolVar(&indexBuildDryRun, "dry-run", false, "Validate manifest and show plan without building")
 	indexBuildCmd.Flags().BoolVar(&indexBuildBackground, "background", false, "Run index build as a managed background job")
 	indexBuildCmd.Flags().BoolVar(&indexBuildDedupe, "dedupe", false, "Refuse to start if an identical job is already running")
+	indexBuildCmd.Flags().StringVar(&indexBuildPriority, "priority", "", "With --background, the job queue priority class: high, normal (default), or low")
+	indexBuildCmd.Flags().StringVar(&indexBuildReserveMemory, "reserve-memory", "", "With --background, memory the job reserves from the job queue memory budget (e.g. 4GiB)")
 	indexBuildCmd.Flags().BoolVar(&indexBuildSummary, "summary", false, "Print top-level object distribution after a completed build")
 	indexBuildCmd.Flags().BoolVar(&indexBuildJSON, "json", false, "Emit machine-stable build_result.v1 receipt on stdout after successful commit")
 	indexBuildCmd.Flags().StringVar(&indexBuildManagedJobID, "_managed-job-id", "", "(internal) Managed job id")
@@ -192,14 +251,24 @@ func init() {
 }
 
 // validateIndexBuildResumeInvocation checks flags that conflict with --resume-run.
-// Resume is always the SQLite checkpoint path; the durable default must not reject
-// the printed `index build --resume-run <id>` command when --format was omitted.
+// Resume continues in the format the run was built with, so --format is
+// optional; the printed `index build --resume-run <id>` hint omits it.
 func validateIndexBuildResumeInvocation(cmd *cobra.Command) error {
 	if indexBuildExperimentalEngine {
 		return fmt.Errorf("--experimental-engine is not compatible with --resume-run")
 	}
-	if cmd != nil && cmd.Flags().Changed("format") && selectedIndexBuildFormat() != "sqlite" {
-		return fmt.Errorf("--resume-run is not compatible with --format %s; resume uses the SQLite checkpoint lifecycle (omit --format or pass --format sqlite)", selectedIndexBuildFormat())
+	if cmd != nil && cmd.Flags().Changed("format") && selectedIndexBuildFormat() == "both" {
+		return fmt.Errorf("--resume-run is not compatible with --format both; resume continues in the format the run was built with (omit --format)")
+	}
+	return nil
+}
+
+// validateIndexBuildResumeFormat refuses an explicit --format that differs from
+// the format the checkpointed run was built with.
+func validateIndexBuildResumeFormat(cmd *cobra.Command, payload indexBuildCheckpointPayload) error {
+	recorded := indexBuildCheckpointFormat(payload)
+	if cmd != nil && cmd.Flags().Changed("format") && selectedIndexBuildFormat() != recorded {
+		return fmt.Errorf("--resume-run is not compatible with --format %s; run was built with --format %s (omit --format)", selectedIndexBuildFormat(), recorded)
 	}
 	return nil
 }
@@ -224,15 +293,19 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 	if indexBuildJSON && indexBuildBackground {
 		return fmt.Errorf("--json is not compatible with --background; the immediate job id is not a committed build receipt")
 	}
+	if !indexBuildBackground && (strings.TrimSpace(indexBuildPriority) != "" || strings.TrimSpace(indexBuildReserveMemory) != "") {
+		return fmt.Errorf("--priority and --reserve-memory apply to the job queue and require --background")
+	}
 	if indexBuildJSON && indexBuildDryRun {
 		return fmt.Errorf("--json is not compatible with --dry-run; dry-run does not emit a committed build receipt")
 	}
 	if indexBuildJSON && resumeRun != "" {
 		return fmt.Errorf("--json is not compatible with --resume-run; resume does not emit a build_result receipt in this cut")
 	}
-	// Resume is a SQLite checkpoint lifecycle path. Dispatch it before build-format
-	// validation so the durable default does not break the printed operator hint
-	// (`gonimbus index build --resume-run <run_id>` with no --format).
+	// Resume continues in the checkpointed run's format. Dispatch it before
+	// build-format validation so the printed operator hint
+	// (`gonimbus index build --resume-run <run_id>` with no --format) works for
+	// either lifecycle.
 	if resumeRun != "" {
 		if err := validateIndexBuildResumeInvocation(cmd); err != nil {
 			return err
@@ -256,24 +329,42 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 		if err := validateIndexBuildBackgroundFlags(); err != nil {
 			return err
 		}
-		execRoot, err := indexJobsRootDir()
+		exec, err := newIndexJobsExecutor()
 		if err != nil {
 			return err
 		}
-		exec := jobregistry.NewExecutor(execRoot)
+		hooks, err := resolveJobHooks()
+		if err != nil {
+			return err
+		}
+		queuedHooks := newQueuedJobHooks(hooks, exec.Store())
+		exec.OnEnqueued = queuedHooks.onEnqueued()
+		// Runs after the job id is printed: the submission is done by then.
+		defer queuedHooks.wait(queuedJobHookGrace)
 		invocation, err := resolvedCurrentIndexBuildInvocation()
 		if err != nil {
 			return err
 		}
+		reserveMemory, err := indexBuildReserveMemoryBytes()
+		if err != nil {
+			return err
+		}
 		job, err := exec.StartIndexBuildBackground(indexBuildJobPath, strings.TrimSpace(indexBuildName), jobregistry.BackgroundOptions{
-			Dedupe:     indexBuildDedupe,
-			Since:      strings.TrimSpace(indexBuildSince),
-			Invocation: &invocation,
+			Dedupe:      indexBuildDedupe,
+			Since:       strings.TrimSpace(indexBuildSince),
+			Invocation:  &invocation,
+			Priority:    indexBuildPriority,
+			MemoryBytes: reserveMemory,
 		})
 		if err != nil {
 			return err
 		}
 		_, _ = fmt.Fprintf(os.Stdout, "%s\n", job.JobID)
+		if job.AwaitingAdmission() {
+			if jobs, err := exec.Store().List(); err == nil {
+				_, _ = fmt.Fprintf(os.Stderr, "queued at position %d; the job starts when the queue limits admit it\n", jobregistry.QueuePositions(jobs)[job.JobID])
+			}
+		}
 		return nil
 	}
 
@@ -300,6 +391,7 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 			if err != nil {
 				return err
 			}
+			indexBuildManagedHooks = startManagedJobHooks(store, job)
 			// Heartbeat begins immediately after claim — not after CreateIndexRun —
 			// so a healthy pre-authority build cannot look stalled by silence.
 			// Dual persist failure cancels THIS context tree (used by crawl/
@@ -375,6 +467,7 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 		return fmt.Errorf("load index manifest: %w", manifestLoadErr)
 
 	}
+	indexBuildJobEvents.bindProvider(m.Connection.Provider)
 
 	// Validate base_uri ends with /
 	if !strings.HasSuffix(m.Connection.BaseURI, "/") {
@@ -499,35 +592,31 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 
 	if selectedIndexBuildFormat() == "durable" {
 		if resolvedDB.Canonical {
-			guard, err := indexreader.OpenSQLiteIdentityPublicationGuard(ctx, indexreader.SQLiteWriteTargetOptions{
-				Path:           resolvedDB.Path,
-				IdentityPath:   filepath.Join(resolvedDB.IdentityDir, "identity.json"),
-				SegmentSetRoot: segmentSetRoot,
-				IndexSetID:     identityResult.IndexSetID,
-				Authority:      maintenance.Authority(),
-				MaxMarkerBytes: int64(maxHubMarkerBytes),
-			})
-			if err != nil {
-				return err
-			}
-			if indexBuildAfterIdentityGuard != nil {
-				if err := indexBuildAfterIdentityGuard(resolvedDB.Path); err != nil {
-					return errors.Join(err, guard.Close())
-				}
-			}
-			if err := guard.PublishIdentity(identityResult); err != nil {
-				return errors.Join(err, guard.Close())
-			}
-			if err := guard.PublishManifest(m); err != nil {
-				return errors.Join(err, guard.Close())
-			}
-			if err := guard.Close(); err != nil {
+			if err := publishIndexBuildCanonicalIdentity(ctx, resolvedDB, segmentSetRoot, identityResult, m, maintenance); err != nil {
 				return err
 			}
 		}
 		cmd.SilenceUsage = true
-		summary, identityDir, err := runIndexBuildDurable(ctx, m, identityResult, buildFilters, resolvedDB, maintenance.Authority())
+		durableRun := newIndexBuildDurableRun()
+		summary, identityDir, err := runIndexBuildDurable(ctx, m, identityResult, buildFilters, resolvedDB, maintenance.Authority(), durableRun)
 		if err != nil {
+			class, progress, resumable, checkpointErr := writeFailedResumableDurableIndexBuildCheckpoint(context.Background(), identityResult.IndexSetID, durableRun, checkpointCfg, err)
+			if checkpointErr != nil {
+				err = fmt.Errorf("%w; write operation checkpoint: %v", err, checkpointErr)
+			} else if resumable {
+				writeOperationErrorSummary(cmd.ErrOrStderr(), "Index build failed with resumable checkpoint", operationIndexBuild, durableRun.RunID, class, progress)
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				if emitErr := emitOperationErrorRecord(context.Background(), enc, operationIndexBuild, durableRun.RunID, class, progress); emitErr != nil {
+					err = fmt.Errorf("%w; write operation error record: %v", err, emitErr)
+				}
+				if store != nil && job != nil {
+					job.State = jobregistry.JobStatePartial
+					ended := time.Now().UTC()
+					job.EndedAt = &ended
+					_ = store.Write(job)
+				}
+				return fmt.Errorf("%w: %w", errIndexBuildFailedResumable, err)
+			}
 			if store != nil && job != nil {
 				job.State = jobregistry.JobStateFailed
 				ended := time.Now().UTC()
@@ -555,7 +644,14 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 		_, _ = fmt.Fprintf(os.Stderr, "  run_id: %s\n", summary.RunID)
 		_, _ = fmt.Fprintf(os.Stderr, "  index_set_id: %s\n", summary.IndexSetID)
 		_, _ = fmt.Fprintf(os.Stderr, "  objects_observed: %d\n", summary.ObjectsObserved)
+		_, _ = fmt.Fprintf(os.Stderr, "  objects_listed: %d\n", summary.ObjectsListed)
+		if summary.Manifest.Selector != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "  filtered: true\n")
+		}
 		_, _ = fmt.Fprintf(os.Stderr, "  segments: %d\n", len(summary.Manifest.Segments))
+		for _, ln := range summary.Lanes {
+			_, _ = fmt.Fprintf(os.Stderr, "  lane %d: plan_entries=%d predicted_objects=%d listed_objects=%d observed_objects=%d\n", ln.Ordinal, ln.PlanEntries, ln.PredictedObjects, ln.ListedObjects, ln.ObservedObjects)
+		}
 		if indexBuildJSON {
 			if err := emitIndexBuildResultJSON(cmd.OutOrStdout(), receipt); err != nil {
 				return err
@@ -760,7 +856,7 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 					job.EndedAt = &ended
 					_ = store.Write(job)
 				}
-				return fmt.Errorf("index build failed resumable: %w", crawlErr)
+				return fmt.Errorf("%w: %w", errIndexBuildFailedResumable, crawlErr)
 			}
 			crawlErr = fmt.Errorf("%w; write operation checkpoint: %v", crawlErr, checkpointErr)
 		}
@@ -954,6 +1050,34 @@ func runIndexBuild(cmd *cobra.Command, args []string) (runErr error) {
 // emitCommittedIndexBuildJSON writes the terminal build_result record for a
 // committed sqlite/both path. Durable success is emitted on its own return path.
 // Failed final status emits nothing (caller already returns error).
+// publishIndexBuildCanonicalIdentity commits durable-only canonical metadata
+// through the library-owned retained guard, before any durable publication.
+func publishIndexBuildCanonicalIdentity(ctx context.Context, resolvedDB resolvedIndexDB, segmentSetRoot string, identityResult *indexstore.IndexSetIdentityResult, m *manifest.IndexManifest, maintenance *indexSetMaintenanceGuard) error {
+	guard, err := indexreader.OpenSQLiteIdentityPublicationGuard(ctx, indexreader.SQLiteWriteTargetOptions{
+		Path:           resolvedDB.Path,
+		IdentityPath:   filepath.Join(resolvedDB.IdentityDir, "identity.json"),
+		SegmentSetRoot: segmentSetRoot,
+		IndexSetID:     identityResult.IndexSetID,
+		Authority:      maintenance.Authority(),
+		MaxMarkerBytes: int64(maxHubMarkerBytes),
+	})
+	if err != nil {
+		return err
+	}
+	if indexBuildAfterIdentityGuard != nil {
+		if err := indexBuildAfterIdentityGuard(resolvedDB.Path); err != nil {
+			return errors.Join(err, guard.Close())
+		}
+	}
+	if err := guard.PublishIdentity(identityResult); err != nil {
+		return errors.Join(err, guard.Close())
+	}
+	if err := guard.PublishManifest(m); err != nil {
+		return errors.Join(err, guard.Close())
+	}
+	return guard.Close()
+}
+
 func emitCommittedIndexBuildJSON(
 	w io.Writer,
 	format string,
@@ -1042,6 +1166,12 @@ func runIndexBuildResume(ctx context.Context, cmd *cobra.Command, runID string)
 	if !validFullIndexSetID(payload.Config.IndexSetID) {
 		return fmt.Errorf("resume checkpoint does not carry a valid full index_set_id")
 	}
+	if err := validateIndexBuildResumeFormat(cmd, payload); err != nil {
+		return err
+	}
+	if indexBuildCheckpointFormat(payload) == indexBuildCheckpointFormatDurable {
+		return runIndexBuildDurableResume(ctx, cmd, opStore, env, payload)
+	}
 	if !payload.Config.UsesDefaultIndexDB {
 		return fmt.Errorf("--resume-run %s is not supported for non-default index database paths in this slice", runID)
 	}
@@ -1197,9 +1327,12 @@ func runIndexBuildResume(ctx context.Context, cmd *cobra.Command, runID string)
 }
 
 type indexBuildCheckpointPayload struct {
-	Config        indexBuildCheckpointConfig  `json:"config"`
-	CrawlPrefixes []string                    `json:"crawl_prefixes,omitempty"`
-	Summary       indexBuildCheckpointSummary `json:"summary"`
+	// Format is the build path that wrote the checkpoint; empty is SQLite.
+	Format        string                       `json:"format,omitempty"`
+	Config        indexBuildCheckpointConfig   `json:"config"`
+	CrawlPrefixes []string                     `json:"crawl_prefixes,omitempty"`
+	Summary       indexBuildCheckpointSummary  `json:"summary"`
+	Durable       *indexBuildDurableCheckpoint `json:"durable,omitempty"`
 }
 
 type indexBuildCheckpointConfig struct {
@@ -1386,6 +1519,19 @@ func classifyIndexBuildRunError(err error, _ *manifest.IndexManifest) opcheckpoi
 	})
 }
 
+// indexBuildReserveMemoryBytes parses --reserve-memory; empty reserves nothing.
+func indexBuildReserveMemoryBytes() (int64, error) {
+	raw := strings.TrimSpace(indexBuildReserveMemory)
+	if raw == "" {
+		return 0, nil
+	}
+	size, err := match.ParseSize(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --reserve-memory %q: %w", raw, err)
+	}
+	return size, nil
+}
+
 func validateIndexBuildBackgroundFlags() error {
 	if indexBuildDryRun {
 		return fmt.Errorf("--background is not compatible with --dry-run")
@@ -2223,6 +2369,7 @@ func runCrawlForIndex(
 		ObjectBatchSize: DefaultObjectBatchSize,
 		PrefixBatchSize: DefaultPrefixBatchSize,
 		DeltaReport:     deltaReport,
+		Events:          indexBuildJobEventsSink(),
 	})
 
 	// Create crawler config with nil-guard
@@ -2263,6 +2410,14 @@ func runCrawlForIndex(
 			}
 		}
 	}
+	if m.Build != nil && m.Build.Crawl != nil && m.Build.Crawl.KeyRanges > 1 && len(crawlPrefixes) == 1 {
+		ranges, err := splitCrawlKeyRanges(ctx, prov, crawlPrefixes, m.Build.Crawl.KeyRanges, crawlCfg.Concurrency)
+		if err != nil {
+			_ = writer.Close()
+			return nil, err
+		}
+		c = c.WithKeyRanges(ranges)
+	}
 	writer.setDeltaPrefixes(crawlPrefixes)
 	_, crawlErr := c.Run(ctx)
 
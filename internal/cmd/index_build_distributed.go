@@ -0,0 +1,1178 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/internal/metrics"
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/indexbuild"
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+	"github.com/3leaps/gonimbus/pkg/manifest"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/shard"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+const (
+	distributedDocVersion      = "1.0"
+	defaultDistributedLeaseTTL = 2 * time.Minute
+	minDistributedLeaseTTL     = 10 * time.Second
+	operationIndexDistributed  = "index distributed"
+)
+
+var errDistributedLeaseLost = errors.New("distributed lane lease lost")
+
+// distributedNow is the clock every lease decision reads. Lease expiry compares
+// one host's clock against a timestamp another host wrote, so the TTL must
+// comfortably exceed the clock skew between workers.
+var distributedNow = func() time.Time { return time.Now().UTC() }
+
+var indexDistributedCmd = &cobra.Command{
+	Use:   "distributed",
+	Short: "Build one durable index run across several hosts (experimental)",
+	Long: `Split one durable index build across several hosts that share an index hub.
+
+A coordinator plans the run's lanes and publishes the plan to the hub. Workers
+on any host claim lanes through conditional-write lease objects, crawl them,
+and upload each lane's sealed journal to the hub. A lease that stops being
+renewed expires and its lane is claimed again by the next worker. Once every
+lane is done, the finalizer downloads the journals and publishes them as one
+durable-v2 run through the same compaction and publish path as index build.
+
+Every step reads the same --job manifest; workers and the finalizer refuse a
+plan whose index set does not match it. The hub must support conditional
+writes and versioned reads (file://, s3://, gs://).
+
+Examples:
+  gonimbus index distributed plan --job index.yaml --hub s3://ops/index-hub/ --lanes 8
+  gonimbus index distributed work --job index.yaml --hub s3://ops/index-hub/ --run-id run_1760000000000000000
+  gonimbus index distributed status --hub s3://ops/index-hub/ --index-set idx_... --run-id run_...
+  gonimbus index distributed finalize --job index.yaml --hub s3://ops/index-hub/ --run-id run_...`,
+}
+
+var indexDistributedPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Plan a distributed run and publish it to the hub",
+	Long: `Plan the lanes of a new durable run and publish the plan to the hub.
+
+Lanes are planned as index build plans them: an explicit multi-entry scope is
+split into plan-entry lanes (bin-packed by the previous run's counts when
+build.crawl.balance_lanes is set), and a single-prefix run is split into
+sampled key ranges when build.crawl.key_ranges is set. --lanes bounds the lane
+count; this host's crawl concurrency does not. The new run ID is printed on
+stdout.`,
+	Args: cobra.NoArgs,
+	RunE: runIndexDistributedPlan,
+}
+
+var indexDistributedWorkCmd = &cobra.Command{
+	Use:   "work",
+	Short: "Claim and crawl lanes of a distributed run",
+	Long: `Claim unfinished lanes of a distributed run, crawl them, and upload their
+sealed journals to the hub.
+
+The worker makes one pass over the plan: a lane that is done or leased by a
+live worker is skipped, and an expired lease is taken over. While a lane is
+crawled its lease is renewed every third of the lease TTL; if renewal finds
+the lease taken, the crawl is abandoned. A lane is done when done.json is
+written, which only one worker can do.`,
+	Args: cobra.NoArgs,
+	RunE: runIndexDistributedWork,
+}
+
+var indexDistributedStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show lane progress of a distributed run",
+	Args:  cobra.NoArgs,
+	RunE:  runIndexDistributedStatus,
+}
+
+var indexDistributedFinalizeCmd = &cobra.Command{
+	Use:   "finalize",
+	Short: "Publish a completed distributed run as one durable run",
+	Long: `Download every lane's sealed journal, verify it against the digest its worker
+recorded, and publish the set as one durable-v2 run of the local index set.
+
+Finalize refuses while any lane is unfinished. Publication holds index-set
+authority and applies the same coverage checks as index build.`,
+	Args: cobra.NoArgs,
+	RunE: runIndexDistributedFinalize,
+}
+
+func init() {
+	indexCmd.AddCommand(indexDistributedCmd)
+	indexDistributedCmd.AddCommand(indexDistributedPlanCmd)
+	indexDistributedCmd.AddCommand(indexDistributedWorkCmd)
+	indexDistributedCmd.AddCommand(indexDistributedStatusCmd)
+	indexDistributedCmd.AddCommand(indexDistributedFinalizeCmd)
+
+	for _, cmd := range []*cobra.Command{indexDistributedPlanCmd, indexDistributedWorkCmd, indexDistributedStatusCmd, indexDistributedFinalizeCmd} {
+		cmd.Flags().String("hub", "", "Hub root URI (required)")
+		cmd.Flags().String("hub-profile", "", "AWS profile for hub")
+		cmd.Flags().String("hub-region", "", "AWS region for hub")
+		cmd.Flags().String("hub-endpoint", "", "Custom endpoint for hub")
+		cmd.Flags().String("hub-gcp-project", "", "GCP project hint for GCS hub")
+		_ = cmd.MarkFlagRequired("hub")
+	}
+	for _, cmd := range []*cobra.Command{indexDistributedPlanCmd, indexDistributedWorkCmd, indexDistributedFinalizeCmd} {
+		cmd.Flags().String("job", "", "Path to index manifest (required)")
+		_ = cmd.MarkFlagRequired("job")
+	}
+	for _, cmd := range []*cobra.Command{indexDistributedWorkCmd, indexDistributedStatusCmd, indexDistributedFinalizeCmd} {
+		cmd.Flags().String("run-id", "", "Distributed run ID printed by plan (required)")
+		_ = cmd.MarkFlagRequired("run-id")
+	}
+
+	indexDistributedPlanCmd.Flags().Int("lanes", 0, fmt.Sprintf("Maximum lanes to plan (default %d, at most %d)", indexbuild.DefaultMaxJournalLanes, indexbuild.MaxJournalLanesCeiling))
+	indexDistributedPlanCmd.Flags().Duration("lease-ttl", defaultDistributedLeaseTTL, "How long a lane lease lives without renewal")
+
+	indexDistributedWorkCmd.Flags().String("worker-id", "", "Holder name recorded in leases (default host-pid)")
+	indexDistributedWorkCmd.Flags().Int("max-lanes", 0, "Stop after completing this many lanes (0 = no limit)")
+
+	indexDistributedStatusCmd.Flags().String("index-set", "", "Index set ID (required)")
+	indexDistributedStatusCmd.Flags().Bool("json", false, "Output as JSON")
+	_ = indexDistributedStatusCmd.MarkFlagRequired("index-set")
+
+	indexDistributedFinalizeCmd.Flags().Bool("json", false, "Emit a build_result receipt on stdout")
+}
+
+// distributedHubStore is what lane coordination needs from the hub: plain and
+// versioned reads, and conditional writes for plan, lease, and done objects.
+type distributedHubStore interface {
+	provider.ObjectGetter
+	provider.VersionedGetter
+	provider.ConditionalPutter
+}
+
+var newDistributedHubStore = func(ctx context.Context, hub *hubDestSpec) (distributedHubStore, error) {
+	if hub.Provider == string(provider.ProviderFile) {
+		if err := os.MkdirAll(hub.BaseDir, 0o755); err != nil {
+			return nil, fmt.Errorf("access hub directory: %w", err)
+		}
+	}
+	p, err := providerdispatch.NewDestination(ctx, providerdispatch.DestinationOptions{
+		Command:     operationIndexDistributed,
+		Provider:    hub.Provider,
+		S3Bucket:    hub.Bucket,
+		S3Prefix:    hub.Prefix,
+		GCSBucket:   hub.Bucket,
+		GCSPrefix:   hub.Prefix,
+		FileBaseDir: hub.BaseDir,
+		S3: providerdispatch.S3Options{
+			Region:         hub.Region,
+			Endpoint:       hub.Endpoint,
+			Profile:        hub.Profile,
+			ForcePathStyle: hub.ForcePathStyle,
+		},
+		GCS: providerdispatch.GCSOptions{
+			Project: strings.TrimSpace(hub.GCPProject),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create hub provider: %w", err)
+	}
+	store, ok := p.(distributedHubStore)
+	if !ok {
+		_ = p.Close()
+		return nil, fmt.Errorf("%s hub does not support conditional writes and versioned reads required for distributed builds", hub.Provider)
+	}
+	return store, nil
+}
+
+// --- hub documents ---
+
+type distributedPlanDoc struct {
+	Version         string               `json:"version"`
+	IndexSetID      string               `json:"index_set_id"`
+	RunID           string               `json:"run_id"`
+	BaseURI         string               `json:"base_uri"`
+	ScopeHash       string               `json:"scope_hash"`
+	RunStartedAt    string               `json:"run_started_at"`
+	CrawlPrefixes   []string             `json:"crawl_prefixes,omitempty"`
+	LeaseTTLSeconds int64                `json:"lease_ttl_seconds"`
+	Lanes           []distributedLaneDoc `json:"lanes"`
+	CreatedAt       string               `json:"created_at"`
+	CreatedBy       string               `json:"created_by"`
+}
+
+type distributedLaneDoc struct {
+	Ordinal          int      `json:"ordinal"`
+	Prefixes         []string `json:"prefixes"`
+	StartAfter       *string  `json:"start_after,omitempty"`
+	EndBefore        *string  `json:"end_before,omitempty"`
+	PredictedObjects int64    `json:"predicted_objects,omitempty"`
+}
+
+type distributedLeaseDoc struct {
+	Version    string `json:"version"`
+	IndexSetID string `json:"index_set_id"`
+	RunID      string `json:"run_id"`
+	Lane       int    `json:"lane"`
+	Holder     string `json:"holder"`
+	Token      string `json:"token"`
+	AcquiredAt string `json:"acquired_at"`
+	RenewedAt  string `json:"renewed_at"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+type distributedDoneDoc struct {
+	Version         string `json:"version"`
+	IndexSetID      string `json:"index_set_id"`
+	RunID           string `json:"run_id"`
+	Lane            int    `json:"lane"`
+	Holder          string `json:"holder"`
+	Token           string `json:"token"`
+	JournalKey      string `json:"journal_key"`
+	SizeBytes       int64  `json:"size_bytes"`
+	SHA256          string `json:"sha256"`
+	ListedObjects   int64  `json:"listed_objects"`
+	ObservedObjects int64  `json:"observed_objects"`
+	CompletedAt     string `json:"completed_at"`
+}
+
+func distributedRunKey(hub *hubDestSpec, indexSetID, runID string, parts ...string) string {
+	return hubArtifactKey(hub, append([]string{"index-sets", indexSetID, "distributed", runID}, parts...)...)
+}
+
+func distributedLaneKey(hub *hubDestSpec, indexSetID, runID string, ordinal int, name string) string {
+	return distributedRunKey(hub, indexSetID, runID, "lanes", fmt.Sprintf("%04d", ordinal), name)
+}
+
+func (p distributedPlanDoc) leaseTTL() time.Duration {
+	return time.Duration(p.LeaseTTLSeconds) * time.Second
+}
+
+func (p distributedPlanDoc) runStartedAt() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, p.RunStartedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("plan run_started_at: %w", err)
+	}
+	return t.UTC(), nil
+}
+
+func (p distributedPlanDoc) lanePlans() []indexbuild.LanePlan {
+	out := make([]indexbuild.LanePlan, len(p.Lanes))
+	for i, ln := range p.Lanes {
+		out[i] = indexbuild.LanePlan{
+			Ordinal:          ln.Ordinal,
+			Prefixes:         append([]string(nil), ln.Prefixes...),
+			PredictedObjects: ln.PredictedObjects,
+		}
+		if ln.StartAfter != nil || ln.EndBefore != nil {
+			kr := &shard.KeyRange{}
+			if ln.StartAfter != nil {
+				kr.StartAfter = *ln.StartAfter
+			}
+			if ln.EndBefore != nil {
+				kr.EndBefore = *ln.EndBefore
+			}
+			out[i].KeyRange = kr
+		}
+	}
+	return out
+}
+
+func distributedLaneDocs(plan []indexbuild.LanePlan) []distributedLaneDoc {
+	out := make([]distributedLaneDoc, len(plan))
+	for i, lp := range plan {
+		out[i] = distributedLaneDoc{
+			Ordinal:          lp.Ordinal,
+			Prefixes:         append([]string(nil), lp.Prefixes...),
+			PredictedObjects: lp.PredictedObjects,
+		}
+		if lp.KeyRange != nil {
+			startAfter, endBefore := lp.KeyRange.StartAfter, lp.KeyRange.EndBefore
+			out[i].StartAfter = &startAfter
+			out[i].EndBefore = &endBefore
+		}
+	}
+	return out
+}
+
+func publishDistributedPlan(ctx context.Context, store distributedHubStore, hub *hubDestSpec, plan distributedPlanDoc) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	key := distributedRunKey(hub, plan.IndexSetID, plan.RunID, "plan.json")
+	if _, err := store.PutObjectConditional(ctx, key, bytes.NewReader(data), int64(len(data)), provider.PutPrecondition{IfAbsent: true}); err != nil {
+		if provider.IsAlreadyExists(err) {
+			return fmt.Errorf("a plan for run %s is already published", plan.RunID)
+		}
+		return fmt.Errorf("write plan.json: %w", err)
+	}
+	return nil
+}
+
+func readDistributedPlan(ctx context.Context, store distributedHubStore, hub *hubDestSpec, indexSetID, runID string) (distributedPlanDoc, error) {
+	data, err := downloadBytesBounded(ctx, store, distributedRunKey(hub, indexSetID, runID, "plan.json"), maxHubMarkerBytes, "plan.json")
+	if err != nil {
+		if provider.IsNotFound(err) {
+			return distributedPlanDoc{}, fmt.Errorf("no distributed plan for run %s of %s in hub", runID, indexSetID)
+		}
+		return distributedPlanDoc{}, fmt.Errorf("read plan.json: %w", err)
+	}
+	var plan distributedPlanDoc
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return distributedPlanDoc{}, fmt.Errorf("parse plan.json: %w", err)
+	}
+	switch {
+	case plan.Version != distributedDocVersion:
+		return distributedPlanDoc{}, fmt.Errorf("plan.json version %q is not supported", plan.Version)
+	case plan.IndexSetID != indexSetID || plan.RunID != runID:
+		return distributedPlanDoc{}, fmt.Errorf("plan.json names %s/%s, expected %s/%s", plan.IndexSetID, plan.RunID, indexSetID, runID)
+	case len(plan.Lanes) == 0:
+		return distributedPlanDoc{}, fmt.Errorf("plan.json has no lanes")
+	case plan.leaseTTL() < minDistributedLeaseTTL:
+		return distributedPlanDoc{}, fmt.Errorf("plan.json lease_ttl_seconds must be at least %d", int64(minDistributedLeaseTTL/time.Second))
+	}
+	if _, err := plan.runStartedAt(); err != nil {
+		return distributedPlanDoc{}, err
+	}
+	return plan, nil
+}
+
+// readDistributedDone returns the lane's done marker, or ok=false when the lane
+// is unfinished.
+func readDistributedDone(ctx context.Context, store distributedHubStore, hub *hubDestSpec, plan distributedPlanDoc, ordinal int) (distributedDoneDoc, bool, error) {
+	data, err := downloadBytesBounded(ctx, store, distributedLaneKey(hub, plan.IndexSetID, plan.RunID, ordinal, "done.json"), maxHubMarkerBytes, "done.json")
+	if err != nil {
+		if provider.IsNotFound(err) {
+			return distributedDoneDoc{}, false, nil
+		}
+		return distributedDoneDoc{}, false, fmt.Errorf("read lane %d done.json: %w", ordinal, err)
+	}
+	var done distributedDoneDoc
+	if err := json.Unmarshal(data, &done); err != nil {
+		return distributedDoneDoc{}, false, fmt.Errorf("parse lane %d done.json: %w", ordinal, err)
+	}
+	journalPrefix := distributedLaneKey(hub, plan.IndexSetID, plan.RunID, ordinal, "journal-")
+	switch {
+	case done.IndexSetID != plan.IndexSetID || done.RunID != plan.RunID || done.Lane != ordinal:
+		return distributedDoneDoc{}, false, fmt.Errorf("lane %d done.json names another lane", ordinal)
+	case !strings.HasPrefix(done.JournalKey, journalPrefix) || strings.Contains(done.JournalKey, ".."):
+		return distributedDoneDoc{}, false, fmt.Errorf("lane %d done.json journal_key is outside the lane", ordinal)
+	case len(done.SHA256) != sha256.Size*2:
+		return distributedDoneDoc{}, false, fmt.Errorf("lane %d done.json sha256 is malformed", ordinal)
+	}
+	return done, true, nil
+}
+
+// --- leases ---
+
+// distributedLease is a held lane lease: the document this holder last wrote
+// and the ETag that proves nobody has written over it since.
+type distributedLease struct {
+	key  string
+	ttl  time.Duration
+	doc  distributedLeaseDoc
+	etag string
+}
+
+func readDistributedLease(ctx context.Context, store distributedHubStore, key string) (distributedLeaseDoc, string, error) {
+	body, meta, err := store.GetObjectVersioned(ctx, key)
+	if err != nil {
+		return distributedLeaseDoc{}, "", err
+	}
+	defer func() { _ = body.Close() }()
+	data, err := readAllBounded(body, meta.Size, maxHubMarkerBytes, "lease.json")
+	if err != nil {
+		return distributedLeaseDoc{}, "", err
+	}
+	var doc distributedLeaseDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return distributedLeaseDoc{}, "", fmt.Errorf("parse lease.json: %w", err)
+	}
+	if strings.TrimSpace(meta.ETag) == "" {
+		return distributedLeaseDoc{}, "", fmt.Errorf("versioned lease.json read did not return an ETag")
+	}
+	return doc, meta.ETag, nil
+}
+
+// leaseExpired treats an unparseable expiry as expired, so a corrupt lease can
+// never hold a lane forever.
+func leaseExpired(doc distributedLeaseDoc, now time.Time) bool {
+	expires, err := time.Parse(time.RFC3339Nano, doc.ExpiresAt)
+	return err != nil || !now.Before(expires)
+}
+
+// claimDistributedLane takes the lane's lease if it is free: absent, or
+// expired and replaced under the ETag that was read. ok=false means another
+// worker holds it or won the race.
+func claimDistributedLane(ctx context.Context, store distributedHubStore, hub *hubDestSpec, plan distributedPlanDoc, ordinal int, holder string) (*distributedLease, bool, error) {
+	key := distributedLaneKey(hub, plan.IndexSetID, plan.RunID, ordinal, "lease.json")
+	now := distributedNow()
+	var precond provider.PutPrecondition
+	current, etag, err := readDistributedLease(ctx, store, key)
+	switch {
+	case provider.IsNotFound(err):
+		precond = provider.PutPrecondition{IfAbsent: true}
+	case err != nil:
+		return nil, false, fmt.Errorf("read lane %d lease: %w", ordinal, err)
+	case !leaseExpired(current, now):
+		return nil, false, nil
+	default:
+		precond = provider.PutPrecondition{IfMatchETag: &etag}
+	}
+
+	lease := &distributedLease{
+		key: key,
+		ttl: plan.leaseTTL(),
+		doc: distributedLeaseDoc{
+			Version:    distributedDocVersion,
+			IndexSetID: plan.IndexSetID,
+			RunID:      plan.RunID,
+			Lane:       ordinal,
+			Holder:     holder,
+			Token:      uuid.NewString(),
+			AcquiredAt: now.Format(time.RFC3339Nano),
+			RenewedAt:  now.Format(time.RFC3339Nano),
+			ExpiresAt:  now.Add(plan.leaseTTL()).Format(time.RFC3339Nano),
+		},
+	}
+	if err := lease.write(ctx, store, precond); err != nil {
+		if errors.Is(err, errDistributedLeaseLost) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return lease, true, nil
+}
+
+// renew extends the lease under the ETag this holder last wrote. It returns
+// errDistributedLeaseLost when another worker has taken the lane over.
+func (l *distributedLease) renew(ctx context.Context, store distributedHubStore) error {
+	now := distributedNow()
+	prev := l.doc
+	l.doc.RenewedAt = now.Format(time.RFC3339Nano)
+	l.doc.ExpiresAt = now.Add(l.ttl).Format(time.RFC3339Nano)
+	etag := l.etag
+	if err := l.write(ctx, store, provider.PutPrecondition{IfMatchETag: &etag}); err != nil {
+		l.doc = prev
+		return err
+	}
+	return nil
+}
+
+func (l *distributedLease) write(ctx context.Context, store distributedHubStore, precond provider.PutPrecondition) error {
+	data, err := json.MarshalIndent(l.doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	res, err := store.PutObjectConditional(ctx, l.key, bytes.NewReader(data), int64(len(data)), precond)
+	if err != nil {
+		if provider.IsAlreadyExists(err) || provider.IsPreconditionFailed(err) || provider.IsNotFound(err) {
+			return errDistributedLeaseLost
+		}
+		return fmt.Errorf("write lane %d lease: %w", l.doc.Lane, err)
+	}
+	if strings.TrimSpace(res.ETag) != "" {
+		l.etag = res.ETag
+		return nil
+	}
+	// Some backends do not return the new ETag from a conditional put; read it
+	// back and make sure the object is still the one just written.
+	doc, etag, err := readDistributedLease(ctx, store, l.key)
+	if err != nil {
+		return fmt.Errorf("read back lane %d lease: %w", l.doc.Lane, err)
+	}
+	if doc.Token != l.doc.Token {
+		return errDistributedLeaseLost
+	}
+	l.etag = etag
+	return nil
+}
+
+// keepDistributedLease renews the lease every third of its TTL until stop is
+// called. Losing the lease cancels the returned context, which abandons the
+// lane's crawl; stop reports the loss.
+func keepDistributedLease(ctx context.Context, store distributedHubStore, lease *distributedLease) (context.Context, func() error) {
+	laneCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(lease.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-laneCtx.Done():
+				return
+			case <-ticker.C:
+				if err := lease.renew(laneCtx, store); err != nil {
+					if laneCtx.Err() != nil {
+						return
+					}
+					cancel(err)
+					return
+				}
+			}
+		}
+	}()
+	return laneCtx, func() error {
+		lost := context.Cause(laneCtx)
+		cancel(nil)
+		<-done
+		if lost != nil && !errors.Is(lost, context.Canceled) {
+			return lost
+		}
+		return nil
+	}
+}
+
+// --- shared command setup ---
+
+// distributedIndexJob is the identity every distributed step derives from the
+// shared --job manifest.
+type distributedIndexJob struct {
+	manifest   *manifest.IndexManifest
+	identity   *indexstore.IndexSetIdentityResult
+	filters    *indexBuildFilters
+	scopeHash  string
+	basePrefix string
+}
+
+func loadDistributedIndexJob(jobPath string) (*distributedIndexJob, error) {
+	m, err := manifest.LoadIndexManifest(jobPath)
+	if err != nil {
+		return nil, fmt.Errorf("load index manifest: %w", err)
+	}
+	if !strings.HasSuffix(m.Connection.BaseURI, "/") {
+		return nil, fmt.Errorf("connection.base_uri must end with '/': %s", m.Connection.BaseURI)
+	}
+	if m.Connection.Endpoint != "" {
+		if err := validateEndpointURL(m.Connection.Endpoint); err != nil {
+			return nil, fmt.Errorf("connection.endpoint: %w", err)
+		}
+	}
+	if m.Build != nil {
+		switch strings.TrimSpace(m.Build.Source) {
+		case "", manifest.DefaultIndexSource:
+		default:
+			return nil, fmt.Errorf("distributed builds support crawl source only")
+		}
+	}
+	identity := buildEffectiveIdentity(m)
+	if err := validateIdentity(m, identity); err != nil {
+		return nil, err
+	}
+	filters, err := computeIndexBuildFilters(m)
+	if err != nil {
+		return nil, err
+	}
+	scopeHash, err := computeScopeHash(m)
+	if err != nil {
+		return nil, err
+	}
+	identityResult, err := indexstore.ComputeIndexSetID(buildIndexSetParams(m, identity, filters.FiltersHash, scopeHash))
+	if err != nil {
+		return nil, fmt.Errorf("compute index identity: %w", err)
+	}
+	baseBucket, basePrefix, err := parseBaseURIForProvider(m.Connection.BaseURI, m.Connection.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("parse base_uri: %w", err)
+	}
+	if baseBucket != "" && baseBucket != m.Connection.Bucket {
+		return nil, fmt.Errorf("base_uri bucket %q does not match connection.bucket %q", baseBucket, m.Connection.Bucket)
+	}
+	return &distributedIndexJob{
+		manifest:   m,
+		identity:   identityResult,
+		filters:    filters,
+		scopeHash:  scopeHash,
+		basePrefix: basePrefix,
+	}, nil
+}
+
+// checkPlan refuses a plan that was made from a different manifest.
+func (j *distributedIndexJob) checkPlan(plan distributedPlanDoc) error {
+	if plan.IndexSetID != j.identity.IndexSetID || plan.BaseURI != j.manifest.Connection.BaseURI || plan.ScopeHash != j.scopeHash {
+		return fmt.Errorf("distributed plan for %s was not made from this manifest", plan.IndexSetID)
+	}
+	return nil
+}
+
+func (j *distributedIndexJob) openSource(ctx context.Context) (provider.Provider, error) {
+	sourceOpts, err := indexBuildSourceOptions(j.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("create provider: %w", err)
+	}
+	prov, err := newIndexBuildEngineSource(ctx, &uri.ObjectURI{
+		Provider: j.manifest.Connection.Provider,
+		Bucket:   j.manifest.Connection.Bucket,
+	}, sourceOpts)
+	if err != nil {
+		return nil, fmt.Errorf("create provider: %w", err)
+	}
+	return prov, nil
+}
+
+// laneConfig is the crawl half of the Config runIndexBuildDurable would build
+// for this run; publication inputs belong to the finalizer.
+func (j *distributedIndexJob) laneConfig(prov provider.Provider, runID string, crawlPrefixes []string, runStartedAt time.Time) indexbuild.Config {
+	cfg := indexbuild.Config{
+		IndexSetID: j.identity.IndexSetID,
+		RunID:      runID,
+		BaseURI:    j.manifest.Connection.BaseURI,
+		Source: indexbuild.Source{
+			Provider:     prov,
+			ProviderName: j.manifest.Connection.Provider,
+		},
+		Match:            indexBuildEngineMatchConfig(j.manifest),
+		Crawl:            indexBuildEngineCrawlConfig(j.manifest),
+		CrawlKeyRanges:   indexBuildEngineCrawlKeyRanges(j.manifest),
+		CrawlPrefixes:    crawlPrefixes,
+		ObservationSinks: []output.Writer{newStderrProgressWriter(os.Stderr)},
+		RunStartedAt:     runStartedAt,
+		CreatedAt:        runStartedAt,
+	}
+	if j.filters != nil {
+		cfg.Filter = j.filters.Filter
+	}
+	return cfg
+}
+
+func distributedHubFromFlags(cmd *cobra.Command) (*hubDestSpec, error) {
+	return parseHubFlags(cmd, nil)
+}
+
+// --- plan ---
+
+func runIndexDistributedPlan(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	jobPath, _ := cmd.Flags().GetString("job")
+	maxLanes, _ := cmd.Flags().GetInt("lanes")
+	leaseTTL, _ := cmd.Flags().GetDuration("lease-ttl")
+	if leaseTTL < minDistributedLeaseTTL {
+		return fmt.Errorf("--lease-ttl must be at least %s", minDistributedLeaseTTL)
+	}
+	if maxLanes < 0 || maxLanes > indexbuild.MaxJournalLanesCeiling {
+		return fmt.Errorf("--lanes must be between 0 and %d", indexbuild.MaxJournalLanesCeiling)
+	}
+	hub, err := distributedHubFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	job, err := loadDistributedIndexJob(jobPath)
+	if err != nil {
+		return err
+	}
+	store, err := newDistributedHubStore(ctx, hub)
+	if err != nil {
+		return err
+	}
+	prov, err := job.openSource(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = prov.Close() }()
+
+	crawlPrefixes, err := indexBuildEngineCrawlPrefixes(ctx, job.manifest, job.basePrefix, prov)
+	if err != nil {
+		return err
+	}
+	runID := indexstore.NewRunID()
+	runStartedAt := distributedNow()
+	cfg := job.laneConfig(prov, runID, crawlPrefixes, runStartedAt)
+	cfg.MaxJournalLanes = maxLanes
+	if indexBuildBalanceLanes(job.manifest) {
+		// Weights come from this host's prior snapshot, if it has one.
+		cfg.PrefixWeights = indexBuildPriorPrefixWeights(ctx, job.identity.IndexSetID, job.basePrefix, crawlPrefixes, nil)
+	}
+	lanes, err := indexbuild.PlanLanes(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	plan := distributedPlanDoc{
+		Version:         distributedDocVersion,
+		IndexSetID:      job.identity.IndexSetID,
+		RunID:           runID,
+		BaseURI:         job.manifest.Connection.BaseURI,
+		ScopeHash:       job.scopeHash,
+		RunStartedAt:    runStartedAt.Format(time.RFC3339Nano),
+		CrawlPrefixes:   crawlPrefixes,
+		LeaseTTLSeconds: int64(leaseTTL / time.Second),
+		Lanes:           distributedLaneDocs(lanes),
+		CreatedAt:       distributedNow().Format(time.RFC3339Nano),
+		CreatedBy:       exportedByString(),
+	}
+	if err := publishDistributedPlan(ctx, store, hub, plan); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Published distributed plan\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  index_set_id: %s\n", plan.IndexSetID)
+	_, _ = fmt.Fprintf(os.Stderr, "  run_id: %s\n", plan.RunID)
+	for _, ln := range lanes {
+		_, _ = fmt.Fprintf(os.Stderr, "  lane %d: plan_entries=%d predicted_objects=%d\n", ln.Ordinal, len(ln.Prefixes), ln.PredictedObjects)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), runID)
+	return nil
+}
+
+// --- work ---
+
+func runIndexDistributedWork(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stopSignals := withInterruptCancel(ctx)
+	defer stopSignals()
+
+	jobPath, _ := cmd.Flags().GetString("job")
+	runID, _ := cmd.Flags().GetString("run-id")
+	holder, _ := cmd.Flags().GetString("worker-id")
+	maxLanes, _ := cmd.Flags().GetInt("max-lanes")
+	if strings.TrimSpace(holder) == "" {
+		host, _ := os.Hostname()
+		holder = fmt.Sprintf("%s-%d", valueOrDefault(host, "worker"), os.Getpid())
+	}
+	hub, err := distributedHubFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	job, err := loadDistributedIndexJob(jobPath)
+	if err != nil {
+		return err
+	}
+	store, err := newDistributedHubStore(ctx, hub)
+	if err != nil {
+		return err
+	}
+	plan, err := readDistributedPlan(ctx, store, hub, job.identity.IndexSetID, strings.TrimSpace(runID))
+	if err != nil {
+		return err
+	}
+	if err := job.checkPlan(plan); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	prov, err := job.openSource(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = prov.Close() }()
+
+	completed, err := workDistributedLanes(ctx, store, hub, job, prov, plan, holder, maxLanes)
+	_, _ = fmt.Fprintf(os.Stderr, "Worker %s completed %d lane(s) of %s\n", holder, completed, plan.RunID)
+	return err
+}
+
+// workDistributedLanes makes one pass over the plan, crawling every lane it can
+// claim, and returns how many lanes this worker completed.
+func workDistributedLanes(ctx context.Context, store distributedHubStore, hub *hubDestSpec, job *distributedIndexJob, prov provider.Provider, plan distributedPlanDoc, holder string, maxLanes int) (int, error) {
+	runStartedAt, err := plan.runStartedAt()
+	if err != nil {
+		return 0, err
+	}
+	cfg := job.laneConfig(prov, plan.RunID, plan.CrawlPrefixes, runStartedAt)
+	lanePlans := plan.lanePlans()
+	completed := 0
+	for _, ln := range plan.Lanes {
+		if maxLanes > 0 && completed >= maxLanes {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return completed, err
+		}
+		if _, done, err := readDistributedDone(ctx, store, hub, plan, ln.Ordinal); err != nil {
+			return completed, err
+		} else if done {
+			continue
+		}
+		lease, ok, err := claimDistributedLane(ctx, store, hub, plan, ln.Ordinal, holder)
+		if err != nil {
+			return completed, err
+		}
+		if !ok {
+			continue
+		}
+		wrote, err := crawlDistributedLane(ctx, store, hub, cfg, lanePlans, lease)
+		if errors.Is(err, errDistributedLeaseLost) {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: lane %d: lease taken over by another worker; abandoning it\n", ln.Ordinal)
+			continue
+		}
+		if err != nil {
+			return completed, fmt.Errorf("lane %d: %w", ln.Ordinal, err)
+		}
+		if wrote {
+			completed++
+		}
+	}
+	return completed, nil
+}
+
+// crawlDistributedLane crawls a claimed lane under a renewed lease, uploads its
+// sealed journal, and marks it done. wrote=false means another holder's done
+// marker landed first, which leaves the lane equally complete.
+func crawlDistributedLane(ctx context.Context, store distributedHubStore, hub *hubDestSpec, cfg indexbuild.Config, plan []indexbuild.LanePlan, lease *distributedLease) (bool, error) {
+	ordinal := lease.doc.Lane
+	runDir, err := indexSubstrateJournalRunDir(cfg.IndexSetID, cfg.RunID)
+	if err != nil {
+		return false, err
+	}
+	journalDir := filepath.Join(runDir, "lane-"+lease.doc.Token)
+	defer func() { _ = os.RemoveAll(journalDir) }()
+
+	laneCtx, stop := keepDistributedLease(ctx, store, lease)
+	journalPath, summary, crawlErr := indexbuild.CrawlLane(laneCtx, cfg, plan, ordinal, journalDir)
+	if lost := stop(); lost != nil {
+		return false, lost
+	}
+	if crawlErr != nil {
+		return false, crawlErr
+	}
+
+	journalKey := distributedLaneKey(hub, cfg.IndexSetID, cfg.RunID, ordinal, "journal-"+lease.doc.Token+".jsonl")
+	digest, size, err := hashFile(journalPath)
+	if err != nil {
+		return false, fmt.Errorf("hash journal: %w", err)
+	}
+	if err := uploadDistributedJournal(ctx, store, journalKey, journalPath, size); err != nil {
+		return false, err
+	}
+	// Confirm the lease is still ours before claiming the lane. Past this point
+	// a takeover can only race to the same done marker, and exactly one wins.
+	if err := lease.renew(ctx, store); err != nil {
+		return false, err
+	}
+	done := distributedDoneDoc{
+		Version:         distributedDocVersion,
+		IndexSetID:      cfg.IndexSetID,
+		RunID:           cfg.RunID,
+		Lane:            ordinal,
+		Holder:          lease.doc.Holder,
+		Token:           lease.doc.Token,
+		JournalKey:      journalKey,
+		SizeBytes:       size,
+		SHA256:          digest,
+		ListedObjects:   summary.ListedObjects,
+		ObservedObjects: summary.ObservedObjects,
+		CompletedAt:     distributedNow().Format(time.RFC3339Nano),
+	}
+	data, err := json.MarshalIndent(done, "", "  ")
+	if err != nil {
+		return false, err
+	}
+	doneKey := distributedLaneKey(hub, cfg.IndexSetID, cfg.RunID, ordinal, "done.json")
+	if _, err := store.PutObjectConditional(ctx, doneKey, bytes.NewReader(data), int64(len(data)), provider.PutPrecondition{IfAbsent: true}); err != nil {
+		if provider.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("write done.json: %w", err)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "lane %d: done observed_objects=%d\n", ordinal, summary.ObservedObjects)
+	return true, nil
+}
+
+func uploadDistributedJournal(ctx context.Context, store distributedHubStore, key, path string, size int64) error {
+	f, err := os.Open(path) // #nosec G304 -- path is the sealed journal this worker just wrote.
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := store.PutObjectConditional(ctx, key, f, size, provider.PutPrecondition{IfAbsent: true}); err != nil {
+		return fmt.Errorf("upload journal: %w", err)
+	}
+	return nil
+}
+
+// --- status ---
+
+type distributedLaneStatus struct {
+	Lane             int    `json:"lane"`
+	State            string `json:"state"`
+	PlanEntries      int    `json:"plan_entries"`
+	PredictedObjects int64  `json:"predicted_objects,omitempty"`
+	Holder           string `json:"holder,omitempty"`
+	ExpiresAt        string `json:"expires_at,omitempty"`
+	ObservedObjects  int64  `json:"observed_objects,omitempty"`
+}
+
+const (
+	distributedLaneDone    = "done"
+	distributedLaneLeased  = "leased"
+	distributedLaneExpired = "expired"
+	distributedLanePending = "pending"
+)
+
+func distributedLaneStatuses(ctx context.Context, store distributedHubStore, hub *hubDestSpec, plan distributedPlanDoc) ([]distributedLaneStatus, error) {
+	now := distributedNow()
+	out := make([]distributedLaneStatus, 0, len(plan.Lanes))
+	for _, ln := range plan.Lanes {
+		st := distributedLaneStatus{Lane: ln.Ordinal, State: distributedLanePending, PlanEntries: len(ln.Prefixes), PredictedObjects: ln.PredictedObjects}
+		done, ok, err := readDistributedDone(ctx, store, hub, plan, ln.Ordinal)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			st.State = distributedLaneDone
+			st.Holder = done.Holder
+			st.ObservedObjects = done.ObservedObjects
+			out = append(out, st)
+			continue
+		}
+		lease, _, err := readDistributedLease(ctx, store, distributedLaneKey(hub, plan.IndexSetID, plan.RunID, ln.Ordinal, "lease.json"))
+		switch {
+		case provider.IsNotFound(err):
+		case err != nil:
+			return nil, fmt.Errorf("read lane %d lease: %w", ln.Ordinal, err)
+		default:
+			st.State = distributedLaneLeased
+			if leaseExpired(lease, now) {
+				st.State = distributedLaneExpired
+			}
+			st.Holder = lease.Holder
+			st.ExpiresAt = lease.ExpiresAt
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+func runIndexDistributedStatus(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	indexSetID, _ := cmd.Flags().GetString("index-set")
+	runID, _ := cmd.Flags().GetString("run-id")
+	jsonOut, _ := cmd.Flags().GetBool("json")
+	hub, err := distributedHubFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	store, err := newDistributedHubStore(ctx, hub)
+	if err != nil {
+		return err
+	}
+	plan, err := readDistributedPlan(ctx, store, hub, strings.TrimSpace(indexSetID), strings.TrimSpace(runID))
+	if err != nil {
+		return err
+	}
+	statuses, err := distributedLaneStatuses(ctx, store, hub, plan)
+	if err != nil {
+		return err
+	}
+	if jsonOut {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "LANE\tSTATE\tPLAN_ENTRIES\tPREDICTED\tOBSERVED\tHOLDER\tEXPIRES_AT")
+	for _, st := range statuses {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%s\t%s\n", st.Lane, st.State, st.PlanEntries, st.PredictedObjects, st.ObservedObjects, valueOrDefault(st.Holder, "-"), valueOrDefault(st.ExpiresAt, "-"))
+	}
+	return tw.Flush()
+}
+
+// --- finalize ---
+
+func runIndexDistributedFinalize(cmd *cobra.Command, _ []string) (runErr error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stopSignals := withInterruptCancel(ctx)
+	defer stopSignals()
+
+	jobPath, _ := cmd.Flags().GetString("job")
+	runID, _ := cmd.Flags().GetString("run-id")
+	jsonOut, _ := cmd.Flags().GetBool("json")
+	hub, err := distributedHubFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	job, err := loadDistributedIndexJob(jobPath)
+	if err != nil {
+		return err
+	}
+	store, err := newDistributedHubStore(ctx, hub)
+	if err != nil {
+		return err
+	}
+	plan, err := readDistributedPlan(ctx, store, hub, job.identity.IndexSetID, strings.TrimSpace(runID))
+	if err != nil {
+		return err
+	}
+	if err := job.checkPlan(plan); err != nil {
+		return err
+	}
+	runStartedAt, err := plan.runStartedAt()
+	if err != nil {
+		return err
+	}
+	dones := make([]distributedDoneDoc, 0, len(plan.Lanes))
+	var missing []string
+	for _, ln := range plan.Lanes {
+		done, ok, err := readDistributedDone(ctx, store, hub, plan, ln.Ordinal)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%d", ln.Ordinal))
+			continue
+		}
+		dones = append(dones, done)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("distributed run %s is not complete; unfinished lanes: %s", plan.RunID, strings.Join(missing, ", "))
+	}
+	cmd.SilenceUsage = true
+
+	maintenance, err := acquireIndexSetMaintenance(ctx, plan.IndexSetID, "index-distributed-"+uuid.NewString())
+	if err != nil {
+		return fmt.Errorf("acquire index-set maintenance lease: %w", err)
+	}
+	defer releaseAuthorityInto(&runErr, maintenance)
+	ctx = maintenance.Context()
+
+	journalDir, err := indexSubstrateJournalRunDir(plan.IndexSetID, plan.RunID)
+	if err != nil {
+		return err
+	}
+	journalPaths := make([]string, 0, len(dones))
+	for _, done := range dones {
+		path := filepath.Join(journalDir, fmt.Sprintf("lane-%04d.jsonl", done.Lane))
+		if err := downloadDistributedJournal(ctx, store, done, path); err != nil {
+			return fmt.Errorf("lane %d: %w", done.Lane, err)
+		}
+		journalPaths = append(journalPaths, path)
+	}
+
+	resolvedDB, err := resolveIndexDBPath("", job.identity)
+	if err != nil {
+		return err
+	}
+	segmentRoot, err := indexSubstrateSegmentCacheDir(plan.IndexSetID)
+	if err != nil {
+		return err
+	}
+	if resolvedDB.Canonical {
+		if err := publishIndexBuildCanonicalIdentity(ctx, resolvedDB, segmentRoot, job.identity, job.manifest, maintenance); err != nil {
+			return err
+		}
+	}
+	coverage, err := indexBuildEngineCoverageFromCrawl(job.basePrefix, plan.CrawlPrefixes)
+	if err != nil {
+		return err
+	}
+	runSegmentDir := filepath.Join(segmentRoot, "runs", plan.RunID)
+	summary, err := indexbuild.Retry(ctx, indexbuild.RetryConfig{
+		IndexSetID:        plan.IndexSetID,
+		RunID:             plan.RunID,
+		BaseURI:           plan.BaseURI,
+		Paths:             indexBuildEnginePathConfig(journalDir, runSegmentDir, segmentRoot, plan.RunID, resolvedDB.IdentityDir),
+		JournalPaths:      journalPaths,
+		Coverage:          coverage,
+		RunStartedAt:      runStartedAt,
+		CreatedAt:         distributedNow(),
+		Authority:         maintenance.Authority(),
+		OnSegmentProgress: newStderrSegmentProgress(os.Stderr),
+		Events:            metrics.IndexBuildEventSink{Labels: indexBuildMetricLabels(job.manifest)},
+	})
+	if err != nil {
+		return err
+	}
+	summary.Lanes = distributedLaneSummaries(plan, dones)
+	// Retry publishes from journals alone; the lane workers recorded what
+	// their crawls listed and observed.
+	for _, done := range dones {
+		summary.ObjectsListed += done.ListedObjects
+		summary.ObjectsObserved += done.ObservedObjects
+	}
+	metrics.RecordIndexBuildSummary(indexBuildMetricLabels(job.manifest), summary)
+
+	_, _ = fmt.Fprintf(os.Stderr, "\nDistributed index build finalized\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  format: durable\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  run_id: %s\n", summary.RunID)
+	_, _ = fmt.Fprintf(os.Stderr, "  index_set_id: %s\n", summary.IndexSetID)
+	_, _ = fmt.Fprintf(os.Stderr, "  objects_observed: %d\n", summary.ObjectsObserved)
+	_, _ = fmt.Fprintf(os.Stderr, "  objects_listed: %d\n", summary.ObjectsListed)
+	if summary.Manifest.Selector != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "  filtered: true\n")
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "  segments: %d\n", len(summary.Manifest.Segments))
+	for _, ln := range summary.Lanes {
+		_, _ = fmt.Fprintf(os.Stderr, "  lane %d: plan_entries=%d predicted_objects=%d listed_objects=%d observed_objects=%d\n", ln.Ordinal, ln.PlanEntries, ln.PredictedObjects, ln.ListedObjects, ln.ObservedObjects)
+	}
+	if jsonOut {
+		return emitIndexBuildResultJSON(cmd.OutOrStdout(), newDurableBuildResultRecord(summary, job.scopeHash, "durable", []string{"durable-v2"}))
+	}
+	return nil
+}
+
+func distributedLaneSummaries(plan distributedPlanDoc, dones []distributedDoneDoc) []indexbuild.LaneSummary {
+	if len(plan.Lanes) < 2 {
+		return nil
+	}
+	observed := make(map[int]distributedDoneDoc, len(dones))
+	for _, done := range dones {
+		observed[done.Lane] = done
+	}
+	out := make([]indexbuild.LaneSummary, len(plan.Lanes))
+	for i, ln := range plan.Lanes {
+		out[i] = indexbuild.LaneSummary{
+			Ordinal:          ln.Ordinal,
+			PlanEntries:      len(ln.Prefixes),
+			PredictedObjects: ln.PredictedObjects,
+			ListedObjects:    observed[ln.Ordinal].ListedObjects,
+			ObservedObjects:  observed[ln.Ordinal].ObservedObjects,
+		}
+	}
+	return out
+}
+
+// downloadDistributedJournal fetches a lane journal and refuses it unless its
+// size and digest match what the completing worker recorded.
+func downloadDistributedJournal(ctx context.Context, store distributedHubStore, done distributedDoneDoc, destPath string) error {
+	body, size, err := store.GetObject(ctx, done.JournalKey)
+	if err != nil {
+		return fmt.Errorf("download journal: %w", err)
+	}
+	defer func() { _ = body.Close() }()
+	if size >= 0 && size != done.SizeBytes {
+		return fmt.Errorf("journal size %d does not match done.json size %d", size, done.SizeBytes)
+	}
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return fmt.Errorf("create journal directory: %w", err)
+	}
+	f, err := os.Create(destPath) // #nosec G304 -- destPath is under the app-data journal run directory.
+	if err != nil {
+		return fmt.Errorf("create journal: %w", err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(body, done.SizeBytes+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && (n != done.SizeBytes || hex.EncodeToString(h.Sum(nil)) != done.SHA256) {
+		err = fmt.Errorf("journal does not match the digest recorded in done.json")
+	}
+	if err != nil {
+		_ = os.Remove(destPath)
+		return err
+	}
+	return nil
+}
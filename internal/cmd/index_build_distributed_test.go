@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+func withDistributedTestClock(t *testing.T, now time.Time) *time.Time {
+	t.Helper()
+	clock := now
+	old := distributedNow
+	distributedNow = func() time.Time { return clock }
+	t.Cleanup(func() { distributedNow = old })
+	return &clock
+}
+
+func TestDistributedLaneLeaseExpiresAndIsTakenOver(t *testing.T) {
+	clock := withDistributedTestClock(t, time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC))
+	hub, err := parseHubURI("file://" + t.TempDir() + "/")
+	require.NoError(t, err)
+	store, err := newDistributedHubStore(context.Background(), hub)
+	require.NoError(t, err)
+	plan := distributedPlanDoc{IndexSetID: "idx_test", RunID: "run_1", LeaseTTLSeconds: 60}
+
+	a, ok, err := claimDistributedLane(context.Background(), store, hub, plan, 1, "host-a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = claimDistributedLane(context.Background(), store, hub, plan, 1, "host-b")
+	require.NoError(t, err)
+	require.False(t, ok, "a live lease is not claimable")
+
+	*clock = clock.Add(45 * time.Second)
+	require.NoError(t, a.renew(context.Background(), store))
+	*clock = clock.Add(45 * time.Second)
+	_, ok, err = claimDistributedLane(context.Background(), store, hub, plan, 1, "host-b")
+	require.NoError(t, err)
+	require.False(t, ok, "renewal pushed expiry out")
+
+	*clock = clock.Add(time.Minute)
+	b, ok, err := claimDistributedLane(context.Background(), store, hub, plan, 1, "host-b")
+	require.NoError(t, err)
+	require.True(t, ok, "an expired lease is taken over")
+	require.NotEqual(t, a.doc.Token, b.doc.Token)
+	require.ErrorIs(t, a.renew(context.Background(), store), errDistributedLeaseLost)
+	require.NoError(t, b.renew(context.Background(), store))
+}
+
+// TestIndexDistributedPlanWorkFinalizePublishesOneRun drives two workers over
+// a file hub, each taking one lane, and finalizes them into one durable run.
+func TestIndexDistributedPlanWorkFinalizePublishesOneRun(t *testing.T) {
+	resetAppDataRootTestState(t)
+	t.Setenv("GONIMBUS_DATA_DIR", filepath.Join(t.TempDir(), "gonimbus-data"))
+	hubURI := "file://" + t.TempDir() + "/"
+	base := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`
+version: "1.0"
+connection:
+  provider: s3
+  bucket: bucket
+  base_uri: s3://bucket/data/
+identity:
+  storage_provider: aws_s3
+build:
+  source: crawl
+  scope:
+    type: prefix_list
+    prefixes: ["a/", "b/", "c/"]
+  match:
+    includes: ["**"]
+`), 0o600))
+
+	var objects []provider.ObjectSummary
+	for _, prefix := range []string{"a/", "b/", "c/"} {
+		for _, name := range []string{"1.xml", "2.xml"} {
+			objects = append(objects, provider.ObjectSummary{Key: "data/" + prefix + name, Size: 1, ETag: `"e"`, LastModified: base, StorageClass: "STANDARD"})
+		}
+	}
+	oldSource := newIndexBuildEngineSource
+	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
+		return &countingIndexBuildProvider{objects: objects}, nil
+	}
+	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
+
+	out, err := runDistributedTestCmd(t, runIndexDistributedPlan, "--job", manifestPath, "--hub", hubURI, "--lanes", "2")
+	require.NoError(t, err)
+	runID := strings.TrimSpace(out)
+	require.True(t, strings.HasPrefix(runID, "run_"), runID)
+
+	job, err := loadDistributedIndexJob(manifestPath)
+	require.NoError(t, err)
+	indexSetID := job.identity.IndexSetID
+	statuses := func() []distributedLaneStatus {
+		t.Helper()
+		out, err := runDistributedTestCmd(t, runIndexDistributedStatus, "--hub", hubURI, "--index-set", indexSetID, "--run-id", runID, "--json")
+		require.NoError(t, err)
+		var got []distributedLaneStatus
+		require.NoError(t, json.Unmarshal([]byte(out), &got))
+		return got
+	}
+	require.Equal(t, []string{distributedLanePending, distributedLanePending}, laneStates(statuses()))
+
+	_, err = runDistributedTestCmd(t, runIndexDistributedWork, "--job", manifestPath, "--hub", hubURI, "--run-id", runID, "--worker-id", "host-a", "--max-lanes", "1")
+	require.NoError(t, err)
+	require.Equal(t, []string{distributedLaneDone, distributedLanePending}, laneStates(statuses()))
+
+	_, err = runDistributedTestCmd(t, runIndexDistributedFinalize, "--job", manifestPath, "--hub", hubURI, "--run-id", runID)
+	require.ErrorContains(t, err, "unfinished lanes: 2")
+
+	_, err = runDistributedTestCmd(t, runIndexDistributedWork, "--job", manifestPath, "--hub", hubURI, "--run-id", runID, "--worker-id", "host-b")
+	require.NoError(t, err)
+	got := statuses()
+	require.Equal(t, []string{distributedLaneDone, distributedLaneDone}, laneStates(got))
+	require.Equal(t, "host-a", got[0].Holder)
+	require.Equal(t, "host-b", got[1].Holder)
+
+	out, err = runDistributedTestCmd(t, runIndexDistributedFinalize, "--job", manifestPath, "--hub", hubURI, "--run-id", runID, "--json")
+	require.NoError(t, err)
+	var rec indexBuildResultRecord
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(out)), &rec))
+	require.Equal(t, "success", rec.Status)
+	require.Equal(t, indexSetID, rec.IndexSetID)
+	require.Equal(t, runID, rec.RunID)
+	require.Equal(t, 6, *rec.ActiveRows)
+	require.Equal(t, []indexBuildLaneRecord{
+		{Ordinal: 1, PlanEntries: 2, ListedObjects: 4, ObservedObjects: 4},
+		{Ordinal: 2, PlanEntries: 1, ListedObjects: 2, ObservedObjects: 2},
+	}, rec.Lanes)
+}
+
+func TestIndexDistributedFinalizeRefusesTamperedJournal(t *testing.T) {
+	hub, err := parseHubURI("file://" + t.TempDir() + "/")
+	require.NoError(t, err)
+	store, err := newDistributedHubStore(context.Background(), hub)
+	require.NoError(t, err)
+	key := "index-sets/idx_test/distributed/run_1/lanes/0001/journal-x.jsonl"
+	require.NoError(t, uploadBytes(context.Background(), store.(provider.ObjectPutter), key, []byte("tampered\n")))
+
+	dest := filepath.Join(t.TempDir(), "lane-0001.jsonl")
+	err = downloadDistributedJournal(context.Background(), store, distributedDoneDoc{
+		JournalKey: key,
+		SizeBytes:  9,
+		SHA256:     strings.Repeat("0", 64),
+	}, dest)
+	require.ErrorContains(t, err, "digest")
+	require.NoFileExists(t, dest)
+}
+
+func laneStates(statuses []distributedLaneStatus) []string {
+	out := make([]string, len(statuses))
+	for i, st := range statuses {
+		out[i] = st.State
+	}
+	return out
+}
+
+func runDistributedTestCmd(t *testing.T, run func(*cobra.Command, []string) error, args ...string) (string, error) {
+	t.Helper()
+	cmd := &cobra.Command{Use: "distributed", RunE: run, SilenceUsage: true, SilenceErrors: true}
+	addHubTestFlags(cmd)
+	cmd.Flags().String("job", "", "")
+	cmd.Flags().String("run-id", "", "")
+	cmd.Flags().Int("lanes", 0, "")
+	cmd.Flags().Duration("lease-ttl", defaultDistributedLeaseTTL, "")
+	cmd.Flags().String("worker-id", "", "")
+	cmd.Flags().Int("max-lanes", 0, "")
+	cmd.Flags().String("index-set", "", "")
+	cmd.Flags().Bool("json", false, "")
+	cmd.SetArgs(args)
+	cmd.SetContext(context.Background())
+	var stdout strings.Builder
+	cmd.SetOut(&stdout)
+	err := cmd.Execute()
+	return stdout.String(), err
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/pkg/indexbuild"
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+	"github.com/3leaps/gonimbus/pkg/opcheckpoint"
+)
+
+// indexBuildCheckpointFormatDurable marks an index build checkpoint written by
+// the durable path. Checkpoints without a format predate it and are SQLite.
+const indexBuildCheckpointFormatDurable = "durable"
+
+// indexBuildDurableCheckpoint is the durable run state an index build
+// checkpoint carries beside the manifest. Lane completion itself lives in the
+// engine's lane checkpoint beside the run's journals; this records what the
+// resume must present to it.
+type indexBuildDurableCheckpoint struct {
+	RunStartedAt time.Time `json:"run_started_at"`
+	PlanDigest   string    `json:"plan_digest"`
+	Lanes        int       `json:"lanes"`
+	SealedLanes  int       `json:"sealed_lanes"`
+}
+
+func indexBuildCheckpointFormat(payload indexBuildCheckpointPayload) string {
+	if payload.Format == "" {
+		return "sqlite"
+	}
+	return payload.Format
+}
+
+// writeFailedResumableDurableIndexBuildCheckpoint records a failed durable
+// build as resumable when its lane checkpoint survives and resuming it would
+// save work: the failure was itself resumable, or at least one lane sealed. An
+// auth denial is never resumable; the same credentials would fail again.
+//
+// resumable is false, with a nil error, when the run is not resumable.
+func writeFailedResumableDurableIndexBuildCheckpoint(
+	ctx context.Context,
+	indexSetID string,
+	run *indexBuildDurableRun,
+	cfg indexBuildCheckpointConfig,
+	buildErr error,
+) (class opcheckpoint.ErrorClass, progress map[string]int64, resumable bool, err error) {
+	classification := classifyIndexBuildRunError(buildErr, nil)
+	if classification.Class == opcheckpoint.ErrorClassAuthDenied || !indexBuildCheckpointEligible(cfg) {
+		return classification.Class, nil, false, nil
+	}
+	journalDir, err := indexSubstrateJournalRunDir(indexSetID, run.RunID)
+	if err != nil {
+		return classification.Class, nil, false, err
+	}
+	state, ok, err := indexbuild.ReadResumeState(journalDir)
+	if err != nil {
+		return classification.Class, nil, false, fmt.Errorf("read lane checkpoint: %w", err)
+	}
+	if !ok || (!classification.Resumable && state.SealedLanes == 0) {
+		return classification.Class, nil, false, nil
+	}
+	cfg.IndexSetID = indexSetID
+	payload := indexBuildCheckpointPayload{
+		Format:        indexBuildCheckpointFormatDurable,
+		Config:        cfg,
+		CrawlPrefixes: append([]string(nil), run.CrawlPrefixes...),
+		Durable: &indexBuildDurableCheckpoint{
+			RunStartedAt: state.RunStartedAt,
+			PlanDigest:   state.PlanDigest,
+			Lanes:        state.Lanes,
+			SealedLanes:  state.SealedLanes,
+		},
+	}
+	progress = indexBuildDurableProgress(payload)
+	if err := bindIndexBuildCrawlPrefixes(&payload.Config, payload.CrawlPrefixes); err != nil {
+		return classification.Class, progress, false, fmt.Errorf("bind crawl prefix plan: %w", err)
+	}
+	fingerprint, err := checkpointFingerprint(payload.Config)
+	if err != nil {
+		return classification.Class, progress, false, err
+	}
+	opStore, err := openDefaultOperationCheckpointStore(ctx)
+	if err != nil {
+		return classification.Class, progress, false, fmt.Errorf("open operation checkpoint store: %w", err)
+	}
+	// A durable run has no index_run row; the engine's run ledger records it.
+	if err := writeIndexRunCheckpoint(ctx, opStore, nil, run.RunID, operationIndexBuild, fingerprint, classification.Class, progress, payload); err != nil {
+		return classification.Class, progress, false, err
+	}
+	return classification.Class, progress, true, nil
+}
+
+func indexBuildDurableProgress(payload indexBuildCheckpointPayload) map[string]int64 {
+	progress := map[string]int64{}
+	if payload.Durable != nil {
+		progress["lanes"] = int64(payload.Durable.Lanes)
+		progress["sealed_lanes"] = int64(payload.Durable.SealedLanes)
+	}
+	if len(payload.CrawlPrefixes) > 0 {
+		progress["crawl_prefixes"] = int64(len(payload.CrawlPrefixes))
+	}
+	return progress
+}
+
+// runIndexBuildDurableResume resumes a failed-resumable durable build from its
+// checkpointed manifest. The engine reuses every lane that sealed and lists the
+// rest; it refuses when the plan the manifest now compiles to differs from the
+// one the run recorded.
+func runIndexBuildDurableResume(ctx context.Context, cmd *cobra.Command, opStore *opcheckpoint.Store, env *opcheckpoint.Envelope, payload indexBuildCheckpointPayload) (runErr error) {
+	runID := env.RunID
+	if payload.Durable == nil {
+		return fmt.Errorf("durable resume checkpoint for run %s does not carry its run state", runID)
+	}
+	if env.Status != opcheckpoint.StatusFailedResumable {
+		return fmt.Errorf("index build run %s is not a failed-resumable durable run", runID)
+	}
+	if !payload.Config.UsesDefaultIndexDB {
+		return fmt.Errorf("--resume-run %s is not supported for non-default index database paths in this slice", runID)
+	}
+	if err := validateIndexBuildCrawlPrefixes(payload.Config, payload.CrawlPrefixes); err != nil {
+		return err
+	}
+	fingerprint, err := checkpointFingerprint(payload.Config)
+	if err != nil {
+		return err
+	}
+	if err := opStore.ValidateIdentity(env, opcheckpoint.Identity{
+		Operation:         operationIndexBuild,
+		RunID:             runID,
+		ConfigFingerprint: fingerprint,
+	}); err != nil {
+		return err
+	}
+
+	m := &payload.Config.Manifest
+	identity := effectiveIdentityFromCheckpoint(payload.Config.Identity)
+	if err := validateIdentity(m, identity); err != nil {
+		return err
+	}
+	oldScopeWarnPrefix := indexBuildScopeWarnPrefix
+	oldScopeMaxPrefix := indexBuildScopeMaxPrefix
+	indexBuildScopeWarnPrefix = payload.Config.ScopeWarnPrefixes
+	indexBuildScopeMaxPrefix = payload.Config.ScopeMaxPrefixes
+	defer func() {
+		indexBuildScopeWarnPrefix = oldScopeWarnPrefix
+		indexBuildScopeMaxPrefix = oldScopeMaxPrefix
+	}()
+	buildFilters, err := computeIndexBuildFilters(m)
+	if err != nil {
+		return err
+	}
+	// The checkpointed manifest must still derive the index set the run belongs
+	// to; a tampered manifest would otherwise resume into another set's lanes.
+	scopeHash, err := computeScopeHash(m)
+	if err != nil {
+		return err
+	}
+	identityResult, err := indexstore.ComputeIndexSetID(buildIndexSetParams(m, identity, buildFilters.FiltersHash, scopeHash))
+	if err != nil {
+		return fmt.Errorf("compute index identity: %w", err)
+	}
+	if identityResult.IndexSetID != payload.Config.IndexSetID {
+		return opcheckpoint.ErrIdentityMismatch
+	}
+	spillResolution, err := resolveIndexBuildSpill()
+	if err != nil {
+		return err
+	}
+	indexBuildSpillResolved = spillResolution
+
+	maintenance, err := acquireIndexSetMaintenance(ctx, identityResult.IndexSetID, "index-build-resume-"+uuid.NewString())
+	if err != nil {
+		return fmt.Errorf("acquire index-set maintenance lease: %w", err)
+	}
+	defer func() { releaseAuthorityInto(&runErr, maintenance) }()
+	ctx = maintenance.Context()
+
+	lease, err := opStore.ClaimLease(ctx, operationIndexBuild, runID, "gonimbus-"+uuid.NewString(), resumeLeaseTTL)
+	if err != nil {
+		return err
+	}
+	heartbeat, leaseCtx, err := startResumeLeaseHeartbeat(ctx, opStore, operationIndexBuild, lease)
+	if err != nil {
+		return err
+	}
+	ctx = leaseCtx
+	defer func() {
+		_ = heartbeat.Stop()
+		_ = opStore.ReleaseLease(operationIndexBuild, *lease)
+	}()
+
+	resolvedDB, err := resolveIndexDBPath("", identityResult)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	run := &indexBuildDurableRun{
+		RunID:         runID,
+		RunStartedAt:  payload.Durable.RunStartedAt,
+		CrawlPrefixes: append([]string(nil), payload.CrawlPrefixes...),
+		Resume:        true,
+	}
+	summary, _, buildErr := runIndexBuildDurable(ctx, m, identityResult, buildFilters, resolvedDB, maintenance.Authority(), run)
+	if buildErr != nil {
+		if err := stopResumeLeaseHeartbeatBeforeFailedResumableCheckpoint(heartbeat); err != nil {
+			return fmt.Errorf("index build resume failed: %w", err)
+		}
+		class, progress, resumable, checkpointErr := writeFailedResumableDurableIndexBuildCheckpoint(context.Background(), identityResult.IndexSetID, run, payload.Config, buildErr)
+		switch {
+		case checkpointErr != nil:
+			buildErr = fmt.Errorf("%w; write operation checkpoint: %v", buildErr, checkpointErr)
+		case resumable:
+			writeOperationErrorSummary(cmd.ErrOrStderr(), "Index build resume failed with resumable checkpoint", operationIndexBuild, runID, class, progress)
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			if emitErr := emitOperationErrorRecord(context.Background(), enc, operationIndexBuild, runID, class, progress); emitErr != nil {
+				buildErr = fmt.Errorf("%w; write operation error record: %v", buildErr, emitErr)
+			}
+		}
+		return fmt.Errorf("index build resume failed: %w", buildErr)
+	}
+	if err := stopResumeLeaseHeartbeat(heartbeat); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "\nIndex build resume completed\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  format: durable\n")
+	_, _ = fmt.Fprintf(os.Stderr, "  run_id: %s\n", runID)
+	_, _ = fmt.Fprintf(os.Stderr, "  index_set_id: %s\n", summary.IndexSetID)
+	_, _ = fmt.Fprintf(os.Stderr, "  reused_lanes: %d\n", summary.ReusedLanes)
+	_, _ = fmt.Fprintf(os.Stderr, "  objects_observed: %d\n", summary.ObjectsObserved)
+	_, _ = fmt.Fprintf(os.Stderr, "  objects_listed: %d\n", summary.ObjectsListed)
+	_, _ = fmt.Fprintf(os.Stderr, "  segments: %d\n", len(summary.Manifest.Segments))
+
+	env.Status = opcheckpoint.StatusSuccess
+	env.Progress = map[string]int64{
+		"objects_observed": summary.ObjectsObserved,
+		"objects_listed":   summary.ObjectsListed,
+		"reused_lanes":     int64(summary.ReusedLanes),
+	}
+	env.Events = append(env.Events, opcheckpoint.CheckpointEvent{Type: "resume_completed", At: time.Now().UTC()})
+	if err := opStore.WriteCheckpoint(context.Background(), *env); err != nil {
+		return fmt.Errorf("write completed checkpoint: %w", err)
+	}
+	return nil
+}
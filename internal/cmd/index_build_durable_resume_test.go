@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/opcheckpoint"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+// prefixFailingIndexBuildProvider fails LIST under failPrefix until healed and
+// counts the LISTs each prefix receives.
+type prefixFailingIndexBuildProvider struct {
+	countingIndexBuildProvider
+	failPrefix string
+
+	mu     sync.Mutex
+	healed bool
+	lists  map[string]int
+}
+
+func (p *prefixFailingIndexBuildProvider) List(ctx context.Context, opts provider.ListOptions) (*provider.ListResult, error) {
+	p.mu.Lock()
+	if p.lists == nil {
+		p.lists = map[string]int{}
+	}
+	p.lists[opts.Prefix]++
+	failing := !p.healed && strings.HasPrefix(opts.Prefix, p.failPrefix)
+	p.mu.Unlock()
+	if failing {
+		return nil, errors.New("listing stalled")
+	}
+	return p.countingIndexBuildProvider.List(ctx, opts)
+}
+
+func (p *prefixFailingIndexBuildProvider) heal() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healed = true
+	p.lists = map[string]int{}
+}
+
+func (p *prefixFailingIndexBuildProvider) listed(prefix string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lists[prefix]
+}
+
+// TestIndexBuildDurableResumeRunListsOnlyIncompleteLanes drives the printed
+// operator path end to end: a durable build whose one lane fails records a
+// failed-resumable checkpoint, and `--resume-run` lists only that lane before
+// publishing.
+func TestIndexBuildDurableResumeRunListsOnlyIncompleteLanes(t *testing.T) {
+	resetAppDataRootTestState(t)
+	t.Setenv("GONIMBUS_DATA_DIR", filepath.Join(t.TempDir(), "gonimbus-data"))
+	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`
+version: "1.0"
+connection:
+  provider: s3
+  bucket: bucket
+  base_uri: s3://bucket/data/
+identity:
+  storage_provider: aws_s3
+build:
+  source: crawl
+  scope:
+    type: prefix_list
+    prefixes: ["a/", "b/", "c/"]
+  match:
+    includes: ["**"]
+  crawl:
+    concurrency: 3
+`), 0o600))
+
+	restore := withIndexBuildExperimentalEngineTestState(t)
+	restore()
+	indexBuildJobPath = manifestPath
+	indexBuildFormat = "durable"
+
+	var objects []provider.ObjectSummary
+	for _, prefix := range []string{"a/", "b/", "c/"} {
+		objects = append(objects, provider.ObjectSummary{Key: "data/" + prefix + "x.xml", Size: 1, ETag: `"e"`, LastModified: base, StorageClass: "STANDARD"})
+	}
+	prov := &prefixFailingIndexBuildProvider{countingIndexBuildProvider: countingIndexBuildProvider{objects: objects}, failPrefix: "data/b/"}
+	oldSource := newIndexBuildEngineSource
+	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
+		return prov, nil
+	}
+	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
+
+	cmd := &cobra.Command{Use: "build"}
+	cmd.SetContext(context.Background())
+	var stdout, stderr strings.Builder
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	err := runIndexBuild(cmd, nil)
+	require.ErrorIs(t, err, errIndexBuildFailedResumable)
+
+	var rec opcheckpoint.ErrorRecord
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(stdout.String())), &rec))
+	require.Equal(t, operationIndexBuild, rec.Data.Operation)
+	require.Equal(t, int64(3), rec.Data.Progress["lanes"])
+	require.Equal(t, int64(2), rec.Data.Progress["sealed_lanes"])
+	runID := rec.Data.RunID
+	require.NotEmpty(t, runID)
+
+	prov.heal()
+	indexBuildJobPath = ""
+	indexBuildResumeRun = runID
+	cmd = &cobra.Command{Use: "build"}
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	require.NoError(t, runIndexBuild(cmd, nil))
+	require.Equal(t, 1, prov.listed("data/b/"), "the failed lane is listed again")
+	require.Zero(t, prov.listed("data/a/"), "a sealed lane is reused")
+	require.Zero(t, prov.listed("data/c/"), "a sealed lane is reused")
+
+	opStore, err := openDefaultOperationCheckpointStore(context.Background())
+	require.NoError(t, err)
+	env, err := opStore.ReadCheckpoint(context.Background(), operationIndexBuild, runID)
+	require.NoError(t, err)
+	require.Equal(t, opcheckpoint.StatusSuccess, env.Status)
+	require.Equal(t, int64(2), env.Progress["reused_lanes"])
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/3leaps/gonimbus/internal/indexcompare"
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/internal/metrics"
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
 	"github.com/3leaps/gonimbus/pkg/crawler"
 	"github.com/3leaps/gonimbus/pkg/indexbuild"
@@ -135,12 +136,19 @@ func runIndexBuildBothFormats(ctx context.Context, m *manifest.IndexManifest, db
 		// Crawl progress already flows via sqliteWriter; segmenting tail is
 		// after the crawl and needs an explicit observational hook.
 		OnSegmentProgress: newStderrSegmentProgress(os.Stderr),
+		Events:            metrics.IndexBuildEventSink{Labels: indexBuildMetricLabels(m)},
+	}
+	if indexBuildJobEvents != nil {
+		cfg.ObservationSinks = append(cfg.ObservationSinks, indexBuildJobEvents)
 	}
 	emitIndexBuildSpillDiagnostics(os.Stderr, indexBuildSpillResolved)
 	if buildFilters != nil {
 		cfg.Filter = buildFilters.Filter
 	}
 	summary, err := runIndexBuildEngine(ctx, cfg)
+	if err == nil {
+		metrics.RecordIndexBuildSummary(indexBuildMetricLabels(m), summary)
+	}
 	out.Summary = summary
 	out.Result = sqliteWriter.Result()
 	out.Result.CrawlPrefixes = append([]string(nil), crawlPrefixes...)
@@ -174,15 +182,35 @@ func runIndexBuildBothFormats(ctx context.Context, m *manifest.IndexManifest, db
 	return out, nil
 }
 
-func runIndexBuildDurable(ctx context.Context, m *manifest.IndexManifest, identityResult *indexstore.IndexSetIdentityResult, buildFilters *indexBuildFilters, resolvedDB resolvedIndexDB, authority *indexcoord.Lease) (indexbuild.Summary, string, error) {
+// indexBuildDurableRun is the run a durable build executes as: a fresh run, or
+// one resumed from the lane checkpoint of its failed attempt.
+type indexBuildDurableRun struct {
+	RunID        string
+	RunStartedAt time.Time
+	// CrawlPrefixes is the run's crawl plan. A fresh run compiles it from the
+	// manifest scope and records it here for its checkpoint; a resumed run
+	// supplies the recorded plan instead of recompiling, so a prefix that
+	// appeared since the failure cannot change what its sealed lanes attest.
+	CrawlPrefixes []string
+	Resume        bool
+}
+
+func newIndexBuildDurableRun() *indexBuildDurableRun {
+	// Match SQLite/hub run-id contract: run_<digits> (not UUID-with-hyphens).
+	return &indexBuildDurableRun{RunID: indexstore.NewRunID(), RunStartedAt: time.Now().UTC()}
+}
+
+func runIndexBuildDurable(ctx context.Context, m *manifest.IndexManifest, identityResult *indexstore.IndexSetIdentityResult, buildFilters *indexBuildFilters, resolvedDB resolvedIndexDB, authority *indexcoord.Lease, run *indexBuildDurableRun) (indexbuild.Summary, string, error) {
 	if m == nil {
 		return indexbuild.Summary{}, "", fmt.Errorf("index manifest is required")
 	}
 	if identityResult == nil {
 		return indexbuild.Summary{}, "", fmt.Errorf("index identity is required")
 	}
-	// Match SQLite/hub run-id contract: run_<digits> (not UUID-with-hyphens).
-	runID := indexstore.NewRunID()
+	if run == nil {
+		return indexbuild.Summary{}, "", fmt.Errorf("index build run is required")
+	}
+	runID := run.RunID
 	baseBucket, basePrefix, err := parseBaseURIForProvider(m.Connection.BaseURI, m.Connection.Provider)
 	if err != nil {
 		return indexbuild.Summary{}, "", fmt.Errorf("parse base_uri: %w", err)
@@ -218,16 +246,25 @@ func runIndexBuildDurable(ctx context.Context, m *manifest.IndexManifest, identi
 	// Durable-only canonical metadata was already committed through the
 	// library-owned retained guard before provider construction.
 
-	crawlPrefixes, err := indexBuildEngineCrawlPrefixes(ctx, m, basePrefix, prov)
-	if err != nil {
-		return indexbuild.Summary{}, "", err
+	crawlPrefixes := run.CrawlPrefixes
+	if !run.Resume {
+		crawlPrefixes, err = indexBuildEngineCrawlPrefixes(ctx, m, basePrefix, prov)
+		if err != nil {
+			return indexbuild.Summary{}, "", err
+		}
+		run.CrawlPrefixes = crawlPrefixes
 	}
 	coverage, err := indexBuildEngineCoverageFromCrawl(basePrefix, crawlPrefixes)
 	if err != nil {
 		return indexbuild.Summary{}, "", err
 	}
 
-	now := time.Now().UTC()
+	var prefixWeights map[string]int64
+	// A resumed run keeps its recorded lane assignment, so weights would go unused.
+	if indexBuildBalanceLanes(m) && !run.Resume {
+		prefixWeights = indexBuildPriorPrefixWeights(ctx, identityResult.IndexSetID, basePrefix, crawlPrefixes, authority)
+	}
+
 	cfg := indexbuild.Config{
 		IndexSetID: identityResult.IndexSetID,
 		RunID:      runID,
@@ -236,21 +273,28 @@ func runIndexBuildDurable(ctx context.Context, m *manifest.IndexManifest, identi
 			Provider:     prov,
 			ProviderName: m.Connection.Provider,
 		},
-		Match:         indexBuildEngineMatchConfig(m),
-		Filter:        nil,
-		Crawl:         indexBuildEngineCrawlConfig(m),
-		CrawlPrefixes: crawlPrefixes,
+		Match:          indexBuildEngineMatchConfig(m),
+		Filter:         nil,
+		Crawl:          indexBuildEngineCrawlConfig(m),
+		CrawlKeyRanges: indexBuildEngineCrawlKeyRanges(m),
+		CrawlPrefixes:  crawlPrefixes,
+		PrefixWeights:  prefixWeights,
 		// Progress-only sink: journalWriter no-ops progress; durable-only had
 		// no ObservationSinks and ran silent. Do not reuse indexIngestWriter.
 		ObservationSinks:     []output.Writer{newStderrProgressWriter(os.Stderr)},
 		Paths:                indexBuildEnginePathConfig(journalDir, runSegmentDir, segmentRoot, runID, resolvedDB.IdentityDir),
 		Coverage:             coverage,
 		Authority:            authority,
-		RunStartedAt:         now,
-		CreatedAt:            now,
+		Resume:               run.Resume,
+		RunStartedAt:         run.RunStartedAt,
+		CreatedAt:            run.RunStartedAt,
 		TargetRowsPerSegment: 0,
 		Spill:                indexbuild.SpillConfig{WorkspaceBytes: indexBuildSpillResolved.WorkspaceBytes, RecordBytes: indexBuildSpillResolved.RecordBytes, Root: indexBuildSpillResolved.Root},
 		OnSegmentProgress:    newStderrSegmentProgress(os.Stderr),
+		Events:               metrics.IndexBuildEventSink{Labels: indexBuildMetricLabels(m)},
+	}
+	if indexBuildJobEvents != nil {
+		cfg.ObservationSinks = append(cfg.ObservationSinks, indexBuildJobEvents)
 	}
 	emitIndexBuildSpillDiagnostics(os.Stderr, indexBuildSpillResolved)
 	if buildFilters != nil {
@@ -260,10 +304,19 @@ func runIndexBuildDurable(ctx context.Context, m *manifest.IndexManifest, identi
 	if err != nil {
 		return indexbuild.Summary{}, "", err
 	}
+	metrics.RecordIndexBuildSummary(indexBuildMetricLabels(m), summary)
 	emitIndexBuildSpillCompletion(os.Stderr, indexBuildSpillResolved, summary.PeakWorkspaceBytes)
 	return summary, resolvedDB.IdentityDir, nil
 }
 
+func indexBuildMetricLabels(m *manifest.IndexManifest) metrics.JobLabels {
+	return metrics.JobLabels{
+		Provider: m.Connection.Provider,
+		Bucket:   m.Connection.Bucket,
+		JobType:  metrics.JobTypeIndexBuild,
+	}
+}
+
 func validateIndexBuildFormatFlags(resumeRun string) error {
 	// resumeRun is unused: resume is validated/dispatched before format validation.
 	_ = resumeRun
@@ -315,9 +368,9 @@ func validateIndexBuildFormatManifest(m *manifest.IndexManifest) error {
 		return nil
 	case "durable", "both":
 		flagName := "--format " + format
-		// build.scope is allowed: it compiles to an explicit LIST prefix plan.
-		// Match predicates that drop objects inside a covered prefix stay rejected
-		// via validateIndexBuildDurableFullBaseMatch (faithful coverage).
+		// build.scope compiles to an explicit LIST prefix plan. Match predicates
+		// and filters are recorded by the engine as the snapshot's observation
+		// selector, so coverage attests the selected objects under that plan.
 		if m != nil && m.Build != nil {
 			switch strings.TrimSpace(m.Build.Source) {
 			case "", manifest.DefaultIndexSource:
@@ -326,42 +379,12 @@ func validateIndexBuildFormatManifest(m *manifest.IndexManifest) error {
 				return fmt.Errorf("%s supports crawl source only", flagName)
 			}
 		}
-		return validateIndexBuildDurableFullBaseMatch(m, flagName)
+		return nil
 	default:
 		return fmt.Errorf("--format must be one of: durable, sqlite, both")
 	}
 }
 
-func validateIndexBuildDurableFullBaseMatch(m *manifest.IndexManifest, flagName string) error {
-	if m == nil || m.Build == nil || m.Build.Match == nil {
-		return nil
-	}
-	matchCfg := m.Build.Match
-	if len(matchCfg.Excludes) > 0 {
-		return fmt.Errorf("%s does not support build.match.excludes in this slice", flagName)
-	}
-	if matchCfg.Filters != nil {
-		return fmt.Errorf("%s does not support build.match.filters in this slice", flagName)
-	}
-	if matchCfg.IncludeHidden {
-		return fmt.Errorf("%s does not support build.match.include_hidden=true in this slice", flagName)
-	}
-	if !isDefaultIndexBuildIncludes(matchCfg.Includes) {
-		return fmt.Errorf("%s supports only default build.match.includes %q in this slice", flagName, manifest.DefaultIndexIncludes)
-	}
-	return nil
-}
-
-func isDefaultIndexBuildIncludes(includes []string) bool {
-	if len(includes) == 0 {
-		return true
-	}
-	if len(includes) != 1 {
-		return false
-	}
-	return includes[0] == manifest.DefaultIndexIncludes
-}
-
 func selectedIndexBuildFormat() string {
 	if indexBuildExperimentalEngine {
 		// Hidden alias forces the durable path for one compatibility cycle.
@@ -405,6 +428,13 @@ func indexBuildEngineCrawlConfig(m *manifest.IndexManifest) crawler.Config {
 	return cfg
 }
 
+func indexBuildEngineCrawlKeyRanges(m *manifest.IndexManifest) int {
+	if m == nil || m.Build == nil || m.Build.Crawl == nil {
+		return 0
+	}
+	return m.Build.Crawl.KeyRanges
+}
+
 func indexBuildEngineCrawlPrefixes(ctx context.Context, m *manifest.IndexManifest, basePrefix string, prov provider.Provider) ([]string, error) {
 	if m != nil && m.Build != nil && m.Build.Scope != nil {
 		var lister provider.PrefixLister
@@ -125,20 +125,83 @@ func TestIndexBuildFormatDurableRejectsDBFlag(t *testing.T) {
 	require.ErrorContains(t, err, "does not use --db")
 }
 
-func TestIndexBuildFormatDurableRejectsIncludeHidden(t *testing.T) {
+func TestIndexBuildFormatDurableAcceptsObservationSelector(t *testing.T) {
 	restore := withIndexBuildExperimentalEngineTestState(t)
 	restore()
+	cases := map[string]*manifest.IndexMatchConfig{
+		"excludes":       {Includes: []string{"**"}, Excludes: []string{"**/_temporary/**"}},
+		"include_hidden": {Includes: []string{"**"}, IncludeHidden: true},
+		"non-prefix":     {Includes: []string{"**/*.parquet"}},
+		"filters":        {Includes: []string{"**"}, Filters: &manifest.FilterConfig{Size: &manifest.SizeFilterConfig{Min: "1KB"}}},
+	}
+	for _, format := range []string{"durable", "both"} {
+		for name, matchCfg := range cases {
+			t.Run(format+"/"+name, func(t *testing.T) {
+				indexBuildFormat = format
+				m := &manifest.IndexManifest{Build: &manifest.IndexBuildConfig{Match: matchCfg}}
+				require.NoError(t, validateIndexBuildFormatManifest(m))
+			})
+		}
+	}
+}
+
+// TestIndexBuildFormatDurableFilteredBuildRecordsSelector proves the command
+// path builds a durable snapshot under excludes and a size filter, keeps only
+// the selected rows, and stamps the selector into the published coverage.
+func TestIndexBuildFormatDurableFilteredBuildRecordsSelector(t *testing.T) {
+	resetAppDataRootTestState(t)
+	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
+	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
+	base := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`
+version: "1.0"
+connection:
+  provider: s3
+  bucket: bucket
+  base_uri: s3://bucket/data/
+identity:
+  storage_provider: aws_s3
+build:
+  source: crawl
+  match:
+    includes: ["**"]
+    excludes: ["**/_temporary/**"]
+    filters:
+      size:
+        min: "11B"
+  crawl:
+    concurrency: 1
+    progress_every: 100
+`), 0o600))
+
+	restore := withIndexBuildExperimentalEngineTestState(t)
+	restore()
+	indexBuildJobPath = manifestPath
 	indexBuildFormat = "durable"
-	m := &manifest.IndexManifest{
-		Build: &manifest.IndexBuildConfig{
-			Match: &manifest.IndexMatchConfig{
-				Includes:      []string{"**"},
-				IncludeHidden: true,
-			},
-		},
+
+	objects := append(indexBuildEngineTestObjects(base),
+		provider.ObjectSummary{Key: "data/_temporary/c.xml", Size: 50, ETag: `"c"`, LastModified: base.Add(-time.Minute), StorageClass: "STANDARD"})
+	oldSource := newIndexBuildEngineSource
+	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
+		return indexBuildEngineFakeProvider{objects: objects}, nil
 	}
-	err := validateIndexBuildFormatManifest(m)
-	require.ErrorContains(t, err, "include_hidden")
+	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
+
+	cmd := &cobra.Command{Use: "build"}
+	cmd.SetContext(context.Background())
+	require.NoError(t, runIndexBuild(cmd, nil))
+
+	latestFiles, err := filepath.Glob(filepath.Join(dataRoot, "cache", "segments", "*", "latest.json"))
+	require.NoError(t, err)
+	require.Len(t, latestFiles, 1)
+	manifestSummary, rows, err := indexbuild.ReadLatest(latestFiles[0])
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, "b.xml", rows[0].RelKey)
+	require.NotNil(t, manifestSummary.Selector)
+	require.Equal(t, []string{"**/_temporary/**"}, manifestSummary.Selector.Excludes)
+	require.Contains(t, manifestSummary.Selector.Filter, "size_min=11")
 }
 
 func TestIndexBuildFormatBothAllowsDBFlag(t *testing.T) {
@@ -934,49 +997,6 @@ build:
 	require.Equal(t, []string{"cold/", "hot/"}, got)
 }
 
-func TestIndexBuildFormatBothRejectsScopePlusMatchExcludes(t *testing.T) {
-	// Scope-relax must not open the match-predicate door.
-	resetAppDataRootTestState(t)
-	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
-	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
-	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
-	require.NoError(t, os.WriteFile(manifestPath, []byte(`
-version: "1.0"
-connection:
-  provider: s3
-  bucket: bucket
-  base_uri: s3://bucket/data/
-identity:
-  storage_provider: aws_s3
-build:
-  source: crawl
-  scope:
-    type: prefix_list
-    prefixes: ["hot/"]
-  match:
-    includes: ["**"]
-    excludes: ["tmp/**"]
-`), 0o600))
-
-	restore := withIndexBuildExperimentalEngineTestState(t)
-	restore()
-	indexBuildJobPath = manifestPath
-	indexBuildFormat = "both"
-
-	prov := &countingIndexBuildProvider{}
-	oldSource := newIndexBuildEngineSource
-	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
-		return prov, nil
-	}
-	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
-
-	cmd := &cobra.Command{Use: "build"}
-	cmd.SetContext(context.Background())
-	err := runIndexBuild(cmd, nil)
-	require.ErrorContains(t, err, "--format both does not support build.match.excludes in this slice")
-	require.Zero(t, prov.listCallCount())
-}
-
 func TestIndexBuildEngineCoverageFaithfulSetEquality(t *testing.T) {
 	// Happy path: multi-prefix plan → exact coverage.
 	coverage, err := indexBuildEngineCoverageFromCrawl("data/", []string{"data/hot/", "data/cold/"})
@@ -1094,221 +1114,6 @@ build:
 	require.Nil(t, manifestDoc.Coverage[0].Scope.Window)
 }
 
-func TestIndexBuildFormatBothRejectsNarrowMatchIncludesBeforeDurablePublish(t *testing.T) {
-	resetAppDataRootTestState(t)
-	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
-	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
-	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
-	require.NoError(t, os.WriteFile(manifestPath, []byte(`
-version: "1.0"
-connection:
-  provider: s3
-  bucket: bucket
-  base_uri: s3://bucket/data/
-identity:
-  storage_provider: aws_s3
-build:
-  source: crawl
-  match:
-    includes: ["hot/**"]
-`), 0o600))
-
-	restore := withIndexBuildExperimentalEngineTestState(t)
-	restore()
-	indexBuildJobPath = manifestPath
-	indexBuildFormat = "both"
-
-	prov := &countingIndexBuildProvider{}
-	oldSource := newIndexBuildEngineSource
-	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
-		return prov, nil
-	}
-	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
-
-	cmd := &cobra.Command{Use: "build"}
-	cmd.SetContext(context.Background())
-	err := runIndexBuild(cmd, nil)
-	require.ErrorContains(t, err, `--format both supports only default build.match.includes "**" in this slice`)
-	require.Zero(t, prov.listCallCount())
-
-	latestFiles, globErr := filepath.Glob(filepath.Join(dataRoot, "cache", "segments", "*", "latest.json"))
-	require.NoError(t, globErr)
-	require.Empty(t, latestFiles)
-}
-
-func TestIndexBuildFormatBothRejectsWhitespacePaddedDefaultIncludeBeforeDurablePublish(t *testing.T) {
-	resetAppDataRootTestState(t)
-	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
-	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
-	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
-	require.NoError(t, os.WriteFile(manifestPath, []byte(`
-version: "1.0"
-connection:
-  provider: s3
-  bucket: bucket
-  base_uri: s3://bucket/data/
-identity:
-  storage_provider: aws_s3
-build:
-  source: crawl
-  match:
-    includes: [" ** "]
-`), 0o600))
-
-	restore := withIndexBuildExperimentalEngineTestState(t)
-	restore()
-	indexBuildJobPath = manifestPath
-	indexBuildFormat = "both"
-
-	prov := &countingIndexBuildProvider{}
-	oldSource := newIndexBuildEngineSource
-	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
-		return prov, nil
-	}
-	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
-
-	cmd := &cobra.Command{Use: "build"}
-	cmd.SetContext(context.Background())
-	err := runIndexBuild(cmd, nil)
-	require.ErrorContains(t, err, `--format both supports only default build.match.includes "**" in this slice`)
-	require.Zero(t, prov.listCallCount())
-
-	latestFiles, globErr := filepath.Glob(filepath.Join(dataRoot, "cache", "segments", "*", "latest.json"))
-	require.NoError(t, globErr)
-	require.Empty(t, latestFiles)
-}
-
-func TestIndexBuildFormatBothRejectsMatchExcludesBeforeDurablePublish(t *testing.T) {
-	resetAppDataRootTestState(t)
-	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
-	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
-	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
-	require.NoError(t, os.WriteFile(manifestPath, []byte(`
-version: "1.0"
-connection:
-  provider: s3
-  bucket: bucket
-  base_uri: s3://bucket/data/
-identity:
-  storage_provider: aws_s3
-build:
-  source: crawl
-  match:
-    includes: ["**"]
-    excludes: ["tmp/**"]
-`), 0o600))
-
-	restore := withIndexBuildExperimentalEngineTestState(t)
-	restore()
-	indexBuildJobPath = manifestPath
-	indexBuildFormat = "both"
-
-	prov := &countingIndexBuildProvider{}
-	oldSource := newIndexBuildEngineSource
-	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
-		return prov, nil
-	}
-	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
-
-	cmd := &cobra.Command{Use: "build"}
-	cmd.SetContext(context.Background())
-	err := runIndexBuild(cmd, nil)
-	require.ErrorContains(t, err, "--format both does not support build.match.excludes in this slice")
-	require.Zero(t, prov.listCallCount())
-
-	latestFiles, globErr := filepath.Glob(filepath.Join(dataRoot, "cache", "segments", "*", "latest.json"))
-	require.NoError(t, globErr)
-	require.Empty(t, latestFiles)
-}
-
-func TestIndexBuildFormatBothRejectsMatchFiltersBeforeDurablePublish(t *testing.T) {
-	resetAppDataRootTestState(t)
-	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
-	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
-	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
-	require.NoError(t, os.WriteFile(manifestPath, []byte(`
-version: "1.0"
-connection:
-  provider: s3
-  bucket: bucket
-  base_uri: s3://bucket/data/
-identity:
-  storage_provider: aws_s3
-build:
-  source: crawl
-  match:
-    includes: ["**"]
-    filters:
-      size:
-        min: "1B"
-`), 0o600))
-
-	restore := withIndexBuildExperimentalEngineTestState(t)
-	restore()
-	indexBuildJobPath = manifestPath
-	indexBuildFormat = "both"
-
-	prov := &countingIndexBuildProvider{}
-	oldSource := newIndexBuildEngineSource
-	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
-		return prov, nil
-	}
-	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
-
-	cmd := &cobra.Command{Use: "build"}
-	cmd.SetContext(context.Background())
-	err := runIndexBuild(cmd, nil)
-	require.ErrorContains(t, err, "--format both does not support build.match.filters in this slice")
-	require.Zero(t, prov.listCallCount())
-
-	latestFiles, globErr := filepath.Glob(filepath.Join(dataRoot, "cache", "segments", "*", "latest.json"))
-	require.NoError(t, globErr)
-	require.Empty(t, latestFiles)
-}
-
-func TestIndexBuildExperimentalEngineRejectsNarrowMatchBeforeDurablePublish(t *testing.T) {
-	resetAppDataRootTestState(t)
-	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
-	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
-	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
-	require.NoError(t, os.WriteFile(manifestPath, []byte(`
-version: "1.0"
-connection:
-  provider: s3
-  bucket: bucket
-  base_uri: s3://bucket/data/
-identity:
-  storage_provider: aws_s3
-build:
-  source: crawl
-  match:
-    includes: ["**"]
-    excludes: ["tmp/**"]
-`), 0o600))
-
-	restore := withIndexBuildExperimentalEngineTestState(t)
-	restore()
-	indexBuildJobPath = manifestPath
-	indexBuildExperimentalEngine = true
-
-	prov := &countingIndexBuildProvider{}
-	oldSource := newIndexBuildEngineSource
-	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
-		return prov, nil
-	}
-	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
-
-	cmd := &cobra.Command{Use: "build"}
-	cmd.SetContext(context.Background())
-	err := runIndexBuild(cmd, nil)
-	require.ErrorContains(t, err, "--format durable does not support build.match.excludes in this slice")
-	require.Zero(t, prov.listCallCount())
-
-	latestFiles, globErr := filepath.Glob(filepath.Join(dataRoot, "cache", "segments", "*", "latest.json"))
-	require.NoError(t, globErr)
-	require.Empty(t, latestFiles)
-}
-
 func TestIndexBuildFormatBothRejectsExperimentalEngineFlag(t *testing.T) {
 	restore := withIndexBuildExperimentalEngineTestState(t)
 	restore()
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/reflow"
+)
+
+// indexBuildJobEvents is the managed child's events.jsonl sink, or nil outside
+// managed mode. `gonimbus serve` streams the file at
+// GET /api/v1/jobs/{id}/events.
+var indexBuildJobEvents *jobEventsWriter
+
+// indexBuildJobEventsSink returns indexBuildJobEvents as an output.Writer, or a
+// nil interface outside managed mode so callers can test it against nil.
+func indexBuildJobEventsSink() output.Writer {
+	if indexBuildJobEvents == nil {
+		return nil
+	}
+	return indexBuildJobEvents
+}
+
+// jobEventsWriter appends a managed job's progress, error, and summary records
+// to its events.jsonl in the output.Record envelope. Object-level record types
+// are dropped: the file is a progress feed, not a listing.
+//
+// Writes are best-effort like stderrProgressWriter: a full disk or removed job
+// directory must not fail the build it is observing.
+type jobEventsWriter struct {
+	mu    sync.Mutex
+	f     *os.File
+	jobID string
+	out   *output.JSONLWriter
+}
+
+func openJobEventsWriter(store *jobregistry.Store, jobID string) (*jobEventsWriter, error) {
+	f, err := store.OpenEvents(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &jobEventsWriter{f: f, jobID: jobID, out: output.NewJSONLWriter(f, jobID, "")}, nil
+}
+
+// bindProvider stamps later records with the provider once the manifest is
+// loaded.
+func (w *jobEventsWriter) bindProvider(provider string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.out = output.NewJSONLWriter(w.f, w.jobID, provider)
+}
+
+func (w *jobEventsWriter) writer() *output.JSONLWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out
+}
+
+func (w *jobEventsWriter) WriteObject(context.Context, *output.ObjectRecord) error {
+	return nil
+}
+
+func (w *jobEventsWriter) WriteError(ctx context.Context, rec *output.ErrorRecord) error {
+	if rec == nil {
+		return nil
+	}
+	sanitized := *rec
+	sanitized.Message = reflow.SanitizeOperationCauseMessage(errors.New(rec.Message))
+	_ = w.writer().WriteError(ctx, &sanitized)
+	return nil
+}
+
+func (w *jobEventsWriter) WriteProgress(ctx context.Context, prog *output.ProgressRecord) error {
+	if prog == nil {
+		return nil
+	}
+	_ = w.writer().WriteProgress(ctx, prog)
+	return nil
+}
+
+func (w *jobEventsWriter) WriteSummary(ctx context.Context, sum *output.SummaryRecord) error {
+	if sum == nil {
+		return nil
+	}
+	_ = w.writer().WriteSummary(ctx, sum)
+	return nil
+}
+
+func (w *jobEventsWriter) WritePrefix(context.Context, *output.PrefixRecord) error {
+	return nil
+}
+
+func (w *jobEventsWriter) WritePreflight(context.Context, *output.PreflightRecord) error {
+	return nil
+}
+
+func (w *jobEventsWriter) WriteTransfer(context.Context, *output.TransferRecord) error {
+	return nil
+}
+
+func (w *jobEventsWriter) WriteSkip(context.Context, *output.SkipRecord) error {
+	return nil
+}
+
+// Close is a no-op so the engine's sink teardown leaves the file open for the
+// terminal error record; closeFile releases it when the command returns.
+func (w *jobEventsWriter) Close() error { return nil }
+
+func (w *jobEventsWriter) closeFile() error {
+	if w == nil {
+		return nil
+	}
+	return w.f.Close()
+}
+
+var _ output.Writer = (*jobEventsWriter)(nil)
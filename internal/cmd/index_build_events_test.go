@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+func TestJobEventsWriterKeepsProgressErrorAndSummaryOnly(t *testing.T) {
+	store := jobregistry.NewStore(filepath.Join(t.TempDir(), "jobs"))
+	jobID := "66666666-6666-4666-8666-666666666666"
+	w, err := openJobEventsWriter(store, jobID)
+	require.NoError(t, err)
+	w.bindProvider("s3")
+
+	ctx := context.Background()
+	require.NoError(t, w.WriteObject(ctx, &output.ObjectRecord{Key: "private/key.csv"}))
+	require.NoError(t, w.WriteProgress(ctx, &output.ProgressRecord{Phase: "listing", ObjectsFound: 3}))
+	require.NoError(t, w.WriteError(ctx, &output.ErrorRecord{
+		Code:    output.ErrCodeAccessDenied,
+		Message: "GET https://x.example/o?X-Amz-Signature=sentinel failed",
+	}))
+	require.NoError(t, w.WriteSummary(ctx, &output.SummaryRecord{ObjectsFound: 3}))
+	require.NoError(t, w.Close())
+	require.NoError(t, w.closeFile())
+
+	f, err := os.Open(filepath.Join(store.JobDir(jobID), jobregistry.EventsFileName))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var types []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		require.NotContains(t, scanner.Text(), "sentinel")
+		require.NotContains(t, scanner.Text(), "private/key.csv")
+		var rec output.Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		require.Equal(t, jobID, rec.JobID)
+		require.Equal(t, "s3", rec.Provider)
+		types = append(types, rec.Type)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{output.TypeProgress, output.TypeError, output.TypeSummary}, types)
+}
+
+func TestIndexBuildManagedFormatsStreamJobEvents(t *testing.T) {
+	for _, format := range []string{"durable", "sqlite"} {
+		t.Run(format, func(t *testing.T) {
+			resetAppDataRootTestState(t)
+			t.Setenv("GONIMBUS_DATA_DIR", filepath.Join(t.TempDir(), "gonimbus-data"))
+			base := time.Date(2026, 7, 9, 15, 0, 0, 0, time.UTC)
+			manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+			require.NoError(t, os.WriteFile(manifestPath, []byte(`
+version: "1.0"
+connection:
+  provider: s3
+  bucket: bucket
+  base_uri: s3://bucket/data/
+identity:
+  storage_provider: aws_s3
+build:
+  source: crawl
+  match:
+    includes: ["**"]
+  crawl:
+    concurrency: 1
+    progress_every: 1
+`), 0o600))
+
+			restore := withIndexBuildExperimentalEngineTestState(t)
+			restore()
+			indexBuildJobPath = manifestPath
+			indexBuildFormat = format
+
+			oldSource := newIndexBuildEngineSource
+			newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
+				return indexBuildEngineFakeProvider{objects: indexBuildEngineTestObjects(base)}, nil
+			}
+			t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
+
+			store := jobregistry.NewStore(filepath.Join(t.TempDir(), "jobs"))
+			jobID := "77777777-7777-4777-8777-777777777777"
+			events, err := openJobEventsWriter(store, jobID)
+			require.NoError(t, err)
+			indexBuildJobEvents = events
+			t.Cleanup(func() {
+				_ = events.closeFile()
+				indexBuildJobEvents = nil
+			})
+
+			cmd := &cobra.Command{Use: "build"}
+			cmd.SetContext(context.Background())
+			cmd.SetOut(io.Discard)
+			require.NoError(t, runIndexBuild(cmd, nil))
+
+			data, err := os.ReadFile(filepath.Join(store.JobDir(jobID), jobregistry.EventsFileName))
+			require.NoError(t, err)
+			seen := map[string]int{}
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				var rec output.Record
+				require.NoError(t, json.Unmarshal([]byte(line), &rec))
+				require.Equal(t, jobID, rec.JobID)
+				seen[rec.Type]++
+			}
+			require.Positive(t, seen[output.TypeProgress], "events.jsonl must carry progress for a managed %s build", format)
+			require.Equal(t, 1, seen[output.TypeSummary], "events.jsonl must carry the run summary for a managed %s build", format)
+		})
+	}
+}
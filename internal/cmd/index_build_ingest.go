@@ -71,6 +71,10 @@ type indexIngestWriter struct {
 	// Scope violation tracking (guardrail)
 	scopeViolationCount int64
 
+	// events receives progress, error, and summary records for a managed
+	// job's events.jsonl; nil outside managed mode.
+	events output.Writer
+
 	// Mutex for concurrent safety (crawler may call from multiple goroutines)
 	mu sync.Mutex
 }
@@ -81,6 +85,7 @@ type indexIngestWriterConfig struct {
 	PrefixBatchSize int
 	DeltaReport     bool
 	DeltaPrefixes   []string
+	Events          output.Writer
 }
 
 // newIndexIngestWriter creates a streaming ingest writer.
@@ -123,6 +128,7 @@ func newIndexIngestWriter(
 		deltaReport:     cfg.DeltaReport,
 		deltaByPrefix:   make(map[string]indexBuildDeltaCounts),
 		deltaPrefixes:   deltaPrefixes,
+		events:          cfg.Events,
 	}
 }
 
@@ -213,6 +219,9 @@ func (w *indexIngestWriter) WriteError(ctx context.Context, errRec *output.Error
 	defer w.mu.Unlock()
 
 	w.errorCount++
+	if w.events != nil {
+		_ = w.events.WriteError(ctx, errRec)
+	}
 
 	// Record structured events based on error code
 	switch errRec.Code {
@@ -255,10 +264,14 @@ func (w *indexIngestWriter) WriteError(ctx context.Context, errRec *output.Error
 }
 
 // WriteProgress emits human-readable progress to stderr.
-func (w *indexIngestWriter) WriteProgress(_ context.Context, prog *output.ProgressRecord) error {
+func (w *indexIngestWriter) WriteProgress(ctx context.Context, prog *output.ProgressRecord) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if w.events != nil {
+		_ = w.events.WriteProgress(ctx, prog)
+	}
+
 	prefix := prog.Prefix
 	if prefix == "" {
 		prefix = "(root)"
@@ -274,8 +287,11 @@ func (w *indexIngestWriter) WriteProgress(_ context.Context, prog *output.Progre
 	return nil
 }
 
-// WriteSummary is a no-op for index ingestion.
-func (w *indexIngestWriter) WriteSummary(_ context.Context, _ *output.SummaryRecord) error {
+// WriteSummary forwards the crawl summary to the job events sink, if any.
+func (w *indexIngestWriter) WriteSummary(ctx context.Context, sum *output.SummaryRecord) error {
+	if w.events != nil {
+		_ = w.events.WriteSummary(ctx, sum)
+	}
 	return nil
 }
 
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/pkg/indexcoord"
+	"github.com/3leaps/gonimbus/pkg/indexreader"
+	"github.com/3leaps/gonimbus/pkg/manifest"
+)
+
+func indexBuildBalanceLanes(m *manifest.IndexManifest) bool {
+	return m != nil && m.Build != nil && m.Build.Crawl != nil && m.Build.Crawl.BalanceLanes
+}
+
+// errNoPriorPrefixStats reports a prior snapshot published without per-prefix
+// aggregates, so there is nothing to weight lanes by.
+var errNoPriorPrefixStats = errors.New("prior snapshot has no prefix stats")
+
+// indexBuildPriorPrefixWeights predicts each crawl-plan prefix's object count
+// from the previous snapshot's per-prefix aggregates, for weighted lane
+// planning.
+//
+// Counts come from the prefix-stats artifact of the set's latest durable-v2
+// snapshot; object rows are not scanned. A SQLite index has no usable
+// aggregates: builds never fill its prefix_stats table. A plan prefix the
+// aggregates cannot answer (not a directory, or folded into a truncated
+// ancestor) is left out and planned at the mean. Balancing is advisory, so
+// every failure — no prior index, a SQLite one, no aggregates, an unreadable
+// one — degrades to unweighted planning with a warning and never fails the
+// build.
+func indexBuildPriorPrefixWeights(ctx context.Context, indexSetID, basePrefix string, crawlPrefixes []string, authority *indexcoord.Lease) map[string]int64 {
+	if len(crawlPrefixes) < 2 {
+		return nil
+	}
+	reader, err := openIndexReaderWithAuthority(ctx, "", indexSetID, "", authority)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: lane balancing: no prior snapshot (%v); planning lanes unweighted\n", err)
+		return nil
+	}
+	defer func() { _ = reader.Close() }()
+
+	meta := reader.Meta()
+	if meta.Format != indexreader.FormatDurableV2 {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: lane balancing: prior snapshot is %s, which records no prefix counts; planning lanes unweighted\n", formatLabel(meta.Format))
+		return nil
+	}
+	stats, err := durablePriorPrefixStats(meta.SourcePath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: lane balancing: read prior prefix stats: %v; planning lanes unweighted\n", err)
+		return nil
+	}
+	return prefixWeightsFromStats(basePrefix, crawlPrefixes, stats)
+}
+
+func durablePriorPrefixStats(latestPath string) ([]indexsubstrate.PrefixStat, error) {
+	opts, err := indexReaderResolveOptions()
+	if err != nil {
+		return nil, err
+	}
+	snap, err := indexsubstrate.OpenLatestPublishedSnapshotBounded(latestPath, opts.MaxMarkerBytes, opts.MaxManifestBytes)
+	if err != nil {
+		return nil, err
+	}
+	doc, ok, err := indexsubstrate.ReadPublishedPrefixStats(snap)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errNoPriorPrefixStats
+	}
+	return doc.Prefixes, nil
+}
+
+// prefixWeightsFromStats maps each plan prefix to the recursive object count of
+// its recorded prefix. A directory prefix with no record under a complete
+// (untruncated) ancestor held nothing and is weighted zero rather than
+// planned at the mean; one under a truncated ancestor is unknown and omitted.
+func prefixWeightsFromStats(basePrefix string, crawlPrefixes []string, stats []indexsubstrate.PrefixStat) map[string]int64 {
+	byPrefix := make(map[string]indexsubstrate.PrefixStat, len(stats))
+	for _, stat := range stats {
+		byPrefix[stat.Prefix] = stat
+	}
+	weights := make(map[string]int64, len(crawlPrefixes))
+	for _, prefix := range crawlPrefixes {
+		if !strings.HasPrefix(prefix, basePrefix) {
+			continue
+		}
+		rel := strings.TrimPrefix(prefix, basePrefix)
+		if rel != "" && !strings.HasSuffix(rel, "/") {
+			continue
+		}
+		if stat, ok := byPrefix[rel]; ok {
+			weights[prefix] = stat.ObjectsRecursive
+			continue
+		}
+		if ancestor, ok := nearestRecordedAncestor(byPrefix, rel); ok && !ancestor.Truncated {
+			weights[prefix] = 0
+		}
+	}
+	if len(weights) == 0 {
+		return nil
+	}
+	return weights
+}
+
+func nearestRecordedAncestor(byPrefix map[string]indexsubstrate.PrefixStat, rel string) (indexsubstrate.PrefixStat, bool) {
+	for rel != "" {
+		rel = rel[:strings.LastIndex(strings.TrimSuffix(rel, "/"), "/")+1]
+		if stat, ok := byPrefix[rel]; ok {
+			return stat, true
+		}
+	}
+	return indexsubstrate.PrefixStat{}, false
+}
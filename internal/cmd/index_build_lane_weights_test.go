@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+func TestPrefixWeightsFromStatsUsesRecursiveCounts(t *testing.T) {
+	stats := []indexsubstrate.PrefixStat{
+		{Prefix: "", ObjectsRecursive: 9},
+		{Prefix: "a/", ObjectsRecursive: 3},
+		{Prefix: "b/", ObjectsRecursive: 1},
+		{Prefix: "bb/", ObjectsRecursive: 1},
+		{Prefix: "deep/", ObjectsRecursive: 4, Truncated: true, TruncatedReason: indexsubstrate.PrefixTruncatedMaxPrefixes},
+	}
+	weights := prefixWeightsFromStats("data/", []string{
+		"data/a/", "data/b/", "data/bb/", "data/q/", "data/deep/x/", "data/a/partial", "other/x/",
+	}, stats)
+	// q/ is absent under a complete root, so it held nothing. deep/x/ was folded
+	// into a truncated ancestor and a/partial is not a directory: both unknown.
+	require.Equal(t, map[string]int64{"data/a/": 3, "data/b/": 1, "data/bb/": 1, "data/q/": 0}, weights)
+}
+
+// TestIndexBuildBalanceLanesReportsPriorRunPrediction runs a scoped durable
+// build twice: the first has no prior snapshot and plans unweighted, the second
+// plans from the first run's counts and reports them in the receipt.
+func TestIndexBuildBalanceLanesReportsPriorRunPrediction(t *testing.T) {
+	resetAppDataRootTestState(t)
+	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
+	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
+	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`
+version: "1.0"
+connection:
+  provider: s3
+  bucket: bucket
+  base_uri: s3://bucket/data/
+identity:
+  storage_provider: aws_s3
+build:
+  source: crawl
+  scope:
+    type: prefix_list
+    prefixes: ["big/", "mid/", "small/", "tiny/"]
+  match:
+    includes: ["**"]
+  crawl:
+    concurrency: 2
+    balance_lanes: true
+`), 0o600))
+
+	restore := withIndexBuildExperimentalEngineTestState(t)
+	restore()
+	indexBuildJobPath = manifestPath
+	indexBuildFormat = "durable"
+	indexBuildJSON = true
+
+	var objects []provider.ObjectSummary
+	add := func(prefix string, n int) {
+		for i := 0; i < n; i++ {
+			objects = append(objects, provider.ObjectSummary{
+				Key: "data/" + prefix + string(rune('a'+i)) + ".xml", Size: 1, ETag: `"e"`, LastModified: base, StorageClass: "STANDARD",
+			})
+		}
+	}
+	add("big/", 6)
+	add("mid/", 3)
+	add("small/", 2)
+	add("tiny/", 1)
+	prov := &countingIndexBuildProvider{objects: objects}
+	oldSource := newIndexBuildEngineSource
+	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
+		return prov, nil
+	}
+	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
+
+	runBuild := func() indexBuildResultRecord {
+		t.Helper()
+		cmd := &cobra.Command{Use: "build"}
+		cmd.SetContext(context.Background())
+		var stdout strings.Builder
+		cmd.SetOut(&stdout)
+		require.NoError(t, runIndexBuild(cmd, nil))
+		var rec indexBuildResultRecord
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(stdout.String())), &rec))
+		return rec
+	}
+
+	first := runBuild()
+	require.Equal(t, []indexBuildLaneRecord{
+		{Ordinal: 1, PlanEntries: 2, ListedObjects: 8, ObservedObjects: 8},
+		{Ordinal: 2, PlanEntries: 2, ListedObjects: 4, ObservedObjects: 4},
+	}, first.Lanes, "round-robin pairs big/ with small/ when nothing is known")
+
+	second := runBuild()
+	require.Equal(t, []indexBuildLaneRecord{
+		{Ordinal: 1, PlanEntries: 1, PredictedObjects: 6, ListedObjects: 6, ObservedObjects: 6},
+		{Ordinal: 2, PlanEntries: 3, PredictedObjects: 6, ListedObjects: 6, ObservedObjects: 6},
+	}, second.Lanes)
+}
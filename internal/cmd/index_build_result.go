@@ -42,9 +42,27 @@ type indexBuildResultRecord struct {
 	Tombstones       *int                              `json:"tombstones,omitempty"`
 	Segments         *int                              `json:"segments,omitempty"`
 	ObjectsObserved  *int64                            `json:"objects_observed,omitempty"`
+	ObjectsListed    *int64                            `json:"objects_listed,omitempty"`
 	ObjectsIngested  *int64                            `json:"objects_ingested,omitempty"`
 	ManifestSHA256   string                            `json:"manifest_sha256,omitempty"`
 	Verification     *indexBuildBothVerificationRecord `json:"verification,omitempty"`
+	Lanes            []indexBuildLaneRecord            `json:"lanes,omitempty"`
+	// Filtered reports that the durable snapshot records an observation
+	// selector (match excludes, non-default includes, include_hidden, or
+	// filters), so objects_observed may be below objects_listed. The selector
+	// itself is not receipt data.
+	Filtered bool `json:"filtered,omitempty"`
+}
+
+// indexBuildLaneRecord reports one journal lane of a multi-lane durable crawl:
+// the size lane planning predicted for it against the objects it observed.
+// Lanes are identified by ordinal only; their prefixes are not receipt data.
+type indexBuildLaneRecord struct {
+	Ordinal          int   `json:"ordinal"`
+	PlanEntries      int   `json:"plan_entries"`
+	PredictedObjects int64 `json:"predicted_objects"`
+	ListedObjects    int64 `json:"listed_objects"`
+	ObservedObjects  int64 `json:"observed_objects"`
 }
 
 // indexBuildBothVerificationRecord reports the run-scoped SQLite
@@ -84,8 +102,28 @@ func newDurableBuildResultRecord(summary indexbuild.Summary, scopeHash, requeste
 		Tombstones:       intPtr(summary.Manifest.Tombstones),
 		Segments:         intPtr(len(summary.Manifest.Segments)),
 		ObjectsObserved:  int64Ptr(summary.ObjectsObserved),
+		ObjectsListed:    int64Ptr(summary.ObjectsListed),
+		Filtered:         summary.Manifest.Selector != nil,
 		ManifestSHA256:   summary.ManifestSHA256,
+		Lanes:            indexBuildLaneRecords(summary.Lanes),
+	}
+}
+
+func indexBuildLaneRecords(lanes []indexbuild.LaneSummary) []indexBuildLaneRecord {
+	if len(lanes) == 0 {
+		return nil
+	}
+	out := make([]indexBuildLaneRecord, len(lanes))
+	for i, ln := range lanes {
+		out[i] = indexBuildLaneRecord{
+			Ordinal:          ln.Ordinal,
+			PlanEntries:      ln.PlanEntries,
+			PredictedObjects: ln.PredictedObjects,
+			ListedObjects:    ln.ListedObjects,
+			ObservedObjects:  ln.ObservedObjects,
+		}
 	}
+	return out
 }
 
 func newSQLiteBuildResultRecord(indexSetID, runID, scopeHash, status string, objectsIngested int64) indexBuildResultRecord {
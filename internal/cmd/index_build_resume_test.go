@@ -27,7 +27,7 @@ func TestIndexBuildResumeRunDoesNotRequireJobFlag(t *testing.T) {
 		indexBuildFormat = oldFormat
 	}()
 
-	// Resume is a SQLite-lifecycle path; keep format sqlite for this flag interaction test.
+	// Keep format sqlite for this flag interaction test.
 	indexBuildFormat = "sqlite"
 	indexBuildJobPath = ""
 	indexBuildResumeRun = ""
@@ -44,7 +44,7 @@ func TestIndexBuildResumeRunDoesNotRequireJobFlag(t *testing.T) {
 func TestIndexBuildResumeRunWithDefaultDurableFormatReachesResumePath(t *testing.T) {
 	// After durable default flip, the printed operator hint
 	// `gonimbus index build --resume-run <run_id>` (no --format) must still
-	// enter the SQLite resume path, not fail durable format validation.
+	// enter the resume path, not fail durable format validation.
 	oldJob := indexBuildJobPath
 	oldResumeRun := indexBuildResumeRun
 	oldDryRun := indexBuildDryRun
@@ -81,7 +81,7 @@ func TestIndexBuildResumeRunWithDefaultDurableFormatReachesResumePath(t *testing
 		"expected resume-path error, got: %v", err)
 }
 
-func TestIndexBuildResumeRunRejectsExplicitDurableFormat(t *testing.T) {
+func TestIndexBuildResumeRunRejectsFormatBoth(t *testing.T) {
 	oldResumeRun := indexBuildResumeRun
 	oldFormat := indexBuildFormat
 	oldExperimental := indexBuildExperimentalEngine
@@ -95,15 +95,23 @@ func TestIndexBuildResumeRunRejectsExplicitDurableFormat(t *testing.T) {
 	}()
 
 	indexBuildResumeRun = "run_123"
-	indexBuildFormat = "durable"
 	indexBuildExperimentalEngine = false
-	if f := indexBuildCmd.Flags().Lookup("format"); f != nil {
-		_ = f.Value.Set("durable")
-		f.Changed = true
+	setFormat := func(format string) {
+		indexBuildFormat = format
+		if f := indexBuildCmd.Flags().Lookup("format"); f != nil {
+			_ = f.Value.Set(format)
+			f.Changed = true
+		}
 	}
 
-	err := validateIndexBuildResumeInvocation(indexBuildCmd)
-	require.ErrorContains(t, err, "--resume-run is not compatible with --format durable")
+	setFormat("both")
+	require.ErrorContains(t, validateIndexBuildResumeInvocation(indexBuildCmd), "--resume-run is not compatible with --format both")
+
+	// An explicit format must match the format the run was built with.
+	setFormat("durable")
+	require.NoError(t, validateIndexBuildResumeInvocation(indexBuildCmd))
+	require.NoError(t, validateIndexBuildResumeFormat(indexBuildCmd, indexBuildCheckpointPayload{Format: indexBuildCheckpointFormatDurable}))
+	require.ErrorContains(t, validateIndexBuildResumeFormat(indexBuildCmd, indexBuildCheckpointPayload{}), "run was built with --format sqlite")
 }
 
 func TestIndexBuildResumeIdentityRejectsTamperedCheckpointConfig(t *testing.T) {
@@ -106,6 +106,12 @@ func resolveSpillByteBudget(flagVal, flagSource, envKey, envSource, configKey, c
 // default (the library zero-value, which maps to the substrate ceiling). Called
 // before the crawl so a bad value fails fast.
 func resolveIndexBuildSpill() (indexBuildSpillResolution, error) {
+	return resolveSpillSettings(indexBuildSpillWorkspaceMax, indexBuildSpillRecordMax, indexBuildSpillRoot)
+}
+
+// resolveSpillSettings applies the resolveIndexBuildSpill rules to the given
+// flag values. Durable enrich shares the same flags, env vars, and config keys.
+func resolveSpillSettings(workspaceFlag, recordFlag, rootFlag string) (indexBuildSpillResolution, error) {
 	def := indexsubstrate.DefaultSpillMergeBudget()
 	res := indexBuildSpillResolution{
 		WorkspaceSource:      spillSourceDefault,
@@ -116,7 +122,7 @@ func resolveIndexBuildSpill() (indexBuildSpillResolution, error) {
 	}
 
 	if b, source, explicit, err := resolveSpillByteBudget(
-		indexBuildSpillWorkspaceMax, spillSourceFlag,
+		workspaceFlag, spillSourceFlag,
 		spillWorkspaceMaxEnv, spillSourceEnv,
 		spillWorkspaceMaxConfig, spillSourceConfig,
 		"spill workspace budget",
@@ -129,7 +135,7 @@ func resolveIndexBuildSpill() (indexBuildSpillResolution, error) {
 	}
 
 	if b, source, explicit, err := resolveSpillByteBudget(
-		indexBuildSpillRecordMax, spillRecordSourceFlag,
+		recordFlag, spillRecordSourceFlag,
 		spillRecordMaxEnv, spillRecordSourceEnv,
 		spillRecordMaxConfig, spillRecordSourceConfig,
 		"spill record budget",
@@ -149,7 +155,7 @@ func resolveIndexBuildSpill() (indexBuildSpillResolution, error) {
 	}
 
 	if raw, source, ok := firstOperatorValue(
-		indexBuildSpillRoot, spillRootSourceFlag,
+		rootFlag, spillRootSourceFlag,
 		spillRootEnv, spillRootSourceEnv,
 		spillRootConfig, spillRootSourceConfig,
 	); ok {
@@ -18,6 +18,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/internal/signing"
 	"github.com/3leaps/gonimbus/pkg/indexreader"
 	"github.com/3leaps/gonimbus/pkg/indexstore"
 )
@@ -48,6 +49,12 @@ It helps map the per-index directory name (idx_<hash-prefix>) to a human-readabl
 - identity.json hash not matching the index_set_id
 - DBs containing multiple index sets (unsupported)
 - durable marker/manifest digest failures
+- signature bundles that do not verify, and, with --trusted-keys, runs that are
+  unsigned or signed by a key the policy does not list
+
+Signature status (signature.status in JSON) reports the detached bundle beside
+a durable manifest (recorded by 'index export --sign-key') or beside index.db
+(kept by 'index hydrate'): unsigned, valid, trusted, untrusted, or invalid.
 
 Examples:
   # Show all local indexes (alias)
@@ -71,6 +78,9 @@ Examples:
   # Dual-format set: select durable detail explicitly
   gonimbus index doctor idx_1234abcd --detail --format durable-v2
 
+  # Check signatures against a trust policy
+  gonimbus index doctor --trusted-keys ~/.config/gonimbus/hub-trusted.pem
+
   # Machine-readable output
   gonimbus index doctor --json`,
 		Args: cobra.MaximumNArgs(1),
@@ -84,6 +94,7 @@ Examples:
 	cmd.Flags().Bool("verbose", false, "Include identity payload details")
 	cmd.Flags().Bool("stats", false, "Include object counts (may be expensive on very large sqlite indexes)")
 	cmd.Flags().Bool("detail", false, "Show detailed JSON report for a single index (includes identity and manifest when present)")
+	addHubTrustedKeysFlag(cmd)
 	cmd.Flags().Bool("leases", false, "Report index set-authority lease lock-state instead of index health (see 'index lease ls')")
 	cmd.Flags().Bool("release-stale", false, "Reclaim provably-unheld set-authority leases (see 'index lease reap'); requires --confirm")
 	cmd.Flags().Bool("confirm", false, "With --release-stale, perform the reclaim (otherwise a dry run)")
@@ -154,6 +165,9 @@ type indexDoctorEntry struct {
 	DurableManifestSHA  string `json:"durable_manifest_sha256,omitempty"`
 	DurableSegmentCount int    `json:"durable_segment_count,omitempty"`
 
+	// Signature is the status of the artifact's detached signature bundle.
+	Signature *hubSignatureCheck `json:"signature,omitempty"`
+
 	// Run-scoped SQLite parity-verification projections retained under
 	// <set>/verification/<attempt>/. Inventory classification only: these are
 	// non-canonical dual-format parity artifacts; run-scoping grants
@@ -178,6 +192,8 @@ type indexDoctorOptions struct {
 	IncludeIdentityPayload bool
 	IncludeStats           bool
 	IncludeManifest        bool
+	// TrustedKeys, when set, makes unsigned and untrusted artifacts faults.
+	TrustedKeys signing.TrustedKeys
 }
 
 // indexDoctorTarget is a format-aware inspect target.
@@ -233,10 +249,15 @@ func runIndexDoctor(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	trusted, err := hubTrustedKeysFromCommand(cmd)
+	if err != nil {
+		return err
+	}
 	opts := indexDoctorOptions{
 		IncludeIdentityPayload: verbose || detail,
 		IncludeStats:           includeStats || detail,
 		IncludeManifest:        detail,
+		TrustedKeys:            trusted,
 	}
 
 	if detail {
@@ -888,6 +909,9 @@ func inspectDurableForDoctor(meta indexreader.Meta, opts indexDoctorOptions) (*i
 	if err := verifyDurableDoctorSegments(snap); err != nil {
 		entry.Notes = append(entry.Notes, fmt.Sprintf("segment digest verify: %v", err))
 	}
+	fillDoctorSignature(entry, snap.Complete.ManifestPath, snap.Manifest.IndexSetID, snap.Manifest.RunID, opts, func() ([]signing.Subject, error) {
+		return []signing.Subject{{Name: "manifest.json", SHA256: snap.Complete.ManifestSHA256, SizeBytes: snap.AccountedManifestBytes}}, nil
+	})
 
 	markerOK := entry.DurableMarkerOK != nil && *entry.DurableMarkerOK
 	// Health counts only fault notes: informational lifecycle classifications
@@ -1128,10 +1152,80 @@ func inspectIndexDBForDoctor(ctx context.Context, dbPath string, opts indexDocto
 		}
 	}
 
+	fillDoctorSignature(entry, dbPath, entry.IndexSetID, doctorSignedSQLiteRunID(dir, entry.LatestRunID), opts, func() ([]signing.Subject, error) {
+		return doctorSQLiteSignatureSubjects(dbPath, entry.IdentityPath)
+	})
+
 	entry.IdentityOK = entry.IndexSetID != "" && len(entry.Notes) == 0
 	return entry, nil
 }
 
+// fillDoctorSignature reports the detached signature bundle beside
+// artifactPath. expected is only called when a bundle exists, so an unsigned
+// SQLite index is never hashed. A bundle that does not verify is a fault;
+// unsigned and untrusted artifacts are faults only under --trusted-keys.
+func fillDoctorSignature(entry *indexDoctorEntry, artifactPath, indexSetID, runID string, opts indexDoctorOptions, expected func() ([]signing.Subject, error)) {
+	check := hubSignatureCheck{Status: hubSignatureUnsigned}
+	data, err := readDoctorBoundedFile(artifactPath+signing.BundleSuffix, maxHubMarkerBytes)
+	switch {
+	case err != nil:
+		check = hubSignatureCheck{Status: hubSignatureInvalid, Detail: err.Error()}
+	case data != nil:
+		subjects, subjectErr := expected()
+		if subjectErr != nil {
+			check = hubSignatureCheck{Status: hubSignatureInvalid, Detail: subjectErr.Error()}
+		} else {
+			check = checkHubSignatureBundle(data, indexSetID, runID, subjects, opts.TrustedKeys)
+		}
+	}
+	entry.Signature = &check
+	if err := check.admit(opts.TrustedKeys, filepath.Base(artifactPath)); err != nil {
+		entry.Notes = append(entry.Notes, err.Error())
+	}
+}
+
+// readDoctorBoundedFile reads a small sidecar file, returning nil data when it
+// does not exist.
+func readDoctorBoundedFile(path string, maxBytes int64) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if info.Size() > maxBytes {
+		return nil, fmt.Errorf("%s size %d exceeds limit %d", filepath.Base(path), info.Size(), maxBytes)
+	}
+	return os.ReadFile(path) // #nosec G304 -- sidecar beside a discovered index artifact.
+}
+
+// doctorSignedSQLiteRunID names the run a SQLite bundle must claim: the run a
+// hydrated directory's complete.json records, else the database's latest run.
+func doctorSignedSQLiteRunID(dir, latestRunID string) string {
+	data, err := readDoctorBoundedFile(filepath.Join(dir, "complete.json"), maxHubCompleteMarkerBytes)
+	if err != nil || data == nil {
+		return latestRunID
+	}
+	var complete completeMarker
+	if json.Unmarshal(data, &complete) != nil || complete.RunID == "" {
+		return latestRunID
+	}
+	return complete.RunID
+}
+
+func doctorSQLiteSignatureSubjects(dbPath, identityPath string) ([]signing.Subject, error) {
+	dbSHA, dbSize, err := hashFile(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash index.db: %w", err)
+	}
+	subjects := []signing.Subject{{Name: "index.db", SHA256: dbSHA, SizeBytes: dbSize}}
+	if identitySHA, identitySize, err := hashFile(identityPath); err == nil {
+		subjects = append(subjects, signing.Subject{Name: "identity.json", SHA256: identitySHA, SizeBytes: identitySize})
+	}
+	return subjects, nil
+}
+
 func loadLatestRun(ctx context.Context, db *sql.DB, entry *indexDoctorEntry) {
 	if entry == nil || entry.IndexSetID == "" {
 		return
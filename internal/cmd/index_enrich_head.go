@@ -18,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
+	"github.com/3leaps/gonimbus/internal/metrics"
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
 	"github.com/3leaps/gonimbus/pkg/indexreader"
 	"github.com/3leaps/gonimbus/pkg/indexstore"
@@ -75,6 +76,9 @@ func init() {
 	indexEnrichWithHeadCmd.Flags().Bool("resume", false, "Skip rows with non-null head_enriched_at")
 	indexEnrichWithHeadCmd.Flags().String("state-out", "", "Write per-candidate audit JSONL to this path")
 	indexEnrichWithHeadCmd.Flags().String("resume-run", "", "Resume a failed-resumable enrich-with-head run by run id")
+	indexEnrichWithHeadCmd.Flags().String("spill-workspace-max", "", "Durable only: ceiling on the merge's live scratch workspace (e.g. 24GiB); empty uses the 16GiB default. Also settable via GONIMBUS_SPILL_WORKSPACE_MAX or the index.spill.workspace_max config key")
+	indexEnrichWithHeadCmd.Flags().String("spill-record-max", "", "Durable only: max single journal-record size for the merge (e.g. 32MiB); empty uses the 16MiB default. Also settable via GONIMBUS_SPILL_RECORD_MAX or the index.spill.record_max config key")
+	indexEnrichWithHeadCmd.Flags().String("spill-root", "", "Durable only: directory for the merge's scratch workspace (default: beside the enrich journal). Also settable via GONIMBUS_SPILL_ROOT or the index.spill.root config key")
 	indexEnrichWithHeadCmd.Flags().StringVar(&enrichHeadProfile, "profile", "", "AWS profile")
 	indexEnrichWithHeadCmd.Flags().StringVar(&enrichHeadRegion, "region", "", "AWS region override")
 	indexEnrichWithHeadCmd.Flags().StringVar(&enrichHeadEndpoint, "endpoint", "", "Custom S3 endpoint override")
@@ -286,6 +290,7 @@ func runIndexEnrichWithHead(cmd *cobra.Command, args []string) (runErr error) {
 	}
 
 	summary.Status = string(status)
+	recordEnrichHeadMetrics(indexSet, summary)
 	ts := time.Now().UTC().Format(time.RFC3339)
 	enc := json.NewEncoder(cmd.OutOrStdout())
 	if err := enc.Encode(enrichHeadSummaryRecord{
@@ -451,6 +456,7 @@ func runIndexEnrichWithHeadResumeHeld(ctx context.Context, cmd *cobra.Command, a
 	}
 
 	summary.Status = string(status)
+	recordEnrichHeadMetrics(indexSet, summary)
 	enc := json.NewEncoder(cmd.OutOrStdout())
 	if err := enc.Encode(enrichHeadSummaryRecord{
 		Type: "gonimbus.index.enrich_with_head.summary.v1",
@@ -813,6 +819,22 @@ func classifyEnrichHeadFatalError(err error, legacyRefreshTextFallback bool) opc
 	})
 }
 
+func recordEnrichHeadMetrics(indexSet *indexstore.IndexSet, summary enrichHeadSummaryData) {
+	labels := metrics.JobLabels{JobType: metrics.JobTypeIndexEnrich}
+	if indexSet != nil {
+		labels.Provider = indexSet.Provider
+		if parsed, err := uri.ParseURI(indexSet.BaseURI); err == nil {
+			labels.Bucket = parsed.Bucket
+		}
+	}
+	metrics.RecordEnrichment(labels, metrics.EnrichCounts{
+		Candidates: summary.Candidates,
+		Enriched:   summary.Enriched,
+		Failed:     summary.Failed,
+		HeadCalls:  summary.HeadCalls,
+	})
+}
+
 func enrichHeadProgress(summary enrichHeadSummaryData) map[string]int64 {
 	return map[string]int64{
 		"candidates":     summary.Candidates,
@@ -28,6 +28,15 @@ func runIndexEnrichWithHeadDurable(ctx context.Context, cmd *cobra.Command, meta
 		return err
 	}
 	_ = storageFiltered
+	// Resolve the merge budgets before the provider or any HEAD so a bad value
+	// fails fast.
+	spillWorkspaceMax, _ := cmd.Flags().GetString("spill-workspace-max")
+	spillRecordMax, _ := cmd.Flags().GetString("spill-record-max")
+	spillRoot, _ := cmd.Flags().GetString("spill-root")
+	spill, err := resolveSpillSettings(spillWorkspaceMax, spillRecordMax, spillRoot)
+	if err != nil {
+		return err
+	}
 
 	indexSet, err := durableEnrichIndexSet(meta, checkpointCfg.Provider)
 	if err != nil {
@@ -97,9 +106,14 @@ func runIndexEnrichWithHeadDurable(ctx context.Context, cmd *cobra.Command, meta
 			StorageClasses: opts.StorageClasses,
 			IncludeDeleted: opts.IncludeDeleted,
 		},
-		Parallel:         parallel,
-		Resume:           resume,
-		StateSink:        stateSink,
+		Parallel:  parallel,
+		Resume:    resume,
+		StateSink: stateSink,
+		Spill: indexenrich.SpillConfig{
+			WorkspaceBytes: spill.WorkspaceBytes,
+			RecordBytes:    spill.RecordBytes,
+			Root:           spill.Root,
+		},
 		MaxMarkerBytes:   int64(maxHubMarkerBytes),
 		MaxManifestBytes: int64(maxDurableManifestBytes),
 	})
@@ -122,6 +136,7 @@ func runIndexEnrichWithHeadDurable(ctx context.Context, cmd *cobra.Command, meta
 		StorageFiltered: res.StorageFiltered,
 		Status:          res.Status,
 	}
+	recordEnrichHeadMetrics(indexSet, summary)
 	if err := enc.Encode(enrichHeadSummaryRecord{
 		Type: "gonimbus.index.enrich_with_head.summary.v1",
 		TS:   ts,
@@ -137,6 +152,7 @@ func runIndexEnrichWithHeadDurable(ctx context.Context, cmd *cobra.Command, meta
 	}
 
 	if res.Published || res.LatestAdvanced {
+		emitIndexBuildSpillCompletion(os.Stderr, spill, res.PeakSpillBytes)
 		if err := enc.Encode(map[string]any{
 			"type":                   "gonimbus.index.enrich_with_head.durable_result.v1",
 			"ts":                     ts,
@@ -222,6 +238,20 @@ func writeEnrichHeadStateEvent(file *os.File, ev indexenrich.StateEvent) error {
 }
 
 func durableEnrichIndexSet(meta indexreader.Meta, providerOpts enrichHeadProviderOptions) (*indexstore.IndexSet, error) {
+	indexSet := durableIdentityIndexSet(meta)
+	_ = providerOpts
+	if strings.TrimSpace(indexSet.BaseURI) == "" {
+		return nil, fmt.Errorf("durable enrich: base_uri is required for index set %s", meta.IndexSetID)
+	}
+	if strings.TrimSpace(indexSet.Provider) == "" {
+		return nil, fmt.Errorf("durable enrich: provider is required for index set %s", meta.IndexSetID)
+	}
+	return indexSet, nil
+}
+
+// durableIdentityIndexSet describes a durable-v2 set from its reader metadata,
+// filling provider and region details from identity.json when one is attached.
+func durableIdentityIndexSet(meta indexreader.Meta) *indexstore.IndexSet {
 	indexSet := &indexstore.IndexSet{
 		IndexSetID: meta.IndexSetID,
 		BaseURI:    meta.BaseURI,
@@ -243,12 +273,5 @@ func durableEnrichIndexSet(meta indexreader.Meta, providerOpts enrichHeadProvide
 			}
 		}
 	}
-	_ = providerOpts
-	if strings.TrimSpace(indexSet.BaseURI) == "" {
-		return nil, fmt.Errorf("durable enrich: base_uri is required for index set %s", meta.IndexSetID)
-	}
-	if strings.TrimSpace(indexSet.Provider) == "" {
-		return nil, fmt.Errorf("durable enrich: provider is required for index set %s", meta.IndexSetID)
-	}
-	return indexSet, nil
+	return indexSet
 }
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/3leaps/gonimbus/internal/signing"
 	"github.com/3leaps/gonimbus/pkg/indexreader"
 	"github.com/3leaps/gonimbus/pkg/indexstore"
 	"github.com/3leaps/gonimbus/pkg/provider"
@@ -38,10 +39,17 @@ SQLite export layout:
 
 Durable export layout:
 
+  <hub>/index-sets/<index_set_id>/segments/sha256/<sha256>  (shared by runs)
   <hub>/index-sets/<index_set_id>/runs/<run_id>/manifest.json
-  <hub>/index-sets/<index_set_id>/runs/<run_id>/segments/...
   <hub>/index-sets/<index_set_id>/runs/<run_id>/complete.json
 
+Durable segments and prefix stats are stored once per index set, named by
+digest, and complete.json references them by content path. Export skips any
+the hub already holds, so re-exporting or exporting a run that shares segments
+with an earlier one uploads only what is new. --per-run-segments writes the
+older layout (segments under runs/<run_id>/segments/) for readers that predate
+marker schema v2.
+
 After a successful upload, the command attempts to advance the latest pointer:
 
   <hub>/index-sets/<index_set_id>/latest.json
@@ -49,6 +57,29 @@ After a successful upload, the command attempts to advance the latest pointer:
 The complete.json marker is written last and serves as the commit signal.
 Consumers should only trust runs where complete.json is present.
 
+With --sign-key (or GONIMBUS_SIGN_KEY_FILE / GONIMBUS_SIGN_KEY holding a PEM
+PKCS#8 ed25519 key) the export also signs what it publishes. A detached bundle
+beside the run's primary artifact signs its digest, and is uploaded before
+complete.json:
+
+  <hub>/index-sets/<index_set_id>/runs/<run_id>/index.db.sig.json       (sqlite)
+  <hub>/index-sets/<index_set_id>/runs/<run_id>/manifest.json.sig.json  (durable)
+
+The durable manifest pins every segment by digest, so its bundle covers the
+whole snapshot. latest.json carries its signature inline. 'index hydrate
+--trusted-keys' verifies both.
+
+With --encrypt-to (or GONIMBUS_ENCRYPT_TO) naming a PEM file of X25519 public
+keys, the export encrypts every artifact that holds key listings (index.db and
+identity.json, or the durable manifest, segments, and prefix stats) before it
+leaves the host. Each object gets its own AES-256-GCM data key, wrapped for
+every recipient. complete.json and latest.json stay readable, so 'index hub
+ls', 'show', 'gc', and 'sync' work without a private key; 'index hydrate
+--decrypt-key' decrypts. Generate a key pair with openssl:
+
+  openssl genpkey -algorithm x25519 -out hub-decrypt.pem
+  openssl pkey -in hub-decrypt.pem -pubout -out hub-recipients.pem
+
 Examples:
   # Export latest local artifact (auto: durable if present, else sqlite)
   gonimbus index export --hub file:///data/index-hub/ --index-set idx_da038d8171b4a9ba
@@ -61,6 +92,14 @@ Examples:
   gonimbus index export --hub s3://my-bucket/index-hub/ \
     --index-set idx_da038d8171b4a9ba --run-id run_1709654400000000000
 
+  # Sign the published run and latest pointer
+  gonimbus index export --hub s3://my-bucket/index-hub/ \
+    --index-set idx_da038d8171b4a9ba --sign-key ~/.config/gonimbus/hub-signing.pem
+
+  # Encrypt the published artifacts
+  gonimbus index export --hub s3://shared-bucket/index-hub/ \
+    --index-set idx_da038d8171b4a9ba --encrypt-to ~/.config/gonimbus/hub-recipients.pem
+
   # Force SQLite compatibility export
   gonimbus index export --hub file:///data/index-hub/ \
     --index-set idx_da038d8171b4a9ba --format sqlite`,
@@ -82,6 +121,9 @@ func init() {
 	indexExportCmd.Flags().String("hub-endpoint", "", "Custom endpoint for hub destination")
 	indexExportCmd.Flags().String("hub-gcp-project", "", "GCP project hint for GCS hub destination")
 	addLatestPointerFlags(indexExportCmd)
+	addHubSignKeyFlag(indexExportCmd)
+	addHubEncryptToFlag(indexExportCmd)
+	indexExportCmd.Flags().Bool("per-run-segments", false, "Durable: upload segments under the run prefix (marker schema v1) instead of the index set's shared digest store, for readers that predate v2")
 
 	_ = indexExportCmd.MarkFlagRequired("hub")
 	_ = indexExportCmd.MarkFlagRequired("index-set")
@@ -184,6 +226,7 @@ func runIndexExport(cmd *cobra.Command, _ []string) (err error) {
 	runIDFlag, _ := cmd.Flags().GetString("run-id")
 	dbFlag, _ := cmd.Flags().GetString("db")
 	formatFlag, _ := cmd.Flags().GetString("format")
+	perRunSegments, _ := cmd.Flags().GetBool("per-run-segments")
 	hubProfile, _ := cmd.Flags().GetString("hub-profile")
 	hubRegion, _ := cmd.Flags().GetString("hub-region")
 	hubEndpoint, _ := cmd.Flags().GetString("hub-endpoint")
@@ -212,6 +255,16 @@ func runIndexExport(cmd *cobra.Command, _ []string) (err error) {
 	if err != nil {
 		return err
 	}
+	// Resolve pointer options, including the signing key, before uploading so
+	// a bad key fails the export before it writes anything.
+	latestOpts, err := latestPointerOptionsFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	recipients, err := hubRecipientsFromCommand(cmd)
+	if err != nil {
+		return err
+	}
 
 	// Create hub provider once for either path.
 	putter, err := newHubProvider(ctx, hub)
@@ -240,7 +293,7 @@ func runIndexExport(cmd *cobra.Command, _ []string) (err error) {
 				indexSet := &indexstore.IndexSet{IndexSetID: durableIndexSetID}
 				run := &indexstore.IndexRun{RunID: durableRunID}
 				_, _ = fmt.Fprintf(os.Stderr, "Exporting index_set=%s run=%s format=%s to %s\n", durableIndexSetID, durableRunID, indexHubFormatDurableV2, hubURI)
-				return runIndexExportDurable(ctx, cmd, hub, getter, putter, indexSet, run)
+				return runIndexExportDurable(ctx, hub, getter, putter, indexSet, run, latestOpts, !perRunSegments, newHubSealedRun(recipients))
 			} else if formatMode == indexHubFormatDurableV2 {
 				return loadErr
 			}
@@ -313,7 +366,7 @@ func runIndexExport(cmd *cobra.Command, _ []string) (err error) {
 				_, _ = fmt.Fprintf(os.Stderr, "export format auto selected: %s\n", indexHubFormatDurableV2)
 			}
 			_, _ = fmt.Fprintf(os.Stderr, "Exporting index_set=%s run=%s format=%s to %s\n", indexSet.IndexSetID, run.RunID, indexHubFormatDurableV2, hubURI)
-			return runIndexExportDurable(ctx, cmd, hub, getter, putter, indexSet, run)
+			return runIndexExportDurable(ctx, hub, getter, putter, indexSet, run, latestOpts, !perRunSegments, newHubSealedRun(recipients))
 		} else if formatMode == indexHubFormatDurableV2 {
 			return loadErr
 		}
@@ -357,24 +410,52 @@ func runIndexExport(cmd *cobra.Command, _ []string) (err error) {
 	// Publish sequence (brief contract): index.db + identity.json first, complete.json last, then latest.json
 	runPrefix := []string{"index-sets", indexSet.IndexSetID, "runs", run.RunID}
 
+	// With --encrypt-to, both artifacts are uploaded sealed.
+	sealed := newHubSealedRun(recipients)
+	sealed.announce()
+	uploadArtifact := func(name, localPath string, size int64) error {
+		key := hubArtifactKey(hub, append(runPrefix, name)...)
+		if sealed == nil {
+			_, _ = fmt.Fprintf(os.Stderr, "  uploading %s (%d bytes)...\n", name, size)
+			return uploadToOutputDest(ctx, putter, key, localPath)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "  uploading %s (%d bytes, encrypted)...\n", name, size)
+		_, err := sealed.uploadSealed(ctx, putter, key, name, localPath)
+		return err
+	}
+
 	// 1. Upload index.db
 	indexDBKey := hubArtifactKey(hub, append(runPrefix, "index.db")...)
-	_, _ = fmt.Fprintf(os.Stderr, "  uploading index.db (%d bytes)...\n", dbSize)
-	if err := uploadToOutputDest(ctx, putter, indexDBKey, localDBPath); err != nil {
+	if err := uploadArtifact("index.db", localDBPath, dbSize); err != nil {
 		return fmt.Errorf("upload index.db: %w", err)
 	}
 
 	// 2. Upload identity.json (if present)
 	if len(identityBytes) > 0 {
-		identityKey := hubArtifactKey(hub, append(runPrefix, "identity.json")...)
-		_, _ = fmt.Fprintf(os.Stderr, "  uploading identity.json (%d bytes)...\n", identitySize)
-		if err := uploadToOutputDest(ctx, putter, identityKey, localIdentityPath); err != nil {
+		if err := uploadArtifact("identity.json", localIdentityPath, identitySize); err != nil {
 			return fmt.Errorf("upload identity.json: %w", err)
 		}
 	}
 
-	// 3. Write complete.json (commit marker — written last)
-	completeJSON, err := buildCompleteJSON(indexSet, run, summary, dbChecksum, dbSize, identityChecksum, identitySize)
+	// 3. Sign index.db and identity.json (optional); the bundle lands before
+	// the commit marker so a committed signed run is never briefly unsigned.
+	if latestOpts.SignKey != nil {
+		subjects := []signing.Subject{{Name: "index.db", SHA256: dbChecksum, SizeBytes: dbSize}}
+		if len(identityBytes) > 0 {
+			subjects = append(subjects, signing.Subject{Name: "identity.json", SHA256: identityChecksum, SizeBytes: identitySize})
+		}
+		bundle, err := buildHubSignatureBundle(latestOpts.SignKey, indexSet.IndexSetID, run.RunID, subjects...)
+		if err != nil {
+			return fmt.Errorf("sign index.db: %w", err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "  writing index.db%s (key_id=%s)...\n", signing.BundleSuffix, hubSignKeyID(latestOpts.SignKey))
+		if err := uploadBytes(ctx, putter, indexDBKey+signing.BundleSuffix, bundle); err != nil {
+			return fmt.Errorf("upload index.db signature: %w", err)
+		}
+	}
+
+	// 4. Write complete.json (commit marker — written last)
+	completeJSON, err := buildCompleteJSON(indexSet, run, summary, dbChecksum, dbSize, identityChecksum, identitySize, sealed)
 	if err != nil {
 		return fmt.Errorf("build complete.json: %w", err)
 	}
@@ -384,12 +465,8 @@ func runIndexExport(cmd *cobra.Command, _ []string) (err error) {
 		return fmt.Errorf("upload complete.json: %w", err)
 	}
 
-	// 4. Update latest.json (CAS pointer advance by default)
+	// 5. Update latest.json (CAS pointer advance by default)
 	_, _ = fmt.Fprintln(os.Stderr, "  updating latest.json...")
-	latestOpts, err := latestPointerOptionsFromCommand(cmd)
-	if err != nil {
-		return err
-	}
 	outcome, err := advanceLatestPointer(ctx, hub, getter, putter, indexSet.IndexSetID, run.RunID, latestOpts)
 	if err != nil {
 		return fmt.Errorf("update latest.json: %w", err)
@@ -517,32 +594,136 @@ func resolveLocalDurableLatestRunID(indexSetID string) (string, error) {
 	return latest.RunID, nil
 }
 
-func runIndexExportDurable(ctx context.Context, cmd *cobra.Command, hub *hubDestSpec, getter provider.ObjectGetter, putter provider.ObjectPutter, indexSet *indexstore.IndexSet, run *indexstore.IndexRun) error {
+func runIndexExportDurable(ctx context.Context, hub *hubDestSpec, getter provider.ObjectGetter, putter provider.ObjectPutter, indexSet *indexstore.IndexSet, run *indexstore.IndexRun, latestOpts latestPointerOptions, shared bool, sealed *hubSealedRun) error {
 	local, err := loadLocalDurableSnapshotForExport(indexSet.IndexSetID, run.RunID)
 	if err != nil {
 		return err
 	}
 
+	sealed.announce()
 	runPrefix := []string{"index-sets", indexSet.IndexSetID, "runs", run.RunID}
+	// Shared artifacts are content-addressed, so one the hub already holds
+	// (from an earlier run or an interrupted export) is not uploaded again.
+	statter, _ := getter.(provider.Provider)
+	var stats hubTransferStats
+	// An encrypted export names shared objects by their envelope digest. The
+	// latest run's envelopes, when sealed to the same recipients, stand in for
+	// segments that have not changed.
+	var previous map[string]artifactRef
+	if sealed != nil && shared {
+		previous = previousHubEnvelopes(ctx, getter, hub, indexSet.IndexSetID, sealed.Info)
+	}
+	if shared {
+		if err := writeHubPendingMarker(ctx, putter, hub, indexSet.IndexSetID, run.RunID, time.Now()); err != nil {
+			return err
+		}
+	}
+	uploadArtifact := func(what, rel, sha, localPath string, size int64) error {
+		path := "segments/" + rel
+		if !shared {
+			key := hubArtifactKey(hub, append(runPrefix, path)...)
+			_, _ = fmt.Fprintf(os.Stderr, "  uploading %s (%d bytes)...\n", what, size)
+			if sealed != nil {
+				if _, err := sealed.uploadSealed(ctx, putter, key, path, localPath); err != nil {
+					return err
+				}
+			} else if err := uploadToOutputDest(ctx, putter, key, localPath); err != nil {
+				return err
+			}
+			stats.Transferred++
+			stats.TransferredBytes += size
+			return nil
+		}
+		if sealed != nil {
+			if prev, ok := previous[sha]; ok && prev.SizeBytes == size && statter != nil {
+				key := hubArtifactKey(hub, "index-sets", indexSet.IndexSetID, prev.ContentPath)
+				if meta, err := statter.Head(ctx, key); err == nil && meta.Size == prev.Envelope.SizeBytes {
+					sealed.Envelopes[path] = *prev.Envelope
+					stats.Reused++
+					stats.ReusedBytes += prev.Envelope.SizeBytes
+					_, _ = fmt.Fprintf(os.Stderr, "  %s already in hub (sha256=%s)\n", what, sha)
+					return nil
+				}
+			}
+			sealedPath, digest, err := sealed.seal(path, localPath)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = os.Remove(sealedPath) }()
+			localPath, sha, size = sealedPath, digest.SHA256, digest.SizeBytes
+		}
+		key := hubArtifactKey(hub, "index-sets", indexSet.IndexSetID, hubSharedSegmentPath(sha))
+		reused := stats.Reused
+		if err := uploadSharedSegment(ctx, statter, putter, key, localPath, size, &stats); err != nil {
+			return err
+		}
+		if stats.Reused > reused {
+			_, _ = fmt.Fprintf(os.Stderr, "  %s already in hub (sha256=%s)\n", what, sha)
+		} else {
+			_, _ = fmt.Fprintf(os.Stderr, "  uploaded %s (%d bytes)\n", what, size)
+		}
+		return nil
+	}
 	for _, segment := range local.Manifest.Segments {
 		localPath, err := safeLocalArtifactPath(local.SegmentDir, segment.Path)
 		if err != nil {
 			return fmt.Errorf("segment %s: %w", segment.SegmentID, err)
 		}
-		key := hubArtifactKey(hub, append(runPrefix, "segments", segment.Path)...)
-		_, _ = fmt.Fprintf(os.Stderr, "  uploading segment %s (%d bytes)...\n", segment.Path, segment.SizeBytes)
-		if err := uploadToOutputDest(ctx, putter, key, localPath); err != nil {
+		if err := uploadArtifact("segment "+segment.Path, segment.Path, segment.Digest.Hex, localPath, segment.SizeBytes); err != nil {
 			return fmt.Errorf("upload segment %s: %w", segment.Path, err)
 		}
 	}
 
+	if desc := local.Manifest.PrefixStats; desc != nil {
+		localPath, err := safeLocalArtifactPath(local.SegmentDir, desc.Path)
+		if err != nil {
+			return fmt.Errorf("prefix stats: %w", err)
+		}
+		if err := uploadArtifact("prefix stats", desc.Path, desc.Digest.Hex, localPath, desc.SizeBytes); err != nil {
+			return fmt.Errorf("upload prefix stats: %w", err)
+		}
+	}
+	if desc := local.Manifest.KeyIndex; desc != nil {
+		localPath, err := safeLocalArtifactPath(local.SegmentDir, desc.Path)
+		if err != nil {
+			return fmt.Errorf("key index: %w", err)
+		}
+		if err := uploadArtifact("key index", desc.Path, desc.Digest.Hex, localPath, desc.SizeBytes); err != nil {
+			return fmt.Errorf("upload key index: %w", err)
+		}
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "  segments: %s\n", stats)
+
 	manifestKey := hubArtifactKey(hub, append(runPrefix, "manifest.json")...)
-	_, _ = fmt.Fprintf(os.Stderr, "  uploading manifest.json (%d bytes)...\n", local.ManifestSize)
-	if err := uploadToOutputDest(ctx, putter, manifestKey, local.ManifestPath); err != nil {
-		return fmt.Errorf("upload durable manifest: %w", err)
+	if sealed != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "  uploading manifest.json (%d bytes, encrypted)...\n", local.ManifestSize)
+		if _, err := sealed.uploadSealed(ctx, putter, manifestKey, "manifest.json", local.ManifestPath); err != nil {
+			return fmt.Errorf("upload durable manifest: %w", err)
+		}
+	} else {
+		_, _ = fmt.Fprintf(os.Stderr, "  uploading manifest.json (%d bytes)...\n", local.ManifestSize)
+		if err := uploadToOutputDest(ctx, putter, manifestKey, local.ManifestPath); err != nil {
+			return fmt.Errorf("upload durable manifest: %w", err)
+		}
+	}
+	// The manifest pins every segment and side artifact by digest, so
+	// signing it signs the snapshot.
+	if latestOpts.SignKey != nil {
+		bundle, err := buildHubSignatureBundle(latestOpts.SignKey, indexSet.IndexSetID, run.RunID,
+			signing.Subject{Name: "manifest.json", SHA256: local.ManifestSHA, SizeBytes: local.ManifestSize})
+		if err != nil {
+			return fmt.Errorf("sign durable manifest: %w", err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "  writing manifest.json%s (key_id=%s)...\n", signing.BundleSuffix, hubSignKeyID(latestOpts.SignKey))
+		if err := uploadBytes(ctx, putter, manifestKey+signing.BundleSuffix, bundle); err != nil {
+			return fmt.Errorf("upload durable manifest signature: %w", err)
+		}
+		if err := writeLocalSignatureBundle(local.ManifestPath, bundle); err != nil {
+			return fmt.Errorf("record durable manifest signature: %w", err)
+		}
 	}
 
-	completeJSON, err := buildDurableCompleteJSON(indexSet, run, local)
+	completeJSON, err := buildDurableCompleteJSON(indexSet, run, local, shared, sealed)
 	if err != nil {
 		return fmt.Errorf("build durable complete.json: %w", err)
 	}
@@ -551,12 +732,20 @@ func runIndexExportDurable(ctx context.Context, cmd *cobra.Command, hub *hubDest
 	if err := uploadBytes(ctx, putter, completeKey, completeJSON); err != nil {
 		return fmt.Errorf("upload complete.json: %w", err)
 	}
+	if shared {
+		// A reused segment may have been swept while this run was written;
+		// withdraw the commit rather than leave it pointing at missing bytes.
+		deleter, _ := putter.(provider.ObjectDeleter)
+		if err := verifyHubSharedSegments(ctx, statter, hub, indexSet.IndexSetID, completeJSON); err != nil {
+			if deleter != nil {
+				_ = deleter.DeleteObject(ctx, completeKey)
+			}
+			return err
+		}
+		clearHubPendingMarker(ctx, deleter, hub, indexSet.IndexSetID, run.RunID)
+	}
 
 	_, _ = fmt.Fprintln(os.Stderr, "  updating latest.json...")
-	latestOpts, err := latestPointerOptionsFromCommand(cmd)
-	if err != nil {
-		return err
-	}
 	outcome, err := advanceLatestPointer(ctx, hub, getter, putter, indexSet.IndexSetID, run.RunID, latestOpts)
 	if err != nil {
 		return fmt.Errorf("update latest.json: %w", err)
@@ -693,17 +882,20 @@ func exportableRunStatus(status indexstore.RunStatus) (string, error) {
 	}
 }
 
-// buildCompleteJSON constructs the complete.json commit marker.
+// buildCompleteJSON constructs the complete.json commit marker. sealed, when
+// set, records the envelopes of an encrypted export.
 func buildCompleteJSON(
 	indexSet *indexstore.IndexSet,
 	run *indexstore.IndexRun,
 	summary *indexstore.IndexSetSummary,
 	dbSHA256 string, dbSize int64,
 	identitySHA256 string, identitySize int64,
+	sealed *hubSealedRun,
 ) ([]byte, error) {
 	type artifactEntry struct {
-		SizeBytes int64  `json:"size_bytes"`
-		SHA256    string `json:"sha256"`
+		SizeBytes int64           `json:"size_bytes"`
+		SHA256    string          `json:"sha256"`
+		Envelope  *artifactDigest `json:"envelope,omitempty"`
 	}
 
 	type sourceInfo struct {
@@ -722,16 +914,17 @@ func buildCompleteJSON(
 	}
 
 	type completeDoc struct {
-		Version             string     `json:"version"`
-		MarkerSchemaVersion string     `json:"marker_schema_version"`
-		Format              string     `json:"format"`
-		FormatVersion       string     `json:"format_version"`
-		IndexSetID          string     `json:"index_set_id"`
-		RunID               string     `json:"run_id"`
-		CompletedAt         string     `json:"completed_at"`
-		ExportedBy          string     `json:"exported_by"`
-		Artifacts           artifacts  `json:"artifacts"`
-		Source              sourceInfo `json:"source"`
+		Version             string             `json:"version"`
+		MarkerSchemaVersion string             `json:"marker_schema_version"`
+		Format              string             `json:"format"`
+		FormatVersion       string             `json:"format_version"`
+		IndexSetID          string             `json:"index_set_id"`
+		RunID               string             `json:"run_id"`
+		CompletedAt         string             `json:"completed_at"`
+		ExportedBy          string             `json:"exported_by"`
+		Artifacts           artifacts          `json:"artifacts"`
+		Source              sourceInfo         `json:"source"`
+		Encryption          *hubEncryptionInfo `json:"encryption,omitempty"`
 	}
 
 	runStatus, err := exportableRunStatus(run.Status)
@@ -749,7 +942,7 @@ func buildCompleteJSON(
 		CompletedAt:         time.Now().UTC().Format(time.RFC3339),
 		ExportedBy:          exportedByString(),
 		Artifacts: artifacts{
-			IndexDB: artifactEntry{SizeBytes: dbSize, SHA256: dbSHA256},
+			IndexDB: artifactEntry{SizeBytes: dbSize, SHA256: dbSHA256, Envelope: sealed.envelope("index.db")},
 		},
 		Source: sourceInfo{
 			BaseURI:      indexSet.BaseURI,
@@ -757,10 +950,11 @@ func buildCompleteJSON(
 			RunStatus:    runStatus,
 			RunStartedAt: run.StartedAt.Format(time.RFC3339),
 		},
+		Encryption: sealed.encryption(),
 	}
 
 	if identitySHA256 != "" {
-		doc.Artifacts.IdentityJSON = &artifactEntry{SizeBytes: identitySize, SHA256: identitySHA256}
+		doc.Artifacts.IdentityJSON = &artifactEntry{SizeBytes: identitySize, SHA256: identitySHA256, Envelope: sealed.envelope("identity.json")}
 	}
 
 	if run.EndedAt != nil {
@@ -125,7 +125,7 @@ func TestBuildCompleteJSON_Basic(t *testing.T) {
 		TotalSizeBytes: 1024,
 	}
 
-	data, err := buildCompleteJSON(indexSet, run, summary, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, "", 0)
+	data, err := buildCompleteJSON(indexSet, run, summary, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, "", 0, nil)
 	require.NoError(t, err)
 
 	var doc map[string]interface{}
@@ -164,7 +164,7 @@ func TestBuildCompleteJSONDoesNotLeakLocalDataRoot(t *testing.T) {
 		Status:     indexstore.RunStatusSuccess,
 	}
 
-	data, err := buildCompleteJSON(indexSet, run, nil, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, "", 0)
+	data, err := buildCompleteJSON(indexSet, run, nil, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, "", 0, nil)
 	require.NoError(t, err)
 	require.NotContains(t, string(data), localRoot)
 	require.NotContains(t, string(data), filepath.Base(localRoot))
@@ -184,7 +184,7 @@ func TestBuildCompleteJSON_WithIdentity(t *testing.T) {
 	}
 
 	identitySHA := "cafecafe" + "cafecafe" + "cafecafe" + "cafecafe" + "cafecafe" + "cafecafe" + "cafecafe" + "cafecafe"
-	data, err := buildCompleteJSON(indexSet, run, nil, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, identitySHA, 256)
+	data, err := buildCompleteJSON(indexSet, run, nil, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, identitySHA, 256, nil)
 	require.NoError(t, err)
 
 	var doc map[string]interface{}
@@ -209,7 +209,7 @@ func TestBuildCompleteJSON_NoSummary(t *testing.T) {
 		Status:     indexstore.RunStatusPartial,
 	}
 
-	data, err := buildCompleteJSON(indexSet, run, nil, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, "", 0)
+	data, err := buildCompleteJSON(indexSet, run, nil, "deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef"+"deadbeef", 4096, "", 0, nil)
 	require.NoError(t, err)
 
 	var doc map[string]interface{}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/pkg/indexreader"
+	"github.com/3leaps/gonimbus/pkg/match"
+)
+
+var indexHistoryCmd = &cobra.Command{
+	Use:   "history <key>",
+	Short: "Show one object across retained durable runs",
+	Long: `Show one object's size, ETag, and last_modified in every retained
+published run of a durable-v2 index set, oldest first. History ends at the run
+latest.json names; a run committed locally but never made latest is skipped.
+
+Durable runs are immutable snapshots, so history is whatever published runs
+are still on disk. Runs removed by gc, or never hydrated from a hub, do not
+appear. SQLite indexes keep only current objects and have no history.
+
+The key is a rel_key, or a full URI under the index base URI. Each run is a
+point lookup, so only the segments that may hold the key are read.
+
+Examples:
+  gonimbus index history --index-set idx_da038d8171b4a9ba data/2026/01/report.xml
+  gonimbus index history --index-set idx_da038d8171b4a9ba s3://bucket/prefix/data/2026/01/report.xml --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIndexHistory,
+}
+
+func init() {
+	indexCmd.AddCommand(indexHistoryCmd)
+
+	indexHistoryCmd.Flags().String("index-set", "", "Index set ID (e.g., idx_da038d8171b4a9ba)")
+	indexHistoryCmd.Flags().Bool("json", false, "Output as JSON")
+	_ = indexHistoryCmd.MarkFlagRequired("index-set")
+}
+
+// Object states index history reports per run, relative to the previous
+// retained run.
+const (
+	indexHistoryPresent   = "present"
+	indexHistoryAdded     = "added"
+	indexHistoryChanged   = "changed"
+	indexHistoryUnchanged = "unchanged"
+	indexHistoryRemoved   = "removed"
+	indexHistoryAbsent    = "absent"
+)
+
+type indexHistoryObject struct {
+	SizeBytes    int64      `json:"size_bytes"`
+	ETag         string     `json:"etag"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	StorageClass *string    `json:"storage_class,omitempty"`
+}
+
+type indexHistoryEntry struct {
+	RunID       string              `json:"run_id"`
+	PublishedAt *time.Time          `json:"published_at,omitempty"`
+	Latest      bool                `json:"latest,omitempty"`
+	State       string              `json:"state"`
+	Object      *indexHistoryObject `json:"object,omitempty"`
+}
+
+type indexHistoryResult struct {
+	IndexSetID string              `json:"index_set_id"`
+	BaseURI    string              `json:"base_uri,omitempty"`
+	Key        string              `json:"key"`
+	Runs       []indexHistoryEntry `json:"runs"`
+}
+
+func runIndexHistory(cmd *cobra.Command, args []string) (err error) {
+	ctx := cmd.Context()
+	indexSetFlag, _ := cmd.Flags().GetString("index-set")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	reader, err := openIndexReader(ctx, "", strings.TrimSpace(indexSetFlag), "")
+	if err != nil {
+		return err
+	}
+	meta := reader.Meta()
+	if err := reader.Close(); err != nil {
+		return err
+	}
+	retained, err := listRetainedDurableRuns(meta)
+	if err != nil {
+		return err
+	}
+	runs := publishedDurableRuns(retained, meta.RunID)
+	if skipped := len(retained) - len(runs); skipped > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "note: skipping %d run(s) newer than latest run %s; latest.json never advanced to them\n", skipped, meta.RunID)
+	}
+	key := args[0]
+	if meta.BaseURI != "" && strings.HasPrefix(key, meta.BaseURI) {
+		key = strings.TrimPrefix(key, meta.BaseURI)
+	}
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+
+	result := indexHistoryResult{IndexSetID: meta.IndexSetID, BaseURI: meta.BaseURI, Key: key}
+	var previous *indexHistoryObject
+	// Listed newest first; history reads oldest first.
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		object, err := lookupDurableRunObject(cmd, meta.IndexSetID, run.RunID, key)
+		if err != nil {
+			return err
+		}
+		result.Runs = append(result.Runs, indexHistoryEntry{
+			RunID:       run.RunID,
+			PublishedAt: run.PublishedAt,
+			Latest:      run.RunID == meta.RunID,
+			State:       indexHistoryState(previous, object, i == len(runs)-1),
+			Object:      object,
+		})
+		previous = object
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	return printIndexHistoryTable(cmd, result)
+}
+
+// lookupDurableRunObject reads key from one pinned run, or nil when the run
+// has no current row for it.
+func lookupDurableRunObject(cmd *cobra.Command, indexSetID, runID, key string) (object *indexHistoryObject, err error) {
+	reader, err := openIndexReader(cmd.Context(), "", indexSetID, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.Join(err, reader.Close()) }()
+	results, _, err := reader.LookupKeys(cmd.Context(), []string{key})
+	if err != nil {
+		return nil, fmt.Errorf("lookup in run %s: %w", runID, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &indexHistoryObject{
+		SizeBytes:    results[0].SizeBytes,
+		ETag:         results[0].ETag,
+		LastModified: results[0].LastModified,
+		StorageClass: results[0].StorageClass,
+	}, nil
+}
+
+func indexHistoryState(previous, current *indexHistoryObject, first bool) string {
+	switch {
+	case current == nil && previous != nil:
+		return indexHistoryRemoved
+	case current == nil:
+		return indexHistoryAbsent
+	case first:
+		return indexHistoryPresent
+	case previous == nil:
+		return indexHistoryAdded
+	case previous.SizeBytes != current.SizeBytes || previous.ETag != current.ETag || !timePtrEqual(previous.LastModified, current.LastModified):
+		return indexHistoryChanged
+	default:
+		return indexHistoryUnchanged
+	}
+}
+
+func timePtrEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func printIndexHistoryTable(cmd *cobra.Command, result indexHistoryResult) error {
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Index set: %s\n", result.IndexSetID)
+	_, _ = fmt.Fprintf(out, "Key: %s\n", result.Key)
+	_, _ = fmt.Fprintf(out, "Retained runs: %d\n\n", len(result.Runs))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "RUN\tPUBLISHED\tSTATE\tSIZE\tETAG\tLAST MODIFIED")
+	for _, entry := range result.Runs {
+		runID := entry.RunID
+		if entry.Latest {
+			runID += " (latest)"
+		}
+		size, etag, modified := "-", "-", "-"
+		if entry.Object != nil {
+			size = formatBytes(entry.Object.SizeBytes)
+			etag = valueOrDefault(entry.Object.ETag, "-")
+			if entry.Object.LastModified != nil {
+				modified = entry.Object.LastModified.UTC().Format(time.RFC3339)
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", runID, formatHistoryTime(entry.PublishedAt), entry.State, size, etag, modified)
+	}
+	return w.Flush()
+}
+
+func formatHistoryTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// listRetainedDurableRuns returns the published runs of a durable-v2 set whose
+// complete markers still open on disk, newest first. gc removes whole runs, so
+// a run missing here can no longer be queried.
+func listRetainedDurableRuns(meta indexreader.Meta) ([]durablePublishedRun, error) {
+	if meta.Format != indexreader.FormatDurableV2 {
+		return nil, fmt.Errorf("index set %s is %s; historical queries need a durable-v2 index because SQLite keeps only current objects", meta.IndexSetID, formatLabel(meta.Format))
+	}
+	opts, err := indexReaderResolveOptions()
+	if err != nil {
+		return nil, err
+	}
+	segmentRoot, err := indexSubstrateSegmentCacheDir(meta.IndexSetID)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := listDurablePublishedRuns(segmentRoot, opts.MaxMarkerBytes, opts.MaxManifestBytes)
+	if err != nil {
+		return nil, fmt.Errorf("list retained runs: %w", err)
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("index set %s has no retained published runs", meta.IndexSetID)
+	}
+	return runs, nil
+}
+
+// findRetainedDurableRun returns runID when it is still retained.
+func findRetainedDurableRun(runs []durablePublishedRun, runID string) (durablePublishedRun, error) {
+	for _, run := range runs {
+		if run.RunID == runID {
+			return run, nil
+		}
+	}
+	oldest := runs[len(runs)-1]
+	return durablePublishedRun{}, fmt.Errorf("run %s is not retained locally; it may have been removed by gc (oldest retained run is %s)", runID, oldest.RunID)
+}
+
+// resolveDurableAsOf picks the retained run an --as-of value names: a run ID,
+// or a time, which selects the newest retained run published at or before it.
+// A time never selects a run newer than latestRunID: a complete marker the
+// latest pointer did not advance to is not part of the published history.
+func resolveDurableAsOf(runs []durablePublishedRun, latestRunID, asOf string) (durablePublishedRun, error) {
+	asOf = strings.TrimSpace(asOf)
+	if validateRunID(asOf) == nil {
+		return findRetainedDurableRun(runs, asOf)
+	}
+	at, err := match.ParseDate(asOf)
+	if err != nil {
+		return durablePublishedRun{}, fmt.Errorf("--as-of must be a run ID or a date/time (YYYY-MM-DD or RFC3339): %w", err)
+	}
+	for _, run := range publishedDurableRuns(runs, latestRunID) {
+		if published := durableSortTime(run); !published.IsZero() && !published.After(at) {
+			return run, nil
+		}
+	}
+	oldest := runs[len(runs)-1]
+	return durablePublishedRun{}, fmt.Errorf("no retained run was published at or before %s; the oldest retained run is %s (published %s)", at.Format(time.RFC3339), oldest.RunID, formatHistoryTime(oldest.PublishedAt))
+}
+
+// publishedDurableRuns drops the retained runs newer than latestRunID: a
+// complete marker the latest pointer did not advance to is not part of the
+// published history.
+func publishedDurableRuns(runs []durablePublishedRun, latestRunID string) []durablePublishedRun {
+	for i, run := range runs {
+		if run.RunID == latestRunID {
+			return runs[i:]
+		}
+	}
+	return runs
+}
+
+// noteAsOfRun tells the operator which run an --as-of value resolved to.
+func noteAsOfRun(asOf string, run durablePublishedRun) {
+	_, _ = fmt.Fprintf(os.Stderr, "note: --as-of %s resolved to run %s (published %s)\n", strings.TrimSpace(asOf), run.RunID, formatHistoryTime(run.PublishedAt))
+}
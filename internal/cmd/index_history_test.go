@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/atlas"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+const (
+	historyRunTwo   = "run_1744459200000000000"
+	historyRunThree = "run_1744632000000000000"
+)
+
+// seedDurableHistory publishes three runs of one set: run_cli_1 on April 10,
+// then a run that changes one.json and drops two.xml, then one that restores
+// two.xml.
+func seedDurableHistory(t *testing.T) (durableCLIEnv, string) {
+	t.Helper()
+	resetAppDataRootTestState(t)
+	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
+	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
+	mod := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	env := seedDurableOnlyAppData(t, dataRoot, []indexsubstrate.CurrentObjectRow{
+		durableCLIRow("data/one.json", 10, "e1", mod),
+		durableCLIRow("data/two.json", 20, "e2", mod),
+	})
+	segmentRoot := filepath.Join(dataRoot, "cache", "segments", env.indexSetID)
+	publishDurableCLIRun(t, segmentRoot, env.indexSetID, historyRunTwo, time.Date(2025, 4, 12, 12, 0, 0, 0, time.UTC), []indexsubstrate.CurrentObjectRow{
+		durableCLIRow("data/one.json", 11, "e1b", mod.Add(24*time.Hour)),
+	})
+	publishDurableCLIRun(t, segmentRoot, env.indexSetID, historyRunThree, time.Date(2025, 4, 14, 12, 0, 0, 0, time.UTC), []indexsubstrate.CurrentObjectRow{
+		durableCLIRow("data/one.json", 11, "e1b", mod.Add(24*time.Hour)),
+		durableCLIRow("data/two.json", 20, "e2", mod),
+	})
+	return env, segmentRoot
+}
+
+// TestIndexQuery_AsOfResolvesRetainedRun pins --as-of: a time selects the
+// newest retained run published at or before it, a run ID must still be
+// retained, and a run removed from disk is no longer selectable.
+func TestIndexQuery_AsOfResolvesRetainedRun(t *testing.T) {
+	env, segmentRoot := seedDurableHistory(t)
+
+	stdout, stderr, err := executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--as-of", "2025-04-13")
+	require.NoError(t, err, "stderr=%q", stderr)
+	require.Contains(t, stderr, "resolved to run "+historyRunTwo)
+	lines := nonEmptyLines(stdout)
+	require.Len(t, lines, 1)
+	var record indexQueryRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	require.Equal(t, "data/one.json", record.Data.RelKey)
+	require.EqualValues(t, 11, record.Data.SizeBytes)
+	require.Equal(t, env.baseURI, record.Data.BaseURI)
+
+	stdout, _, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--as-of", "2025-04-11T00:00:00Z", "--count")
+	require.NoError(t, err)
+	require.Equal(t, "2", nonEmptyLines(stdout)[0])
+
+	_, _, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--as-of", "2025-04-01")
+	require.ErrorContains(t, err, "no retained run was published at or before 2025-04-01T00:00:00Z")
+
+	// gc removes whole runs; a removed run is gone from history.
+	require.NoError(t, os.RemoveAll(filepath.Join(segmentRoot, "runs", historyRunTwo)))
+	_, _, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--as-of", historyRunTwo)
+	require.ErrorContains(t, err, "run "+historyRunTwo+" is not retained locally")
+	_, stderr, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--as-of", "2025-04-13", "--count")
+	require.NoError(t, err)
+	require.Contains(t, stderr, "resolved to run run_cli_1")
+
+	_, _, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--as-of", "2025-04-13", "--since-run", historyRunThree)
+	require.ErrorContains(t, err, "do not combine them")
+}
+
+// TestIndexHistory_ReportsObjectAcrossRuns pins the per-run states history
+// derives from point lookups, oldest run first.
+func TestIndexHistory_ReportsObjectAcrossRuns(t *testing.T) {
+	env, _ := seedDurableHistory(t)
+
+	history := func(key string) indexHistoryResult {
+		t.Helper()
+		cmd := &cobra.Command{Use: "history", Args: cobra.ExactArgs(1), RunE: runIndexHistory}
+		cmd.Flags().String("index-set", "", "")
+		cmd.Flags().Bool("json", false, "")
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--index-set", env.indexSetID, "--json", key})
+		require.NoError(t, cmd.Execute())
+		var result indexHistoryResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+		return result
+	}
+	states := func(result indexHistoryResult) []string {
+		var out []string
+		for _, entry := range result.Runs {
+			out = append(out, entry.State)
+		}
+		return out
+	}
+
+	one := history(env.baseURI + "data/one.json")
+	require.Equal(t, "data/one.json", one.Key)
+	require.Equal(t, []string{"run_cli_1", historyRunTwo, historyRunThree}, []string{one.Runs[0].RunID, one.Runs[1].RunID, one.Runs[2].RunID})
+	require.Equal(t, []string{"present", "changed", "unchanged"}, states(one))
+	require.Equal(t, "e1b", one.Runs[1].Object.ETag)
+	require.EqualValues(t, 11, one.Runs[1].Object.SizeBytes)
+	require.True(t, one.Runs[2].Latest)
+
+	two := history("data/two.json")
+	require.Equal(t, []string{"present", "removed", "added"}, states(two))
+	require.Nil(t, two.Runs[1].Object)
+
+	require.Equal(t, []string{"absent", "absent", "absent"}, states(history("data/missing.json")))
+}
+
+// TestIndexHistory_StopsAtLatest pins that history, like --as-of, leaves out a
+// run whose complete marker is newer than the run latest.json names.
+func TestIndexHistory_StopsAtLatest(t *testing.T) {
+	env, segmentRoot := seedDurableHistory(t)
+	latestPath := filepath.Join(segmentRoot, "latest.json")
+	latest, err := os.ReadFile(latestPath)
+	require.NoError(t, err)
+	unpublished := "run_1744804800000000000"
+	publishDurableCLIRun(t, segmentRoot, env.indexSetID, unpublished, time.Date(2025, 4, 16, 12, 0, 0, 0, time.UTC), []indexsubstrate.CurrentObjectRow{
+		durableCLIRow("data/one.json", 99, "e1c", time.Date(2025, 4, 16, 0, 0, 0, 0, time.UTC)),
+	})
+	require.NoError(t, os.WriteFile(latestPath, latest, 0o644))
+
+	cmd := &cobra.Command{Use: "history", Args: cobra.ExactArgs(1), RunE: runIndexHistory}
+	cmd.Flags().String("index-set", "", "")
+	cmd.Flags().Bool("json", false, "")
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--index-set", env.indexSetID, "--json", "data/one.json"})
+	require.NoError(t, cmd.Execute())
+	var result indexHistoryResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	var runIDs []string
+	for _, entry := range result.Runs {
+		runIDs = append(runIDs, entry.RunID)
+	}
+	require.Equal(t, []string{"run_cli_1", historyRunTwo, historyRunThree}, runIDs)
+	require.True(t, result.Runs[2].Latest)
+}
+
+// TestRunAtlasBuild_DurableRetainedRun pins that atlas builds against an
+// earlier retained durable run, reading objects as that run published them.
+func TestRunAtlasBuild_DurableRetainedRun(t *testing.T) {
+	env, _ := seedDurableHistory(t)
+	recipePath := filepath.Join(t.TempDir(), "atlas-recipe.yaml")
+	require.NoError(t, os.WriteFile(recipePath, []byte(`version: "1.0"
+coverage: full
+dimensions:
+  - name: event_date
+    kind: temporal-day
+    classification: 1-confidential
+    extractor:
+      type: json_path
+      json_path: $.event_date
+shard_by: [event_date]
+`), 0o644))
+	oldFactory := newAtlasBuildProvider
+	newAtlasBuildProvider = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (atlasBuildProvider, error) {
+		return fakeAtlasBuildProvider{
+			"data/data/one.json": []byte(`{"event_date":"2025-04-02"}`),
+			"data/data/two.json": []byte(`{"event_date":"2025-04-01"}`),
+		}, nil
+	}
+	t.Cleanup(func() { newAtlasBuildProvider = oldFactory })
+
+	build := func(runID string) (atlas.Header, error) {
+		cmd := newAtlasBuildTestCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs([]string{"--from-index", env.indexSetID, "--run", runID, "--recipe", recipePath, "--output", filepath.Join(t.TempDir(), "atlas"), "--json"})
+		cmd.SetContext(context.Background())
+		if err := cmd.Execute(); err != nil {
+			return atlas.Header{}, err
+		}
+		var header atlas.Header
+		require.NoError(t, json.Unmarshal(out.Bytes(), &header))
+		return header, nil
+	}
+
+	header, err := build(historyRunTwo)
+	require.NoError(t, err)
+	require.Equal(t, historyRunTwo, header.SourceRunID)
+	require.Equal(t, env.indexSetID, header.SourceIndexSetID)
+	require.Equal(t, int64(1), header.Counts.RowsWritten)
+
+	header, err = build("run_cli_1")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), header.Counts.RowsWritten)
+
+	_, err = build("run_1700000000000000000")
+	require.ErrorContains(t, err, "is not retained locally")
+}
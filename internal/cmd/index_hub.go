@@ -79,7 +79,9 @@ var indexHubSetLatestCmd = &cobra.Command{
 	Short: "Repoint latest.json to a specific run",
 	Long: `Update the latest.json pointer for an index set to a specific run.
 
-The target run must have a valid complete.json commit marker.
+The target run must have a valid complete.json commit marker. With --sign-key
+(or GONIMBUS_SIGN_KEY_FILE / GONIMBUS_SIGN_KEY) the new pointer carries an
+inline signature; hydrates under --trusted-keys refuse an unsigned pointer.
 
 Examples:
   gonimbus index hub set-latest file:///tmp/gonimbus-hub/ \
@@ -116,6 +118,16 @@ Retention modes (one required):
   --keep N       Keep the N most recent committed runs
   --before DATE  Remove committed runs older than DATE (RFC 3339 or YYYY-MM-DD)
 
+Durable runs exported with marker schema v2 share segments through
+<index_set>/segments/sha256/. After pruning runs, gc deletes a shared segment
+only when no remaining run's complete.json references it and it is older than
+--segment-grace, which protects segments uploaded by an export that has not yet
+written its complete.json. An export also leaves runs/<run>/pending.json until
+it commits; while that marker is younger than --segment-grace the run is kept
+and its index set's shared segments are not swept, since the export may be
+reusing segments no committed run references. An index set with an unreadable
+complete.json is not swept. 'index hub rm-run' never deletes shared segments; the next gc does.
+
 Examples:
   gonimbus index hub gc file:///tmp/gonimbus-hub/ --keep 3
   gonimbus index hub gc --hub s3://my-bucket/ops/index-hub/ --index-set idx_da038d... --before 2026-01-01
@@ -156,6 +168,7 @@ func init() {
 	indexHubSetLatestCmd.Flags().String("index-set", "", "Index set ID (required)")
 	indexHubSetLatestCmd.Flags().String("run-id", "", "Run ID to set as latest (required)")
 	addLatestPointerFlags(indexHubSetLatestCmd)
+	addHubSignKeyFlag(indexHubSetLatestCmd)
 	_ = indexHubSetLatestCmd.MarkFlagRequired("index-set")
 	_ = indexHubSetLatestCmd.MarkFlagRequired("run-id")
 
@@ -171,6 +184,7 @@ func init() {
 	indexHubGCCmd.Flags().Int("keep", 0, "Keep the N most recent committed runs per index set")
 	indexHubGCCmd.Flags().String("before", "", "Remove committed runs older than this date (RFC 3339 or YYYY-MM-DD)")
 	indexHubGCCmd.Flags().Bool("dry-run", false, "Show what would be removed without deleting")
+	indexHubGCCmd.Flags().Duration("segment-grace", defaultHubSegmentGrace, "Keep unreferenced shared segments younger than this")
 	indexHubGCCmd.Flags().Bool("json", false, "Output as JSON")
 }
 
@@ -295,6 +309,8 @@ type hubIndexSetInfo struct {
 	LatestFormat string         `json:"latest_format,omitempty"`
 	RunCount     int            `json:"run_count"`
 	FormatCounts map[string]int `json:"format_counts,omitempty"`
+	// EncryptedRuns counts committed runs whose artifacts are sealed.
+	EncryptedRuns int `json:"encrypted_runs,omitempty"`
 }
 
 type hubArtifactSummary struct {
@@ -304,6 +320,8 @@ type hubArtifactSummary struct {
 	IdentityJSON  bool  `json:"identity_json,omitempty"`
 	Manifest      bool  `json:"manifest,omitempty"`
 	Segments      int   `json:"segments,omitempty"`
+	PrefixStats   bool  `json:"prefix_stats,omitempty"`
+	KeyIndex      bool  `json:"key_index,omitempty"`
 	RequiredCount int   `json:"required_count,omitempty"`
 }
 
@@ -312,6 +330,10 @@ type hubRunMarkerSummary struct {
 	FormatVersion string             `json:"format_version,omitempty"`
 	CompletedAt   string             `json:"completed_at,omitempty"`
 	Artifacts     hubArtifactSummary `json:"artifacts"`
+	Encryption    *hubEncryptionInfo `json:"encryption,omitempty"`
+	// SharedSegments lists the content paths a v2 durable marker references
+	// in the index set's shared segment store.
+	SharedSegments []string `json:"-"`
 }
 
 type hubCompleteEnvelope struct {
@@ -374,6 +396,9 @@ func runIndexHubLs(cmd *cobra.Command, args []string) error {
 				continue
 			}
 			info.FormatCounts[summary.Format]++
+			if summary.Encryption != nil {
+				info.EncryptedRuns++
+			}
 			if runID == info.LatestRun {
 				info.LatestFormat = summary.Format
 			}
@@ -427,20 +452,28 @@ func readHubLatestPointer(ctx context.Context, getter provider.ObjectGetter, key
 		}
 		return latestPointerDoc{}, false, err
 	}
+	latest, err := parseHubLatestPointer(data, expectedIndexSetID)
+	if err != nil {
+		return latestPointerDoc{}, false, err
+	}
+	return latest, true, nil
+}
+
+func parseHubLatestPointer(data []byte, expectedIndexSetID string) (latestPointerDoc, error) {
 	var latest latestPointerDoc
 	if err := json.Unmarshal(data, &latest); err != nil {
-		return latestPointerDoc{}, false, fmt.Errorf("parse latest.json: %w", err)
+		return latestPointerDoc{}, fmt.Errorf("parse latest.json: %w", err)
 	}
 	if strings.TrimSpace(latest.RunID) == "" {
-		return latestPointerDoc{}, false, fmt.Errorf("parse latest.json: run_id is required")
+		return latestPointerDoc{}, fmt.Errorf("parse latest.json: run_id is required")
 	}
 	if err := validateRunID(latest.RunID); err != nil {
-		return latestPointerDoc{}, false, fmt.Errorf("parse latest.json: invalid run_id: %w", err)
+		return latestPointerDoc{}, fmt.Errorf("parse latest.json: invalid run_id: %w", err)
 	}
 	if latest.IndexSetID != "" && latest.IndexSetID != expectedIndexSetID {
-		return latestPointerDoc{}, false, fmt.Errorf("parse latest.json: index_set_id %q does not match %q", latest.IndexSetID, expectedIndexSetID)
+		return latestPointerDoc{}, fmt.Errorf("parse latest.json: index_set_id %q does not match %q", latest.IndexSetID, expectedIndexSetID)
 	}
-	return latest, true, nil
+	return latest, nil
 }
 
 func summarizeHubRunMarker(data []byte) (hubRunMarkerSummary, error) {
@@ -453,10 +486,12 @@ func summarizeHubRunMarker(data []byte) (hubRunMarkerSummary, error) {
 		format = "unknown"
 	}
 	return hubRunMarkerSummary{
-		Format:        format,
-		FormatVersion: strings.TrimSpace(complete.FormatVersion),
-		CompletedAt:   strings.TrimSpace(complete.CompletedAt),
-		Artifacts:     summarizeHubArtifacts(complete.completeMarker),
+		Format:         format,
+		FormatVersion:  strings.TrimSpace(complete.FormatVersion),
+		CompletedAt:    strings.TrimSpace(complete.CompletedAt),
+		Artifacts:      summarizeHubArtifacts(complete.completeMarker),
+		Encryption:     complete.Encryption,
+		SharedSegments: hubSharedSegmentRefs(complete.completeMarker),
 	}, nil
 }
 
@@ -467,8 +502,9 @@ func summarizeHubArtifacts(complete completeMarker) hubArtifactSummary {
 			return
 		}
 		summary.Count++
-		if ref.SizeBytes > 0 {
-			summary.TotalBytes += ref.SizeBytes
+		// Bytes held at the hub: an envelope's, for a sealed artifact.
+		if size := ref.stored().SizeBytes; size > 0 {
+			summary.TotalBytes += size
 		}
 		if ref.Required {
 			summary.RequiredCount++
@@ -490,6 +526,14 @@ func summarizeHubArtifacts(complete completeMarker) hubArtifactSummary {
 		summary.Segments++
 		add(&complete.Artifacts.Segments[i])
 	}
+	if complete.Artifacts.PrefixStats != nil {
+		summary.PrefixStats = true
+		add(complete.Artifacts.PrefixStats)
+	}
+	if complete.Artifacts.KeyIndex != nil {
+		summary.KeyIndex = true
+		add(complete.Artifacts.KeyIndex)
+	}
 	return summary
 }
 
@@ -597,6 +641,7 @@ type hubRunInfo struct {
 	FormatVersion string              `json:"format_version,omitempty"`
 	CompletedAt   string              `json:"completed_at,omitempty"`
 	Artifacts     *hubArtifactSummary `json:"artifacts,omitempty"`
+	Encryption    *hubEncryptionInfo  `json:"encryption,omitempty"`
 	Complete      json.RawMessage     `json:"complete,omitempty"`
 }
 
@@ -662,6 +707,7 @@ func runIndexHubShow(cmd *cobra.Command, args []string) error {
 				info.FormatVersion = summary.FormatVersion
 				info.CompletedAt = summary.CompletedAt
 				info.Artifacts = &summary.Artifacts
+				info.Encryption = summary.Encryption
 			}
 		}
 
@@ -692,7 +738,7 @@ func runIndexHubShow(cmd *cobra.Command, args []string) error {
 	_, _ = fmt.Fprintf(os.Stdout, "Runs:      %d\n\n", len(runs))
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "RUN ID\tCOMMITTED\tLATEST\tFORMAT\tARTIFACTS\tBYTES")
+	_, _ = fmt.Fprintln(w, "RUN ID\tCOMMITTED\tLATEST\tFORMAT\tARTIFACTS\tBYTES\tENCRYPTED")
 	for _, r := range runs {
 		committed := "no"
 		if r.IsCommitted {
@@ -714,7 +760,11 @@ func runIndexHubShow(cmd *cobra.Command, args []string) error {
 				totalBytes = fmt.Sprintf("%d", r.Artifacts.TotalBytes)
 			}
 		}
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.RunID, committed, latest, format, artifactCount, totalBytes)
+		encrypted := "-"
+		if r.Encryption != nil {
+			encrypted = strings.Join(r.Encryption.Recipients, ",")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.RunID, committed, latest, format, artifactCount, totalBytes, encrypted)
 	}
 	return w.Flush()
 }
@@ -908,9 +958,10 @@ type gcRunCandidate struct {
 // entry carries an Error if its deletion failed, and Errors is the count of
 // such failures.
 type gcResult struct {
-	DryRun  bool             `json:"dry_run"`
-	Removed []gcRunCandidate `json:"removed"`
-	Errors  int              `json:"errors,omitempty"`
+	DryRun         bool              `json:"dry_run"`
+	Removed        []gcRunCandidate  `json:"removed"`
+	SharedSegments []gcSharedSegment `json:"shared_segments,omitempty"`
+	Errors         int               `json:"errors,omitempty"`
 }
 
 func warnRetainingUnreadableComplete(indexSetID, runID, reason string) {
@@ -924,6 +975,10 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 	beforeStr, _ := cmd.Flags().GetString("before")
 	dryRun, _ := cmd.Flags().GetBool("dry-run")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
+	segmentGrace := defaultHubSegmentGrace
+	if cmd.Flags().Lookup("segment-grace") != nil {
+		segmentGrace, _ = cmd.Flags().GetDuration("segment-grace")
+	}
 
 	if keep == 0 && beforeStr == "" {
 		return fmt.Errorf("one of --keep or --before is required")
@@ -934,6 +989,9 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 	if keep < 0 {
 		return fmt.Errorf("--keep must be positive")
 	}
+	if segmentGrace < 0 {
+		return fmt.Errorf("--segment-grace must not be negative")
+	}
 
 	var beforeTime time.Time
 	if beforeStr != "" {
@@ -981,6 +1039,7 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 	}
 
 	var toRemove []gcRunCandidate
+	var segmentSets []gcSegmentSet
 	for _, setID := range indexSetIDs {
 		var latestRunID string
 		latestKey := hubArtifactKey(hub, "index-sets", setID, "latest.json")
@@ -1008,6 +1067,8 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 			completedAt  time.Time
 			hasComplete  bool
 			retainReason string
+			sharedRefs   []string
+			inFlight     bool
 		}
 		candidates := make([]runWithTime, 0, len(runIDs))
 		for _, runID := range runIDs {
@@ -1016,6 +1077,11 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 			if data, getErr := downloadBytesBounded(ctx, getter, completeKey, maxHubCompleteMarkerBytes, "complete.json"); getErr != nil {
 				if !provider.IsNotFound(getErr) {
 					r.retainReason = fmt.Sprintf("read complete.json: %v", getErr)
+				} else if inFlight, pendingErr := hubRunInFlight(ctx, getter, hub, setID, runID, segmentGrace, time.Now()); inFlight {
+					if pendingErr != nil {
+						_, _ = fmt.Fprintf(os.Stderr, "warning: treating run %s/%s as in flight: %v\n", setID, runID, pendingErr)
+					}
+					r.inFlight = true
 				}
 			} else {
 				r.hasComplete = true
@@ -1025,6 +1091,7 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 				} else {
 					r.format = summary.Format
 					r.artifacts = summary.Artifacts
+					r.sharedRefs = summary.SharedSegments
 					completedAt := strings.TrimSpace(summary.CompletedAt)
 					if completedAt == "" {
 						r.retainReason = "parse complete.json: completed_at is required for GC"
@@ -1038,6 +1105,18 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 			candidates = append(candidates, r)
 		}
 
+		segmentSet := gcSegmentSet{IndexSetID: setID, RunRefs: make(map[string][]string, len(candidates))}
+		for _, r := range candidates {
+			if r.retainReason != "" {
+				segmentSet.Blocked = true
+			}
+			if r.inFlight {
+				segmentSet.InFlight = append(segmentSet.InFlight, r.runID)
+			}
+			segmentSet.RunRefs[r.runID] = r.sharedRefs
+		}
+		segmentSets = append(segmentSets, segmentSet)
+
 		// Sort by completion time (newest first) for --keep
 		sort.Slice(candidates, func(i, j int) bool {
 			return candidates[i].completedAt.After(candidates[j].completedAt)
@@ -1075,6 +1154,9 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 				if r.isLatest {
 					continue // latest is always kept, even in a corrupt partial hub
 				}
+				if r.inFlight {
+					continue // still being exported; not an abandoned partial run
+				}
 				if !r.hasComplete {
 					toRemove = append(toRemove, gcRunCandidate{
 						IndexSetID: setID,
@@ -1105,6 +1187,9 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 					warnRetainingUnreadableComplete(setID, r.runID, r.retainReason)
 					continue
 				}
+				if r.inFlight {
+					continue
+				}
 				if !r.hasComplete {
 					toRemove = append(toRemove, gcRunCandidate{
 						IndexSetID: setID,
@@ -1128,23 +1213,42 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 
+	// Shared segments are planned as if every removal succeeds. A real run
+	// plans again after deleting, when failed removals are known.
+	now := time.Now()
+	var orphans []gcSharedSegment
+	if dryRun || len(toRemove) == 0 {
+		orphans = planGCSharedSegments(ctx, lister, hub, segmentSets, toRemove, segmentGrace, now)
+	}
+
 	// Empty case
-	if len(toRemove) == 0 {
+	if len(toRemove) == 0 && len(orphans) == 0 {
 		if jsonOutput {
 			return enc.Encode(gcResult{DryRun: dryRun, Removed: []gcRunCandidate{}})
 		}
 		_, _ = fmt.Fprintln(os.Stderr, "Nothing to remove")
 		return nil
 	}
+	if toRemove == nil {
+		toRemove = []gcRunCandidate{}
+	}
 
 	// Dry-run case: report candidates and stop. No deletion.
 	if dryRun {
 		if jsonOutput {
-			return enc.Encode(gcResult{DryRun: true, Removed: toRemove})
+			return enc.Encode(gcResult{DryRun: true, Removed: toRemove, SharedSegments: orphans})
+		}
+		if len(toRemove) > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Would remove %d run(s):\n", len(toRemove))
+			for _, r := range toRemove {
+				_, _ = fmt.Fprintf(os.Stderr, "  %s / %s (%s)\n", r.IndexSetID, r.RunID, gcVisibilityString(r))
+			}
 		}
-		_, _ = fmt.Fprintf(os.Stderr, "Would remove %d run(s):\n", len(toRemove))
-		for _, r := range toRemove {
-			_, _ = fmt.Fprintf(os.Stderr, "  %s / %s (%s)\n", r.IndexSetID, r.RunID, gcVisibilityString(r))
+		if len(orphans) > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "Would remove %d unreferenced shared segment(s):\n", len(orphans))
+			for _, o := range orphans {
+				_, _ = fmt.Fprintf(os.Stderr, "  %s / %s (%d bytes)\n", o.IndexSetID, path.Base(o.Key), o.SizeBytes)
+			}
 		}
 		return nil
 	}
@@ -1205,10 +1309,29 @@ func runIndexHubGC(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(toRemove) > 0 {
+		orphans = planGCSharedSegments(ctx, lister, hub, segmentSets, toRemove, segmentGrace, now)
+	}
+	segmentCount := 0
+	for i, o := range orphans {
+		if delErr := deleter.DeleteObject(ctx, o.Key); delErr != nil {
+			orphans[i].Error = fmt.Sprintf("delete: %v", delErr)
+			errors++
+			if !jsonOutput {
+				_, _ = fmt.Fprintf(os.Stderr, "warning: failed to delete %s: %v\n", o.Key, delErr)
+			}
+			continue
+		}
+		segmentCount++
+	}
+
 	if jsonOutput {
-		return enc.Encode(gcResult{DryRun: false, Removed: toRemove, Errors: errors})
+		return enc.Encode(gcResult{DryRun: false, Removed: toRemove, SharedSegments: orphans, Errors: errors})
 	}
 	_, _ = fmt.Fprintf(os.Stderr, "GC complete: removed %d run(s)\n", successCount)
+	if len(orphans) > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "  removed %d unreferenced shared segment(s)\n", segmentCount)
+	}
 	if errors > 0 {
 		_, _ = fmt.Fprintf(os.Stderr, "  with %d error(s)\n", errors)
 	}
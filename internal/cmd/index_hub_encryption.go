@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/internal/envelope"
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// Encrypted exports seal every artifact that carries key listings (index.db
+// and identity.json, or the durable manifest, segments, and prefix stats) in
+// an envelope before upload. complete.json and latest.json stay in the clear:
+// they hold digests, counts, and the recipients' key IDs, which is what hub
+// ls, show, gc, and sync need. An artifact's sha256 and size_bytes keep
+// describing its plaintext; its envelope digest describes the object the hub
+// holds, and a shared segment's content path names that digest.
+
+const (
+	// hubEncryptToEnv names the recipients file used when --encrypt-to is unset.
+	hubEncryptToEnv = "GONIMBUS_ENCRYPT_TO"
+	// hubDecryptKeyFileEnv names a PEM key file used when --decrypt-key is unset.
+	hubDecryptKeyFileEnv = "GONIMBUS_DECRYPT_KEY_FILE"
+	// hubDecryptKeyEnv holds the PEM key itself, for CI secrets that are not files.
+	hubDecryptKeyEnv = "GONIMBUS_DECRYPT_KEY"
+)
+
+func addHubEncryptToFlag(cmd *cobra.Command) {
+	cmd.Flags().String("encrypt-to", "", "PEM file of X25519 public keys; artifacts are encrypted so any one of them can decrypt (default: $"+hubEncryptToEnv+")")
+}
+
+func addHubDecryptKeyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("decrypt-key", "", "PEM PKCS#8 X25519 private key that decrypts encrypted runs (default: $"+hubDecryptKeyFileEnv+" or $"+hubDecryptKeyEnv+")")
+}
+
+// hubRecipientsFromCommand resolves the optional encryption recipients from
+// --encrypt-to or GONIMBUS_ENCRYPT_TO. nil means the export is not encrypted.
+func hubRecipientsFromCommand(cmd *cobra.Command) ([]*ecdh.PublicKey, error) {
+	path, _ := cmd.Flags().GetString("encrypt-to")
+	if strings.TrimSpace(path) == "" {
+		path = os.Getenv(hubEncryptToEnv)
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	keys, err := envelope.LoadRecipients(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --encrypt-to: %w", err)
+	}
+	return keys, nil
+}
+
+// hubDecryptKeyFromCommand resolves the optional decryption key: --decrypt-key,
+// then the key file named by GONIMBUS_DECRYPT_KEY_FILE, then PEM text in
+// GONIMBUS_DECRYPT_KEY. It returns nil when none is configured.
+func hubDecryptKeyFromCommand(cmd *cobra.Command) (*ecdh.PrivateKey, error) {
+	path, _ := cmd.Flags().GetString("decrypt-key")
+	if strings.TrimSpace(path) == "" {
+		path = os.Getenv(hubDecryptKeyFileEnv)
+	}
+	if strings.TrimSpace(path) != "" {
+		key, err := envelope.LoadIdentity(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --decrypt-key: %w", err)
+		}
+		return key, nil
+	}
+	if raw := os.Getenv(hubDecryptKeyEnv); strings.TrimSpace(raw) != "" {
+		key, err := envelope.ParseIdentity([]byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", hubDecryptKeyEnv, err)
+		}
+		return key, nil
+	}
+	return nil, nil
+}
+
+// hubEncryptionInfo is the cleartext encryption block of an encrypted run's
+// complete.json.
+type hubEncryptionInfo struct {
+	Scheme     string   `json:"scheme"`
+	Cipher     string   `json:"cipher"`
+	KeyWrap    string   `json:"key_wrap"`
+	Recipients []string `json:"recipients"`
+}
+
+func newHubEncryptionInfo(recipients []*ecdh.PublicKey) hubEncryptionInfo {
+	ids := make([]string, 0, len(recipients))
+	for _, pub := range recipients {
+		ids = append(ids, envelope.KeyID(pub))
+	}
+	slices.Sort(ids)
+	return hubEncryptionInfo{Scheme: envelope.Scheme, Cipher: envelope.Cipher, KeyWrap: envelope.KeyWrapX25519, Recipients: ids}
+}
+
+// sameRecipients reports whether two runs were encrypted to the same keys.
+func (e hubEncryptionInfo) sameRecipients(other hubEncryptionInfo) bool {
+	a, b := slices.Clone(e.Recipients), slices.Clone(other.Recipients)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// artifactDigest pins the bytes of an object by SHA-256 and size.
+type artifactDigest struct {
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// stored returns ref as the hub holds it: for a sealed artifact, the envelope
+// digest in place of the plaintext's.
+func (r artifactRef) stored() artifactRef {
+	if r.Envelope != nil {
+		r.SHA256 = r.Envelope.SHA256
+		r.SizeBytes = r.Envelope.SizeBytes
+		r.Envelope = nil
+	}
+	return r
+}
+
+// hubSealedRun records how an encrypted export stored a run: the encryption
+// block and the envelope digest of each artifact, keyed by the artifact's
+// path in complete.json (index.db, manifest.json, segments/<name>, ...).
+// A nil *hubSealedRun means the run is not encrypted.
+type hubSealedRun struct {
+	Recipients []*ecdh.PublicKey
+	Info       hubEncryptionInfo
+	Envelopes  map[string]artifactDigest
+}
+
+func newHubSealedRun(recipients []*ecdh.PublicKey) *hubSealedRun {
+	if len(recipients) == 0 {
+		return nil
+	}
+	return &hubSealedRun{Recipients: recipients, Info: newHubEncryptionInfo(recipients), Envelopes: map[string]artifactDigest{}}
+}
+
+// announce reports the recipients an export encrypts to.
+func (s *hubSealedRun) announce() {
+	if s != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "  encrypting artifacts to key_id %s\n", strings.Join(s.Info.Recipients, ", "))
+	}
+}
+
+func (s *hubSealedRun) encryption() *hubEncryptionInfo {
+	if s == nil {
+		return nil
+	}
+	info := s.Info
+	return &info
+}
+
+func (s *hubSealedRun) envelope(path string) *artifactDigest {
+	if s == nil {
+		return nil
+	}
+	if digest, ok := s.Envelopes[path]; ok {
+		return &digest
+	}
+	return nil
+}
+
+// seal encrypts localPath to a temp file and records its envelope under path.
+// The caller uploads the returned file and removes it.
+func (s *hubSealedRun) seal(path, localPath string) (string, artifactDigest, error) {
+	in, err := os.Open(localPath) // #nosec G304 -- local artifact resolved from the index store.
+	if err != nil {
+		return "", artifactDigest{}, err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.CreateTemp("", "gonimbus-sealed-*")
+	if err != nil {
+		return "", artifactDigest{}, fmt.Errorf("create temp file: %w", err)
+	}
+	sealedPath := out.Name()
+	if err := envelope.Encrypt(out, in, s.Recipients); err != nil {
+		_ = out.Close()
+		_ = os.Remove(sealedPath)
+		return "", artifactDigest{}, fmt.Errorf("encrypt: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(sealedPath)
+		return "", artifactDigest{}, err
+	}
+	sha, size, err := hashFile(sealedPath)
+	if err != nil {
+		_ = os.Remove(sealedPath)
+		return "", artifactDigest{}, err
+	}
+	digest := artifactDigest{SizeBytes: size, SHA256: sha}
+	s.Envelopes[path] = digest
+	return sealedPath, digest, nil
+}
+
+// uploadSealed encrypts localPath and uploads the envelope to key.
+func (s *hubSealedRun) uploadSealed(ctx context.Context, putter provider.ObjectPutter, key, path, localPath string) (artifactDigest, error) {
+	sealedPath, digest, err := s.seal(path, localPath)
+	if err != nil {
+		return artifactDigest{}, err
+	}
+	defer func() { _ = os.Remove(sealedPath) }()
+	return digest, uploadToOutputDest(ctx, putter, key, sealedPath)
+}
+
+// previousHubEnvelopes returns the sealed shared artifacts of the index set's
+// latest run, keyed by plaintext SHA-256, when that run was encrypted to the
+// same recipients. An encrypted export reuses them instead of sealing and
+// uploading the same segments again. Any failure just means no reuse.
+func previousHubEnvelopes(ctx context.Context, getter provider.ObjectGetter, hub *hubDestSpec, indexSetID string, info hubEncryptionInfo) map[string]artifactRef {
+	latest, ok, err := readHubLatestPointer(ctx, getter, hubArtifactKey(hub, "index-sets", indexSetID, "latest.json"), indexSetID)
+	if err != nil || !ok {
+		return nil
+	}
+	data, err := downloadBytesBounded(ctx, getter, hubArtifactKey(hub, "index-sets", indexSetID, "runs", latest.RunID, "complete.json"), maxHubCompleteMarkerBytes, "complete.json")
+	if err != nil {
+		return nil
+	}
+	var complete completeMarker
+	if json.Unmarshal(data, &complete) != nil || complete.Encryption == nil || !complete.Encryption.sameRecipients(info) {
+		return nil
+	}
+	if validateDurableCompleteMarker(indexSetID, latest.RunID, complete) != nil || validateHubEncryption(complete) != nil {
+		return nil
+	}
+	previous := map[string]artifactRef{}
+	add := func(ref artifactRef) {
+		if ref.ContentPath != "" && ref.Envelope != nil {
+			previous[ref.SHA256] = ref
+		}
+	}
+	for _, ref := range complete.Artifacts.Segments {
+		add(ref)
+	}
+	for _, ref := range []*artifactRef{complete.Artifacts.PrefixStats, complete.Artifacts.KeyIndex} {
+		if ref != nil {
+			add(*ref)
+		}
+	}
+	return previous
+}
+
+// hubPinnedArtifacts lists the artifacts a marker pins by digest.
+func hubPinnedArtifacts(complete completeMarker) []artifactRef {
+	var refs []artifactRef
+	for _, ref := range []*artifactRef{complete.Artifacts.IndexDB, complete.Artifacts.IdentityJSON, complete.Artifacts.Manifest, complete.Artifacts.PrefixStats, complete.Artifacts.KeyIndex} {
+		if ref != nil && ref.SHA256 != "" {
+			refs = append(refs, *ref)
+		}
+	}
+	return append(refs, complete.Artifacts.Segments...)
+}
+
+// validateHubEncryption checks that a marker's encryption block and its
+// artifacts agree: an encrypted run seals every pinned artifact, and an
+// unencrypted run seals none.
+func validateHubEncryption(complete completeMarker) error {
+	enc := complete.Encryption
+	if enc != nil {
+		switch {
+		case enc.Scheme != envelope.Scheme:
+			return fmt.Errorf("unsupported hub encryption scheme %q", enc.Scheme)
+		case len(enc.Recipients) == 0:
+			return fmt.Errorf("encrypted hub run lists no recipients")
+		}
+	}
+	for _, ref := range hubPinnedArtifacts(complete) {
+		name := ref.Path
+		if name == "" {
+			name = "artifact"
+		}
+		switch {
+		case enc != nil && ref.Envelope == nil:
+			return fmt.Errorf("encrypted hub run has unsealed %s", name)
+		case enc == nil && ref.Envelope != nil:
+			return fmt.Errorf("hub run seals %s but has no encryption block", name)
+		case ref.Envelope != nil && !validSHA256(ref.Envelope.SHA256):
+			return fmt.Errorf("sealed %s has an invalid envelope sha256", name)
+		}
+	}
+	return nil
+}
+
+// admitHubDecryption refuses an encrypted run before anything is downloaded
+// unless key is one of its recipients.
+func admitHubDecryption(complete completeMarker, key *ecdh.PrivateKey) error {
+	enc := complete.Encryption
+	switch {
+	case enc == nil:
+		return nil
+	case key == nil:
+		return fmt.Errorf("run is encrypted to key_id %s; pass --decrypt-key (or set %s)", strings.Join(enc.Recipients, ", "), hubDecryptKeyFileEnv)
+	case !slices.Contains(enc.Recipients, envelope.KeyID(key.PublicKey())):
+		return fmt.Errorf("run is encrypted to key_id %s, not to the --decrypt-key (key_id=%s)", strings.Join(enc.Recipients, ", "), envelope.KeyID(key.PublicKey()))
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "  encrypted: %s, decrypting with key_id=%s\n", enc.Cipher, envelope.KeyID(key.PublicKey()))
+	return nil
+}
+
+// verifyHubArtifactFile checks a local file against a pinned digest.
+func verifyHubArtifactFile(path string, ref artifactRef) error {
+	gotSHA, gotSize, err := hashFile(path)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if gotSHA != ref.SHA256 {
+		return fmt.Errorf("integrity check failed: expected sha256=%s, got %s", ref.SHA256, gotSHA)
+	}
+	if gotSize != ref.SizeBytes {
+		return fmt.Errorf("size mismatch: expected %d, got %d", ref.SizeBytes, gotSize)
+	}
+	return nil
+}
+
+// fetchHubArtifact downloads ref's object to destPath and verifies it. A
+// sealed artifact is checked against its envelope digest, decrypted with key,
+// and the plaintext checked against the artifact digest.
+func fetchHubArtifact(ctx context.Context, getter provider.ObjectGetter, objectKey string, ref artifactRef, destPath string, key *ecdh.PrivateKey) error {
+	if ref.Envelope == nil {
+		if err := downloadFile(ctx, getter, objectKey, destPath); err != nil {
+			return fmt.Errorf("download: %w", err)
+		}
+		return verifyHubArtifactFile(destPath, ref)
+	}
+	sealedPath := destPath + ".sealed"
+	defer func() { _ = os.Remove(sealedPath) }()
+	if err := downloadFile(ctx, getter, objectKey, sealedPath); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	if err := verifyHubArtifactFile(sealedPath, ref.stored()); err != nil {
+		return fmt.Errorf("envelope %w", err)
+	}
+	if err := decryptHubFile(sealedPath, destPath, key); err != nil {
+		_ = os.Remove(destPath)
+		return err
+	}
+	return verifyHubArtifactFile(destPath, ref)
+}
+
+func decryptHubFile(sealedPath, destPath string, key *ecdh.PrivateKey) error {
+	in, err := os.Open(sealedPath) // #nosec G304 -- temp file beside the destination.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return err
+	}
+	out, err := os.Create(destPath) // #nosec G304 -- path validated by safeLocalArtifactPath.
+	if err != nil {
+		return err
+	}
+	if err := envelope.Decrypt(out, in, key); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("decrypt: %w", err)
+	}
+	return out.Close()
+}
+
+// openHubBytes verifies and decrypts a sealed artifact held in memory.
+func openHubBytes(data []byte, ref artifactRef, key *ecdh.PrivateKey) ([]byte, error) {
+	stored := ref.stored()
+	if int64(len(data)) != stored.SizeBytes || sha256HexBytes(data) != stored.SHA256 {
+		return nil, fmt.Errorf("envelope integrity check failed: expected sha256=%s size=%d", stored.SHA256, stored.SizeBytes)
+	}
+	var plain bytes.Buffer
+	if err := envelope.Decrypt(&plain, bytes.NewReader(data), key); err != nil {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+	return plain.Bytes(), nil
+}
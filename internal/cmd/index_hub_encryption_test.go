@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/envelope"
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+)
+
+// writeHubEncryptionKeys writes a PEM X25519 private key and a one-key
+// recipients file for it, returning both paths and the key ID.
+func writeHubEncryptionKeys(t *testing.T) (keyPath, recipientsPath, keyID string) {
+	t.Helper()
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPath = filepath.Join(dir, "decrypt.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	pubDER, err := x509.MarshalPKIXPublicKey(key.PublicKey())
+	require.NoError(t, err)
+	recipientsPath = filepath.Join(dir, "recipients.pem")
+	require.NoError(t, os.WriteFile(recipientsPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644))
+	return keyPath, recipientsPath, envelope.KeyID(key.PublicKey())
+}
+
+func readCompleteMarkerForTest(t *testing.T, runDir string) completeMarker {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(runDir, "complete.json"))
+	require.NoError(t, err)
+	var complete completeMarker
+	require.NoError(t, json.Unmarshal(data, &complete))
+	return complete
+}
+
+// TestHubEncryption_DurableExportHydrate pins the encrypted hub contract:
+// segments and the manifest are stored sealed under their envelope digest,
+// hydrate refuses a missing or foreign key before downloading, and the right
+// key hydrates a run identical to the local snapshot.
+func TestHubEncryption_DurableExportHydrate(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	signKeyPath, trustedPath := writeHubSigningKeys(t)
+	keyPath, recipientsPath, keyID := writeHubEncryptionKeys(t)
+	otherKeyPath, _, _ := writeHubEncryptionKeys(t)
+	hubDir := t.TempDir()
+	hubURI := "file://" + hubDir + "/"
+
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "durable", "--sign-key", signKeyPath, "--encrypt-to", recipientsPath,
+	).Execute())
+
+	local, err := loadLocalDurableSnapshotForExport(indexSet.IndexSetID, run.RunID)
+	require.NoError(t, err)
+	setDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID)
+	runDir := filepath.Join(setDir, "runs", run.RunID)
+	complete := readCompleteMarkerForTest(t, runDir)
+	require.NotNil(t, complete.Encryption)
+	require.Equal(t, []string{keyID}, complete.Encryption.Recipients)
+
+	storedManifest, err := os.ReadFile(filepath.Join(runDir, "manifest.json"))
+	require.NoError(t, err)
+	require.False(t, json.Valid(storedManifest), "the hub manifest is sealed")
+	header, err := envelope.ReadHeader(bytes.NewReader(storedManifest))
+	require.NoError(t, err)
+	require.Equal(t, []string{keyID}, header.KeyIDs())
+
+	for _, ref := range complete.Artifacts.Segments {
+		require.NotNil(t, ref.Envelope, ref.Path)
+		require.Equal(t, hubSharedSegmentPath(ref.Envelope.SHA256), ref.ContentPath)
+		sealed, err := os.ReadFile(filepath.Join(setDir, ref.ContentPath))
+		require.NoError(t, err)
+		plain, err := os.ReadFile(filepath.Join(local.SegmentDir, filepath.Base(ref.Path)))
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(sealed, plain[:min(len(plain), 64)]))
+	}
+
+	// No key, or a key the run is not encrypted to: refused before download.
+	dest := t.TempDir()
+	err = newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--dest", dest,
+	).Execute()
+	require.ErrorContains(t, err, "--decrypt-key")
+	err = newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--dest", dest, "--decrypt-key", otherKeyPath,
+	).Execute()
+	require.ErrorContains(t, err, keyID)
+	require.NoFileExists(t, filepath.Join(dest, "manifest.json"))
+
+	// Signatures cover the plaintext, so a trust policy still applies.
+	require.NoError(t, newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--dest", dest,
+		"--decrypt-key", keyPath, "--trusted-keys", trustedPath,
+	).Execute())
+	hydrated, err := indexsubstrate.ReadInternalManifestFile(filepath.Join(dest, "manifest.json"))
+	require.NoError(t, err)
+	localManifest, err := indexsubstrate.ReadInternalManifestFile(local.ManifestPath)
+	require.NoError(t, err)
+	require.Equal(t, localManifest, hydrated)
+	rows, err := indexsubstrate.ReadManifestRows(filepath.Join(dest, "segments"), hydrated)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	matches, err := filepath.Glob(filepath.Join(dest, "segments", "*.sealed"))
+	require.NoError(t, err)
+	require.Empty(t, matches, "sealed downloads are removed after decryption")
+}
+
+// TestHubEncryption_ReexportReusesEnvelopesAndShowsWithoutKey pins that a
+// re-export to the same recipients reuses the published envelopes, so the
+// shared store does not grow, and that ls/show report encryption without a
+// key.
+func TestHubEncryption_ReexportReusesEnvelopesAndShowsWithoutKey(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	_, recipientsPath, keyID := writeHubEncryptionKeys(t)
+	hubDir := t.TempDir()
+	hubURI := "file://" + hubDir + "/"
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "durable", "--encrypt-to", recipientsPath,
+	).Execute())
+
+	sharedDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "segments", "sha256")
+	before, err := os.ReadDir(sharedDir)
+	require.NoError(t, err)
+
+	// Sealing again would draw a fresh data key and so a fresh digest; the
+	// previous run's envelopes are reused instead.
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "durable", "--encrypt-to", recipientsPath,
+	).Execute())
+	after, err := os.ReadDir(sharedDir)
+	require.NoError(t, err)
+	require.Len(t, after, len(before), "unchanged segments reuse their envelopes")
+
+	captured, err := captureHubStdout(t, func() error {
+		return newHubShowCmd(t, hubDir, indexSet.IndexSetID, true).Execute()
+	})
+	require.NoError(t, err)
+	var result struct {
+		Runs []hubRunInfo `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(captured, &result))
+	require.Len(t, result.Runs, 1)
+	for _, info := range result.Runs {
+		require.NotNil(t, info.Encryption, info.RunID)
+		require.Equal(t, []string{keyID}, info.Encryption.Recipients)
+		require.NotNil(t, info.Artifacts)
+		require.Positive(t, info.Artifacts.TotalBytes)
+	}
+
+	captured, err = captureHubStdout(t, func() error {
+		return newHubLsCmd(t, hubDir, true).Execute()
+	})
+	require.NoError(t, err)
+	require.Contains(t, string(captured), `"encrypted_runs": 1`)
+}
+
+// TestHubEncryption_SyncAndSQLiteRoundTrip pins that sync mirrors sealed
+// artifacts without a key and that a SQLite export round-trips through
+// encryption byte for byte.
+func TestHubEncryption_SyncAndSQLiteRoundTrip(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	keyPath, recipientsPath, _ := writeHubEncryptionKeys(t)
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	from, to := "file://"+srcDir+"/", "file://"+dstDir+"/"
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", from, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "sqlite", "--encrypt-to", recipientsPath,
+	).Execute())
+
+	runDir := filepath.Join(srcDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID)
+	complete := readCompleteMarkerForTest(t, runDir)
+	require.NotNil(t, complete.Encryption)
+	require.NotNil(t, complete.Artifacts.IndexDB)
+	require.NotNil(t, complete.Artifacts.IndexDB.Envelope)
+	stored, err := os.ReadFile(filepath.Join(runDir, "index.db"))
+	require.NoError(t, err)
+	_, err = envelope.ReadHeader(bytes.NewReader(stored))
+	require.NoError(t, err)
+
+	result, err := runHubSyncJSON(t, "--from", from, "--to", to)
+	require.NoError(t, err)
+	require.Equal(t, hubSyncRunCopied, result.IndexSets[0].Runs[0].Status, result.IndexSets[0].Runs[0].Error)
+
+	dest := t.TempDir()
+	require.NoError(t, newSigningHydrateTestCommand(
+		"--hub", to, "--index-set", indexSet.IndexSetID, "--dest", dest, "--decrypt-key", keyPath,
+	).Execute())
+	srcHash, _, err := hashFile(dbPath)
+	require.NoError(t, err)
+	hydratedHash, _, err := hashFile(filepath.Join(dest, "index.db"))
+	require.NoError(t, err)
+	require.Equal(t, srcHash, hydratedHash)
+	require.NoFileExists(t, filepath.Join(dest, "index.db.sealed"))
+}
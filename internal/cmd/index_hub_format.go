@@ -15,7 +15,10 @@ import (
 )
 
 const (
-	indexHubMarkerSchemaV1  = "gonimbus.index.hub_marker.v1"
+	indexHubMarkerSchemaV1 = "gonimbus.index.hub_marker.v1"
+	// indexHubMarkerSchemaV2 marks a durable run whose segments live in the
+	// index set's content-addressed segment store rather than under the run.
+	indexHubMarkerSchemaV2  = "gonimbus.index.hub_marker.v2"
 	indexHubFormatSQLiteV1  = "sqlite-v1"
 	indexHubFormatDurableV2 = "durable-v2"
 
@@ -66,8 +69,8 @@ func completeMarkerFormat(complete completeMarker) string {
 
 func validateDurableCompleteMarker(indexSetID, runID string, complete completeMarker) error {
 	switch {
-	case complete.MarkerSchemaVersion != indexHubMarkerSchemaV1:
-		return fmt.Errorf("durable complete marker schema version must be %s", indexHubMarkerSchemaV1)
+	case complete.MarkerSchemaVersion != indexHubMarkerSchemaV1 && complete.MarkerSchemaVersion != indexHubMarkerSchemaV2:
+		return fmt.Errorf("durable complete marker schema version must be %s or %s", indexHubMarkerSchemaV1, indexHubMarkerSchemaV2)
 	case complete.Format != indexHubFormatDurableV2:
 		return fmt.Errorf("durable complete marker format must be %s", indexHubFormatDurableV2)
 	case complete.FormatVersion != "2":
@@ -81,8 +84,46 @@ func validateDurableCompleteMarker(indexSetID, runID string, complete completeMa
 	case complete.Artifacts.Manifest == nil:
 		return fmt.Errorf("durable hub run is missing manifest artifact")
 	default:
-		return nil
+		return validateDurableContentPaths(complete)
+	}
+}
+
+// validateDurableContentPaths pins where a marker may send a reader: a v2
+// marker stores every segment and the prefix stats at the content path their
+// digest (for a sealed artifact, the envelope's) names, and a v1 marker stores
+// nothing outside the run.
+func validateDurableContentPaths(complete completeMarker) error {
+	shared := complete.MarkerSchemaVersion == indexHubMarkerSchemaV2
+	check := func(ref artifactRef) error {
+		ref = ref.stored()
+		switch {
+		case !shared && ref.ContentPath != "":
+			return fmt.Errorf("durable artifact %s has a content path in a %s marker", ref.Path, indexHubMarkerSchemaV1)
+		case shared && !validSHA256(ref.SHA256):
+			return fmt.Errorf("durable artifact %s has an invalid sha256", ref.Path)
+		case shared && ref.ContentPath != hubSharedSegmentPath(ref.SHA256):
+			return fmt.Errorf("durable artifact %s content path must be %s", ref.Path, hubSharedSegmentPath(ref.SHA256))
+		default:
+			return nil
+		}
+	}
+	if complete.Artifacts.Manifest.ContentPath != "" {
+		return fmt.Errorf("durable manifest is stored with its run, not by content path")
 	}
+	for _, ref := range complete.Artifacts.Segments {
+		if err := check(ref); err != nil {
+			return err
+		}
+	}
+	for _, ref := range []*artifactRef{complete.Artifacts.PrefixStats, complete.Artifacts.KeyIndex} {
+		if ref == nil {
+			continue
+		}
+		if err := check(*ref); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func loadLocalDurableSnapshotForExport(indexSetID, runID string) (durableExportSnapshot, error) {
@@ -126,6 +167,16 @@ func loadLocalDurableSnapshotForExport(indexSetID, runID string) (durableExportS
 	if err := verifyLocalDurableSegments(complete.SegmentDir, manifest); err != nil {
 		return durableExportSnapshot{}, err
 	}
+	if manifest.PrefixStats != nil {
+		if _, err := indexsubstrate.ReadPrefixStatsVerified(complete.SegmentDir, *manifest.PrefixStats); err != nil {
+			return durableExportSnapshot{}, fmt.Errorf("verify local prefix stats: %w", err)
+		}
+	}
+	if manifest.KeyIndex != nil {
+		if _, err := indexsubstrate.ReadKeyIndexVerified(complete.SegmentDir, *manifest.KeyIndex); err != nil {
+			return durableExportSnapshot{}, fmt.Errorf("verify local key index: %w", err)
+		}
+	}
 	return durableExportSnapshot{
 		Manifest:     manifest,
 		ManifestPath: complete.ManifestPath,
@@ -186,7 +237,11 @@ func verifyLocalDurableSegments(segmentDir string, manifest indexsubstrate.Inter
 	return nil
 }
 
-func buildDurableCompleteJSON(indexSet *indexstore.IndexSet, run *indexstore.IndexRun, snapshot durableExportSnapshot) ([]byte, error) {
+// buildDurableCompleteJSON renders the durable commit marker. With shared, the
+// segments and prefix stats are referenced in the index set's content-addressed
+// store and the marker is v2. sealed, when set, records the envelopes of an
+// encrypted export; shared objects are then named by envelope digest.
+func buildDurableCompleteJSON(indexSet *indexstore.IndexSet, run *indexstore.IndexRun, snapshot durableExportSnapshot, shared bool, sealed *hubSealedRun) ([]byte, error) {
 	type durableInfo struct {
 		ManifestType       string `json:"manifest_type"`
 		ManifestRender     string `json:"manifest_render"`
@@ -196,34 +251,53 @@ func buildDurableCompleteJSON(indexSet *indexstore.IndexSet, run *indexstore.Ind
 		Rows               int    `json:"rows"`
 	}
 	type artifacts struct {
-		Manifest artifactRef   `json:"manifest"`
-		Segments []artifactRef `json:"segments"`
+		Manifest    artifactRef   `json:"manifest"`
+		Segments    []artifactRef `json:"segments"`
+		PrefixStats *artifactRef  `json:"prefix_stats,omitempty"`
+		KeyIndex    *artifactRef  `json:"key_index,omitempty"`
 	}
 	type completeDoc struct {
-		Version             string      `json:"version"`
-		MarkerSchemaVersion string      `json:"marker_schema_version"`
-		Format              string      `json:"format"`
-		FormatVersion       string      `json:"format_version"`
-		IndexSetID          string      `json:"index_set_id"`
-		RunID               string      `json:"run_id"`
-		CompletedAt         string      `json:"completed_at"`
-		ExportedBy          string      `json:"exported_by"`
-		Artifacts           artifacts   `json:"artifacts"`
-		Durable             durableInfo `json:"durable"`
+		Version             string             `json:"version"`
+		MarkerSchemaVersion string             `json:"marker_schema_version"`
+		Format              string             `json:"format"`
+		FormatVersion       string             `json:"format_version"`
+		IndexSetID          string             `json:"index_set_id"`
+		RunID               string             `json:"run_id"`
+		CompletedAt         string             `json:"completed_at"`
+		ExportedBy          string             `json:"exported_by"`
+		Artifacts           artifacts          `json:"artifacts"`
+		Durable             durableInfo        `json:"durable"`
+		Encryption          *hubEncryptionInfo `json:"encryption,omitempty"`
+	}
+	contentPath := func(path, sha string) string {
+		if !shared {
+			return ""
+		}
+		if envelope := sealed.envelope(path); envelope != nil {
+			return hubSharedSegmentPath(envelope.SHA256)
+		}
+		return hubSharedSegmentPath(sha)
+	}
+	markerSchema := indexHubMarkerSchemaV1
+	if shared {
+		markerSchema = indexHubMarkerSchemaV2
 	}
 	segmentRefs := make([]artifactRef, 0, len(snapshot.Manifest.Segments))
 	for _, segment := range snapshot.Manifest.Segments {
+		path := "segments/" + segment.Path
 		segmentRefs = append(segmentRefs, artifactRef{
-			Path:      "segments/" + segment.Path,
-			Role:      "segment",
-			Required:  true,
-			SizeBytes: segment.SizeBytes,
-			SHA256:    segment.Digest.Hex,
+			Path:        path,
+			ContentPath: contentPath(path, segment.Digest.Hex),
+			Role:        "segment",
+			Required:    true,
+			SizeBytes:   segment.SizeBytes,
+			SHA256:      segment.Digest.Hex,
+			Envelope:    sealed.envelope(path),
 		})
 	}
 	doc := completeDoc{
 		Version:             "1.0",
-		MarkerSchemaVersion: indexHubMarkerSchemaV1,
+		MarkerSchemaVersion: markerSchema,
 		Format:              indexHubFormatDurableV2,
 		FormatVersion:       "2",
 		IndexSetID:          indexSet.IndexSetID,
@@ -237,6 +311,7 @@ func buildDurableCompleteJSON(indexSet *indexstore.IndexSet, run *indexstore.Ind
 				Required:  true,
 				SizeBytes: snapshot.ManifestSize,
 				SHA256:    snapshot.ManifestSHA,
+				Envelope:  sealed.envelope("manifest.json"),
 			},
 			Segments: segmentRefs,
 		},
@@ -248,6 +323,31 @@ func buildDurableCompleteJSON(indexSet *indexstore.IndexSet, run *indexstore.Ind
 			Segments:           len(snapshot.Manifest.Segments),
 			Rows:               snapshot.Manifest.Counts.Rows,
 		},
+		Encryption: sealed.encryption(),
+	}
+	if desc := snapshot.Manifest.PrefixStats; desc != nil {
+		path := "segments/" + desc.Path
+		doc.Artifacts.PrefixStats = &artifactRef{
+			Path:        path,
+			ContentPath: contentPath(path, desc.Digest.Hex),
+			Role:        "prefix_stats",
+			Required:    true,
+			SizeBytes:   desc.SizeBytes,
+			SHA256:      desc.Digest.Hex,
+			Envelope:    sealed.envelope(path),
+		}
+	}
+	if desc := snapshot.Manifest.KeyIndex; desc != nil {
+		path := "segments/" + desc.Path
+		doc.Artifacts.KeyIndex = &artifactRef{
+			Path:        path,
+			ContentPath: contentPath(path, desc.Digest.Hex),
+			Role:        "key_index",
+			Required:    true,
+			SizeBytes:   desc.SizeBytes,
+			SHA256:      desc.Digest.Hex,
+			Envelope:    sealed.envelope(path),
+		}
 	}
 	return json.MarshalIndent(doc, "", "  ")
 }
@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ecdh"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// Durable segments and prefix stats are immutable and named by their SHA-256,
+// so v2 exports store them once per index set:
+//
+//	<hub>/index-sets/<index_set_id>/segments/sha256/<sha256>
+//
+// A run's complete.json references them by content path. Export skips objects
+// already present, hydrate reuses local files with the same digest, and hub gc
+// deletes a shared object only when no retained run references it.
+
+const (
+	hubSharedSegmentsPrefix = "segments/sha256/"
+	// defaultHubSegmentGrace protects unreferenced shared segments younger than
+	// this from gc: an export uploads segments before its complete.json, so a
+	// fresh orphan may belong to a run still being exported.
+	defaultHubSegmentGrace = 24 * time.Hour
+)
+
+// hubSharedSegmentPath is a durable artifact's content path relative to its
+// index set root.
+func hubSharedSegmentPath(sha string) string {
+	return hubSharedSegmentsPrefix + sha
+}
+
+// hubTransferStats counts what an incremental export or hydrate moved and
+// what it found already in place.
+type hubTransferStats struct {
+	Transferred      int
+	TransferredBytes int64
+	Reused           int
+	ReusedBytes      int64
+}
+
+func (s hubTransferStats) String() string {
+	return fmt.Sprintf("%d transferred (%d bytes), %d reused (%d bytes)", s.Transferred, s.TransferredBytes, s.Reused, s.ReusedBytes)
+}
+
+// uploadSharedSegment uploads a content-addressed durable artifact unless the
+// hub already holds an object of the expected size at its content key. The key
+// names the digest and uploads are whole-object writes, so a present object of
+// the right size is the artifact. An object of the wrong size is replaced.
+func uploadSharedSegment(ctx context.Context, statter provider.Provider, putter provider.ObjectPutter, key, localPath string, size int64, stats *hubTransferStats) error {
+	if statter != nil {
+		meta, err := statter.Head(ctx, key)
+		switch {
+		case err == nil && meta.Size == size:
+			stats.Reused++
+			stats.ReusedBytes += size
+			return nil
+		case err != nil && !provider.IsNotFound(err):
+			return fmt.Errorf("check hub segment: %w", err)
+		}
+	}
+	if err := uploadToOutputDest(ctx, putter, key, localPath); err != nil {
+		return err
+	}
+	stats.Transferred++
+	stats.TransferredBytes += size
+	return nil
+}
+
+// hydrateReuseIndex maps SHA-256 digests to local files that already hold
+// those bytes: the segments of a previous hydrate into the same destination,
+// and the index set's local durable segment cache.
+type hydrateReuseIndex map[string]string
+
+// newHydrateReuseIndex collects reuse candidates from destDir's current
+// manifest and every local durable run of indexSetID. Candidates are only
+// hints: each is re-verified before it is used.
+func newHydrateReuseIndex(destDir, indexSetID string) hydrateReuseIndex {
+	index := hydrateReuseIndex{}
+	index.addManifest(filepath.Join(destDir, "manifest.json"), filepath.Join(destDir, "segments"))
+	segmentRoot, err := indexSubstrateSegmentCacheDir(indexSetID)
+	if err != nil {
+		return index
+	}
+	runs, err := os.ReadDir(filepath.Join(segmentRoot, "runs"))
+	if err != nil {
+		return index
+	}
+	for _, run := range runs {
+		data, err := os.ReadFile(filepath.Join(segmentRoot, "runs", run.Name(), "complete.json")) // #nosec G304 -- local durable cache.
+		if err != nil {
+			continue
+		}
+		var complete durableLocalCompleteDoc
+		if json.Unmarshal(data, &complete) == nil && complete.ManifestPath != "" && complete.SegmentDir != "" {
+			index.addManifest(complete.ManifestPath, complete.SegmentDir)
+		}
+	}
+	return index
+}
+
+func (idx hydrateReuseIndex) addManifest(manifestPath, segmentDir string) {
+	manifest, err := indexsubstrate.ReadInternalManifestFile(manifestPath)
+	if err != nil {
+		return
+	}
+	add := func(rel, sha string) {
+		if path, err := safeLocalArtifactPath(segmentDir, rel); err == nil && sha != "" {
+			if _, ok := idx[sha]; !ok {
+				idx[sha] = path
+			}
+		}
+	}
+	for _, segment := range manifest.Segments {
+		add(segment.Path, segment.Digest.Hex)
+	}
+	if desc := manifest.PrefixStats; desc != nil {
+		add(desc.Path, desc.Digest.Hex)
+	}
+	if desc := manifest.KeyIndex; desc != nil {
+		add(desc.Path, desc.Digest.Hex)
+	}
+}
+
+// place puts a verified copy of ref's bytes at destPath from a local file with
+// the same digest. It reports false when no candidate verifies, leaving the
+// caller to download.
+func (idx hydrateReuseIndex) place(ref artifactRef, destPath string) bool {
+	if sha, size, err := hashFile(destPath); err == nil && sha == ref.SHA256 && size == ref.SizeBytes {
+		return true
+	}
+	source, ok := idx[ref.SHA256]
+	if !ok || source == destPath {
+		return false
+	}
+	if sha, size, err := hashFile(source); err != nil || sha != ref.SHA256 || size != ref.SizeBytes {
+		return false
+	}
+	if err := copyLocalArtifact(source, destPath); err != nil {
+		_ = os.Remove(destPath)
+		return false
+	}
+	return true
+}
+
+// copyLocalArtifact hard-links source to dest when both share a filesystem and
+// copies otherwise. Durable artifacts are immutable, so a link is safe.
+func copyLocalArtifact(source, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	_ = os.Remove(dest)
+	if err := os.Link(source, dest); err == nil {
+		return nil
+	}
+	in, err := os.Open(source) // #nosec G304 -- reuse candidate from a local manifest.
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644) // #nosec G304 -- path validated by safeLocalArtifactPath.
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// hydrateDurableArtifact places one verified durable artifact at destPath,
+// reusing a local copy when one matches and downloading (and, for a sealed
+// artifact, decrypting) otherwise.
+func hydrateDurableArtifact(ctx context.Context, getter provider.ObjectGetter, key string, ref artifactRef, destPath string, reuse hydrateReuseIndex, decryptKey *ecdh.PrivateKey, stats *hubTransferStats) error {
+	if reuse.place(ref, destPath) {
+		stats.Reused++
+		stats.ReusedBytes += ref.SizeBytes
+		return nil
+	}
+	// destPath may be a hard link into the local segment cache from an earlier
+	// reuse; unlink it so the download cannot rewrite the cached copy.
+	_ = os.Remove(destPath)
+	if err := fetchHubArtifact(ctx, getter, key, ref, destPath, decryptKey); err != nil {
+		return err
+	}
+	stats.Transferred++
+	stats.TransferredBytes += ref.stored().SizeBytes
+	return nil
+}
+
+// hubSharedSegmentArtifacts returns the artifacts a committed run's marker
+// stores in the index set's shared segment store.
+func hubSharedSegmentArtifacts(complete completeMarker) []artifactRef {
+	var refs []artifactRef
+	for _, ref := range complete.Artifacts.Segments {
+		if ref.ContentPath != "" {
+			refs = append(refs, ref)
+		}
+	}
+	for _, ref := range []*artifactRef{complete.Artifacts.PrefixStats, complete.Artifacts.KeyIndex} {
+		if ref != nil && ref.ContentPath != "" {
+			refs = append(refs, *ref)
+		}
+	}
+	return refs
+}
+
+// hubSharedSegmentRefs returns the content paths a committed run's marker
+// references.
+func hubSharedSegmentRefs(complete completeMarker) []string {
+	var refs []string
+	for _, ref := range hubSharedSegmentArtifacts(complete) {
+		refs = append(refs, ref.ContentPath)
+	}
+	return refs
+}
+
+// A run writing shared segments holds no references until its complete.json
+// lands, and a segment it reuses keeps its original LastModified, so neither
+// the reference scan nor --segment-grace protects it from a concurrent gc.
+// The writer therefore drops a pending marker in the run directory before it
+// touches the shared store:
+//
+//	<hub>/index-sets/<index_set_id>/runs/<run_id>/pending.json
+//
+// gc sweeps no shared segments of a set with a pending marker younger than
+// --segment-grace, and the writer re-checks every referenced segment after
+// committing. The marker is removed once the run is committed; one left by a
+// crashed writer stops protecting the set when it ages past the grace.
+const hubPendingMarkerType = "gonimbus.index.hub.pending.v1"
+
+type hubPendingMarker struct {
+	Type       string `json:"type"`
+	IndexSetID string `json:"index_set_id"`
+	RunID      string `json:"run_id"`
+	StartedAt  string `json:"started_at"`
+}
+
+func hubPendingMarkerKey(hub *hubDestSpec, indexSetID, runID string) string {
+	return hubArtifactKey(hub, "index-sets", indexSetID, "runs", runID, "pending.json")
+}
+
+// writeHubPendingMarker records that runID is about to write shared segments.
+func writeHubPendingMarker(ctx context.Context, putter provider.ObjectPutter, hub *hubDestSpec, indexSetID, runID string, now time.Time) error {
+	data, err := json.MarshalIndent(hubPendingMarker{
+		Type:       hubPendingMarkerType,
+		IndexSetID: indexSetID,
+		RunID:      runID,
+		StartedAt:  now.UTC().Format(time.RFC3339),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := uploadBytes(ctx, putter, hubPendingMarkerKey(hub, indexSetID, runID), append(data, '\n')); err != nil {
+		return fmt.Errorf("write pending.json: %w", err)
+	}
+	return nil
+}
+
+// clearHubPendingMarker removes runID's pending marker once the run is
+// committed. Removal is best-effort: a leftover marker only delays gc until it
+// ages past the grace.
+func clearHubPendingMarker(ctx context.Context, deleter provider.ObjectDeleter, hub *hubDestSpec, indexSetID, runID string) {
+	if deleter == nil {
+		return
+	}
+	if err := deleter.DeleteObject(ctx, hubPendingMarkerKey(hub, indexSetID, runID)); err != nil && !provider.IsNotFound(err) {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: could not remove pending.json for %s/%s: %v\n", indexSetID, runID, err)
+	}
+}
+
+// hubRunInFlight reports whether runID, which has no complete.json, holds a
+// pending marker younger than grace. A marker that cannot be read or parsed is
+// treated as in flight so gc errs toward keeping segments.
+func hubRunInFlight(ctx context.Context, getter provider.ObjectGetter, hub *hubDestSpec, indexSetID, runID string, grace time.Duration, now time.Time) (bool, error) {
+	data, err := downloadBytesBounded(ctx, getter, hubPendingMarkerKey(hub, indexSetID, runID), maxHubMarkerBytes, "pending.json")
+	if err != nil {
+		if provider.IsNotFound(err) {
+			return false, nil
+		}
+		return true, err
+	}
+	var marker hubPendingMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		return true, fmt.Errorf("parse pending.json: %w", err)
+	}
+	startedAt, err := time.Parse(time.RFC3339, strings.TrimSpace(marker.StartedAt))
+	if err != nil {
+		return true, fmt.Errorf("parse pending.json: invalid started_at: %w", err)
+	}
+	return now.Sub(startedAt) < grace, nil
+}
+
+// verifyHubSharedSegments re-checks, after a run's complete.json is written,
+// that every shared segment it references is still in the hub. A segment that
+// was reused rather than uploaded may have been swept by a gc that ran before
+// the pending marker existed or after it aged out.
+func verifyHubSharedSegments(ctx context.Context, statter provider.Provider, hub *hubDestSpec, indexSetID string, completeJSON []byte) error {
+	if statter == nil {
+		return nil
+	}
+	var complete completeMarker
+	if err := json.Unmarshal(completeJSON, &complete); err != nil {
+		return fmt.Errorf("parse complete.json: %w", err)
+	}
+	for _, ref := range hubSharedSegmentArtifacts(complete) {
+		key := hubArtifactKey(hub, "index-sets", indexSetID, ref.ContentPath)
+		meta, err := statter.Head(ctx, key)
+		switch {
+		case provider.IsNotFound(err):
+			return fmt.Errorf("shared segment %s vanished while the run was written (concurrent hub gc?); re-run to restore it", ref.ContentPath)
+		case err != nil:
+			return fmt.Errorf("check shared segment %s: %w", ref.ContentPath, err)
+		case meta.Size != ref.stored().SizeBytes:
+			return fmt.Errorf("shared segment %s is %d bytes, expected %d; re-run to restore it", ref.ContentPath, meta.Size, ref.stored().SizeBytes)
+		}
+	}
+	return nil
+}
+
+// gcSharedSegment is a shared segment hub gc removes (or would remove).
+type gcSharedSegment struct {
+	IndexSetID string `json:"index_set_id"`
+	Key        string `json:"key"`
+	SizeBytes  int64  `json:"size_bytes"`
+	Error      string `json:"error,omitempty"`
+}
+
+// unreferencedSharedSegments lists the shared segments of one index set that
+// no retained run references and that are older than grace. referenced holds
+// content paths relative to the index set root.
+func unreferencedSharedSegments(ctx context.Context, lister provider.Provider, hub *hubDestSpec, indexSetID string, referenced map[string]bool, grace time.Duration, now time.Time) ([]gcSharedSegment, error) {
+	setRoot := hubArtifactKey(hub, "index-sets", indexSetID) + "/"
+	prefix := setRoot + hubSharedSegmentsPrefix
+	var orphans []gcSharedSegment
+	token := ""
+	for {
+		result, err := lister.List(ctx, provider.ListOptions{Prefix: prefix, ContinuationToken: token, MaxKeys: 1000})
+		if err != nil {
+			return nil, fmt.Errorf("list shared segments: %w", err)
+		}
+		for _, obj := range result.Objects {
+			if referenced[strings.TrimPrefix(obj.Key, setRoot)] {
+				continue
+			}
+			if !obj.LastModified.IsZero() && now.Sub(obj.LastModified) < grace {
+				continue
+			}
+			orphans = append(orphans, gcSharedSegment{IndexSetID: indexSetID, Key: obj.Key, SizeBytes: obj.Size})
+		}
+		if !result.IsTruncated || result.ContinuationToken == "" {
+			break
+		}
+		token = result.ContinuationToken
+	}
+	return orphans, nil
+}
+
+// gcSegmentSet is what hub gc knows about one index set's shared segment
+// references: the content paths each run's complete.json names. Blocked sets
+// have a run whose marker could not be read, so their references are unknown
+// and nothing is swept. InFlight names runs still writing shared segments
+// (a fresh pending marker), whose references are not yet recorded.
+type gcSegmentSet struct {
+	IndexSetID string
+	RunRefs    map[string][]string
+	Blocked    bool
+	InFlight   []string
+}
+
+// planGCSharedSegments returns the shared segments that no retained run
+// references once removed runs are gone. A removal that failed (Error set)
+// keeps its run, and its references, retained.
+func planGCSharedSegments(ctx context.Context, lister provider.Provider, hub *hubDestSpec, sets []gcSegmentSet, removed []gcRunCandidate, grace time.Duration, now time.Time) []gcSharedSegment {
+	gone := make(map[string]bool, len(removed))
+	for _, r := range removed {
+		if r.Error == "" {
+			gone[r.IndexSetID+"/"+r.RunID] = true
+		}
+	}
+	var orphans []gcSharedSegment
+	for _, set := range sets {
+		if set.Blocked {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: not sweeping shared segments for %s: a run's complete.json is unreadable\n", set.IndexSetID)
+			continue
+		}
+		if len(set.InFlight) > 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: not sweeping shared segments for %s: run %s is still being written\n", set.IndexSetID, set.InFlight[0])
+			continue
+		}
+		referenced := map[string]bool{}
+		for runID, refs := range set.RunRefs {
+			if gone[set.IndexSetID+"/"+runID] {
+				continue
+			}
+			for _, ref := range refs {
+				referenced[ref] = true
+			}
+		}
+		setOrphans, err := unreferencedSharedSegments(ctx, lister, hub, set.IndexSetID, referenced, grace, now)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: not sweeping shared segments for %s: %v\n", set.IndexSetID, err)
+			continue
+		}
+		orphans = append(orphans, setOrphans...)
+	}
+	return orphans
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	providerfile "github.com/3leaps/gonimbus/pkg/provider/file"
+)
+
+func TestUploadSharedSegmentSkipsPresentObject(t *testing.T) {
+	hubDir := t.TempDir()
+	prov, err := providerfile.New(providerfile.Config{BaseDir: hubDir})
+	require.NoError(t, err)
+	local := filepath.Join(t.TempDir(), "seg.parquet")
+	require.NoError(t, os.WriteFile(local, []byte("segment-bytes"), 0o644))
+	sha, size, err := hashFile(local)
+	require.NoError(t, err)
+	key := "index-sets/" + testFullIndexSetID + "/" + hubSharedSegmentPath(sha)
+
+	var stats hubTransferStats
+	require.NoError(t, uploadSharedSegment(context.Background(), prov, prov, key, local, size, &stats))
+	require.NoError(t, uploadSharedSegment(context.Background(), prov, prov, key, local, size, &stats))
+	require.Equal(t, hubTransferStats{Transferred: 1, TransferredBytes: size, Reused: 1, ReusedBytes: size}, stats)
+
+	// A truncated object at the content key is replaced, not trusted.
+	require.NoError(t, os.WriteFile(filepath.Join(hubDir, filepath.FromSlash(key)), []byte("seg"), 0o644))
+	require.NoError(t, uploadSharedSegment(context.Background(), prov, prov, key, local, size, &stats))
+	require.Equal(t, 2, stats.Transferred)
+	got, _, err := hashFile(filepath.Join(hubDir, filepath.FromSlash(key)))
+	require.NoError(t, err)
+	require.Equal(t, sha, got)
+}
+
+// TestHubSharedSegments_ReexportAndHydrateReuse pins the incremental contract:
+// a second export of the same run uploads no segment bytes, and hydrate on a
+// host that already holds the segments locally does not need the hub copies.
+func TestHubSharedSegments_ReexportAndHydrateReuse(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	hubDir := t.TempDir()
+	hubURI := "file://" + hubDir + "/"
+	export := func() {
+		require.NoError(t, newSigningExportTestCommand(
+			"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+			"--db", dbPath, "--format", "durable",
+		).Execute())
+	}
+	export()
+	require.NoFileExists(t, filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID, "pending.json"),
+		"a committed export removes its pending marker")
+	sharedDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "segments", "sha256")
+	entries, err := os.ReadDir(sharedDir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	old := time.Now().Add(-time.Hour)
+	for _, entry := range entries {
+		require.NoError(t, os.Chtimes(filepath.Join(sharedDir, entry.Name()), old, old))
+	}
+	export()
+	for _, entry := range entries {
+		info, err := os.Stat(filepath.Join(sharedDir, entry.Name()))
+		require.NoError(t, err)
+		require.True(t, info.ModTime().Equal(old), "re-export rewrote %s", entry.Name())
+	}
+
+	// With the shared objects gone, hydrate still succeeds from the local
+	// segment cache, and the result verifies.
+	require.NoError(t, os.RemoveAll(sharedDir))
+	dest := t.TempDir()
+	require.NoError(t, newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID, "--dest", dest,
+	).Execute())
+	local, err := loadLocalDurableSnapshotForExport(indexSet.IndexSetID, run.RunID)
+	require.NoError(t, err)
+	for _, segment := range local.Manifest.Segments {
+		got, _, err := hashFile(filepath.Join(dest, "segments", segment.Path))
+		require.NoError(t, err)
+		require.Equal(t, segment.Digest.Hex, got)
+	}
+
+	// Elsewhere (no local cache) the missing objects are an error.
+	t.Setenv("GONIMBUS_DATA_DIR", filepath.Join(t.TempDir(), "other-host"))
+	require.Error(t, newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID, "--dest", t.TempDir(),
+	).Execute())
+}
+
+// writeSharedSegmentHubRun writes a committed v2 durable run whose marker
+// references the given shared objects, creating them under the set root.
+func writeSharedSegmentHubRun(t *testing.T, hubDir, runID, completedAt string, contents ...string) []string {
+	t.Helper()
+	setDir := filepath.Join(hubDir, "index-sets", testFullIndexSetID)
+	var refs []map[string]any
+	var paths []string
+	for i, content := range contents {
+		sha := sha256HexBytes([]byte(content))
+		contentPath := hubSharedSegmentPath(sha)
+		require.NoError(t, os.MkdirAll(filepath.Join(setDir, "segments", "sha256"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(setDir, filepath.FromSlash(contentPath)), []byte(content), 0o644))
+		refs = append(refs, map[string]any{
+			"path": "segments/seg_" + string(rune('a'+i)) + ".parquet", "content_path": contentPath,
+			"role": "segment", "required": true, "size_bytes": len(content), "sha256": sha,
+		})
+		paths = append(paths, contentPath)
+	}
+	complete := map[string]any{
+		"version": "1.0", "marker_schema_version": indexHubMarkerSchemaV2, "format": indexHubFormatDurableV2,
+		"index_set_id": testFullIndexSetID, "run_id": runID, "completed_at": completedAt,
+		"artifacts": map[string]any{"segments": refs},
+	}
+	data, err := json.Marshal(complete)
+	require.NoError(t, err)
+	runDir := filepath.Join(setDir, "runs", runID)
+	require.NoError(t, os.MkdirAll(runDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "complete.json"), data, 0o644))
+	return paths
+}
+
+func newHubGCSegmentCmd(t *testing.T, hubDir string, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := newHubGCCmd(t, hubDir, "", 0, "", false, false)
+	cmd.Flags().Duration("segment-grace", defaultHubSegmentGrace, "")
+	cmd.SetArgs(append([]string{"--hub", "file://" + hubDir + "/"}, args...))
+	return cmd
+}
+
+// TestRunIndexHubGC_SharedSegmentsRefcounted pins that removing a run deletes
+// only the shared segments no retained run references, and only once they are
+// older than the grace window.
+func TestRunIndexHubGC_SharedSegmentsRefcounted(t *testing.T) {
+	hubDir := t.TempDir()
+	oldPaths := writeSharedSegmentHubRun(t, hubDir, "run_1000000000000000000", "2026-01-01T00:00:00Z", "only-old", "shared")
+	newPaths := writeSharedSegmentHubRun(t, hubDir, "run_2000000000000000000", "2026-02-01T00:00:00Z", "shared", "only-new")
+	setDir := filepath.Join(hubDir, "index-sets", testFullIndexSetID)
+	require.NoError(t, os.WriteFile(filepath.Join(setDir, "latest.json"),
+		[]byte(`{"index_set_id":"`+testFullIndexSetID+`","run_id":"run_2000000000000000000"}`), 0o644))
+	stray := hubSharedSegmentPath(sha256HexBytes([]byte("stray")))
+	require.NoError(t, os.WriteFile(filepath.Join(setDir, filepath.FromSlash(stray)), []byte("stray"), 0o644))
+
+	// Fresh objects are inside the default grace window: the run goes, its
+	// segments stay.
+	require.NoError(t, newHubGCSegmentCmd(t, hubDir, "--keep", "1").Execute())
+	require.NoFileExists(t, filepath.Join(setDir, "runs", "run_1000000000000000000", "complete.json"))
+	require.FileExists(t, filepath.Join(setDir, filepath.FromSlash(oldPaths[0])))
+	require.FileExists(t, filepath.Join(setDir, filepath.FromSlash(stray)))
+
+	captured, err := captureHubStdout(t, func() error {
+		return newHubGCSegmentCmd(t, hubDir, "--keep", "1", "--segment-grace", "0s", "--dry-run", "--json").Execute()
+	})
+	require.NoError(t, err)
+	var result gcResult
+	require.NoError(t, json.Unmarshal(captured, &result))
+	require.Len(t, result.SharedSegments, 2)
+	require.FileExists(t, filepath.Join(setDir, filepath.FromSlash(stray)), "dry run deletes nothing")
+
+	require.NoError(t, newHubGCSegmentCmd(t, hubDir, "--keep", "1", "--segment-grace", "0s").Execute())
+	require.NoFileExists(t, filepath.Join(setDir, filepath.FromSlash(oldPaths[0])))
+	require.NoFileExists(t, filepath.Join(setDir, filepath.FromSlash(stray)))
+	for _, p := range newPaths {
+		require.FileExists(t, filepath.Join(setDir, filepath.FromSlash(p)), "segment %s is referenced by the retained run", p)
+	}
+}
+
+// TestRunIndexHubGC_SharedSegmentsSkipUnreadableSet pins that a set whose
+// references cannot all be read is not swept.
+func TestRunIndexHubGC_SharedSegmentsSkipUnreadableSet(t *testing.T) {
+	hubDir := t.TempDir()
+	paths := writeSharedSegmentHubRun(t, hubDir, "run_1000000000000000000", "2026-01-01T00:00:00Z", "kept")
+	setDir := filepath.Join(hubDir, "index-sets", testFullIndexSetID)
+	brokenDir := filepath.Join(setDir, "runs", "run_2000000000000000000")
+	require.NoError(t, os.MkdirAll(brokenDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(brokenDir, "complete.json"), []byte("{not json"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(setDir, "runs", "run_1000000000000000000", "complete.json")))
+
+	require.NoError(t, newHubGCSegmentCmd(t, hubDir, "--keep", "5", "--segment-grace", "0s").Execute())
+	require.FileExists(t, filepath.Join(setDir, filepath.FromSlash(paths[0])))
+}
+
+// TestRunIndexHubGC_SharedSegmentsSkipInFlightRun pins that a run still being
+// written (a fresh pending marker, no complete.json) keeps its set's shared
+// segments, which it may be reusing unreferenced, and is not pruned; a marker
+// older than the grace protects nothing.
+func TestRunIndexHubGC_SharedSegmentsSkipInFlightRun(t *testing.T) {
+	hubDir := t.TempDir()
+	writeSharedSegmentHubRun(t, hubDir, "run_1000000000000000000", "2026-01-01T00:00:00Z", "kept")
+	setDir := filepath.Join(hubDir, "index-sets", testFullIndexSetID)
+	reused := hubSharedSegmentPath(sha256HexBytes([]byte("reused")))
+	require.NoError(t, os.WriteFile(filepath.Join(setDir, filepath.FromSlash(reused)), []byte("reused"), 0o644))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(setDir, filepath.FromSlash(reused)), old, old))
+
+	prov, err := providerfile.New(providerfile.Config{BaseDir: hubDir})
+	require.NoError(t, err)
+	hub := &hubDestSpec{}
+	inFlightRun := "run_2000000000000000000"
+	pendingPath := filepath.Join(setDir, "runs", inFlightRun, "pending.json")
+	require.NoError(t, writeHubPendingMarker(context.Background(), prov, hub, testFullIndexSetID, inFlightRun, time.Now()))
+
+	require.NoError(t, newHubGCSegmentCmd(t, hubDir, "--keep", "5", "--segment-grace", "1h").Execute())
+	require.FileExists(t, filepath.Join(setDir, filepath.FromSlash(reused)))
+	require.FileExists(t, pendingPath, "an in-flight run is not pruned")
+
+	require.NoError(t, writeHubPendingMarker(context.Background(), prov, hub, testFullIndexSetID, inFlightRun, old))
+	require.NoError(t, newHubGCSegmentCmd(t, hubDir, "--keep", "5", "--segment-grace", "1h").Execute())
+	require.NoFileExists(t, filepath.Join(setDir, filepath.FromSlash(reused)))
+	require.NoFileExists(t, pendingPath, "a stale pending run is an abandoned partial run")
+}
+
+func TestVerifyHubSharedSegmentsReportsVanishedSegment(t *testing.T) {
+	hubDir := t.TempDir()
+	paths := writeSharedSegmentHubRun(t, hubDir, "run_1000000000000000000", "2026-01-01T00:00:00Z", "one", "two")
+	setDir := filepath.Join(hubDir, "index-sets", testFullIndexSetID)
+	completeJSON, err := os.ReadFile(filepath.Join(setDir, "runs", "run_1000000000000000000", "complete.json"))
+	require.NoError(t, err)
+	prov, err := providerfile.New(providerfile.Config{BaseDir: hubDir})
+	require.NoError(t, err)
+
+	require.NoError(t, verifyHubSharedSegments(context.Background(), prov, &hubDestSpec{}, testFullIndexSetID, completeJSON))
+	require.NoError(t, os.Remove(filepath.Join(setDir, filepath.FromSlash(paths[1]))))
+	err = verifyHubSharedSegments(context.Background(), prov, &hubDestSpec{}, testFullIndexSetID, completeJSON)
+	require.ErrorContains(t, err, paths[1])
+}
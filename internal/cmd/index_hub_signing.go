@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/internal/signing"
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+const (
+	// hubSignKeyFileEnv names a PEM key file used when --sign-key is unset.
+	hubSignKeyFileEnv = "GONIMBUS_SIGN_KEY_FILE"
+	// hubSignKeyEnv holds the PEM key itself, for CI secrets that are not files.
+	hubSignKeyEnv = "GONIMBUS_SIGN_KEY"
+	// hubTrustedKeysEnv names the trust policy used when --trusted-keys is unset.
+	hubTrustedKeysEnv = "GONIMBUS_TRUSTED_KEYS"
+)
+
+// Signature statuses reported by hydrate and doctor.
+const (
+	hubSignatureUnsigned  = "unsigned"
+	hubSignatureValid     = "valid"
+	hubSignatureTrusted   = "trusted"
+	hubSignatureUntrusted = "untrusted"
+	hubSignatureInvalid   = "invalid"
+)
+
+func addHubSignKeyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("sign-key", "", "PEM PKCS#8 ed25519 private key that signs published artifacts and latest.json (default: $"+hubSignKeyFileEnv+" or $"+hubSignKeyEnv+")")
+}
+
+func addHubTrustedKeysFlag(cmd *cobra.Command) {
+	cmd.Flags().String("trusted-keys", "", "PEM file of trusted ed25519 public keys; when set, artifacts must carry a trusted signature (default: $"+hubTrustedKeysEnv+")")
+}
+
+// hubSignKeyFromCommand resolves the optional signing key: --sign-key, then
+// the key file named by GONIMBUS_SIGN_KEY_FILE, then PEM text in
+// GONIMBUS_SIGN_KEY. It returns nil when none is configured.
+func hubSignKeyFromCommand(cmd *cobra.Command) (ed25519.PrivateKey, error) {
+	path, _ := cmd.Flags().GetString("sign-key")
+	if strings.TrimSpace(path) == "" {
+		path = os.Getenv(hubSignKeyFileEnv)
+	}
+	if strings.TrimSpace(path) != "" {
+		key, err := signing.LoadPrivateKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --sign-key: %w", err)
+		}
+		return key, nil
+	}
+	if raw := os.Getenv(hubSignKeyEnv); strings.TrimSpace(raw) != "" {
+		key, err := signing.ParsePrivateKey([]byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", hubSignKeyEnv, err)
+		}
+		return key, nil
+	}
+	return nil, nil
+}
+
+// hubSignKeyID returns the short key ID printed beside signed uploads.
+func hubSignKeyID(key ed25519.PrivateKey) string {
+	pub, _ := key.Public().(ed25519.PublicKey)
+	return signing.KeyID(pub)
+}
+
+// hubTrustedKeysFromCommand resolves the optional trust policy from
+// --trusted-keys or GONIMBUS_TRUSTED_KEYS. nil means no policy: signatures are
+// still checked when present, but none is required.
+func hubTrustedKeysFromCommand(cmd *cobra.Command) (signing.TrustedKeys, error) {
+	path, _ := cmd.Flags().GetString("trusted-keys")
+	if strings.TrimSpace(path) == "" {
+		path = os.Getenv(hubTrustedKeysEnv)
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	keys, err := signing.LoadTrustedKeys(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --trusted-keys: %w", err)
+	}
+	return keys, nil
+}
+
+// hubArtifactClaims binds a run artifact statement to its index set and run.
+func hubArtifactClaims(indexSetID, runID string) map[string]string {
+	return map[string]string{"index_set_id": indexSetID, "run_id": runID}
+}
+
+// buildHubSignatureBundle signs the run artifacts named by subjects.
+func buildHubSignatureBundle(key ed25519.PrivateKey, indexSetID, runID string, subjects ...signing.Subject) ([]byte, error) {
+	bundle, err := signing.SignBundle(key, signing.Statement{
+		Subjects: subjects,
+		Claims:   hubArtifactClaims(indexSetID, runID),
+		SignedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(bundle, "", "  ")
+}
+
+// writeLocalSignatureBundle records a published bundle beside the local
+// artifact it covers so doctor can report it. The write is atomic.
+func writeLocalSignatureBundle(artifactPath string, data []byte) error {
+	path := artifactPath + signing.BundleSuffix
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".sig-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// hubSignatureCheck is the outcome of checking one signature.
+type hubSignatureCheck struct {
+	Status string `json:"status"`
+	KeyID  string `json:"key_id,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// admit applies the trust policy. With a policy only a trusted signature is
+// admitted; without one anything but an invalid signature is, since a bundle
+// that fails to verify means the artifact or its bundle was altered.
+func (c hubSignatureCheck) admit(trusted signing.TrustedKeys, what string) error {
+	switch {
+	case c.Status == hubSignatureInvalid:
+		return fmt.Errorf("%s signature is invalid: %s", what, c.Detail)
+	case trusted == nil:
+		return nil
+	case c.Status == hubSignatureTrusted:
+		return nil
+	case c.Status == hubSignatureUnsigned:
+		return fmt.Errorf("%s is unsigned and --trusted-keys requires a trusted signature", what)
+	default:
+		return fmt.Errorf("%s: %w (key_id=%s)", what, signing.ErrUntrustedKey, c.KeyID)
+	}
+}
+
+func (c hubSignatureCheck) String() string {
+	switch {
+	case c.KeyID != "" && c.Detail != "":
+		return fmt.Sprintf("%s (key_id=%s: %s)", c.Status, c.KeyID, c.Detail)
+	case c.KeyID != "":
+		return fmt.Sprintf("%s (key_id=%s)", c.Status, c.KeyID)
+	case c.Detail != "":
+		return fmt.Sprintf("%s (%s)", c.Status, c.Detail)
+	default:
+		return c.Status
+	}
+}
+
+// checkSignature classifies a signature that has already been checked for
+// integrity (verifyErr) against the trust policy.
+func checkSignature(sig *signing.Signature, verifyErr error, trusted signing.TrustedKeys) hubSignatureCheck {
+	check := hubSignatureCheck{KeyID: sig.KeyID}
+	switch {
+	case verifyErr != nil:
+		check.Status = hubSignatureInvalid
+		check.Detail = verifyErr.Error()
+	case trusted == nil:
+		check.Status = hubSignatureValid
+	case trusted.Trusts(sig):
+		check.Status = hubSignatureTrusted
+	default:
+		check.Status = hubSignatureUntrusted
+	}
+	return check
+}
+
+// checkHubSignatureBundle checks a run artifact bundle (nil data: absent)
+// against the subjects the caller expects. Every expected subject must appear
+// with the same digest and size, and the claims must name this run.
+func checkHubSignatureBundle(data []byte, indexSetID, runID string, expected []signing.Subject, trusted signing.TrustedKeys) hubSignatureCheck {
+	if data == nil {
+		return hubSignatureCheck{Status: hubSignatureUnsigned}
+	}
+	bundle, err := signing.ParseBundle(data)
+	if err != nil {
+		return hubSignatureCheck{Status: hubSignatureInvalid, Detail: err.Error()}
+	}
+	verifyErr := bundle.Verify()
+	if verifyErr == nil {
+		verifyErr = matchHubBundleStatement(bundle.Statement, indexSetID, runID, expected)
+	}
+	return checkSignature(&bundle.Signature, verifyErr, trusted)
+}
+
+func matchHubBundleStatement(stmt signing.Statement, indexSetID, runID string, expected []signing.Subject) error {
+	for claim, want := range hubArtifactClaims(indexSetID, runID) {
+		if got := stmt.Claims[claim]; got != want {
+			return fmt.Errorf("statement %s is %q, want %q", claim, got, want)
+		}
+	}
+	for _, want := range expected {
+		got, ok := stmt.Subject(want.Name)
+		if !ok {
+			return fmt.Errorf("statement does not cover %s", want.Name)
+		}
+		if got.SHA256 != want.SHA256 || got.SizeBytes != want.SizeBytes {
+			return fmt.Errorf("%s digest does not match the signed statement", want.Name)
+		}
+	}
+	return nil
+}
+
+// signedHubBundleSubject returns the subject named name from a bundle that
+// has already been admitted, so its statement is authentic.
+func signedHubBundleSubject(data []byte, name string) (signing.Subject, bool) {
+	bundle, err := signing.ParseBundle(data)
+	if err != nil {
+		return signing.Subject{}, false
+	}
+	return bundle.Statement.Subject(name)
+}
+
+// downloadHubSignatureBundle reads the bundle beside a hub artifact. It
+// returns nil data, and no error, when the artifact is unsigned.
+func downloadHubSignatureBundle(ctx context.Context, getter provider.ObjectGetter, artifactKey string) ([]byte, error) {
+	data, err := downloadBytesBounded(ctx, getter, artifactKey+signing.BundleSuffix, maxHubMarkerBytes, "signature bundle")
+	if err != nil {
+		if provider.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read signature bundle: %w", err)
+	}
+	return data, nil
+}
+
+// signLatestPointer embeds a signature in doc. The pointer is replaced by a
+// conditional write, so its signature travels inline: a detached bundle could
+// not be swapped in the same write and would race a concurrent exporter.
+func signLatestPointer(doc *latestPointerDoc, key ed25519.PrivateKey) error {
+	doc.Signature = nil
+	sig, err := signing.Sign(key, doc)
+	if err != nil {
+		return err
+	}
+	doc.Signature = sig
+	return nil
+}
+
+// checkLatestPointerSignature checks the inline signature of a latest.json
+// pointer over the rest of the document.
+func checkLatestPointerSignature(doc latestPointerDoc, trusted signing.TrustedKeys) hubSignatureCheck {
+	if doc.Signature == nil {
+		return hubSignatureCheck{Status: hubSignatureUnsigned}
+	}
+	sig := doc.Signature
+	doc.Signature = nil
+	return checkSignature(sig, signing.Verify(sig, doc), trusted)
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/signing"
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+)
+
+// writeHubSigningKeys writes a PEM private key and a one-key trust policy for
+// it, returning both paths.
+func writeHubSigningKeys(t *testing.T) (keyPath, trustedPath string) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPath = filepath.Join(dir, "sign.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	trustedPath = filepath.Join(dir, "trusted.pem")
+	require.NoError(t, os.WriteFile(trustedPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600))
+	return keyPath, trustedPath
+}
+
+func newSigningExportTestCommand(args ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "export", RunE: runIndexExport}
+	for _, name := range []string{"hub", "index-set", "run-id", "db", "format", "hub-profile", "hub-region", "hub-endpoint", "hub-gcp-project"} {
+		cmd.Flags().String(name, "", "")
+	}
+	addLatestPointerFlags(cmd)
+	addHubSignKeyFlag(cmd)
+	addHubEncryptToFlag(cmd)
+	cmd.SetArgs(args)
+	cmd.SetContext(context.Background())
+	return cmd
+}
+
+func newSigningHydrateTestCommand(args ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "hydrate", RunE: runIndexHydrate, SilenceUsage: true, SilenceErrors: true}
+	for _, name := range []string{"hub", "index-set", "run-id", "dest", "hub-profile", "hub-region", "hub-endpoint", "hub-gcp-project"} {
+		cmd.Flags().String(name, "", "")
+	}
+	addHubTrustedKeysFlag(cmd)
+	addHubDecryptKeyFlag(cmd)
+	cmd.SetArgs(args)
+	cmd.SetContext(context.Background())
+	return cmd
+}
+
+// exportDurableForSigningTest creates a local durable snapshot and returns its
+// index set, run, and the --db path the export resolves it through.
+func exportDurableForSigningTest(t *testing.T) (*indexstore.IndexSet, *indexstore.IndexRun, string) {
+	t.Helper()
+	resetAppDataRootTestState(t)
+	t.Setenv("GONIMBUS_DATA_DIR", filepath.Join(t.TempDir(), "gonimbus-data"))
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+	db, err := indexstore.Open(ctx, indexstore.Config{Path: dbPath})
+	require.NoError(t, err)
+	require.NoError(t, indexstore.Migrate(ctx, db))
+	indexSet, _, err := indexstore.FindOrCreateIndexSet(ctx, db, testIndexSetParams("s3://bucket/prefix/"))
+	require.NoError(t, err)
+	run, err := indexstore.CreateIndexRun(ctx, db, indexSet.IndexSetID, "crawl")
+	require.NoError(t, err)
+	require.NoError(t, indexstore.UpdateIndexRunStatus(ctx, db, run.RunID, indexstore.RunStatusSuccess, nil))
+	_, _ = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	require.NoError(t, db.Close())
+	writeLocalDurableSnapshotForHubTest(t, indexSet.IndexSetID, run.RunID)
+	return indexSet, run, dbPath
+}
+
+// TestHubSigning_DurableExportHydrateTrustPolicy pins the signed hub contract:
+// a signed export publishes a manifest bundle and a signed pointer, hydrate
+// under a trust policy accepts them and keeps the bundle, and a policy that
+// does not list the signer refuses the run before downloading it.
+func TestHubSigning_DurableExportHydrateTrustPolicy(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	keyPath, trustedPath := writeHubSigningKeys(t)
+	_, otherTrustedPath := writeHubSigningKeys(t)
+	hubDir := t.TempDir()
+	hubURI := "file://" + hubDir + "/"
+
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "durable", "--sign-key", keyPath,
+	).Execute())
+
+	runDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID)
+	require.FileExists(t, filepath.Join(runDir, "manifest.json"+signing.BundleSuffix))
+	local, err := loadLocalDurableSnapshotForExport(indexSet.IndexSetID, run.RunID)
+	require.NoError(t, err)
+	require.FileExists(t, local.ManifestPath+signing.BundleSuffix, "export records the bundle beside the local manifest")
+
+	latestData, err := os.ReadFile(filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "latest.json"))
+	require.NoError(t, err)
+	var latest latestPointerDoc
+	require.NoError(t, json.Unmarshal(latestData, &latest))
+	require.NotNil(t, latest.Signature)
+
+	// Trusted: resolves through the signed pointer and keeps the bundle.
+	dest := t.TempDir()
+	require.NoError(t, newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--dest", dest, "--trusted-keys", trustedPath,
+	).Execute())
+	require.FileExists(t, filepath.Join(dest, "manifest.json"+signing.BundleSuffix))
+
+	// Untrusted signer: refused before any artifact lands.
+	dest = t.TempDir()
+	err = newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID, "--dest", dest, "--trusted-keys", otherTrustedPath,
+	).Execute()
+	require.ErrorIs(t, err, signing.ErrUntrustedKey)
+	require.NoFileExists(t, filepath.Join(dest, "manifest.json"))
+}
+
+// TestHubSigning_HydrateRefusesTamperedAndUnsigned pins that a bundle that no
+// longer matches complete.json fails even without a policy, and that a policy
+// refuses an unsigned run.
+func TestHubSigning_HydrateRefusesTamperedAndUnsigned(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	keyPath, trustedPath := writeHubSigningKeys(t)
+	hubDir := t.TempDir()
+	hubURI := "file://" + hubDir + "/"
+
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "durable",
+	).Execute())
+	err := newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID, "--dest", t.TempDir(), "--trusted-keys", trustedPath,
+	).Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsigned")
+
+	// A bundle signed for a different manifest digest is invalid.
+	key, err := signing.LoadPrivateKey(keyPath)
+	require.NoError(t, err)
+	bundle, err := buildHubSignatureBundle(key, indexSet.IndexSetID, run.RunID, signing.Subject{Name: "manifest.json", SHA256: "00", SizeBytes: 1})
+	require.NoError(t, err)
+	runDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID)
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "manifest.json"+signing.BundleSuffix), bundle, 0o644))
+	err = newSigningHydrateTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID, "--dest", t.TempDir(),
+	).Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "signature is invalid")
+}
+
+// TestHubSigning_SQLiteHydrateRequiresSignedIdentity pins that a signed SQLite
+// run's identity.json is checked against the signed statement, not against
+// whatever the unsigned complete.json still lists.
+func TestHubSigning_SQLiteHydrateRequiresSignedIdentity(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(dbPath), "identity.json"), []byte(`{"test":"identity"}`), 0o644))
+	keyPath, trustedPath := writeHubSigningKeys(t)
+	hubDir := t.TempDir()
+	hubURI := "file://" + hubDir + "/"
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "sqlite", "--sign-key", keyPath,
+	).Execute())
+	hydrate := func() error {
+		return newSigningHydrateTestCommand(
+			"--hub", hubURI, "--index-set", indexSet.IndexSetID, "--run-id", run.RunID, "--dest", t.TempDir(), "--trusted-keys", trustedPath,
+		).Execute()
+	}
+	require.NoError(t, hydrate())
+
+	// Drop identity_json from complete.json and forge the file.
+	runDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID)
+	completePath := filepath.Join(runDir, "complete.json")
+	data, err := os.ReadFile(completePath)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	delete(doc["artifacts"].(map[string]any), "identity_json")
+	data, err = json.Marshal(doc)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(completePath, data, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(runDir, "identity.json"), []byte(`{"forged":true}`), 0o644))
+	err = hydrate()
+	require.ErrorContains(t, err, "identity.json is covered by the index.db signature")
+}
+
+// TestHubSigning_LatestPointerSignatureCoversRunID pins that rewriting the
+// run a signed pointer names invalidates it.
+func TestHubSigning_LatestPointerSignatureCoversRunID(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	data, err := buildLatestPointerJSON(testFullIndexSetID, "run_1", key)
+	require.NoError(t, err)
+	var doc latestPointerDoc
+	require.NoError(t, json.Unmarshal(data, &doc))
+	require.Equal(t, hubSignatureValid, checkLatestPointerSignature(doc, nil).Status)
+
+	doc.RunID = "run_2"
+	require.Equal(t, hubSignatureInvalid, checkLatestPointerSignature(doc, nil).Status)
+}
+
+// TestIndexDoctorReportsDurableSignature pins doctor's signature report for a
+// durable snapshot signed at export.
+func TestIndexDoctorReportsDurableSignature(t *testing.T) {
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	keyPath, trustedPath := writeHubSigningKeys(t)
+	_, otherTrustedPath := writeHubSigningKeys(t)
+	require.NoError(t, newSigningExportTestCommand(
+		"--hub", "file://"+t.TempDir()+"/", "--index-set", indexSet.IndexSetID, "--run-id", run.RunID,
+		"--db", dbPath, "--format", "durable", "--sign-key", keyPath,
+	).Execute())
+
+	resolveOpts, err := indexReaderResolveOptions()
+	require.NoError(t, err)
+	targets, err := discoverDoctorTargets(resolveOpts)
+	require.NoError(t, err)
+	var target *indexDoctorTarget
+	for i := range targets {
+		if targets[i].Meta.IndexSetID == indexSet.IndexSetID {
+			target = &targets[i]
+		}
+	}
+	require.NotNil(t, target)
+
+	trusted, err := signing.LoadTrustedKeys(trustedPath)
+	require.NoError(t, err)
+	entry, err := inspectDurableForDoctor(target.Meta, indexDoctorOptions{TrustedKeys: trusted})
+	require.NoError(t, err)
+	require.NotNil(t, entry.Signature)
+	require.Equal(t, hubSignatureTrusted, entry.Signature.Status)
+
+	other, err := signing.LoadTrustedKeys(otherTrustedPath)
+	require.NoError(t, err)
+	entry, err = inspectDurableForDoctor(target.Meta, indexDoctorOptions{TrustedKeys: other})
+	require.NoError(t, err)
+	require.Equal(t, hubSignatureUntrusted, entry.Signature.Status)
+	require.False(t, entry.IdentityOK)
+}
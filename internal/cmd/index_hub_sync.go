@@ -0,0 +1,870 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/internal/signing"
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// --- sync ---
+
+var indexHubSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror index sets and runs from one hub to another",
+	Long: `Copy committed runs from one hub root to another, for example from a
+primary hub to a disaster-recovery mirror in another cloud.
+
+Every artifact a run's complete.json names is verified against its recorded
+SHA-256 and size before it is written to the mirror. Signature bundles are
+copied verbatim. complete.json is written last, so a run becomes visible at the
+mirror only once all of its artifacts are there.
+
+latest.json is copied after every selected run has landed, and only when the
+run it names is committed at the mirror. The copy is a conditional write like
+'index export' uses (--latest-write-mode, --latest-retry-max). The source
+document is copied byte for byte, so a signed pointer stays signed.
+
+Sync is idempotent and resumable. A run whose complete.json already matches the
+source is skipped. Artifacts already present at the mirror with the recorded
+digest are not copied again, so an interrupted sync picks up where it stopped.
+
+--keep N selects the latest-pointed run plus the N-1 most recent other
+committed runs of each index set, copies only those, and then prunes older
+committed runs at the mirror. Shared segments no retained mirror run references
+are swept once they are older than --segment-grace.
+
+Examples:
+  gonimbus index hub sync --from s3://primary/index-hub/ --to gs://dr-mirror/index-hub/
+  gonimbus index hub sync --from s3://primary/index-hub/ --to file:///mnt/mirror/ \
+    --index-set idx_da038d... --keep 3
+  gonimbus index hub sync --from s3://primary/index-hub/ --to gs://dr-mirror/index-hub/ --dry-run --json`,
+	RunE: runIndexHubSync,
+}
+
+func init() {
+	indexHubCmd.AddCommand(indexHubSyncCmd)
+	addHubSyncFlags(indexHubSyncCmd)
+}
+
+func addHubSyncFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", "Source hub root URI (required)")
+	cmd.Flags().String("to", "", "Mirror hub root URI (required)")
+	for _, side := range []string{"from", "to"} {
+		cmd.Flags().String(side+"-profile", "", "AWS profile for the "+side+" hub")
+		cmd.Flags().String(side+"-region", "", "AWS region for the "+side+" hub")
+		cmd.Flags().String(side+"-endpoint", "", "Custom endpoint for the "+side+" hub")
+		cmd.Flags().String(side+"-gcp-project", "", "GCP project hint for the "+side+" GCS hub")
+	}
+	cmd.Flags().StringSlice("index-set", nil, "Index set IDs to sync (repeatable; default: all)")
+	cmd.Flags().Int("keep", 0, "Mirror only the N most recent committed runs per index set and prune older ones")
+	cmd.Flags().Duration("segment-grace", defaultHubSegmentGrace, "With --keep, keep unreferenced shared segments younger than this")
+	cmd.Flags().Bool("dry-run", false, "Show what would be copied and pruned without writing")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+	addLatestPointerFlags(cmd)
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+}
+
+// Per-run sync statuses.
+const (
+	hubSyncRunCopied    = "copied"
+	hubSyncRunPresent   = "present"
+	hubSyncRunWouldCopy = "would-copy"
+	hubSyncRunFailed    = "failed"
+)
+
+// hubSyncRun is the outcome for one source run.
+type hubSyncRun struct {
+	RunID            string `json:"run_id"`
+	Status           string `json:"status"`
+	Transferred      int    `json:"transferred,omitempty"`
+	TransferredBytes int64  `json:"transferred_bytes,omitempty"`
+	Reused           int    `json:"reused,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// hubSyncSet is the outcome for one index set.
+type hubSyncSet struct {
+	IndexSetID     string       `json:"index_set_id"`
+	Runs           []hubSyncRun `json:"runs"`
+	LatestRunID    string       `json:"latest_run_id,omitempty"`
+	Latest         string       `json:"latest,omitempty"`
+	Pruned         []string     `json:"pruned,omitempty"`
+	SharedSegments int          `json:"shared_segments_removed,omitempty"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// hubSyncResult is the JSON envelope emitted by `gonimbus index hub sync --json`.
+type hubSyncResult struct {
+	DryRun    bool         `json:"dry_run"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	IndexSets []hubSyncSet `json:"index_sets"`
+	Errors    int          `json:"errors,omitempty"`
+}
+
+// hubSyncHub is one side of a sync.
+type hubSyncHub struct {
+	hub     *hubDestSpec
+	getter  provider.ObjectGetter
+	lister  provider.Provider
+	putter  provider.ObjectPutter
+	deleter provider.ObjectDeleter
+}
+
+// hubSyncSourceRun is a committed source run selected for sync.
+type hubSyncSourceRun struct {
+	runID        string
+	completeData []byte
+	complete     completeMarker
+	completedAt  time.Time
+}
+
+// hubSyncOptions carries the sync flags that shape each index set.
+type hubSyncOptions struct {
+	keep         int
+	segmentGrace time.Duration
+	dryRun       bool
+	latest       latestPointerOptions
+}
+
+// parseSyncHubFlags builds one side's hub spec from --<side> and the
+// --<side>-* auth flags.
+func parseSyncHubFlags(cmd *cobra.Command, side string) (*hubDestSpec, error) {
+	uri, _ := cmd.Flags().GetString(side)
+	hub, err := parseHubURI(uri)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %w", side, err)
+	}
+	hub.Profile, _ = cmd.Flags().GetString(side + "-profile")
+	hub.Region, _ = cmd.Flags().GetString(side + "-region")
+	hub.Endpoint, _ = cmd.Flags().GetString(side + "-endpoint")
+	hub.GCPProject, _ = cmd.Flags().GetString(side + "-gcp-project")
+	hub.GCPProject = strings.TrimSpace(hub.GCPProject)
+	if hub.Endpoint != "" {
+		hub.ForcePathStyle = true
+	}
+	return hub, nil
+}
+
+func runIndexHubSync(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	indexSetFlags, _ := cmd.Flags().GetStringSlice("index-set")
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	var opts hubSyncOptions
+	opts.keep, _ = cmd.Flags().GetInt("keep")
+	opts.segmentGrace, _ = cmd.Flags().GetDuration("segment-grace")
+	opts.dryRun, _ = cmd.Flags().GetBool("dry-run")
+
+	if opts.keep < 0 {
+		return fmt.Errorf("--keep must be positive")
+	}
+	if opts.segmentGrace < 0 {
+		return fmt.Errorf("--segment-grace must not be negative")
+	}
+	for _, id := range indexSetFlags {
+		if err := validateFullIndexSetID(id); err != nil {
+			return err
+		}
+	}
+	latestOpts, err := latestPointerOptionsFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	opts.latest = latestOpts
+
+	fromHub, err := parseSyncHubFlags(cmd, "from")
+	if err != nil {
+		return err
+	}
+	toHub, err := parseSyncHubFlags(cmd, "to")
+	if err != nil {
+		return err
+	}
+	if fromHub.Provider == toHub.Provider && fromHub.Bucket == toHub.Bucket && fromHub.Prefix == toHub.Prefix && fromHub.BaseDir == toHub.BaseDir {
+		return fmt.Errorf("--from and --to name the same hub")
+	}
+
+	src, closeSrc, err := openHubSyncHub(ctx, fromHub, false)
+	if err != nil {
+		return fmt.Errorf("source hub: %w", err)
+	}
+	defer closeSrc()
+	dst, closeDst, err := openHubSyncHub(ctx, toHub, !opts.dryRun)
+	if err != nil {
+		return fmt.Errorf("mirror hub: %w", err)
+	}
+	defer closeDst()
+
+	indexSetIDs := indexSetFlags
+	if len(indexSetIDs) == 0 {
+		indexSetIDs, err = discoverIndexSets(ctx, src.lister, hubArtifactKey(fromHub, "index-sets/"))
+		if err != nil {
+			return err
+		}
+	}
+
+	fromURI, _ := cmd.Flags().GetString("from")
+	toURI, _ := cmd.Flags().GetString("to")
+	result := hubSyncResult{DryRun: opts.dryRun, From: fromURI, To: toURI, IndexSets: []hubSyncSet{}}
+	for _, setID := range indexSetIDs {
+		if !jsonOutput {
+			_, _ = fmt.Fprintf(os.Stderr, "Syncing index_set=%s\n", setID)
+		}
+		set := syncHubIndexSet(ctx, src, dst, setID, opts)
+		if set.Error != "" {
+			result.Errors++
+		}
+		for _, run := range set.Runs {
+			if run.Status == hubSyncRunFailed {
+				result.Errors++
+			}
+		}
+		if !jsonOutput {
+			printHubSyncSet(set, opts.dryRun)
+		}
+		result.IndexSets = append(result.IndexSets, set)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	}
+	if result.Errors > 0 {
+		return fmt.Errorf("hub sync finished with %d error(s); re-run to resume", result.Errors)
+	}
+	if !jsonOutput {
+		_, _ = fmt.Fprintf(os.Stderr, "Sync complete: %d index set(s)\n", len(result.IndexSets))
+	}
+	return nil
+}
+
+// openHubSyncHub opens a hub for reading and, when writable, for writing and
+// deleting.
+func openHubSyncHub(ctx context.Context, hub *hubDestSpec, writable bool) (*hubSyncHub, func(), error) {
+	var closers []io.Closer
+	closeAll := func() {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+	}
+	getter, err := newHubGetter(ctx, hub)
+	if err != nil {
+		return nil, closeAll, err
+	}
+	if c, ok := getter.(io.Closer); ok {
+		closers = append(closers, c)
+	}
+	lister, ok := getter.(provider.Provider)
+	if !ok {
+		return nil, closeAll, fmt.Errorf("hub provider does not support listing")
+	}
+	h := &hubSyncHub{hub: hub, getter: getter, lister: lister}
+	if !writable {
+		return h, closeAll, nil
+	}
+	putter, err := newHubProvider(ctx, hub)
+	if err != nil {
+		return nil, closeAll, err
+	}
+	if c, ok := putter.(io.Closer); ok {
+		closers = append(closers, c)
+	}
+	h.putter = putter
+	if d, ok := getter.(provider.ObjectDeleter); ok {
+		h.deleter = d
+	} else if d, ok := putter.(provider.ObjectDeleter); ok {
+		h.deleter = d
+	}
+	return h, closeAll, nil
+}
+
+func printHubSyncSet(set hubSyncSet, dryRun bool) {
+	for _, run := range set.Runs {
+		switch run.Status {
+		case hubSyncRunFailed:
+			_, _ = fmt.Fprintf(os.Stderr, "  %s: failed: %s\n", run.RunID, run.Error)
+		case hubSyncRunCopied:
+			_, _ = fmt.Fprintf(os.Stderr, "  %s: copied (%d transferred, %d bytes; %d already present)\n", run.RunID, run.Transferred, run.TransferredBytes, run.Reused)
+		default:
+			_, _ = fmt.Fprintf(os.Stderr, "  %s: %s\n", run.RunID, run.Status)
+		}
+	}
+	if set.Latest != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "  latest.json -> %s: %s\n", set.LatestRunID, set.Latest)
+	}
+	verb := "pruned"
+	if dryRun {
+		verb = "would prune"
+	}
+	for _, runID := range set.Pruned {
+		_, _ = fmt.Fprintf(os.Stderr, "  %s %s\n", verb, runID)
+	}
+	if set.SharedSegments > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "  %s %d unreferenced shared segment(s)\n", verb, set.SharedSegments)
+	}
+	if set.Error != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "  error: %s\n", set.Error)
+	}
+}
+
+// syncHubIndexSet copies one index set's selected runs, then its pointer,
+// then applies --keep at the mirror. Run failures are recorded per run and do
+// not stop the others, but a failed pointed-to run leaves latest.json alone.
+func syncHubIndexSet(ctx context.Context, src, dst *hubSyncHub, setID string, opts hubSyncOptions) hubSyncSet {
+	set := hubSyncSet{IndexSetID: setID, Runs: []hubSyncRun{}}
+	latestKey := hubArtifactKey(src.hub, "index-sets", setID, "latest.json")
+	latestData, err := downloadBytesBounded(ctx, src.getter, latestKey, maxHubMarkerBytes, "latest.json")
+	if err != nil && !provider.IsNotFound(err) {
+		set.Error = fmt.Sprintf("read source latest.json: %v", err)
+		return set
+	}
+	var latest latestPointerDoc
+	if latestData != nil {
+		if latest, err = parseHubLatestPointer(latestData, setID); err != nil {
+			set.Error = fmt.Sprintf("source latest.json: %v", err)
+			return set
+		}
+		set.LatestRunID = latest.RunID
+	}
+
+	runs, err := listHubSyncSourceRuns(ctx, src, setID, &set)
+	if err != nil {
+		set.Error = err.Error()
+		return set
+	}
+	runs = selectHubSyncRuns(runs, latest.RunID, opts.keep)
+
+	committed := map[string]bool{}
+	for _, run := range runs {
+		result := syncHubRun(ctx, src, dst, setID, run, opts.dryRun)
+		set.Runs = append(set.Runs, result)
+		if result.Status != hubSyncRunFailed {
+			committed[run.runID] = true
+		}
+	}
+
+	if latestData != nil {
+		switch {
+		case !committed[latest.RunID]:
+			set.Latest = "skipped: run is not committed at the mirror"
+		case opts.dryRun:
+			current, err := downloadBytesBounded(ctx, dst.getter, hubArtifactKey(dst.hub, "index-sets", setID, "latest.json"), maxHubMarkerBytes, "latest.json")
+			if err == nil && bytes.Equal(current, latestData) {
+				set.Latest = string(latestPointerUnchanged)
+			} else {
+				set.Latest = "would update"
+			}
+		default:
+			outcome, err := mirrorLatestPointer(ctx, dst, setID, latest.RunID, latestData, opts.latest)
+			if err != nil {
+				set.Error = fmt.Sprintf("update mirror latest.json: %v", err)
+				return set
+			}
+			set.Latest = string(outcome)
+		}
+	}
+
+	if opts.keep > 0 {
+		if err := pruneHubSyncMirror(ctx, dst, setID, opts, &set); err != nil {
+			set.Error = fmt.Sprintf("prune mirror: %v", err)
+		}
+	}
+	return set
+}
+
+// listHubSyncSourceRuns reads every committed source run's complete.json.
+// Runs without one are uncommitted and not mirrored; unreadable markers are
+// recorded as failed runs.
+func listHubSyncSourceRuns(ctx context.Context, src *hubSyncHub, setID string, set *hubSyncSet) ([]hubSyncSourceRun, error) {
+	runIDs, err := discoverRuns(ctx, src.lister, hubArtifactKey(src.hub, "index-sets", setID, "runs/"))
+	if err != nil {
+		return nil, fmt.Errorf("list source runs: %w", err)
+	}
+	var runs []hubSyncSourceRun
+	for _, runID := range runIDs {
+		key := hubArtifactKey(src.hub, "index-sets", setID, "runs", runID, "complete.json")
+		data, err := downloadBytesBounded(ctx, src.getter, key, maxHubCompleteMarkerBytes, "complete.json")
+		if err != nil {
+			if !provider.IsNotFound(err) {
+				set.Runs = append(set.Runs, hubSyncRun{RunID: runID, Status: hubSyncRunFailed, Error: fmt.Sprintf("read complete.json: %v", err)})
+			}
+			continue
+		}
+		run := hubSyncSourceRun{runID: runID, completeData: data}
+		var envelope hubCompleteEnvelope
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			set.Runs = append(set.Runs, hubSyncRun{RunID: runID, Status: hubSyncRunFailed, Error: fmt.Sprintf("parse complete.json: %v", err)})
+			continue
+		}
+		run.complete = envelope.completeMarker
+		if t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(envelope.CompletedAt)); err == nil {
+			run.completedAt = t
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// selectHubSyncRuns applies --keep with the same accounting as hub gc: the
+// latest-pointed run always counts as one of the N. Runs are returned oldest
+// first so a mirror fills in the order the source did.
+func selectHubSyncRuns(runs []hubSyncSourceRun, latestRunID string, keep int) []hubSyncSourceRun {
+	newestFirst := func(i, j int) bool {
+		if !runs[i].completedAt.Equal(runs[j].completedAt) {
+			return runs[i].completedAt.After(runs[j].completedAt)
+		}
+		return runs[i].runID > runs[j].runID
+	}
+	sort.Slice(runs, newestFirst)
+	if keep > 0 {
+		slots := keep
+		var selected []hubSyncSourceRun
+		for _, run := range runs {
+			if run.runID == latestRunID {
+				selected = append(selected, run)
+				slots--
+			}
+		}
+		for _, run := range runs {
+			if slots <= 0 {
+				break
+			}
+			if run.runID != latestRunID {
+				selected = append(selected, run)
+				slots--
+			}
+		}
+		runs = selected
+	}
+	sort.Slice(runs, func(i, j int) bool { return newestFirst(j, i) })
+	return runs
+}
+
+// hubSyncArtifact is one object a run needs at the mirror. rel is relative to
+// the index set root, and ref pins the object as stored: for an encrypted run,
+// the envelope. Sync needs no keys. A nil ref marks an optional artifact the
+// marker does not pin (signature bundles, an unpinned identity.json), copied
+// verbatim when the source has it.
+type hubSyncArtifact struct {
+	rel    string
+	ref    *artifactRef
+	shared bool
+}
+
+// hubSyncArtifacts lists what a committed run needs, in upload order. The
+// marker is validated first so it cannot direct writes outside the run or the
+// shared segment store.
+func hubSyncArtifacts(setID, runID string, complete completeMarker) ([]hubSyncArtifact, error) {
+	runRel := "runs/" + runID + "/"
+	if err := validateHubEncryption(complete); err != nil {
+		return nil, err
+	}
+	var artifacts []hubSyncArtifact
+	switch completeMarkerFormat(complete) {
+	case indexHubFormatDurableV2:
+		if err := validateDurableCompleteMarker(setID, runID, complete); err != nil {
+			return nil, err
+		}
+		add := func(ref artifactRef) error {
+			ref = ref.stored()
+			if _, err := safeLocalArtifactPath(".", ref.Path); err != nil || ref.Path == "" {
+				return fmt.Errorf("durable artifact path %q is not a relative path", ref.Path)
+			}
+			if !validSHA256(ref.SHA256) {
+				return fmt.Errorf("durable artifact %s has an invalid sha256", ref.Path)
+			}
+			if ref.ContentPath != "" {
+				artifacts = append(artifacts, hubSyncArtifact{rel: ref.ContentPath, ref: &ref, shared: true})
+			} else {
+				artifacts = append(artifacts, hubSyncArtifact{rel: runRel + ref.Path, ref: &ref})
+			}
+			return nil
+		}
+		for _, ref := range complete.Artifacts.Segments {
+			if err := add(ref); err != nil {
+				return nil, err
+			}
+		}
+		for _, ref := range []*artifactRef{complete.Artifacts.PrefixStats, complete.Artifacts.KeyIndex} {
+			if ref == nil {
+				continue
+			}
+			if err := add(*ref); err != nil {
+				return nil, err
+			}
+		}
+		if err := add(*complete.Artifacts.Manifest); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, hubSyncArtifact{rel: runRel + "manifest.json" + signing.BundleSuffix})
+	case indexHubFormatSQLiteV1:
+		if complete.Artifacts.IndexDB == nil {
+			return nil, fmt.Errorf("sqlite hub run has no verifiable index_db artifact")
+		}
+		indexDB := complete.Artifacts.IndexDB.stored()
+		if !validSHA256(indexDB.SHA256) {
+			return nil, fmt.Errorf("sqlite hub run has no verifiable index_db artifact")
+		}
+		artifacts = append(artifacts, hubSyncArtifact{rel: runRel + "index.db", ref: &indexDB})
+		identity := hubSyncArtifact{rel: runRel + "identity.json"}
+		if ref := complete.Artifacts.IdentityJSON; ref != nil && ref.SHA256 != "" {
+			stored := ref.stored()
+			if !validSHA256(stored.SHA256) {
+				return nil, fmt.Errorf("sqlite identity.json artifact has an invalid sha256")
+			}
+			identity.ref = &stored
+		}
+		artifacts = append(artifacts, identity, hubSyncArtifact{rel: runRel + "index.db" + signing.BundleSuffix})
+	default:
+		return nil, fmt.Errorf("unsupported hub format %q", completeMarkerFormat(complete))
+	}
+	return artifacts, nil
+}
+
+// syncHubRun copies one committed run. A mirror complete.json identical to
+// the source's means the run is already mirrored. A differing one is removed
+// before anything is copied, so the mirror never shows a marker over a mix of
+// old and new artifacts.
+func syncHubRun(ctx context.Context, src, dst *hubSyncHub, setID string, run hubSyncSourceRun, dryRun bool) hubSyncRun {
+	result := hubSyncRun{RunID: run.runID}
+	fail := func(err error) hubSyncRun {
+		result.Status = hubSyncRunFailed
+		result.Error = err.Error()
+		return result
+	}
+	artifacts, err := hubSyncArtifacts(setID, run.runID, run.complete)
+	if err != nil {
+		return fail(err)
+	}
+	completeKey := hubArtifactKey(dst.hub, "index-sets", setID, "runs", run.runID, "complete.json")
+	current, err := downloadBytesBounded(ctx, dst.getter, completeKey, maxHubCompleteMarkerBytes, "complete.json")
+	switch {
+	case err == nil && bytes.Equal(current, run.completeData):
+		result.Status = hubSyncRunPresent
+		return result
+	case err != nil && !provider.IsNotFound(err):
+		return fail(fmt.Errorf("read mirror complete.json: %w", err))
+	case dryRun:
+		result.Status = hubSyncRunWouldCopy
+		return result
+	case err == nil:
+		if dst.deleter == nil {
+			return fail(fmt.Errorf("mirror holds a different complete.json and does not support deletion"))
+		}
+		if err := dst.deleter.DeleteObject(ctx, completeKey); err != nil {
+			return fail(fmt.Errorf("retract stale mirror complete.json: %w", err))
+		}
+	}
+
+	// Shared segments the mirror already holds are reused in place, so mark
+	// the run in flight for a concurrent gc, as export does. The marker goes
+	// once this run is done either way; this sync's own prune follows.
+	shared := len(hubSharedSegmentArtifacts(run.complete)) > 0
+	if shared {
+		if err := writeHubPendingMarker(ctx, dst.putter, dst.hub, setID, run.runID, time.Now()); err != nil {
+			return fail(err)
+		}
+		defer clearHubPendingMarker(ctx, dst.deleter, dst.hub, setID, run.runID)
+	}
+	var stats hubTransferStats
+	for _, artifact := range artifacts {
+		srcKey := hubArtifactKey(src.hub, "index-sets", setID, artifact.rel)
+		dstKey := hubArtifactKey(dst.hub, "index-sets", setID, artifact.rel)
+		if err := copyHubSyncArtifact(ctx, src, dst, srcKey, dstKey, artifact, &stats); err != nil {
+			return fail(fmt.Errorf("%s: %w", path.Base(artifact.rel), err))
+		}
+	}
+	if err := uploadBytes(ctx, dst.putter, completeKey, run.completeData); err != nil {
+		return fail(fmt.Errorf("write complete.json: %w", err))
+	}
+	if shared {
+		if err := verifyHubSharedSegments(ctx, dst.lister, dst.hub, setID, run.completeData); err != nil {
+			if dst.deleter != nil {
+				_ = dst.deleter.DeleteObject(ctx, completeKey)
+			}
+			return fail(err)
+		}
+	}
+	result.Status = hubSyncRunCopied
+	result.Transferred = stats.Transferred
+	result.TransferredBytes = stats.TransferredBytes
+	result.Reused = stats.Reused
+	return result
+}
+
+// copyHubSyncArtifact copies one artifact unless the mirror already holds it.
+// Pinned artifacts are verified on the way through; a shared one present at
+// its content key with the right size is the artifact, and any other present
+// copy is hashed before it is trusted.
+func copyHubSyncArtifact(ctx context.Context, src, dst *hubSyncHub, srcKey, dstKey string, artifact hubSyncArtifact, stats *hubTransferStats) error {
+	ref := artifact.ref
+	if ref == nil {
+		data, err := downloadBytesBounded(ctx, src.getter, srcKey, maxHubMarkerBytes, path.Base(srcKey))
+		if provider.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if existing, err := downloadBytesBounded(ctx, dst.getter, dstKey, maxHubMarkerBytes, path.Base(dstKey)); err == nil && bytes.Equal(existing, data) {
+			stats.Reused++
+			return nil
+		}
+		if err := uploadBytes(ctx, dst.putter, dstKey, data); err != nil {
+			return err
+		}
+		stats.Transferred++
+		stats.TransferredBytes += int64(len(data))
+		return nil
+	}
+
+	if meta, err := dst.lister.Head(ctx, dstKey); err == nil && meta.Size == ref.SizeBytes {
+		if artifact.shared {
+			stats.Reused++
+			return nil
+		}
+		if sha, _, err := hashHubObject(ctx, dst.getter, dstKey); err == nil && sha == ref.SHA256 {
+			stats.Reused++
+			return nil
+		}
+	} else if err != nil && !provider.IsNotFound(err) {
+		return fmt.Errorf("check mirror: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "gonimbus-hub-sync-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmpPath) }()
+	if err := downloadFile(ctx, src.getter, srcKey, tmpPath); err != nil {
+		return fmt.Errorf("download: %w", err)
+	}
+	sha, size, err := hashFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if sha != ref.SHA256 || size != ref.SizeBytes {
+		return fmt.Errorf("source integrity check failed: expected sha256=%s size=%d, got sha256=%s size=%d", ref.SHA256, ref.SizeBytes, sha, size)
+	}
+	if err := uploadToOutputDest(ctx, dst.putter, dstKey, tmpPath); err != nil {
+		return err
+	}
+	stats.Transferred++
+	stats.TransferredBytes += size
+	return nil
+}
+
+// hashHubObject streams a hub object through SHA-256.
+func hashHubObject(ctx context.Context, getter provider.ObjectGetter, key string) (string, int64, error) {
+	body, _, err := getter.GetObject(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = body.Close() }()
+	h := sha256.New()
+	n, err := io.Copy(h, body)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// mirrorLatestPointer makes the mirror's latest.json a byte copy of the
+// source's, so an inline signature survives. Unlike advanceLatestPointer it
+// does not yield to a newer mirror run: the mirror follows the source,
+// including a rollback. The write is conditional on the pointer read, and a
+// conflict re-reads and retries within the same budget.
+func mirrorLatestPointer(ctx context.Context, dst *hubSyncHub, setID, runID string, data []byte, opts latestPointerOptions) (latestPointerOutcome, error) {
+	latestKey := hubArtifactKey(dst.hub, "index-sets", setID, "latest.json")
+	if opts.Mode == latestWriteModeUnconditional {
+		if err := uploadBytes(ctx, dst.putter, latestKey, data); err != nil {
+			return "", err
+		}
+		return latestPointerUpdated, nil
+	}
+	versioned, ok := dst.getter.(provider.VersionedGetter)
+	if !ok {
+		return "", fmt.Errorf("mirror provider does not support versioned reads; re-run with --latest-write-mode unconditional")
+	}
+	conditional, ok := dst.putter.(provider.ConditionalPutter)
+	if !ok {
+		return "", fmt.Errorf("mirror provider does not support conditional writes; re-run with --latest-write-mode unconditional")
+	}
+	for attempt := 0; ; attempt++ {
+		var precond provider.PutPrecondition
+		var currentRunID string
+		body, meta, err := versioned.GetObjectVersioned(ctx, latestKey)
+		switch {
+		case provider.IsNotFound(err):
+			precond = provider.PutPrecondition{IfAbsent: true}
+		case err != nil:
+			return "", fmt.Errorf("read mirror latest.json: %w", err)
+		default:
+			current, readErr := readAllBounded(body, meta.Size, maxHubMarkerBytes, "latest.json")
+			_ = body.Close()
+			if readErr != nil {
+				return "", readErr
+			}
+			if bytes.Equal(current, data) {
+				return latestPointerUnchanged, nil
+			}
+			if strings.TrimSpace(meta.ETag) == "" {
+				return "", fmt.Errorf("versioned latest.json read did not return an ETag")
+			}
+			var doc latestPointerDoc
+			if json.Unmarshal(current, &doc) == nil {
+				currentRunID = doc.RunID
+			}
+			etag := meta.ETag
+			precond = provider.PutPrecondition{IfMatchETag: &etag}
+		}
+
+		if _, err := conditional.PutObjectConditional(ctx, latestKey, bytes.NewReader(data), int64(len(data)), precond); err == nil {
+			return latestPointerUpdated, nil
+		} else if !provider.IsAlreadyExists(err) && !provider.IsPreconditionFailed(err) {
+			return "", fmt.Errorf("write latest.json: %w", err)
+		}
+
+		rec := latestCASRecord{IndexSet: setID, RunID: runID, Current: currentRunID, Attempt: attempt + 1, MaxRetries: opts.RetryMax}
+		if attempt >= opts.RetryMax {
+			rec.Message = "CAS conflict budget exhausted on mirror latest pointer"
+			_ = emitLatestCASEvent(ctx, opts.Events, dst.hub, casFailRecordType, rec)
+			return "", fmt.Errorf("CAS conflict budget exhausted; re-run sync to retry")
+		}
+		rec.Message = "CAS conflict observed; retrying mirror latest pointer update"
+		_ = emitLatestCASEvent(ctx, opts.Events, dst.hub, casRetryRecordType, rec)
+		if delay := latestRetryDelay(opts.RetryBase, attempt); delay > 0 {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+	}
+}
+
+// pruneHubSyncMirror applies --keep at the mirror with hub gc's accounting:
+// the mirror's latest run plus the N-1 most recent other committed runs stay.
+// Mirror runs without complete.json are left alone, since they may be a sync
+// in progress; one with a fresh pending marker also keeps the set's shared
+// segments. A run's complete.json is deleted first, so a partly pruned run is
+// uncommitted rather than broken.
+func pruneHubSyncMirror(ctx context.Context, dst *hubSyncHub, setID string, opts hubSyncOptions, set *hubSyncSet) error {
+	if !opts.dryRun && dst.deleter == nil {
+		return fmt.Errorf("mirror provider does not support deletion")
+	}
+	latestRunID := ""
+	latest, ok, err := readHubLatestPointer(ctx, dst.getter, hubArtifactKey(dst.hub, "index-sets", setID, "latest.json"), setID)
+	if err != nil {
+		return fmt.Errorf("read mirror latest.json: %w", err)
+	}
+	if ok {
+		latestRunID = latest.RunID
+	}
+	if opts.dryRun && set.LatestRunID != "" && set.Latest == "would update" {
+		latestRunID = set.LatestRunID
+	}
+
+	runIDs, err := discoverRuns(ctx, dst.lister, hubArtifactKey(dst.hub, "index-sets", setID, "runs/"))
+	if err != nil {
+		return fmt.Errorf("list mirror runs: %w", err)
+	}
+	segments := gcSegmentSet{IndexSetID: setID, RunRefs: map[string][]string{}}
+	var committed []hubSyncSourceRun
+	for _, runID := range runIDs {
+		data, err := downloadBytesBounded(ctx, dst.getter, hubArtifactKey(dst.hub, "index-sets", setID, "runs", runID, "complete.json"), maxHubCompleteMarkerBytes, "complete.json")
+		if provider.IsNotFound(err) {
+			if inFlight, _ := hubRunInFlight(ctx, dst.getter, dst.hub, setID, runID, opts.segmentGrace, time.Now()); inFlight {
+				segments.InFlight = append(segments.InFlight, runID)
+			}
+			continue
+		}
+		if err != nil {
+			segments.Blocked = true
+			continue
+		}
+		summary, err := summarizeHubRunMarker(data)
+		if err != nil {
+			segments.Blocked = true
+			continue
+		}
+		segments.RunRefs[runID] = summary.SharedSegments
+		run := hubSyncSourceRun{runID: runID}
+		if t, tErr := time.Parse(time.RFC3339Nano, summary.CompletedAt); tErr == nil {
+			run.completedAt = t
+		}
+		committed = append(committed, run)
+	}
+	keep := map[string]bool{}
+	for _, run := range selectHubSyncRuns(committed, latestRunID, opts.keep) {
+		keep[run.runID] = true
+	}
+
+	var removed []gcRunCandidate
+	for _, run := range committed {
+		if keep[run.runID] {
+			continue
+		}
+		set.Pruned = append(set.Pruned, run.runID)
+		candidate := gcRunCandidate{IndexSetID: setID, RunID: run.runID}
+		if !opts.dryRun {
+			if err := deleteHubSyncRun(ctx, dst, setID, run.runID); err != nil {
+				candidate.Error = err.Error()
+				set.Error = fmt.Sprintf("prune %s: %v", run.runID, err)
+			}
+		}
+		removed = append(removed, candidate)
+	}
+
+	orphans := planGCSharedSegments(ctx, dst.lister, dst.hub, []gcSegmentSet{segments}, removed, opts.segmentGrace, time.Now())
+	for _, orphan := range orphans {
+		if !opts.dryRun {
+			if err := dst.deleter.DeleteObject(ctx, orphan.Key); err != nil {
+				return fmt.Errorf("delete shared segment %s: %w", path.Base(orphan.Key), err)
+			}
+		}
+		set.SharedSegments++
+	}
+	return nil
+}
+
+func deleteHubSyncRun(ctx context.Context, dst *hubSyncHub, setID, runID string) error {
+	runPrefix := hubArtifactKey(dst.hub, "index-sets", setID, "runs", runID) + "/"
+	completeKey := runPrefix + "complete.json"
+	if err := dst.deleter.DeleteObject(ctx, completeKey); err != nil && !provider.IsNotFound(err) {
+		return err
+	}
+	keys, err := listAllKeys(ctx, dst.lister, runPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == completeKey {
+			continue
+		}
+		if err := dst.deleter.DeleteObject(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
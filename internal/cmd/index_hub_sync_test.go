@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+	providerfile "github.com/3leaps/gonimbus/pkg/provider/file"
+)
+
+func newHubSyncTestCommand(args ...string) *cobra.Command {
+	cmd := &cobra.Command{Use: "sync", RunE: runIndexHubSync, SilenceUsage: true, SilenceErrors: true}
+	addHubSyncFlags(cmd)
+	cmd.SetArgs(args)
+	cmd.SetContext(context.Background())
+	return cmd
+}
+
+// exportDurableRunsForSyncTest exports n durable runs of one index set to a
+// fresh file hub, signing each, and returns the set, the run IDs (oldest
+// first), and the hub directory.
+func exportDurableRunsForSyncTest(t *testing.T, n int) (string, []string, string) {
+	t.Helper()
+	indexSet, run, dbPath := exportDurableForSigningTest(t)
+	keyPath, _ := writeHubSigningKeys(t)
+	runIDs := []string{run.RunID}
+	for len(runIDs) < n {
+		db, err := indexstore.Open(context.Background(), indexstore.Config{Path: dbPath})
+		require.NoError(t, err)
+		next, err := indexstore.CreateIndexRun(context.Background(), db, indexSet.IndexSetID, "crawl")
+		require.NoError(t, err)
+		require.NoError(t, indexstore.UpdateIndexRunStatus(context.Background(), db, next.RunID, indexstore.RunStatusSuccess, nil))
+		require.NoError(t, db.Close())
+		writeLocalDurableSnapshotForHubTest(t, indexSet.IndexSetID, next.RunID)
+		runIDs = append(runIDs, next.RunID)
+	}
+	hubDir := t.TempDir()
+	for _, runID := range runIDs {
+		require.NoError(t, newSigningExportTestCommand(
+			"--hub", "file://"+hubDir+"/", "--index-set", indexSet.IndexSetID, "--run-id", runID,
+			"--db", dbPath, "--format", "durable", "--sign-key", keyPath,
+		).Execute())
+	}
+	return indexSet.IndexSetID, runIDs, hubDir
+}
+
+func runHubSyncJSON(t *testing.T, args ...string) (hubSyncResult, error) {
+	t.Helper()
+	captured, err := captureHubStdout(t, func() error {
+		return newHubSyncTestCommand(append(args, "--json")...).Execute()
+	})
+	var result hubSyncResult
+	require.NoError(t, json.Unmarshal(captured, &result))
+	return result, err
+}
+
+// TestHubSync_MirrorsRunsAndPointerIdempotently pins the mirror contract: all
+// committed runs land with their pointer copied byte for byte (so its
+// signature survives), hydrate works from the mirror, and a second sync
+// copies nothing.
+func TestHubSync_MirrorsRunsAndPointerIdempotently(t *testing.T) {
+	setID, runIDs, srcDir := exportDurableRunsForSyncTest(t, 2)
+	dstDir := t.TempDir()
+	from, to := "file://"+srcDir+"/", "file://"+dstDir+"/"
+
+	result, err := runHubSyncJSON(t, "--from", from, "--to", to)
+	require.NoError(t, err)
+	require.Len(t, result.IndexSets, 1)
+	set := result.IndexSets[0]
+	require.Len(t, set.Runs, 2)
+	for _, run := range set.Runs {
+		require.Equal(t, hubSyncRunCopied, run.Status, run.Error)
+	}
+	require.Equal(t, string(latestPointerUpdated), set.Latest)
+	srcLatest, err := os.ReadFile(filepath.Join(srcDir, "index-sets", setID, "latest.json"))
+	require.NoError(t, err)
+	dstLatest, err := os.ReadFile(filepath.Join(dstDir, "index-sets", setID, "latest.json"))
+	require.NoError(t, err)
+	require.Equal(t, srcLatest, dstLatest)
+	var pointer latestPointerDoc
+	require.NoError(t, json.Unmarshal(dstLatest, &pointer))
+	require.Equal(t, runIDs[1], pointer.RunID)
+	require.Equal(t, hubSignatureValid, checkLatestPointerSignature(pointer, nil).Status)
+
+	require.NoError(t, newSigningHydrateTestCommand(
+		"--hub", to, "--index-set", setID, "--dest", t.TempDir(),
+	).Execute())
+
+	result, err = runHubSyncJSON(t, "--from", from, "--to", to)
+	require.NoError(t, err)
+	for _, run := range result.IndexSets[0].Runs {
+		require.Equal(t, hubSyncRunPresent, run.Status)
+	}
+	require.Equal(t, string(latestPointerUnchanged), result.IndexSets[0].Latest)
+}
+
+// TestHubSync_ResumesAndRefusesCorruptSource pins resumability and digest
+// verification: an interrupted run is completed without recopying what
+// landed, and a source artifact that fails its digest is never committed at
+// the mirror, nor is the pointer moved to it.
+func TestHubSync_ResumesAndRefusesCorruptSource(t *testing.T) {
+	setID, runIDs, srcDir := exportDurableRunsForSyncTest(t, 1)
+	dstDir := t.TempDir()
+	from, to := "file://"+srcDir+"/", "file://"+dstDir+"/"
+	_, err := runHubSyncJSON(t, "--from", from, "--to", to)
+	require.NoError(t, err)
+
+	// Simulate an interruption before complete.json and one segment landed.
+	mirrorRun := filepath.Join(dstDir, "index-sets", setID, "runs", runIDs[0])
+	require.NoError(t, os.Remove(filepath.Join(mirrorRun, "complete.json")))
+	sharedDir := filepath.Join(dstDir, "index-sets", setID, "segments", "sha256")
+	entries, err := os.ReadDir(sharedDir)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(sharedDir, entries[0].Name())))
+
+	result, err := runHubSyncJSON(t, "--from", from, "--to", to)
+	require.NoError(t, err)
+	run := result.IndexSets[0].Runs[0]
+	require.Equal(t, hubSyncRunCopied, run.Status)
+	require.Equal(t, 1, run.Transferred, "only the missing segment is copied")
+	require.FileExists(t, filepath.Join(mirrorRun, "complete.json"))
+
+	// Corrupt the source copy of a segment the mirror lacks.
+	require.NoError(t, os.Remove(filepath.Join(mirrorRun, "complete.json")))
+	require.NoError(t, os.Remove(filepath.Join(sharedDir, entries[0].Name())))
+	require.NoError(t, os.Remove(filepath.Join(dstDir, "index-sets", setID, "latest.json")))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "index-sets", setID, "segments", "sha256", entries[0].Name()), []byte("tampered"), 0o644))
+
+	result, err = runHubSyncJSON(t, "--from", from, "--to", to)
+	require.Error(t, err)
+	require.Equal(t, hubSyncRunFailed, result.IndexSets[0].Runs[0].Status)
+	require.Contains(t, result.IndexSets[0].Runs[0].Error, "integrity")
+	require.NoFileExists(t, filepath.Join(mirrorRun, "complete.json"))
+	require.NoFileExists(t, filepath.Join(dstDir, "index-sets", setID, "latest.json"))
+}
+
+// TestHubSync_KeepSelectsAndPrunes pins --keep: only the newest runs are
+// copied, and older committed runs already at the mirror are pruned.
+func TestHubSync_KeepSelectsAndPrunes(t *testing.T) {
+	setID, runIDs, srcDir := exportDurableRunsForSyncTest(t, 3)
+	dstDir := t.TempDir()
+	from, to := "file://"+srcDir+"/", "file://"+dstDir+"/"
+
+	result, err := runHubSyncJSON(t, "--from", from, "--to", to, "--keep", "2", "--index-set", setID)
+	require.NoError(t, err)
+	var synced []string
+	for _, run := range result.IndexSets[0].Runs {
+		synced = append(synced, run.RunID)
+	}
+	require.Equal(t, runIDs[1:], synced)
+	require.NoFileExists(t, filepath.Join(dstDir, "index-sets", setID, "runs", runIDs[0], "complete.json"))
+
+	result, err = runHubSyncJSON(t, "--from", from, "--to", to, "--keep", "1", "--segment-grace", "0s")
+	require.NoError(t, err)
+	require.Equal(t, []string{runIDs[1]}, result.IndexSets[0].Pruned)
+	require.NoFileExists(t, filepath.Join(dstDir, "index-sets", setID, "runs", runIDs[1], "complete.json"))
+	require.FileExists(t, filepath.Join(dstDir, "index-sets", setID, "runs", runIDs[2], "complete.json"))
+	require.NoError(t, newSigningHydrateTestCommand(
+		"--hub", to, "--index-set", setID, "--dest", t.TempDir(),
+	).Execute())
+}
+
+// TestHubSync_PruneSkipsSetWithInFlightRun pins that the mirror's prune honors
+// a pending marker the way hub gc does: a run still being written there may
+// reuse shared segments no committed run references yet.
+func TestHubSync_PruneSkipsSetWithInFlightRun(t *testing.T) {
+	setID, runIDs, srcDir := exportDurableRunsForSyncTest(t, 1)
+	dstDir := t.TempDir()
+	from, to := "file://"+srcDir+"/", "file://"+dstDir+"/"
+	_, err := runHubSyncJSON(t, "--from", from, "--to", to)
+	require.NoError(t, err)
+	setDir := filepath.Join(dstDir, "index-sets", setID)
+	require.NoFileExists(t, filepath.Join(setDir, "runs", runIDs[0], "pending.json"), "a synced run removes its pending marker")
+
+	orphan := filepath.Join(setDir, filepath.FromSlash(hubSharedSegmentPath(sha256HexBytes([]byte("reused")))))
+	require.NoError(t, os.WriteFile(orphan, []byte("reused"), 0o644))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(orphan, old, old))
+	prov, err := providerfile.New(providerfile.Config{BaseDir: dstDir})
+	require.NoError(t, err)
+	require.NoError(t, writeHubPendingMarker(context.Background(), prov, &hubDestSpec{}, setID, "run_9000000000000000000", time.Now()))
+
+	result, err := runHubSyncJSON(t, "--from", from, "--to", to, "--keep", "1", "--segment-grace", "1h")
+	require.NoError(t, err)
+	require.Zero(t, result.IndexSets[0].SharedSegments)
+	require.FileExists(t, orphan)
+
+	require.NoError(t, writeHubPendingMarker(context.Background(), prov, &hubDestSpec{}, setID, "run_9000000000000000000", old))
+	result, err = runHubSyncJSON(t, "--from", from, "--to", to, "--keep", "1", "--segment-grace", "1h")
+	require.NoError(t, err)
+	require.Equal(t, 1, result.IndexSets[0].SharedSegments)
+	require.NoFileExists(t, orphan)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"crypto/ecdh"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -12,8 +13,10 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/3leaps/gonimbus/internal/envelope"
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/internal/signing"
 	"github.com/3leaps/gonimbus/pkg/provider"
 	"github.com/spf13/cobra"
 )
@@ -34,6 +37,22 @@ Resolution order:
 After download, the integrity of index.db is verified against the SHA-256
 recorded in complete.json.
 
+Signatures written by 'index export --sign-key' are checked whenever present:
+the run's detached bundle (index.db.sig.json or manifest.json.sig.json) against
+complete.json, and the inline signature of latest.json when it resolves the
+run. A signature that does not verify always fails the hydrate. With
+--trusted-keys (or GONIMBUS_TRUSTED_KEYS) naming a PEM file of ed25519 public
+keys, the run and any latest.json used must also be signed by one of them;
+unsigned or untrusted artifacts are refused before anything is downloaded.
+The verified bundle is kept in the destination beside complete.json.
+
+Runs exported with --encrypt-to are decrypted on the way down with
+--decrypt-key (or GONIMBUS_DECRYPT_KEY_FILE / GONIMBUS_DECRYPT_KEY holding a
+PEM PKCS#8 X25519 key). The key must be one of the run's recipients; that is
+checked before anything is downloaded. Each envelope is verified against
+complete.json, then the plaintext is verified against its own digest, so the
+destination holds the same files an unencrypted hydrate would.
+
 Examples:
   # Hydrate latest run to a local directory
   gonimbus index hydrate --hub file:///data/index-hub/ \
@@ -44,6 +63,16 @@ Examples:
     --index-set idx_da038d8171b4a9ba --dest /tmp/hydrated/ \
     --hub-profile my-profile
 
+  # Hydrate only runs signed by a trusted key
+  gonimbus index hydrate --hub s3://my-bucket/index-hub/ \
+    --index-set idx_da038d8171b4a9ba --dest /tmp/hydrated/ \
+    --trusted-keys ~/.config/gonimbus/hub-trusted.pem
+
+  # Hydrate an encrypted run
+  gonimbus index hydrate --hub s3://shared-bucket/index-hub/ \
+    --index-set idx_da038d8171b4a9ba --dest /tmp/hydrated/ \
+    --decrypt-key ~/.config/gonimbus/hub-decrypt.pem
+
   # Hydrate a specific run
   gonimbus index hydrate --hub s3://my-bucket/index-hub/ \
     --index-set idx_da038d8171b4a9ba --run-id run_1709654400000000000 \
@@ -64,6 +93,8 @@ func init() {
 	indexHydrateCmd.Flags().String("hub-region", "", "AWS region for hub source")
 	indexHydrateCmd.Flags().String("hub-endpoint", "", "Custom endpoint for hub source")
 	indexHydrateCmd.Flags().String("hub-gcp-project", "", "GCP project hint for GCS hub source")
+	addHubTrustedKeysFlag(indexHydrateCmd)
+	addHubDecryptKeyFlag(indexHydrateCmd)
 
 	_ = indexHydrateCmd.MarkFlagRequired("hub")
 	_ = indexHydrateCmd.MarkFlagRequired("index-set")
@@ -129,6 +160,15 @@ func runIndexHydrate(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	trusted, err := hubTrustedKeysFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	decryptKey, err := hubDecryptKeyFromCommand(cmd)
+	if err != nil {
+		return err
+	}
+
 	// Parse hub
 	hub, err := parseHubURI(hubURI)
 	if err != nil {
@@ -154,11 +194,21 @@ func runIndexHydrate(cmd *cobra.Command, _ []string) error {
 	// Resolve run ID
 	runID := runIDFlag
 	if runID == "" {
-		resolved, resolveErr := resolveLatestRunID(ctx, getter, hub, indexSetFlag)
+		latest, resolveErr := resolveLatestPointer(ctx, getter, hub, indexSetFlag)
 		if resolveErr != nil {
 			return resolveErr
 		}
-		runID = resolved
+		check := checkLatestPointerSignature(latest, trusted)
+		if check.Status != hubSignatureUnsigned || trusted != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "  latest.json signature: %s\n", check)
+		}
+		if err := check.admit(trusted, "latest.json"); err != nil {
+			return err
+		}
+		if check.Status != hubSignatureUnsigned && latest.IndexSetID != indexSetFlag {
+			return fmt.Errorf("signed latest.json names index set %s, not %s", latest.IndexSetID, indexSetFlag)
+		}
+		runID = latest.RunID
 	}
 
 	_, _ = fmt.Fprintf(os.Stderr, "Hydrating index_set=%s run=%s from %s\n", indexSetFlag, runID, hubURI)
@@ -180,6 +230,12 @@ func runIndexHydrate(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("parse complete.json: %w", err)
 	}
 	format := completeMarkerFormat(complete)
+	if err := validateHubEncryption(complete); err != nil {
+		return err
+	}
+	if err := admitHubDecryption(complete, decryptKey); err != nil {
+		return err
+	}
 
 	// Prepare destination
 	if err := os.MkdirAll(destDir, 0o755); err != nil {
@@ -187,9 +243,9 @@ func runIndexHydrate(cmd *cobra.Command, _ []string) error {
 	}
 	switch format {
 	case indexHubFormatSQLiteV1:
-		return hydrateSQLiteRun(ctx, getter, runPrefix, complete, completeData, destDir)
+		return hydrateSQLiteRun(ctx, getter, runPrefix, indexSetFlag, runID, complete, completeData, destDir, trusted, decryptKey)
 	case indexHubFormatDurableV2:
-		return hydrateDurableRun(ctx, getter, runPrefix, indexSetFlag, runID, complete, completeData, destDir)
+		return hydrateDurableRun(ctx, getter, runPrefix, indexSetFlag, runID, complete, completeData, destDir, trusted, decryptKey)
 	case "":
 		return fmt.Errorf("complete.json has no format and no sqlite index_db artifact")
 	default:
@@ -197,38 +253,54 @@ func runIndexHydrate(cmd *cobra.Command, _ []string) error {
 	}
 }
 
-func hydrateSQLiteRun(ctx context.Context, getter provider.ObjectGetter, runPrefix string, complete completeMarker, completeData []byte, destDir string) error {
+func hydrateSQLiteRun(ctx context.Context, getter provider.ObjectGetter, runPrefix, indexSetID, runID string, complete completeMarker, completeData []byte, destDir string, trusted signing.TrustedKeys, decryptKey *ecdh.PrivateKey) error {
 	if complete.Artifacts.IndexDB == nil {
 		return fmt.Errorf("sqlite hub run is missing index_db artifact")
 	}
-	// Download index.db
 	indexDBKey := runPrefix + "/index.db"
-	indexDBDest := filepath.Join(destDir, "index.db")
-	_, _ = fmt.Fprintf(os.Stderr, "  downloading index.db (%d bytes)...\n", complete.Artifacts.IndexDB.SizeBytes)
-	if err := downloadFile(ctx, getter, indexDBKey, indexDBDest); err != nil {
-		return fmt.Errorf("download index.db: %w", err)
-	}
 
-	// Verify index.db integrity
-	actualHash, actualSize, err := hashFile(indexDBDest)
+	// Check the signature against complete.json before downloading; the
+	// downloads are then verified against the same digests.
+	subjects := []signing.Subject{{Name: "index.db", SHA256: complete.Artifacts.IndexDB.SHA256, SizeBytes: complete.Artifacts.IndexDB.SizeBytes}}
+	if ref := complete.Artifacts.IdentityJSON; ref != nil && ref.SHA256 != "" {
+		subjects = append(subjects, signing.Subject{Name: "identity.json", SHA256: ref.SHA256, SizeBytes: ref.SizeBytes})
+	}
+	bundle, err := admitHydrateSignature(ctx, getter, indexDBKey, "index.db", indexSetID, runID, subjects, trusted)
 	if err != nil {
-		return fmt.Errorf("verify index.db: %w", err)
+		return err
 	}
-	if actualHash != complete.Artifacts.IndexDB.SHA256 {
-		return fmt.Errorf("index.db integrity check failed: expected sha256=%s, got %s", complete.Artifacts.IndexDB.SHA256, actualHash)
+	// complete.json is unsigned, so it cannot decide what the signature
+	// covers: a run signed over identity.json must still list it.
+	identityRef := complete.Artifacts.IdentityJSON
+	if bundle != nil {
+		if _, signed := signedHubBundleSubject(bundle, "identity.json"); signed && (identityRef == nil || identityRef.SHA256 == "") {
+			return fmt.Errorf("identity.json is covered by the index.db signature but complete.json does not list it")
+		}
 	}
-	if actualSize != complete.Artifacts.IndexDB.SizeBytes {
-		return fmt.Errorf("index.db size mismatch: expected %d, got %d", complete.Artifacts.IndexDB.SizeBytes, actualSize)
+
+	// Download index.db
+	indexDBDest := filepath.Join(destDir, "index.db")
+	_, _ = fmt.Fprintf(os.Stderr, "  downloading index.db (%d bytes)...\n", complete.Artifacts.IndexDB.SizeBytes)
+	if err := fetchHubArtifact(ctx, getter, indexDBKey, *complete.Artifacts.IndexDB, indexDBDest, decryptKey); err != nil {
+		return fmt.Errorf("index.db %w", err)
 	}
 
 	// Download identity.json (optional)
 	identityKey := runPrefix + "/identity.json"
 	identityDest := filepath.Join(destDir, "identity.json")
-	if err := downloadFile(ctx, getter, identityKey, identityDest); err != nil {
-		if provider.IsNotFound(err) {
-			_, _ = fmt.Fprintln(os.Stderr, "  identity.json not present in hub (older index)")
-		} else {
+	if ref := identityRef; ref != nil && ref.Envelope != nil {
+		if err := fetchHubArtifact(ctx, getter, identityKey, *ref, identityDest, decryptKey); err != nil {
+			return fmt.Errorf("identity.json %w", err)
+		}
+		_, _ = fmt.Fprintln(os.Stderr, "  downloaded identity.json")
+	} else if err := downloadFile(ctx, getter, identityKey, identityDest); err != nil {
+		switch {
+		case !provider.IsNotFound(err):
 			return fmt.Errorf("download identity.json: %w", err)
+		case identityRef != nil && identityRef.SHA256 != "":
+			return fmt.Errorf("identity.json is listed in complete.json but missing from the hub")
+		default:
+			_, _ = fmt.Fprintln(os.Stderr, "  identity.json not present in hub (older index)")
 		}
 	} else {
 		_, _ = fmt.Fprintln(os.Stderr, "  downloaded identity.json")
@@ -248,7 +320,12 @@ func hydrateSQLiteRun(ctx context.Context, getter provider.ObjectGetter, runPref
 		}
 	}
 
-	// Write complete.json to dest for provenance
+	// Write the bundle and complete.json to dest for provenance
+	if bundle != nil {
+		if err := os.WriteFile(filepath.Join(destDir, "index.db"+signing.BundleSuffix), bundle, 0o644); err != nil {
+			return fmt.Errorf("write index.db signature: %w", err)
+		}
+	}
 	completeDest := filepath.Join(destDir, "complete.json")
 	if err := os.WriteFile(completeDest, completeData, 0o644); err != nil {
 		return fmt.Errorf("write complete.json: %w", err)
@@ -258,7 +335,7 @@ func hydrateSQLiteRun(ctx context.Context, getter provider.ObjectGetter, runPref
 	return nil
 }
 
-func hydrateDurableRun(ctx context.Context, getter provider.ObjectGetter, runPrefix, indexSetID, runID string, complete completeMarker, completeData []byte, destDir string) error {
+func hydrateDurableRun(ctx context.Context, getter provider.ObjectGetter, runPrefix, indexSetID, runID string, complete completeMarker, completeData []byte, destDir string, trusted signing.TrustedKeys, decryptKey *ecdh.PrivateKey) error {
 	if err := validateDurableCompleteMarker(indexSetID, runID, complete); err != nil {
 		return err
 	}
@@ -270,11 +347,25 @@ func hydrateDurableRun(ctx context.Context, getter provider.ObjectGetter, runPre
 		return fmt.Errorf("durable manifest size %d exceeds limit %d", manifestRef.SizeBytes, maxDurableManifestBytes)
 	}
 	manifestKey := runPrefix + "/manifest.json"
+	bundle, err := admitHydrateSignature(ctx, getter, manifestKey, "manifest.json", indexSetID, runID,
+		[]signing.Subject{{Name: "manifest.json", SHA256: manifestRef.SHA256, SizeBytes: manifestRef.SizeBytes}}, trusted)
+	if err != nil {
+		return err
+	}
 	_, _ = fmt.Fprintf(os.Stderr, "  downloading manifest.json (%d bytes)...\n", manifestRef.SizeBytes)
-	manifestData, err := downloadBytesBounded(ctx, getter, manifestKey, maxDurableManifestBytes, "manifest.json")
+	manifestLimit := int64(maxDurableManifestBytes)
+	if manifestRef.Envelope != nil {
+		manifestLimit = envelope.MaxSealedSize(manifestLimit)
+	}
+	manifestData, err := downloadBytesBounded(ctx, getter, manifestKey, manifestLimit, "manifest.json")
 	if err != nil {
 		return fmt.Errorf("download durable manifest: %w", err)
 	}
+	if manifestRef.Envelope != nil {
+		if manifestData, err = openHubBytes(manifestData, manifestRef, decryptKey); err != nil {
+			return fmt.Errorf("durable manifest: %w", err)
+		}
+	}
 	if int64(len(manifestData)) != manifestRef.SizeBytes {
 		return fmt.Errorf("durable manifest size mismatch: expected %d, got %d", manifestRef.SizeBytes, len(manifestData))
 	}
@@ -296,31 +387,65 @@ func hydrateDurableRun(ctx context.Context, getter provider.ObjectGetter, runPre
 		return err
 	}
 
+	setPrefix := strings.TrimSuffix(runPrefix, "/runs/"+runID)
+	artifactKey := func(ref artifactRef) string {
+		if ref.ContentPath != "" {
+			return setPrefix + "/" + ref.ContentPath
+		}
+		return runPrefix + "/" + ref.Path
+	}
+	// Segments already on disk (a previous hydrate into destDir, or the local
+	// durable cache) are reused by digest instead of downloaded again.
+	reuse := newHydrateReuseIndex(destDir, indexSetID)
+	var stats hubTransferStats
 	for _, segment := range manifest.Segments {
 		ref := segmentRefs["segments/"+segment.Path]
 		destPath, err := safeLocalArtifactPath(destDir, ref.Path)
 		if err != nil {
 			return fmt.Errorf("segment %s: %w", segment.SegmentID, err)
 		}
-		key := runPrefix + "/" + ref.Path
-		_, _ = fmt.Fprintf(os.Stderr, "  downloading segment %s (%d bytes)...\n", segment.Path, ref.SizeBytes)
-		if err := downloadFile(ctx, getter, key, destPath); err != nil {
-			return fmt.Errorf("download segment %s: %w", segment.Path, err)
+		_, _ = fmt.Fprintf(os.Stderr, "  segment %s (%d bytes)...\n", segment.Path, ref.SizeBytes)
+		if err := hydrateDurableArtifact(ctx, getter, artifactKey(ref), ref, destPath, reuse, decryptKey, &stats); err != nil {
+			return fmt.Errorf("segment %s: %w", segment.Path, err)
 		}
-		gotSHA, gotSize, err := hashFile(destPath)
+	}
+	prefixRef, err := durablePrefixStatsArtifact(complete, manifest)
+	if err != nil {
+		return err
+	}
+	if prefixRef != nil {
+		destPath, err := safeLocalArtifactPath(destDir, prefixRef.Path)
 		if err != nil {
-			return fmt.Errorf("verify segment %s: %w", segment.Path, err)
+			return fmt.Errorf("prefix stats: %w", err)
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "  prefix stats (%d bytes)...\n", prefixRef.SizeBytes)
+		if err := hydrateDurableArtifact(ctx, getter, artifactKey(*prefixRef), *prefixRef, destPath, reuse, decryptKey, &stats); err != nil {
+			return fmt.Errorf("prefix stats: %w", err)
 		}
-		if gotSHA != ref.SHA256 {
-			return fmt.Errorf("segment %s integrity check failed: expected sha256=%s, got %s", segment.Path, ref.SHA256, gotSHA)
+	}
+	keyRef, err := durableKeyIndexArtifact(complete, manifest)
+	if err != nil {
+		return err
+	}
+	if keyRef != nil {
+		destPath, err := safeLocalArtifactPath(destDir, keyRef.Path)
+		if err != nil {
+			return fmt.Errorf("key index: %w", err)
 		}
-		if gotSize != ref.SizeBytes {
-			return fmt.Errorf("segment %s size mismatch: expected %d, got %d", segment.Path, ref.SizeBytes, gotSize)
+		_, _ = fmt.Fprintf(os.Stderr, "  key index (%d bytes)...\n", keyRef.SizeBytes)
+		if err := hydrateDurableArtifact(ctx, getter, artifactKey(*keyRef), *keyRef, destPath, reuse, decryptKey, &stats); err != nil {
+			return fmt.Errorf("key index: %w", err)
 		}
 	}
+	_, _ = fmt.Fprintf(os.Stderr, "  segments: %s\n", stats)
 	if err := os.WriteFile(filepath.Join(destDir, "manifest.json"), manifestData, 0o644); err != nil {
 		return fmt.Errorf("write manifest.json: %w", err)
 	}
+	if bundle != nil {
+		if err := os.WriteFile(filepath.Join(destDir, "manifest.json"+signing.BundleSuffix), bundle, 0o644); err != nil {
+			return fmt.Errorf("write manifest.json signature: %w", err)
+		}
+	}
 	if err := os.WriteFile(filepath.Join(destDir, "complete.json"), completeData, 0o644); err != nil {
 		return fmt.Errorf("write complete.json: %w", err)
 	}
@@ -369,6 +494,59 @@ func durableSegmentArtifactMap(complete completeMarker, manifest indexsubstrate.
 	return refs, nil
 }
 
+// durablePrefixStatsArtifact checks the complete marker's prefix artifact
+// against the digest-bound manifest descriptor. Both are absent for runs
+// published before the artifact existed; one without the other is refused.
+func durablePrefixStatsArtifact(complete completeMarker, manifest indexsubstrate.InternalManifest) (*artifactRef, error) {
+	var desc *durableSideDescriptor
+	if d := manifest.PrefixStats; d != nil {
+		desc = &durableSideDescriptor{path: d.Path, sha256: d.Digest.Hex, size: d.SizeBytes}
+	}
+	return durableSideArtifact(complete.Artifacts.PrefixStats, desc, "prefix_stats", "prefix stats", indexsubstrate.DefaultMaxPrefixStatsBytes)
+}
+
+// durableKeyIndexArtifact is durablePrefixStatsArtifact for the key index.
+func durableKeyIndexArtifact(complete completeMarker, manifest indexsubstrate.InternalManifest) (*artifactRef, error) {
+	var desc *durableSideDescriptor
+	if d := manifest.KeyIndex; d != nil {
+		desc = &durableSideDescriptor{path: d.Path, sha256: d.Digest.Hex, size: d.SizeBytes}
+	}
+	return durableSideArtifact(complete.Artifacts.KeyIndex, desc, "key_index", "key index", indexsubstrate.DefaultMaxKeyIndexBytes)
+}
+
+// durableSideDescriptor is the manifest binding of an artifact published
+// beside the segments.
+type durableSideDescriptor struct {
+	path   string
+	sha256 string
+	size   int64
+}
+
+func durableSideArtifact(ref *artifactRef, desc *durableSideDescriptor, role, what string, maxBytes int64) (*artifactRef, error) {
+	switch {
+	case ref == nil && desc == nil:
+		return nil, nil
+	case ref == nil:
+		return nil, fmt.Errorf("durable complete marker missing %s artifact", what)
+	case desc == nil:
+		return nil, fmt.Errorf("durable complete marker lists %s the manifest does not bind", what)
+	}
+	want := "segments/" + desc.path
+	if ref.Path != want || ref.Role != role || !ref.Required {
+		return nil, fmt.Errorf("durable %s artifact must be the required %s role at %s", what, role, want)
+	}
+	if _, err := safeLocalArtifactPath(".", ref.Path); err != nil {
+		return nil, fmt.Errorf("durable %s artifact %q: %w", what, ref.Path, err)
+	}
+	if ref.SHA256 != desc.sha256 || ref.SizeBytes != desc.size {
+		return nil, fmt.Errorf("durable %s artifact mismatch for %s", what, ref.Path)
+	}
+	if ref.SizeBytes > maxBytes {
+		return nil, fmt.Errorf("durable %s size %d exceeds limit %d", what, ref.SizeBytes, maxBytes)
+	}
+	return ref, nil
+}
+
 // validFullIndexSetPattern matches idx_<exactly 64 hex chars>.
 var validFullIndexSetPattern = regexp.MustCompile(`^idx_[0-9a-f]{64}$`)
 
@@ -396,32 +574,58 @@ func validateRunID(id string) error {
 	return nil
 }
 
+// admitHydrateSignature checks the signature bundle beside a hub artifact
+// against the digests complete.json recorded and applies the trust policy. It
+// returns the bundle to keep beside the hydrated artifact, or nil when the run
+// is unsigned and no policy requires a signature.
+func admitHydrateSignature(ctx context.Context, getter provider.ObjectGetter, artifactKey, artifact, indexSetID, runID string, subjects []signing.Subject, trusted signing.TrustedKeys) ([]byte, error) {
+	bundle, err := downloadHubSignatureBundle(ctx, getter, artifactKey)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", artifact, err)
+	}
+	check := checkHubSignatureBundle(bundle, indexSetID, runID, subjects, trusted)
+	if check.Status != hubSignatureUnsigned || trusted != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "  %s signature: %s\n", artifact, check)
+	}
+	if err := check.admit(trusted, artifact); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
 // resolveLatestRunID reads latest.json from the hub to determine the run ID.
 func resolveLatestRunID(ctx context.Context, getter provider.ObjectGetter, hub *hubDestSpec, indexSetID string) (string, error) {
+	latest, err := resolveLatestPointer(ctx, getter, hub, indexSetID)
+	if err != nil {
+		return "", err
+	}
+	return latest.RunID, nil
+}
+
+// resolveLatestPointer reads and validates the latest.json pointer document.
+func resolveLatestPointer(ctx context.Context, getter provider.ObjectGetter, hub *hubDestSpec, indexSetID string) (latestPointerDoc, error) {
 	latestKey := hubArtifactKey(hub, "index-sets", indexSetID, "latest.json")
 	data, err := downloadBytesBounded(ctx, getter, latestKey, maxHubMarkerBytes, "latest.json")
 	if err != nil {
 		if provider.IsNotFound(err) {
-			return "", fmt.Errorf("no latest.json found for index set %s; use --run-id to specify explicitly", indexSetID)
+			return latestPointerDoc{}, fmt.Errorf("no latest.json found for index set %s; use --run-id to specify explicitly", indexSetID)
 		}
-		return "", fmt.Errorf("read latest.json: %w", err)
+		return latestPointerDoc{}, fmt.Errorf("read latest.json: %w", err)
 	}
 
-	var latest struct {
-		RunID string `json:"run_id"`
-	}
+	var latest latestPointerDoc
 	if err := json.Unmarshal(data, &latest); err != nil {
-		return "", fmt.Errorf("parse latest.json: %w", err)
+		return latestPointerDoc{}, fmt.Errorf("parse latest.json: %w", err)
 	}
 	if latest.RunID == "" {
-		return "", fmt.Errorf("latest.json has empty run_id")
+		return latestPointerDoc{}, fmt.Errorf("latest.json has empty run_id")
 	}
 	if err := validateRunID(latest.RunID); err != nil {
-		return "", fmt.Errorf("latest.json contains %w", err)
+		return latestPointerDoc{}, fmt.Errorf("latest.json contains %w", err)
 	}
 
 	_, _ = fmt.Fprintf(os.Stderr, "  resolved latest run: %s\n", latest.RunID)
-	return latest.RunID, nil
+	return latest, nil
 }
 
 // completeMarker is the subset of complete.json needed for hydration verification.
@@ -437,15 +641,25 @@ type completeMarker struct {
 		IdentityJSON *artifactRef  `json:"identity_json,omitempty"`
 		Manifest     *artifactRef  `json:"manifest,omitempty"`
 		Segments     []artifactRef `json:"segments,omitempty"`
+		PrefixStats  *artifactRef  `json:"prefix_stats,omitempty"`
+		KeyIndex     *artifactRef  `json:"key_index,omitempty"`
 	} `json:"artifacts"`
+	Encryption *hubEncryptionInfo `json:"encryption,omitempty"`
 }
 
 type artifactRef struct {
-	Path      string `json:"path,omitempty"`
-	Role      string `json:"role,omitempty"`
-	Required  bool   `json:"required,omitempty"`
-	SizeBytes int64  `json:"size_bytes"`
-	SHA256    string `json:"sha256"`
+	Path string `json:"path,omitempty"`
+	// ContentPath, on v2 durable markers, is where the bytes live relative to
+	// the index set root (segments/sha256/<sha256>); Path stays the artifact's
+	// place in the run's local layout.
+	ContentPath string `json:"content_path,omitempty"`
+	Role        string `json:"role,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	SizeBytes   int64  `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+	// Envelope, on an encrypted run, pins the sealed object the hub holds;
+	// SizeBytes and SHA256 describe the plaintext.
+	Envelope *artifactDigest `json:"envelope,omitempty"`
 }
 
 // downloadBytes reads a small object entirely into memory.
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -350,7 +351,8 @@ func TestRunIndexExportHydrate_DurableFileHub(t *testing.T) {
 	runDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID)
 	require.NoFileExists(t, filepath.Join(runDir, "index.db"))
 	require.FileExists(t, filepath.Join(runDir, "manifest.json"))
-	require.FileExists(t, filepath.Join(runDir, "segments", localManifest.Segments[0].Path))
+	require.NoDirExists(t, filepath.Join(runDir, "segments"))
+	require.FileExists(t, filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, hubSharedSegmentPath(localManifest.Segments[0].Digest.Hex)))
 	completeData, err := os.ReadFile(filepath.Join(runDir, "complete.json"))
 	require.NoError(t, err)
 	var complete map[string]any
@@ -386,6 +388,14 @@ func TestRunIndexExportHydrate_DurableFileHub(t *testing.T) {
 	rows, err := indexsubstrate.ReadManifestRows(filepath.Join(hydrateDir, "segments"), hydratedManifest)
 	require.NoError(t, err)
 	require.Len(t, rows, 2)
+	require.NotNil(t, hydratedManifest.PrefixStats)
+	prefixes, err := indexsubstrate.ReadPrefixStatsVerified(filepath.Join(hydrateDir, "segments"), *hydratedManifest.PrefixStats)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, prefixes.Prefixes[0].ObjectsRecursive)
+	require.NotNil(t, hydratedManifest.KeyIndex)
+	keyIndex, err := indexsubstrate.ReadKeyIndexVerified(filepath.Join(hydrateDir, "segments"), *hydratedManifest.KeyIndex)
+	require.NoError(t, err)
+	require.Len(t, keyIndex.Segments, len(hydratedManifest.Segments))
 }
 
 // TestRunIndexExportHydrate_StreamedDurableSegments proves export→hydrate
@@ -441,7 +451,8 @@ func TestRunIndexExportHydrate_StreamedDurableSegments(t *testing.T) {
 
 	runDir := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, "runs", run.RunID)
 	require.FileExists(t, filepath.Join(runDir, "manifest.json"))
-	require.FileExists(t, filepath.Join(runDir, "segments", localManifest.Segments[0].Path))
+	require.NoDirExists(t, filepath.Join(runDir, "segments"))
+	require.FileExists(t, filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, hubSharedSegmentPath(localManifest.Segments[0].Digest.Hex)))
 
 	hydrateDir := t.TempDir()
 	hydrateCmd := &cobra.Command{Use: "hydrate", RunE: runIndexHydrate}
@@ -472,7 +483,7 @@ func TestRunIndexExportHydrate_StreamedDurableSegments(t *testing.T) {
 
 	// Segment digests match source streamed artifacts.
 	for _, seg := range hydratedManifest.Segments {
-		srcDigest, err := sha256HexFile(filepath.Join(runDir, "segments", seg.Path))
+		srcDigest, err := sha256HexFile(filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, hubSharedSegmentPath(seg.Digest.Hex)))
 		require.NoError(t, err)
 		destDigest, err := sha256HexFile(filepath.Join(hydrateDir, "segments", seg.Path))
 		require.NoError(t, err)
@@ -480,8 +491,10 @@ func TestRunIndexExportHydrate_StreamedDurableSegments(t *testing.T) {
 		require.Equal(t, seg.Digest.Hex, destDigest)
 	}
 
-	// Tamper one streamed segment on the hub and prove hydrate refuses.
-	tamperPath := filepath.Join(runDir, "segments", localManifest.Segments[0].Path)
+	// Tamper one streamed segment on the hub and prove hydrate refuses. A fresh
+	// data dir stands in for another machine, so no local copy can be reused.
+	t.Setenv("GONIMBUS_DATA_DIR", filepath.Join(t.TempDir(), "other-host"))
+	tamperPath := filepath.Join(hubDir, "index-sets", indexSet.IndexSetID, hubSharedSegmentPath(localManifest.Segments[0].Digest.Hex))
 	require.NoError(t, os.WriteFile(tamperPath, []byte("not-valid-parquet-anymore"), 0o600))
 	badDest := t.TempDir()
 	hydrateCmd2 := &cobra.Command{Use: "hydrate", RunE: runIndexHydrate}
@@ -890,6 +903,23 @@ func TestRunIndexHydrate_DurableRejectsNonCanonicalSegmentArtifactPath(t *testin
 	require.Contains(t, err.Error(), "durable segment artifact path must be canonical")
 }
 
+func TestRunIndexHydrate_DurableRejectsPrefixStatsDrift(t *testing.T) {
+	ctx := context.Background()
+	hubDir, indexSetID, runID := writeDurableHubRunForHydrateTest(t, func(complete map[string]any) {
+		artifacts := complete["artifacts"].(map[string]any)
+		delete(artifacts, "prefix_stats")
+	}, nil)
+	err := runHydrateFileHubForTest(ctx, hubDir, indexSetID, runID, t.TempDir())
+	require.ErrorContains(t, err, "missing prefix stats artifact")
+
+	hubDir, indexSetID, runID = writeDurableHubRunForHydrateTest(t, func(complete map[string]any) {
+		artifacts := complete["artifacts"].(map[string]any)
+		artifacts["prefix_stats"].(map[string]any)["sha256"] = strings.Repeat("0", 64)
+	}, nil)
+	err = runHydrateFileHubForTest(ctx, hubDir, indexSetID, runID, t.TempDir())
+	require.ErrorContains(t, err, "durable prefix stats artifact mismatch")
+}
+
 func TestValidateDurableHubManifestBoundsRejectsExcessiveSegments(t *testing.T) {
 	manifest := indexsubstrate.InternalManifest{
 		Segments: make([]indexsubstrate.SegmentDescriptor, maxDurableHubSegments+1),
@@ -1118,7 +1148,7 @@ func writeDurableHubRunForHydrateTest(t *testing.T, mutateComplete func(map[stri
 		ManifestSHA:  manifestSHA,
 		ManifestSize: manifestSize,
 		SegmentDir:   segmentDir,
-	})
+	}, false, nil)
 	require.NoError(t, err)
 	var complete map[string]any
 	require.NoError(t, json.Unmarshal(completeBytes, &complete))
@@ -103,16 +103,21 @@ func runIndexJobsList(cmd *cobra.Command, _ []string) error {
 		return nil
 	}
 
+	positions := jobregistry.QueuePositions(jobs)
 	if jsonOutput {
+		items := make([]indexJobListItem, 0, len(jobs))
+		for _, j := range jobs {
+			items = append(items, indexJobListItem{JobRecord: j, QueuePosition: positions[j.JobID]})
+		}
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
-		return enc.Encode(jobs)
+		return enc.Encode(items)
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	defer func() { _ = w.Flush() }()
 
-	_, _ = fmt.Fprintln(w, "JOB ID\tNAME\tSTATE\tSTARTED\tENDED\tINDEX SET\tRUN\tMANIFEST")
+	_, _ = fmt.Fprintln(w, "JOB ID\tNAME\tSTATE\tQUEUE\tSTARTED\tENDED\tINDEX SET\tRUN\tMANIFEST")
 	for _, j := range jobs {
 		started := formatOptionalTime(j.StartedAt)
 		ended := formatOptionalTime(j.EndedAt)
@@ -133,10 +138,11 @@ func runIndexJobsList(cmd *cobra.Command, _ []string) error {
 			manifest = "-"
 		}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			shortJobID(j.JobID),
 			name,
 			j.State,
+			formatQueuePosition(positions[j.JobID], j.Queue),
 			started,
 			ended,
 			shortIndexSetID(indexSet),
@@ -182,6 +188,14 @@ func runIndexJobsStatus(cmd *cobra.Command, args []string) error {
 		_, _ = fmt.Fprintf(os.Stdout, "name=%s\n", rec.Name)
 	}
 	_, _ = fmt.Fprintf(os.Stdout, "state=%s\n", rec.State)
+	if rec.Queue != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "queue_priority=%s\n", rec.Queue.Priority)
+		if rec.AwaitingAdmission() {
+			if jobs, err := store.List(); err == nil {
+				_, _ = fmt.Fprintf(os.Stdout, "queue_position=%d\n", jobregistry.QueuePositions(jobs)[rec.JobID])
+			}
+		}
+	}
 	_, _ = fmt.Fprintf(os.Stdout, "manifest_path=%s\n", rec.ManifestPath)
 	if rec.IndexDir != "" {
 		_, _ = fmt.Fprintf(os.Stdout, "index_dir=%s\n", rec.IndexDir)
@@ -202,6 +216,22 @@ func runIndexJobsStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// indexJobListItem is a listed job plus its queue position while it waits for
+// admission.
+type indexJobListItem struct {
+	jobregistry.JobRecord
+	QueuePosition int `json:"queue_position,omitempty"`
+}
+
+// formatQueuePosition renders a waiting job's queue position and priority
+// class, such as "#2 high".
+func formatQueuePosition(position int, entry *jobregistry.JobQueueEntry) string {
+	if position == 0 || entry == nil {
+		return "-"
+	}
+	return fmt.Sprintf("#%d %s", position, entry.Priority)
+}
+
 func shortJobID(jobID string) string {
 	jobID = strings.TrimSpace(jobID)
 	if len(jobID) <= 12 {
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+// jobsHooksConfig is the application config key holding the job lifecycle
+// hooks. Every process that moves a job between states (index build
+// --background, the managed child, serve) delivers hooks from the same list.
+const jobsHooksConfig = "jobs.hooks"
+
+// jobHookConfigEntry is one entry under jobs.hooks.
+type jobHookConfigEntry struct {
+	Name        string   `mapstructure:"name"`
+	Events      []string `mapstructure:"events"`
+	URL         string   `mapstructure:"url"`
+	SecretEnv   string   `mapstructure:"secret_env"`
+	Command     []string `mapstructure:"command"`
+	MaxAttempts int      `mapstructure:"max_attempts"`
+	Timeout     string   `mapstructure:"timeout"`
+}
+
+// resolveJobHooks reads the job lifecycle hooks from application config. It
+// returns nil when none are configured. Webhook secrets are named by
+// secret_env and read from the environment so they never land in config or
+// job records.
+func resolveJobHooks() (*jobregistry.Hooks, error) {
+	if !viper.IsSet(jobsHooksConfig) {
+		return nil, nil
+	}
+	var entries []jobHookConfigEntry
+	if err := viper.UnmarshalKey(jobsHooksConfig, &entries); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", jobsHooksConfig, err)
+	}
+	configs := make([]jobregistry.HookConfig, 0, len(entries))
+	for i, e := range entries {
+		c := jobregistry.HookConfig{
+			Name:        strings.TrimSpace(e.Name),
+			URL:         strings.TrimSpace(e.URL),
+			Command:     e.Command,
+			MaxAttempts: e.MaxAttempts,
+		}
+		for _, event := range e.Events {
+			c.Events = append(c.Events, strings.ToLower(strings.TrimSpace(event)))
+		}
+		if raw := strings.TrimSpace(e.Timeout); raw != "" {
+			timeout, err := time.ParseDuration(raw)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid config %s[%d].timeout: %q (want a duration such as 30s)", jobsHooksConfig, i, raw)
+			}
+			c.Timeout = timeout
+		}
+		if env := strings.TrimSpace(e.SecretEnv); env != "" {
+			secret := os.Getenv(env)
+			if secret == "" {
+				return nil, fmt.Errorf("invalid config %s[%d]: secret_env %s is not set", jobsHooksConfig, i, env)
+			}
+			c.Secret = secret
+		}
+		configs = append(configs, c)
+	}
+	hooks, err := jobregistry.NewHooks(configs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", jobsHooksConfig, err)
+	}
+	return hooks, nil
+}
+
+// managedJobHooks delivers a managed child's running and terminal events.
+// The running event is delivered in the background so a slow receiver does
+// not delay the build; the terminal event waits for it so receivers see the
+// events in order.
+type managedJobHooks struct {
+	hooks   *jobregistry.Hooks
+	store   *jobregistry.Store
+	running sync.WaitGroup
+}
+
+// indexBuildManagedHooks is set once the managed child claims its job; a
+// child that never claimed its job delivers no events.
+var indexBuildManagedHooks *managedJobHooks
+
+// startManagedJobHooks delivers the running event for a just-claimed job.
+func startManagedJobHooks(store *jobregistry.Store, job *jobregistry.JobRecord) *managedJobHooks {
+	hooks, err := resolveJobHooks()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: job hooks disabled: %v\n", err)
+		return nil
+	}
+	if hooks == nil {
+		return nil
+	}
+	m := &managedJobHooks{hooks: hooks, store: store}
+	running := *job
+	m.running.Add(1)
+	go func() {
+		defer m.running.Done()
+		if err := hooks.Notify(store, &running, jobregistry.HookEventRunning, ""); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}()
+	return m
+}
+
+// finish delivers the terminal event for the job's persisted state. buildErr
+// is the raw build error; detail is the sanitized message sent to receivers.
+func (m *managedJobHooks) finish(jobID string, buildErr error, detail string) {
+	if m == nil {
+		return
+	}
+	m.running.Wait()
+	rec, err := m.store.Get(jobID)
+	if err != nil {
+		return
+	}
+	event := managedJobTerminalEvent(rec.State, buildErr)
+	if event == "" {
+		return
+	}
+	if err := m.hooks.Notify(m.store, rec, event, detail); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+// managedJobTerminalEvent maps a managed job's terminal state to its hook
+// event. A build that stopped with a resumable checkpoint is recorded as
+// partial but reported as failed_resumable.
+func managedJobTerminalEvent(state jobregistry.JobState, buildErr error) string {
+	switch {
+	case errors.Is(buildErr, errIndexBuildFailedResumable):
+		return jobregistry.HookEventFailedResumable
+	case state == jobregistry.JobStateSuccess:
+		return jobregistry.HookEventSucceeded
+	case state == jobregistry.JobStatePartial:
+		return jobregistry.HookEventPartial
+	case state == jobregistry.JobStateFailed, state == jobregistry.JobStateStopping, state == jobregistry.JobStateStopped, state == jobregistry.JobStateUnknown:
+		return jobregistry.HookEventFailed
+	default:
+		return ""
+	}
+}
+
+// queuedJobHookGrace bounds how long a CLI submission waits, after printing the
+// job id, for its queued event to finish delivering before the process exits.
+var queuedJobHookGrace = 5 * time.Second
+
+// queuedJobHooks delivers the queued event for jobs a CLI submission enqueues.
+// Like the server's notifier, delivery runs in the background so a slow or
+// down receiver holds neither the submission nor admission.
+type queuedJobHooks struct {
+	hooks   *jobregistry.Hooks
+	store   *jobregistry.Store
+	pending sync.WaitGroup
+	mu      sync.Mutex
+	jobIDs  []string
+}
+
+// newQueuedJobHooks returns nil when no hooks are configured.
+func newQueuedJobHooks(hooks *jobregistry.Hooks, store *jobregistry.Store) *queuedJobHooks {
+	if hooks == nil {
+		return nil
+	}
+	return &queuedJobHooks{hooks: hooks, store: store}
+}
+
+// onEnqueued returns the Executor.OnEnqueued callback, or nil for q == nil.
+func (q *queuedJobHooks) onEnqueued() func(*jobregistry.JobRecord) {
+	if q == nil {
+		return nil
+	}
+	return func(rec *jobregistry.JobRecord) {
+		q.mu.Lock()
+		q.jobIDs = append(q.jobIDs, rec.JobID)
+		q.mu.Unlock()
+		q.pending.Add(1)
+		go func() {
+			defer q.pending.Done()
+			if err := q.hooks.Notify(q.store, rec, jobregistry.HookEventQueued, ""); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}()
+	}
+}
+
+// wait gives in-flight deliveries up to grace to finish and reports whether
+// they did. A delivery cut off when the CLI exits stays recorded as pending on
+// the job, where `index jobs hooks --replay` can resend it.
+func (q *queuedJobHooks) wait(grace time.Duration) bool {
+	if q == nil {
+		return true
+	}
+	done := make(chan struct{})
+	go func() {
+		q.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(grace):
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for _, jobID := range q.jobIDs {
+			_, _ = fmt.Fprintf(os.Stderr, "warning: queued hook delivery still in progress; see `gonimbus index jobs hooks %s`\n", jobID)
+		}
+		return false
+	}
+}
+
+var indexJobsHooksCmd = &cobra.Command{
+	Use:   "hooks <job_id>",
+	Short: "Show or replay a job's hook deliveries",
+	Long: `List the lifecycle events delivered to the configured jobs.hooks for one
+managed job, with each delivery's status and attempts.
+
+--replay re-sends a recorded delivery's payload to its hook under the same
+delivery ID, so receivers can deduplicate it. The hook must still be
+configured.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIndexJobsHooks,
+}
+
+func init() {
+	indexJobsCmd.AddCommand(indexJobsHooksCmd)
+	indexJobsHooksCmd.Flags().String("replay", "", "Re-send the delivery with this delivery ID")
+	indexJobsHooksCmd.Flags().Bool("json", false, "Output as JSON")
+}
+
+func runIndexJobsHooks(cmd *cobra.Command, args []string) error {
+	jobID := strings.TrimSpace(args[0])
+	if jobID == "" {
+		return fmt.Errorf("job_id is required")
+	}
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	replayID, _ := cmd.Flags().GetString("replay")
+
+	root, err := indexJobsRootDir()
+	if err != nil {
+		return err
+	}
+	store := jobregistry.NewStore(root)
+	resolvedID, err := resolveJobID(store, jobID)
+	if err != nil {
+		return err
+	}
+
+	if replayID = strings.TrimSpace(replayID); replayID != "" {
+		hooks, err := resolveJobHooks()
+		if err != nil {
+			return err
+		}
+		d, err := hooks.Replay(store, resolvedID, replayID)
+		if d != nil {
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if encErr := enc.Encode(d); encErr != nil {
+					return encErr
+				}
+			} else {
+				_, _ = fmt.Fprintf(os.Stdout, "delivery_id=%s hook=%s event=%s status=%s attempts=%d\n", d.DeliveryID, d.Hook, d.Event, d.Status, d.Attempts)
+			}
+		}
+		return err
+	}
+
+	rec, err := store.Get(resolvedID)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		deliveries := rec.HookDeliveries
+		if deliveries == nil {
+			deliveries = []jobregistry.HookDelivery{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(deliveries)
+	}
+	if len(rec.HookDeliveries) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "No hook deliveries")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DELIVERY ID\tHOOK\tEVENT\tSTATUS\tATTEMPTS\tUPDATED\tERROR")
+	for _, d := range rec.HookDeliveries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n", d.DeliveryID, d.Hook, d.Event, d.Status, d.Attempts, d.UpdatedAt.Format(time.RFC3339), d.LastError)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+func setJobHooksConfig(t *testing.T, hooks []map[string]any) {
+	t.Helper()
+	viper.Set(jobsHooksConfig, hooks)
+	t.Cleanup(func() { viper.Set(jobsHooksConfig, nil) })
+}
+
+func TestResolveJobHooks_UnsetIsNil(t *testing.T) {
+	hooks, err := resolveJobHooks()
+	require.NoError(t, err)
+	require.Nil(t, hooks)
+}
+
+func TestResolveJobHooks_ReadsConfig(t *testing.T) {
+	t.Setenv("TEST_GONIMBUS_HOOK_SECRET", "s3cret")
+	setJobHooksConfig(t, []map[string]any{
+		{"name": "downstream", "url": "https://hooks.example.com/gonimbus", "secret_env": "TEST_GONIMBUS_HOOK_SECRET", "events": []string{"Succeeded", "failed"}, "timeout": "10s"},
+		{"name": "local", "command": []string{"/usr/local/bin/on-index"}, "max_attempts": 1},
+	})
+	hooks, err := resolveJobHooks()
+	require.NoError(t, err)
+	require.NotNil(t, hooks)
+}
+
+func TestResolveJobHooks_Refusals(t *testing.T) {
+	for name, entry := range map[string]map[string]any{
+		"missing secret": {"name": "h", "url": "https://hooks.example.com", "secret_env": "TEST_GONIMBUS_HOOK_SECRET_UNSET"},
+		"bad timeout":    {"name": "h", "url": "https://hooks.example.com", "timeout": "soon"},
+		"bad event":      {"name": "h", "url": "https://hooks.example.com", "events": []string{"finished"}},
+		"no target":      {"name": "h"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			setJobHooksConfig(t, []map[string]any{entry})
+			_, err := resolveJobHooks()
+			require.ErrorContains(t, err, jobsHooksConfig)
+		})
+	}
+}
+
+func TestManagedJobTerminalEvent(t *testing.T) {
+	resumable := fmt.Errorf("%w: %w", errIndexBuildFailedResumable, fmt.Errorf("throttled"))
+	require.Equal(t, "index build failed resumable: throttled", resumable.Error())
+
+	require.Equal(t, jobregistry.HookEventFailedResumable, managedJobTerminalEvent(jobregistry.JobStatePartial, resumable))
+	require.Equal(t, jobregistry.HookEventSucceeded, managedJobTerminalEvent(jobregistry.JobStateSuccess, nil))
+	require.Equal(t, jobregistry.HookEventPartial, managedJobTerminalEvent(jobregistry.JobStatePartial, nil))
+	require.Equal(t, jobregistry.HookEventFailed, managedJobTerminalEvent(jobregistry.JobStateFailed, fmt.Errorf("boom")))
+	require.Equal(t, jobregistry.HookEventFailed, managedJobTerminalEvent(jobregistry.JobStateStopped, nil))
+	require.Empty(t, managedJobTerminalEvent(jobregistry.JobStateRunning, nil))
+}
+
+func TestQueuedJobHooksDeliverWithoutBlockingSubmission(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	hooks, err := jobregistry.NewHooks([]jobregistry.HookConfig{{Name: "slow", URL: srv.URL, MaxAttempts: 1}})
+	require.NoError(t, err)
+
+	store := jobregistry.NewStore(filepath.Join(t.TempDir(), "jobs"))
+	rec := &jobregistry.JobRecord{
+		JobID:     "88888888-8888-4888-8888-888888888888",
+		Type:      jobregistry.JobTypeIndexBuild,
+		State:     jobregistry.JobStateQueued,
+		CreatedAt: time.Now().UTC(),
+	}
+	require.NoError(t, store.Write(rec))
+
+	q := newQueuedJobHooks(hooks, store)
+	returned := make(chan struct{})
+	go func() {
+		q.onEnqueued()(rec)
+		close(returned)
+	}()
+	select {
+	case <-returned:
+	case <-time.After(2 * time.Second):
+		t.Fatal("the queued callback must not wait on the receiver")
+	}
+	require.False(t, q.wait(20*time.Millisecond), "delivery is still held by the receiver")
+
+	close(release)
+	require.True(t, q.wait(5*time.Second))
+	got, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.Len(t, got.HookDeliveries, 1)
+	require.Equal(t, jobregistry.HookEventQueued, got.HookDeliveries[0].Event)
+	require.Equal(t, jobregistry.HookDeliveryDelivered, got.HookDeliveries[0].Status)
+
+	var nilHooks *queuedJobHooks
+	require.Nil(t, nilHooks.onEnqueued())
+	require.True(t, nilHooks.wait(0))
+}
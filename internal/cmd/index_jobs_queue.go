@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/match"
+)
+
+// Operator surfaces for the managed job queue limits. Precedence is
+// environment > application config; unset limits are unlimited. Every process
+// that admits jobs from a jobs root (index build --background, a finishing
+// managed job, serve) should see the same limits.
+const (
+	jobsQueueMaxRunningEnv        = "GONIMBUS_JOBS_MAX_RUNNING"
+	jobsQueueMaxRunningConfig     = "jobs.queue.max_running"
+	jobsQueueMaxPerBucketEnv      = "GONIMBUS_JOBS_MAX_PER_BUCKET"
+	jobsQueueMaxPerBucketConfig   = "jobs.queue.max_per_bucket"
+	jobsQueueMaxPerEndpointEnv    = "GONIMBUS_JOBS_MAX_PER_ENDPOINT"
+	jobsQueueMaxPerEndpointConfig = "jobs.queue.max_per_endpoint"
+	jobsQueueMemoryBudgetEnv      = "GONIMBUS_JOBS_MEMORY_BUDGET"
+	jobsQueueMemoryBudgetConfig   = "jobs.queue.memory_budget"
+)
+
+// resolveJobQueueLimits reads the queue limits from the environment and
+// application config.
+func resolveJobQueueLimits() (jobregistry.QueueLimits, error) {
+	var limits jobregistry.QueueLimits
+	counts := []struct {
+		env, config string
+		dst         *int
+	}{
+		{jobsQueueMaxRunningEnv, jobsQueueMaxRunningConfig, &limits.MaxRunning},
+		{jobsQueueMaxPerBucketEnv, jobsQueueMaxPerBucketConfig, &limits.MaxPerBucket},
+		{jobsQueueMaxPerEndpointEnv, jobsQueueMaxPerEndpointConfig, &limits.MaxPerEndpoint},
+	}
+	for _, c := range counts {
+		raw, source, set := firstOperatorValue("", "", c.env, "env "+c.env, c.config, "config "+c.config)
+		if !set {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return jobregistry.QueueLimits{}, fmt.Errorf("invalid job queue limit from %s: %q (want a count >= 0; 0 is unlimited)", source, raw)
+		}
+		*c.dst = n
+	}
+	raw, source, set := firstOperatorValue("", "", jobsQueueMemoryBudgetEnv, "env "+jobsQueueMemoryBudgetEnv, jobsQueueMemoryBudgetConfig, "config "+jobsQueueMemoryBudgetConfig)
+	if set {
+		size, err := match.ParseSize(raw)
+		if err != nil || size < 0 {
+			return jobregistry.QueueLimits{}, fmt.Errorf("invalid job queue memory budget from %s: %q (want a size such as 32GiB)", source, raw)
+		}
+		limits.MemoryBytes = size
+	}
+	return limits, nil
+}
+
+// newIndexJobsExecutor returns an executor for the jobs root under the
+// configured queue limits.
+func newIndexJobsExecutor() (*jobregistry.Executor, error) {
+	root, err := indexJobsRootDir()
+	if err != nil {
+		return nil, err
+	}
+	limits, err := resolveJobQueueLimits()
+	if err != nil {
+		return nil, err
+	}
+	executor := jobregistry.NewExecutor(root)
+	executor.Limits = limits
+	return executor, nil
+}
+
+// admitQueuedIndexJobs admits the jobs waiting in the queue. A managed job
+// runs it as it exits so queued jobs progress without a server. It admits
+// nothing while a draining server holds admission on the jobs root.
+func admitQueuedIndexJobs() {
+	executor, err := newIndexJobsExecutor()
+	if err != nil {
+		return
+	}
+	_, _ = executor.AdmitQueued()
+}
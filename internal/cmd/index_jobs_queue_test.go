@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+func TestResolveJobQueueLimits_DefaultUnlimited(t *testing.T) {
+	limits, err := resolveJobQueueLimits()
+	require.NoError(t, err)
+	require.Equal(t, jobregistry.QueueLimits{}, limits)
+}
+
+func TestResolveJobQueueLimits_EnvOverridesConfig(t *testing.T) {
+	setSpillConfig(t, jobsQueueMaxRunningConfig, "4")
+	setSpillConfig(t, jobsQueueMaxPerBucketConfig, "1")
+	setSpillConfig(t, jobsQueueMemoryBudgetConfig, "32GiB")
+	t.Setenv(jobsQueueMaxRunningEnv, "2")
+
+	limits, err := resolveJobQueueLimits()
+	require.NoError(t, err)
+	require.Equal(t, jobregistry.QueueLimits{MaxRunning: 2, MaxPerBucket: 1, MemoryBytes: int64(32) << 30}, limits)
+}
+
+func TestResolveJobQueueLimits_Refusals(t *testing.T) {
+	for key, val := range map[string]string{
+		jobsQueueMaxRunningEnv:     "-1",
+		jobsQueueMaxPerEndpointEnv: "many",
+		jobsQueueMemoryBudgetEnv:   "a lot",
+	} {
+		t.Run(key, func(t *testing.T) {
+			t.Setenv(key, val)
+			_, err := resolveJobQueueLimits()
+			require.ErrorContains(t, err, key)
+		})
+	}
+}
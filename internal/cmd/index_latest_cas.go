@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/3leaps/gonimbus/internal/signing"
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/provider"
 	"github.com/spf13/cobra"
@@ -34,6 +36,8 @@ type latestPointerOptions struct {
 	RetryMax  int
 	RetryBase time.Duration
 	Events    io.Writer
+	// SignKey, when set, signs the pointer document inline.
+	SignKey ed25519.PrivateKey
 }
 
 type latestPointerOutcome string
@@ -50,6 +54,9 @@ type latestPointerDoc struct {
 	RunID      string `json:"run_id"`
 	UpdatedAt  string `json:"updated_at"`
 	UpdatedBy  string `json:"updated_by"`
+	// Signature is present on pointers written with a signing key; it covers
+	// the rest of the document.
+	Signature *signing.Signature `json:"signature,omitempty"`
 }
 
 type hubCompleteDoc struct {
@@ -90,6 +97,11 @@ func latestPointerOptionsFromCommand(cmd *cobra.Command) (latestPointerOptions,
 	opts.RetryMax, _ = cmd.Flags().GetInt("latest-retry-max")
 	opts.RetryBase, _ = cmd.Flags().GetDuration("latest-retry-base")
 	opts.Events = cmd.ErrOrStderr()
+	key, err := hubSignKeyFromCommand(cmd)
+	if err != nil {
+		return opts, err
+	}
+	opts.SignKey = key
 	return normalizeLatestPointerOptions(opts)
 }
 
@@ -121,7 +133,7 @@ func advanceLatestPointer(ctx context.Context, hub *hubDestSpec, getter provider
 	}
 
 	latestKey := hubArtifactKey(hub, "index-sets", indexSetID, "latest.json")
-	latestJSON, err := buildLatestJSONForRun(indexSetID, runID)
+	latestJSON, err := buildLatestPointerJSON(indexSetID, runID, opts.SignKey)
 	if err != nil {
 		return "", err
 	}
@@ -215,6 +227,11 @@ func advanceLatestPointer(ctx context.Context, hub *hubDestSpec, getter provider
 }
 
 func buildLatestJSONForRun(indexSetID, runID string) ([]byte, error) {
+	return buildLatestPointerJSON(indexSetID, runID, nil)
+}
+
+// buildLatestPointerJSON renders a latest.json pointer, signed when key is set.
+func buildLatestPointerJSON(indexSetID, runID string, key ed25519.PrivateKey) ([]byte, error) {
 	doc := latestPointerDoc{
 		Version:    "1.0",
 		IndexSetID: indexSetID,
@@ -222,6 +239,11 @@ func buildLatestJSONForRun(indexSetID, runID string) ([]byte, error) {
 		UpdatedAt:  time.Now().UTC().Format(time.RFC3339),
 		UpdatedBy:  exportedByString(),
 	}
+	if key != nil {
+		if err := signLatestPointer(&doc, key); err != nil {
+			return nil, fmt.Errorf("sign latest.json: %w", err)
+		}
+	}
 	return json.MarshalIndent(doc, "", "  ")
 }
 
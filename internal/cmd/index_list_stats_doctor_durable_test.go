@@ -13,6 +13,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
 	"github.com/3leaps/gonimbus/pkg/indexreader"
 	"github.com/3leaps/gonimbus/pkg/provider"
@@ -499,7 +500,7 @@ func TestIndexBuildExplicitExternalSQLiteRetainsCallerOwnedBehavior(t *testing.T
 	require.NoFileExists(t, filepath.Join(externalDir, "identity.json"))
 }
 
-func TestIndexStatsDurableAndPrefixesRejected(t *testing.T) {
+func TestIndexStatsDurableWithPrefixes(t *testing.T) {
 	resetAppDataRootTestState(t)
 	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
 	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
@@ -545,9 +546,94 @@ func TestIndexStatsDurableAndPrefixesRejected(t *testing.T) {
 	_, hasPublished := latest["published_at"]
 	require.True(t, hasPublished || latest["run_id"] != "", "durable latest must carry run id")
 
+	runs := doc["runs"].(map[string]any)
+	require.EqualValues(t, 1, runs["successful"])
+
 	require.NoError(t, statsCmd.Flags().Set("prefixes", "true"))
-	err := runIndexStats(statsCmd, []string{"s3://bucket/data/"})
-	require.ErrorContains(t, err, "--prefixes is not supported on durable-v2")
+	stdout = captureStdout(t, func() {
+		require.NoError(t, runIndexStats(statsCmd, []string{"s3://bucket/data/"}))
+	})
+	doc = map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &doc))
+	require.Equal(t, true, doc["prefixes_available"])
+	prefixes := doc["prefixes"].([]any)
+	require.Len(t, prefixes, 2)
+	root := prefixes[0].(map[string]any)
+	require.Equal(t, "", root["prefix"])
+	require.EqualValues(t, 0, root["objects_direct"])
+	require.EqualValues(t, 2, root["objects_recursive"])
+	require.EqualValues(t, 30, root["bytes_recursive"])
+	require.EqualValues(t, 1, root["common_prefixes"])
+	hot := prefixes[1].(map[string]any)
+	require.Equal(t, "hot/", hot["prefix"])
+	require.EqualValues(t, 1, hot["depth"])
+	require.EqualValues(t, 2, hot["objects_direct"])
+	require.EqualValues(t, 30, hot["bytes_direct"])
+}
+
+func TestIndexStatsDurableRunsListsLedgerFailures(t *testing.T) {
+	resetAppDataRootTestState(t)
+	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
+	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
+	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	manifestPath := writeScopedPrefixManifest(t, []string{"hot/"})
+
+	oldSource := newIndexBuildEngineSource
+	newIndexBuildEngineSource = func(context.Context, *uri.ObjectURI, providerdispatch.SourceOptions) (provider.Provider, error) {
+		return indexBuildEngineFakeProvider{objects: []provider.ObjectSummary{
+			{Key: "data/hot/a.xml", Size: 10, ETag: `"a"`, LastModified: base},
+		}}, nil
+	}
+	t.Cleanup(func() { newIndexBuildEngineSource = oldSource })
+
+	restore := withIndexBuildExperimentalEngineTestState(t)
+	restore()
+	indexBuildJobPath = manifestPath
+	indexBuildFormat = "durable"
+	require.NoError(t, runIndexBuild(&cobra.Command{Use: "build"}, nil))
+
+	reader, err := openIndexReader(context.Background(), "s3://bucket/data/", "", "")
+	require.NoError(t, err)
+	segmentRoot := filepath.Dir(reader.Meta().SourcePath)
+	indexSetID := reader.Meta().IndexSetID
+	require.NoError(t, reader.Close())
+	ended := base.Add(time.Hour)
+	require.NoError(t, indexsubstrate.WriteRunLedgerEntry(segmentRoot, indexsubstrate.RunLedgerEntry{
+		IndexSetID: indexSetID, RunID: "run_9000000000000000001", Operation: "build",
+		Status: indexsubstrate.RunLedgerFailedResumable, StartedAt: base, EndedAt: &ended,
+		SealedJournals: 2, Reason: "publish failed",
+	}))
+	require.NoError(t, indexsubstrate.WriteRunLedgerEntry(segmentRoot, indexsubstrate.RunLedgerEntry{
+		IndexSetID: indexSetID, RunID: "run_9000000000000000002", Operation: "build",
+		Status: indexsubstrate.RunLedgerAborted, StartedAt: base, EndedAt: &ended,
+	}))
+
+	statsCmd := &cobra.Command{Use: "stats"}
+	statsCmd.Flags().Bool("json", false, "")
+	statsCmd.Flags().Bool("prefixes", false, "")
+	statsCmd.Flags().Bool("runs", false, "")
+	require.NoError(t, statsCmd.Flags().Set("json", "true"))
+	require.NoError(t, statsCmd.Flags().Set("runs", "true"))
+	statsCmd.SetContext(context.Background())
+	stdout := captureStdout(t, func() {
+		require.NoError(t, runIndexStats(statsCmd, []string{"s3://bucket/data/"}))
+	})
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &doc))
+	runs := doc["runs"].(map[string]any)
+	require.EqualValues(t, 3, runs["total"])
+	require.EqualValues(t, 1, runs["successful"])
+	require.EqualValues(t, 1, runs["failed_resumable"])
+	require.EqualValues(t, 1, runs["aborted"])
+	require.EqualValues(t, 1, doc["published_runs"].(map[string]any)["total"])
+
+	statuses := map[string]string{}
+	for _, item := range doc["publication_history"].([]any) {
+		r := item.(map[string]any)
+		statuses[r["run_id"].(string)] = r["status"].(string)
+	}
+	require.Equal(t, "failed-resumable", statuses["run_9000000000000000001"])
+	require.Equal(t, "aborted", statuses["run_9000000000000000002"])
 }
 
 func TestIndexDoctorDurableOnly(t *testing.T) {
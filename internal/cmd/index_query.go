@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/3leaps/gonimbus/internal/indexquery"
 	"github.com/3leaps/gonimbus/pkg/indexreader"
 	"github.com/3leaps/gonimbus/pkg/indexstore"
 	"github.com/3leaps/gonimbus/pkg/match"
@@ -67,12 +70,20 @@ Examples:
   # Pin a durable snapshot run (bypasses latest.json; requires --index-set)
   gonimbus index query --index-set idx_da038d8171b4a9ba --run-id run_1783087200000000000 --pattern "**/*.xml"
 
+  # Query the retained durable run that was current at a point in time
+  gonimbus index query --index-set idx_da038d8171b4a9ba --as-of 2026-05-01 --pattern "**/*.xml"
+
   # Emit one canonical object per non-empty ETag group
   gonimbus index query s3://bucket/prefix/ --canonical-by-etag
 
   # Emit current objects first seen or changed after a completed run
   gonimbus index query --index-set idx_da038d8171b4a9ba --since-run run_1783087200000000000
 
+  # Look up specific keys (one per line; "-" reads stdin). Durable indexes
+  # seek straight to candidate segments instead of scanning.
+  gonimbus index query s3://bucket/prefix/ --keys-from keys.txt
+  printf 'a/1.xml\nb/2.xml\n' | gonimbus index query s3://bucket/prefix/ --keys-from - --count
+
   ETag caveat: ETag is a provider version/fingerprint hint, not a universal
   content hash. See docs/user-guide/index-build-mental-model.md.`,
 	Args: cobra.MaximumNArgs(1),
@@ -104,10 +115,12 @@ func init() {
 	indexQueryCmd.Flags().String("canonical-tie-break", string(indexstore.CanonicalTieBreakMinKey), "Canonical selection rule for --canonical-by-etag: min-key, min-modified, max-modified")
 	indexQueryCmd.Flags().Bool("include-alternates", false, "Populate alternates[] on canonical ETag records")
 	indexQueryCmd.Flags().String("since-run", "", "Only emit current objects first seen or changed after this successful run")
+	indexQueryCmd.Flags().String("keys-from", "", "Look up the keys listed in this file, one per line (- for stdin); full URIs under the base are accepted")
 
 	// Index selection
 	indexQueryCmd.Flags().String("index-set", "", "Explicit index set ID (e.g., idx_da038d8171b4a9ba); skips auto-selection")
 	indexQueryCmd.Flags().String("run-id", "", "Pin durable snapshot run (requires --index-set; bypasses latest.json)")
+	indexQueryCmd.Flags().String("as-of", "", "Query the retained durable run published at or before this time (YYYY-MM-DD or RFC3339), or a retained run ID")
 
 	// Output destination
 	indexQueryCmd.Flags().String("output", "", "Output destination URI (s3://bucket/key.jsonl or file:///path/file.jsonl)")
@@ -117,84 +130,10 @@ func init() {
 }
 
 // indexQueryRecord is the JSONL output format for query results.
-type indexQueryRecord struct {
-	Type string               `json:"type"`
-	TS   string               `json:"ts"`
-	Data indexQueryRecordData `json:"data"`
-}
-
-type indexQueryRecordData struct {
-	BaseURI          string  `json:"base_uri"`
-	RelKey           string  `json:"rel_key"`
-	Key              string  `json:"key"`
-	SizeBytes        int64   `json:"size_bytes"`
-	LastModified     *string `json:"last_modified,omitempty"`
-	ETag             string  `json:"etag,omitempty"`
-	StorageClass     *string `json:"storage_class,omitempty"`
-	ArchiveStatus    *string `json:"archive_status,omitempty"`
-	RestoreState     *string `json:"restore_state,omitempty"`
-	RestoreExpiry    *string `json:"restore_expiry,omitempty"`
-	ContentType      *string `json:"content_type,omitempty"`
-	HeadEnrichedAt   *string `json:"head_enriched_at,omitempty"`
-	FirstSeenRunID   string  `json:"first_seen_run_id,omitempty"`
-	FirstSeenAt      *string `json:"first_seen_at,omitempty"`
-	LastChangedRunID string  `json:"last_changed_run_id,omitempty"`
-	LastChangedAt    *string `json:"last_changed_at,omitempty"`
-	ChangeKind       string  `json:"change_kind,omitempty"`
-	DeletedAt        *string `json:"deleted_at,omitempty"`
-}
-
-type indexCanonicalQueryRecord struct {
-	Type string                        `json:"type"`
-	TS   string                        `json:"ts"`
-	Data indexCanonicalQueryRecordData `json:"data"`
-}
-
-type indexCanonicalQueryRecordData struct {
-	BaseURI         string                        `json:"base_uri"`
-	ETag            string                        `json:"etag"`
-	Canonical       indexCanonicalObjectData      `json:"canonical"`
-	TieBreakRule    string                        `json:"tie_break_rule"`
-	AlternatesCount int                           `json:"alternates_count"`
-	Alternates      []indexCanonicalAlternateData `json:"alternates,omitempty"`
-}
-
-type indexCanonicalObjectData struct {
-	RelKey           string  `json:"rel_key"`
-	Key              string  `json:"key"`
-	SizeBytes        int64   `json:"size_bytes"`
-	LastModified     *string `json:"last_modified,omitempty"`
-	StorageClass     *string `json:"storage_class,omitempty"`
-	ArchiveStatus    *string `json:"archive_status,omitempty"`
-	RestoreState     *string `json:"restore_state,omitempty"`
-	RestoreExpiry    *string `json:"restore_expiry,omitempty"`
-	ContentType      *string `json:"content_type,omitempty"`
-	HeadEnrichedAt   *string `json:"head_enriched_at,omitempty"`
-	FirstSeenRunID   string  `json:"first_seen_run_id,omitempty"`
-	FirstSeenAt      *string `json:"first_seen_at,omitempty"`
-	LastChangedRunID string  `json:"last_changed_run_id,omitempty"`
-	LastChangedAt    *string `json:"last_changed_at,omitempty"`
-	ChangeKind       string  `json:"change_kind,omitempty"`
-	DeletedAt        *string `json:"deleted_at"`
-}
-
-type indexCanonicalAlternateData struct {
-	RelKey           string  `json:"rel_key"`
-	SizeBytes        int64   `json:"size_bytes"`
-	LastModified     *string `json:"last_modified,omitempty"`
-	StorageClass     *string `json:"storage_class,omitempty"`
-	ArchiveStatus    *string `json:"archive_status,omitempty"`
-	RestoreState     *string `json:"restore_state,omitempty"`
-	RestoreExpiry    *string `json:"restore_expiry,omitempty"`
-	ContentType      *string `json:"content_type,omitempty"`
-	HeadEnrichedAt   *string `json:"head_enriched_at,omitempty"`
-	FirstSeenRunID   string  `json:"first_seen_run_id,omitempty"`
-	FirstSeenAt      *string `json:"first_seen_at,omitempty"`
-	LastChangedRunID string  `json:"last_changed_run_id,omitempty"`
-	LastChangedAt    *string `json:"last_changed_at,omitempty"`
-	ChangeKind       string  `json:"change_kind,omitempty"`
-	DeletedAt        *string `json:"deleted_at"`
-}
+type (
+	indexQueryRecord          = indexquery.ObjectRecord
+	indexCanonicalQueryRecord = indexquery.CanonicalRecord
+)
 
 func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 	ctx := cmd.Context()
@@ -241,6 +180,22 @@ func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 	if runIDFlag != "" && strings.TrimSpace(sinceRun) != "" {
 		return fmt.Errorf("--run-id and --since-run are distinct selectors; do not combine them")
 	}
+	asOf, _ := cmd.Flags().GetString("as-of")
+	asOf = strings.TrimSpace(asOf)
+	if asOf != "" && runIDFlag != "" {
+		return fmt.Errorf("--as-of and --run-id both select a run; do not combine them")
+	}
+	if asOf != "" && strings.TrimSpace(sinceRun) != "" {
+		return fmt.Errorf("--as-of and --since-run are distinct selectors; do not combine them")
+	}
+	keysFrom, _ := cmd.Flags().GetString("keys-from")
+	if keysFrom != "" {
+		for _, name := range indexQueryKeysFromConflicts {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--keys-from looks up exact keys; do not combine it with --%s", name)
+			}
+		}
+	}
 
 	// Format-aware read seam: sqlite-v1 or durable-v2 (pinned run forces durable-v2).
 	reader, err := openIndexReader(ctx, baseURI, indexSetFlag, runIDFlag)
@@ -265,6 +220,52 @@ func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 	if err := warnIfReaderLatestRunFailedResumable(ctx, reader); err != nil {
 		return err
 	}
+	// --as-of resolves against the retained runs, then reopens pinned to the
+	// chosen one; the latest reader is only needed to find the set.
+	if asOf != "" {
+		runs, err := listRetainedDurableRuns(meta)
+		if err != nil {
+			return err
+		}
+		run, err := resolveDurableAsOf(runs, meta.RunID, asOf)
+		if err != nil {
+			return err
+		}
+		noteAsOfRun(asOf, run)
+		if run.RunID != meta.RunID {
+			pinned, err := openIndexReader(ctx, "", meta.IndexSetID, run.RunID)
+			if err != nil {
+				return err
+			}
+			if closeErr := reader.Close(); closeErr != nil {
+				_ = pinned.Close()
+				return closeErr
+			}
+			reader = pinned
+			meta = reader.Meta()
+		}
+	}
+	if meta.Selector != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "note: index set was built filtered (%s); objects outside the selector are not indexed\n", meta.Selector)
+	}
+
+	// --keys-from resolves its rows up front: a point lookup reads only the
+	// candidate segments, so there is nothing to stream incrementally.
+	var (
+		lookupKeys    []string
+		lookupResults []indexstore.QueryResult
+		lookupStats   indexstore.QueryStats
+	)
+	if keysFrom != "" {
+		lookupKeys, err = readIndexQueryKeys(cmd, keysFrom, baseURI)
+		if err != nil {
+			return err
+		}
+		lookupResults, lookupStats, err = reader.LookupKeys(ctx, lookupKeys)
+		if err != nil {
+			return fmt.Errorf("lookup failed: %w", err)
+		}
+	}
 
 	// Build query params
 	params := indexstore.QueryParams{
@@ -339,6 +340,10 @@ func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 		if limit > 0 {
 			_, _ = fmt.Fprintf(os.Stderr, "warning: --limit is ignored with --count (use without --count to limit output)\n")
 		}
+		if keysFrom != "" {
+			_, _ = fmt.Fprintf(os.Stdout, "%d\n", len(lookupResults))
+			return nil
+		}
 		// Clear limit for count - we want total matches
 		countParams := params
 		countParams.Limit = 0
@@ -410,6 +415,14 @@ func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 			}
 		}
 		matched = len(canonicalResults)
+	} else if keysFrom != "" {
+		stats = lookupStats
+		for _, r := range lookupResults {
+			if err := enc.Encode(newIndexQueryRecord(baseURI, now, r)); err != nil {
+				return fmt.Errorf("encode record: %w", err)
+			}
+			matched++
+		}
 	} else {
 		// Stream verified rows as they arrive (bounded memory on durable-v2).
 		// A later-segment failure may leave a verified prefix on the writer but
@@ -461,6 +474,8 @@ func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 	// Summary to stderr
 	if canonicalByETag {
 		_, _ = fmt.Fprintf(os.Stderr, "%d canonical groups, %d ungrouped empty-ETag rows, %d total records\n", canonicalStats.CanonicalGroups, canonicalStats.PassthroughRows, canonicalStats.TotalRecords)
+	} else if keysFrom != "" {
+		_, _ = fmt.Fprintf(os.Stderr, "Matched %d of %d keys\n", matched, lookupStats.KeysLookedUp)
 	} else {
 		_, _ = fmt.Fprintf(os.Stderr, "Matched %d objects\n", matched)
 	}
@@ -471,6 +486,49 @@ func runIndexQuery(cmd *cobra.Command, args []string) (err error) {
 	return nil
 }
 
+// indexQueryKeysFromConflicts are the filter and selector flags --keys-from
+// refuses: a lookup returns exactly the listed keys that are indexed.
+var indexQueryKeysFromConflicts = []string{
+	"pattern", "key-regex", "min-size", "max-size", "after", "before",
+	"storage-class", "enriched-after", "limit", "include-deleted",
+	"canonical-by-etag", "since-run",
+}
+
+// readIndexQueryKeys reads one key per line from path, or stdin for "-".
+// Lines are taken verbatim apart from a trailing CR, since object keys may
+// carry spaces; blank lines are skipped. A line that is a full URI under
+// baseURI is reduced to its relative key.
+func readIndexQueryKeys(cmd *cobra.Command, path, baseURI string) ([]string, error) {
+	var in io.Reader
+	if path == "-" {
+		in = cmd.InOrStdin()
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open --keys-from: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var keys []string
+	for scanner.Scan() {
+		key := strings.TrimSuffix(scanner.Text(), "\r")
+		if key == "" {
+			continue
+		}
+		if baseURI != "" && strings.HasPrefix(key, baseURI) {
+			key = strings.TrimPrefix(key, baseURI)
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read --keys-from: %w", err)
+	}
+	return keys, nil
+}
+
 func parseStorageClassFilterValues(raw []string) ([]string, error) {
 	if len(raw) == 0 {
 		return nil, nil
@@ -489,171 +547,11 @@ func parseStorageClassFilterValues(raw []string) ([]string, error) {
 }
 
 func newIndexQueryRecord(baseURI string, ts string, r indexstore.QueryResult) indexQueryRecord {
-	record := indexQueryRecord{
-		Type: "gonimbus.index.object.v1",
-		TS:   ts,
-		Data: indexQueryRecordData{
-			BaseURI:       baseURI,
-			RelKey:        r.RelKey,
-			Key:           reconstructFullKey(baseURI, r.RelKey),
-			SizeBytes:     r.SizeBytes,
-			ETag:          r.ETag,
-			StorageClass:  r.StorageClass,
-			ArchiveStatus: r.ArchiveStatus,
-			RestoreState:  r.RestoreState,
-			ContentType:   r.ContentType,
-		},
-	}
-
-	if r.LastModified != nil {
-		lastModified := r.LastModified.Format(time.RFC3339)
-		record.Data.LastModified = &lastModified
-	}
-	if r.DeletedAt != nil {
-		deletedAt := r.DeletedAt.Format(time.RFC3339)
-		record.Data.DeletedAt = &deletedAt
-	}
-	if r.RestoreExpiry != nil {
-		restoreExpiry := r.RestoreExpiry.Format(time.RFC3339)
-		record.Data.RestoreExpiry = &restoreExpiry
-	}
-	if r.HeadEnrichedAt != nil {
-		headEnrichedAt := r.HeadEnrichedAt.Format(time.RFC3339)
-		record.Data.HeadEnrichedAt = &headEnrichedAt
-	}
-	populateIndexQueryDeltaData(&record.Data, r)
-	return record
-}
-
-func populateIndexQueryDeltaData(data *indexQueryRecordData, r indexstore.QueryResult) {
-	if data == nil || r.ChangeKind == "" {
-		return
-	}
-	data.FirstSeenRunID = r.FirstSeenRunID
-	data.LastChangedRunID = r.LastChangedRunID
-	data.ChangeKind = r.ChangeKind
-	if r.FirstSeenAt != nil {
-		firstSeenAt := r.FirstSeenAt.Format(time.RFC3339)
-		data.FirstSeenAt = &firstSeenAt
-	}
-	if r.LastChangedAt != nil {
-		lastChangedAt := r.LastChangedAt.Format(time.RFC3339)
-		data.LastChangedAt = &lastChangedAt
-	}
+	return indexquery.NewObjectRecord(baseURI, ts, r)
 }
 
 func newIndexCanonicalQueryRecord(baseURI string, ts string, group indexstore.CanonicalObjectGroup, rule indexstore.CanonicalTieBreak, includeAlternates bool) indexCanonicalQueryRecord {
-	record := indexCanonicalQueryRecord{
-		Type: "gonimbus.index.object.canonical.v1",
-		TS:   ts,
-		Data: indexCanonicalQueryRecordData{
-			BaseURI:         baseURI,
-			ETag:            group.ETag,
-			Canonical:       newIndexCanonicalObjectData(baseURI, group.Canonical),
-			TieBreakRule:    string(rule),
-			AlternatesCount: len(group.Alternates),
-		},
-	}
-	if includeAlternates {
-		record.Data.Alternates = make([]indexCanonicalAlternateData, 0, len(group.Alternates))
-		for _, alternate := range group.Alternates {
-			record.Data.Alternates = append(record.Data.Alternates, newIndexCanonicalAlternateData(alternate))
-		}
-	}
-	return record
-}
-
-func newIndexCanonicalObjectData(baseURI string, r indexstore.QueryResult) indexCanonicalObjectData {
-	record := indexCanonicalObjectData{
-		RelKey:        r.RelKey,
-		Key:           reconstructFullKey(baseURI, r.RelKey),
-		SizeBytes:     r.SizeBytes,
-		StorageClass:  r.StorageClass,
-		ArchiveStatus: r.ArchiveStatus,
-		RestoreState:  r.RestoreState,
-		ContentType:   r.ContentType,
-	}
-	if r.LastModified != nil {
-		lastModified := r.LastModified.Format(time.RFC3339)
-		record.LastModified = &lastModified
-	}
-	if r.DeletedAt != nil {
-		deletedAt := r.DeletedAt.Format(time.RFC3339)
-		record.DeletedAt = &deletedAt
-	}
-	if r.RestoreExpiry != nil {
-		restoreExpiry := r.RestoreExpiry.Format(time.RFC3339)
-		record.RestoreExpiry = &restoreExpiry
-	}
-	if r.HeadEnrichedAt != nil {
-		headEnrichedAt := r.HeadEnrichedAt.Format(time.RFC3339)
-		record.HeadEnrichedAt = &headEnrichedAt
-	}
-	populateIndexCanonicalDeltaData(&record, r)
-	return record
-}
-
-func populateIndexCanonicalDeltaData(data *indexCanonicalObjectData, r indexstore.QueryResult) {
-	if data == nil || r.ChangeKind == "" {
-		return
-	}
-	data.FirstSeenRunID = r.FirstSeenRunID
-	data.LastChangedRunID = r.LastChangedRunID
-	data.ChangeKind = r.ChangeKind
-	if r.FirstSeenAt != nil {
-		firstSeenAt := r.FirstSeenAt.Format(time.RFC3339)
-		data.FirstSeenAt = &firstSeenAt
-	}
-	if r.LastChangedAt != nil {
-		lastChangedAt := r.LastChangedAt.Format(time.RFC3339)
-		data.LastChangedAt = &lastChangedAt
-	}
-}
-
-func newIndexCanonicalAlternateData(r indexstore.QueryResult) indexCanonicalAlternateData {
-	record := indexCanonicalAlternateData{
-		RelKey:        r.RelKey,
-		SizeBytes:     r.SizeBytes,
-		StorageClass:  r.StorageClass,
-		ArchiveStatus: r.ArchiveStatus,
-		RestoreState:  r.RestoreState,
-		ContentType:   r.ContentType,
-	}
-	if r.LastModified != nil {
-		lastModified := r.LastModified.Format(time.RFC3339)
-		record.LastModified = &lastModified
-	}
-	if r.DeletedAt != nil {
-		deletedAt := r.DeletedAt.Format(time.RFC3339)
-		record.DeletedAt = &deletedAt
-	}
-	if r.RestoreExpiry != nil {
-		restoreExpiry := r.RestoreExpiry.Format(time.RFC3339)
-		record.RestoreExpiry = &restoreExpiry
-	}
-	if r.HeadEnrichedAt != nil {
-		headEnrichedAt := r.HeadEnrichedAt.Format(time.RFC3339)
-		record.HeadEnrichedAt = &headEnrichedAt
-	}
-	populateIndexCanonicalAlternateDeltaData(&record, r)
-	return record
-}
-
-func populateIndexCanonicalAlternateDeltaData(data *indexCanonicalAlternateData, r indexstore.QueryResult) {
-	if data == nil || r.ChangeKind == "" {
-		return
-	}
-	data.FirstSeenRunID = r.FirstSeenRunID
-	data.LastChangedRunID = r.LastChangedRunID
-	data.ChangeKind = r.ChangeKind
-	if r.FirstSeenAt != nil {
-		firstSeenAt := r.FirstSeenAt.Format(time.RFC3339)
-		data.FirstSeenAt = &firstSeenAt
-	}
-	if r.LastChangedAt != nil {
-		lastChangedAt := r.LastChangedAt.Format(time.RFC3339)
-		data.LastChangedAt = &lastChangedAt
-	}
+	return indexquery.NewCanonicalRecord(baseURI, ts, group, rule, includeAlternates)
 }
 
 type indexDBEntry struct {
@@ -1023,27 +921,6 @@ func normalizeQueryBaseURI(uri string) string {
 }
 
 // reconstructFullKey builds the full object key from base URI and relative key.
-// The key is the path portion after the bucket in the base URI.
 func reconstructFullKey(baseURI, relKey string) string {
-	// baseURI is like "s3://bucket/prefix/"
-	// Extract the prefix part (everything after s3://bucket/)
-	// For now, since rel_key is stored relative to base_uri prefix, return
-	// the prefix + rel_key
-	//
-	// Example:
-	//   base_uri: s3://mybucket/data/
-	//   rel_key: 2025/01/file.json
-	//   full_key: data/2025/01/file.json
-
-	// Parse the base URI to extract the prefix
-	if strings.HasPrefix(baseURI, "s3://") {
-		parts := strings.SplitN(strings.TrimPrefix(baseURI, "s3://"), "/", 2)
-		if len(parts) == 2 {
-			prefix := parts[1] // includes trailing slash
-			return prefix + relKey
-		}
-	}
-
-	// Fallback: just return rel_key
-	return relKey
+	return indexquery.FullKey(baseURI, relKey)
 }
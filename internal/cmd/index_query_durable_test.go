@@ -50,6 +50,41 @@ func TestIndexQuery_DurableOnlyCLI(t *testing.T) {
 	require.EqualValues(t, 10, first.Data.SizeBytes)
 }
 
+// TestIndexQuery_KeysFromLooksUpListedKeys pins --keys-from: listed keys that
+// are indexed come back in key order, full URIs under the base are accepted,
+// a repeated key is counted once, and filters that would change the answer are
+// refused.
+func TestIndexQuery_KeysFromLooksUpListedKeys(t *testing.T) {
+	resetAppDataRootTestState(t)
+	dataRoot := filepath.Join(t.TempDir(), "gonimbus-data")
+	t.Setenv("GONIMBUS_DATA_DIR", dataRoot)
+
+	env := seedDurableOnlyAppData(t, dataRoot, []indexsubstrate.CurrentObjectRow{
+		durableCLIRow("data/one.json", 10, "e1", time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)),
+		durableCLIRow("data/two.xml", 20, "e2", time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)),
+		durableCLIRow("other/three.txt", 30, "e3", time.Date(2025, 4, 3, 0, 0, 0, 0, time.UTC)),
+	})
+	keysPath := filepath.Join(t.TempDir(), "keys.txt")
+	require.NoError(t, os.WriteFile(keysPath, []byte("other/three.txt\r\n\n"+env.baseURI+"data/one.json\ndata/missing.json\ndata/one.json\n"), 0o600))
+
+	stdout, stderr, err := executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--keys-from", keysPath)
+	require.NoError(t, err, "stderr=%q", stderr)
+	lines := nonEmptyLines(stdout)
+	require.Len(t, lines, 2, "stdout=%q", stdout)
+	require.Contains(t, stderr, "Matched 2 of 3 keys")
+	var first indexQueryRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.Equal(t, "data/one.json", first.Data.RelKey)
+	require.Equal(t, "e1", first.Data.ETag)
+
+	stdout, _, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--keys-from", keysPath, "--count")
+	require.NoError(t, err)
+	require.Equal(t, "2", strings.TrimSpace(stdout))
+
+	_, _, err = executeIndexQueryCommand(t, "--index-set", env.indexSetID, "--keys-from", keysPath, "--pattern", "data/**")
+	require.ErrorContains(t, err, "do not combine it with --pattern")
+}
+
 func executeIndexQueryCommand(t *testing.T, args ...string) (string, string, error) {
 	t.Helper()
 	cmd := newIndexQueryCommandForTest()
@@ -99,7 +134,9 @@ func newIndexQueryCommandForTest() *cobra.Command {
 	cmd.Flags().String("canonical-tie-break", string(indexstore.CanonicalTieBreakMinKey), "Canonical selection rule")
 	cmd.Flags().Bool("include-alternates", false, "Populate alternates[] on canonical ETag records")
 	cmd.Flags().String("since-run", "", "Only emit current objects first seen or changed after this successful run")
+	cmd.Flags().String("keys-from", "", "Look up the keys listed in this file")
 	cmd.Flags().String("index-set", "", "Explicit index set ID")
+	cmd.Flags().String("as-of", "", "Query the retained durable run published at or before this time")
 	cmd.Flags().String("output", "", "Output destination URI")
 	cmd.Flags().String("output-profile", "", "AWS profile for output destination")
 	cmd.Flags().String("output-region", "", "AWS region for output destination")
@@ -240,12 +277,27 @@ func seedDurableOnlyAppData(t *testing.T, dataRoot string, rows []indexsubstrate
 	require.NoError(t, os.MkdirAll(identityDir, 0o755))
 	require.NoError(t, os.WriteFile(filepath.Join(identityDir, "identity.json"), []byte(identity.CanonicalJSON+"\n"), 0o600))
 
-	runID := "run_cli_1"
 	segmentRoot := filepath.Join(segmentCacheRoot, identity.IndexSetID)
+	publishDurableCLIRun(t, segmentRoot, identity.IndexSetID, "run_cli_1", time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC), rows)
+	lease, err := indexsubstrate.AcquireWriteLease(segmentRoot, identity.IndexSetID, "fixture-publish", 0)
+	require.NoError(t, err)
+	require.NoError(t, lease.Release())
+	return durableCLIEnv{
+		baseURI:     baseURI,
+		indexSetID:  identity.IndexSetID,
+		identityDir: identityDir,
+		params:      params,
+	}
+}
+
+// publishDurableCLIRun writes rows as runID's snapshot under segmentRoot,
+// publishes its complete marker, and advances latest.json to it.
+func publishDurableCLIRun(t *testing.T, segmentRoot, indexSetID, runID string, createdAt time.Time, rows []indexsubstrate.CurrentObjectRow) {
+	t.Helper()
 	runDir := filepath.Join(segmentRoot, "runs", runID)
 	require.NoError(t, os.MkdirAll(runDir, 0o755))
 	for i := range rows {
-		rows[i].IndexSetID = identity.IndexSetID
+		rows[i].IndexSetID = indexSetID
 		if rows[i].FirstSeenRunID == "" {
 			rows[i].FirstSeenRunID = runID
 		}
@@ -256,10 +308,9 @@ func seedDurableOnlyAppData(t *testing.T, dataRoot string, rows []indexsubstrate
 			rows[i].LastSeenRunID = runID
 		}
 	}
-	createdAt := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
 	manifest, err := indexsubstrate.WriteSegmentSet(indexsubstrate.SegmentWriterConfig{
 		Dir:                  runDir,
-		IndexSetID:           identity.IndexSetID,
+		IndexSetID:           indexSetID,
 		RunID:                runID,
 		CreatedAt:            createdAt,
 		TargetRowsPerSegment: 100,
@@ -272,7 +323,7 @@ func seedDurableOnlyAppData(t *testing.T, dataRoot string, rows []indexsubstrate
 	completePath := filepath.Join(runDir, "complete.json")
 	writeJSONFile(t, completePath, map[string]any{
 		"type":            "gonimbus.index.complete.v1",
-		"index_set_id":    identity.IndexSetID,
+		"index_set_id":    indexSetID,
 		"run_id":          runID,
 		"completed_at":    createdAt.Format(time.RFC3339Nano),
 		"manifest_path":   manifestPath,
@@ -282,20 +333,11 @@ func seedDurableOnlyAppData(t *testing.T, dataRoot string, rows []indexsubstrate
 	})
 	writeJSONFile(t, filepath.Join(segmentRoot, "latest.json"), map[string]any{
 		"type":          "gonimbus.index.latest.v1",
-		"index_set_id":  identity.IndexSetID,
+		"index_set_id":  indexSetID,
 		"run_id":        runID,
 		"updated_at":    createdAt.Format(time.RFC3339Nano),
 		"complete_path": completePath,
 	})
-	lease, err := indexsubstrate.AcquireWriteLease(segmentRoot, identity.IndexSetID, "fixture-publish", 0)
-	require.NoError(t, err)
-	require.NoError(t, lease.Release())
-	return durableCLIEnv{
-		baseURI:     baseURI,
-		indexSetID:  identity.IndexSetID,
-		identityDir: identityDir,
-		params:      params,
-	}
 }
 
 func durableCLIRow(relKey string, size int64, etag string, mod time.Time) indexsubstrate.CurrentObjectRow {
@@ -31,8 +31,10 @@ Format-aware: resolves sqlite-v1 or durable-v2 via the local index reader seam.
 Durable-v2 notes:
   - Object counts come from the published manifest (active/tombstone/total rows).
   - Size is the sum of published segment sizes (not per-object SUM(size_bytes)).
-  - --prefixes is sqlite-only (prefix_stats table).
-  - --runs lists published durable complete markers only (not SQLite run lifecycle).
+  - --prefixes reads the prefix artifact the manifest binds by digest, with
+    direct and recursive object/byte counts per prefix.
+  - --runs merges published complete markers with the durable run ledger, so
+    failed, failed-resumable, aborted, and still-running builds are listed too.
 
 Examples:
   # Show stats for an index
@@ -41,8 +43,11 @@ Examples:
   # Show with JSON output
   gonimbus index stats s3://bucket/prefix/ --json
 
-  # Show prefix breakdown (sqlite-v1 / both)
-  gonimbus index stats s3://bucket/prefix/ --prefixes`,
+  # Show prefix breakdown
+  gonimbus index stats s3://bucket/prefix/ --prefixes
+
+  # Show the top two levels of the prefix tree only
+  gonimbus index stats s3://bucket/prefix/ --prefixes --prefix-depth 2`,
 	Args: cobra.ExactArgs(1),
 	RunE: runIndexStats,
 }
@@ -52,6 +57,7 @@ func init() {
 	indexStatsCmd.Flags().Bool("json", false, "Output as JSON")
 	indexStatsCmd.Flags().Bool("prefixes", false, "Include prefix breakdown")
 	indexStatsCmd.Flags().Bool("runs", false, "Include run history")
+	indexStatsCmd.Flags().Int("prefix-depth", 0, "With --prefixes, show prefixes down to this depth only (0 = all recorded)")
 }
 
 func runIndexStats(cmd *cobra.Command, args []string) (err error) {
@@ -60,6 +66,10 @@ func runIndexStats(cmd *cobra.Command, args []string) (err error) {
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 	showPrefixes, _ := cmd.Flags().GetBool("prefixes")
 	showRuns, _ := cmd.Flags().GetBool("runs")
+	prefixDepth, _ := cmd.Flags().GetInt("prefix-depth")
+	if prefixDepth < 0 {
+		return fmt.Errorf("--prefix-depth must be non-negative")
+	}
 
 	reader, err := openIndexReader(ctx, baseURI, "", "")
 	if err != nil {
@@ -70,18 +80,15 @@ func runIndexStats(cmd *cobra.Command, args []string) (err error) {
 
 	switch meta.Format {
 	case indexreader.FormatSQLiteV1:
-		return runIndexStatsSQLite(ctx, reader.SQLiteDB(), meta, jsonOutput, showPrefixes, showRuns)
+		return runIndexStatsSQLite(ctx, reader.SQLiteDB(), meta, jsonOutput, showPrefixes, showRuns, prefixDepth)
 	case indexreader.FormatDurableV2:
-		if showPrefixes {
-			return errUnsupportedOnDurable("--prefixes")
-		}
-		return runIndexStatsDurable(meta, jsonOutput, showRuns)
+		return runIndexStatsDurable(meta, jsonOutput, showPrefixes, showRuns, prefixDepth)
 	default:
 		return fmt.Errorf("unsupported index format %q", meta.Format)
 	}
 }
 
-func runIndexStatsSQLite(ctx context.Context, db *sql.DB, meta indexreader.Meta, jsonOutput, showPrefixes, showRuns bool) error {
+func runIndexStatsSQLite(ctx context.Context, db *sql.DB, meta indexreader.Meta, jsonOutput, showPrefixes, showRuns bool, prefixDepth int) error {
 	if db == nil {
 		return fmt.Errorf("SQLite reader connection is unavailable")
 	}
@@ -111,6 +118,15 @@ func runIndexStatsSQLite(ctx context.Context, db *sql.DB, meta indexreader.Meta,
 		if err != nil {
 			return fmt.Errorf("get prefix stats: %w", err)
 		}
+		if prefixDepth > 0 {
+			kept := prefixStats[:0]
+			for _, ps := range prefixStats {
+				if ps.Depth <= prefixDepth {
+					kept = append(kept, ps)
+				}
+			}
+			prefixStats = kept
+		}
 	}
 
 	var runs []indexstore.IndexRun
@@ -127,17 +143,20 @@ func runIndexStatsSQLite(ctx context.Context, db *sql.DB, meta indexreader.Meta,
 	return printStatsTable(summary, prefixStats, runs, indexSet, string(indexreader.FormatSQLiteV1))
 }
 
-// durablePublishedRun is publication history for durable-v2. It deliberately
-// does not populate SQLite RunStartedAt semantics — durable markers carry
-// publication/completion times, not exact crawl run-start provenance.
+// durablePublishedRun is run history for durable-v2. Published runs come from
+// complete markers, which carry publication/completion times, not exact crawl
+// run-start provenance; StartedAt/EndedAt are only set from the run ledger.
 type durablePublishedRun struct {
 	RunID             string
 	PublishedAt       *time.Time // complete.completed_at when parseable
 	ManifestCreatedAt *time.Time // manifest.created_at when non-zero
+	StartedAt         *time.Time // ledger started_at, history only
+	EndedAt           *time.Time // ledger ended_at, history only
 	Status            string
+	Reason            string
 }
 
-func runIndexStatsDurable(meta indexreader.Meta, jsonOutput, showRuns bool) error {
+func runIndexStatsDurable(meta indexreader.Meta, jsonOutput, showPrefixes, showRuns bool, prefixDepth int) error {
 	opts, err := indexReaderResolveOptions()
 	if err != nil {
 		return err
@@ -179,6 +198,13 @@ func runIndexStatsDurable(meta indexreader.Meta, jsonOutput, showRuns bool) erro
 	if len(published) == 0 {
 		published = []durablePublishedRun{latest}
 	}
+	publishedCount := len(published)
+	// The ledger is history only; an unreadable ledger leaves the published view.
+	ledger, err := indexsubstrate.ListRunLedger(segmentRoot, indexSetID, opts.MaxMarkerBytes)
+	if err != nil {
+		ledger = nil
+	}
+	history := mergeDurableRunLedger(published, ledger)
 
 	indexSet := &indexstore.IndexSet{
 		IndexSetID: indexSetID,
@@ -216,12 +242,30 @@ func runIndexStatsDurable(meta indexreader.Meta, jsonOutput, showRuns bool) erro
 		DeletedObjects:  int64(snap.Manifest.Counts.Tombstones),
 		TotalObjects:    int64(snap.Manifest.Counts.Rows),
 		TotalSizeBytes:  durableSegmentTotalSize(snap.Manifest),
-		PublishedRuns:   len(published),
+		PublishedRuns:   publishedCount,
+		RunCounts:       tallyDurableRuns(history),
 		Latest:          latest,
 		History:         nil,
 	}
 	if showRuns {
-		view.History = published
+		view.History = history
+	}
+	if showPrefixes {
+		doc, ok, err := indexsubstrate.ReadPublishedPrefixStats(snap)
+		if err != nil {
+			return fmt.Errorf("read durable prefix stats: %w", err)
+		}
+		view.PrefixesRequested = true
+		if ok {
+			view.PrefixesAvailable = true
+			view.PrefixesTruncated = doc.Truncated
+			for _, ps := range doc.Prefixes {
+				if prefixDepth > 0 && ps.Depth > prefixDepth {
+					continue
+				}
+				view.Prefixes = append(view.Prefixes, ps)
+			}
+		}
 	}
 	if jsonOutput {
 		return printDurableStatsJSON(view)
@@ -282,9 +326,88 @@ func durableSortTime(r durablePublishedRun) time.Time {
 	if r.ManifestCreatedAt != nil {
 		return *r.ManifestCreatedAt
 	}
+	if r.EndedAt != nil {
+		return *r.EndedAt
+	}
+	if r.StartedAt != nil {
+		return *r.StartedAt
+	}
 	return time.Time{}
 }
 
+// mergeDurableRunLedger folds run ledger entries into the published history.
+// A complete marker is the authority that a run published, so a published run
+// keeps its success status whatever its ledger entry says; the ledger adds its
+// start/end times and contributes the runs that never published.
+func mergeDurableRunLedger(published []durablePublishedRun, ledger []indexsubstrate.RunLedgerEntry) []durablePublishedRun {
+	out := append([]durablePublishedRun(nil), published...)
+	index := make(map[string]int, len(out))
+	for i, r := range out {
+		index[r.RunID] = i
+	}
+	for _, entry := range ledger {
+		started := entry.StartedAt
+		var startedAt *time.Time
+		if !started.IsZero() {
+			startedAt = &started
+		}
+		if i, ok := index[entry.RunID]; ok {
+			out[i].StartedAt = startedAt
+			out[i].EndedAt = entry.EndedAt
+			continue
+		}
+		if entry.Status == indexsubstrate.RunLedgerSuccess {
+			// Claims success without a complete marker on disk; the marker is
+			// authoritative, so this is not a published run.
+			entry.Status = indexsubstrate.RunLedgerFailed
+			entry.Reason = firstNonEmpty(entry.Reason, "complete marker missing")
+		}
+		out = append(out, durablePublishedRun{
+			RunID:     entry.RunID,
+			StartedAt: startedAt,
+			EndedAt:   entry.EndedAt,
+			Status:    string(entry.Status),
+			Reason:    entry.Reason,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		ti, tj := durableSortTime(out[i]), durableSortTime(out[j])
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return out[i].RunID > out[j].RunID
+	})
+	return out
+}
+
+type durableRunTally struct {
+	Total           int
+	Successful      int
+	Failed          int
+	FailedResumable int
+	Aborted         int
+	Running         int
+}
+
+func tallyDurableRuns(history []durablePublishedRun) durableRunTally {
+	counts := durableRunTally{Total: len(history)}
+	for _, r := range history {
+		switch indexsubstrate.RunLedgerStatus(r.Status) {
+		case indexsubstrate.RunLedgerSuccess:
+			counts.Successful++
+		case indexsubstrate.RunLedgerFailed:
+			counts.Failed++
+		case indexsubstrate.RunLedgerFailedResumable:
+			counts.FailedResumable++
+		case indexsubstrate.RunLedgerAborted:
+			counts.Aborted++
+		case indexsubstrate.RunLedgerRunning:
+			counts.Running++
+		}
+	}
+	return counts
+}
+
 type durableStatsView struct {
 	IndexSetID      string
 	BaseURI         string
@@ -297,8 +420,14 @@ type durableStatsView struct {
 	TotalObjects    int64
 	TotalSizeBytes  int64
 	PublishedRuns   int
+	RunCounts       durableRunTally
 	Latest          durablePublishedRun
 	History         []durablePublishedRun
+
+	PrefixesRequested bool
+	PrefixesAvailable bool
+	PrefixesTruncated bool
+	Prefixes          []indexsubstrate.PrefixStat
 }
 
 func printDurableStatsTable(view durableStatsView) error {
@@ -331,16 +460,33 @@ func printDurableStatsTable(view durableStatsView) error {
 	_, _ = fmt.Fprintln(os.Stdout, "  note: durable markers carry publication times, not crawl run-start")
 	_, _ = fmt.Fprintln(os.Stdout)
 
+	_, _ = fmt.Fprintln(os.Stdout, "Runs (complete markers and run ledger):")
+	_, _ = fmt.Fprintf(os.Stdout, "  Total:      %d\n", view.RunCounts.Total)
+	_, _ = fmt.Fprintf(os.Stdout, "  Successful: %d\n", view.RunCounts.Successful)
+	_, _ = fmt.Fprintf(os.Stdout, "  Failed:     %d\n", view.RunCounts.Failed)
+	_, _ = fmt.Fprintf(os.Stdout, "  Resumable:  %d\n", view.RunCounts.FailedResumable)
+	_, _ = fmt.Fprintf(os.Stdout, "  Aborted:    %d\n", view.RunCounts.Aborted)
+	if view.RunCounts.Running > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "  Running:    %d (a crashed run stays running)\n", view.RunCounts.Running)
+	}
+	_, _ = fmt.Fprintln(os.Stdout)
+
+	if view.PrefixesRequested {
+		printDurablePrefixTable(view)
+	}
+
 	if len(view.History) > 0 {
-		_, _ = fmt.Fprintln(os.Stdout, "Publication history:")
+		_, _ = fmt.Fprintln(os.Stdout, "Run history:")
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-		_, _ = fmt.Fprintln(w, "  RUN ID\tPUBLISHED\tMANIFEST_CREATED\tSTATUS")
+		_, _ = fmt.Fprintln(w, "  RUN ID\tSTARTED\tPUBLISHED\tMANIFEST_CREATED\tSTATUS\tREASON")
 		for _, r := range view.History {
-			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n",
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n",
 				r.RunID,
+				formatOptionalRFC3339(r.StartedAt),
 				formatOptionalRFC3339(r.PublishedAt),
 				formatOptionalRFC3339(r.ManifestCreatedAt),
 				r.Status,
+				valueOrDash(r.Reason),
 			)
 		}
 		_ = w.Flush()
@@ -348,12 +494,58 @@ func printDurableStatsTable(view durableStatsView) error {
 	return nil
 }
 
+func printDurablePrefixTable(view durableStatsView) {
+	if !view.PrefixesAvailable {
+		_, _ = fmt.Fprintln(os.Stdout, "Prefixes: not recorded (snapshot predates the prefix artifact; rebuild to publish one)")
+		_, _ = fmt.Fprintln(os.Stdout)
+		return
+	}
+	_, _ = fmt.Fprintln(os.Stdout, "Prefixes (from latest snapshot):")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  PREFIX\tOBJECTS\tSIZE\tTOTAL_OBJECTS\tTOTAL_SIZE\tSUBPREFIXES\tTRUNCATED")
+	for _, ps := range view.Prefixes {
+		truncated := "-"
+		if ps.Truncated {
+			truncated = ps.TruncatedReason
+		}
+		_, _ = fmt.Fprintf(w, "  %s%s\t%d\t%s\t%d\t%s\t%d\t%s\n",
+			strings.Repeat("  ", ps.Depth),
+			displayPrefix(ps.Prefix),
+			ps.ObjectsDirect,
+			formatBytes(ps.BytesDirect),
+			ps.ObjectsRecursive,
+			formatBytes(ps.BytesRecursive),
+			ps.CommonPrefixes,
+			truncated,
+		)
+	}
+	_ = w.Flush()
+	if view.PrefixesTruncated {
+		_, _ = fmt.Fprintln(os.Stdout, "  note: some prefixes were folded into their parent (see TRUNCATED)")
+	}
+	_, _ = fmt.Fprintln(os.Stdout)
+}
+
 func printDurableStatsJSON(view durableStatsView) error {
 	type jsonPubRun struct {
 		RunID             string  `json:"run_id"`
 		PublishedAt       *string `json:"published_at,omitempty"`
 		ManifestCreatedAt *string `json:"manifest_created_at,omitempty"`
+		LedgerStartedAt   *string `json:"ledger_started_at,omitempty"`
+		LedgerEndedAt     *string `json:"ledger_ended_at,omitempty"`
 		Status            string  `json:"status"`
+		Reason            string  `json:"reason,omitempty"`
+	}
+	type jsonPrefix struct {
+		Prefix           string `json:"prefix"`
+		Depth            int    `json:"depth"`
+		ObjectsDirect    int64  `json:"objects_direct"`
+		BytesDirect      int64  `json:"bytes_direct"`
+		ObjectsRecursive int64  `json:"objects_recursive"`
+		BytesRecursive   int64  `json:"bytes_recursive"`
+		CommonPrefixes   int64  `json:"common_prefixes"`
+		Truncated        bool   `json:"truncated"`
+		TruncatedReason  string `json:"truncated_reason,omitempty"`
 	}
 	type jsonOut struct {
 		IndexSetID      string `json:"index_set_id"`
@@ -374,15 +566,34 @@ func printDurableStatsJSON(view durableStatsView) error {
 			Total  int         `json:"total"`
 			Latest *jsonPubRun `json:"latest,omitempty"`
 		} `json:"published_runs"`
+		Runs struct {
+			Total           int `json:"total"`
+			Successful      int `json:"successful"`
+			Failed          int `json:"failed"`
+			FailedResumable int `json:"failed_resumable"`
+			Aborted         int `json:"aborted"`
+			Running         int `json:"running"`
+		} `json:"runs"`
+		PrefixesAvailable  *bool        `json:"prefixes_available,omitempty"`
+		PrefixesTruncated  bool         `json:"prefixes_truncated,omitempty"`
+		Prefixes           []jsonPrefix `json:"prefixes,omitempty"`
 		PublicationHistory []jsonPubRun `json:"publication_history,omitempty"`
 		TimeSemantics      string       `json:"time_semantics"`
 	}
 	toJSON := func(r durablePublishedRun) jsonPubRun {
-		out := jsonPubRun{RunID: r.RunID, Status: r.Status}
+		out := jsonPubRun{RunID: r.RunID, Status: r.Status, Reason: r.Reason}
 		if r.PublishedAt != nil {
 			s := r.PublishedAt.Format(time.RFC3339Nano)
 			out.PublishedAt = &s
 		}
+		if r.StartedAt != nil {
+			s := r.StartedAt.Format(time.RFC3339Nano)
+			out.LedgerStartedAt = &s
+		}
+		if r.EndedAt != nil {
+			s := r.EndedAt.Format(time.RFC3339Nano)
+			out.LedgerEndedAt = &s
+		}
 		if r.ManifestCreatedAt != nil {
 			s := r.ManifestCreatedAt.Format(time.RFC3339Nano)
 			out.ManifestCreatedAt = &s
@@ -405,6 +616,20 @@ func printDurableStatsJSON(view durableStatsView) error {
 	out.Objects.TotalSizeBytes = view.TotalSizeBytes
 	out.Objects.SizeSemantics = "segment_file_bytes"
 	out.PublishedRuns.Total = view.PublishedRuns
+	out.Runs.Total = view.RunCounts.Total
+	out.Runs.Successful = view.RunCounts.Successful
+	out.Runs.Failed = view.RunCounts.Failed
+	out.Runs.FailedResumable = view.RunCounts.FailedResumable
+	out.Runs.Aborted = view.RunCounts.Aborted
+	out.Runs.Running = view.RunCounts.Running
+	if view.PrefixesRequested {
+		available := view.PrefixesAvailable
+		out.PrefixesAvailable = &available
+		out.PrefixesTruncated = view.PrefixesTruncated
+		for _, ps := range view.Prefixes {
+			out.Prefixes = append(out.Prefixes, jsonPrefix(ps))
+		}
+	}
 	latest := toJSON(view.Latest)
 	out.PublishedRuns.Latest = &latest
 	if len(view.History) > 0 {
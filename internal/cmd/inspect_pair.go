@@ -3,18 +3,25 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fulmenhq/gofulmen/foundry"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
 	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/internal/signing"
+	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/provider"
 	reflowpkg "github.com/3leaps/gonimbus/pkg/reflow"
@@ -33,7 +40,17 @@ var inspectPairCmd = &cobra.Command{
 
 inspect-pair is audit-driven: it reads gonimbus.reflow.v1 records, validates
 each destination URI against an operator-supplied expected destination prefix,
-then HEADs in-scope claimed writes and emits JSONL verdict records.`,
+then HEADs in-scope claimed writes and emits JSONL verdict records.
+
+--verify content=full|content=sample:N% additionally re-reads source and
+destination bytes for pairs whose metadata verified and compares SHA-256
+digests. Full mode streams both objects; sample mode reads evenly spaced 1 MiB
+windows (always including the first and last) with ranged GETs until at least
+N% of the object is covered. --verify-max-requests, --verify-max-bytes, and
+--verify-bandwidth bound the reads.
+
+--sign-key signs the summary record with an ed25519 key so the run can be
+archived as migration evidence.`,
 	Args: cobra.NoArgs,
 	RunE: runInspectPairCommand,
 }
@@ -46,6 +63,15 @@ var (
 	inspectPairProfile              string
 	inspectPairEndpoint             string
 	inspectPairGCPProject           string
+	inspectPairSrcRegion            string
+	inspectPairSrcProfile           string
+	inspectPairSrcEndpoint          string
+	inspectPairSrcGCPProject        string
+	inspectPairVerify               string
+	inspectPairVerifyMaxRequests    int64
+	inspectPairVerifyMaxBytes       string
+	inspectPairVerifyBandwidth      string
+	inspectPairSignKey              string
 )
 
 type inspectPairProviderFactory func(context.Context, inspectPairScope) (provider.Provider, error)
@@ -81,6 +107,15 @@ func init() {
 	inspectPairCmd.Flags().StringVarP(&inspectPairProfile, "profile", "p", "", "AWS profile for destination HEADs")
 	inspectPairCmd.Flags().StringVar(&inspectPairEndpoint, "endpoint", "", "Custom S3 endpoint for destination HEADs")
 	inspectPairCmd.Flags().StringVar(&inspectPairGCPProject, "gcp-project", "", "GCP project hint for GCS destination HEADs")
+	inspectPairCmd.Flags().StringVar(&inspectPairSrcRegion, "src-region", "", "AWS region for source reads (content verification)")
+	inspectPairCmd.Flags().StringVar(&inspectPairSrcProfile, "src-profile", "", "AWS profile for source reads (content verification)")
+	inspectPairCmd.Flags().StringVar(&inspectPairSrcEndpoint, "src-endpoint", "", "Custom S3 endpoint for source reads (content verification)")
+	inspectPairCmd.Flags().StringVar(&inspectPairSrcGCPProject, "src-gcp-project", "", "GCP project hint for GCS source reads (content verification)")
+	inspectPairCmd.Flags().StringVar(&inspectPairVerify, "verify", inspectPairVerifyMetadata, "Verification level: metadata, content=full, or content=sample:N%")
+	inspectPairCmd.Flags().Int64Var(&inspectPairVerifyMaxRequests, "verify-max-requests", 0, "Maximum content read requests across both sides (0 = unlimited)")
+	inspectPairCmd.Flags().StringVar(&inspectPairVerifyMaxBytes, "verify-max-bytes", "", "Maximum content bytes read across both sides (e.g. 50GiB; empty = unlimited)")
+	inspectPairCmd.Flags().StringVar(&inspectPairVerifyBandwidth, "verify-bandwidth", "", "Content read bandwidth limit per second (e.g. 100MiB; empty = unlimited)")
+	inspectPairCmd.Flags().StringVar(&inspectPairSignKey, "sign-key", "", "PEM PKCS#8 ed25519 private key used to sign the summary record")
 }
 
 type inspectPairOptions struct {
@@ -88,6 +123,12 @@ type inspectPairOptions struct {
 	FromReflow           string
 	ExpectedDestPrefixes []string
 	ProviderFactory      inspectPairProviderFactory
+
+	// Verify is the --verify value; empty means metadata.
+	Verify                string
+	ContentBudget         inspectPairContentBudget
+	SourceProviderFactory inspectPairProviderFactory
+	SignKey               ed25519.PrivateKey
 }
 
 type inspectPairScope struct {
@@ -138,6 +179,14 @@ type inspectPairRecord struct {
 	UpstreamStatus     string `json:"upstream_status,omitempty"`
 	UpstreamReason     string `json:"upstream_reason,omitempty"`
 	ExpectedDestPrefix string `json:"expected_dest_prefix,omitempty"`
+
+	ContentVerdict       string `json:"content_verdict,omitempty"`
+	ContentMode          string `json:"content_mode,omitempty"`
+	ContentBytesCompared int64  `json:"content_bytes_compared,omitempty"`
+	ContentWindows       int    `json:"content_windows,omitempty"`
+	SourceSHA256         string `json:"source_sha256,omitempty"`
+	DestSHA256           string `json:"dest_sha256,omitempty"`
+	ContentReason        string `json:"content_reason,omitempty"`
 }
 
 type inspectPairSummary struct {
@@ -145,19 +194,56 @@ type inspectPairSummary struct {
 	Verified                int64 `json:"verified"`
 	VerifiedSizeETagDiffers int64 `json:"verified_size_etag_differs"`
 	SizeMismatch            int64 `json:"size_mismatch"`
+	ContentMismatch         int64 `json:"content_mismatch"`
 	Missing                 int64 `json:"missing"`
 	Error                   int64 `json:"error"`
 	InvalidDest             int64 `json:"invalid_dest"`
 	NotVerified             int64 `json:"not_verified"`
 	IgnoredNonterminal      int64 `json:"ignored_nonterminal"`
+
+	Content *inspectPairContentSummary `json:"content,omitempty"`
+
+	// RecordsSHA256 chains the pair records into the signed summary: SHA-256
+	// over each record's data in signing.CanonicalJSON form, newline-terminated,
+	// in output order. Set only when signing.
+	RecordsSHA256 string             `json:"records_sha256,omitempty"`
+	SignedAt      string             `json:"signed_at,omitempty"`
+	Signature     *signing.Signature `json:"signature,omitempty"`
 }
 
 func runInspectPairCommand(cmd *cobra.Command, _ []string) error {
 	opts := inspectPairOptions{
-		UseStdin:             inspectPairStdin,
-		FromReflow:           inspectPairFromReflow,
-		ExpectedDestPrefixes: append([]string(nil), inspectPairExpectedDestPrefixes...),
-		ProviderFactory:      newInspectPairProvider,
+		UseStdin:              inspectPairStdin,
+		FromReflow:            inspectPairFromReflow,
+		ExpectedDestPrefixes:  append([]string(nil), inspectPairExpectedDestPrefixes...),
+		ProviderFactory:       newInspectPairProvider,
+		Verify:                inspectPairVerify,
+		SourceProviderFactory: newInspectPairSourceProvider,
+		ContentBudget:         inspectPairContentBudget{MaxRequests: inspectPairVerifyMaxRequests},
+	}
+	if opts.ContentBudget.MaxRequests < 0 {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --verify-max-requests", fmt.Errorf("must be >= 0"))
+	}
+	if strings.TrimSpace(inspectPairVerifyMaxBytes) != "" {
+		n, err := match.ParseSize(inspectPairVerifyMaxBytes)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --verify-max-bytes", err)
+		}
+		opts.ContentBudget.MaxBytes = n
+	}
+	if strings.TrimSpace(inspectPairVerifyBandwidth) != "" {
+		n, err := match.ParseSize(inspectPairVerifyBandwidth)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --verify-bandwidth", err)
+		}
+		opts.ContentBudget.BytesPerSecond = n
+	}
+	if strings.TrimSpace(inspectPairSignKey) != "" {
+		key, err := signing.LoadPrivateKey(inspectPairSignKey)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --sign-key", err)
+		}
+		opts.SignKey = key
 	}
 	if err := runInspectPair(cmd.Context(), cmd.InOrStdin(), cmd.OutOrStdout(), opts); err != nil {
 		return err
@@ -180,6 +266,10 @@ func runInspectPair(ctx context.Context, stdin io.Reader, stdout io.Writer, opts
 	if err != nil {
 		return exitError(foundry.ExitInvalidArgument, "Invalid --expected-dest-prefix", err)
 	}
+	spec, err := parseInspectPairVerify(opts.Verify)
+	if err != nil {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --verify", err)
+	}
 
 	in := stdin
 	var f *os.File
@@ -203,6 +293,31 @@ func runInspectPair(ctx context.Context, stdin io.Reader, stdout io.Writer, opts
 	}()
 
 	summary := inspectPairSummary{}
+	var content *inspectPairContentVerifier
+	if spec.enabled() {
+		sourceFactory := opts.SourceProviderFactory
+		if sourceFactory == nil {
+			sourceFactory = newInspectPairSourceProvider
+		}
+		summary.Content = &inspectPairContentSummary{Mode: spec.String()}
+		content = newInspectPairContentVerifier(spec, opts.ContentBudget, sourceFactory, summary.Content)
+		defer content.Close()
+	}
+	var recordsHash hash.Hash
+	if opts.SignKey != nil {
+		recordsHash = sha256.New()
+	}
+	writeRecord := func(rec inspectPairRecord) error {
+		if recordsHash != nil {
+			payload, err := signing.CanonicalJSON(rec)
+			if err != nil {
+				return err
+			}
+			_, _ = recordsHash.Write(append(payload, '\n'))
+		}
+		return w.WriteAny(ctx, inspectPairRecordType, rec)
+	}
+
 	scanner := bufio.NewScanner(in)
 	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
 	for scanner.Scan() {
@@ -225,7 +340,7 @@ func runInspectPair(ctx context.Context, stdin io.Reader, stdout io.Writer, opts
 		out, matchedScope, shouldHead := inspectPairRecordForReflow(rec, scopes)
 		if !shouldHead {
 			summary.add(out.Verdict)
-			if err := w.WriteAny(ctx, inspectPairRecordType, out); err != nil {
+			if err := writeRecord(out); err != nil {
 				return err
 			}
 			continue
@@ -235,20 +350,36 @@ func runInspectPair(ctx context.Context, stdin io.Reader, stdout io.Writer, opts
 		if err != nil {
 			return exitError(foundry.ExitExternalServiceUnavailable, "Failed to connect to destination provider", err)
 		}
-		completeInspectPairHead(ctx, prov, rec, matchedScope, &out)
+		destKey, ok := completeInspectPairHead(ctx, prov, rec, matchedScope, &out)
+		if ok && content != nil && (out.Verdict == "verified" || out.Verdict == "verified_size_etag_differs") {
+			content.verify(ctx, rec, prov, destKey, &out)
+		}
 		summary.add(out.Verdict)
-		if err := w.WriteAny(ctx, inspectPairRecordType, out); err != nil {
+		if err := writeRecord(out); err != nil {
 			return err
 		}
 	}
 	if err := scanner.Err(); err != nil {
 		return exitError(foundry.ExitFileReadError, "Failed to read reflow audit", err)
 	}
+	if opts.SignKey != nil {
+		summary.RecordsSHA256 = hex.EncodeToString(recordsHash.Sum(nil))
+		summary.SignedAt = time.Now().UTC().Format(time.RFC3339)
+		sig, err := signing.Sign(opts.SignKey, summary)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Failed to sign inspect-pair summary", err)
+		}
+		summary.Signature = sig
+	}
 	if err := w.WriteAny(ctx, inspectPairSummaryType, summary); err != nil {
 		return err
 	}
 	if summary.hasFailure() {
-		return exitError(foundry.ExitExternalServiceUnavailable, "inspect-pair completed with verification failures", fmt.Errorf("size_mismatch=%d missing=%d error=%d invalid_dest=%d", summary.SizeMismatch, summary.Missing, summary.Error, summary.InvalidDest))
+		detail := fmt.Sprintf("size_mismatch=%d content_mismatch=%d missing=%d error=%d invalid_dest=%d", summary.SizeMismatch, summary.ContentMismatch, summary.Missing, summary.Error, summary.InvalidDest)
+		if summary.Content != nil {
+			detail += fmt.Sprintf(" content_error=%d", summary.Content.Error)
+		}
+		return exitError(foundry.ExitExternalServiceUnavailable, "inspect-pair completed with verification failures", fmt.Errorf("%s", detail))
 	}
 	return nil
 }
@@ -461,12 +592,14 @@ func inspectPairProviderForScope(ctx context.Context, scope inspectPairScope, pr
 	return p, nil
 }
 
-func completeInspectPairHead(ctx context.Context, prov provider.Provider, rec inspectPairReflowRecord, scope inspectPairScope, out *inspectPairRecord) {
+// completeInspectPairHead HEADs the destination and sets the metadata verdict.
+// It returns the provider key it used and whether the HEAD succeeded.
+func completeInspectPairHead(ctx context.Context, prov provider.Provider, rec inspectPairReflowRecord, scope inspectPairScope, out *inspectPairRecord) (string, bool) {
 	dest, err := uri.ParseURI(rec.DestURI)
 	if err != nil {
 		out.Verdict = "invalid_dest"
 		out.Reason = "invalid_dest_uri"
-		return
+		return "", false
 	}
 	key := dest.Key
 	if scope.Provider == string(provider.ProviderFile) {
@@ -474,7 +607,7 @@ func completeInspectPairHead(ctx context.Context, prov provider.Provider, rec in
 		if err != nil {
 			out.Verdict = "invalid_dest"
 			out.Reason = "outside_expected_dest_prefix"
-			return
+			return "", false
 		}
 		key = filepath.ToSlash(rel)
 	}
@@ -483,15 +616,16 @@ func completeInspectPairHead(ctx context.Context, prov provider.Provider, rec in
 		if provider.IsNotFound(err) {
 			out.Verdict = "missing"
 			out.Reason = "not_found"
-			return
+			return "", false
 		}
 		out.Verdict = "error"
 		out.Reason = err.Error()
-		return
+		return "", false
 	}
 	out.DestSizeBytes = meta.Size
 	out.DestETagObserved = meta.ETag
 	out.Verdict, out.ETagComparable = inspectPairVerdict(rec.SourceSize, meta.Size, rec.SourceETag, meta.ETag)
+	return key, true
 }
 
 func inspectPairVerdict(sourceSize int64, destSize int64, sourceETag string, destETag string) (string, bool) {
@@ -535,6 +669,8 @@ func (s *inspectPairSummary) add(verdict string) {
 		s.VerifiedSizeETagDiffers++
 	case "size_mismatch":
 		s.SizeMismatch++
+	case "content_mismatch":
+		s.ContentMismatch++
 	case "missing":
 		s.Missing++
 	case "error":
@@ -546,6 +682,11 @@ func (s *inspectPairSummary) add(verdict string) {
 	}
 }
 
+// hasFailure reports verdicts that fail the run. Content pairs skipped for
+// budget or capability reasons are reported in the summary but do not fail it.
 func (s inspectPairSummary) hasFailure() bool {
-	return s.SizeMismatch > 0 || s.Missing > 0 || s.Error > 0 || s.InvalidDest > 0
+	if s.Content != nil && (s.Content.Mismatch > 0 || s.Content.Error > 0) {
+		return true
+	}
+	return s.SizeMismatch > 0 || s.ContentMismatch > 0 || s.Missing > 0 || s.Error > 0 || s.InvalidDest > 0
 }
@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/time/rate"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+const (
+	inspectPairVerifyMetadata = "metadata"
+
+	inspectPairContentFull   = "full"
+	inspectPairContentSample = "sample"
+
+	// inspectPairSampleWindowBytes is the size of one sampled byte window.
+	inspectPairSampleWindowBytes int64 = 1 << 20
+)
+
+// inspectPairVerifySpec is the parsed --verify value. An empty Mode means
+// metadata-only verification.
+type inspectPairVerifySpec struct {
+	Mode          string
+	SamplePercent float64
+}
+
+func parseInspectPairVerify(raw string) (inspectPairVerifySpec, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == inspectPairVerifyMetadata {
+		return inspectPairVerifySpec{}, nil
+	}
+	mode, ok := strings.CutPrefix(raw, "content=")
+	if !ok {
+		return inspectPairVerifySpec{}, fmt.Errorf("--verify must be metadata, content=full, or content=sample:N%%, got %q", raw)
+	}
+	if mode == inspectPairContentFull {
+		return inspectPairVerifySpec{Mode: inspectPairContentFull}, nil
+	}
+	pct, ok := strings.CutPrefix(mode, inspectPairContentSample+":")
+	if !ok {
+		return inspectPairVerifySpec{}, fmt.Errorf("--verify content mode must be full or sample:N%%, got %q", mode)
+	}
+	value, err := strconv.ParseFloat(strings.TrimSuffix(pct, "%"), 64)
+	if err != nil || !strings.HasSuffix(pct, "%") || value <= 0 || value > 100 || math.IsNaN(value) {
+		return inspectPairVerifySpec{}, fmt.Errorf("--verify sample percentage must be in (0, 100]%%, got %q", pct)
+	}
+	return inspectPairVerifySpec{Mode: inspectPairContentSample, SamplePercent: value}, nil
+}
+
+func (s inspectPairVerifySpec) enabled() bool { return s.Mode != "" }
+
+func (s inspectPairVerifySpec) String() string {
+	switch s.Mode {
+	case inspectPairContentFull:
+		return inspectPairContentFull
+	case inspectPairContentSample:
+		return fmt.Sprintf("%s:%s%%", inspectPairContentSample, strconv.FormatFloat(s.SamplePercent, 'f', -1, 64))
+	default:
+		return inspectPairVerifyMetadata
+	}
+}
+
+// inspectPairContentBudget bounds the provider reads content verification may
+// issue across a run. Zero values are unlimited. Requests and bytes count both
+// sides of every pair.
+type inspectPairContentBudget struct {
+	MaxRequests    int64
+	MaxBytes       int64
+	BytesPerSecond int64
+}
+
+type inspectPairContentSummary struct {
+	Mode            string `json:"mode"`
+	Match           int64  `json:"match"`
+	Mismatch        int64  `json:"mismatch"`
+	Error           int64  `json:"error"`
+	BudgetExhausted int64  `json:"budget_exhausted"`
+	NotVerified     int64  `json:"not_verified"`
+	BytesRead       int64  `json:"bytes_read"`
+	Requests        int64  `json:"requests"`
+}
+
+func (s *inspectPairContentSummary) add(verdict string) {
+	switch verdict {
+	case "match":
+		s.Match++
+	case "mismatch":
+		s.Mismatch++
+	case "error":
+		s.Error++
+	case "budget_exhausted":
+		s.BudgetExhausted++
+	case "not_verified":
+		s.NotVerified++
+	}
+}
+
+// inspectPairContentVerifier re-reads source and destination bytes for pairs
+// whose metadata already verified. Source providers are constructed lazily and
+// cached per bucket (or, for file sources, per parent directory).
+type inspectPairContentVerifier struct {
+	spec      inspectPairVerifySpec
+	budget    inspectPairContentBudget
+	factory   inspectPairProviderFactory
+	providers map[string]provider.Provider
+	limiter   *rate.Limiter
+	summary   *inspectPairContentSummary
+}
+
+func newInspectPairContentVerifier(spec inspectPairVerifySpec, budget inspectPairContentBudget, factory inspectPairProviderFactory, summary *inspectPairContentSummary) *inspectPairContentVerifier {
+	v := &inspectPairContentVerifier{
+		spec:      spec,
+		budget:    budget,
+		factory:   factory,
+		providers: map[string]provider.Provider{},
+		summary:   summary,
+	}
+	if budget.BytesPerSecond > 0 {
+		burst := min(budget.BytesPerSecond, inspectPairSampleWindowBytes)
+		v.limiter = rate.NewLimiter(rate.Limit(budget.BytesPerSecond), int(burst))
+	}
+	return v
+}
+
+func (v *inspectPairContentVerifier) Close() {
+	for _, p := range v.providers {
+		_ = p.Close()
+	}
+}
+
+// verify compares content for one pair and records the outcome on out. A
+// content mismatch downgrades the pair verdict to content_mismatch.
+func (v *inspectPairContentVerifier) verify(ctx context.Context, rec inspectPairReflowRecord, dest provider.Provider, destKey string, out *inspectPairRecord) {
+	out.ContentMode = v.spec.String()
+	v.run(ctx, rec, dest, destKey, out)
+	v.summary.add(out.ContentVerdict)
+	if out.ContentVerdict == "mismatch" {
+		out.Verdict = "content_mismatch"
+	}
+}
+
+func (v *inspectPairContentVerifier) run(ctx context.Context, rec inspectPairReflowRecord, dest provider.Provider, destKey string, out *inspectPairRecord) {
+	src, srcKey, err := v.sourceProvider(ctx, rec.SourceURI)
+	if err != nil {
+		out.ContentVerdict = "error"
+		out.ContentReason = "source: " + err.Error()
+		return
+	}
+	size := out.DestSizeBytes
+
+	var windows [][2]int64
+	requests := int64(2)
+	bytes := 2 * size
+	if v.spec.Mode == inspectPairContentSample {
+		windows = inspectPairSampleWindows(size, v.spec.SamplePercent)
+		requests = 2 * int64(len(windows))
+		bytes = 0
+		for _, w := range windows {
+			bytes += 2 * (w[1] - w[0] + 1)
+		}
+		_, srcOK := src.(provider.ObjectRanger)
+		_, dstOK := dest.(provider.ObjectRanger)
+		if !srcOK || !dstOK {
+			out.ContentVerdict = "not_verified"
+			out.ContentReason = "ranged_reads_unsupported"
+			return
+		}
+	} else {
+		_, srcOK := src.(provider.ObjectGetter)
+		_, dstOK := dest.(provider.ObjectGetter)
+		if !srcOK || !dstOK {
+			out.ContentVerdict = "not_verified"
+			out.ContentReason = "streaming_reads_unsupported"
+			return
+		}
+	}
+	if !v.fits(requests, bytes) {
+		out.ContentVerdict = "budget_exhausted"
+		out.ContentReason = fmt.Sprintf("needs %d requests and %d bytes", requests, bytes)
+		return
+	}
+
+	srcHash, dstHash := sha256.New(), sha256.New()
+	var srcRead, dstRead int64
+	if v.spec.Mode == inspectPairContentSample {
+		out.ContentWindows = len(windows)
+		for _, w := range windows {
+			n, err := v.readRange(ctx, src, srcKey, w, srcHash)
+			srcRead += n
+			if err != nil {
+				out.ContentVerdict, out.ContentReason = "error", "source: "+err.Error()
+				return
+			}
+			n, err = v.readRange(ctx, dest, destKey, w, dstHash)
+			dstRead += n
+			if err != nil {
+				out.ContentVerdict, out.ContentReason = "error", "dest: "+err.Error()
+				return
+			}
+		}
+	} else {
+		n, err := v.readAll(ctx, src, srcKey, srcHash)
+		srcRead = n
+		if err != nil {
+			out.ContentVerdict, out.ContentReason = "error", "source: "+err.Error()
+			return
+		}
+		n, err = v.readAll(ctx, dest, destKey, dstHash)
+		dstRead = n
+		if err != nil {
+			out.ContentVerdict, out.ContentReason = "error", "dest: "+err.Error()
+			return
+		}
+	}
+
+	out.SourceSHA256 = hex.EncodeToString(srcHash.Sum(nil))
+	out.DestSHA256 = hex.EncodeToString(dstHash.Sum(nil))
+	out.ContentBytesCompared = dstRead
+	switch {
+	case srcRead != dstRead:
+		out.ContentVerdict = "mismatch"
+		out.ContentReason = fmt.Sprintf("read %d source bytes and %d destination bytes", srcRead, dstRead)
+	case out.SourceSHA256 != out.DestSHA256:
+		out.ContentVerdict = "mismatch"
+		out.ContentReason = "sha256_differs"
+	default:
+		out.ContentVerdict = "match"
+	}
+}
+
+// fits reports whether a read of the given cost stays within the run budget.
+// An object that does not fit is skipped whole; smaller objects later in the
+// audit may still fit.
+func (v *inspectPairContentVerifier) fits(requests, bytes int64) bool {
+	if v.budget.MaxRequests > 0 && v.summary.Requests+requests > v.budget.MaxRequests {
+		return false
+	}
+	if v.budget.MaxBytes > 0 && v.summary.BytesRead+bytes > v.budget.MaxBytes {
+		return false
+	}
+	return true
+}
+
+func (v *inspectPairContentVerifier) readAll(ctx context.Context, p provider.Provider, key string, h hash.Hash) (int64, error) {
+	v.summary.Requests++
+	body, _, err := p.(provider.ObjectGetter).GetObject(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = body.Close() }()
+	return v.copy(ctx, h, body)
+}
+
+func (v *inspectPairContentVerifier) readRange(ctx context.Context, p provider.Provider, key string, w [2]int64, h hash.Hash) (int64, error) {
+	v.summary.Requests++
+	body, _, err := p.(provider.ObjectRanger).GetRange(ctx, key, w[0], w[1])
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = body.Close() }()
+	n, err := v.copy(ctx, h, body)
+	if err == nil && n != w[1]-w[0]+1 {
+		err = fmt.Errorf("range %d-%d returned %d bytes", w[0], w[1], n)
+	}
+	return n, err
+}
+
+// copy streams r into h, pacing reads through the bandwidth limiter.
+func (v *inspectPairContentVerifier) copy(ctx context.Context, h hash.Hash, r io.Reader) (int64, error) {
+	buf := make([]byte, 32*1024)
+	if v.limiter != nil && v.limiter.Burst() < len(buf) {
+		buf = buf[:v.limiter.Burst()]
+	}
+	var total int64
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if v.limiter != nil {
+				if werr := v.limiter.WaitN(ctx, n); werr != nil {
+					return total, werr
+				}
+			}
+			_, _ = h.Write(buf[:n])
+			total += int64(n)
+			v.summary.BytesRead += int64(n)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+func (v *inspectPairContentVerifier) sourceProvider(ctx context.Context, sourceURI string) (provider.Provider, string, error) {
+	src, err := uri.ParseURI(sourceURI)
+	if err != nil {
+		return nil, "", err
+	}
+	scope := inspectPairScope{Provider: src.Provider, Bucket: src.Bucket}
+	key := src.Key
+	if src.Provider == string(provider.ProviderFile) {
+		path := filepath.Clean(filepath.FromSlash(src.Key))
+		scope = inspectPairScope{Provider: src.Provider, FileRoot: filepath.Dir(path)}
+		key = filepath.Base(path)
+	}
+	p, err := inspectPairProviderForScope(ctx, scope, v.providers, v.factory)
+	if err != nil {
+		return nil, "", err
+	}
+	return p, key, nil
+}
+
+// inspectPairSampleWindows returns the inclusive byte ranges sampled for an
+// object of size bytes. Windows are evenly spaced across the object and always
+// include the first and last window, so truncation and header or trailer
+// corruption are caught even at low percentages.
+func inspectPairSampleWindows(size int64, percent float64) [][2]int64 {
+	if size <= 0 {
+		return nil
+	}
+	total := (size + inspectPairSampleWindowBytes - 1) / inspectPairSampleWindowBytes
+	want := int64(math.Ceil(float64(size) * percent / 100 / float64(inspectPairSampleWindowBytes)))
+	want = max(want, min(total, 2))
+	want = min(want, total)
+
+	windows := make([][2]int64, 0, want)
+	for i := range want {
+		idx := int64(0)
+		if want > 1 {
+			idx = int64(math.Round(float64(i) * float64(total-1) / float64(want-1)))
+		}
+		start := idx * inspectPairSampleWindowBytes
+		end := min(start+inspectPairSampleWindowBytes, size) - 1
+		windows = append(windows, [2]int64{start, end})
+	}
+	return windows
+}
+
+var newInspectPairSourceProvider inspectPairProviderFactory = func(ctx context.Context, scope inspectPairScope) (provider.Provider, error) {
+	return providerdispatch.NewSource(ctx, &uri.ObjectURI{Provider: scope.Provider, Bucket: scope.Bucket}, providerdispatch.SourceOptions{
+		Command:     "inspect-pair",
+		FileBaseDir: scope.FileRoot,
+		S3: providerdispatch.S3Options{
+			Region:         inspectPairSrcRegion,
+			Endpoint:       inspectPairSrcEndpoint,
+			Profile:        inspectPairSrcProfile,
+			ForcePathStyle: inspectPairSrcEndpoint != "",
+		},
+		GCS: providerdispatch.GCSOptions{
+			Project: inspectPairSrcGCPProject,
+		},
+	})
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/signing"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// inspectPairContentMock serves object bytes for HEAD, GET, and ranged GET.
+type inspectPairContentMock struct {
+	inspectPairMockProvider
+	content    map[string][]byte
+	rangeCalls []string
+}
+
+func newInspectPairContentMock(content map[string][]byte) *inspectPairContentMock {
+	m := &inspectPairContentMock{content: content}
+	m.meta = map[string]provider.ObjectMeta{}
+	for key, body := range content {
+		m.meta[key] = provider.ObjectMeta{ObjectSummary: provider.ObjectSummary{Key: key, Size: int64(len(body))}}
+	}
+	return m
+}
+
+func (m *inspectPairContentMock) GetObject(_ context.Context, key string) (io.ReadCloser, int64, error) {
+	body, ok := m.content[key]
+	if !ok {
+		return nil, 0, &provider.ProviderError{Op: "GetObject", Provider: provider.ProviderS3, Key: key, Err: provider.ErrNotFound}
+	}
+	return io.NopCloser(bytes.NewReader(body)), int64(len(body)), nil
+}
+
+func (m *inspectPairContentMock) GetRange(_ context.Context, key string, start, endInclusive int64) (io.ReadCloser, int64, error) {
+	m.rangeCalls = append(m.rangeCalls, key)
+	body, ok := m.content[key]
+	if !ok {
+		return nil, 0, &provider.ProviderError{Op: "GetRange", Provider: provider.ProviderS3, Key: key, Err: provider.ErrNotFound}
+	}
+	window := body[start : endInclusive+1]
+	return io.NopCloser(bytes.NewReader(window)), int64(len(window)), nil
+}
+
+func runInspectPairContent(t *testing.T, src, dst provider.Provider, lines []string, opts inspectPairOptions) (string, error) {
+	t.Helper()
+	opts.UseStdin = true
+	opts.ExpectedDestPrefixes = []string{"s3://dst/root/"}
+	opts.ProviderFactory = func(context.Context, inspectPairScope) (provider.Provider, error) { return dst, nil }
+	opts.SourceProviderFactory = func(_ context.Context, scope inspectPairScope) (provider.Provider, error) {
+		require.Equal(t, "src", scope.Bucket)
+		return src, nil
+	}
+	var stdout bytes.Buffer
+	err := runInspectPair(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &stdout, opts)
+	return stdout.String(), err
+}
+
+func contentPairLine(name string, size int) string {
+	return reflowLine(map[string]any{
+		"status":            "complete",
+		"source_uri":        "s3://src/" + name,
+		"dest_uri":          "s3://dst/root/" + name,
+		"source_size_bytes": size,
+	})
+}
+
+func TestInspectPairContentFullDetectsMismatch(t *testing.T) {
+	src := newInspectPairContentMock(map[string][]byte{
+		"same.bin":    []byte("identical bytes"),
+		"corrupt.bin": []byte("original bytes!"),
+	})
+	dst := newInspectPairContentMock(map[string][]byte{
+		"root/same.bin":    []byte("identical bytes"),
+		"root/corrupt.bin": []byte("0riginal bytes!"),
+	})
+
+	stdout, err := runInspectPairContent(t, src, dst, []string{
+		contentPairLine("same.bin", 15),
+		contentPairLine("corrupt.bin", 15),
+	}, inspectPairOptions{Verify: "content=full"})
+	require.Error(t, err)
+
+	records, summary := decodeInspectPairOutput(t, stdout)
+	require.Len(t, records, 2)
+	sum := sha256.Sum256([]byte("identical bytes"))
+	require.Equal(t, "verified", records[0].Verdict)
+	require.Equal(t, "match", records[0].ContentVerdict)
+	require.Equal(t, "full", records[0].ContentMode)
+	require.Equal(t, hex.EncodeToString(sum[:]), records[0].SourceSHA256)
+	require.Equal(t, records[0].SourceSHA256, records[0].DestSHA256)
+	require.Equal(t, int64(15), records[0].ContentBytesCompared)
+
+	require.Equal(t, "content_mismatch", records[1].Verdict)
+	require.Equal(t, "mismatch", records[1].ContentVerdict)
+	require.Equal(t, "sha256_differs", records[1].ContentReason)
+	require.NotEqual(t, records[1].SourceSHA256, records[1].DestSHA256)
+
+	require.Equal(t, int64(1), summary.Verified)
+	require.Equal(t, int64(1), summary.ContentMismatch)
+	require.Equal(t, summary.Total, summary.Verified+summary.VerifiedSizeETagDiffers+summary.SizeMismatch+
+		summary.ContentMismatch+summary.Missing+summary.Error+summary.InvalidDest+summary.NotVerified,
+		"every counted verdict lands in a bucket")
+	require.NotNil(t, summary.Content)
+	require.Equal(t, inspectPairContentSummary{Mode: "full", Match: 1, Mismatch: 1, BytesRead: 60, Requests: 4}, *summary.Content)
+	require.ErrorContains(t, err, "content_mismatch=1")
+}
+
+func TestInspectPairContentSampleReadsEdgeWindows(t *testing.T) {
+	size := int(5 * inspectPairSampleWindowBytes)
+	original := bytes.Repeat([]byte{'a'}, size)
+	middleCorrupt := append([]byte(nil), original...)
+	middleCorrupt[2*inspectPairSampleWindowBytes+10] = 'b'
+	tailCorrupt := append([]byte(nil), original...)
+	tailCorrupt[size-1] = 'b'
+
+	src := newInspectPairContentMock(map[string][]byte{"middle.bin": original, "tail.bin": original})
+	dst := newInspectPairContentMock(map[string][]byte{"root/middle.bin": middleCorrupt, "root/tail.bin": tailCorrupt})
+
+	stdout, err := runInspectPairContent(t, src, dst, []string{
+		contentPairLine("middle.bin", size),
+		contentPairLine("tail.bin", size),
+	}, inspectPairOptions{Verify: "content=sample:20%"})
+	require.Error(t, err)
+
+	records, summary := decodeInspectPairOutput(t, stdout)
+	require.Len(t, records, 2)
+	// 20% of five windows is one; the first and last windows are always read.
+	require.Equal(t, "match", records[0].ContentVerdict, "unsampled middle window is not read")
+	require.Equal(t, 2, records[0].ContentWindows)
+	require.Equal(t, 2*inspectPairSampleWindowBytes, records[0].ContentBytesCompared)
+	require.Equal(t, "sample:20%", records[0].ContentMode)
+	require.Equal(t, "mismatch", records[1].ContentVerdict)
+	require.Len(t, dst.rangeCalls, 4)
+	require.Equal(t, int64(8), summary.Content.Requests)
+}
+
+func TestInspectPairContentBudgetAndCapabilities(t *testing.T) {
+	src := newInspectPairContentMock(map[string][]byte{
+		"a.bin": []byte("aaaa"),
+		"b.bin": []byte("bbbb"),
+		"c.bin": []byte("cc"),
+	})
+	dst := newInspectPairContentMock(map[string][]byte{
+		"root/a.bin": []byte("aaaa"),
+		"root/b.bin": []byte("bbbb"),
+		"root/c.bin": []byte("cc"),
+	})
+
+	// a fits (8 bytes), b would exceed 12 bytes, c still fits (4 bytes).
+	stdout, err := runInspectPairContent(t, src, dst, []string{
+		contentPairLine("a.bin", 4),
+		contentPairLine("b.bin", 4),
+		contentPairLine("c.bin", 2),
+	}, inspectPairOptions{Verify: "content=full", ContentBudget: inspectPairContentBudget{MaxBytes: 12, BytesPerSecond: 1 << 20}})
+	require.NoError(t, err)
+	records, summary := decodeInspectPairOutput(t, stdout)
+	require.Equal(t, []string{"match", "budget_exhausted", "match"},
+		[]string{records[0].ContentVerdict, records[1].ContentVerdict, records[2].ContentVerdict})
+	require.Equal(t, "verified", records[1].Verdict)
+	require.Equal(t, int64(1), summary.Content.BudgetExhausted)
+	require.Equal(t, int64(12), summary.Content.BytesRead)
+
+	headOnly := &inspectPairMockProvider{meta: map[string]provider.ObjectMeta{
+		"root/a.bin": {ObjectSummary: provider.ObjectSummary{Key: "root/a.bin", Size: 4}},
+	}}
+	stdout, err = runInspectPairContent(t, src, headOnly, []string{contentPairLine("a.bin", 4)}, inspectPairOptions{Verify: "content=sample:50%"})
+	require.NoError(t, err)
+	records, _ = decodeInspectPairOutput(t, stdout)
+	require.Equal(t, "not_verified", records[0].ContentVerdict)
+	require.Equal(t, "ranged_reads_unsupported", records[0].ContentReason)
+}
+
+func TestInspectPairSignedSummaryVerifies(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	src := newInspectPairContentMock(map[string][]byte{"a.bin": []byte("payload")})
+	dst := newInspectPairContentMock(map[string][]byte{"root/a.bin": []byte("payload")})
+
+	stdout, err := runInspectPairContent(t, src, dst, []string{
+		contentPairLine("a.bin", 7),
+		reflowLine(map[string]any{"status": "skipped", "source_uri": "s3://src/b.bin", "dest_uri": "s3://dst/root/b.bin", "reason": "collision.duplicate"}),
+	}, inspectPairOptions{Verify: "content=full", SignKey: key})
+	require.NoError(t, err)
+
+	recordsHash := sha256.New()
+	var summaryData json.RawMessage
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		var env output.Record
+		require.NoError(t, json.Unmarshal([]byte(line), &env))
+		switch env.Type {
+		case inspectPairRecordType:
+			var generic any
+			require.NoError(t, json.Unmarshal(env.Data, &generic))
+			payload, err := signing.CanonicalJSON(generic)
+			require.NoError(t, err)
+			recordsHash.Write(append(payload, '\n'))
+		case inspectPairSummaryType:
+			summaryData = env.Data
+		}
+	}
+
+	var summary map[string]any
+	require.NoError(t, json.Unmarshal(summaryData, &summary))
+	require.Equal(t, hex.EncodeToString(recordsHash.Sum(nil)), summary["records_sha256"])
+	rawSig, err := json.Marshal(summary["signature"])
+	require.NoError(t, err)
+	var sig signing.Signature
+	require.NoError(t, json.Unmarshal(rawSig, &sig))
+	delete(summary, "signature")
+	require.NoError(t, signing.Verify(&sig, summary))
+
+	summary["total"] = 1
+	require.ErrorIs(t, signing.Verify(&sig, summary), signing.ErrInvalidSignature)
+}
+
+func TestParseInspectPairVerify(t *testing.T) {
+	spec, err := parseInspectPairVerify("")
+	require.NoError(t, err)
+	require.False(t, spec.enabled())
+	spec, err = parseInspectPairVerify("content=sample:2.5%")
+	require.NoError(t, err)
+	require.Equal(t, inspectPairVerifySpec{Mode: inspectPairContentSample, SamplePercent: 2.5}, spec)
+	require.Equal(t, "sample:2.5%", spec.String())
+
+	for _, raw := range []string{"content", "content=sample:0%", "content=sample:101%", "content=sample:5", "content=partial", "full"} {
+		_, err := parseInspectPairVerify(raw)
+		require.Error(t, err, raw)
+	}
+}
+
+func TestInspectPairSampleWindows(t *testing.T) {
+	w := inspectPairSampleWindowBytes
+	require.Nil(t, inspectPairSampleWindows(0, 10))
+	require.Equal(t, [][2]int64{{0, 99}}, inspectPairSampleWindows(100, 1))
+	require.Equal(t, [][2]int64{{0, w - 1}, {w, w + 9}}, inspectPairSampleWindows(w+10, 1))
+	require.Equal(t, [][2]int64{{0, w - 1}, {5 * w, 6*w - 1}, {10 * w, 11*w - 1}}, inspectPairSampleWindows(11*w, 25))
+	require.Len(t, inspectPairSampleWindows(4*w, 100), 4)
+}
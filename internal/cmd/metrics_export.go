@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/3leaps/gonimbus/internal/metrics"
+	"github.com/3leaps/gonimbus/internal/observability"
+)
+
+// Environment variables honored when the matching flag is not set. A managed
+// job child receives GONIMBUS_METRICS_TEXTFILE from the job executor so the
+// server can expose the metrics of the jobs it runs.
+const (
+	metricsTextfileEnv = "GONIMBUS_METRICS_TEXTFILE"
+	metricsPushURLEnv  = "GONIMBUS_METRICS_PUSH_URL"
+)
+
+const metricsTextfileInterval = 15 * time.Second
+
+var (
+	metricsTextfile string
+	metricsPushURL  string
+
+	metricsExportStop func() error
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&metricsTextfile, "metrics-textfile", "", "Write domain metrics in Prometheus textfile format to this path (env: "+metricsTextfileEnv+")")
+	rootCmd.PersistentFlags().StringVar(&metricsPushURL, "metrics-push-url", "", "Push domain metrics to this Prometheus push gateway when the command exits (env: "+metricsPushURLEnv+")")
+	rootCmd.PersistentPreRunE = startRunTelemetry
+}
+
+// startRunTelemetry starts the optional domain metrics export and tracing for
+// the executing command.
+func startRunTelemetry(cmd *cobra.Command, args []string) error {
+	if err := startMetricsExport(cmd, args); err != nil {
+		return err
+	}
+	return startTracing(cmd)
+}
+
+// flushRunTelemetry writes terminal domain metrics and flushes traces. failed
+// marks the command's root span as an error.
+func flushRunTelemetry(failed bool) {
+	flushMetricsExport()
+	flushTracing(failed)
+}
+
+func resolvedMetricsTextfile() string {
+	if path := strings.TrimSpace(metricsTextfile); path != "" {
+		return path
+	}
+	return strings.TrimSpace(os.Getenv(metricsTextfileEnv))
+}
+
+func resolvedMetricsPushURL() string {
+	if u := strings.TrimSpace(metricsPushURL); u != "" {
+		return u
+	}
+	return strings.TrimSpace(os.Getenv(metricsPushURLEnv))
+}
+
+// startMetricsExport installs the process-local domain metrics collector when
+// a textfile or push gateway destination is configured. It is a no-op for
+// `serve`, whose metrics are scraped from the exporter directly.
+func startMetricsExport(cmd *cobra.Command, _ []string) error {
+	if cmd.Name() == "serve" {
+		return nil
+	}
+	textfile := resolvedMetricsTextfile()
+	pushURL := resolvedMetricsPushURL()
+	if textfile == "" && pushURL == "" {
+		return nil
+	}
+	collector := metrics.NewCollector()
+	metrics.SetCollector(collector)
+
+	stopTextfile := func() error { return nil }
+	if textfile != "" {
+		if abs, err := filepath.Abs(textfile); err == nil {
+			textfile = abs
+		}
+		stopTextfile = metrics.StartTextfileExporter(context.Background(), collector, textfile, metricsTextfileInterval)
+	}
+	jobName := strings.ReplaceAll(cmd.CommandPath(), " ", "_")
+	metricsExportStop = func() error {
+		err := stopTextfile()
+		if pushURL != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if pushErr := collector.Push(ctx, &http.Client{Timeout: 10 * time.Second}, pushURL, jobName); pushErr != nil && err == nil {
+				err = pushErr
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// flushMetricsExport writes the terminal domain metrics. Failures are logged,
+// never surfaced as command failures: metrics are observational.
+func flushMetricsExport() {
+	if metricsExportStop == nil {
+		return
+	}
+	stop := metricsExportStop
+	metricsExportStop = nil
+	if err := stop(); err != nil && observability.CLILogger != nil {
+		observability.CLILogger.Warn("Failed to export domain metrics", zap.Error(err))
+	}
+}
@@ -90,7 +90,8 @@ var rootCmd = &cobra.Command{
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute() error {
+func Execute() (err error) {
+	defer func() { flushRunTelemetry(err != nil) }()
 	return rootCmd.Execute()
 }
 
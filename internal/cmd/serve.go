@@ -21,8 +21,33 @@ import (
 var (
 	serverPort int
 	serverHost string
+
+	serverTLSCert      string
+	serverTLSKey       string
+	serverTLSClientCA  string
+	serverUnixSocket   string
+	serverDrainJobs    string
+	serverDrainTimeout time.Duration
 )
 
+// serveListener is how serve accepts connections: host:port or a Unix
+// socket, optionally over TLS.
+type serveListener struct {
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+	unixSocket  string
+}
+
+func currentServeListener() serveListener {
+	return serveListener{
+		tlsCert:     strings.TrimSpace(serverTLSCert),
+		tlsKey:      strings.TrimSpace(serverTLSKey),
+		tlsClientCA: strings.TrimSpace(serverTLSClientCA),
+		unixSocket:  strings.TrimSpace(serverUnixSocket),
+	}
+}
+
 // signalHealthChecker implements HealthChecker for signal system
 type signalHealthChecker struct{}
 
@@ -71,6 +96,44 @@ Signal Handling:
   • Ctrl+C twice within 2s: Force quit
   • SIGHUP: Config reload (placeholder - restart recommended)
 
+Listeners:
+  • --tls-cert/--tls-key serve HTTPS; the files are re-read when they change,
+    so a rotated certificate is picked up without a restart.
+  • --tls-client-ca requires client certificates signed by that CA bundle.
+  • --unix-socket listens on an owner-only Unix socket instead of host:port
+    (sidecar deployments). A stale socket from an unclean exit is replaced.
+  • The job control API needs a loopback host, a Unix socket, or
+    --tls-client-ca; it is never exposed to unauthenticated remote callers.
+
+Drain:
+  On shutdown the server first drains: /health/ready reports not-ready and
+  job submissions get 503, while status, logs, and reads keep working.
+  --drain-jobs then decides what happens to the background jobs this server
+  started: leave (default; they keep running as separate processes), wait
+  (until they finish, up to --drain-timeout, then checkpoint the rest), or
+  checkpoint (SIGTERM now; interrupted SQLite builds can continue with
+  index build --resume-run). Checkpointed jobs and their resume commands are
+  logged.
+
+Schedules:
+  With the job API enabled, POST /api/v1/schedules registers a recurring
+  index build (cron expression, job template, overlap policy, jitter, and
+  catch-up). Schedules are stored under the jobs root, survive restarts, and
+  run only while a server is up; drain stops them before jobs are handled.
+
+Job queue:
+  Submitted and scheduled builds wait in the job queue until it admits them
+  under the jobs.queue.* limits (max_running, max_per_bucket,
+  max_per_endpoint, memory_budget). The server admits waiting jobs every few
+  seconds, including jobs queued by the CLI or left waiting by a stopped
+  server. Drain stops admission; waiting jobs stay queued.
+
+Job hooks:
+  The jobs.hooks config list delivers job lifecycle events (queued, running,
+  succeeded, partial, failed_resumable, failed) to HMAC-signed webhooks or
+  local commands, with retry and backoff. Deliveries are recorded on the job;
+  see gonimbus index jobs hooks.
+
 The server will cleanly shut down the HTTP server and flush logs on shutdown.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get app identity for telemetry namespace
@@ -112,7 +175,10 @@ The server will cleanly shut down the HTTP server and flush logs on shutdown.`,
 			configName: identity.ConfigName,
 		})
 
-		serverOpts, err := serveServerOptions(cmd.Context(), serverHost)
+		if err := server.ValidateDrainJobs(serverDrainJobs); err != nil {
+			return errwrap.NewConfigInvalidError(err.Error())
+		}
+		serverOpts, err := serveServerOptions(cmd.Context(), serverHost, currentServeListener())
 		if err != nil {
 			return err
 		}
@@ -141,8 +207,22 @@ The server will cleanly shut down the HTTP server and flush logs on shutdown.`,
 			return nil
 		})
 
-		// Handler 2: Shutdown HTTP server (executed first)
+		// Handler 2: Drain, then shut down the HTTP server (executed first)
 		signals.OnShutdown(func(ctx context.Context) error {
+			result, err := srv.Drain(ctx, server.DrainOptions{
+				Jobs:        serverDrainJobs,
+				Timeout:     serverDrainTimeout,
+				StopTimeout: shutdownTimeout,
+			})
+			if err != nil {
+				observability.ServerLogger.Error("Drain failed; shutting down anyway", zap.Error(err))
+			} else {
+				observability.ServerLogger.Info("Drain complete",
+					zap.Int("jobs_finished", result.Finished),
+					zap.Int("jobs_checkpointed", len(result.Checkpointed)),
+					zap.Strings("jobs_left_running", result.Left))
+			}
+
 			observability.ServerLogger.Info("Shutting down HTTP server...")
 			shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
 			defer cancel()
@@ -196,12 +276,17 @@ The server will cleanly shut down the HTTP server and flush logs on shutdown.`,
 		go func() {
 			observability.ServerLogger.Info("Starting HTTP server...",
 				zap.String("host", serverHost),
-				zap.Int("port", serverPort))
+				zap.Int("port", serverPort),
+				zap.String("unix_socket", serverUnixSocket),
+				zap.Bool("tls", serverOpts.TLS != nil))
 			if err := srv.Start(); err != nil && err != http.ErrServerClosed {
 				errChan <- err
 			}
 		}()
 
+		// Run due schedules (POST /api/v1/schedules) until drain.
+		srv.StartScheduler()
+
 		// Start signal listener in background
 		go func() {
 			if err := listenForServeSignals(cmd.Context(), errChan, signals.Listen); err != nil {
@@ -230,13 +315,26 @@ func init() {
 	serveCmd.Flags().StringVar(&serverHost, "host", "localhost", "server host")
 	serveCmd.Flags().IntVarP(&serverPort, "port", "p", 8080, "server port")
 
+	serveCmd.Flags().StringVar(&serverTLSCert, "tls-cert", "", "TLS certificate file (PEM); serves HTTPS, reloaded on change")
+	serveCmd.Flags().StringVar(&serverTLSKey, "tls-key", "", "TLS private key file (PEM) for --tls-cert")
+	serveCmd.Flags().StringVar(&serverTLSClientCA, "tls-client-ca", "", "CA bundle (PEM); require client certificates signed by it")
+	serveCmd.Flags().StringVar(&serverUnixSocket, "unix-socket", "", "Listen on this Unix socket path instead of host:port")
+	serveCmd.Flags().StringVar(&serverDrainJobs, "drain-jobs", server.DrainJobsLeave, "On shutdown, what to do with jobs this server started: leave, wait, or checkpoint")
+	serveCmd.Flags().DurationVar(&serverDrainTimeout, "drain-timeout", 5*time.Minute, "With --drain-jobs wait, how long to wait before checkpointing remaining jobs (0 = no limit)")
+
 	_ = viper.BindPFlag("server.host", serveCmd.Flags().Lookup("host"))
 	_ = viper.BindPFlag("server.port", serveCmd.Flags().Lookup("port"))
 }
 
-func serveServerOptions(ctx context.Context, host string) (server.Options, error) {
-	if !isLoopbackServeHost(host) {
-		return server.Options{}, errwrap.NewConfigInvalidError("local job control API requires a loopback serve host; use --host localhost or --host 127.0.0.1")
+func serveServerOptions(ctx context.Context, host string, listener serveListener) (server.Options, error) {
+	tlsOpts, err := listener.tlsOptions()
+	if err != nil {
+		return server.Options{}, err
+	}
+	// The job control API starts processes, so it is served only where callers
+	// are local or authenticated: loopback, a Unix socket, or mutual TLS.
+	if listener.unixSocket == "" && listener.tlsClientCA == "" && !isLoopbackServeHost(host) {
+		return server.Options{}, errwrap.NewConfigInvalidError("local job control API requires a loopback serve host; use --host localhost or --host 127.0.0.1, --unix-socket, or --tls-client-ca")
 	}
 	jobsRoot, err := indexJobsRootDir()
 	if err != nil {
@@ -246,7 +344,32 @@ func serveServerOptions(ctx context.Context, host string) (server.Options, error
 	if err != nil {
 		return server.Options{}, errwrap.WrapInternal(ctx, err, "resolve job runner invocation")
 	}
-	return server.Options{JobsRoot: jobsRoot, JobsInvocation: &invocation}, nil
+	queueLimits, err := resolveJobQueueLimits()
+	if err != nil {
+		return server.Options{}, errwrap.NewConfigInvalidError(err.Error())
+	}
+	jobHooks, err := resolveJobHooks()
+	if err != nil {
+		return server.Options{}, errwrap.NewConfigInvalidError(err.Error())
+	}
+	indexOpts, err := indexReaderResolveOptions()
+	if err != nil {
+		return server.Options{}, errwrap.WrapInternal(ctx, err, "resolve index roots")
+	}
+	return server.Options{JobsRoot: jobsRoot, JobsInvocation: &invocation, JobsQueue: queueLimits, JobHooks: jobHooks, Indexes: &indexOpts, TLS: tlsOpts, UnixSocket: listener.unixSocket}, nil
+}
+
+func (l serveListener) tlsOptions() (*server.TLSOptions, error) {
+	switch {
+	case l.tlsCert == "" && l.tlsKey == "":
+		if l.tlsClientCA != "" {
+			return nil, errwrap.NewConfigInvalidError("--tls-client-ca requires --tls-cert and --tls-key")
+		}
+		return nil, nil
+	case l.tlsCert == "" || l.tlsKey == "":
+		return nil, errwrap.NewConfigInvalidError("--tls-cert and --tls-key must be set together")
+	}
+	return &server.TLSOptions{CertFile: l.tlsCert, KeyFile: l.tlsKey, ClientCAFile: l.tlsClientCA}, nil
 }
 
 func isLoopbackServeHost(host string) bool {
@@ -153,8 +153,30 @@ func TestIsLoopbackServeHost(t *testing.T) {
 }
 
 func TestServeServerOptionsRejectsNonLoopbackHost(t *testing.T) {
-	_, err := serveServerOptions(t.Context(), "0.0.0.0")
+	_, err := serveServerOptions(t.Context(), "0.0.0.0", serveListener{})
 
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "local job control API requires a loopback serve host")
 }
+
+func TestServeServerOptionsListenerRules(t *testing.T) {
+	resetAppDataRootTestState(t)
+	t.Setenv("GONIMBUS_DATA_DIR", t.TempDir())
+
+	opts, err := serveServerOptions(t.Context(), "0.0.0.0", serveListener{unixSocket: "/run/gonimbus.sock"})
+	require.NoError(t, err, "a unix socket is local")
+	require.Equal(t, "/run/gonimbus.sock", opts.UnixSocket)
+	require.Nil(t, opts.TLS)
+
+	opts, err = serveServerOptions(t.Context(), "0.0.0.0", serveListener{tlsCert: "c.pem", tlsKey: "k.pem", tlsClientCA: "ca.pem"})
+	require.NoError(t, err, "mutual TLS authenticates remote callers")
+	require.Equal(t, "ca.pem", opts.TLS.ClientCAFile)
+
+	_, err = serveServerOptions(t.Context(), "0.0.0.0", serveListener{tlsCert: "c.pem", tlsKey: "k.pem"})
+	require.ErrorContains(t, err, "requires a loopback serve host", "server-only TLS does not authenticate callers")
+
+	_, err = serveServerOptions(t.Context(), "localhost", serveListener{tlsCert: "c.pem"})
+	require.ErrorContains(t, err, "must be set together")
+	_, err = serveServerOptions(t.Context(), "localhost", serveListener{tlsClientCA: "ca.pem"})
+	require.ErrorContains(t, err, "requires --tls-cert")
+}
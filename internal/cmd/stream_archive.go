@@ -0,0 +1,521 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fulmenhq/gofulmen/foundry"
+	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/transfer"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+const (
+	streamArchiveRecordType   = "gonimbus.stream.archive.v1"
+	streamArchiveManifestName = "gonimbus-manifest.jsonl"
+
+	streamArchiveFormatTar    = "tar"
+	streamArchiveFormatTarZst = "tar.zst"
+	streamArchiveFormatZip    = "zip"
+)
+
+var streamArchiveCmd = &cobra.Command{
+	Use:   "archive [<uri>]",
+	Short: "Write a selection of objects as one tar, tar.zst, or zip archive",
+	Long: `Write a selection of objects as one streaming archive.
+
+Selection:
+- A prefix or glob URI (s3/gs) archives every listed object.
+- --stdin reads one input per line: an exact, prefix, or glob URI, a
+  gonimbus.index.object.v1 record (index query output), a
+  gonimbus.reflow.input.v1 record, or, with --base-uri naming the crawled
+  bucket, a gonimbus.object.v1 record (crawl output).
+
+Archive:
+- Entries are named by object key, in input order, with mtimes from LastModified.
+- A final gonimbus-manifest.jsonl entry lists every selected object's path,
+  URI, size, ETag, and status.
+- Objects are fetched --concurrency at a time, buffered ahead within
+  --max-buffer-bytes, and written one at a time.
+
+Output:
+- Without --dest the archive is written to stdout and JSONL records go to stderr.
+- With --dest the archive is uploaded (multipart above --multipart-threshold)
+  and JSONL records go to stdout. Existing destinations are refused unless
+  --overwrite is set.
+
+An object that cannot be fetched is left out, reported as an error record and
+in the manifest, and the command exits non-zero. So is an object whose body
+length differs from its listed size; an entry already started for it is cut
+or zero-padded to that size. Any other failure while an entry is being
+written leaves the archive unusable, so it stops the run; an upload is
+aborted.
+`,
+	Args:         validateStreamArchiveArgs,
+	RunE:         runStreamArchive,
+	SilenceUsage: true,
+}
+
+var (
+	streamArchiveFormat         string
+	streamArchiveStdin          bool
+	streamArchiveBaseURI        string
+	streamArchiveDest           string
+	streamArchiveOverwrite      bool
+	streamArchiveConcurrency    int
+	streamArchiveMaxBufferBytes int64
+	streamArchivePartSize       string
+	streamArchiveThreshold      string
+	streamArchiveRegion         string
+	streamArchiveProfile        string
+	streamArchiveEndpoint       string
+	streamArchiveGCPProject     string
+)
+
+func init() {
+	streamCmd.AddCommand(streamArchiveCmd)
+
+	streamArchiveCmd.Flags().StringVar(&streamArchiveFormat, "format", streamArchiveFormatTar, "Archive format (tar, tar.zst, or zip)")
+	streamArchiveCmd.Flags().BoolVar(&streamArchiveStdin, "stdin", false, "Read URIs or index/reflow-input JSONL from stdin (one per line)")
+	streamArchiveCmd.Flags().StringVar(&streamArchiveBaseURI, "base-uri", "", "Bucket URI that stdin crawl records (gonimbus.object.v1) are keys in, e.g. s3://bucket/")
+	streamArchiveCmd.Flags().StringVar(&streamArchiveDest, "dest", "", "Destination object URI for the archive (default: stdout)")
+	streamArchiveCmd.Flags().BoolVar(&streamArchiveOverwrite, "overwrite", false, "Allow overwriting an existing destination object")
+	streamArchiveCmd.Flags().IntVar(&streamArchiveConcurrency, "concurrency", defaultStreamGetConcurrency, "Max concurrent object fetches")
+	streamArchiveCmd.Flags().Int64Var(&streamArchiveMaxBufferBytes, "max-buffer-bytes", defaultStreamGetMaxBufferBytes, "Max bytes buffered ahead of the entry being written")
+	streamArchiveCmd.Flags().StringVar(&streamArchivePartSize, "part-size", "8MiB", "Multipart upload part size in bytes or KiB/MiB/GiB")
+	streamArchiveCmd.Flags().StringVar(&streamArchiveThreshold, "multipart-threshold", "64MiB", "Size threshold for switching to multipart upload")
+	streamArchiveCmd.Flags().StringVarP(&streamArchiveRegion, "region", "r", "", "AWS region")
+	streamArchiveCmd.Flags().StringVarP(&streamArchiveProfile, "profile", "p", "", "AWS profile")
+	streamArchiveCmd.Flags().StringVar(&streamArchiveEndpoint, "endpoint", "", "Custom S3 endpoint")
+	streamArchiveCmd.Flags().StringVar(&streamArchiveGCPProject, "gcp-project", "", "GCP project hint for GCS")
+}
+
+func validateStreamArchiveArgs(cmd *cobra.Command, args []string) error {
+	stdin, _ := cmd.Flags().GetBool("stdin")
+	if stdin {
+		if len(args) != 0 {
+			return fmt.Errorf("when using --stdin, do not provide <uri> arguments")
+		}
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("requires exactly 1 argument: <uri> (or use --stdin)")
+	}
+	return nil
+}
+
+// streamArchiveEntry is one line of the archive manifest.
+type streamArchiveEntry struct {
+	Path         string     `json:"path,omitempty"`
+	URI          string     `json:"uri"`
+	Size         int64      `json:"size"`
+	ETag         string     `json:"etag,omitempty"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	Status       string     `json:"status"`
+	Error        string     `json:"error,omitempty"`
+}
+
+type streamArchiveRecord struct {
+	Format     string `json:"format"`
+	DestURI    string `json:"dest_uri,omitempty"`
+	Entries    int    `json:"entries"`
+	Failed     int    `json:"failed"`
+	Bytes      int64  `json:"bytes"`
+	ETag       string `json:"etag,omitempty"`
+	UploadMode string `json:"upload_mode,omitempty"`
+	Status     string `json:"status"`
+}
+
+func runStreamArchive(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(strings.TrimSpace(streamArchiveFormat))
+	switch format {
+	case streamArchiveFormatTar, streamArchiveFormatTarZst, streamArchiveFormatZip:
+	default:
+		return exitError(foundry.ExitInvalidArgument, "Invalid --format value", fmt.Errorf("stream archive supports tar, tar.zst, or zip"))
+	}
+	if streamArchiveConcurrency < 1 {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --concurrency value", fmt.Errorf("concurrency must be >= 1"))
+	}
+	if streamArchiveMaxBufferBytes < 1 {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --max-buffer-bytes value", fmt.Errorf("max-buffer-bytes must be >= 1"))
+	}
+	var crawlBase *uri.ObjectURI
+	if streamArchiveBaseURI != "" {
+		base, err := uri.ParseURI(streamArchiveBaseURI)
+		if err == nil && (base.Provider == string(provider.ProviderFile) || strings.Trim(base.Key, "/") != "") {
+			err = fmt.Errorf("base-uri must name an s3 or gs bucket, e.g. s3://bucket/")
+		}
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --base-uri value", err)
+		}
+		crawlBase = base
+	}
+
+	inputs := args
+	if streamArchiveStdin {
+		lines, err := readURILines(cmd.InOrStdin())
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Failed to read stdin", err)
+		}
+		inputs = lines
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	jobID := uuid.New().String()
+	recordsOut := cmd.ErrOrStderr()
+	if streamArchiveDest != "" {
+		recordsOut = cmd.OutOrStdout()
+	}
+	w := output.NewJSONLWriter(recordsOut, jobID, commandOutputProviderForInputs(inputs, string(provider.ProviderS3)))
+	defer func() { _ = w.Close() }()
+
+	sink := cmd.OutOrStdout()
+	var dest *outputDestSpec
+	var session *transfer.UploadSession
+	if streamArchiveDest != "" {
+		partSize, err := parseStreamPutSize(streamArchivePartSize, streamPutDefaultPart)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --part-size value", err)
+		}
+		if partSize <= 0 {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --part-size value", errors.New("part size must be > 0"))
+		}
+		threshold, err := parseStreamPutSize(streamArchiveThreshold, streamPutDefaultTrigger)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --multipart-threshold value", err)
+		}
+		if threshold < 0 {
+			return exitError(foundry.ExitInvalidArgument, "Invalid --multipart-threshold value", errors.New("multipart threshold must be >= 0"))
+		}
+		dest, err = parseOutputDest(streamArchiveDest)
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Invalid destination URI", err)
+		}
+		dest.Region = streamArchiveRegion
+		dest.Profile = streamArchiveProfile
+		dest.Endpoint = streamArchiveEndpoint
+		dest.ForcePathStyle = streamArchiveEndpoint != ""
+		dest.GCPProject = streamArchiveGCPProject
+		putter, err := newOutputProvider(ctx, dest)
+		if err != nil {
+			_ = emitStreamPutError(ctx, w, dest.Key, output.ErrCodeProviderUnavailable, "Failed to connect to storage provider", err, nil)
+			return exitError(foundry.ExitExternalServiceUnavailable, "Failed to connect to storage provider", err)
+		}
+		if closer, ok := putter.(io.Closer); ok {
+			defer func() { _ = closer.Close() }()
+		}
+		opts := streamPutUploadOptions{PartSize: partSize, MultipartThreshold: threshold, Overwrite: streamArchiveOverwrite}
+		session, err = transfer.NewUploadSession(ctx, putter, dest.Key, streamPutTransferOptions(outputDestURI(dest), opts, w))
+		if err != nil {
+			return exitError(foundry.ExitFileWriteError, "Failed to start archive upload", err)
+		}
+		sink = session
+	}
+
+	counted := &streamArchiveCountingWriter{w: sink}
+	aw, err := newStreamArchiveWriter(format, counted)
+	if err != nil {
+		if session != nil {
+			_ = session.Abort(ctx)
+		}
+		return exitError(foundry.ExitFileWriteError, "Failed to start archive", err)
+	}
+	a := &streamArchive{aw: aw, seen: map[string]bool{streamArchiveManifestName: true}}
+	b := &streamGetBatch{
+		command:     "stream archive",
+		source:      streamGetSourceFlags{region: streamArchiveRegion, profile: streamArchiveProfile, endpoint: streamArchiveEndpoint, gcpProject: streamArchiveGCPProject},
+		allowPrefix: true,
+		crawlBase:   crawlBase,
+		errOut:      w,
+		providers:   map[string]provider.Provider{},
+		cancel:      cancel,
+		onFail:      a.failed,
+	}
+	defer b.close()
+
+	b.runOrdered(ctx, b.startInputs(ctx, inputs, streamArchiveStdin), streamArchiveConcurrency, streamArchiveMaxBufferBytes, func(ctx context.Context, o streamGetObject, res *streamGetResolved, open func(i int) (io.ReadCloser, int64, error)) {
+		b.wrote(a.add(ctx, b, o, res.meta, func() (io.ReadCloser, int64, error) { return open(0) }))
+	})
+
+	archiveErr := b.firstWriteErr()
+	if archiveErr == nil && cmd.Context().Err() == nil {
+		archiveErr = a.finish()
+	}
+	rec := streamArchiveRecord{Format: format, Entries: a.written, Failed: a.failures, Status: "success"}
+	if dest != nil {
+		rec.DestURI = outputDestURI(dest)
+	}
+	if archiveErr == nil && cmd.Context().Err() == nil && session != nil {
+		result, err := session.Close(ctx)
+		if err != nil {
+			_ = session.Abort(ctx)
+			return streamPutUploadExit(ctx, w, dest, err)
+		}
+		rec.ETag, rec.UploadMode = result.ETag, result.Mode
+		session = nil
+	}
+	if session != nil {
+		_ = session.Abort(context.WithoutCancel(ctx))
+	}
+	rec.Bytes = counted.n
+
+	if archiveErr != nil {
+		rec.Status = "error"
+		_ = emitStreamPutError(ctx, w, "", output.ErrCodeInternal, "Archive failed", archiveErr, map[string]any{"format": format})
+		_ = w.WriteAny(context.WithoutCancel(ctx), streamArchiveRecordType, &rec)
+		return exitError(foundry.ExitFileWriteError, "Failed to write archive", archiveErr)
+	}
+	if err := cmd.Context().Err(); err != nil {
+		return exitError(foundry.ExitSignalInt, "stream archive cancelled", err)
+	}
+	if err := w.WriteAny(ctx, streamArchiveRecordType, &rec); err != nil {
+		return exitError(foundry.ExitFileWriteError, "Failed to write archive record", err)
+	}
+	if n := b.invalid.Load(); n > 0 {
+		return exitError(foundry.ExitInvalidArgument, "stream archive completed with invalid inputs", fmt.Errorf("invalid_inputs=%d", n))
+	}
+	if n := b.failed.Load(); n > 0 {
+		return exitError(foundry.ExitExternalServiceUnavailable, "stream archive completed with errors", fmt.Errorf("errors=%d", n))
+	}
+	return nil
+}
+
+// streamArchive assembles the archive from a batch's objects, which arrive one
+// at a time in input order.
+type streamArchive struct {
+	aw       streamArchiveWriter
+	seen     map[string]bool
+	manifest []streamArchiveEntry
+	written  int
+	failures int
+}
+
+// add writes one object as an entry. A failure before the entry is started
+// only leaves the object out, and so does a body whose length differs from
+// the size the entry was declared with: the entry is kept to its declared
+// size, so the archive stays well formed, and the object is recorded as
+// failed. An error returned here means the archive itself is broken.
+func (a *streamArchive) add(ctx context.Context, b *streamGetBatch, o streamGetObject, meta *provider.ObjectMeta, open func() (io.ReadCloser, int64, error)) error {
+	name, err := streamArchivePath(o.uri)
+	if err == nil && a.seen[name] {
+		err = &streamGetInputError{msg: "duplicate archive path: " + name}
+	}
+	if err != nil {
+		b.fail(ctx, o, err)
+		return nil
+	}
+	body, gotSize, err := open()
+	if err == nil && gotSize >= 0 && meta.Size != gotSize {
+		_ = body.Close()
+		err = &streamSizeMismatchError{Key: o.key(), Expected: meta.Size, Got: gotSize}
+	}
+	if err != nil {
+		b.fail(ctx, o, err)
+		return nil
+	}
+	defer func() { _ = body.Close() }()
+
+	mtime := meta.LastModified
+	if mtime.IsZero() {
+		mtime = time.Unix(0, 0)
+	}
+	err = a.aw.add(name, meta.Size, mtime.UTC(), body)
+	var sizeErr *streamArchiveSizeError
+	if errors.As(err, &sizeErr) {
+		a.seen[name] = true
+		b.fail(ctx, o, &streamSizeMismatchError{Key: o.key(), Expected: meta.Size, Got: sizeErr.got})
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("write entry %s: %w", name, err)
+	}
+	a.seen[name] = true
+	a.written++
+	entry := streamArchiveEntry{Path: name, URI: o.uri.String(), Size: meta.Size, ETag: meta.ETag, Status: "success"}
+	if !meta.LastModified.IsZero() {
+		lm := meta.LastModified.UTC()
+		entry.LastModified = &lm
+	}
+	a.manifest = append(a.manifest, entry)
+	return nil
+}
+
+// failed records an object the batch could not archive.
+func (a *streamArchive) failed(o streamGetObject, err error) {
+	a.failures++
+	if o.uri == nil {
+		return
+	}
+	a.manifest = append(a.manifest, streamArchiveEntry{URI: o.uri.String(), Status: "error", Error: err.Error()})
+}
+
+// finish appends the manifest entry and completes the archive.
+func (a *streamArchive) finish() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range a.manifest {
+		if err := enc.Encode(&a.manifest[i]); err != nil {
+			return err
+		}
+	}
+	if err := a.aw.add(streamArchiveManifestName, int64(buf.Len()), time.Now().UTC(), &buf); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return a.aw.Close()
+}
+
+// streamArchivePath is an object's entry name: its key, relative and clean.
+// Keys that would name a path outside the archive root are refused.
+func streamArchivePath(u *uri.ObjectURI) (string, error) {
+	key := u.Key
+	if u.Provider == string(provider.ProviderFile) {
+		key = filepath.ToSlash(key)
+	}
+	key = strings.TrimLeft(key, "/")
+	clean := path.Clean(key)
+	if key == "" || clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", &streamGetInputError{msg: "object key cannot be an archive path: " + u.Key}
+	}
+	return clean, nil
+}
+
+// streamArchiveWriter writes entries of one archive format. add writes
+// exactly size bytes; a body of any other length still completes the entry
+// and is reported as a *streamArchiveSizeError.
+type streamArchiveWriter interface {
+	add(name string, size int64, mtime time.Time, body io.Reader) error
+	Close() error
+}
+
+func newStreamArchiveWriter(format string, w io.Writer) (streamArchiveWriter, error) {
+	switch format {
+	case streamArchiveFormatZip:
+		return &zipStreamArchive{zw: zip.NewWriter(w)}, nil
+	case streamArchiveFormatTarZst:
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return &tarStreamArchive{tw: tar.NewWriter(enc), compressor: enc}, nil
+	default:
+		return &tarStreamArchive{tw: tar.NewWriter(w)}, nil
+	}
+}
+
+type tarStreamArchive struct {
+	tw         *tar.Writer
+	compressor io.WriteCloser
+}
+
+func (t *tarStreamArchive) add(name string, size int64, mtime time.Time, body io.Reader) error {
+	if err := t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  mtime,
+		Format:   tar.FormatPAX,
+	}); err != nil {
+		return err
+	}
+	n, err := copyStreamArchiveEntry(t.tw, size, body)
+	if err != nil {
+		return err
+	}
+	if n < size {
+		// The header is out; pad the entry so the next one starts where
+		// a reader expects it.
+		if _, err := io.CopyN(t.tw, streamArchiveZeros{}, size-n); err != nil {
+			return err
+		}
+	}
+	if n != size {
+		return &streamArchiveSizeError{got: n}
+	}
+	return nil
+}
+
+func (t *tarStreamArchive) Close() error {
+	err := t.tw.Close()
+	if t.compressor != nil {
+		err = errors.Join(err, t.compressor.Close())
+	}
+	return err
+}
+
+type zipStreamArchive struct {
+	zw *zip.Writer
+}
+
+func (z *zipStreamArchive) add(name string, size int64, mtime time.Time, body io.Reader) error {
+	fw, err := z.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: mtime})
+	if err != nil {
+		return err
+	}
+	n, err := copyStreamArchiveEntry(fw, size, body)
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return &streamArchiveSizeError{got: n}
+	}
+	return nil
+}
+
+func (z *zipStreamArchive) Close() error { return z.zw.Close() }
+
+// streamArchiveSizeError reports an entry body that did not match its
+// declared size.
+type streamArchiveSizeError struct {
+	got int64
+}
+
+func (e *streamArchiveSizeError) Error() string {
+	return fmt.Sprintf("source returned %d bytes", e.got)
+}
+
+// copyStreamArchiveEntry copies at most size bytes of body to w and returns
+// how many bytes body held, counting any it had past size.
+func copyStreamArchiveEntry(w io.Writer, size int64, body io.Reader) (int64, error) {
+	n, err := io.Copy(w, io.LimitReader(body, size))
+	if err != nil || n < size {
+		return n, err
+	}
+	extra, err := io.Copy(io.Discard, body)
+	return n + extra, err
+}
+
+type streamArchiveZeros struct{}
+
+func (streamArchiveZeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+type streamArchiveCountingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *streamArchiveCountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/provider/s3"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+type streamArchiveTestEntry struct {
+	name    string
+	data    string
+	modTime time.Time
+}
+
+func runStreamArchiveTest(t *testing.T, prov provider.Provider, args []string, stdin string, configure func()) (stdout, stderr []byte, err error) {
+	t.Helper()
+	restore := providerdispatch.UseFactoriesForTest(providerdispatch.Factories{
+		S3: func(context.Context, s3.Config) (provider.Provider, error) { return prov, nil },
+	})
+	t.Cleanup(restore)
+
+	old := []any{streamArchiveFormat, streamArchiveStdin, streamArchiveDest, streamArchiveOverwrite, streamArchiveConcurrency, streamArchiveMaxBufferBytes, streamArchiveBaseURI}
+	t.Cleanup(func() {
+		streamArchiveFormat = old[0].(string)
+		streamArchiveStdin = old[1].(bool)
+		streamArchiveDest = old[2].(string)
+		streamArchiveOverwrite = old[3].(bool)
+		streamArchiveConcurrency = old[4].(int)
+		streamArchiveMaxBufferBytes = old[5].(int64)
+		streamArchiveBaseURI = old[6].(string)
+	})
+	streamArchiveFormat = streamArchiveFormatTar
+	streamArchiveStdin = stdin != ""
+	streamArchiveBaseURI = ""
+	streamArchiveDest = ""
+	streamArchiveOverwrite = false
+	streamArchiveConcurrency = 4
+	streamArchiveMaxBufferBytes = defaultStreamGetMaxBufferBytes
+	if configure != nil {
+		configure()
+	}
+
+	cmd := &cobra.Command{Use: "archive"}
+	cmd.SetContext(context.Background())
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetIn(strings.NewReader(stdin))
+	err = runStreamArchive(cmd, args)
+	return out.Bytes(), errOut.Bytes(), err
+}
+
+func readTarEntries(t *testing.T, r io.Reader) []streamArchiveTestEntry {
+	t.Helper()
+	tr := tar.NewReader(r)
+	var out []streamArchiveTestEntry
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return out
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		out = append(out, streamArchiveTestEntry{name: hdr.Name, data: string(data), modTime: hdr.ModTime})
+	}
+}
+
+func decodeStreamArchiveManifest(t *testing.T, data string) []streamArchiveEntry {
+	t.Helper()
+	var out []streamArchiveEntry
+	s := bufio.NewScanner(strings.NewReader(data))
+	for s.Scan() {
+		var e streamArchiveEntry
+		require.NoError(t, json.Unmarshal(s.Bytes(), &e))
+		out = append(out, e)
+	}
+	return out
+}
+
+// TestStreamArchiveTarToStdoutKeepsOrderAndManifest archives a stdin
+// selection with one missing object: the archive holds the rest in input
+// order, and the manifest records every selected object.
+func TestStreamArchiveTarToStdoutKeepsOrderAndManifest(t *testing.T) {
+	prov := &streamGetBatchProvider{
+		objects: map[string][]byte{
+			"audit/b.xml": []byte("bravo"),
+			"audit/a.xml": []byte("alpha-alpha"),
+		},
+		slow: map[string]time.Duration{"audit/b.xml": 30 * time.Millisecond},
+	}
+	stdin := strings.Join([]string{
+		"s3://bucket/audit/b.xml",
+		"s3://bucket/audit/gone.xml",
+		`{"type":"gonimbus.index.object.v1","data":{"base_uri":"s3://bucket/","key":"audit/a.xml"}}`,
+	}, "\n")
+
+	stdout, stderr, err := runStreamArchiveTest(t, prov, nil, stdin, nil)
+	require.ErrorContains(t, err, "completed with errors")
+
+	entries := readTarEntries(t, bytes.NewReader(stdout))
+	require.Len(t, entries, 3)
+	require.Equal(t, "audit/b.xml", entries[0].name)
+	require.Equal(t, "bravo", entries[0].data)
+	require.Equal(t, "audit/a.xml", entries[1].name)
+	require.Equal(t, "alpha-alpha", entries[1].data)
+	require.Equal(t, streamArchiveManifestName, entries[2].name)
+
+	manifest := decodeStreamArchiveManifest(t, entries[2].data)
+	require.Len(t, manifest, 3)
+	require.Equal(t, streamArchiveEntry{Path: "audit/b.xml", URI: "s3://bucket/audit/b.xml", Size: 5, ETag: `"audit/b.xml"`, Status: "success"}, manifest[0])
+	require.Equal(t, "s3://bucket/audit/gone.xml", manifest[1].URI)
+	require.Equal(t, "error", manifest[1].Status)
+	require.Equal(t, "audit/a.xml", manifest[2].Path)
+
+	records := decodeOutputRecords(t, string(stderr))
+	require.Equal(t, streamArchiveRecordType, records[len(records)-1].Type)
+	var rec streamArchiveRecord
+	require.NoError(t, json.Unmarshal(records[len(records)-1].Data, &rec))
+	require.Equal(t, streamArchiveRecord{Format: "tar", Entries: 2, Failed: 1, Bytes: int64(len(stdout)), Status: "success"}, rec)
+}
+
+func TestStreamArchiveZipAndTarZstToFileDestination(t *testing.T) {
+	modified := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	objects := map[string][]byte{
+		"logs/2026/01.log": []byte("one"),
+		"logs/2026/02.log": []byte("two"),
+		"other/x.log":      []byte("x"),
+	}
+	prov := &streamGetBatchProvider{objects: objects}
+	listed := &streamArchiveListProvider{streamGetBatchProvider: prov, modified: modified}
+
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "out.zip")
+	stdout, _, err := runStreamArchiveTest(t, listed, []string{"s3://bucket/logs/"}, "", func() {
+		streamArchiveFormat = streamArchiveFormatZip
+		streamArchiveDest = "file://" + zipPath
+	})
+	require.NoError(t, err)
+	records := decodeOutputRecords(t, string(stdout))
+	var rec streamArchiveRecord
+	require.NoError(t, json.Unmarshal(records[len(records)-1].Data, &rec))
+	require.Equal(t, "file://"+zipPath, rec.DestURI)
+	require.Equal(t, 2, rec.Entries)
+
+	zr, err := zip.OpenReader(zipPath)
+	require.NoError(t, err)
+	defer func() { _ = zr.Close() }()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"logs/2026/01.log", "logs/2026/02.log", streamArchiveManifestName}, names)
+	require.True(t, zr.File[0].Modified.Equal(modified), "mtime from LastModified")
+	rc, err := zr.File[1].Open()
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "two", string(got))
+
+	_, _, err = runStreamArchiveTest(t, listed, []string{"s3://bucket/logs/"}, "", func() {
+		streamArchiveFormat = streamArchiveFormatZip
+		streamArchiveDest = "file://" + zipPath
+	})
+	require.ErrorContains(t, err, "Destination exists")
+
+	zstPath := filepath.Join(dir, "out.tar.zst")
+	_, _, err = runStreamArchiveTest(t, listed, []string{"s3://bucket/logs/*/02.log"}, "", func() {
+		streamArchiveFormat = streamArchiveFormatTarZst
+		streamArchiveDest = "file://" + zstPath
+	})
+	require.NoError(t, err)
+	raw, err := os.ReadFile(zstPath)
+	require.NoError(t, err)
+	dec, err := zstd.NewReader(bytes.NewReader(raw))
+	require.NoError(t, err)
+	defer dec.Close()
+	entries := readTarEntries(t, dec)
+	require.Len(t, entries, 2)
+	require.Equal(t, "logs/2026/02.log", entries[0].name)
+	require.True(t, entries[0].modTime.Equal(modified))
+}
+
+// TestStreamArchiveReadsCrawlRecordsAgainstBaseURI archives crawl output:
+// gonimbus.object.v1 records name keys in the --base-uri bucket, and are
+// refused as invalid without it.
+func TestStreamArchiveReadsCrawlRecordsAgainstBaseURI(t *testing.T) {
+	prov := &streamGetBatchProvider{objects: map[string][]byte{"audit/a.xml": []byte("alpha")}}
+	stdin := `{"type":"gonimbus.object.v1","ts":"2026-01-23T12:28:17Z","job_id":"j","provider":"s3","data":{"key":"audit/a.xml","size":5,"etag":"x","last_modified":"2026-01-23T12:00:00Z"}}`
+
+	stdout, _, err := runStreamArchiveTest(t, prov, nil, stdin, func() { streamArchiveBaseURI = "s3://bucket/" })
+	require.NoError(t, err)
+	entries := readTarEntries(t, bytes.NewReader(stdout))
+	require.Len(t, entries, 2)
+	require.Equal(t, "audit/a.xml", entries[0].name)
+	require.Equal(t, "alpha", entries[0].data)
+	manifest := decodeStreamArchiveManifest(t, entries[1].data)
+	require.Equal(t, "s3://bucket/audit/a.xml", manifest[0].URI)
+
+	_, stderr, err := runStreamArchiveTest(t, prov, nil, stdin, nil)
+	require.ErrorContains(t, err, "invalid inputs")
+	require.Contains(t, string(stderr), "--base-uri")
+
+	_, _, err = runStreamArchiveTest(t, prov, nil, stdin, func() { streamArchiveBaseURI = "s3://bucket/audit/" })
+	require.ErrorContains(t, err, "Invalid --base-uri")
+}
+
+func TestStreamArchivePathRefusesEscapingKeys(t *testing.T) {
+	for key, want := range map[string]string{
+		"a/b.txt":     "a/b.txt",
+		"/lead/x":     "lead/x",
+		"a//b/./c":    "a/b/c",
+		"../up":       "",
+		"a/../../etc": "",
+		"..":          "",
+	} {
+		got, err := streamArchivePath(&uri.ObjectURI{Provider: "s3", Bucket: "b", Key: key})
+		if want == "" {
+			require.Error(t, err, key)
+			continue
+		}
+		require.NoError(t, err, key)
+		require.Equal(t, want, got)
+	}
+}
+
+// streamArchiveListProvider stamps listed objects with a fixed LastModified.
+type streamArchiveListProvider struct {
+	*streamGetBatchProvider
+	modified time.Time
+}
+
+func (p *streamArchiveListProvider) List(ctx context.Context, opts provider.ListOptions) (*provider.ListResult, error) {
+	res, err := p.streamGetBatchProvider.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res.Objects {
+		res.Objects[i].LastModified = p.modified
+	}
+	return res, nil
+}
+
+// streamArchiveChangedProvider serves a different body from GetObject than
+// Head describes, with the length unknown until it is read, as for an
+// object rewritten between listing and fetch.
+type streamArchiveChangedProvider struct {
+	*streamGetBatchProvider
+	bodies map[string]string
+}
+
+func (p *streamArchiveChangedProvider) GetObject(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	if body, ok := p.bodies[key]; ok {
+		return io.NopCloser(strings.NewReader(body)), -1, nil
+	}
+	return p.streamGetBatchProvider.GetObject(ctx, key)
+}
+
+// TestStreamArchiveFailsEntriesWhoseBodyChangedSize streams objects whose
+// bodies grew or shrank after Head: each entry is kept to its declared size
+// and recorded as failed, and the archive stays readable past them.
+func TestStreamArchiveFailsEntriesWhoseBodyChangedSize(t *testing.T) {
+	prov := &streamArchiveChangedProvider{
+		streamGetBatchProvider: &streamGetBatchProvider{objects: map[string][]byte{
+			"grew.txt":   []byte("ab"),
+			"shrank.txt": []byte("abcdef"),
+			"next.txt":   []byte("next"),
+		}},
+		bodies: map[string]string{"grew.txt": "grown", "shrank.txt": "abc"},
+	}
+	stdin := "s3://bucket/grew.txt\ns3://bucket/shrank.txt\ns3://bucket/next.txt\n"
+
+	stdout, _, err := runStreamArchiveTest(t, prov, nil, stdin, func() { streamArchiveMaxBufferBytes = 1 })
+	require.ErrorContains(t, err, "completed with errors")
+
+	entries := readTarEntries(t, bytes.NewReader(stdout))
+	require.Len(t, entries, 4)
+	require.Equal(t, "grew.txt", entries[0].name)
+	require.Equal(t, "gr", entries[0].data)
+	require.Equal(t, "shrank.txt", entries[1].name)
+	require.Equal(t, "abc\x00\x00\x00", entries[1].data)
+	require.Equal(t, "next.txt", entries[2].name)
+	require.Equal(t, "next", entries[2].data)
+
+	manifest := decodeStreamArchiveManifest(t, entries[3].data)
+	require.Len(t, manifest, 3)
+	require.Equal(t, "error", manifest[0].Status)
+	require.Contains(t, manifest[0].Error, "expected=2 got=5")
+	require.Equal(t, "error", manifest[1].Status)
+	require.Contains(t, manifest[1].Error, "expected=6 got=3")
+	require.Equal(t, "success", manifest[2].Status)
+}
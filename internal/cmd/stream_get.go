@@ -22,15 +22,35 @@ import (
 
 var streamGetCmd = &cobra.Command{
 	Use:   "get <uri>",
-	Short: "Stream a single object (JSONL headers + raw bytes)",
-	Long: `Stream a single object as a mixed-framing stream.
+	Short: "Stream objects (JSONL headers + raw bytes)",
+	Long: `Stream one or more objects as a mixed-framing stream.
 
 Behavior:
 - Performs a HEAD first to capture metadata (etag/last_modified/size) for the open record.
 - Streams content in fixed-size chunks.
 - Emits errors to stdout as gonimbus.error.v1 records (streaming mode contract).
+
+Multiple objects:
+- A glob URI (s3/gs) streams every matching object, using listing metadata instead of a HEAD.
+- --stdin reads one input per line: an exact or glob URI, a gonimbus.index.object.v1
+  record (index query output), or a gonimbus.reflow.input.v1 record.
+- Up to --concurrency objects are fetched at once. By default each object's
+  open/chunk/close sequence is emitted whole, in input order; objects are
+  buffered ahead within --max-buffer-bytes, and a larger object is streamed
+  live when its turn comes.
+- --interleave emits streams as they arrive, interleaved; tell them apart by stream_id.
+- A failed object emits an error record (and a close with status "error" if
+  its open was already written); the batch continues and exits non-zero.
+
+Byte ranges:
+- --range reads part of each object: start-end (end inclusive), start- (to the
+  end, e.g. to resume a partial download), or -n (the last n bytes). Repeat the
+  flag or separate ranges with commas; --tail n adds a last-n-bytes range.
+- Each range is emitted as its own open/chunk/close sequence: the open record
+  carries the resolved range, and chunk offsets are positions in the object.
+- Ends past the object are clamped; a range starting past the end fails that object.
 `,
-	Args: cobra.ExactArgs(1),
+	Args: validateStreamGetArgs,
 	RunE: runStreamGet,
 }
 
@@ -40,6 +60,14 @@ var (
 	streamGetEndpoint   string
 	streamGetGCPProject string
 	streamGetChunk      int
+
+	streamGetStdin          bool
+	streamGetConcurrency    int
+	streamGetMaxBufferBytes int64
+	streamGetInterleave     bool
+
+	streamGetRanges []string
+	streamGetTail   int64
 )
 
 func init() {
@@ -50,19 +78,48 @@ func init() {
 	streamGetCmd.Flags().StringVar(&streamGetEndpoint, "endpoint", "", "Custom S3 endpoint")
 	streamGetCmd.Flags().StringVar(&streamGetGCPProject, "gcp-project", "", "GCP project hint for GCS")
 	streamGetCmd.Flags().IntVar(&streamGetChunk, "chunk-bytes", 64*1024, "Chunk size in bytes")
+	streamGetCmd.Flags().BoolVar(&streamGetStdin, "stdin", false, "Read URIs or index/reflow-input JSONL from stdin (one per line)")
+	streamGetCmd.Flags().IntVar(&streamGetConcurrency, "concurrency", defaultStreamGetConcurrency, "Max concurrent object fetches (multi-object mode)")
+	streamGetCmd.Flags().Int64Var(&streamGetMaxBufferBytes, "max-buffer-bytes", defaultStreamGetMaxBufferBytes, "Max bytes buffered ahead of the object being written (ordered multi-object mode)")
+	streamGetCmd.Flags().BoolVar(&streamGetInterleave, "interleave", false, "Emit concurrent streams interleaved by stream_id instead of one object at a time")
+	streamGetCmd.Flags().StringArrayVar(&streamGetRanges, "range", nil, "Byte range to read: start-end (inclusive), start-, or -n (repeatable; comma-separated)")
+	streamGetCmd.Flags().Int64Var(&streamGetTail, "tail", 0, "Read the last N bytes of each object")
+}
+
+func validateStreamGetArgs(cmd *cobra.Command, args []string) error {
+	stdin, _ := cmd.Flags().GetBool("stdin")
+	if stdin {
+		if len(args) != 0 {
+			return fmt.Errorf("when using --stdin, do not provide <uri> arguments")
+		}
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("requires exactly 1 argument: <uri> (or use --stdin)")
+	}
+	return nil
 }
 
 func runStreamGet(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
+	if streamGetStdin {
+		return runStreamGetBatch(cmd, nil)
+	}
 	rawURI := args[0]
 
 	parsed, err := uri.ParseURI(rawURI)
 	if err != nil {
 		return exitError(foundry.ExitInvalidArgument, "Invalid URI", err)
 	}
+	if parsed.IsPattern() && parsed.Provider != string(provider.ProviderFile) {
+		return runStreamGetBatch(cmd, args)
+	}
 	if parsed.IsPattern() || parsed.IsPrefix() {
 		return exitError(foundry.ExitInvalidArgument, "stream get requires an exact object key", fmt.Errorf("provide an exact object URI (no glob, no trailing '/'): %s", rawURI))
 	}
+	if len(streamGetRanges) > 0 || streamGetTail != 0 {
+		return runStreamGetBatch(cmd, args)
+	}
 
 	target := commandSourceTargetForRead(parsed)
 	prov, err := newCommandSourceProviderWithGCSProject(ctx, target.ProviderURI, "stream get", streamGetRegion, streamGetProfile, streamGetEndpoint, streamGetGCPProject)
@@ -171,7 +228,28 @@ func (e *streamSizeMismatchError) Error() string {
 	return fmt.Sprintf("source size mismatch for %s: expected=%d got=%d", e.Key, e.Expected, e.Got)
 }
 
-func emitStreamError(ctx context.Context, w output.Writer, key string, err error) error {
+// streamErrorWriter is what a streaming error record is written through: an
+// output.Writer, or a stream.Writer when errors share the stream's lock.
+type streamErrorWriter interface {
+	WriteError(ctx context.Context, err *output.ErrorRecord) error
+}
+
+func emitStreamError(ctx context.Context, w streamErrorWriter, key string, err error) error {
+	emitStreamErrorDetails(ctx, w, key, err, nil)
+	return nil
+}
+
+func emitStreamErrorDetails(ctx context.Context, w streamErrorWriter, key string, err error, details map[string]any) {
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["mode"] = "streaming"
+	if err := w.WriteError(ctx, &output.ErrorRecord{Code: streamErrorCode(err), Message: err.Error(), Key: key, Details: details}); err != nil {
+		observability.CLILogger.Debug("Failed to emit streaming error record", zap.Error(err))
+	}
+}
+
+func streamErrorCode(err error) string {
 	code := output.ErrCodeInternal
 	if provider.IsNotFound(err) {
 		code = output.ErrCodeNotFound
@@ -183,13 +261,8 @@ func emitStreamError(ctx context.Context, w output.Writer, key string, err error
 		code = output.ErrCodeProviderUnavailable
 	} else if _, ok := err.(*streamSizeMismatchError); ok {
 		code = output.ErrCodeNotFound
+	} else if _, ok := err.(*streamGetInputError); ok {
+		code = output.ErrCodeInvalidInput
 	}
-
-	details := map[string]any{
-		"mode": "streaming",
-	}
-	if err := w.WriteError(ctx, &output.ErrorRecord{Code: code, Message: err.Error(), Key: key, Details: details}); err != nil {
-		observability.CLILogger.Debug("Failed to emit streaming error record", zap.Error(err))
-	}
-	return nil
+	return code
 }
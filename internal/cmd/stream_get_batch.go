@@ -0,0 +1,815 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fulmenhq/gofulmen/foundry"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/match"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/stream"
+	"github.com/3leaps/gonimbus/pkg/uri"
+)
+
+const (
+	defaultStreamGetConcurrency    = 8
+	defaultStreamGetMaxBufferBytes = 64 * 1024 * 1024
+)
+
+// streamGetInputError marks a stdin line that names no streamable object.
+type streamGetInputError struct {
+	msg string
+}
+
+func (e *streamGetInputError) Error() string { return e.msg }
+
+// streamGetObject is one object of a batch, in input order. An object whose
+// input or listing failed carries err and is reported in its place.
+type streamGetObject struct {
+	line   int
+	uri    *uri.ObjectURI
+	listed *provider.ObjectMeta
+	err    error
+}
+
+func (o streamGetObject) details() map[string]any {
+	d := map[string]any{}
+	if o.uri != nil {
+		d["uri"] = o.uri.String()
+	}
+	if o.line > 0 {
+		d["line"] = o.line
+	}
+	return d
+}
+
+func (o streamGetObject) key() string {
+	if o.uri == nil {
+		return ""
+	}
+	return o.uri.Key
+}
+
+// streamGetBatch fetches many objects for one command. Providers are opened
+// once per bucket (or per file directory) and shared by the workers.
+//
+// stream get writes each object as a stream through sw; stream archive
+// supplies its own emit to runOrdered and leaves sw nil.
+type streamGetBatch struct {
+	command     string
+	source      streamGetSourceFlags
+	allowPrefix bool
+	// crawlBase, when set, is the bucket crawl object records resolve
+	// against; the records carry only the key.
+	crawlBase *uri.ObjectURI
+	errOut    streamErrorWriter
+	// onFail, when set, also sees each failure. runOrdered reports failures
+	// from its one emitting goroutine, so onFail needs no locking there.
+	onFail func(o streamGetObject, err error)
+
+	sw         *stream.Writer
+	chunkBytes int
+	// ranges, when set, are read from every object instead of its whole body.
+	ranges []streamGetRangeSpec
+
+	provMu    sync.Mutex
+	providers map[string]provider.Provider
+
+	failed  atomic.Int64
+	invalid atomic.Int64
+
+	writeMu  sync.Mutex
+	writeErr error
+	cancel   context.CancelFunc
+}
+
+// streamGetSourceFlags are the provider flags a batch opens sources with.
+type streamGetSourceFlags struct {
+	region, profile, endpoint, gcpProject string
+}
+
+// streamGetEmitFunc writes one resolved object; open fetches part i of it (or
+// hands back the buffered bytes), for i below res.parts().
+type streamGetEmitFunc func(ctx context.Context, o streamGetObject, res *streamGetResolved, open func(i int) (io.ReadCloser, int64, error))
+
+// streamGetResolved is an object ready to fetch. With ranges set, each range
+// is one part, fetched and streamed on its own; otherwise the whole object is
+// the only part.
+type streamGetResolved struct {
+	getter provider.ObjectGetter
+	ranger provider.ObjectRanger
+	key    string
+	meta   *provider.ObjectMeta
+	ranges []stream.ByteRange
+}
+
+func (r *streamGetResolved) parts() int {
+	if r.ranges == nil {
+		return 1
+	}
+	return len(r.ranges)
+}
+
+// partRange is part i's range, or nil for a whole object.
+func (r *streamGetResolved) partRange(i int) *stream.ByteRange {
+	if r.ranges == nil {
+		return nil
+	}
+	rng := r.ranges[i]
+	return &rng
+}
+
+// partSize is the byte count part i should have; -1 when unknown.
+func (r *streamGetResolved) partSize(i int) int64 {
+	if r.ranges == nil {
+		return r.meta.Size
+	}
+	return r.ranges[i].End - r.ranges[i].Start + 1
+}
+
+// totalSize is the byte count of every part together; -1 when unknown.
+func (r *streamGetResolved) totalSize() int64 {
+	var total int64
+	for i := 0; i < r.parts(); i++ {
+		n := r.partSize(i)
+		if n < 0 {
+			return -1
+		}
+		total += n
+	}
+	return total
+}
+
+// fetch opens part i. An empty range is served without a request.
+func (r *streamGetResolved) fetch(ctx context.Context, i int) (io.ReadCloser, int64, error) {
+	if r.ranges == nil {
+		return r.getter.GetObject(ctx, r.key)
+	}
+	rng := r.ranges[i]
+	if rng.End < rng.Start {
+		return io.NopCloser(bytes.NewReader(nil)), 0, nil
+	}
+	return r.ranger.GetRange(ctx, r.key, rng.Start, rng.End)
+}
+
+// startInputs expands inputs into objects on a feeder goroutine. Line numbers
+// are reported for stdin input only.
+func (b *streamGetBatch) startInputs(ctx context.Context, inputs []string, fromStdin bool) <-chan streamGetObject {
+	objects := make(chan streamGetObject)
+	go func() {
+		defer close(objects)
+		for i, in := range inputs {
+			line := 0
+			if fromStdin {
+				line = i + 1
+			}
+			if !b.expandInput(ctx, line, in, objects) {
+				return
+			}
+		}
+	}()
+	return objects
+}
+
+func runStreamGetBatch(cmd *cobra.Command, args []string) error {
+	if streamGetConcurrency < 1 {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --concurrency value", fmt.Errorf("concurrency must be >= 1"))
+	}
+	if streamGetMaxBufferBytes < 1 {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --max-buffer-bytes value", fmt.Errorf("max-buffer-bytes must be >= 1"))
+	}
+	ranges, err := parseStreamGetRanges(streamGetRanges, streamGetTail)
+	if err != nil {
+		return exitError(foundry.ExitInvalidArgument, "Invalid --range/--tail value", err)
+	}
+
+	inputs := args
+	if streamGetStdin {
+		lines, err := readURILines(cmd.InOrStdin())
+		if err != nil {
+			return exitError(foundry.ExitInvalidArgument, "Failed to read stdin", err)
+		}
+		inputs = lines
+	}
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	chunkBytes := streamGetChunk
+	if chunkBytes <= 0 {
+		chunkBytes = 64 * 1024
+	}
+	sw := stream.NewWriter(cmd.OutOrStdout(), uuid.New().String(), commandOutputProviderForInputs(inputs, string(provider.ProviderS3)))
+	defer func() { _ = sw.Close() }()
+	b := &streamGetBatch{
+		command:    "stream get",
+		source:     streamGetSourceFlags{region: streamGetRegion, profile: streamGetProfile, endpoint: streamGetEndpoint, gcpProject: streamGetGCPProject},
+		errOut:     sw,
+		sw:         sw,
+		chunkBytes: chunkBytes,
+		ranges:     ranges,
+		providers:  map[string]provider.Provider{},
+		cancel:     cancel,
+	}
+	defer b.close()
+
+	objects := b.startInputs(ctx, inputs, streamGetStdin)
+	if streamGetInterleave {
+		b.runInterleaved(ctx, objects, streamGetConcurrency)
+	} else {
+		b.runOrdered(ctx, objects, streamGetConcurrency, streamGetMaxBufferBytes, b.streamObject)
+	}
+
+	if err := b.firstWriteErr(); err != nil {
+		return exitError(foundry.ExitFileWriteError, "Failed to write stream", err)
+	}
+	if err := cmd.Context().Err(); err != nil {
+		return exitError(foundry.ExitSignalInt, "stream get cancelled", err)
+	}
+	if n := b.invalid.Load(); n > 0 {
+		return exitError(foundry.ExitInvalidArgument, "stream get completed with invalid inputs", fmt.Errorf("invalid_inputs=%d", n))
+	}
+	if n := b.failed.Load(); n > 0 {
+		return exitError(foundry.ExitExternalServiceUnavailable, "stream get completed with errors", fmt.Errorf("errors=%d", n))
+	}
+	return nil
+}
+
+// expandInput turns one input into the objects it names, sending them in
+// order: a glob (or, with allowPrefix, a prefix) is listed. It reports false
+// once ctx is done.
+func (b *streamGetBatch) expandInput(ctx context.Context, line int, in string, out chan<- streamGetObject) bool {
+	send := func(o streamGetObject) bool {
+		select {
+		case out <- o:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	parsed, err := parseStreamGetInput(in, b.crawlBase)
+	if err != nil {
+		return send(streamGetObject{line: line, err: &streamGetInputError{msg: err.Error()}})
+	}
+	if !parsed.IsPattern() && !parsed.IsPrefix() {
+		return send(streamGetObject{line: line, uri: parsed})
+	}
+	if !parsed.IsPattern() && !b.allowPrefix {
+		return send(streamGetObject{line: line, uri: parsed, err: &streamGetInputError{msg: b.command + " requires an exact object key or glob, not a prefix: " + parsed.String()}})
+	}
+	if parsed.Provider == string(provider.ProviderFile) {
+		return send(streamGetObject{line: line, uri: parsed, err: &streamGetInputError{msg: "listing URIs are supported for s3 and gs only: " + parsed.String()}})
+	}
+	prov, err := b.provider(ctx, parsed)
+	if err != nil {
+		return send(streamGetObject{line: line, uri: parsed, err: err})
+	}
+	var matcher *match.Matcher
+	if parsed.IsPattern() {
+		matcher, err = match.New(match.Config{Includes: []string{parsed.Pattern}})
+		if err != nil {
+			return send(streamGetObject{line: line, uri: parsed, err: &streamGetInputError{msg: fmt.Sprintf("invalid pattern: %v", err)}})
+		}
+	}
+	var token string
+	for {
+		res, err := prov.List(ctx, provider.ListOptions{Prefix: parsed.Key, ContinuationToken: token})
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			return send(streamGetObject{line: line, uri: parsed, err: fmt.Errorf("list failed: %w", err)})
+		}
+		for _, obj := range res.Objects {
+			if matcher != nil && !matcher.Match(obj.Key) {
+				continue
+			}
+			objURI := &uri.ObjectURI{Provider: parsed.Provider, Bucket: parsed.Bucket, Key: obj.Key}
+			if !send(streamGetObject{line: line, uri: objURI, listed: &provider.ObjectMeta{ObjectSummary: obj}}) {
+				return false
+			}
+		}
+		if !res.IsTruncated || res.ContinuationToken == "" {
+			return true
+		}
+		token = res.ContinuationToken
+	}
+}
+
+// parseStreamGetInput reads one input line: a URI (exact or glob), or a
+// gonimbus.index.object.v1 / gonimbus.reflow.input.v1 record naming one
+// object. With crawlBase set, gonimbus.object.v1 crawl records are read too,
+// as keys in that bucket.
+func parseStreamGetInput(line string, crawlBase *uri.ObjectURI) (*uri.ObjectURI, error) {
+	if !strings.HasPrefix(line, "{") {
+		return uri.ParseURI(line)
+	}
+	var env struct {
+		Type     string          `json:"type"`
+		Provider string          `json:"provider"`
+		Data     json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(line), &env); err != nil {
+		return nil, err
+	}
+	switch env.Type {
+	case "gonimbus.index.object.v1":
+		var data struct {
+			BaseURI   string  `json:"base_uri"`
+			Key       string  `json:"key"`
+			RelKey    string  `json:"rel_key"`
+			DeletedAt *string `json:"deleted_at"`
+		}
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			return nil, err
+		}
+		if data.DeletedAt != nil {
+			return nil, fmt.Errorf("deleted objects cannot be streamed")
+		}
+		base, err := uri.ParseURI(data.BaseURI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base_uri: %w", err)
+		}
+		key := strings.TrimPrefix(data.Key, "/")
+		if key == "" {
+			key = strings.TrimPrefix(data.RelKey, "/")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("missing key in index record")
+		}
+		if base.Provider == string(provider.ProviderFile) {
+			return uri.ParseURI(fileURI(filepath.Join(base.Key, filepath.FromSlash(key))))
+		}
+		return &uri.ObjectURI{Provider: base.Provider, Bucket: base.Bucket, Key: key}, nil
+	case "gonimbus.reflow.input.v1":
+		var data struct {
+			SourceURI string `json:"source_uri"`
+			SourceKey string `json:"source_key"`
+		}
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(data.SourceURI) == "" {
+			return nil, fmt.Errorf("missing data.source_uri")
+		}
+		u, err := uri.ParseURI(data.SourceURI)
+		if err != nil {
+			return nil, err
+		}
+		if u.IsPrefix() || u.IsPattern() {
+			return nil, fmt.Errorf("reflow input source_uri must be an exact object URI")
+		}
+		if u.Provider != string(provider.ProviderFile) && strings.TrimSpace(data.SourceKey) != "" {
+			u = &uri.ObjectURI{Provider: u.Provider, Bucket: u.Bucket, Key: strings.TrimPrefix(strings.TrimSpace(data.SourceKey), "/")}
+		}
+		return u, nil
+	case output.TypeObject:
+		if crawlBase == nil {
+			return nil, fmt.Errorf("crawl object records need --base-uri naming their bucket")
+		}
+		if env.Provider != "" && env.Provider != crawlBase.Provider {
+			return nil, fmt.Errorf("crawl record provider %q does not match --base-uri %s", env.Provider, crawlBase)
+		}
+		var data struct {
+			Key string `json:"key"`
+		}
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			return nil, err
+		}
+		key := strings.TrimPrefix(data.Key, "/")
+		if key == "" {
+			return nil, fmt.Errorf("missing key in crawl record")
+		}
+		return &uri.ObjectURI{Provider: crawlBase.Provider, Bucket: crawlBase.Bucket, Key: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported json record type %q", env.Type)
+	}
+}
+
+func (b *streamGetBatch) provider(ctx context.Context, src *uri.ObjectURI) (provider.Provider, error) {
+	id := commandSourceProviderID(src)
+	b.provMu.Lock()
+	defer b.provMu.Unlock()
+	if p, ok := b.providers[id]; ok {
+		return p, nil
+	}
+	p, err := newCommandSourceProviderWithGCSProject(ctx, src, b.command, b.source.region, b.source.profile, b.source.endpoint, b.source.gcpProject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to storage provider: %w", err)
+	}
+	b.providers[id] = p
+	return p, nil
+}
+
+func (b *streamGetBatch) close() {
+	b.provMu.Lock()
+	defer b.provMu.Unlock()
+	for _, p := range b.providers {
+		_ = p.Close()
+	}
+}
+
+// resolve opens the object's provider and reads its metadata: from the
+// listing that named it, else by HEAD. Requested ranges are fixed against the
+// object's size here.
+func (b *streamGetBatch) resolve(ctx context.Context, o streamGetObject) (*streamGetResolved, error) {
+	target := commandSourceTargetForRead(o.uri)
+	prov, err := b.provider(ctx, target.ProviderURI)
+	if err != nil {
+		return nil, err
+	}
+	res := &streamGetResolved{key: target.QueryURI.Key, meta: o.listed}
+	res.getter, err = providerdispatch.RequireCapability[provider.ObjectGetter](prov, b.command, o.uri.Provider, "ObjectGetter")
+	if err != nil {
+		return nil, err
+	}
+	if b.ranges != nil {
+		res.ranger, err = providerdispatch.RequireCapability[provider.ObjectRanger](prov, b.command, o.uri.Provider, "ObjectRanger")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if res.meta == nil {
+		res.meta, err = prov.Head(ctx, res.key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if b.ranges != nil {
+		res.ranges, err = resolveStreamGetRanges(b.ranges, res.meta.Size)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// fail counts and reports one object's failure; a caller that already wrote
+// the object's open record closes its stream with status error.
+func (b *streamGetBatch) fail(ctx context.Context, o streamGetObject, err error) {
+	var inputErr *streamGetInputError
+	if errors.As(err, &inputErr) {
+		b.invalid.Add(1)
+	} else {
+		b.failed.Add(1)
+	}
+	emitStreamErrorDetails(ctx, b.errOut, o.key(), err, o.details())
+	if b.onFail != nil {
+		b.onFail(o, err)
+	}
+}
+
+// wrote records a write failure; the batch cannot continue past a broken
+// output, so the first one cancels it.
+func (b *streamGetBatch) wrote(err error) bool {
+	if err == nil {
+		return true
+	}
+	b.writeMu.Lock()
+	if b.writeErr == nil {
+		b.writeErr = err
+	}
+	b.writeMu.Unlock()
+	b.cancel()
+	return false
+}
+
+func (b *streamGetBatch) firstWriteErr() error {
+	b.writeMu.Lock()
+	defer b.writeMu.Unlock()
+	return b.writeErr
+}
+
+// streamObject writes one open, chunks, and close per part of an object. It
+// is the whole output for an object whose bytes are already held, and the
+// live stream for one fetched as it is written. A failed part ends the
+// object: its remaining ranges are not read.
+func (b *streamGetBatch) streamObject(ctx context.Context, o streamGetObject, res *streamGetResolved, open func(i int) (io.ReadCloser, int64, error)) {
+	for i := 0; i < res.parts(); i++ {
+		if !b.streamPart(ctx, o, res, i, open) {
+			return
+		}
+	}
+}
+
+// streamPart writes part i as one stream and reports whether the object can
+// go on to its next part.
+func (b *streamGetBatch) streamPart(ctx context.Context, o streamGetObject, res *streamGetResolved, i int, open func(i int) (io.ReadCloser, int64, error)) bool {
+	meta := res.meta
+	rng := res.partRange(i)
+	streamID := uuid.New().String()
+	size := meta.Size
+	lastModified := meta.LastModified
+	if !b.wrote(b.sw.WriteOpen(ctx, &stream.Open{
+		StreamID:     streamID,
+		URI:          o.uri.String(),
+		ETag:         meta.ETag,
+		Size:         &size,
+		LastModified: &lastModified,
+		ContentType:  meta.ContentType,
+		Range:        rng,
+	})) {
+		return false
+	}
+	started := time.Now()
+	closeStream := func(status string, chunks, total int64) bool {
+		duration := time.Since(started).Nanoseconds()
+		return b.wrote(b.sw.WriteClose(ctx, &stream.Close{StreamID: streamID, Status: status, Chunks: chunks, Bytes: total, DurationNS: &duration}))
+	}
+
+	body, gotSize, err := open(i)
+	if err != nil {
+		b.fail(ctx, o, err)
+		closeStream("error", 0, 0)
+		return false
+	}
+	defer func() { _ = body.Close() }()
+	if want := res.partSize(i); want > 0 && gotSize >= 0 && want != gotSize {
+		b.fail(ctx, o, &streamSizeMismatchError{Key: o.key(), Expected: want, Got: gotSize})
+		closeStream("error", 0, 0)
+		return false
+	}
+
+	var base int64
+	if rng != nil {
+		base = rng.Start
+	}
+	buf := make([]byte, b.chunkBytes)
+	var seq, total int64
+	for {
+		n, rerr := body.Read(buf)
+		if n > 0 {
+			offset := base + total
+			if !b.wrote(b.sw.WriteChunk(ctx, &stream.Chunk{StreamID: streamID, Seq: seq, NBytes: int64(n), Offset: &offset}, bytes.NewReader(buf[:n]))) {
+				return false
+			}
+			seq++
+			total += int64(n)
+		}
+		if rerr != nil {
+			if errors.Is(rerr, io.EOF) {
+				break
+			}
+			b.fail(ctx, o, rerr)
+			closeStream("error", seq, total)
+			return false
+		}
+	}
+	return closeStream("success", seq, total)
+}
+
+// runInterleaved streams up to concurrency objects at once. Their records
+// interleave on the output and are told apart by stream_id.
+func (b *streamGetBatch) runInterleaved(ctx context.Context, objects <-chan streamGetObject, concurrency int) {
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for o := range objects {
+				if ctx.Err() != nil {
+					continue
+				}
+				if o.err != nil {
+					b.fail(ctx, o, o.err)
+					continue
+				}
+				res, err := b.resolve(ctx, o)
+				if err != nil {
+					b.fail(ctx, o, err)
+					continue
+				}
+				b.streamObject(ctx, o, res, func(i int) (io.ReadCloser, int64, error) { return res.fetch(ctx, i) })
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// streamGetSlot is one object's place in an ordered batch. A worker fills it
+// and closes ready; the emitter writes slots strictly in input order.
+//
+// Buffer reservations are taken in input order too: each slot waits for its
+// predecessor's reserved before reserving, so the budget is only ever held by
+// objects ahead of every waiter and the emitter always drains it.
+type streamGetSlot struct {
+	obj          streamGetObject
+	prevReserved <-chan struct{}
+	reserved     chan struct{}
+	ready        chan struct{}
+
+	res   *streamGetResolved
+	parts [][]byte
+	held  int64
+	err   error
+}
+
+// runOrdered fetches up to concurrency objects ahead of the one being written,
+// buffering each whole while the buffer budget allows, and hands one object
+// at a time to emit, in input order. An object larger than the budget is not
+// buffered: it is fetched when its turn comes and read as it arrives.
+func (b *streamGetBatch) runOrdered(ctx context.Context, objects <-chan streamGetObject, concurrency int, maxBuffer int64, emit streamGetEmitFunc) {
+	budget := newStreamGetBudget(maxBuffer)
+	slots := make(chan *streamGetSlot, concurrency)
+	work := make(chan *streamGetSlot)
+
+	go func() {
+		defer close(slots)
+		defer close(work)
+		prev := make(chan struct{})
+		close(prev)
+		for o := range objects {
+			s := &streamGetSlot{obj: o, prevReserved: prev, reserved: make(chan struct{}), ready: make(chan struct{})}
+			prev = s.reserved
+			select {
+			case slots <- s:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case work <- s:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range work {
+				b.prepare(ctx, s, budget, maxBuffer)
+			}
+		}()
+	}
+
+	for s := range slots {
+		select {
+		case <-s.ready:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		switch {
+		case s.err != nil:
+			b.fail(ctx, s.obj, s.err)
+		case s.parts != nil:
+			parts := s.parts
+			emit(ctx, s.obj, s.res, func(i int) (io.ReadCloser, int64, error) {
+				return io.NopCloser(bytes.NewReader(parts[i])), int64(len(parts[i])), nil
+			})
+		default:
+			res := s.res
+			emit(ctx, s.obj, res, func(i int) (io.ReadCloser, int64, error) { return res.fetch(ctx, i) })
+		}
+		s.parts = nil
+		budget.release(s.held)
+	}
+	for range slots {
+	}
+	wg.Wait()
+}
+
+// prepare resolves a slot's object and, when it fits the budget, reads it
+// whole. It always closes reserved (after its predecessor's) and ready.
+func (b *streamGetBatch) prepare(ctx context.Context, s *streamGetSlot, budget *streamGetBudget, maxBuffer int64) {
+	defer close(s.ready)
+	var once sync.Once
+	passTurn := func() {
+		once.Do(func() {
+			select {
+			case <-s.prevReserved:
+			case <-ctx.Done():
+			}
+			close(s.reserved)
+		})
+	}
+	defer passTurn()
+
+	if s.obj.err != nil {
+		s.err = s.obj.err
+		return
+	}
+	res, err := b.resolve(ctx, s.obj)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.res = res
+	total := res.totalSize()
+	if total > maxBuffer || total < 0 {
+		// Streamed live by the emitter.
+		return
+	}
+
+	select {
+	case <-s.prevReserved:
+	case <-ctx.Done():
+		return
+	}
+	if err := budget.reserve(ctx, total); err != nil {
+		return
+	}
+	s.held = total
+	passTurn()
+
+	parts := make([][]byte, res.parts())
+	for i := range parts {
+		parts[i], err = b.readPart(ctx, s.obj, res, i)
+		if err != nil {
+			s.err = err
+			return
+		}
+	}
+	s.parts = parts
+}
+
+// readPart fetches part i whole, checking it against its expected size.
+func (b *streamGetBatch) readPart(ctx context.Context, o streamGetObject, res *streamGetResolved, i int) ([]byte, error) {
+	want := res.partSize(i)
+	body, gotSize, err := res.fetch(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = body.Close() }()
+	if want > 0 && gotSize >= 0 && want != gotSize {
+		return nil, &streamSizeMismatchError{Key: o.key(), Expected: want, Got: gotSize}
+	}
+	data := bytes.NewBuffer(make([]byte, 0, want))
+	if _, err := data.ReadFrom(io.LimitReader(body, want+1)); err != nil {
+		return nil, err
+	}
+	if int64(data.Len()) != want {
+		return nil, &streamSizeMismatchError{Key: o.key(), Expected: want, Got: int64(data.Len())}
+	}
+	return data.Bytes(), nil
+}
+
+// streamGetBudget bounds the bytes an ordered batch holds in memory.
+// Reservations are serialized by the slot turnstile, so at most one caller
+// waits at a time.
+type streamGetBudget struct {
+	mu    sync.Mutex
+	max   int64
+	used  int64
+	freed chan struct{}
+}
+
+func newStreamGetBudget(max int64) *streamGetBudget {
+	return &streamGetBudget{max: max}
+}
+
+func (b *streamGetBudget) reserve(ctx context.Context, n int64) error {
+	for {
+		b.mu.Lock()
+		if b.used+n <= b.max {
+			b.used += n
+			b.mu.Unlock()
+			return nil
+		}
+		if b.freed == nil {
+			b.freed = make(chan struct{})
+		}
+		freed := b.freed
+		b.mu.Unlock()
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *streamGetBudget) release(n int64) {
+	if n == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used -= n
+	if b.freed != nil {
+		close(b.freed)
+		b.freed = nil
+	}
+}
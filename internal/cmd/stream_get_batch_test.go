@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/provider/s3"
+	"github.com/3leaps/gonimbus/pkg/stream"
+)
+
+// streamGetBatchProvider serves objects from memory. Objects listed in slow
+// are held back on GetObject, so later objects finish fetching first.
+type streamGetBatchProvider struct {
+	objects map[string][]byte
+	slow    map[string]time.Duration
+	heads   atomic.Int64
+	ranges  atomic.Int64
+}
+
+func (p *streamGetBatchProvider) List(_ context.Context, opts provider.ListOptions) (*provider.ListResult, error) {
+	keys := make([]string, 0, len(p.objects))
+	for k := range p.objects {
+		if strings.HasPrefix(k, opts.Prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	res := &provider.ListResult{}
+	for _, k := range keys {
+		res.Objects = append(res.Objects, provider.ObjectSummary{Key: k, Size: int64(len(p.objects[k])), ETag: `"` + k + `"`})
+	}
+	return res, nil
+}
+
+func (p *streamGetBatchProvider) Head(_ context.Context, key string) (*provider.ObjectMeta, error) {
+	p.heads.Add(1)
+	data, ok := p.objects[key]
+	if !ok {
+		return nil, provider.ErrNotFound
+	}
+	return &provider.ObjectMeta{ObjectSummary: provider.ObjectSummary{Key: key, Size: int64(len(data)), ETag: `"` + key + `"`}}, nil
+}
+
+func (p *streamGetBatchProvider) GetObject(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	if d := p.slow[key]; d > 0 {
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+	data, ok := p.objects[key]
+	if !ok {
+		return nil, 0, provider.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (p *streamGetBatchProvider) GetRange(_ context.Context, key string, start, endInclusive int64) (io.ReadCloser, int64, error) {
+	p.ranges.Add(1)
+	data, ok := p.objects[key]
+	if !ok {
+		return nil, 0, provider.ErrNotFound
+	}
+	end := min(endInclusive+1, int64(len(data)))
+	return io.NopCloser(bytes.NewReader(data[start:end])), end - start, nil
+}
+
+func (p *streamGetBatchProvider) Close() error { return nil }
+
+type streamGetDecoded struct {
+	uri     string
+	rng     *stream.ByteRange
+	offsets []int64
+	data    []byte
+	status  string
+}
+
+// decodeStreamGetOutput decodes a batch's output into per-stream results in
+// the order their open records appear, plus the error records. With ordered
+// set, it also requires each stream to be contiguous.
+func decodeStreamGetOutput(t *testing.T, out []byte, ordered bool) ([]*streamGetDecoded, []output.ErrorRecord) {
+	t.Helper()
+	d := stream.NewDecoder(bytes.NewReader(out))
+	byID := map[string]*streamGetDecoded{}
+	var streams []*streamGetDecoded
+	var errs []output.ErrorRecord
+	active := ""
+	for {
+		ev, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		switch {
+		case ev.Kind == stream.EventChunk:
+			s := byID[ev.Chunk.Header.StreamID]
+			require.NotNil(t, s)
+			if ordered {
+				require.Equal(t, active, ev.Chunk.Header.StreamID)
+			}
+			b, err := io.ReadAll(ev.Chunk.Body)
+			require.NoError(t, err)
+			s.data = append(s.data, b...)
+			if off := ev.Chunk.Header.Offset; off != nil {
+				s.offsets = append(s.offsets, *off)
+			}
+		case ev.Record.Type == stream.TypeStreamOpen:
+			var open stream.Open
+			require.NoError(t, json.Unmarshal(ev.Record.Data, &open))
+			if ordered {
+				require.Empty(t, active, "open before previous stream closed")
+				active = open.StreamID
+			}
+			s := &streamGetDecoded{uri: open.URI, rng: open.Range}
+			byID[open.StreamID] = s
+			streams = append(streams, s)
+		case ev.Record.Type == stream.TypeStreamClose:
+			var cl stream.Close
+			require.NoError(t, json.Unmarshal(ev.Record.Data, &cl))
+			byID[cl.StreamID].status = cl.Status
+			require.Equal(t, cl.Bytes, int64(len(byID[cl.StreamID].data)))
+			active = ""
+		case ev.Record.Type == output.TypeError:
+			var rec output.ErrorRecord
+			require.NoError(t, json.Unmarshal(ev.Record.Data, &rec))
+			errs = append(errs, rec)
+		default:
+			t.Fatalf("unexpected record %s", ev.Record.Type)
+		}
+	}
+	return streams, errs
+}
+
+func runStreamGetBatchTest(t *testing.T, prov provider.Provider, args []string, stdin string, configure func()) ([]byte, error) {
+	t.Helper()
+	restore := providerdispatch.UseFactoriesForTest(providerdispatch.Factories{
+		S3: func(context.Context, s3.Config) (provider.Provider, error) { return prov, nil },
+	})
+	t.Cleanup(restore)
+
+	oldStdin, oldConc, oldBuf, oldInter, oldChunk := streamGetStdin, streamGetConcurrency, streamGetMaxBufferBytes, streamGetInterleave, streamGetChunk
+	oldRanges, oldTail := streamGetRanges, streamGetTail
+	t.Cleanup(func() {
+		streamGetStdin, streamGetConcurrency, streamGetMaxBufferBytes, streamGetInterleave, streamGetChunk = oldStdin, oldConc, oldBuf, oldInter, oldChunk
+		streamGetRanges, streamGetTail = oldRanges, oldTail
+	})
+	streamGetStdin = stdin != ""
+	streamGetConcurrency = 4
+	streamGetMaxBufferBytes = defaultStreamGetMaxBufferBytes
+	streamGetInterleave = false
+	streamGetChunk = 4
+	streamGetRanges = nil
+	streamGetTail = 0
+	if configure != nil {
+		configure()
+	}
+
+	cmd := &cobra.Command{Use: "get"}
+	cmd.SetContext(context.Background())
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetIn(strings.NewReader(stdin))
+	err := runStreamGet(cmd, args)
+	return out.Bytes(), err
+}
+
+// TestStreamGetStdinEmitsWholeStreamsInInputOrder fetches concurrently with
+// the first object slowest and one object over the buffer budget, and still
+// gets one complete stream per object in input order, with failures reported
+// in place.
+func TestStreamGetStdinEmitsWholeStreamsInInputOrder(t *testing.T) {
+	prov := &streamGetBatchProvider{
+		objects: map[string][]byte{
+			"a.txt":   []byte("alpha"),
+			"b.txt":   []byte("bravo-bravo-bravo"),
+			"c.txt":   []byte("charlie"),
+			"d/e.xml": []byte("<e/>"),
+		},
+		slow: map[string]time.Duration{"a.txt": 50 * time.Millisecond},
+	}
+	stdin := strings.Join([]string{
+		"s3://bucket/a.txt",
+		"s3://bucket/missing.txt",
+		`{"type":"gonimbus.index.object.v1","data":{"base_uri":"s3://bucket/","key":"b.txt"}}`,
+		"s3://bucket/prefix/",
+		`{"type":"gonimbus.reflow.input.v1","data":{"source_uri":"s3://bucket/c.txt"}}`,
+		"s3://bucket/d/*.xml",
+	}, "\n")
+
+	out, err := runStreamGetBatchTest(t, prov, nil, stdin, func() { streamGetMaxBufferBytes = 10 })
+	require.ErrorContains(t, err, "completed with invalid inputs")
+
+	streams, errs := decodeStreamGetOutput(t, out, true)
+	var uris, bodies []string
+	for _, s := range streams {
+		require.Equal(t, "success", s.status)
+		uris = append(uris, s.uri)
+		bodies = append(bodies, string(s.data))
+	}
+	require.Equal(t, []string{"s3://bucket/a.txt", "s3://bucket/b.txt", "s3://bucket/c.txt", "s3://bucket/d/e.xml"}, uris)
+	require.Equal(t, []string{"alpha", "bravo-bravo-bravo", "charlie", "<e/>"}, bodies)
+
+	require.Len(t, errs, 2)
+	require.Equal(t, output.ErrCodeNotFound, errs[0].Code)
+	require.Equal(t, "missing.txt", errs[0].Key)
+	require.Equal(t, output.ErrCodeInvalidInput, errs[1].Code)
+	require.EqualValues(t, 4, errs[1].Details.(map[string]any)["line"])
+	require.Equal(t, int64(4), prov.heads.Load(), "the glob's object uses listing metadata")
+}
+
+func TestStreamGetGlobInterleavedStreamsEveryMatch(t *testing.T) {
+	objects := map[string][]byte{}
+	for i := 0; i < 12; i++ {
+		objects[fmt.Sprintf("logs/%02d.log", i)] = bytes.Repeat([]byte{byte('a' + i)}, 9+i)
+	}
+	objects["logs/skip.txt"] = []byte("no")
+	prov := &streamGetBatchProvider{objects: objects}
+
+	out, err := runStreamGetBatchTest(t, prov, []string{"s3://bucket/logs/*.log"}, "", func() { streamGetInterleave = true })
+	require.NoError(t, err)
+
+	streams, errs := decodeStreamGetOutput(t, out, false)
+	require.Empty(t, errs)
+	require.Len(t, streams, 12)
+	for _, s := range streams {
+		require.Equal(t, "success", s.status)
+		require.Equal(t, objects[strings.TrimPrefix(s.uri, "s3://bucket/")], s.data)
+	}
+	require.Zero(t, prov.heads.Load())
+}
+
+func TestStreamGetBudgetWaitsForRelease(t *testing.T) {
+	b := newStreamGetBudget(10)
+	require.NoError(t, b.reserve(context.Background(), 8))
+
+	got := make(chan error, 1)
+	go func() { got <- b.reserve(context.Background(), 5) }()
+	select {
+	case <-got:
+		t.Fatal("reserve over budget must wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+	b.release(8)
+	require.NoError(t, <-got)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, b.reserve(ctx, 6), context.Canceled)
+}
+
+// TestStreamGetRangesEmitOneStreamPerRange reads several ranges of one object
+// and gets one stream per range, in request order, with chunk offsets at the
+// object positions the bytes came from.
+func TestStreamGetRangesEmitOneStreamPerRange(t *testing.T) {
+	prov := &streamGetBatchProvider{objects: map[string][]byte{"data.parquet": []byte("0123456789ABCDEF")}}
+
+	out, err := runStreamGetBatchTest(t, prov, []string{"s3://bucket/data.parquet"}, "", func() {
+		streamGetRanges = []string{"0-3,10-", "12-99"}
+		streamGetTail = 2
+	})
+	require.NoError(t, err)
+
+	streams, errs := decodeStreamGetOutput(t, out, true)
+	require.Empty(t, errs)
+	require.Len(t, streams, 4)
+	want := []struct {
+		rng     stream.ByteRange
+		data    string
+		offsets []int64
+	}{
+		{stream.ByteRange{Start: 0, End: 3}, "0123", []int64{0}},
+		{stream.ByteRange{Start: 10, End: 15}, "ABCDEF", []int64{10, 14}},
+		{stream.ByteRange{Start: 12, End: 15}, "CDEF", []int64{12}},
+		{stream.ByteRange{Start: 14, End: 15}, "EF", []int64{14}},
+	}
+	for i, w := range want {
+		require.Equal(t, "success", streams[i].status)
+		require.Equal(t, &w.rng, streams[i].rng)
+		require.Equal(t, w.data, string(streams[i].data))
+		require.Equal(t, w.offsets, streams[i].offsets)
+	}
+	require.Equal(t, int64(4), prov.ranges.Load())
+}
+
+// TestStreamGetTailAcrossBatch takes the tail of every stdin object, buffered
+// and live, and fails only the object a range starts past.
+func TestStreamGetTailAcrossBatch(t *testing.T) {
+	prov := &streamGetBatchProvider{objects: map[string][]byte{
+		"a.log":     []byte("first line\nlast line\n"),
+		"b.log":     []byte("ab"),
+		"empty.log": nil,
+	}}
+	stdin := "s3://bucket/a.log\ns3://bucket/b.log\ns3://bucket/empty.log"
+
+	out, err := runStreamGetBatchTest(t, prov, nil, stdin, func() {
+		streamGetTail = 10
+		streamGetMaxBufferBytes = 4
+	})
+	require.NoError(t, err)
+	streams, errs := decodeStreamGetOutput(t, out, true)
+	require.Empty(t, errs)
+	require.Len(t, streams, 3)
+	require.Equal(t, "last line\n", string(streams[0].data))
+	require.Equal(t, &stream.ByteRange{Start: 11, End: 20}, streams[0].rng)
+	require.Equal(t, "ab", string(streams[1].data))
+	require.Equal(t, &stream.ByteRange{Start: 0, End: -1}, streams[2].rng)
+	require.Empty(t, streams[2].data)
+	require.Equal(t, int64(2), prov.ranges.Load(), "an empty range makes no request")
+
+	out, err = runStreamGetBatchTest(t, prov, nil, stdin, func() { streamGetRanges = []string{"3-"} })
+	require.ErrorContains(t, err, "completed with invalid inputs")
+	streams, errs = decodeStreamGetOutput(t, out, true)
+	require.Len(t, streams, 1)
+	require.Equal(t, "st line\nlast line\n", string(streams[0].data))
+	require.Len(t, errs, 2)
+	require.Equal(t, "b.log", errs[0].Key)
+	require.Equal(t, output.ErrCodeInvalidInput, errs[0].Code)
+}
+
+func TestParseStreamGetRanges(t *testing.T) {
+	specs, err := parseStreamGetRanges([]string{"5-9, 7-", "-3"}, 4)
+	require.NoError(t, err)
+	require.Equal(t, []streamGetRangeSpec{{start: 5, end: 9}, {start: 7, end: -1}, {suffix: 3}, {suffix: 4}}, specs)
+
+	specs, err = parseStreamGetRanges(nil, 0)
+	require.NoError(t, err)
+	require.Nil(t, specs)
+
+	for _, bad := range []string{"", "5", "9-5", "-0", "a-b", "-1-2", "1-x"} {
+		_, err := parseStreamGetRanges([]string{bad}, 0)
+		require.Error(t, err, bad)
+	}
+	_, err = parseStreamGetRanges(nil, -1)
+	require.Error(t, err)
+}
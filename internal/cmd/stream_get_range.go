@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/3leaps/gonimbus/pkg/stream"
+)
+
+// streamGetRangeSpec is one requested byte range before it is fixed against
+// an object's size: start-end (end inclusive), start- (to the end of the
+// object), or -n (the last n bytes).
+type streamGetRangeSpec struct {
+	start  int64
+	end    int64 // -1: to the end of the object
+	suffix int64 // > 0: the last suffix bytes; start and end are unused
+}
+
+// parseStreamGetRanges reads --range values (each may list several ranges,
+// comma-separated) and --tail into specs, in the order given. It returns nil
+// when no range was requested.
+func parseStreamGetRanges(values []string, tail int64) ([]streamGetRangeSpec, error) {
+	if tail < 0 {
+		return nil, fmt.Errorf("tail must be >= 0")
+	}
+	var specs []streamGetRangeSpec
+	for _, value := range values {
+		for _, raw := range strings.Split(value, ",") {
+			spec, err := parseStreamGetRange(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, spec)
+		}
+	}
+	if tail > 0 {
+		specs = append(specs, streamGetRangeSpec{suffix: tail})
+	}
+	return specs, nil
+}
+
+func parseStreamGetRange(raw string) (streamGetRangeSpec, error) {
+	startText, endText, ok := strings.Cut(raw, "-")
+	if !ok {
+		return streamGetRangeSpec{}, fmt.Errorf("invalid range %q: want start-end, start-, or -n", raw)
+	}
+	if startText == "" {
+		n, err := strconv.ParseInt(endText, 10, 64)
+		if err != nil || n <= 0 {
+			return streamGetRangeSpec{}, fmt.Errorf("invalid range %q: suffix length must be a positive integer", raw)
+		}
+		return streamGetRangeSpec{suffix: n}, nil
+	}
+	start, err := strconv.ParseInt(startText, 10, 64)
+	if err != nil || start < 0 {
+		return streamGetRangeSpec{}, fmt.Errorf("invalid range %q: start must be a non-negative integer", raw)
+	}
+	if endText == "" {
+		return streamGetRangeSpec{start: start, end: -1}, nil
+	}
+	end, err := strconv.ParseInt(endText, 10, 64)
+	if err != nil || end < start {
+		return streamGetRangeSpec{}, fmt.Errorf("invalid range %q: end must be an integer >= start", raw)
+	}
+	return streamGetRangeSpec{start: start, end: end}, nil
+}
+
+// resolveStreamGetRanges fixes specs against an object of size bytes. Ends
+// past the object are clamped to its last byte; a range starting exactly at
+// the end (or a suffix of an empty object) is empty, End = Start - 1. A range
+// starting past the end fails the object.
+func resolveStreamGetRanges(specs []streamGetRangeSpec, size int64) ([]stream.ByteRange, error) {
+	if size < 0 {
+		return nil, &streamGetInputError{msg: "object size is unknown; byte ranges need it"}
+	}
+	out := make([]stream.ByteRange, 0, len(specs))
+	for _, spec := range specs {
+		if spec.suffix > 0 {
+			out = append(out, stream.ByteRange{Start: max(0, size-spec.suffix), End: size - 1})
+			continue
+		}
+		if spec.start > size {
+			return nil, &streamGetInputError{msg: fmt.Sprintf("range start %d is past the end of the object (size %d)", spec.start, size)}
+		}
+		end := spec.end
+		if end < 0 || end >= size {
+			end = size - 1
+		}
+		out = append(out, stream.ByteRange{Start: spec.start, End: end})
+	}
+	return out, nil
+}
@@ -329,6 +329,9 @@ func readStreamPutFramedObject(ctx context.Context, dec *stream.Decoder, root st
 	if strings.TrimSpace(open.StreamID) == "" {
 		return nil, errors.New("stream open record missing stream_id")
 	}
+	if open.Range != nil {
+		return nil, errors.New("stream open record carries a byte range; stream put writes whole objects only")
+	}
 	destKey, err := root.keyForOpen(open, streamPutDestFrame, objectIndex)
 	if err != nil {
 		return nil, err
@@ -319,6 +319,13 @@ func TestStreamPutCommand_JSONLFramingRejectsMalformedInput(t *testing.T) {
 			},
 			wantErr: "duplicate stream open record",
 		},
+		{
+			name: "ranged open",
+			input: func(t *testing.T) []byte {
+				return buildStreamPutFramedInputWithOpen(t, stream.Open{StreamID: "stream-1", URI: "s3://source/a.bin", Range: &stream.ByteRange{Start: 0, End: 2}}, [][]byte{[]byte("abc")})
+			},
+			wantErr: "stream open record carries a byte range",
+		},
 		{
 			name: "chunk before open",
 			input: func(t *testing.T) []byte {
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fulmenhq/gofulmen/signals"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/3leaps/gonimbus/internal/observability"
+	"github.com/3leaps/gonimbus/internal/providerdispatch"
+	"github.com/3leaps/gonimbus/internal/tracing"
+)
+
+var (
+	traceExporter string
+	traceFile     string
+	traceEndpoint string
+
+	tracingStop func(failed bool)
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&traceExporter, "trace-exporter", "", "Export OpenTelemetry traces: none, otlp, or file (env: "+tracing.EnvExporter+")")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "JSON-lines file for --trace-exporter file (env: "+tracing.EnvFile+")")
+	rootCmd.PersistentFlags().StringVar(&traceEndpoint, "trace-endpoint", "", "OTLP/HTTP endpoint URL for --trace-exporter otlp (env: "+tracing.EnvEndpoint+", default: OTEL_EXPORTER_OTLP_ENDPOINT)")
+}
+
+func resolvedTracingConfig() tracing.Config {
+	pick := func(flag, env string) string {
+		if v := strings.TrimSpace(flag); v != "" {
+			return v
+		}
+		return strings.TrimSpace(os.Getenv(env))
+	}
+	cfg := tracing.Config{
+		Exporter:       pick(traceExporter, tracing.EnvExporter),
+		FilePath:       pick(traceFile, tracing.EnvFile),
+		Endpoint:       pick(traceEndpoint, tracing.EnvEndpoint),
+		ServiceName:    "gonimbus",
+		ServiceVersion: versionInfo.Version,
+	}
+	if cfg.FilePath != "" {
+		if abs, err := filepath.Abs(cfg.FilePath); err == nil {
+			cfg.FilePath = abs
+		}
+	}
+	return cfg
+}
+
+// startTracing installs the trace exporter, routes provider HTTP requests
+// through the tracing transport, and opens the command's root span, parented
+// on TRACEPARENT when a server or caller supplied one.
+//
+// `serve` gets no root span: it is long-lived, and its spans are per request.
+// It instead publishes the resolved exporter to its environment so the managed
+// job children it spawns trace to the same destination.
+func startTracing(cmd *cobra.Command) error {
+	cfg := resolvedTracingConfig()
+	if !cfg.Enabled() {
+		return nil
+	}
+	shutdownProvider, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		return fmt.Errorf("initialize tracing: %w", err)
+	}
+	providerdispatch.SetTransportWrapper(tracing.WrapTransport)
+
+	shutdown := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownProvider(ctx); err != nil && observability.CLILogger != nil {
+			observability.CLILogger.Warn("Failed to flush traces", zap.Error(err))
+		}
+	}
+
+	if cmd.Name() == "serve" {
+		_ = os.Setenv(tracing.EnvExporter, cfg.Exporter)
+		if cfg.Endpoint != "" {
+			_ = os.Setenv(tracing.EnvEndpoint, cfg.Endpoint)
+		}
+		signals.OnShutdown(func(context.Context) error {
+			shutdown()
+			return nil
+		})
+		tracingStop = func(bool) { shutdown() }
+		return nil
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := tracing.Tracer().Start(tracing.ContextFromEnv(ctx), cmd.CommandPath(), trace.WithSpanKind(trace.SpanKindInternal))
+	cmd.SetContext(ctx)
+	tracingStop = func(failed bool) {
+		if failed {
+			span.SetStatus(codes.Error, "command failed")
+		}
+		span.End()
+		shutdown()
+	}
+	return nil
+}
+
+// flushTracing ends the command's root span and flushes buffered spans.
+func flushTracing(failed bool) {
+	if tracingStop == nil {
+		return
+	}
+	stop := tracingStop
+	tracingStop = nil
+	stop(failed)
+}
@@ -101,6 +101,9 @@ func showTransferPlan(m *manifest.TransferManifest) error {
 	if m.Transfer.Sharding.Enabled {
 		fmt.Printf("Sharding: enabled=true depth=%d max_shards=%d list_concurrency=%d delimiter=%q\n", m.Transfer.Sharding.Depth, m.Transfer.Sharding.MaxShards, m.Transfer.Sharding.ListConcurrency, m.Transfer.Sharding.Delimiter)
 	}
+	if m.Transfer.Sharding.KeyRanges > 1 {
+		fmt.Printf("KeyRanges: %d per listing prefix\n", m.Transfer.Sharding.KeyRanges)
+	}
 	fmt.Printf("Dedup:    enabled=%v strategy=%s\n", m.Transfer.Dedup.DedupEnabled(), m.Transfer.Dedup.Strategy)
 	fmt.Printf("Preflight: mode=%s probe_strategy=%s probe_prefix=%s\n", m.Transfer.Preflight.Mode, m.Transfer.Preflight.ProbeStrategy, m.Transfer.Preflight.ProbePrefix)
 	if m.Transfer.PathTemplate != "" {
@@ -196,6 +199,7 @@ func executeTransfer(ctx context.Context, m *manifest.TransferManifest, dryRun b
 			MaxShards:       m.Transfer.Sharding.MaxShards,
 			ListConcurrency: m.Transfer.Sharding.ListConcurrency,
 			Delimiter:       m.Transfer.Sharding.Delimiter,
+			KeyRanges:       m.Transfer.Sharding.KeyRanges,
 		},
 		Dedup: transfer.DedupConfig{
 			Enabled:  m.Transfer.Dedup.DedupEnabled(),
@@ -19,6 +19,7 @@ import (
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
+	"github.com/3leaps/gonimbus/internal/metrics"
 	"github.com/3leaps/gonimbus/internal/observability"
 	"github.com/3leaps/gonimbus/internal/reflowprobe"
 	"github.com/3leaps/gonimbus/pkg/opcheckpoint"
@@ -550,6 +551,7 @@ func runTransferReflowWithRunID(cmd *cobra.Command, args []string, runID string)
 		fatalRun     *reflowFatalRunError
 	)
 	stats := newReflowRunStats()
+	stats.metricLabels = reflowMetricLabels(positionalReflowSource(args))
 	writeReflowRecord := func(ctx context.Context, rec reflowpkg.Record) {
 		stats.record(rec)
 		_ = w.WriteAny(ctx, reflowpkg.RecordType, rec)
@@ -1289,10 +1291,14 @@ func runTransferReflowWithRunID(cmd *cobra.Command, args []string, runID string)
 	}
 	close(tasks)
 	wg.Wait()
-	_ = w.WriteAny(context.Background(), reflowpkg.SummaryRecordType, stats.summary(destURI, reflowDryRun, collCfg, ifAbsentCapability, concurrencyLimiter.Snapshot(), invalidCount.Load(), errorCount.Load()))
+	summaryRec := stats.summary(destURI, reflowDryRun, collCfg, ifAbsentCapability, concurrencyLimiter.Snapshot(), invalidCount.Load(), errorCount.Load())
+	metrics.RecordReflowConcurrency(stats.metricLabels, summaryRec.ConcurrencyStats, true)
+	_ = w.WriteAny(context.Background(), reflowpkg.SummaryRecordType, summaryRec)
 	// Sterile object-path stage attribution (permit wait / source read / dest write).
 	stageStats.AttachLimiterPeaks(concurrencyLimiter)
-	_ = w.WriteAny(context.Background(), reflowpkg.ObjectPathStageStatsRecordType, stageStats.Snapshot())
+	stageRec := stageStats.Snapshot()
+	metrics.RecordReflowStageStats(stats.metricLabels, stageRec)
+	_ = w.WriteAny(context.Background(), reflowpkg.ObjectPathStageStatsRecordType, stageRec)
 	// Sterile checkpoint-writer diagnostics for measure-first (checkpoint measure).
 	// Snapshot before Close (deferred); process-local aggregates only.
 	emitCheckpointWriterStatsIfPresent(context.Background(), w, state)
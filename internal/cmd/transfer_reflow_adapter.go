@@ -12,6 +12,7 @@ import (
 
 	"github.com/fulmenhq/gofulmen/foundry"
 
+	"github.com/3leaps/gonimbus/internal/metrics"
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/partition"
 	"github.com/3leaps/gonimbus/pkg/producer"
@@ -126,6 +127,7 @@ func planTransferReflowEngineAdapter(ctx context.Context, input io.Reader, first
 	var (
 		srcProv             provider.Provider
 		srcProviderIdentity string
+		stopBudgetMetrics   func()
 	)
 	srcResolver := func(ctx context.Context, sourceURI string) (provider.Provider, error) {
 		parsed, err := uri.ParseURI(sourceURI)
@@ -191,7 +193,7 @@ func planTransferReflowEngineAdapter(ctx context.Context, input io.Reader, first
 		// would reclassify as a pattern when the engine parses it and be refused.
 		// The parsed value still builds the provider, and selects the source form.
 		if positional.IsPrefix() || positional.IsPattern() {
-			enumerator, authority, enumErr := newPrefixLaneEnumerator(positional, positionalSource, binding, concurrencyCfg)
+			enumerator, authority, budget, enumErr := newPrefixLaneEnumerator(positional, positionalSource, binding, concurrencyCfg)
 			if enumErr != nil {
 				_ = p.Close()
 				srcProv = nil
@@ -199,6 +201,9 @@ func planTransferReflowEngineAdapter(ctx context.Context, input io.Reader, first
 				plan.reason = "partitioned source unavailable"
 				return plan
 			}
+			if budget != nil {
+				stopBudgetMetrics = metrics.WatchBudget(reflowMetricLabels(positionalSource), budget.Snapshot, 0)
+			}
 			plan.source = reflowpkg.PrefixSource{
 				Provider:   p,
 				URI:        positionalSource,
@@ -215,6 +220,9 @@ func planTransferReflowEngineAdapter(ctx context.Context, input io.Reader, first
 		}
 	}
 	plan.close = func() {
+		if stopBudgetMetrics != nil {
+			stopBudgetMetrics()
+		}
 		if srcProv != nil {
 			_ = srcProv.Close()
 		}
@@ -351,12 +359,12 @@ func runTransferReflowViaEngine(ctx context.Context, plan transferReflowEnginePl
 	if plan.close != nil {
 		defer plan.close()
 	}
-	plan.cfg.Events = transferReflowEventSink{
+	plan.cfg.Events = metrics.NewReflowEventSink(transferReflowEventSink{
 		w:              w,
 		checkpointPath: checkpointPath,
 		resume:         resume,
 		metadata:       metadataRunConfig(metaCfg),
-	}
+	})
 	runner, err := reflowpkg.NewRunner(plan.cfg)
 	if err != nil {
 		return reflowpkg.Summary{}, err
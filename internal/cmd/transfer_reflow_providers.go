@@ -92,19 +92,21 @@ func newSourceBinding(ctx context.Context, src *uri.ObjectURI, concurrency reflo
 	})
 }
 
-func newPrefixLaneEnumerator(src *uri.ObjectURI, selector string, binding *providerdispatch.SourceBinding, concurrency reflowpkg.ConcurrencyConfig) (producer.LaneEnumerator, *partition.Authority, error) {
+// newPrefixLaneEnumerator also returns the request budget bounding the lane
+// listing so the caller can sample its usage.
+func newPrefixLaneEnumerator(src *uri.ObjectURI, selector string, binding *providerdispatch.SourceBinding, concurrency reflowpkg.ConcurrencyConfig) (producer.LaneEnumerator, *partition.Authority, *runbudget.Budget, error) {
 	if src == nil {
-		return nil, nil, fmt.Errorf("source URI is nil")
+		return nil, nil, nil, fmt.Errorf("source URI is nil")
 	}
 	if binding == nil || binding.Provider == nil {
-		return nil, nil, fmt.Errorf("source provider binding is nil")
+		return nil, nil, nil, fmt.Errorf("source provider binding is nil")
 	}
 	// The partition contract refuses an empty prefix because it names a whole
 	// scope rather than an explicit partition. Preserve the standing
 	// unpartitioned whole-bucket path until a scope compiler supplies explicit
 	// partitions for it.
 	if src.Key == "" {
-		return nil, nil, nil
+		return nil, nil, nil, nil
 	}
 
 	ceiling := concurrency.EffectiveCeiling
@@ -115,7 +117,7 @@ func newPrefixLaneEnumerator(src *uri.ObjectURI, selector string, binding *provi
 		InFlight: map[runbudget.OpClass]int{runbudget.OpList: ceiling},
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	fingerprint := sha256.Sum256([]byte("transfer-reflow-prefix-enumeration-v1\x00" + selector))
 	authority, err := partition.CompileAuthority(partition.PlanRequest{
@@ -126,7 +128,7 @@ func newPrefixLaneEnumerator(src *uri.ObjectURI, selector string, binding *provi
 		MaxLanes:          1,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	executor, err := producer.NewLaneExecutor(producer.LaneExecutorConfig{
 		Authority:     authority,
@@ -136,9 +138,9 @@ func newPrefixLaneEnumerator(src *uri.ObjectURI, selector string, binding *provi
 		QueueCapacity: ceiling * 2,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	return executor, authority, nil
+	return executor, authority, budget, nil
 }
 
 func reflowSourceIdentity(src *uri.ObjectURI) string {
@@ -3,7 +3,9 @@ package cmd
 import (
 	"sync"
 
+	"github.com/3leaps/gonimbus/internal/metrics"
 	reflowpkg "github.com/3leaps/gonimbus/pkg/reflow"
+	"github.com/3leaps/gonimbus/pkg/uri"
 )
 
 type reflowRunStats struct {
@@ -11,16 +13,33 @@ type reflowRunStats struct {
 	statuses        map[string]int64
 	collisions      map[string]int64
 	fallbackObjects int64
+	metricLabels    metrics.JobLabels
 }
 
 func newReflowRunStats() *reflowRunStats {
 	return &reflowRunStats{
-		statuses:   map[string]int64{},
-		collisions: map[string]int64{},
+		statuses:     map[string]int64{},
+		collisions:   map[string]int64{},
+		metricLabels: metrics.JobLabels{JobType: metrics.JobTypeReflow},
 	}
 }
 
+// reflowMetricLabels labels pool-path metrics by the positional source. Stdin
+// runs may span sources, so they carry only the job type.
+func reflowMetricLabels(source string) metrics.JobLabels {
+	labels := metrics.JobLabels{JobType: metrics.JobTypeReflow}
+	if source == "" {
+		return labels
+	}
+	if parsed, err := uri.ParseURI(source); err == nil {
+		labels.Provider = parsed.Provider
+		labels.Bucket = parsed.Bucket
+	}
+	return labels
+}
+
 func (s *reflowRunStats) record(rec reflowpkg.Record) {
+	metrics.RecordReflowObject(s.metricLabels, rec.Status, rec.Bytes)
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if rec.Status != "" {
@@ -0,0 +1,436 @@
+// Package envelope holds the client-side encryption gonimbus applies to index
+// artifacts it publishes to a hub. It is internal: not a Stable library
+// surface.
+//
+// Each envelope has its own random AES-256-GCM data key. The data key is
+// wrapped for every recipient X25519 public key (ECDH with a fresh ephemeral
+// key, HKDF-SHA256, AES-256-GCM) and the wrapped copies travel in the
+// envelope header, so any one recipient's private key opens it. The body is
+// sealed in fixed-size chunks, in the STREAM construction age uses: each
+// chunk's nonce carries its index and a final-chunk flag, and every chunk
+// authenticates the header, so chunks cannot be reordered, dropped, truncated,
+// or moved to another envelope.
+//
+// Keys are PEM, as openssl writes them: a PKCS#8 X25519 private key
+// (`openssl genpkey -algorithm x25519`) and its PKIX public key
+// (`openssl pkey -pubout`).
+package envelope
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// Scheme identifies the envelope format in headers and in the hub
+	// metadata that describes encrypted artifacts.
+	Scheme = "gonimbus.envelope.v1"
+	// Cipher is the body and key-wrap cipher.
+	Cipher = "aes-256-gcm"
+	// KeyWrapX25519 is the recipient stanza type: an X25519 key agreement
+	// with an ephemeral key, expanded by HKDF-SHA256.
+	KeyWrapX25519 = "x25519-hkdf-sha256"
+	// ChunkSize is the plaintext size of every body chunk but the last.
+	ChunkSize = 64 << 10
+
+	magic          = "gonimbus-envelope/v1\n"
+	maxHeaderBytes = 64 << 10
+	minChunkSize   = 1 << 10
+	maxChunkSize   = 16 << 20
+	keySize        = 32
+	tagSize        = 16
+	wrapInfo       = "gonimbus envelope x25519 v1"
+)
+
+var (
+	// ErrNoIdentity reports an envelope that none of the caller's private
+	// keys can open.
+	ErrNoIdentity = errors.New("no private key matches the envelope's recipients")
+	// ErrDecrypt reports an envelope whose body or wrapped key does not
+	// authenticate: it was altered, truncated, or is not an envelope.
+	ErrDecrypt = errors.New("envelope does not authenticate")
+)
+
+// Header is the cleartext head of an envelope.
+type Header struct {
+	Type       string   `json:"type"`
+	Cipher     string   `json:"cipher"`
+	ChunkSize  int      `json:"chunk_size"`
+	Recipients []Stanza `json:"recipients"`
+	raw        []byte   // exact header bytes, authenticated by every chunk
+}
+
+// Stanza is the data key wrapped for one recipient. Ephemeral and WrappedKey
+// are standard base64.
+type Stanza struct {
+	Type       string `json:"type"`
+	KeyID      string `json:"key_id"`
+	Ephemeral  string `json:"ephemeral"`
+	WrappedKey string `json:"wrapped_key"`
+}
+
+// KeyIDs returns the recipient key IDs in header order.
+func (h *Header) KeyIDs() []string {
+	ids := make([]string, 0, len(h.Recipients))
+	for _, s := range h.Recipients {
+		ids = append(ids, s.KeyID)
+	}
+	return ids
+}
+
+// KeyID returns the short identifier for a recipient public key: the first 16
+// hex characters of the SHA-256 of the raw key, as signing.KeyID does for
+// ed25519 keys.
+func KeyID(pub *ecdh.PublicKey) string {
+	sum := sha256.Sum256(pub.Bytes())
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// LoadIdentity reads a PEM-encoded PKCS#8 X25519 private key.
+func LoadIdentity(path string) (*ecdh.PrivateKey, error) {
+	raw, err := os.ReadFile(path) // #nosec G304 -- operator-supplied key path.
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseIdentity(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ParseIdentity decodes a PEM-encoded PKCS#8 X25519 private key held in
+// memory, such as one passed through the environment.
+func ParseIdentity(raw []byte) (*ecdh.PrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdh.PrivateKey)
+	if !ok || key.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("key is %T, want x25519", parsed)
+	}
+	return key, nil
+}
+
+// LoadRecipients reads one or more PEM "PUBLIC KEY" blocks holding X25519
+// keys, concatenated in a single file.
+func LoadRecipients(path string) ([]*ecdh.PublicKey, error) {
+	raw, err := os.ReadFile(path) // #nosec G304 -- operator-supplied recipients path.
+	if err != nil {
+		return nil, err
+	}
+	var keys []*ecdh.PublicKey
+	seen := map[string]bool{}
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		pub, ok := parsed.(*ecdh.PublicKey)
+		if !ok || pub.Curve() != ecdh.X25519() {
+			return nil, fmt.Errorf("%s: key is %T, want x25519", path, parsed)
+		}
+		if id := KeyID(pub); !seen[id] {
+			seen[id] = true
+			keys = append(keys, pub)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no PEM PUBLIC KEY blocks found", path)
+	}
+	return keys, nil
+}
+
+// Encrypt seals src to every recipient and writes the envelope to dst.
+func Encrypt(dst io.Writer, src io.Reader, recipients []*ecdh.PublicKey) error {
+	if len(recipients) == 0 {
+		return errors.New("envelope needs at least one recipient")
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return err
+	}
+	header := Header{Type: Scheme, Cipher: Cipher, ChunkSize: ChunkSize}
+	for _, recipient := range recipients {
+		stanza, err := wrapKey(dataKey, recipient)
+		if err != nil {
+			return err
+		}
+		header.Recipients = append(header.Recipients, stanza)
+	}
+	raw, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	prefix := encodePrefix(raw)
+	if _, err := dst.Write(prefix); err != nil {
+		return err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+	aad := sha256.Sum256(prefix)
+
+	// Read one chunk ahead so the last chunk can carry the final flag.
+	cur, next := make([]byte, ChunkSize), make([]byte, ChunkSize)
+	n, err := readChunk(src, cur)
+	if err != nil {
+		return err
+	}
+	out := make([]byte, 0, ChunkSize+aead.Overhead())
+	for index := uint64(0); ; index++ {
+		var m int
+		if n == ChunkSize {
+			if m, err = readChunk(src, next); err != nil {
+				return err
+			}
+		}
+		final := n < ChunkSize || m == 0
+		out = aead.Seal(out[:0], chunkNonce(index, final), cur[:n], aad[:])
+		if _, err := dst.Write(out); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+		cur, next, n = next, cur, m
+	}
+}
+
+// MaxSealedSize bounds the size of an envelope holding at most n bytes of
+// plaintext, for callers that cap what they download.
+func MaxSealedSize(n int64) int64 {
+	return n + (n/ChunkSize+1)*tagSize + int64(len(magic)+4+maxHeaderBytes)
+}
+
+// ReadHeader reads and checks an envelope header without decrypting
+// anything. src is left positioned at the first body chunk.
+func ReadHeader(src io.Reader) (*Header, error) {
+	var fixed [len(magic) + 4]byte
+	if _, err := io.ReadFull(src, fixed[:]); err != nil {
+		return nil, fmt.Errorf("%w: short header", ErrDecrypt)
+	}
+	if string(fixed[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: not a %s envelope", ErrDecrypt, Scheme)
+	}
+	size := binary.BigEndian.Uint32(fixed[len(magic):])
+	if size == 0 || size > maxHeaderBytes {
+		return nil, fmt.Errorf("%w: header size %d out of range", ErrDecrypt, size)
+	}
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(src, raw); err != nil {
+		return nil, fmt.Errorf("%w: short header", ErrDecrypt)
+	}
+	var header Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("%w: parse header: %v", ErrDecrypt, err)
+	}
+	switch {
+	case header.Type != Scheme:
+		return nil, fmt.Errorf("unsupported envelope type %q", header.Type)
+	case header.Cipher != Cipher:
+		return nil, fmt.Errorf("unsupported envelope cipher %q", header.Cipher)
+	case header.ChunkSize < minChunkSize || header.ChunkSize > maxChunkSize:
+		return nil, fmt.Errorf("%w: chunk size %d out of range", ErrDecrypt, header.ChunkSize)
+	case len(header.Recipients) == 0:
+		return nil, fmt.Errorf("%w: no recipients", ErrDecrypt)
+	}
+	header.raw = raw
+	return &header, nil
+}
+
+// Decrypt opens the envelope in src with the first identity that is one of
+// its recipients and writes the plaintext to dst. Plaintext is written as
+// each chunk authenticates, so on error dst may hold a prefix of it; callers
+// must discard dst unless Decrypt returns nil.
+func Decrypt(dst io.Writer, src io.Reader, identities ...*ecdh.PrivateKey) error {
+	in := bufio.NewReader(src)
+	header, err := ReadHeader(in)
+	if err != nil {
+		return err
+	}
+	dataKey, err := header.unwrap(identities)
+	if err != nil {
+		return err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+	aad := sha256.Sum256(encodePrefix(header.raw))
+	buf := make([]byte, header.ChunkSize+aead.Overhead())
+	var plain []byte
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(in, buf)
+		switch {
+		case err == io.EOF:
+			return fmt.Errorf("%w: truncated", ErrDecrypt)
+		case err != nil && err != io.ErrUnexpectedEOF:
+			return err
+		}
+		final := n < len(buf)
+		if !final {
+			if _, peekErr := in.Peek(1); peekErr == io.EOF {
+				final = true
+			} else if peekErr != nil {
+				return peekErr
+			}
+		}
+		plain, err = aead.Open(plain[:0], chunkNonce(index, final), buf[:n], aad[:])
+		if err != nil {
+			return fmt.Errorf("%w: chunk %d", ErrDecrypt, index)
+		}
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+func (h *Header) unwrap(identities []*ecdh.PrivateKey) ([]byte, error) {
+	for _, identity := range identities {
+		if identity == nil {
+			continue
+		}
+		id := KeyID(identity.PublicKey())
+		for _, stanza := range h.Recipients {
+			if stanza.Type != KeyWrapX25519 || stanza.KeyID != id {
+				continue
+			}
+			return unwrapKey(stanza, identity)
+		}
+	}
+	return nil, ErrNoIdentity
+}
+
+func wrapKey(dataKey []byte, recipient *ecdh.PublicKey) (Stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return Stanza{}, err
+	}
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return Stanza{}, err
+	}
+	wrapping, err := wrappingKey(shared, ephemeral.PublicKey(), recipient)
+	if err != nil {
+		return Stanza{}, err
+	}
+	aead, err := newGCM(wrapping)
+	if err != nil {
+		return Stanza{}, err
+	}
+	wrapped := aead.Seal(nil, make([]byte, aead.NonceSize()), dataKey, nil)
+	return Stanza{
+		Type:       KeyWrapX25519,
+		KeyID:      KeyID(recipient),
+		Ephemeral:  base64.StdEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+	}, nil
+}
+
+func unwrapKey(stanza Stanza, identity *ecdh.PrivateKey) ([]byte, error) {
+	rawEphemeral, err := base64.StdEncoding.DecodeString(stanza.Ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed ephemeral key", ErrDecrypt)
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(rawEphemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed ephemeral key", ErrDecrypt)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(stanza.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed wrapped key", ErrDecrypt)
+	}
+	shared, err := identity.ECDH(ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	wrapping, err := wrappingKey(shared, ephemeral, identity.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(wrapping)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), wrapped, nil)
+	if err != nil || len(dataKey) != keySize {
+		return nil, fmt.Errorf("%w: wrapped key", ErrDecrypt)
+	}
+	return dataKey, nil
+}
+
+// wrappingKey derives the key-wrap key from an X25519 shared secret. The salt
+// binds it to both the ephemeral and the recipient public key.
+func wrappingKey(shared []byte, ephemeral, recipient *ecdh.PublicKey) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeral.Bytes()...), recipient.Bytes()...)
+	return hkdf.Key(sha256.New, shared, salt, wrapInfo, keySize)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encodePrefix(header []byte) []byte {
+	prefix := make([]byte, 0, len(magic)+4+len(header))
+	prefix = append(prefix, magic...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(header))) // #nosec G115 -- header is far below 4 GiB.
+	return append(prefix, header...)
+}
+
+// chunkNonce is the chunk index, big-endian in the first 11 bytes, and the
+// final flag in the last.
+func chunkNonce(index uint64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], index)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// readChunk fills buf as far as src allows and returns the byte count; a
+// short count means src is exhausted.
+func readChunk(src io.Reader, buf []byte) (int, error) {
+	n, err := io.ReadFull(src, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, nil
+	}
+	return n, err
+}
@@ -0,0 +1,93 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newIdentity(t *testing.T) *ecdh.PrivateKey {
+	t.Helper()
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func seal(t *testing.T, plaintext []byte, recipients ...*ecdh.PublicKey) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Encrypt(&buf, bytes.NewReader(plaintext), recipients))
+	return buf.Bytes()
+}
+
+func TestEncryptDecryptRoundTripAcrossChunkBoundaries(t *testing.T) {
+	alice, bob := newIdentity(t), newIdentity(t)
+	for _, size := range []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 3 * ChunkSize} {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+		sealed := seal(t, plaintext, alice.PublicKey(), bob.PublicKey())
+		for _, identity := range []*ecdh.PrivateKey{alice, bob} {
+			var out bytes.Buffer
+			require.NoError(t, Decrypt(&out, bytes.NewReader(sealed), identity), "size %d", size)
+			require.True(t, bytes.Equal(plaintext, out.Bytes()), "size %d", size)
+		}
+	}
+}
+
+func TestDecryptRefusesOtherKeysAndAlteredEnvelopes(t *testing.T) {
+	alice := newIdentity(t)
+	plaintext := bytes.Repeat([]byte("key/listing/"), ChunkSize/4) // three full chunks
+	sealed := seal(t, plaintext, alice.PublicKey())
+
+	header, err := ReadHeader(bytes.NewReader(sealed))
+	require.NoError(t, err)
+	require.Equal(t, []string{KeyID(alice.PublicKey())}, header.KeyIDs())
+
+	require.ErrorIs(t, Decrypt(&bytes.Buffer{}, bytes.NewReader(sealed), newIdentity(t)), ErrNoIdentity)
+
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-20] ^= 1
+	require.ErrorIs(t, Decrypt(&bytes.Buffer{}, bytes.NewReader(flipped), alice), ErrDecrypt)
+
+	// Dropping the final chunk leaves a stream that ends on a non-final one.
+	prefixLen := len(sealed) - len(plaintext) - 3*16
+	truncated := sealed[:prefixLen+ChunkSize+16]
+	require.ErrorIs(t, Decrypt(&bytes.Buffer{}, bytes.NewReader(truncated), alice), ErrDecrypt)
+
+	require.ErrorIs(t, Decrypt(&bytes.Buffer{}, bytes.NewReader([]byte("plain bytes, not an envelope")), alice), ErrDecrypt)
+}
+
+func TestLoadKeysFromOpenSSLPEM(t *testing.T) {
+	dir := t.TempDir()
+	alice, bob := newIdentity(t), newIdentity(t)
+	der, err := x509.MarshalPKCS8PrivateKey(alice)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	var recipients []byte
+	for _, pub := range []*ecdh.PublicKey{alice.PublicKey(), bob.PublicKey(), alice.PublicKey()} {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		require.NoError(t, err)
+		recipients = append(recipients, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+	}
+	recipientsPath := filepath.Join(dir, "recipients.pem")
+	require.NoError(t, os.WriteFile(recipientsPath, recipients, 0o644))
+
+	loaded, err := LoadIdentity(keyPath)
+	require.NoError(t, err)
+	require.Equal(t, KeyID(alice.PublicKey()), KeyID(loaded.PublicKey()))
+	keys, err := LoadRecipients(recipientsPath)
+	require.NoError(t, err)
+	require.Len(t, keys, 2, "duplicate recipients collapse")
+
+	_, err = LoadRecipients(keyPath)
+	require.Error(t, err)
+}
@@ -61,6 +61,10 @@ func NewTimeoutError(message string) *errors.ErrorEnvelope {
 	return errors.NewErrorEnvelope("TIMEOUT", message)
 }
 
+func NewServiceUnavailableError(message string) *errors.ErrorEnvelope {
+	return errors.NewErrorEnvelope("SERVICE_UNAVAILABLE", message)
+}
+
 // Application-Specific Errors
 func NewDataProcessingError(message string) *errors.ErrorEnvelope {
 	return errors.NewErrorEnvelope("DATA_PROCESSING_ERROR", message)
@@ -53,6 +53,11 @@ type DeltaCoverageSummary struct {
 	AfterScopes      int    `json:"after_scopes"`
 	AttributedRows   int64  `json:"attributed_rows"`
 	UnattributedRows int64  `json:"unattributed_rows"`
+	// Selector is the observation selector both snapshots were filtered by,
+	// absent when unfiltered. Under a selector, added and tombstoned include
+	// objects that moved into or out of the selection, not only objects
+	// created or deleted at the source.
+	Selector *indexsubstrate.ObservationSelector `json:"selector,omitempty"`
 }
 
 type DeltaChange struct {
@@ -113,6 +118,19 @@ func CompareDurableDelta(ctx context.Context, input DurableDeltaInput) (DurableD
 	if err := validateDeltaCoverage("after", input.After.Manifest.Coverage); err != nil {
 		return DurableDeltaReport{}, err
 	}
+	// Rows of snapshots observed under different selectors are not comparable:
+	// an object one selector dropped would read as added or tombstoned.
+	beforeSelector, err := indexsubstrate.CoverageSelector(input.Before.Manifest.Coverage)
+	if err != nil {
+		return DurableDeltaReport{}, fmt.Errorf("before coverage: %w", err)
+	}
+	afterSelector, err := indexsubstrate.CoverageSelector(input.After.Manifest.Coverage)
+	if err != nil {
+		return DurableDeltaReport{}, fmt.Errorf("after coverage: %w", err)
+	}
+	if !indexsubstrate.SameObservationSelector(beforeSelector, afterSelector) {
+		return DurableDeltaReport{}, fmt.Errorf("durable delta requires matching observation selectors (before %s, after %s)", beforeSelector, afterSelector)
+	}
 
 	report := DurableDeltaReport{
 		Type:              "gonimbus.index.durable_delta_result.v1",
@@ -126,6 +144,7 @@ func CompareDurableDelta(ctx context.Context, input DurableDeltaInput) (DurableD
 			Semantics:    "confirmed_complete_ungapped_before_and_after_scope",
 			BeforeScopes: len(input.Before.Manifest.Coverage),
 			AfterScopes:  len(input.After.Manifest.Coverage),
+			Selector:     afterSelector,
 		},
 	}
 
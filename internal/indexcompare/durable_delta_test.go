@@ -202,6 +202,32 @@ func TestCompareDurableDeltaRejectsMismatchedAndUnsupportedSnapshots(t *testing.
 	require.ErrorContains(t, err, "schema version")
 }
 
+func TestCompareDurableDeltaRequiresMatchingSelectors(t *testing.T) {
+	base := time.Date(2026, 7, 8, 12, 0, 0, 0, time.UTC)
+	beforeManifest, beforeDir := setupDurableDeltaRows(t, "run_before", base, nil, nil)
+	afterManifest, afterDir := setupDurableDeltaRows(t, "run_after", base.Add(time.Hour), nil, nil)
+	selector := &indexsubstrate.ObservationSelector{Excludes: []string{"**/_temporary/**"}}
+
+	filtered := afterManifest
+	filtered.Coverage = []indexsubstrate.CoverageAttestation{afterManifest.Coverage[0]}
+	filtered.Coverage[0].Selector = selector
+	_, err := CompareDurableDelta(context.Background(), DurableDeltaInput{
+		Before: DurableSnapshotInput{Manifest: beforeManifest, SegmentDir: beforeDir},
+		After:  DurableSnapshotInput{Manifest: filtered, SegmentDir: afterDir},
+	})
+	require.ErrorContains(t, err, "matching observation selectors")
+
+	filteredBefore := beforeManifest
+	filteredBefore.Coverage = []indexsubstrate.CoverageAttestation{beforeManifest.Coverage[0]}
+	filteredBefore.Coverage[0].Selector = selector.Clone()
+	report, err := CompareDurableDelta(context.Background(), DurableDeltaInput{
+		Before: DurableSnapshotInput{Manifest: filteredBefore, SegmentDir: beforeDir},
+		After:  DurableSnapshotInput{Manifest: filtered, SegmentDir: afterDir},
+	})
+	require.NoError(t, err)
+	require.Equal(t, selector, report.Coverage.Selector)
+}
+
 func TestCompareDurableDeltaRejectsMissingParentWhenRequired(t *testing.T) {
 	base := time.Date(2026, 7, 8, 12, 0, 0, 0, time.UTC)
 	beforeManifest, beforeDir := setupDurableDeltaRows(t, "run_before", base, nil, nil)
@@ -0,0 +1,300 @@
+// Package indexquery holds the JSONL record shapes shared by `gonimbus index
+// query` and the server's remote index query API, so both emit byte-identical
+// records for the same rows.
+package indexquery
+
+import (
+	"strings"
+	"time"
+
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+)
+
+const (
+	// ObjectRecordType is the record type for one matched object row.
+	ObjectRecordType = "gonimbus.index.object.v1"
+	// CanonicalRecordType is the record type for one canonical ETag group.
+	CanonicalRecordType = "gonimbus.index.object.canonical.v1"
+)
+
+// ObjectRecord is the JSONL record for one matched index row.
+type ObjectRecord struct {
+	Type string     `json:"type"`
+	TS   string     `json:"ts"`
+	Data ObjectData `json:"data"`
+}
+
+// ObjectData is the payload of an ObjectRecord.
+type ObjectData struct {
+	BaseURI          string  `json:"base_uri"`
+	RelKey           string  `json:"rel_key"`
+	Key              string  `json:"key"`
+	SizeBytes        int64   `json:"size_bytes"`
+	LastModified     *string `json:"last_modified,omitempty"`
+	ETag             string  `json:"etag,omitempty"`
+	StorageClass     *string `json:"storage_class,omitempty"`
+	ArchiveStatus    *string `json:"archive_status,omitempty"`
+	RestoreState     *string `json:"restore_state,omitempty"`
+	RestoreExpiry    *string `json:"restore_expiry,omitempty"`
+	ContentType      *string `json:"content_type,omitempty"`
+	HeadEnrichedAt   *string `json:"head_enriched_at,omitempty"`
+	FirstSeenRunID   string  `json:"first_seen_run_id,omitempty"`
+	FirstSeenAt      *string `json:"first_seen_at,omitempty"`
+	LastChangedRunID string  `json:"last_changed_run_id,omitempty"`
+	LastChangedAt    *string `json:"last_changed_at,omitempty"`
+	ChangeKind       string  `json:"change_kind,omitempty"`
+	DeletedAt        *string `json:"deleted_at,omitempty"`
+}
+
+// CanonicalRecord is the JSONL record for one non-empty ETag group.
+type CanonicalRecord struct {
+	Type string        `json:"type"`
+	TS   string        `json:"ts"`
+	Data CanonicalData `json:"data"`
+}
+
+// CanonicalData is the payload of a CanonicalRecord.
+type CanonicalData struct {
+	BaseURI         string                   `json:"base_uri"`
+	ETag            string                   `json:"etag"`
+	Canonical       CanonicalObjectData      `json:"canonical"`
+	TieBreakRule    string                   `json:"tie_break_rule"`
+	AlternatesCount int                      `json:"alternates_count"`
+	Alternates      []CanonicalAlternateData `json:"alternates,omitempty"`
+}
+
+// CanonicalObjectData is the selected member of a canonical group.
+type CanonicalObjectData struct {
+	RelKey           string  `json:"rel_key"`
+	Key              string  `json:"key"`
+	SizeBytes        int64   `json:"size_bytes"`
+	LastModified     *string `json:"last_modified,omitempty"`
+	StorageClass     *string `json:"storage_class,omitempty"`
+	ArchiveStatus    *string `json:"archive_status,omitempty"`
+	RestoreState     *string `json:"restore_state,omitempty"`
+	RestoreExpiry    *string `json:"restore_expiry,omitempty"`
+	ContentType      *string `json:"content_type,omitempty"`
+	HeadEnrichedAt   *string `json:"head_enriched_at,omitempty"`
+	FirstSeenRunID   string  `json:"first_seen_run_id,omitempty"`
+	FirstSeenAt      *string `json:"first_seen_at,omitempty"`
+	LastChangedRunID string  `json:"last_changed_run_id,omitempty"`
+	LastChangedAt    *string `json:"last_changed_at,omitempty"`
+	ChangeKind       string  `json:"change_kind,omitempty"`
+	DeletedAt        *string `json:"deleted_at"`
+}
+
+// CanonicalAlternateData is a non-selected member of a canonical group.
+type CanonicalAlternateData struct {
+	RelKey           string  `json:"rel_key"`
+	SizeBytes        int64   `json:"size_bytes"`
+	LastModified     *string `json:"last_modified,omitempty"`
+	StorageClass     *string `json:"storage_class,omitempty"`
+	ArchiveStatus    *string `json:"archive_status,omitempty"`
+	RestoreState     *string `json:"restore_state,omitempty"`
+	RestoreExpiry    *string `json:"restore_expiry,omitempty"`
+	ContentType      *string `json:"content_type,omitempty"`
+	HeadEnrichedAt   *string `json:"head_enriched_at,omitempty"`
+	FirstSeenRunID   string  `json:"first_seen_run_id,omitempty"`
+	FirstSeenAt      *string `json:"first_seen_at,omitempty"`
+	LastChangedRunID string  `json:"last_changed_run_id,omitempty"`
+	LastChangedAt    *string `json:"last_changed_at,omitempty"`
+	ChangeKind       string  `json:"change_kind,omitempty"`
+	DeletedAt        *string `json:"deleted_at"`
+}
+
+// NewObjectRecord builds the record for one matched row.
+func NewObjectRecord(baseURI string, ts string, r indexstore.QueryResult) ObjectRecord {
+	record := ObjectRecord{
+		Type: ObjectRecordType,
+		TS:   ts,
+		Data: ObjectData{
+			BaseURI:       baseURI,
+			RelKey:        r.RelKey,
+			Key:           FullKey(baseURI, r.RelKey),
+			SizeBytes:     r.SizeBytes,
+			ETag:          r.ETag,
+			StorageClass:  r.StorageClass,
+			ArchiveStatus: r.ArchiveStatus,
+			RestoreState:  r.RestoreState,
+			ContentType:   r.ContentType,
+		},
+	}
+
+	if r.LastModified != nil {
+		lastModified := r.LastModified.Format(time.RFC3339)
+		record.Data.LastModified = &lastModified
+	}
+	if r.DeletedAt != nil {
+		deletedAt := r.DeletedAt.Format(time.RFC3339)
+		record.Data.DeletedAt = &deletedAt
+	}
+	if r.RestoreExpiry != nil {
+		restoreExpiry := r.RestoreExpiry.Format(time.RFC3339)
+		record.Data.RestoreExpiry = &restoreExpiry
+	}
+	if r.HeadEnrichedAt != nil {
+		headEnrichedAt := r.HeadEnrichedAt.Format(time.RFC3339)
+		record.Data.HeadEnrichedAt = &headEnrichedAt
+	}
+	populateObjectDeltaData(&record.Data, r)
+	return record
+}
+
+func populateObjectDeltaData(data *ObjectData, r indexstore.QueryResult) {
+	if data == nil || r.ChangeKind == "" {
+		return
+	}
+	data.FirstSeenRunID = r.FirstSeenRunID
+	data.LastChangedRunID = r.LastChangedRunID
+	data.ChangeKind = r.ChangeKind
+	if r.FirstSeenAt != nil {
+		firstSeenAt := r.FirstSeenAt.Format(time.RFC3339)
+		data.FirstSeenAt = &firstSeenAt
+	}
+	if r.LastChangedAt != nil {
+		lastChangedAt := r.LastChangedAt.Format(time.RFC3339)
+		data.LastChangedAt = &lastChangedAt
+	}
+}
+
+// NewCanonicalRecord builds the record for one canonical group. Alternates
+// are listed only when includeAlternates is set; the count is always present.
+func NewCanonicalRecord(baseURI string, ts string, group indexstore.CanonicalObjectGroup, rule indexstore.CanonicalTieBreak, includeAlternates bool) CanonicalRecord {
+	record := CanonicalRecord{
+		Type: CanonicalRecordType,
+		TS:   ts,
+		Data: CanonicalData{
+			BaseURI:         baseURI,
+			ETag:            group.ETag,
+			Canonical:       newCanonicalObjectData(baseURI, group.Canonical),
+			TieBreakRule:    string(rule),
+			AlternatesCount: len(group.Alternates),
+		},
+	}
+	if includeAlternates {
+		record.Data.Alternates = make([]CanonicalAlternateData, 0, len(group.Alternates))
+		for _, alternate := range group.Alternates {
+			record.Data.Alternates = append(record.Data.Alternates, newCanonicalAlternateData(alternate))
+		}
+	}
+	return record
+}
+
+func newCanonicalObjectData(baseURI string, r indexstore.QueryResult) CanonicalObjectData {
+	record := CanonicalObjectData{
+		RelKey:        r.RelKey,
+		Key:           FullKey(baseURI, r.RelKey),
+		SizeBytes:     r.SizeBytes,
+		StorageClass:  r.StorageClass,
+		ArchiveStatus: r.ArchiveStatus,
+		RestoreState:  r.RestoreState,
+		ContentType:   r.ContentType,
+	}
+	if r.LastModified != nil {
+		lastModified := r.LastModified.Format(time.RFC3339)
+		record.LastModified = &lastModified
+	}
+	if r.DeletedAt != nil {
+		deletedAt := r.DeletedAt.Format(time.RFC3339)
+		record.DeletedAt = &deletedAt
+	}
+	if r.RestoreExpiry != nil {
+		restoreExpiry := r.RestoreExpiry.Format(time.RFC3339)
+		record.RestoreExpiry = &restoreExpiry
+	}
+	if r.HeadEnrichedAt != nil {
+		headEnrichedAt := r.HeadEnrichedAt.Format(time.RFC3339)
+		record.HeadEnrichedAt = &headEnrichedAt
+	}
+	populateCanonicalDeltaData(&record, r)
+	return record
+}
+
+func populateCanonicalDeltaData(data *CanonicalObjectData, r indexstore.QueryResult) {
+	if data == nil || r.ChangeKind == "" {
+		return
+	}
+	data.FirstSeenRunID = r.FirstSeenRunID
+	data.LastChangedRunID = r.LastChangedRunID
+	data.ChangeKind = r.ChangeKind
+	if r.FirstSeenAt != nil {
+		firstSeenAt := r.FirstSeenAt.Format(time.RFC3339)
+		data.FirstSeenAt = &firstSeenAt
+	}
+	if r.LastChangedAt != nil {
+		lastChangedAt := r.LastChangedAt.Format(time.RFC3339)
+		data.LastChangedAt = &lastChangedAt
+	}
+}
+
+func newCanonicalAlternateData(r indexstore.QueryResult) CanonicalAlternateData {
+	record := CanonicalAlternateData{
+		RelKey:        r.RelKey,
+		SizeBytes:     r.SizeBytes,
+		StorageClass:  r.StorageClass,
+		ArchiveStatus: r.ArchiveStatus,
+		RestoreState:  r.RestoreState,
+		ContentType:   r.ContentType,
+	}
+	if r.LastModified != nil {
+		lastModified := r.LastModified.Format(time.RFC3339)
+		record.LastModified = &lastModified
+	}
+	if r.DeletedAt != nil {
+		deletedAt := r.DeletedAt.Format(time.RFC3339)
+		record.DeletedAt = &deletedAt
+	}
+	if r.RestoreExpiry != nil {
+		restoreExpiry := r.RestoreExpiry.Format(time.RFC3339)
+		record.RestoreExpiry = &restoreExpiry
+	}
+	if r.HeadEnrichedAt != nil {
+		headEnrichedAt := r.HeadEnrichedAt.Format(time.RFC3339)
+		record.HeadEnrichedAt = &headEnrichedAt
+	}
+	populateCanonicalAlternateDeltaData(&record, r)
+	return record
+}
+
+func populateCanonicalAlternateDeltaData(data *CanonicalAlternateData, r indexstore.QueryResult) {
+	if data == nil || r.ChangeKind == "" {
+		return
+	}
+	data.FirstSeenRunID = r.FirstSeenRunID
+	data.LastChangedRunID = r.LastChangedRunID
+	data.ChangeKind = r.ChangeKind
+	if r.FirstSeenAt != nil {
+		firstSeenAt := r.FirstSeenAt.Format(time.RFC3339)
+		data.FirstSeenAt = &firstSeenAt
+	}
+	if r.LastChangedAt != nil {
+		lastChangedAt := r.LastChangedAt.Format(time.RFC3339)
+		data.LastChangedAt = &lastChangedAt
+	}
+}
+
+// FullKey builds the full object key from base URI and relative key.
+// The key is the path portion after the bucket in the base URI.
+func FullKey(baseURI, relKey string) string {
+	// baseURI is like "s3://bucket/prefix/"
+	// Extract the prefix part (everything after s3://bucket/)
+	// For now, since rel_key is stored relative to base_uri prefix, return
+	// the prefix + rel_key
+	//
+	// Example:
+	//   base_uri: s3://mybucket/data/
+	//   rel_key: 2025/01/file.json
+	//   full_key: data/2025/01/file.json
+
+	// Parse the base URI to extract the prefix
+	if strings.HasPrefix(baseURI, "s3://") {
+		parts := strings.SplitN(strings.TrimPrefix(baseURI, "s3://"), "/", 2)
+		if len(parts) == 2 {
+			prefix := parts[1] // includes trailing slash
+			return prefix + relKey
+		}
+	}
+
+	// Fallback: just return rel_key
+	return relKey
+}
@@ -2,6 +2,7 @@ package indexsubstrate
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -24,6 +25,85 @@ type CoverageAttestation struct {
 	Basis    CoverageBasis `json:"basis"`
 	Complete bool          `json:"complete"`
 	Gaps     []Scope       `json:"gaps,omitempty"`
+	// Selector, when present, records the ingest predicates the observation
+	// applied after LIST. Complete coverage then attests every listed object
+	// the selector matched, not every object under the scope. Absent means the
+	// unfiltered default, so legacy coverage digests are unchanged.
+	Selector *ObservationSelector `json:"selector,omitempty"`
+}
+
+// ObservationSelector is the recorded form of a build's match and filter
+// settings. Filter is the exact canonical form of the metadata filter, so two
+// selectors are the same observation universe only when they compare equal.
+type ObservationSelector struct {
+	Includes      []string `json:"includes,omitempty"`
+	Excludes      []string `json:"excludes,omitempty"`
+	IncludeHidden bool     `json:"include_hidden,omitempty"`
+	Filter        string   `json:"filter,omitempty"`
+}
+
+// Clone returns a deep copy of s, or nil for a nil selector.
+func (s *ObservationSelector) Clone() *ObservationSelector {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Includes = append([]string(nil), s.Includes...)
+	out.Excludes = append([]string(nil), s.Excludes...)
+	return &out
+}
+
+// String renders the selector for diagnostics.
+func (s *ObservationSelector) String() string {
+	if s == nil {
+		return "unfiltered"
+	}
+	var parts []string
+	if len(s.Includes) > 0 {
+		parts = append(parts, "includes="+strings.Join(s.Includes, ","))
+	}
+	if len(s.Excludes) > 0 {
+		parts = append(parts, "excludes="+strings.Join(s.Excludes, ","))
+	}
+	if s.IncludeHidden {
+		parts = append(parts, "include_hidden")
+	}
+	if s.Filter != "" {
+		parts = append(parts, "filter="+s.Filter)
+	}
+	if len(parts) == 0 {
+		return "unfiltered"
+	}
+	return strings.Join(parts, " ")
+}
+
+// SameObservationSelector reports whether a and b select the same objects
+// from a listing. Pattern order is significant only as recorded; the engine
+// records patterns in a canonical order.
+func SameObservationSelector(a, b *ObservationSelector) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return slices.Equal(a.Includes, b.Includes) &&
+		slices.Equal(a.Excludes, b.Excludes) &&
+		a.IncludeHidden == b.IncludeHidden &&
+		a.Filter == b.Filter
+}
+
+// CoverageSelector returns the observation selector shared by every coverage
+// entry. Entries that disagree are refused: a snapshot is one observation and
+// has one selector.
+func CoverageSelector(coverage []CoverageAttestation) (*ObservationSelector, error) {
+	if len(coverage) == 0 {
+		return nil, nil
+	}
+	selector := coverage[0].Selector
+	for _, entry := range coverage[1:] {
+		if !SameObservationSelector(selector, entry.Selector) {
+			return nil, fmt.Errorf("%w: coverage entries record different observation selectors", ErrInvalidCoverage)
+		}
+	}
+	return selector.Clone(), nil
 }
 
 type CurrentObjectRow struct {
@@ -495,3 +495,32 @@ func rowsByRelKey(rows []CurrentObjectRow) map[string]CurrentObjectRow {
 	}
 	return out
 }
+
+func TestCoverageSelectorRequiresOneSelector(t *testing.T) {
+	filtered := &ObservationSelector{Excludes: []string{"**/_temporary/**"}, Filter: "size_min=10;size_max=-1"}
+	entry := func(prefix string, s *ObservationSelector) CoverageAttestation {
+		return CoverageAttestation{Scope: &Scope{Prefix: prefix}, Basis: CoverageBasisConfirmed, Complete: true, Selector: s}
+	}
+
+	got, err := CoverageSelector([]CoverageAttestation{entry("a/", filtered), entry("b/", filtered.Clone())})
+	require.NoError(t, err)
+	require.True(t, SameObservationSelector(filtered, got))
+	require.NoError(t, validatePublicationCoverage([]CoverageAttestation{entry("a/", filtered)}, PublicationModeDefault))
+
+	mixed := []CoverageAttestation{entry("a/", filtered), entry("b/", nil)}
+	_, err = CoverageSelector(mixed)
+	require.ErrorIs(t, err, ErrInvalidCoverage)
+	require.ErrorIs(t, validatePublicationCoverage(mixed, PublicationModeDefault), ErrInvalidCoverage)
+
+	unfiltered, err := CoverageSelector([]CoverageAttestation{entry("a/", nil)})
+	require.NoError(t, err)
+	require.Nil(t, unfiltered)
+	require.Equal(t, "unfiltered", unfiltered.String())
+
+	// An unfiltered entry keeps its legacy digest.
+	legacy, err := CoverageSHA256([]CoverageAttestation{entry("a/", nil)})
+	require.NoError(t, err)
+	withSelector, err := CoverageSHA256([]CoverageAttestation{entry("a/", filtered)})
+	require.NoError(t, err)
+	require.NotEqual(t, legacy, withSelector)
+}
@@ -28,6 +28,9 @@ const (
 	// subset of the run plan this journal attests, rather than the whole-run plan.
 	// See JournalHeader.CrawlPlanMode.
 	CrawlPlanModeLaneLocal = "lane-local"
+	// CrawlPlanModeKeyRange marks a journal that attests one lexicographic key
+	// range of the whole-run plan. See JournalHeader.KeyRange.
+	CrawlPlanModeKeyRange = "key-range"
 
 	IndexSchemaVersion = 8
 
@@ -46,6 +49,13 @@ type Scope struct {
 	Window *Window `json:"window,omitempty"`
 }
 
+// KeyRange bounds the keys a journal observed: keys sorting strictly after
+// StartAfter and strictly before EndBefore. Empty bounds are open.
+type KeyRange struct {
+	StartAfter string `json:"start_after,omitempty"`
+	EndBefore  string `json:"end_before,omitempty"`
+}
+
 type Window struct {
 	From string `json:"from,omitempty"`
 	To   string `json:"to,omitempty"`
@@ -88,9 +98,22 @@ type JournalHeader struct {
 	// reader must not be used for forward recovery. Authority still holds in such
 	// a reader: handed one lane it derives only that lane-local plan, so an
 	// omitted lane cannot widen coverage.
-	CrawlPlanMode      string    `json:"crawl_plan_mode,omitempty"`
-	IndexSchemaVersion int       `json:"index_schema_version"`
-	StartedAt          time.Time `json:"started_at"`
+	//
+	// CrawlPlanModeKeyRange splits a single-prefix plan by key instead: every
+	// journal records the whole-run plan, and KeyRange names the slice of it
+	// this journal listed. Recovery accepts only a set whose ranges tile the
+	// keyspace, so an omitted range journal refuses rather than narrowing.
+	CrawlPlanMode string `json:"crawl_plan_mode,omitempty"`
+	// KeyRange is the key slice a CrawlPlanModeKeyRange journal observed, and
+	// is absent under every other mode.
+	KeyRange *KeyRange `json:"key_range,omitempty"`
+	// Selector records the ingest predicates this journal's observation
+	// applied after LIST; absent means unfiltered. Recovery re-publishes under
+	// the sealed selector rather than one supplied by the caller, and every
+	// journal of a run must record the same one.
+	Selector           *ObservationSelector `json:"selector,omitempty"`
+	IndexSchemaVersion int                  `json:"index_schema_version"`
+	StartedAt          time.Time            `json:"started_at"`
 }
 
 type ObjectRecord struct {
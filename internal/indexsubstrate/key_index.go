@@ -0,0 +1,525 @@
+package indexsubstrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	// KeyIndexType is the document type of the per-segment key index published
+	// beside a snapshot's segments.
+	KeyIndexType       = "gonimbus.index.key_index.v1"
+	KeyIndexFormatJSON = "json"
+	KeyBloomHashFNV    = "fnv1a64-double"
+	// DefaultKeyIndexStride is the row distance between sparse key samples. A
+	// lookup seeks to the nearest sample at or before its key and reads at most
+	// one stride of rows forward.
+	DefaultKeyIndexStride = 1024
+	// DefaultKeyBloomBitsPerKey and DefaultKeyBloomHashes give a false-positive
+	// rate of roughly one percent.
+	DefaultKeyBloomBitsPerKey = 10
+	DefaultKeyBloomHashes     = 7
+	// DefaultMaxKeyIndexBytes bounds reads of a published key index. Writers
+	// omit the artifact rather than publish one past the bound, so very large
+	// snapshots fall back to key-range pruning.
+	DefaultMaxKeyIndexBytes = 256 << 20
+)
+
+// errKeyIndexOverBudget reports a key index that would exceed
+// DefaultMaxKeyIndexBytes. Writers treat it as "publish without one".
+var errKeyIndexOverBudget = errors.New("key index exceeds size budget")
+
+// KeyBloom is a bloom filter over the rel_keys of one segment. Bit i lives in
+// Data[i/8] at bit i%8. Probes use double hashing over the two halves of the
+// key's FNV-1a 64-bit hash.
+type KeyBloom struct {
+	Hash   string `json:"hash"`
+	Bits   int    `json:"bits"`
+	Hashes int    `json:"hashes"`
+	Data   []byte `json:"data"`
+}
+
+// KeySample pins the rel_key found at a row offset within a segment.
+type KeySample struct {
+	Row int64  `json:"row"`
+	Key string `json:"key"`
+}
+
+// SegmentKeyIndex is the lookup entry for one segment: a bloom filter over
+// every row's rel_key, tombstones included, and a sample every Stride rows.
+type SegmentKeyIndex struct {
+	SegmentID string      `json:"segment_id"`
+	Rows      int         `json:"rows"`
+	Bloom     KeyBloom    `json:"bloom"`
+	Samples   []KeySample `json:"samples"`
+}
+
+// KeyIndexDocument is the on-disk key index. Segments follow manifest order.
+type KeyIndexDocument struct {
+	Type       string            `json:"type"`
+	IndexSetID string            `json:"index_set_id"`
+	RunID      string            `json:"run_id"`
+	Stride     int               `json:"stride"`
+	Segments   []SegmentKeyIndex `json:"segments"`
+}
+
+// KeyIndexDescriptor binds the key index into the manifest by path, size, and
+// digest, the same way segments are bound.
+type KeyIndexDescriptor struct {
+	Path      string        `json:"path"`
+	Format    string        `json:"format"`
+	Segments  int           `json:"segments"`
+	Stride    int           `json:"stride"`
+	SizeBytes int64         `json:"size_bytes"`
+	Digest    SegmentDigest `json:"digest"`
+}
+
+func keyHashes(key string) (uint64, uint64) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	return sum & 0xffffffff, (sum >> 32) | 1
+}
+
+func newKeyBloom(keys int) KeyBloom {
+	bits := keys * DefaultKeyBloomBitsPerKey
+	if bits < 64 {
+		bits = 64
+	}
+	bits = (bits + 7) &^ 7
+	return KeyBloom{Hash: KeyBloomHashFNV, Bits: bits, Hashes: DefaultKeyBloomHashes, Data: make([]byte, bits/8)}
+}
+
+func (b *KeyBloom) add(key string) {
+	h1, h2 := keyHashes(key)
+	for i := uint64(0); i < uint64(b.Hashes); i++ {
+		bit := (h1 + i*h2) % uint64(b.Bits)
+		b.Data[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// MayContain reports whether key may be in the filter. False is definitive.
+func (b KeyBloom) MayContain(key string) bool {
+	if b.Bits <= 0 {
+		return true
+	}
+	h1, h2 := keyHashes(key)
+	for i := uint64(0); i < uint64(b.Hashes); i++ {
+		bit := (h1 + i*h2) % uint64(b.Bits)
+		if b.Data[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b KeyBloom) validate() error {
+	if b.Hash != KeyBloomHashFNV {
+		return fmt.Errorf("unsupported key bloom hash %q", b.Hash)
+	}
+	if b.Bits <= 0 || b.Bits%8 != 0 || len(b.Data) != b.Bits/8 {
+		return fmt.Errorf("key bloom size is inconsistent")
+	}
+	if b.Hashes <= 0 || b.Hashes > 32 {
+		return fmt.Errorf("key bloom hash count %d is out of range", b.Hashes)
+	}
+	return nil
+}
+
+// MayContain reports whether the segment may hold a row for key.
+func (s SegmentKeyIndex) MayContain(key string) bool {
+	return s.Bloom.MayContain(key)
+}
+
+// seekRow returns the row of the last sample at or before key, which is where
+// a forward read for key starts.
+func (s SegmentKeyIndex) seekRow(key string) int64 {
+	i := sort.Search(len(s.Samples), func(i int) bool { return s.Samples[i].Key > key })
+	if i == 0 {
+		return 0
+	}
+	return s.Samples[i-1].Row
+}
+
+func (s SegmentKeyIndex) validate(stride int) error {
+	if err := s.Bloom.validate(); err != nil {
+		return fmt.Errorf("segment %s: %w", s.SegmentID, err)
+	}
+	for i, sample := range s.Samples {
+		if sample.Row != int64(i)*int64(stride) || sample.Row >= int64(s.Rows) {
+			return fmt.Errorf("segment %s: key sample %d is out of place", s.SegmentID, i)
+		}
+		if i > 0 && sample.Key <= s.Samples[i-1].Key {
+			return fmt.Errorf("segment %s: key samples are not ordered", s.SegmentID)
+		}
+	}
+	return nil
+}
+
+// Segment returns the entry for segmentID, or nil when the index has none.
+func (d KeyIndexDocument) Segment(segmentID string) *SegmentKeyIndex {
+	for i := range d.Segments {
+		if d.Segments[i].SegmentID == segmentID {
+			return &d.Segments[i]
+		}
+	}
+	return nil
+}
+
+// keyIndexBuilder accumulates one entry per sealed segment. Once the encoded
+// size estimate passes the read bound it drops what it holds and stops, so the
+// writer's memory stays bounded too.
+type keyIndexBuilder struct {
+	stride     int
+	segments   []SegmentKeyIndex
+	estimate   int64
+	overBudget bool
+}
+
+func newKeyIndexBuilder(stride int) *keyIndexBuilder {
+	if stride <= 0 {
+		stride = DefaultKeyIndexStride
+	}
+	return &keyIndexBuilder{stride: stride}
+}
+
+// add indexes a sealed segment. keyAt returns the rel_key of row i, in the
+// ascending order the segment was written.
+func (b *keyIndexBuilder) add(segmentID string, rows int, keyAt func(int) string) {
+	if b.overBudget {
+		return
+	}
+	entry := SegmentKeyIndex{SegmentID: segmentID, Rows: rows, Bloom: newKeyBloom(rows)}
+	// Base64 bloom bytes plus per-sample JSON overhead.
+	b.estimate += int64(len(entry.Bloom.Data))*4/3 + 128
+	for i := 0; i < rows; i++ {
+		key := keyAt(i)
+		entry.Bloom.add(key)
+		if i%b.stride == 0 {
+			entry.Samples = append(entry.Samples, KeySample{Row: int64(i), Key: key})
+			b.estimate += int64(len(key)) + 32
+		}
+	}
+	if b.estimate > DefaultMaxKeyIndexBytes {
+		b.overBudget = true
+		b.segments = nil
+		return
+	}
+	b.segments = append(b.segments, entry)
+}
+
+// document returns the key index, or ok=false when it outgrew the budget.
+func (b *keyIndexBuilder) document(indexSetID, runID string) (KeyIndexDocument, bool) {
+	if b.overBudget {
+		return KeyIndexDocument{}, false
+	}
+	segments := b.segments
+	if segments == nil {
+		segments = []SegmentKeyIndex{}
+	}
+	return KeyIndexDocument{
+		Type:       KeyIndexType,
+		IndexSetID: indexSetID,
+		RunID:      runID,
+		Stride:     b.stride,
+		Segments:   segments,
+	}, true
+}
+
+// writeKeyIndexFile seals the key index into config.Dir under a
+// content-addressed name. created follows the segment seal contract: true only
+// when this call linked a new final, so callers can roll it back. An index
+// past DefaultMaxKeyIndexBytes is refused with errKeyIndexOverBudget before
+// anything is written.
+func writeKeyIndexFile(config SegmentWriterConfig, doc KeyIndexDocument) (KeyIndexDescriptor, bool, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return KeyIndexDescriptor{}, false, fmt.Errorf("encode key index: %w", err)
+	}
+	if len(data) > DefaultMaxKeyIndexBytes {
+		return KeyIndexDescriptor{}, false, errKeyIndexOverBudget
+	}
+	digestHex := sha256HexBytes(data)
+	descriptor := KeyIndexDescriptor{
+		Path:      "key_index_" + digestHex[:16] + ".json",
+		Format:    KeyIndexFormatJSON,
+		Segments:  len(doc.Segments),
+		Stride:    doc.Stride,
+		SizeBytes: int64(len(data)),
+		Digest:    SegmentDigest{Algorithm: "sha256", Hex: digestHex},
+	}
+	ops := resolveSegmentFileOps(config)
+	temp, err := os.CreateTemp(config.Dir, ".key-index-*.json.tmp")
+	if err != nil {
+		return KeyIndexDescriptor{}, false, fmt.Errorf("create temporary key index: %w", err)
+	}
+	tempPath := temp.Name()
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		return KeyIndexDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("write key index: %w", err))
+	}
+	if err := temp.Close(); err != nil {
+		return KeyIndexDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("close key index: %w", err))
+	}
+	finalPath := filepath.Join(config.Dir, descriptor.Path)
+	if err := os.Link(tempPath, finalPath); err != nil {
+		if config.AllowExistingIdentical && errors.Is(err, os.ErrExist) {
+			existing, digestErr := sha256HexFile(finalPath)
+			if digestErr != nil {
+				return KeyIndexDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("hash existing key index: %w", digestErr))
+			}
+			if existing == digestHex {
+				return descriptor, false, retireTemp(ops, tempPath, nil)
+			}
+		}
+		return KeyIndexDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("create immutable key index: %w", err))
+	}
+	return descriptor, true, retireTemp(ops, tempPath, nil)
+}
+
+// sealKeyIndex writes the builder's index and returns its descriptor, or nil
+// when the index outgrew the size budget and the snapshot ships without one.
+func sealKeyIndex(config SegmentWriterConfig, keys *keyIndexBuilder) (*KeyIndexDescriptor, bool, error) {
+	doc, ok := keys.document(config.IndexSetID, config.RunID)
+	if !ok {
+		return nil, false, nil
+	}
+	descriptor, created, err := writeKeyIndexFile(config, doc)
+	if errors.Is(err, errKeyIndexOverBudget) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, created, err
+	}
+	return &descriptor, created, nil
+}
+
+// ReadKeyIndexVerified loads the key index a manifest binds, refusing it
+// unless the bytes match the recorded size and digest.
+func ReadKeyIndexVerified(dir string, descriptor KeyIndexDescriptor) (KeyIndexDocument, error) {
+	path, err := safeSegmentPath(dir, descriptor.Path)
+	if err != nil {
+		return KeyIndexDocument{}, err
+	}
+	if descriptor.Digest.Algorithm != "sha256" || strings.TrimSpace(descriptor.Digest.Hex) == "" {
+		return KeyIndexDocument{}, fmt.Errorf("key index digest is required")
+	}
+	maxBytes := int64(DefaultMaxKeyIndexBytes)
+	if descriptor.SizeBytes > maxBytes {
+		return KeyIndexDocument{}, fmt.Errorf("key index size %d exceeds limit %d", descriptor.SizeBytes, maxBytes)
+	}
+	data, err := readFileBounded(path, maxBytes)
+	if err != nil {
+		return KeyIndexDocument{}, fmt.Errorf("read key index: %w", err)
+	}
+	if int64(len(data)) != descriptor.SizeBytes || sha256HexBytes(data) != descriptor.Digest.Hex {
+		return KeyIndexDocument{}, fmt.Errorf("key index digest mismatch for %s", descriptor.Path)
+	}
+	var doc KeyIndexDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return KeyIndexDocument{}, fmt.Errorf("parse key index: %w", err)
+	}
+	if doc.Type != KeyIndexType {
+		return KeyIndexDocument{}, fmt.Errorf("key index type mismatch")
+	}
+	if doc.Stride <= 0 {
+		return KeyIndexDocument{}, fmt.Errorf("key index stride must be positive")
+	}
+	for _, segment := range doc.Segments {
+		if err := segment.validate(doc.Stride); err != nil {
+			return KeyIndexDocument{}, fmt.Errorf("key index: %w", err)
+		}
+	}
+	return doc, nil
+}
+
+// ReadPublishedKeyIndex loads the key index of a verified snapshot and checks
+// that it covers exactly the manifest's segments. ok is false for snapshots
+// published before the artifact existed.
+func ReadPublishedKeyIndex(snap PublishedSnapshot) (doc KeyIndexDocument, ok bool, err error) {
+	if snap.Manifest.KeyIndex == nil {
+		return KeyIndexDocument{}, false, nil
+	}
+	doc, err = ReadKeyIndexVerified(snap.SegmentDir, *snap.Manifest.KeyIndex)
+	if err != nil {
+		return KeyIndexDocument{}, false, err
+	}
+	if doc.IndexSetID != snap.Manifest.IndexSetID || doc.RunID != snap.Manifest.RunID {
+		return KeyIndexDocument{}, false, fmt.Errorf("key index identity mismatch")
+	}
+	if len(doc.Segments) != len(snap.Manifest.Segments) {
+		return KeyIndexDocument{}, false, fmt.Errorf("key index covers %d segments, manifest has %d", len(doc.Segments), len(snap.Manifest.Segments))
+	}
+	for i, segment := range snap.Manifest.Segments {
+		if doc.Segments[i].SegmentID != segment.SegmentID || doc.Segments[i].Rows != segment.Rows {
+			return KeyIndexDocument{}, false, fmt.Errorf("key index entry %d does not match segment %s", i, segment.SegmentID)
+		}
+	}
+	return doc, true, nil
+}
+
+// LookupSegmentKeysVerified visits the rows of one segment whose rel_key is in
+// keys, which must be sorted ascending and unique. The segment is verified
+// against its digest under the same-open contract of WalkSegmentFileVerified.
+// With a key index entry, keys the bloom filter excludes are skipped and each
+// remaining key seeks to its nearest sample; without one the segment is read
+// forward once. Callers looking up the same segment repeatedly should hold a
+// VerifiedSegment instead, which hashes it once.
+func LookupSegmentKeysVerified(dir string, descriptor SegmentDescriptor, index *SegmentKeyIndex, keys []string, visit func(CurrentObjectRow) error) error {
+	keys = index.Candidates(keys)
+	if len(keys) == 0 {
+		return nil
+	}
+	segment, err := OpenSegmentVerified(dir, descriptor)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = segment.Close() }()
+	return segment.LookupKeys(index, keys, visit)
+}
+
+// Candidates returns the keys the bloom filter does not exclude. A nil entry
+// excludes nothing.
+func (s *SegmentKeyIndex) Candidates(keys []string) []string {
+	if s == nil {
+		return keys
+	}
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if s.MayContain(key) {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered
+}
+
+// VerifiedSegment is a segment whose digest has been checked, held open so
+// later reads parse the bytes that were hashed without hashing them again.
+// Reads go through ReadAt only, so concurrent lookups may share it.
+type VerifiedSegment struct {
+	file *os.File
+	root *os.Root
+	pq   *parquet.File
+}
+
+// OpenSegmentVerified opens and verifies one segment. The caller closes it.
+func OpenSegmentVerified(dir string, descriptor SegmentDescriptor) (*VerifiedSegment, error) {
+	file, root, err := openSegmentFileVerified(dir, descriptor)
+	if err != nil {
+		return nil, err
+	}
+	segment := &VerifiedSegment{file: file, root: root}
+	info, err := file.Stat()
+	if err != nil {
+		_ = segment.Close()
+		return nil, fmt.Errorf("stat segment: %w", err)
+	}
+	segment.pq, err = parquet.OpenFile(io.NewSectionReader(file, 0, info.Size()), info.Size())
+	if err != nil {
+		_ = segment.Close()
+		return nil, fmt.Errorf("open segment %s: %w", descriptor.Path, err)
+	}
+	return segment, nil
+}
+
+func (s *VerifiedSegment) Close() error {
+	return errors.Join(s.file.Close(), s.root.Close())
+}
+
+// LookupKeys visits the rows whose rel_key is in keys, which must be sorted
+// ascending and unique, as LookupSegmentKeysVerified does. Keys are expected
+// to be bloom-filtered already (see Candidates); index only places seeks.
+func (s *VerifiedSegment) LookupKeys(index *SegmentKeyIndex, keys []string, visit func(CurrentObjectRow) error) error {
+	reader := parquet.NewGenericReader[segmentParquetRow](s.pq)
+	defer func() { _ = reader.Close() }()
+	cursor := &segmentCursor{reader: reader, buf: make([]segmentParquetRow, 64)}
+	for _, key := range keys {
+		if index != nil {
+			if err := cursor.seek(index.seekRow(key)); err != nil {
+				return err
+			}
+		}
+		for {
+			row, err := cursor.peek()
+			if err != nil {
+				return err
+			}
+			if row == nil || row.RelKey > key {
+				break
+			}
+			if row.RelKey == key {
+				current, err := currentRowFromSegmentParquet(*row)
+				if err != nil {
+					return err
+				}
+				if err := visit(current); err != nil {
+					return err
+				}
+				cursor.advance()
+				break
+			}
+			cursor.advance()
+		}
+	}
+	return nil
+}
+
+// segmentCursor reads a segment forward in small batches and can skip ahead
+// to a row offset. pos is the row offset of buf[i], or of the next row to be
+// read when the buffer is drained.
+type segmentCursor struct {
+	reader *parquet.GenericReader[segmentParquetRow]
+	buf    []segmentParquetRow
+	n, i   int
+	pos    int64
+	eof    bool
+}
+
+func (c *segmentCursor) seek(row int64) error {
+	if row <= c.pos {
+		return nil
+	}
+	if buffered := int64(c.n - c.i); row < c.pos+buffered {
+		c.i += int(row - c.pos)
+		c.pos = row
+		return nil
+	}
+	if err := c.reader.SeekToRow(row); err != nil {
+		return fmt.Errorf("seek segment: %w", err)
+	}
+	c.n, c.i, c.pos, c.eof = 0, 0, row, false
+	return nil
+}
+
+func (c *segmentCursor) peek() (*segmentParquetRow, error) {
+	if c.i >= c.n {
+		if c.eof {
+			return nil, nil
+		}
+		n, err := c.reader.Read(c.buf)
+		c.n, c.i = n, 0
+		if err == io.EOF || (err == nil && n == 0) {
+			c.eof = true
+		} else if err != nil {
+			return nil, fmt.Errorf("read segment: %w", err)
+		}
+		if n == 0 {
+			return nil, nil
+		}
+	}
+	return &c.buf[c.i], nil
+}
+
+func (c *segmentCursor) advance() {
+	c.i++
+	c.pos++
+}
@@ -0,0 +1,89 @@
+package indexsubstrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyBloomHasNoFalseNegatives(t *testing.T) {
+	bloom := newKeyBloom(1000)
+	for i := 0; i < 1000; i++ {
+		bloom.add(fmt.Sprintf("data/%04d.xml", i))
+	}
+	require.NoError(t, bloom.validate())
+	misses := 0
+	for i := 0; i < 1000; i++ {
+		require.True(t, bloom.MayContain(fmt.Sprintf("data/%04d.xml", i)))
+		if !bloom.MayContain(fmt.Sprintf("other/%04d.xml", i)) {
+			misses++
+		}
+	}
+	require.Greater(t, misses, 950, "false-positive rate stays near one percent")
+}
+
+func TestStreamingSegmentSetPublishesKeyIndexAndLooksUpKeys(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)
+	deleted := base.Add(time.Hour)
+	rows := make([]CurrentObjectRow, 0, 250)
+	for i := 0; i < 250; i++ {
+		row := segmentTestRow("idx_test", fmt.Sprintf("data/%04d.xml", i*2), int64(i), fmt.Sprintf(`"e%d"`, i), base, nil, nil, nil, nil)
+		if i == 7 {
+			row.DeletedAt = &deleted
+		}
+		rows = append(rows, row)
+	}
+	config := SegmentWriterConfig{Dir: dir, IndexSetID: "idx_test", RunID: "run_test", CreatedAt: base, TargetRowsPerSegment: 100, KeyIndexStride: 16}
+	manifest, err := WriteStreamingSegmentSet(context.Background(), config, NewSliceOrderedRows(rows))
+	require.NoError(t, err)
+	require.NotNil(t, manifest.KeyIndex)
+	require.Equal(t, 3, manifest.KeyIndex.Segments)
+	require.Equal(t, 16, manifest.KeyIndex.Stride)
+
+	doc, err := ReadKeyIndexVerified(dir, *manifest.KeyIndex)
+	require.NoError(t, err)
+	require.Len(t, doc.Segments[0].Samples, 7)
+	require.Equal(t, "data/0032.xml", doc.Segments[0].Samples[1].Key)
+
+	materialized, err := WriteSegmentSet(SegmentWriterConfig{
+		Dir: t.TempDir(), IndexSetID: "idx_test", RunID: "run_test", CreatedAt: base, TargetRowsPerSegment: 100, KeyIndexStride: 16,
+	}, rows)
+	require.NoError(t, err)
+	require.Equal(t, *manifest.KeyIndex, *materialized.KeyIndex)
+
+	// Even keys exist; odd ones fall between rows; the tombstone is a row.
+	keys := []string{"data/0000.xml", "data/0001.xml", "data/0014.xml", "data/0064.xml", "data/0065.xml", "data/0198.xml", "data/0199.xml", "zz"}
+	for _, index := range []*SegmentKeyIndex{doc.Segment(manifest.Segments[0].SegmentID), nil} {
+		var found []string
+		require.NoError(t, LookupSegmentKeysVerified(dir, manifest.Segments[0], index, keys, func(row CurrentObjectRow) error {
+			found = append(found, row.RelKey)
+			return nil
+		}))
+		require.Equal(t, []string{"data/0000.xml", "data/0014.xml", "data/0064.xml", "data/0198.xml"}, found)
+	}
+
+	// A key the bloom filter excludes never opens the segment.
+	segment := manifest.Segments[1]
+	entry := doc.Segment(segment.SegmentID)
+	require.False(t, entry.MayContain("absent/key"))
+	require.NoError(t, os.Chmod(filepath.Join(dir, segment.Path), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, segment.Path), []byte("corrupt"), 0o600))
+	require.NoError(t, LookupSegmentKeysVerified(dir, segment, entry, []string{"absent/key"}, func(CurrentObjectRow) error {
+		t.Fatal("excluded key visited a row")
+		return nil
+	}))
+	err = LookupSegmentKeysVerified(dir, segment, entry, []string{"data/0200.xml"}, func(CurrentObjectRow) error { return nil })
+	require.ErrorContains(t, err, "segment digest mismatch")
+
+	path := filepath.Join(dir, manifest.KeyIndex.Path)
+	require.NoError(t, os.Chmod(path, 0o600))
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"tampered"}`), 0o600))
+	_, err = ReadKeyIndexVerified(dir, *manifest.KeyIndex)
+	require.ErrorContains(t, err, "key index digest mismatch")
+}
@@ -0,0 +1,291 @@
+package indexsubstrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// PrefixStatsType is the document type of the per-prefix aggregate artifact
+	// published beside a snapshot's segments.
+	PrefixStatsType         = "gonimbus.index.prefix_stats.v1"
+	PrefixStatsFormatJSON   = "json"
+	DefaultPrefixStatsDepth = 8
+	// DefaultPrefixStatsMaxPrefixes bounds how many prefixes the artifact breaks
+	// out. Prefixes past the budget are folded into their nearest recorded
+	// ancestor, which is marked truncated.
+	DefaultPrefixStatsMaxPrefixes = 100_000
+	// DefaultMaxPrefixStatsBytes bounds reads of a published prefix artifact.
+	DefaultMaxPrefixStatsBytes = 64 << 20
+
+	PrefixTruncatedMaxDepth    = "max_depth"
+	PrefixTruncatedMaxPrefixes = "max_prefixes"
+)
+
+// PrefixStatsLimits bounds the prefix breakdown a publication computes. Zero
+// fields fall back to the defaults.
+type PrefixStatsLimits struct {
+	MaxDepth    int
+	MaxPrefixes int
+}
+
+// PrefixStat is one prefix in the published breakdown. Counts cover active
+// rows only; tombstones are not objects. Direct counts are objects whose key
+// sits immediately under the prefix; recursive counts include every object
+// below it. CommonPrefixes counts immediate child prefixes whether or not
+// they were broken out themselves.
+type PrefixStat struct {
+	Prefix           string `json:"prefix"`
+	Depth            int    `json:"depth"`
+	ObjectsDirect    int64  `json:"objects_direct"`
+	BytesDirect      int64  `json:"bytes_direct"`
+	ObjectsRecursive int64  `json:"objects_recursive"`
+	BytesRecursive   int64  `json:"bytes_recursive"`
+	CommonPrefixes   int64  `json:"common_prefixes"`
+	Truncated        bool   `json:"truncated"`
+	TruncatedReason  string `json:"truncated_reason,omitempty"`
+}
+
+// PrefixStatsDocument is the on-disk prefix artifact. Prefixes are sorted and
+// relative to the index base, with "" as the root.
+type PrefixStatsDocument struct {
+	Type        string       `json:"type"`
+	IndexSetID  string       `json:"index_set_id"`
+	RunID       string       `json:"run_id"`
+	MaxDepth    int          `json:"max_depth"`
+	MaxPrefixes int          `json:"max_prefixes"`
+	Truncated   bool         `json:"truncated"`
+	Prefixes    []PrefixStat `json:"prefixes"`
+}
+
+// PrefixStatsDescriptor binds the prefix artifact into the manifest by path,
+// size, and digest, the same way segments are bound.
+type PrefixStatsDescriptor struct {
+	Path      string        `json:"path"`
+	Format    string        `json:"format"`
+	Prefixes  int           `json:"prefixes"`
+	MaxDepth  int           `json:"max_depth"`
+	Truncated bool          `json:"truncated"`
+	SizeBytes int64         `json:"size_bytes"`
+	Digest    SegmentDigest `json:"digest"`
+}
+
+func normalizePrefixStatsLimits(limits PrefixStatsLimits) PrefixStatsLimits {
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = DefaultPrefixStatsDepth
+	}
+	if limits.MaxPrefixes <= 0 {
+		limits.MaxPrefixes = DefaultPrefixStatsMaxPrefixes
+	}
+	return limits
+}
+
+type prefixFrame struct {
+	stat      PrefixStat
+	lastChild string
+}
+
+// prefixAccumulator folds rel_key-ordered rows into per-prefix aggregates.
+// Open prefixes form a stack along the current key's path, so memory is the
+// depth bound plus the recorded prefixes, never the row count.
+type prefixAccumulator struct {
+	limits    PrefixStatsLimits
+	stack     []*prefixFrame
+	done      []PrefixStat
+	opened    int
+	truncated bool
+}
+
+func newPrefixAccumulator(limits PrefixStatsLimits) *prefixAccumulator {
+	limits = normalizePrefixStatsLimits(limits)
+	return &prefixAccumulator{
+		limits: limits,
+		stack:  []*prefixFrame{{stat: PrefixStat{}}},
+		opened: 1,
+	}
+}
+
+// observe records one row. Rows must arrive in ascending rel_key order, which
+// the segment writers already enforce.
+func (a *prefixAccumulator) observe(relKey string, sizeBytes int64, deleted bool) {
+	if deleted {
+		return
+	}
+	for len(a.stack) > 1 && !strings.HasPrefix(relKey, a.top().stat.Prefix) {
+		a.pop()
+	}
+	dirs := keyDirectories(relKey)
+	for len(dirs) > a.top().stat.Depth {
+		top := a.top()
+		child := dirs[top.stat.Depth]
+		if child == top.lastChild {
+			// Seen before but not on the stack: it was not broken out.
+			break
+		}
+		top.lastChild = child
+		top.stat.CommonPrefixes++
+		if top.stat.Depth+1 > a.limits.MaxDepth {
+			a.markTruncated(top, PrefixTruncatedMaxDepth)
+			break
+		}
+		if a.opened >= a.limits.MaxPrefixes {
+			a.markTruncated(top, PrefixTruncatedMaxPrefixes)
+			break
+		}
+		a.opened++
+		a.stack = append(a.stack, &prefixFrame{stat: PrefixStat{Prefix: child, Depth: top.stat.Depth + 1}})
+	}
+	for _, frame := range a.stack {
+		frame.stat.ObjectsRecursive++
+		frame.stat.BytesRecursive += sizeBytes
+	}
+	if top := a.top(); top.stat.Depth == len(dirs) {
+		top.stat.ObjectsDirect++
+		top.stat.BytesDirect += sizeBytes
+	}
+}
+
+func (a *prefixAccumulator) top() *prefixFrame {
+	return a.stack[len(a.stack)-1]
+}
+
+func (a *prefixAccumulator) pop() {
+	a.done = append(a.done, a.top().stat)
+	a.stack = a.stack[:len(a.stack)-1]
+}
+
+func (a *prefixAccumulator) markTruncated(frame *prefixFrame, reason string) {
+	a.truncated = true
+	if !frame.stat.Truncated {
+		frame.stat.Truncated = true
+		frame.stat.TruncatedReason = reason
+	}
+}
+
+// document closes every open prefix and returns the sorted artifact.
+func (a *prefixAccumulator) document(indexSetID, runID string) PrefixStatsDocument {
+	for len(a.stack) > 0 {
+		a.pop()
+	}
+	sort.Slice(a.done, func(i, j int) bool { return a.done[i].Prefix < a.done[j].Prefix })
+	return PrefixStatsDocument{
+		Type:        PrefixStatsType,
+		IndexSetID:  indexSetID,
+		RunID:       runID,
+		MaxDepth:    a.limits.MaxDepth,
+		MaxPrefixes: a.limits.MaxPrefixes,
+		Truncated:   a.truncated,
+		Prefixes:    a.done,
+	}
+}
+
+// keyDirectories returns every "/"-delimited prefix above relKey, shortest
+// first. "a/b/c" yields ["a/", "a/b/"].
+func keyDirectories(relKey string) []string {
+	var dirs []string
+	for i := 0; i < len(relKey); i++ {
+		if relKey[i] == '/' {
+			dirs = append(dirs, relKey[:i+1])
+		}
+	}
+	return dirs
+}
+
+// writePrefixStatsFile seals the prefix artifact into config.Dir under a
+// content-addressed name. created follows the segment seal contract: true only
+// when this call linked a new final, so callers can roll it back.
+func writePrefixStatsFile(config SegmentWriterConfig, doc PrefixStatsDocument) (PrefixStatsDescriptor, bool, error) {
+	data, err := marshalIndentedJSON(doc)
+	if err != nil {
+		return PrefixStatsDescriptor{}, false, fmt.Errorf("encode prefix stats: %w", err)
+	}
+	digestHex := sha256HexBytes(data)
+	descriptor := PrefixStatsDescriptor{
+		Path:      "prefix_stats_" + digestHex[:16] + ".json",
+		Format:    PrefixStatsFormatJSON,
+		Prefixes:  len(doc.Prefixes),
+		MaxDepth:  doc.MaxDepth,
+		Truncated: doc.Truncated,
+		SizeBytes: int64(len(data)),
+		Digest:    SegmentDigest{Algorithm: "sha256", Hex: digestHex},
+	}
+	ops := resolveSegmentFileOps(config)
+	temp, err := os.CreateTemp(config.Dir, ".prefix-stats-*.json.tmp")
+	if err != nil {
+		return PrefixStatsDescriptor{}, false, fmt.Errorf("create temporary prefix stats: %w", err)
+	}
+	tempPath := temp.Name()
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		return PrefixStatsDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("write prefix stats: %w", err))
+	}
+	if err := temp.Close(); err != nil {
+		return PrefixStatsDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("close prefix stats: %w", err))
+	}
+	finalPath := filepath.Join(config.Dir, descriptor.Path)
+	if err := os.Link(tempPath, finalPath); err != nil {
+		if config.AllowExistingIdentical && errors.Is(err, os.ErrExist) {
+			existing, digestErr := sha256HexFile(finalPath)
+			if digestErr != nil {
+				return PrefixStatsDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("hash existing prefix stats: %w", digestErr))
+			}
+			if existing == digestHex {
+				return descriptor, false, retireTemp(ops, tempPath, nil)
+			}
+		}
+		return PrefixStatsDescriptor{}, false, retireTemp(ops, tempPath, fmt.Errorf("create immutable prefix stats: %w", err))
+	}
+	return descriptor, true, retireTemp(ops, tempPath, nil)
+}
+
+// ReadPrefixStatsVerified loads the prefix artifact a manifest binds, refusing
+// it unless the bytes match the recorded size and digest.
+func ReadPrefixStatsVerified(dir string, descriptor PrefixStatsDescriptor) (PrefixStatsDocument, error) {
+	path, err := safeSegmentPath(dir, descriptor.Path)
+	if err != nil {
+		return PrefixStatsDocument{}, err
+	}
+	if descriptor.Digest.Algorithm != "sha256" || strings.TrimSpace(descriptor.Digest.Hex) == "" {
+		return PrefixStatsDocument{}, fmt.Errorf("prefix stats digest is required")
+	}
+	maxBytes := int64(DefaultMaxPrefixStatsBytes)
+	if descriptor.SizeBytes > maxBytes {
+		return PrefixStatsDocument{}, fmt.Errorf("prefix stats size %d exceeds limit %d", descriptor.SizeBytes, maxBytes)
+	}
+	data, err := readFileBounded(path, maxBytes)
+	if err != nil {
+		return PrefixStatsDocument{}, fmt.Errorf("read prefix stats: %w", err)
+	}
+	if int64(len(data)) != descriptor.SizeBytes || sha256HexBytes(data) != descriptor.Digest.Hex {
+		return PrefixStatsDocument{}, fmt.Errorf("prefix stats digest mismatch for %s", descriptor.Path)
+	}
+	var doc PrefixStatsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return PrefixStatsDocument{}, fmt.Errorf("parse prefix stats: %w", err)
+	}
+	if doc.Type != PrefixStatsType {
+		return PrefixStatsDocument{}, fmt.Errorf("prefix stats type mismatch")
+	}
+	return doc, nil
+}
+
+// ReadPublishedPrefixStats loads the prefix artifact of a verified snapshot.
+// ok is false for snapshots published before the artifact existed.
+func ReadPublishedPrefixStats(snap PublishedSnapshot) (doc PrefixStatsDocument, ok bool, err error) {
+	if snap.Manifest.PrefixStats == nil {
+		return PrefixStatsDocument{}, false, nil
+	}
+	doc, err = ReadPrefixStatsVerified(snap.SegmentDir, *snap.Manifest.PrefixStats)
+	if err != nil {
+		return PrefixStatsDocument{}, false, err
+	}
+	if doc.IndexSetID != snap.Manifest.IndexSetID || doc.RunID != snap.Manifest.RunID {
+		return PrefixStatsDocument{}, false, fmt.Errorf("prefix stats identity mismatch")
+	}
+	return doc, true, nil
+}
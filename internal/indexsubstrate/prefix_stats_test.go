@@ -0,0 +1,112 @@
+package indexsubstrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixAccumulatorAggregatesDirectAndRecursive(t *testing.T) {
+	acc := newPrefixAccumulator(PrefixStatsLimits{})
+	for _, row := range []struct {
+		key     string
+		size    int64
+		deleted bool
+	}{
+		{"a/1.xml", 1, false},
+		{"a/b/2.xml", 2, false},
+		{"a/b/3.xml", 4, false},
+		{"a/c/gone.xml", 100, true},
+		{"a/d/4.xml", 8, false},
+		{"root.xml", 16, false},
+	} {
+		acc.observe(row.key, row.size, row.deleted)
+	}
+	doc := acc.document("idx_test", "run_test")
+
+	require.False(t, doc.Truncated)
+	require.Equal(t, []PrefixStat{
+		{Prefix: "", Depth: 0, ObjectsDirect: 1, BytesDirect: 16, ObjectsRecursive: 5, BytesRecursive: 31, CommonPrefixes: 1},
+		{Prefix: "a/", Depth: 1, ObjectsDirect: 1, BytesDirect: 1, ObjectsRecursive: 4, BytesRecursive: 15, CommonPrefixes: 2},
+		{Prefix: "a/b/", Depth: 2, ObjectsDirect: 2, BytesDirect: 6, ObjectsRecursive: 2, BytesRecursive: 6},
+		{Prefix: "a/d/", Depth: 2, ObjectsDirect: 1, BytesDirect: 8, ObjectsRecursive: 1, BytesRecursive: 8},
+	}, doc.Prefixes)
+}
+
+func TestPrefixAccumulatorTruncatesAtLimits(t *testing.T) {
+	keys := []string{"a/b/c/1", "a/b/d/2", "a/e/3", "f/4", "g/5"}
+
+	byDepth := newPrefixAccumulator(PrefixStatsLimits{MaxDepth: 2})
+	for _, key := range keys {
+		byDepth.observe(key, 1, false)
+	}
+	doc := byDepth.document("idx_test", "run_test")
+	require.True(t, doc.Truncated)
+	ab := findPrefixStat(t, doc, "a/b/")
+	require.True(t, ab.Truncated)
+	require.Equal(t, PrefixTruncatedMaxDepth, ab.TruncatedReason)
+	require.EqualValues(t, 2, ab.CommonPrefixes, "children are counted even when not broken out")
+	require.EqualValues(t, 2, ab.ObjectsRecursive)
+	require.EqualValues(t, 0, ab.ObjectsDirect)
+
+	byCount := newPrefixAccumulator(PrefixStatsLimits{MaxPrefixes: 3})
+	for _, key := range keys {
+		byCount.observe(key, 1, false)
+	}
+	doc = byCount.document("idx_test", "run_test")
+	require.Len(t, doc.Prefixes, 3, "root plus the first two prefixes in key order")
+	root := findPrefixStat(t, doc, "")
+	require.True(t, root.Truncated)
+	require.Equal(t, PrefixTruncatedMaxPrefixes, root.TruncatedReason)
+	require.EqualValues(t, 5, root.ObjectsRecursive)
+	require.EqualValues(t, 3, root.CommonPrefixes)
+}
+
+func TestStreamingSegmentSetPublishesDigestBoundPrefixStats(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)
+	rows := []CurrentObjectRow{
+		segmentTestRow("idx_test", "data/a.xml", 10, `"a"`, base, nil, nil, nil, nil),
+		segmentTestRow("idx_test", "data/b.xml", 20, `"b"`, base, nil, nil, nil, nil),
+		segmentTestRow("idx_test", "other/c.xml", 30, `"c"`, base, nil, nil, nil, nil),
+	}
+	config := SegmentWriterConfig{Dir: dir, IndexSetID: "idx_test", RunID: "run_test", CreatedAt: base, TargetRowsPerSegment: 2}
+	streamed, err := WriteStreamingSegmentSet(context.Background(), config, NewSliceOrderedRows(rows))
+	require.NoError(t, err)
+	require.NotNil(t, streamed.PrefixStats)
+	require.Equal(t, 3, streamed.PrefixStats.Prefixes)
+
+	doc, err := ReadPrefixStatsVerified(dir, *streamed.PrefixStats)
+	require.NoError(t, err)
+	require.Equal(t, "idx_test", doc.IndexSetID)
+	require.EqualValues(t, 2, findPrefixStat(t, doc, "data/").ObjectsDirect)
+	require.EqualValues(t, 60, findPrefixStat(t, doc, "").BytesRecursive)
+
+	// The materialized writer produces the identical artifact.
+	materialized, err := WriteSegmentSet(SegmentWriterConfig{
+		Dir: t.TempDir(), IndexSetID: "idx_test", RunID: "run_test", CreatedAt: base, TargetRowsPerSegment: 2,
+	}, rows)
+	require.NoError(t, err)
+	require.Equal(t, *streamed.PrefixStats, *materialized.PrefixStats)
+
+	path := filepath.Join(dir, streamed.PrefixStats.Path)
+	require.NoError(t, os.Chmod(path, 0o600))
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"tampered"}`), 0o600))
+	_, err = ReadPrefixStatsVerified(dir, *streamed.PrefixStats)
+	require.ErrorContains(t, err, "prefix stats digest mismatch")
+}
+
+func findPrefixStat(t *testing.T, doc PrefixStatsDocument, prefix string) PrefixStat {
+	t.Helper()
+	for _, ps := range doc.Prefixes {
+		if ps.Prefix == prefix {
+			return ps
+		}
+	}
+	t.Fatalf("prefix %q not recorded", prefix)
+	return PrefixStat{}
+}
@@ -80,6 +80,9 @@ type PublishConfig struct {
 	// SpillBudget bounds the streaming merge's memory, workspace disk, and merge
 	// topology. Zero fields fall back to DefaultSpillMergeBudget.
 	SpillBudget SpillMergeBudget
+	// PrefixStats bounds the per-prefix aggregate artifact published beside the
+	// segments. Zero fields fall back to the defaults.
+	PrefixStats PrefixStatsLimits
 	// Mode selects compaction/publication policy (default crawl vs enrich-only).
 	Mode PublicationMode
 	// ExpectedParent, when non-nil, enforces latest-pointer CAS at advance.
@@ -221,6 +224,7 @@ func PublishSnapshotContext(ctx context.Context, config PublishConfig) (PublishR
 		StateParent:            config.StateParent,
 		Lineage:                config.Lineage,
 		OnSegmentProgress:      config.OnSegmentProgress,
+		PrefixStats:            config.PrefixStats,
 	}, stateSource)
 	if err != nil {
 		return result, err
@@ -721,6 +725,9 @@ func validatePublicationCoverage(coverage []CoverageAttestation, mode Publicatio
 			return fmt.Errorf("%w: coverage scope must be explicit prefix coverage", ErrInvalidCoverage)
 		}
 	}
+	if _, err := CoverageSelector(coverage); err != nil {
+		return err
+	}
 	return nil
 }
 
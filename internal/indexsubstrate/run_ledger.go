@@ -0,0 +1,155 @@
+package indexsubstrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunLedgerType is the document type of a durable run ledger entry.
+const RunLedgerType = "gonimbus.index.run_ledger.v1"
+
+// RunLedgerStatus is the lifecycle state a ledger entry records. The values
+// match the SQLite index_runs statuses so both formats read the same.
+type RunLedgerStatus string
+
+const (
+	RunLedgerRunning         RunLedgerStatus = "running"
+	RunLedgerSuccess         RunLedgerStatus = "success"
+	RunLedgerFailed          RunLedgerStatus = "failed"
+	RunLedgerFailedResumable RunLedgerStatus = "failed-resumable"
+	RunLedgerAborted         RunLedgerStatus = "aborted"
+)
+
+// RunLedgerEntry is the durable lifecycle record of one run. Complete markers
+// only exist for published runs; the ledger also keeps the runs that failed or
+// were aborted, and a crash leaves its entry at running.
+type RunLedgerEntry struct {
+	Type       string          `json:"type"`
+	IndexSetID string          `json:"index_set_id"`
+	RunID      string          `json:"run_id"`
+	Operation  string          `json:"operation"`
+	Status     RunLedgerStatus `json:"status"`
+	StartedAt  time.Time       `json:"started_at"`
+	EndedAt    *time.Time      `json:"ended_at,omitempty"`
+	// SealedJournals counts the journals on disk when the run ended. A
+	// failed-resumable run has them; they are what recovery publishes from.
+	SealedJournals int `json:"sealed_journals,omitempty"`
+	// Reason is a sanitized one-line failure summary.
+	Reason string `json:"reason,omitempty"`
+}
+
+// RunLedgerDir returns the ledger directory of a segment-set root.
+func RunLedgerDir(segmentSetRoot string) string {
+	return filepath.Join(segmentSetRoot, "ledger")
+}
+
+// WriteRunLedgerEntry records entry under the segment-set root, replacing the
+// run's previous entry. Each run owns its own file, so concurrent runs never
+// contend; the replace is a rename so readers see either state whole.
+func WriteRunLedgerEntry(segmentSetRoot string, entry RunLedgerEntry) error {
+	segmentSetRoot = strings.TrimSpace(segmentSetRoot)
+	if segmentSetRoot == "" {
+		return fmt.Errorf("segment set root is required")
+	}
+	if !safeLedgerRunID(entry.RunID) {
+		return fmt.Errorf("run ledger: invalid run_id")
+	}
+	if strings.TrimSpace(entry.IndexSetID) == "" {
+		return fmt.Errorf("run ledger: index_set_id is required")
+	}
+	switch entry.Status {
+	case RunLedgerRunning, RunLedgerSuccess, RunLedgerFailed, RunLedgerFailedResumable, RunLedgerAborted:
+	default:
+		return fmt.Errorf("run ledger: unknown status %q", entry.Status)
+	}
+	entry.Type = RunLedgerType
+	entry.StartedAt = entry.StartedAt.UTC()
+	if entry.EndedAt != nil {
+		ended := entry.EndedAt.UTC()
+		entry.EndedAt = &ended
+	}
+	data, err := marshalIndentedJSON(entry)
+	if err != nil {
+		return fmt.Errorf("encode run ledger entry: %w", err)
+	}
+	dir := RunLedgerDir(segmentSetRoot)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create run ledger directory: %w", err)
+	}
+	temp, err := os.CreateTemp(dir, ".ledger-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("create temporary run ledger entry: %w", err)
+	}
+	tempPath := temp.Name()
+	defer func() { _ = os.Remove(tempPath) }()
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		return fmt.Errorf("write run ledger entry: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("close run ledger entry: %w", err)
+	}
+	if err := os.Rename(tempPath, filepath.Join(dir, entry.RunID+".json")); err != nil {
+		return fmt.Errorf("commit run ledger entry: %w", err)
+	}
+	return nil
+}
+
+// ListRunLedger returns the ledger entries of a segment-set root, newest start
+// first. Entries that are unreadable, oversized, or belong to another set are
+// skipped: the ledger is history, not publication authority.
+func ListRunLedger(segmentSetRoot, indexSetID string, maxEntryBytes int64) ([]RunLedgerEntry, error) {
+	if maxEntryBytes <= 0 {
+		maxEntryBytes = DefaultMaxPublishedMarkerBytes
+	}
+	dir := RunLedgerDir(segmentSetRoot)
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read run ledger: %w", err)
+	}
+	var entries []RunLedgerEntry
+	for _, item := range items {
+		name := item.Name()
+		if !item.Type().IsRegular() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		runID := strings.TrimSuffix(name, ".json")
+		if !safeLedgerRunID(runID) {
+			continue
+		}
+		data, err := readFileBounded(filepath.Join(dir, name), maxEntryBytes)
+		if err != nil {
+			continue
+		}
+		var entry RunLedgerEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			continue
+		}
+		if entry.Type != RunLedgerType || entry.RunID != runID || (indexSetID != "" && entry.IndexSetID != indexSetID) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].StartedAt.Equal(entries[j].StartedAt) {
+			return entries[i].StartedAt.After(entries[j].StartedAt)
+		}
+		return entries[i].RunID > entries[j].RunID
+	})
+	return entries, nil
+}
+
+func safeLedgerRunID(runID string) bool {
+	if runID == "" || runID == "." || runID == ".." {
+		return false
+	}
+	return !strings.ContainsAny(runID, `/\`) && strings.TrimSpace(runID) == runID
+}
@@ -0,0 +1,52 @@
+package indexsubstrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunLedgerRecordsAndListsNewestFirst(t *testing.T) {
+	root := t.TempDir()
+	base := time.Date(2026, 7, 6, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, WriteRunLedgerEntry(root, RunLedgerEntry{
+		IndexSetID: "idx_test", RunID: "run_1", Operation: "build", Status: RunLedgerRunning, StartedAt: base,
+	}))
+	ended := base.Add(time.Minute)
+	require.NoError(t, WriteRunLedgerEntry(root, RunLedgerEntry{
+		IndexSetID: "idx_test", RunID: "run_1", Operation: "build", Status: RunLedgerFailedResumable,
+		StartedAt: base, EndedAt: &ended, SealedJournals: 3, Reason: "publish failed",
+	}))
+	require.NoError(t, WriteRunLedgerEntry(root, RunLedgerEntry{
+		IndexSetID: "idx_test", RunID: "run_2", Operation: "build", Status: RunLedgerAborted, StartedAt: base.Add(time.Hour),
+	}))
+	require.NoError(t, WriteRunLedgerEntry(root, RunLedgerEntry{
+		IndexSetID: "idx_other", RunID: "run_3", Operation: "build", Status: RunLedgerFailed, StartedAt: base,
+	}))
+	// Unreadable residue is skipped, never fatal.
+	require.NoError(t, os.WriteFile(filepath.Join(RunLedgerDir(root), "run_4.json"), []byte("{"), 0o600))
+
+	entries, err := ListRunLedger(root, "idx_test", 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "run_2", entries[0].RunID)
+	require.Equal(t, RunLedgerAborted, entries[0].Status)
+	require.Equal(t, "run_1", entries[1].RunID)
+	require.Equal(t, RunLedgerFailedResumable, entries[1].Status, "a later entry replaces the run's earlier one")
+	require.Equal(t, 3, entries[1].SealedJournals)
+}
+
+func TestRunLedgerRefusesUnsafeEntries(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now().UTC()
+	require.ErrorContains(t, WriteRunLedgerEntry(root, RunLedgerEntry{IndexSetID: "idx_test", RunID: "../escape", Status: RunLedgerFailed, StartedAt: now}), "invalid run_id")
+	require.ErrorContains(t, WriteRunLedgerEntry(root, RunLedgerEntry{IndexSetID: "idx_test", RunID: "run_1", Status: "paused", StartedAt: now}), "unknown status")
+
+	entries, err := ListRunLedger(t.TempDir(), "idx_test", 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
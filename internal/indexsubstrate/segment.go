@@ -61,6 +61,12 @@ type SegmentWriterConfig struct {
 	// OnSegmentProgress is optional. Invoked after each segment file is written;
 	// outside persisted artifact bytes and ignored for failure control.
 	OnSegmentProgress OnSegmentProgressFunc
+	// PrefixStats bounds the per-prefix breakdown sealed beside the segments.
+	// Zero fields select the defaults.
+	PrefixStats PrefixStatsLimits
+	// KeyIndexStride is the row distance between sparse key samples in the
+	// key index. Zero selects DefaultKeyIndexStride.
+	KeyIndexStride int
 	// segmentOps is an optional per-invocation filesystem seam for hermetic
 	// seal/cleanup tests. Nil selects production os operations. Unexported so
 	// it is not part of the public config surface.
@@ -95,6 +101,12 @@ type InternalManifest struct {
 	Coverage        []CoverageAttestation `json:"coverage"`
 	Counts          ManifestCounts        `json:"counts"`
 	Segments        []SegmentDescriptor   `json:"segments"`
+	// PrefixStats binds the per-prefix aggregate artifact written beside the
+	// segments. Absent on manifests published before the artifact existed.
+	PrefixStats *PrefixStatsDescriptor `json:"prefix_stats,omitempty"`
+	// KeyIndex binds the per-segment bloom filters and sparse key samples
+	// that point lookups use. Absent on manifests published before it existed.
+	KeyIndex *KeyIndexDescriptor `json:"key_index,omitempty"`
 }
 
 type ManifestReference struct {
@@ -177,11 +189,16 @@ func WriteSegmentSet(config SegmentWriterConfig, rows []CurrentObjectRow) (Inter
 	}
 	manifest := newSegmentManifestSkeleton(config, runStartedAt)
 	manifest.Counts = manifestCounts(sortedRows)
+	prefixes := newPrefixAccumulator(config.PrefixStats)
+	for _, row := range sortedRows {
+		prefixes.observe(row.RelKey, row.SizeBytes, row.DeletedAt != nil)
+	}
 
 	totalSegments := 0
 	if len(sortedRows) > 0 && config.TargetRowsPerSegment > 0 {
 		totalSegments = (len(sortedRows) + config.TargetRowsPerSegment - 1) / config.TargetRowsPerSegment
 	}
+	keys := newKeyIndexBuilder(config.KeyIndexStride)
 	rowsDone := 0
 	for start := 0; start < len(sortedRows); start += config.TargetRowsPerSegment {
 		end := start + config.TargetRowsPerSegment
@@ -193,6 +210,8 @@ func WriteSegmentSet(config SegmentWriterConfig, rows []CurrentObjectRow) (Inter
 			return InternalManifest{}, err
 		}
 		manifest.Segments = append(manifest.Segments, descriptor)
+		segmentRows := sortedRows[start:end]
+		keys.add(descriptor.SegmentID, len(segmentRows), func(i int) string { return segmentRows[i].RelKey })
 		rowsDone += end - start
 		if config.OnSegmentProgress != nil {
 			// Observational only: never treat progress callback outcome as publish failure.
@@ -204,6 +223,16 @@ func WriteSegmentSet(config SegmentWriterConfig, rows []CurrentObjectRow) (Inter
 			})
 		}
 	}
+	descriptor, _, err := writePrefixStatsFile(config, prefixes.document(config.IndexSetID, config.RunID))
+	if err != nil {
+		return InternalManifest{}, err
+	}
+	manifest.PrefixStats = &descriptor
+	keyDesc, _, err := sealKeyIndex(config, keys)
+	if err != nil {
+		return InternalManifest{}, err
+	}
+	manifest.KeyIndex = keyDesc
 	return manifest, nil
 }
 
@@ -431,40 +460,55 @@ func ReadSegmentFileVerified(dir string, descriptor SegmentDescriptor) ([]Curren
 }
 
 func WalkSegmentFileVerified(dir string, descriptor SegmentDescriptor, visit func(CurrentObjectRow) error) error {
-	path, err := safeSegmentPath(dir, descriptor.Path)
+	file, root, err := openSegmentFileVerified(dir, descriptor)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		_ = file.Close()
+		_ = root.Close()
+	}()
+	return walkSegmentFile(file, visit)
+}
+
+// openSegmentFileVerified opens a segment and checks its digest, returning the
+// descriptor rewound for parsing. The caller closes both handles.
+func openSegmentFileVerified(dir string, descriptor SegmentDescriptor) (*os.File, *os.Root, error) {
+	path, err := safeSegmentPath(dir, descriptor.Path)
+	if err != nil {
+		return nil, nil, err
+	}
 	if descriptor.Digest.Algorithm != "sha256" {
-		return fmt.Errorf("unsupported segment digest algorithm %q", descriptor.Digest.Algorithm)
+		return nil, nil, fmt.Errorf("unsupported segment digest algorithm %q", descriptor.Digest.Algorithm)
 	}
 	if strings.TrimSpace(descriptor.Digest.Hex) == "" {
-		return fmt.Errorf("segment digest is required")
+		return nil, nil, fmt.Errorf("segment digest is required")
 	}
 	// Same-open trust: open once under os.Root, digest that FD, seek, parse the
 	// same FD. Never hash then re-open the pathname (TOCTOU).
 	file, root, err := openSegmentFile(path)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	defer func() {
+	fail := func(err error) (*os.File, *os.Root, error) {
 		_ = file.Close()
 		_ = root.Close()
-	}()
+		return nil, nil, err
+	}
 	got, err := sha256HexFileHandle(file)
 	if err != nil {
-		return err
+		return fail(err)
 	}
 	if got != descriptor.Digest.Hex {
-		return fmt.Errorf("segment digest mismatch for %s", descriptor.Path)
+		return fail(fmt.Errorf("segment digest mismatch for %s", descriptor.Path))
 	}
 	if afterSegmentDigestVerifiedForTest != nil {
 		afterSegmentDigestVerifiedForTest(path)
 	}
 	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("seek segment after digest verify: %w", err)
+		return fail(fmt.Errorf("seek segment after digest verify: %w", err))
 	}
-	return walkSegmentFile(file, visit)
+	return file, root, nil
 }
 
 func sha256HexFileHandle(file *os.File) (string, error) {
@@ -838,6 +882,7 @@ func copyCoverageAttestations(in []CoverageAttestation) []CoverageAttestation {
 		if len(entry.Gaps) > 0 {
 			copied.Gaps = append([]Scope(nil), entry.Gaps...)
 		}
+		copied.Selector = entry.Selector.Clone()
 		out = append(out, copied)
 	}
 	return out
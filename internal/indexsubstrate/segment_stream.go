@@ -162,6 +162,8 @@ func WriteStreamingSegmentSet(ctx context.Context, config SegmentWriterConfig, s
 		open        []segmentParquetRow
 		counts      ManifestCounts
 		etagSeen    = make(map[string]struct{})
+		prefixes    = newPrefixAccumulator(config.PrefixStats)
+		keys        = newKeyIndexBuilder(config.KeyIndexStride)
 		havePrevKey bool
 		prevRelKey  string
 		rowIndex    int
@@ -188,6 +190,7 @@ func WriteStreamingSegmentSet(ctx context.Context, config SegmentWriterConfig, s
 			return streamSegErr(StreamSegmentWrite, "seal", "segment seal failed", rowIndex, sealErr)
 		}
 		manifest.Segments = append(manifest.Segments, desc)
+		keys.add(desc.SegmentID, len(open), func(i int) string { return open[i].RelKey })
 		rowsDone += len(open)
 		if config.OnSegmentProgress != nil {
 			// Observational only. Streaming path always reports Total=0.
@@ -243,6 +246,7 @@ func WriteStreamingSegmentSet(ctx context.Context, config SegmentWriterConfig, s
 
 		pq := segmentParquetFromCurrentRow(prepared)
 		open = append(open, pq)
+		prefixes.observe(pq.RelKey, pq.SizeBytes, pq.DeletedAt != nil)
 		counts.Rows++
 		if pq.DeletedAt != nil {
 			counts.Tombstones++
@@ -266,6 +270,22 @@ func WriteStreamingSegmentSet(ctx context.Context, config SegmentWriterConfig, s
 	if err := sealOpen(); err != nil {
 		return fail(err)
 	}
+	prefixDesc, created, prefixErr := writePrefixStatsFile(config, prefixes.document(config.IndexSetID, config.RunID))
+	if created {
+		ownedPaths = append(ownedPaths, filepath.Join(config.Dir, prefixDesc.Path))
+	}
+	if prefixErr != nil {
+		return fail(streamSegErr(StreamSegmentWrite, "prefix_stats", "prefix stats seal failed", rowIndex, prefixErr))
+	}
+	manifest.PrefixStats = &prefixDesc
+	keyDesc, created, keyErr := sealKeyIndex(config, keys)
+	if created && keyDesc != nil {
+		ownedPaths = append(ownedPaths, filepath.Join(config.Dir, keyDesc.Path))
+	}
+	if keyErr != nil {
+		return fail(streamSegErr(StreamSegmentWrite, "key_index", "key index seal failed", rowIndex, keyErr))
+	}
+	manifest.KeyIndex = keyDesc
 
 	// Success requires source finalization (EOF alone is incomplete for 2.2 sources).
 	closeSrc()
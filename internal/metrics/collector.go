@@ -0,0 +1,334 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DomainNamespace prefixes every domain metric name rendered by a Collector.
+// It matches the telemetry namespace `gonimbus serve` passes to the Prometheus
+// exporter, so a series scraped from the server and one read from a CLI
+// textfile carry the same name.
+const DomainNamespace = "gonimbus"
+
+type metricKind string
+
+const (
+	kindCounter metricKind = "counter"
+	kindGauge   metricKind = "gauge"
+)
+
+type series struct {
+	kind   metricKind
+	name   string
+	labels string
+	value  float64
+}
+
+// Collector is a process-local aggregate of domain metrics for runs that are
+// not scraped directly: CLI crawls, index builds, and reflows write it as a
+// node_exporter textfile or push it to a Prometheus push gateway, and the
+// server merges the textfiles of the jobs it runs.
+//
+// Counters accumulate; gauges keep the last value set. A Collector is safe for
+// concurrent use.
+type Collector struct {
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// NewCollector returns an empty Collector.
+func NewCollector() *Collector {
+	return &Collector{series: make(map[string]*series)}
+}
+
+// Counter adds value to the named counter series.
+func (c *Collector) Counter(name string, value float64, tags map[string]string) {
+	c.add(kindCounter, name, renderLabels(tags), value)
+}
+
+// Gauge sets the named gauge series to value.
+func (c *Collector) Gauge(name string, value float64, tags map[string]string) {
+	c.add(kindGauge, name, renderLabels(tags), value)
+}
+
+func (c *Collector) add(kind metricKind, name, labels string, value float64) {
+	if c == nil {
+		return
+	}
+	key := name + "{" + labels + "}"
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok || s.kind != kind {
+		c.series[key] = &series{kind: kind, name: name, labels: labels, value: value}
+		return
+	}
+	if kind == kindCounter {
+		s.value += value
+		return
+	}
+	s.value = value
+}
+
+// WriteText renders the collector in the Prometheus text exposition format,
+// sorted by name and labels so successive writes diff cleanly.
+func (c *Collector) WriteText(w io.Writer) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	snapshot := make([]series, 0, len(c.series))
+	for _, s := range c.series {
+		snapshot = append(snapshot, *s)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].name != snapshot[j].name {
+			return snapshot[i].name < snapshot[j].name
+		}
+		return snapshot[i].labels < snapshot[j].labels
+	})
+
+	bw := bufio.NewWriter(w)
+	lastName := ""
+	for _, s := range snapshot {
+		full := DomainNamespace + "_" + s.name
+		if s.name != lastName {
+			if _, err := fmt.Fprintf(bw, "# TYPE %s %s\n", full, s.kind); err != nil {
+				return err
+			}
+			lastName = s.name
+		}
+		value := strconv.FormatFloat(s.value, 'g', -1, 64)
+		var err error
+		if s.labels == "" {
+			_, err = fmt.Fprintf(bw, "%s %s\n", full, value)
+		} else {
+			_, err = fmt.Fprintf(bw, "%s{%s} %s\n", full, s.labels, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteTextfile atomically replaces path with the rendered collector. The
+// temp-file-and-rename sequence is what node_exporter's textfile collector
+// requires so a scrape never observes a partial file.
+func (c *Collector) WriteTextfile(path string) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp.*")
+	if err != nil {
+		return fmt.Errorf("create metrics textfile: %w", err)
+	}
+	tmpName := tmp.Name()
+	if err := c.WriteText(tmp); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("write metrics textfile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("close metrics textfile: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		_ = os.Remove(tmpName)
+		return fmt.Errorf("publish metrics textfile: %w", err)
+	}
+	return nil
+}
+
+// Push replaces this process's group on a Prometheus push gateway. gatewayURL
+// is the gateway base URL; job names the grouping key.
+func (c *Collector) Push(ctx context.Context, client *http.Client, gatewayURL, job string) error {
+	base, err := url.Parse(strings.TrimRight(strings.TrimSpace(gatewayURL), "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return fmt.Errorf("invalid push gateway URL")
+	}
+	var body bytes.Buffer
+	if err := c.WriteText(&body); err != nil {
+		return err
+	}
+	target := base.JoinPath("metrics", "job", job)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push metrics: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("push metrics: gateway returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Merge folds a textfile previously written by WriteText into c. Counters are
+// summed and gauges take the merged value, so merging job textfiles oldest
+// first leaves each gauge at its most recent job's value. Lines outside the
+// domain namespace are ignored.
+func (c *Collector) Merge(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	kinds := map[string]metricKind{}
+	prefix := DomainNamespace + "_"
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# TYPE ") {
+			fields := strings.Fields(line)
+			if len(fields) == 4 && strings.HasPrefix(fields[2], prefix) {
+				kinds[strings.TrimPrefix(fields[2], prefix)] = metricKind(fields[3])
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		sep := strings.LastIndexByte(line, ' ')
+		if sep < 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(line[sep+1:], 64)
+		if err != nil {
+			continue
+		}
+		head := line[:sep]
+		name, labels := head, ""
+		if open := strings.IndexByte(head, '{'); open >= 0 && strings.HasSuffix(head, "}") {
+			name, labels = head[:open], head[open+1:len(head)-1]
+		}
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		name = strings.TrimPrefix(name, prefix)
+		kind, ok := kinds[name]
+		if !ok || (kind != kindCounter && kind != kindGauge) {
+			continue
+		}
+		c.add(kind, name, labels, value)
+	}
+	return scanner.Err()
+}
+
+// MergeCollector folds other into c the way Merge folds a textfile: counters
+// are summed and gauges take other's value.
+func (c *Collector) MergeCollector(other *Collector) {
+	c.absorb(other, false)
+}
+
+// MergeCounters folds only other's counters into c.
+func (c *Collector) MergeCounters(other *Collector) {
+	c.absorb(other, true)
+}
+
+func (c *Collector) absorb(other *Collector, countersOnly bool) {
+	if c == nil || other == nil {
+		return
+	}
+	other.mu.Lock()
+	snapshot := make([]series, 0, len(other.series))
+	for _, s := range other.series {
+		if countersOnly && s.kind != kindCounter {
+			continue
+		}
+		snapshot = append(snapshot, *s)
+	}
+	other.mu.Unlock()
+	for _, s := range snapshot {
+		c.add(s.kind, s.name, s.labels, s.value)
+	}
+}
+
+// labelValueEscaper applies exposition-format escaping to a label value: only
+// backslash, double quote, and newline are escaped; everything else, UTF-8
+// included, is written as is.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func renderLabels(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+`="`+labelValueEscaper.Replace(tags[k])+`"`)
+	}
+	return strings.Join(parts, ",")
+}
+
+var activeCollector atomic.Pointer[Collector]
+
+// SetCollector installs c as the process-local sink for domain metrics, in
+// addition to the telemetry system. Passing nil removes it.
+func SetCollector(c *Collector) {
+	activeCollector.Store(c)
+}
+
+// ActiveCollector returns the collector installed by SetCollector, or nil.
+func ActiveCollector() *Collector {
+	return activeCollector.Load()
+}
+
+// StartTextfileExporter rewrites path from c every interval until ctx is done
+// or the returned stop function is called. Stop performs a final write and
+// returns its error, so the textfile always ends at the run's terminal values.
+func StartTextfileExporter(ctx context.Context, c *Collector, path string, interval time.Duration) (stop func() error) {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = c.WriteTextfile(path)
+			}
+		}
+	}()
+	var once sync.Once
+	var stopErr error
+	return func() error {
+		once.Do(func() {
+			cancel()
+			<-done
+			stopErr = c.WriteTextfile(path)
+		})
+		return stopErr
+	}
+}
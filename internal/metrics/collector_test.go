@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/crawler"
+	"github.com/3leaps/gonimbus/pkg/reflow"
+	"github.com/3leaps/gonimbus/pkg/runbudget"
+)
+
+func TestCollectorAccumulatesCountersAndReplacesGauges(t *testing.T) {
+	c := NewCollector()
+	tags := map[string]string{"provider": "s3", "job_type": "crawl"}
+	c.Counter("crawl_objects_listed_total", 3, tags)
+	c.Counter("crawl_objects_listed_total", 4, tags)
+	c.Gauge("crawl_last_duration_seconds", 9, tags)
+	c.Gauge("crawl_last_duration_seconds", 2.5, tags)
+
+	var out bytes.Buffer
+	require.NoError(t, c.WriteText(&out))
+	text := out.String()
+	require.Contains(t, text, "# TYPE gonimbus_crawl_objects_listed_total counter\n")
+	require.Contains(t, text, `gonimbus_crawl_objects_listed_total{job_type="crawl",provider="s3"} 7`)
+	require.Contains(t, text, "# TYPE gonimbus_crawl_last_duration_seconds gauge\n")
+	require.Contains(t, text, `gonimbus_crawl_last_duration_seconds{job_type="crawl",provider="s3"} 2.5`)
+}
+
+func TestCollectorEscapesLabelValuesForExposition(t *testing.T) {
+	c := NewCollector()
+	c.Counter("crawl_objects_listed_total", 1, map[string]string{"prefix": "café\ta\\b\"c\nd"})
+
+	var out bytes.Buffer
+	require.NoError(t, c.WriteText(&out))
+	// Only backslash, quote, and newline are escaped; UTF-8 and tabs pass through.
+	require.Contains(t, out.String(), "gonimbus_crawl_objects_listed_total{prefix=\"café\ta\\\\b\\\"c\\nd\"} 1\n")
+}
+
+func TestCollectorMergeRoundTrip(t *testing.T) {
+	first := NewCollector()
+	first.Counter("reflow_objects_total", 5, map[string]string{"status": "complete"})
+	first.Gauge("reflow_parallelism", 8, map[string]string{"level": "final"})
+	second := NewCollector()
+	second.Counter("reflow_objects_total", 2, map[string]string{"status": "complete"})
+	second.Gauge("reflow_parallelism", 4, map[string]string{"level": "final"})
+
+	merged := NewCollector()
+	for _, c := range []*Collector{first, second} {
+		var buf bytes.Buffer
+		require.NoError(t, c.WriteText(&buf))
+		require.NoError(t, merged.Merge(&buf))
+	}
+	require.NoError(t, merged.Merge(strings.NewReader("# TYPE other_metric counter\nother_metric 1\n")))
+
+	var out bytes.Buffer
+	require.NoError(t, merged.WriteText(&out))
+	text := out.String()
+	require.Contains(t, text, `gonimbus_reflow_objects_total{status="complete"} 7`)
+	require.Contains(t, text, `gonimbus_reflow_parallelism{level="final"} 4`)
+	require.NotContains(t, text, "other_metric")
+}
+
+func TestCollectorMergeCountersSkipsGauges(t *testing.T) {
+	job := NewCollector()
+	job.Counter("reflow_objects_total", 5, map[string]string{"status": "complete"})
+	job.Gauge("reflow_parallelism", 8, map[string]string{"level": "final"})
+
+	merged := NewCollector()
+	merged.Counter("reflow_objects_total", 2, map[string]string{"status": "complete"})
+	merged.MergeCounters(job)
+	var out bytes.Buffer
+	require.NoError(t, merged.WriteText(&out))
+	require.Contains(t, out.String(), `gonimbus_reflow_objects_total{status="complete"} 7`)
+	require.NotContains(t, out.String(), "reflow_parallelism")
+
+	merged.MergeCollector(job)
+	out.Reset()
+	require.NoError(t, merged.WriteText(&out))
+	require.Contains(t, out.String(), `gonimbus_reflow_objects_total{status="complete"} 12`)
+	require.Contains(t, out.String(), `gonimbus_reflow_parallelism{level="final"} 8`)
+}
+
+func TestCollectorWriteTextfileIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "metrics.prom")
+	c := NewCollector()
+	c.Counter("crawl_runs_total", 1, nil)
+	require.NoError(t, c.WriteTextfile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "gonimbus_crawl_runs_total 1")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temp files must not be left behind")
+}
+
+func TestCollectorPushReplacesGatewayGroup(t *testing.T) {
+	var method, path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewCollector()
+	c.Counter("crawl_runs_total", 1, nil)
+	require.NoError(t, c.Push(context.Background(), srv.Client(), srv.URL+"/", "gonimbus_crawl"))
+	require.Equal(t, http.MethodPut, method)
+	require.Equal(t, "/metrics/job/gonimbus_crawl", path)
+	require.Contains(t, body, "gonimbus_crawl_runs_total 1")
+
+	require.Error(t, c.Push(context.Background(), srv.Client(), "not a url", "job"))
+}
+
+func TestDomainRecordersUseBoundedLabels(t *testing.T) {
+	c := NewCollector()
+	SetCollector(c)
+	t.Cleanup(func() { SetCollector(nil) })
+
+	labels := JobLabels{Provider: "s3", Bucket: "customer-secret-bucket", JobType: JobTypeReflow}
+	RecordCrawlSummary(JobLabels{Provider: "gcs", Bucket: "b", JobType: JobTypeCrawl}, &crawler.Summary{ObjectsListed: 10, ObjectsMatched: 4, BytesTotal: 100, Duration: time.Second}, true)
+	RecordReflowObject(labels, "complete", 64)
+	RecordReflowError(labels, "free text with spaces and s3://bucket/key")
+	RecordReflowConcurrency(labels, reflow.ConcurrencyStats{ConcurrencyFinal: 6, ConcurrencyThrottleBackoffs: 2}, true)
+	RecordBudgetUsage(labels, runbudget.Usage{
+		Domains: []runbudget.DomainUsage{
+			{Domain: runbudget.Domain{Version: 1, ID: "account-a"}, InFlight: map[runbudget.OpClass]int{runbudget.OpList: 2}, RateFactor: 0.5},
+			{Domain: runbudget.Domain{Version: 1, ID: "account-b"}, InFlight: map[runbudget.OpClass]int{runbudget.OpList: 1}, RateFactor: 1},
+		},
+		Waiters: 3,
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, c.WriteText(&out))
+	text := out.String()
+	require.NotContains(t, text, "customer-secret-bucket")
+	require.NotContains(t, text, "account-a")
+	require.NotContains(t, text, "s3://")
+	hash := BucketHash("customer-secret-bucket")
+	require.Len(t, hash, 12)
+	require.Contains(t, text, `gonimbus_reflow_errors_total{bucket_hash="`+hash+`",code="other",job_type="reflow",provider="s3"} 1`)
+	require.Contains(t, text, `gonimbus_reflow_bytes_copied_total{bucket_hash="`+hash+`",job_type="reflow",provider="s3"} 64`)
+	require.Contains(t, text, `gonimbus_reflow_throttle_backoffs_total{bucket_hash="`+hash+`",job_type="reflow",provider="s3"} 2`)
+	require.Contains(t, text, `gonimbus_budget_in_flight{bucket_hash="`+hash+`",job_type="reflow",op_class="list",provider="s3"} 3`)
+	require.Contains(t, text, `gonimbus_budget_rate_factor{bucket_hash="`+hash+`",job_type="reflow",provider="s3"} 0.5`)
+	require.Contains(t, text, `gonimbus_crawl_objects_listed_total{bucket_hash="`+BucketHash("b")+`",job_type="crawl",provider="gcs"} 10`)
+}
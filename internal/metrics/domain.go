@@ -0,0 +1,391 @@
+package metrics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/3leaps/gonimbus/internal/observability"
+	"github.com/3leaps/gonimbus/pkg/crawler"
+	"github.com/3leaps/gonimbus/pkg/indexbuild"
+	"github.com/3leaps/gonimbus/pkg/reflow"
+	"github.com/3leaps/gonimbus/pkg/runbudget"
+)
+
+// Domain metrics describing crawls, index builds, enrichment, and reflow.
+//
+// Every series carries only bounded labels (provider, bucket_hash, job_type,
+// plus a small fixed vocabulary such as status or op_class). Keys, prefixes,
+// URIs, and raw bucket names are never labels.
+var (
+	// Crawl metrics
+	CrawlObjectsListedTotal  = "crawl_objects_listed_total"
+	CrawlObjectsMatchedTotal = "crawl_objects_matched_total"
+	CrawlBytesMatchedTotal   = "crawl_bytes_matched_total"
+	CrawlErrorsTotal         = "crawl_errors_total"
+	CrawlRunsTotal           = "crawl_runs_total"
+	CrawlDurationSeconds     = "crawl_last_duration_seconds"
+
+	// Index build metrics
+	IndexBuildEventsTotal          = "index_build_events_total"
+	IndexBuildObjectsObservedTotal = "index_build_objects_observed_total"
+	IndexBuildRowsPublished        = "index_build_rows_published"
+	IndexBuildSegmentsPublished    = "index_build_segments_published"
+	IndexBuildSpillPeakBytes       = "index_build_spill_peak_bytes"
+
+	// Enrichment metrics
+	IndexEnrichCandidatesTotal = "index_enrich_candidates_total"
+	IndexEnrichHeadCallsTotal  = "index_enrich_head_calls_total"
+	IndexEnrichEnrichedTotal   = "index_enrich_enriched_total"
+	IndexEnrichFailedTotal     = "index_enrich_failed_total"
+
+	// Reflow metrics
+	ReflowObjectsTotal          = "reflow_objects_total"
+	ReflowBytesCopiedTotal      = "reflow_bytes_copied_total"
+	ReflowErrorsTotal           = "reflow_errors_total"
+	ReflowThrottleBackoffsTotal = "reflow_throttle_backoffs_total"
+	ReflowParallelism           = "reflow_parallelism"
+	ReflowStageOpsTotal         = "reflow_stage_ops_total"
+	ReflowStageSecondsTotal     = "reflow_stage_seconds_total"
+
+	// Request budget metrics
+	BudgetInFlight        = "budget_in_flight"
+	BudgetMemoryHeldBytes = "budget_memory_held_bytes"
+	BudgetWaiters         = "budget_waiters"
+	BudgetRateFactor      = "budget_rate_factor"
+)
+
+// Job types used for the job_type label.
+const (
+	JobTypeCrawl       = "crawl"
+	JobTypeIndexBuild  = "index_build"
+	JobTypeIndexEnrich = "index_enrich"
+	JobTypeReflow      = "reflow"
+)
+
+// JobLabels identifies the run a domain metric belongs to. Bucket is hashed
+// before it becomes a label so bucket names never leave the host and a label
+// value has a fixed width.
+type JobLabels struct {
+	Provider string
+	Bucket   string
+	JobType  string
+}
+
+// BucketHash returns the short, stable digest used as the bucket_hash label.
+func BucketHash(bucket string) string {
+	bucket = strings.TrimSpace(bucket)
+	if bucket == "" {
+		return "none"
+	}
+	sum := sha256.Sum256([]byte(bucket))
+	return hex.EncodeToString(sum[:6])
+}
+
+func (l JobLabels) tags(extra ...string) map[string]string {
+	provider := strings.TrimSpace(l.Provider)
+	if provider == "" {
+		provider = "unknown"
+	}
+	tags := map[string]string{
+		"provider":    provider,
+		"bucket_hash": BucketHash(l.Bucket),
+		"job_type":    l.JobType,
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		tags[extra[i]] = extra[i+1]
+	}
+	return tags
+}
+
+func emitCounter(name string, value float64, tags map[string]string) {
+	if observability.TelemetrySystem != nil {
+		_ = observability.TelemetrySystem.Counter(name, value, tags)
+	}
+	if c := ActiveCollector(); c != nil {
+		c.Counter(name, value, tags)
+	}
+}
+
+func emitGauge(name string, value float64, tags map[string]string) {
+	if observability.TelemetrySystem != nil {
+		_ = observability.TelemetrySystem.Gauge(name, value, tags)
+	}
+	if c := ActiveCollector(); c != nil {
+		c.Gauge(name, value, tags)
+	}
+}
+
+// RecordCrawlSummary records the terminal counters of one crawl.
+func RecordCrawlSummary(labels JobLabels, summary *crawler.Summary, success bool) {
+	if summary == nil {
+		return
+	}
+	status := "success"
+	if !success {
+		status = "failure"
+	}
+	tags := labels.tags()
+	emitCounter(CrawlObjectsListedTotal, float64(summary.ObjectsListed), tags)
+	emitCounter(CrawlObjectsMatchedTotal, float64(summary.ObjectsMatched), tags)
+	emitCounter(CrawlBytesMatchedTotal, float64(summary.BytesTotal), tags)
+	emitCounter(CrawlErrorsTotal, float64(summary.Errors), tags)
+	emitCounter(CrawlRunsTotal, 1, labels.tags("status", status))
+	emitGauge(CrawlDurationSeconds, summary.Duration.Seconds(), tags)
+}
+
+// RecordIndexBuildSummary records the published shape of one durable build.
+func RecordIndexBuildSummary(labels JobLabels, summary indexbuild.Summary) {
+	tags := labels.tags()
+	emitCounter(IndexBuildObjectsObservedTotal, float64(summary.ObjectsObserved), tags)
+	emitGauge(IndexBuildRowsPublished, float64(summary.Manifest.Rows), tags)
+	emitGauge(IndexBuildSegmentsPublished, float64(len(summary.Manifest.Segments)), tags)
+	emitGauge(IndexBuildSpillPeakBytes, float64(summary.PeakWorkspaceBytes), tags)
+}
+
+// IndexBuildEventSink counts indexbuild engine events by type. Wrap an
+// existing sink with Next to keep delivering events to it.
+type IndexBuildEventSink struct {
+	Labels JobLabels
+	Next   indexbuild.EventSink
+}
+
+// OnEvent implements indexbuild.EventSink.
+func (s IndexBuildEventSink) OnEvent(ctx context.Context, event indexbuild.Event) error {
+	emitCounter(IndexBuildEventsTotal, 1, s.Labels.tags("event", boundedEventType(event.Type)))
+	if s.Next != nil {
+		return s.Next.OnEvent(ctx, event)
+	}
+	return nil
+}
+
+func boundedEventType(eventType string) string {
+	switch eventType {
+	case indexbuild.EventTypeRunStart, indexbuild.EventTypeCrawlError, indexbuild.EventTypeSnapshotPublished:
+		return eventType
+	default:
+		return "other"
+	}
+}
+
+// EnrichCounts is the terminal tally of one HEAD enrichment run.
+type EnrichCounts struct {
+	Candidates int64
+	Enriched   int64
+	Failed     int64
+	HeadCalls  int64
+}
+
+// RecordEnrichment records the terminal counters of one enrich-with-head run.
+func RecordEnrichment(labels JobLabels, counts EnrichCounts) {
+	tags := labels.tags()
+	emitCounter(IndexEnrichCandidatesTotal, float64(counts.Candidates), tags)
+	emitCounter(IndexEnrichEnrichedTotal, float64(counts.Enriched), tags)
+	emitCounter(IndexEnrichFailedTotal, float64(counts.Failed), tags)
+	emitCounter(IndexEnrichHeadCallsTotal, float64(counts.HeadCalls), tags)
+}
+
+// RecordReflowObject records one per-object reflow outcome.
+func RecordReflowObject(labels JobLabels, status string, bytes int64) {
+	emitCounter(ReflowObjectsTotal, 1, labels.tags("status", boundedLabel(status)))
+	if bytes > 0 {
+		emitCounter(ReflowBytesCopiedTotal, float64(bytes), labels.tags())
+	}
+}
+
+// RecordReflowError records one per-object or run reflow error by code.
+func RecordReflowError(labels JobLabels, code string) {
+	emitCounter(ReflowErrorsTotal, 1, labels.tags("code", boundedLabel(code)))
+}
+
+// RecordReflowConcurrency records the adaptive --parallel evidence carried on
+// reflow run and summary records.
+func RecordReflowConcurrency(labels JobLabels, stats reflow.ConcurrencyStats, final bool) {
+	emitGauge(ReflowParallelism, float64(stats.ConcurrencyCeilingEffective), labels.tags("level", "ceiling"))
+	emitGauge(ReflowParallelism, float64(stats.ConcurrencyInitial), labels.tags("level", "initial"))
+	if !final {
+		return
+	}
+	emitGauge(ReflowParallelism, float64(stats.ConcurrencyFinal), labels.tags("level", "final"))
+	emitGauge(ReflowParallelism, float64(stats.ConcurrencyMaxActive), labels.tags("level", "max_active"))
+	emitGauge(ReflowParallelism, stats.ConcurrencyTimeAvgActive, labels.tags("level", "time_avg_active"))
+	emitCounter(ReflowThrottleBackoffsTotal, float64(stats.ConcurrencyThrottleBackoffs), labels.tags())
+}
+
+// RecordReflowStageStats records the object-path stage timings of one reflow.
+func RecordReflowStageStats(labels JobLabels, rec reflow.ObjectPathStageStatsRecord) {
+	stages := []struct {
+		stage string
+		ops   int64
+		nanos int64
+	}{
+		{"permit_wait", rec.PermitWaits, rec.PermitWaitNanos},
+		{"source_read", rec.SourceReads, rec.SourceReadNanos},
+		{"dest_write", rec.DestWrites, rec.DestWriteNanos},
+		{"coupled_copy", rec.CoupledCopies, rec.CoupledCopyNanos},
+		{"collision_probe", rec.CollisionProbes, rec.CollisionProbeNanos},
+		{"checkpoint_write", rec.CheckpointWrites, rec.CheckpointWriteNanos},
+	}
+	for _, s := range stages {
+		if s.ops == 0 {
+			continue
+		}
+		tags := labels.tags("stage", s.stage)
+		emitCounter(ReflowStageOpsTotal, float64(s.ops), tags)
+		emitCounter(ReflowStageSecondsTotal, time.Duration(s.nanos).Seconds(), tags)
+	}
+}
+
+// RecordBudgetUsage records a point-in-time request budget snapshot. Domains
+// are aggregated, not labelled: a quota domain ID can name an account or
+// endpoint, so in-flight requests are summed per op class and the most
+// throttled domain's rate factor is reported.
+func RecordBudgetUsage(labels JobLabels, usage runbudget.Usage) {
+	inFlight := map[runbudget.OpClass]int{
+		runbudget.OpList:           0,
+		runbudget.OpHead:           0,
+		runbudget.OpGet:            0,
+		runbudget.OpCopySourceRead: 0,
+	}
+	rateFactor := 1.0
+	for _, d := range usage.Domains {
+		for class, n := range d.InFlight {
+			if _, ok := inFlight[class]; ok {
+				inFlight[class] += n
+			}
+		}
+		if d.RateFactor > 0 && d.RateFactor < rateFactor {
+			rateFactor = d.RateFactor
+		}
+	}
+	for class, n := range inFlight {
+		emitGauge(BudgetInFlight, float64(n), labels.tags("op_class", string(class)))
+	}
+	tags := labels.tags()
+	emitGauge(BudgetMemoryHeldBytes, float64(usage.MemoryHeld), tags)
+	emitGauge(BudgetWaiters, float64(usage.Waiters), tags)
+	emitGauge(BudgetRateFactor, rateFactor, tags)
+}
+
+// WatchBudget samples snapshot every interval into the budget gauges until the
+// returned stop function is called. Stop records one final sample.
+func WatchBudget(labels JobLabels, snapshot func() runbudget.Usage, interval time.Duration) (stop func()) {
+	if snapshot == nil {
+		return func() {}
+	}
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				RecordBudgetUsage(labels, snapshot())
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-exited
+			RecordBudgetUsage(labels, snapshot())
+		})
+	}
+}
+
+// ReflowEventSink records reflow engine events as domain metrics and forwards
+// every event to Next. Labels are taken from the resolved source once the
+// engine reports it.
+type ReflowEventSink struct {
+	Next reflow.EventSink
+
+	mu     sync.Mutex
+	labels JobLabels
+}
+
+// NewReflowEventSink wraps next with reflow metrics.
+func NewReflowEventSink(next reflow.EventSink) *ReflowEventSink {
+	return &ReflowEventSink{Next: next, labels: JobLabels{JobType: JobTypeReflow}}
+}
+
+func (s *ReflowEventSink) currentLabels() JobLabels {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.labels
+}
+
+// OnRun implements reflow.EventSink.
+func (s *ReflowEventSink) OnRun(ctx context.Context, rec reflow.RunRecord) error {
+	RecordReflowConcurrency(s.currentLabels(), rec.ConcurrencyStats, false)
+	return s.Next.OnRun(ctx, rec)
+}
+
+// OnSource implements reflow.EventSink.
+func (s *ReflowEventSink) OnSource(ctx context.Context, rec reflow.SourceRunRecord) error {
+	s.mu.Lock()
+	s.labels.Provider = rec.Provider
+	s.labels.Bucket = rec.Bucket
+	s.mu.Unlock()
+	return s.Next.OnSource(ctx, rec)
+}
+
+// OnRecord implements reflow.EventSink.
+func (s *ReflowEventSink) OnRecord(ctx context.Context, rec reflow.Record) error {
+	RecordReflowObject(s.currentLabels(), rec.Status, rec.Bytes)
+	return s.Next.OnRecord(ctx, rec)
+}
+
+// OnWarning implements reflow.EventSink.
+func (s *ReflowEventSink) OnWarning(ctx context.Context, w reflow.Warning) error {
+	return s.Next.OnWarning(ctx, w)
+}
+
+// OnError implements reflow.EventSink.
+func (s *ReflowEventSink) OnError(ctx context.Context, e reflow.ErrorEvent) error {
+	RecordReflowError(s.currentLabels(), e.Code)
+	return s.Next.OnError(ctx, e)
+}
+
+// OnObjectPathStageStats forwards stage statistics when Next accepts them.
+func (s *ReflowEventSink) OnObjectPathStageStats(ctx context.Context, rec reflow.ObjectPathStageStatsRecord) error {
+	RecordReflowStageStats(s.currentLabels(), rec)
+	if next, ok := s.Next.(reflow.ObjectPathStageStatsEmitter); ok {
+		return next.OnObjectPathStageStats(ctx, rec)
+	}
+	return nil
+}
+
+// OnSummary implements reflow.EventSink.
+func (s *ReflowEventSink) OnSummary(ctx context.Context, rec reflow.SummaryRecord) error {
+	RecordReflowConcurrency(s.currentLabels(), rec.ConcurrencyStats, true)
+	return s.Next.OnSummary(ctx, rec)
+}
+
+// boundedLabel keeps engine-authored vocabulary (statuses, error codes) as
+// label values while refusing anything that looks like free text.
+func boundedLabel(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "none"
+	}
+	if len(value) > 48 {
+		return "other"
+	}
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.') {
+			return "other"
+		}
+	}
+	return value
+}
@@ -5,7 +5,9 @@ package providerdispatch
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"sync/atomic"
 
 	"github.com/3leaps/gonimbus/pkg/provider"
 	providerfile "github.com/3leaps/gonimbus/pkg/provider/file"
@@ -103,6 +105,31 @@ var factories = Factories{
 	},
 }
 
+// TransportWrapper returns the HTTP transport wrapper for one provider
+// construction, given the provider scheme and bucket.
+type TransportWrapper func(providerName, bucket string) func(http.RoundTripper) http.RoundTripper
+
+var transportWrapper atomic.Pointer[TransportWrapper]
+
+// SetTransportWrapper installs wrap for every S3 and GCS provider constructed
+// afterwards. The CLI uses it to trace provider requests. Passing nil removes
+// the wrapper.
+func SetTransportWrapper(wrap TransportWrapper) {
+	if wrap == nil {
+		transportWrapper.Store(nil)
+		return
+	}
+	transportWrapper.Store(&wrap)
+}
+
+func wrapTransport(providerName, bucket string) func(http.RoundTripper) http.RoundTripper {
+	wrap := transportWrapper.Load()
+	if wrap == nil {
+		return nil
+	}
+	return (*wrap)(providerName, bucket)
+}
+
 // UseFactoriesForTest installs temporary constructors and returns a restore
 // function. Tests should call the returned function from t.Cleanup.
 func UseFactoriesForTest(next Factories) func() {
@@ -150,6 +177,7 @@ func NewSourceBinding(ctx context.Context, src *uri.ObjectURI, opts SourceOption
 			ForcePathStyle:      opts.S3.ForcePathStyle,
 			MaxIdleConnsPerHost: opts.S3.MaxIdleConnsPerHost,
 			MaxConnsPerHost:     opts.S3.MaxConnsPerHost,
+			WrapTransport:       wrapTransport(src.Provider, src.Bucket),
 		})
 	case string(provider.ProviderGCS):
 		p, err = factories.GCS(ctx, gcs.Config{
@@ -159,6 +187,7 @@ func NewSourceBinding(ctx context.Context, src *uri.ObjectURI, opts SourceOption
 			MaxIdleConnsPerHost:  opts.GCS.MaxIdleConnsPerHost,
 			MaxConnsPerHost:      opts.GCS.MaxConnsPerHost,
 			WriterChunkSizeBytes: opts.GCS.WriterChunkSizeBytes,
+			WrapTransport:        wrapTransport(src.Provider, src.Bucket),
 		})
 	case string(provider.ProviderFile):
 		baseDir := opts.FileBaseDir
@@ -218,6 +247,7 @@ func NewDestination(ctx context.Context, opts DestinationOptions) (provider.Prov
 			ForcePathStyle:      opts.S3.ForcePathStyle,
 			MaxIdleConnsPerHost: opts.S3.MaxIdleConnsPerHost,
 			MaxConnsPerHost:     opts.S3.MaxConnsPerHost,
+			WrapTransport:       wrapTransport(opts.Provider, opts.S3Bucket),
 		})
 	case string(provider.ProviderGCS):
 		bucket := opts.GCSBucket
@@ -231,6 +261,7 @@ func NewDestination(ctx context.Context, opts DestinationOptions) (provider.Prov
 			MaxIdleConnsPerHost:  opts.GCS.MaxIdleConnsPerHost,
 			MaxConnsPerHost:      opts.GCS.MaxConnsPerHost,
 			WriterChunkSizeBytes: opts.GCS.WriterChunkSizeBytes,
+			WrapTransport:        wrapTransport(opts.Provider, bucket),
 		})
 	case string(provider.ProviderFile):
 		if opts.FileBaseDir == "" {
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/3leaps/gonimbus/internal/observability"
+	"github.com/3leaps/gonimbus/internal/server/handlers"
+)
+
+// Drain job modes: what a draining server does with the background jobs it
+// started.
+const (
+	// DrainJobsLeave leaves jobs running; they are separate processes and
+	// outlive the server.
+	DrainJobsLeave = "leave"
+	// DrainJobsWait waits for jobs to finish, then checkpoints any still
+	// running when the drain timeout expires.
+	DrainJobsWait = "wait"
+	// DrainJobsCheckpoint stops jobs with SIGTERM right away so they record a
+	// resumable checkpoint.
+	DrainJobsCheckpoint = "checkpoint"
+)
+
+const defaultDrainPollInterval = 500 * time.Millisecond
+
+// DrainOptions configures Drain.
+type DrainOptions struct {
+	Jobs string
+	// Timeout bounds DrainJobsWait; zero waits until ctx is done.
+	Timeout time.Duration
+	// StopTimeout is how long a checkpointed job gets to exit after SIGTERM
+	// before it is killed.
+	StopTimeout  time.Duration
+	PollInterval time.Duration
+}
+
+// DrainResult reports what a drain did with the server's jobs.
+type DrainResult struct {
+	Finished     int                   `json:"finished"`
+	Checkpointed []handlers.DrainedJob `json:"checkpointed,omitempty"`
+	Left         []string              `json:"left,omitempty"`
+}
+
+// ValidateDrainJobs checks a drain job mode.
+func ValidateDrainJobs(mode string) error {
+	switch mode {
+	case DrainJobsLeave, DrainJobsWait, DrainJobsCheckpoint:
+		return nil
+	default:
+		return fmt.Errorf("invalid drain jobs mode %q (want leave, wait, or checkpoint)", mode)
+	}
+}
+
+// Draining reports whether Drain has begun.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// Drain prepares the server for shutdown. Readiness flips to not-ready, the
+// scheduler stops, job submissions are refused, and queue admission is held
+// on the jobs root at once, so a job finishing during the drain does not start
+// the next queued one; the hold lapses when this process exits. The jobs this
+// server started, submitted or scheduled, are then left, waited on, or
+// checkpointed per opts. The listener keeps serving status, health, and reads
+// until Shutdown.
+func (s *Server) Drain(ctx context.Context, opts DrainOptions) (*DrainResult, error) {
+	if err := ValidateDrainJobs(opts.Jobs); err != nil {
+		return nil, err
+	}
+	s.draining.Store(true)
+	if hm := handlers.GetHealthManager(); hm != nil {
+		hm.SetDraining(true)
+	}
+	s.StopScheduler()
+	if s.queue != nil {
+		if err := s.queue.HoldAdmission(); err != nil && observability.ServerLogger != nil {
+			observability.ServerLogger.Warn("Failed to hold job queue admission", zap.Error(err))
+		}
+	}
+	result := &DrainResult{}
+	if s.jobs == nil {
+		return result, nil
+	}
+	s.jobs.BeginDrain()
+
+	active, err := s.jobs.ActiveJobs()
+	if err != nil {
+		return result, err
+	}
+	s.logDrain("Draining server", zap.String("jobs", opts.Jobs), zap.Int("active_jobs", len(active)))
+
+	switch opts.Jobs {
+	case DrainJobsLeave:
+		for _, job := range active {
+			result.Left = append(result.Left, job.JobID)
+		}
+		return result, nil
+	case DrainJobsWait:
+		waitCtx := ctx
+		if opts.Timeout > 0 {
+			var cancel context.CancelFunc
+			waitCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+			defer cancel()
+		}
+		poll := opts.PollInterval
+		if poll <= 0 {
+			poll = defaultDrainPollInterval
+		}
+		initial := len(active)
+		for len(active) > 0 && waitCtx.Err() == nil {
+			select {
+			case <-waitCtx.Done():
+			case <-time.After(poll):
+			}
+			if active, err = s.jobs.ActiveJobs(); err != nil {
+				return result, err
+			}
+		}
+		result.Finished = initial - len(active)
+		if len(active) > 0 {
+			s.logDrain("Drain timeout reached; checkpointing remaining jobs", zap.Int("remaining_jobs", len(active)))
+		}
+	}
+
+	for _, job := range active {
+		drained, err := s.jobs.CheckpointJob(job.JobID, opts.StopTimeout)
+		if err != nil {
+			if handlers.IsJobNotRunning(err) {
+				result.Left = append(result.Left, job.JobID)
+				continue
+			}
+			return result, fmt.Errorf("checkpoint job %s: %w", job.JobID, err)
+		}
+		s.logDrain("Checkpointed job",
+			zap.String("job_id", drained.JobID),
+			zap.String("run_id", drained.RunID),
+			zap.Bool("forced_kill", drained.ForcedKill),
+			zap.String("resume_command", drained.ResumeCommand))
+		result.Checkpointed = append(result.Checkpointed, *drained)
+	}
+	return result, nil
+}
+
+func (s *Server) logDrain(msg string, fields ...zap.Field) {
+	if observability.ServerLogger != nil {
+		observability.ServerLogger.Info(msg, fields...)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/fulmenhq/gofulmen/errors"
@@ -32,6 +33,7 @@ type HealthChecker interface {
 type HealthManager struct {
 	checkers map[string]HealthChecker
 	version  string
+	draining atomic.Bool
 }
 
 // NewHealthManager creates a new health manager
@@ -47,6 +49,17 @@ func (hm *HealthManager) RegisterChecker(name string, checker HealthChecker) {
 	hm.checkers[name] = checker
 }
 
+// SetDraining marks the server as draining. A draining server stays live but
+// reports not-ready, so load balancers stop sending it new work.
+func (hm *HealthManager) SetDraining(draining bool) {
+	hm.draining.Store(draining)
+}
+
+// Draining reports whether the server is draining.
+func (hm *HealthManager) Draining() bool {
+	return hm.draining.Load()
+}
+
 // runHealthChecks executes all registered health checks
 func (hm *HealthManager) runHealthChecks(ctx context.Context) map[string]string {
 	checks := make(map[string]string)
@@ -152,6 +165,13 @@ func (hm *HealthManager) LivenessHandler(w http.ResponseWriter, r *http.Request)
 func (hm *HealthManager) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	if hm.Draining() {
+		envelope := errors.NewErrorEnvelope("SERVICE_UNAVAILABLE", "readiness probe failed: server is draining")
+		envelope = enrichHealthEnvelope(envelope, "ready", "draining", nil)
+		respondWithError(w, r, envelope)
+		return
+	}
+
 	// Run health checks with timeout for readiness
 	checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -239,3 +239,28 @@ func TestGlobalHandlers_WhenNotInitialized(t *testing.T) {
 		})
 	}
 }
+
+func TestReadinessReportsNotReadyWhileDraining(t *testing.T) {
+	manager := NewHealthManager("1.2.3")
+	manager.RegisterChecker("ok", stubChecker{err: nil})
+	manager.SetDraining(true)
+
+	rec := httptest.NewRecorder()
+	manager.ReadinessHandler(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readiness 503 while draining, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	manager.LivenessHandler(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected liveness 200 while draining, got %d", rec.Code)
+	}
+
+	manager.SetDraining(false)
+	rec = httptest.NewRecorder()
+	manager.ReadinessHandler(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected readiness 200 after drain cleared, got %d", rec.Code)
+	}
+}
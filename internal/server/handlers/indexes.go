@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/3leaps/gonimbus/internal/errors"
+	"github.com/3leaps/gonimbus/internal/indexquery"
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/pkg/indexreader"
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+	"github.com/3leaps/gonimbus/pkg/match"
+)
+
+const (
+	// defaultIndexQueryPageSize is the page size when a query omits limit.
+	defaultIndexQueryPageSize = 1000
+	// maxIndexQueryPageSize bounds one page so a single request cannot pin a
+	// reader for an unbounded walk; clients page with the returned cursor.
+	maxIndexQueryPageSize = 100000
+
+	// indexQueryPageRecordType is the trailing record of every query page.
+	indexQueryPageRecordType = "gonimbus.index.query.page.v1"
+)
+
+type indexResolver interface {
+	List(context.Context) ([]indexreader.ListedIndex, error)
+	Open(context.Context, string) (indexreader.Reader, error)
+	Options() indexreader.ResolveOptions
+}
+
+type localIndexResolver struct {
+	opts indexreader.ResolveOptions
+}
+
+func (l localIndexResolver) List(ctx context.Context) ([]indexreader.ListedIndex, error) {
+	return indexreader.ListIndexReaders(ctx, l.opts)
+}
+
+func (l localIndexResolver) Open(ctx context.Context, id string) (indexreader.Reader, error) {
+	return indexreader.ResolveIndexReader(ctx, l.opts, indexreader.ResolveTarget{IndexSetID: id})
+}
+
+func (l localIndexResolver) Options() indexreader.ResolveOptions {
+	return l.opts
+}
+
+// IndexesHandler serves read-only access to the local indexes under the
+// server's index roots.
+type IndexesHandler struct {
+	indexes indexResolver
+}
+
+func NewIndexesHandler(opts indexreader.ResolveOptions) *IndexesHandler {
+	return &IndexesHandler{indexes: localIndexResolver{opts: opts}}
+}
+
+type indexListItem struct {
+	IndexSetID         string `json:"index_set_id"`
+	Format             string `json:"format"`
+	BaseURI            string `json:"base_uri"`
+	Provider           string `json:"provider"`
+	RunID              string `json:"run_id,omitempty"`
+	IdentityStatus     string `json:"identity_status"`
+	IdentityDiagnostic string `json:"identity_diagnostic,omitempty"`
+}
+
+type indexListEnvelope struct {
+	Indexes []indexListItem `json:"indexes"`
+	Total   int             `json:"total"`
+}
+
+type indexStatsObjects struct {
+	Active         int64  `json:"active"`
+	Deleted        int64  `json:"deleted"`
+	Total          int64  `json:"total"`
+	TotalSizeBytes int64  `json:"total_size_bytes"`
+	SizeSemantics  string `json:"size_semantics,omitempty"`
+}
+
+type indexStatsRun struct {
+	RunID       string  `json:"run_id"`
+	Status      string  `json:"status"`
+	StartedAt   *string `json:"started_at,omitempty"`
+	EndedAt     *string `json:"ended_at,omitempty"`
+	PublishedAt *string `json:"published_at,omitempty"`
+}
+
+type indexStatsEnvelope struct {
+	IndexSetID string            `json:"index_set_id"`
+	Format     string            `json:"format"`
+	BaseURI    string            `json:"base_uri"`
+	Provider   string            `json:"provider"`
+	Objects    indexStatsObjects `json:"objects"`
+	LatestRun  *indexStatsRun    `json:"latest_run,omitempty"`
+}
+
+// indexQueryRequest mirrors the `gonimbus index query` filters. Sizes and
+// dates use the same syntax as the CLI flags.
+type indexQueryRequest struct {
+	Pattern           string   `json:"pattern,omitempty"`
+	KeyRegex          string   `json:"key_regex,omitempty"`
+	MinSize           string   `json:"min_size,omitempty"`
+	MaxSize           string   `json:"max_size,omitempty"`
+	After             string   `json:"after,omitempty"`
+	Before            string   `json:"before,omitempty"`
+	EnrichedAfter     string   `json:"enriched_after,omitempty"`
+	StorageClass      []string `json:"storage_class,omitempty"`
+	IncludeDeleted    bool     `json:"include_deleted,omitempty"`
+	SinceRun          string   `json:"since_run,omitempty"`
+	CanonicalByETag   bool     `json:"canonical_by_etag,omitempty"`
+	CanonicalTieBreak string   `json:"canonical_tie_break,omitempty"`
+	IncludeAlternates bool     `json:"include_alternates,omitempty"`
+	Limit             int      `json:"limit,omitempty"`
+	Cursor            string   `json:"cursor,omitempty"`
+}
+
+type indexQueryPageRecord struct {
+	Type string             `json:"type"`
+	TS   string             `json:"ts"`
+	Data indexQueryPageData `json:"data"`
+}
+
+type indexQueryPageData struct {
+	IndexSetID string `json:"index_set_id"`
+	Records    int    `json:"records"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (h *IndexesHandler) List(w http.ResponseWriter, r *http.Request) {
+	listed, err := h.indexes.List(r.Context())
+	if err != nil {
+		respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "list indexes"))
+		return
+	}
+	items := make([]indexListItem, 0, len(listed))
+	for _, item := range listed {
+		items = append(items, indexListItem{
+			IndexSetID:         item.Meta.IndexSetID,
+			Format:             string(item.Meta.Format),
+			BaseURI:            item.Meta.BaseURI,
+			Provider:           item.Meta.Provider,
+			RunID:              item.Meta.RunID,
+			IdentityStatus:     string(item.IdentityStatus),
+			IdentityDiagnostic: item.IdentityDiagnostic,
+		})
+	}
+	writeJSON(w, http.StatusOK, indexListEnvelope{Indexes: items, Total: len(items)})
+}
+
+func (h *IndexesHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	reader, ok := h.openIndex(w, r)
+	if !ok {
+		return
+	}
+	defer func() { _ = reader.Close() }()
+
+	meta := reader.Meta()
+	out := indexStatsEnvelope{
+		IndexSetID: meta.IndexSetID,
+		Format:     string(meta.Format),
+		BaseURI:    meta.BaseURI,
+		Provider:   meta.Provider,
+	}
+	switch meta.Format {
+	case indexreader.FormatSQLiteV1:
+		db := reader.SQLiteDB()
+		if db == nil {
+			respondWithError(w, r, apperrors.NewInternalError("SQLite reader connection is unavailable"))
+			return
+		}
+		summary, err := indexstore.GetIndexSetSummary(r.Context(), db, meta.IndexSetID)
+		if err != nil {
+			respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "read index summary"))
+			return
+		}
+		out.Objects = indexStatsObjects{
+			Active:         summary.ActiveObjects,
+			Deleted:        summary.DeletedObjects,
+			Total:          summary.TotalObjects,
+			TotalSizeBytes: summary.TotalSizeBytes,
+		}
+		if run := summary.LatestRun; run != nil {
+			out.LatestRun = &indexStatsRun{
+				RunID:     run.RunID,
+				Status:    string(run.Status),
+				StartedAt: formatStatsTime(&run.StartedAt),
+				EndedAt:   formatStatsTime(run.EndedAt),
+			}
+		}
+	case indexreader.FormatDurableV2:
+		opts := h.indexes.Options()
+		snap, err := indexsubstrate.OpenLatestPublishedSnapshotBounded(meta.SourcePath, opts.MaxMarkerBytes, opts.MaxManifestBytes)
+		if err != nil {
+			respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "open durable snapshot"))
+			return
+		}
+		var segmentBytes int64
+		for _, seg := range snap.Manifest.Segments {
+			segmentBytes += seg.SizeBytes
+		}
+		// Durable counts come from the published manifest; size is the sum of
+		// segment files, matching `gonimbus index stats`.
+		out.Objects = indexStatsObjects{
+			Active:         int64(snap.Manifest.Counts.ActiveRows),
+			Deleted:        int64(snap.Manifest.Counts.Tombstones),
+			Total:          int64(snap.Manifest.Counts.Rows),
+			TotalSizeBytes: segmentBytes,
+			SizeSemantics:  "segment_file_bytes",
+		}
+		latest := &indexStatsRun{RunID: snap.Manifest.RunID, Status: string(indexstore.RunStatusSuccess)}
+		if ts, parseErr := time.Parse(time.RFC3339Nano, snap.Complete.CompletedAt); parseErr == nil {
+			latest.PublishedAt = formatStatsTime(&ts)
+		}
+		out.LatestRun = latest
+	default:
+		respondWithError(w, r, apperrors.NewInternalError(fmt.Sprintf("unsupported index format %q", meta.Format)))
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// Query streams one page of matching index records as JSONL, in rel_key
+// order, followed by a gonimbus.index.query.page.v1 record. When has_more is
+// true, repeating the request with cursor set to next_cursor returns the next
+// page. Records are identical to `gonimbus index query` output.
+//
+// Rows are written as the reader visits them, so a durable segment that fails
+// verification mid-walk ends the stream without a page record; clients must
+// treat a missing page record as a failed page.
+func (h *IndexesHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req indexQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, apperrors.WrapInvalidInput(r.Context(), err, "invalid index query JSON"))
+		return
+	}
+	params, pageSize, err := buildIndexQueryParams(r.Context(), req)
+	if err != nil {
+		respondWithError(w, r, err)
+		return
+	}
+
+	reader, ok := h.openIndex(w, r)
+	if !ok {
+		return
+	}
+	defer func() { _ = reader.Close() }()
+	meta := reader.Meta()
+	params.IndexSetID = meta.IndexSetID
+
+	if strings.TrimSpace(req.SinceRun) != "" {
+		filter, err := reader.ResolveSinceRunFilter(r.Context(), strings.TrimSpace(req.SinceRun))
+		if err != nil {
+			respondWithError(w, r, apperrors.WrapValidationError(r.Context(), err, "invalid since_run"))
+			return
+		}
+		params.SinceRun = filter
+	}
+
+	// Fetch one record past the page to learn whether another page exists.
+	params.Limit = pageSize + 1
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	page := indexQueryPageData{IndexSetID: meta.IndexSetID}
+
+	if req.CanonicalByETag {
+		// Canonical grouping materialises the filtered set before the first
+		// record, so validation errors still surface as a JSON error response.
+		results, _, err := reader.QueryCanonicalObjects(r.Context(), params)
+		if err != nil {
+			respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "index query failed"))
+			return
+		}
+		enc := startIndexQueryStream(w)
+		for _, result := range results {
+			if page.Records == pageSize {
+				page.HasMore = true
+				break
+			}
+			var (
+				record any
+				relKey string
+			)
+			switch {
+			case result.Group != nil:
+				record = indexquery.NewCanonicalRecord(meta.BaseURI, now, *result.Group, params.CanonicalTieBreak, req.IncludeAlternates)
+				relKey = result.Group.Canonical.RelKey
+			case result.Passthrough != nil:
+				record = indexquery.NewObjectRecord(meta.BaseURI, now, *result.Passthrough)
+				relKey = result.Passthrough.RelKey
+			default:
+				continue
+			}
+			if err := enc.Encode(record); err != nil {
+				return
+			}
+			page.Records++
+			page.NextCursor = relKey
+		}
+		finishIndexQueryStream(w, enc, now, page)
+		return
+	}
+
+	var enc *json.Encoder
+	_, err = reader.WalkObjects(r.Context(), params, func(result indexstore.QueryResult) error {
+		if page.Records == pageSize {
+			page.HasMore = true
+			return errIndexQueryPageFull
+		}
+		if enc == nil {
+			enc = startIndexQueryStream(w)
+		}
+		if err := enc.Encode(indexquery.NewObjectRecord(meta.BaseURI, now, result)); err != nil {
+			return err
+		}
+		page.Records++
+		page.NextCursor = result.RelKey
+		return nil
+	})
+	if err != nil && !errors.Is(err, errIndexQueryPageFull) {
+		if enc == nil {
+			respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "index query failed"))
+		}
+		// Records are already on the wire; ending without a page record marks
+		// the page as failed.
+		return
+	}
+	if enc == nil {
+		enc = startIndexQueryStream(w)
+	}
+	finishIndexQueryStream(w, enc, now, page)
+}
+
+var errIndexQueryPageFull = errors.New("index query page full")
+
+func (h *IndexesHandler) openIndex(w http.ResponseWriter, r *http.Request) (indexreader.Reader, bool) {
+	id := strings.TrimSpace(chi.URLParam(r, "index_id"))
+	if id == "" {
+		respondWithError(w, r, apperrors.NewInvalidInputError("index_id is required"))
+		return nil, false
+	}
+	reader, err := h.indexes.Open(r.Context(), id)
+	if err != nil {
+		respondWithError(w, r, apperrors.WrapNotFound(r.Context(), err, "index not found"))
+		return nil, false
+	}
+	return reader, true
+}
+
+func buildIndexQueryParams(ctx context.Context, req indexQueryRequest) (indexstore.QueryParams, int, error) {
+	params := indexstore.QueryParams{
+		Pattern:        req.Pattern,
+		KeyRegex:       req.KeyRegex,
+		IncludeDeleted: req.IncludeDeleted,
+		StartAfter:     req.Cursor,
+	}
+	pageSize := req.Limit
+	switch {
+	case pageSize == 0:
+		pageSize = defaultIndexQueryPageSize
+	case pageSize < 0 || pageSize > maxIndexQueryPageSize:
+		return params, 0, apperrors.NewInvalidInputError(fmt.Sprintf("limit must be between 1 and %d", maxIndexQueryPageSize))
+	}
+
+	if params.Pattern != "" && !doublestar.ValidatePattern(params.Pattern) {
+		return params, 0, apperrors.NewInvalidInputError("invalid pattern")
+	}
+	if params.KeyRegex != "" {
+		if _, err := regexp.Compile(params.KeyRegex); err != nil {
+			return params, 0, apperrors.WrapValidationError(ctx, err, "invalid key_regex")
+		}
+	}
+	if req.IncludeDeleted && strings.TrimSpace(req.SinceRun) != "" {
+		return params, 0, apperrors.NewInvalidInputError("include_deleted is not supported with since_run")
+	}
+	if req.CanonicalByETag {
+		params.CanonicalTieBreak = indexstore.CanonicalTieBreakMinKey
+		if raw := strings.TrimSpace(req.CanonicalTieBreak); raw != "" {
+			params.CanonicalTieBreak = indexstore.CanonicalTieBreak(raw)
+		}
+		switch params.CanonicalTieBreak {
+		case indexstore.CanonicalTieBreakMinKey, indexstore.CanonicalTieBreakMinModified, indexstore.CanonicalTieBreakMaxModified:
+		default:
+			return params, 0, apperrors.NewInvalidInputError("canonical_tie_break must be min-key, min-modified, or max-modified")
+		}
+	} else if strings.TrimSpace(req.CanonicalTieBreak) != "" || req.IncludeAlternates {
+		return params, 0, apperrors.NewInvalidInputError("canonical_tie_break and include_alternates require canonical_by_etag")
+	}
+	for _, class := range req.StorageClass {
+		if strings.TrimSpace(class) == "" {
+			return params, 0, apperrors.NewInvalidInputError("storage_class contains an empty value")
+		}
+		params.StorageClasses = append(params.StorageClasses, strings.TrimSpace(class))
+	}
+
+	if req.MinSize != "" {
+		size, err := match.ParseSize(req.MinSize)
+		if err != nil {
+			return params, 0, apperrors.WrapValidationError(ctx, err, "invalid min_size")
+		}
+		params.MinSize = size
+	}
+	if req.MaxSize != "" {
+		size, err := match.ParseSize(req.MaxSize)
+		if err != nil {
+			return params, 0, apperrors.WrapValidationError(ctx, err, "invalid max_size")
+		}
+		params.MaxSize = size
+	}
+	for _, d := range []struct {
+		raw  string
+		name string
+		dst  *time.Time
+	}{
+		{req.After, "after", &params.ModifiedAfter},
+		{req.Before, "before", &params.ModifiedBefore},
+		{req.EnrichedAfter, "enriched_after", &params.EnrichedAfter},
+	} {
+		if d.raw == "" {
+			continue
+		}
+		t, err := match.ParseDate(d.raw)
+		if err != nil {
+			return params, 0, apperrors.WrapValidationError(ctx, err, "invalid "+d.name)
+		}
+		*d.dst = t
+	}
+	return params, pageSize, nil
+}
+
+func startIndexQueryStream(w http.ResponseWriter) *json.Encoder {
+	// Large pages outlive the server's write timeout; clear it for this response.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w)
+}
+
+func finishIndexQueryStream(w http.ResponseWriter, enc *json.Encoder, ts string, page indexQueryPageData) {
+	if !page.HasMore {
+		page.NextCursor = ""
+	}
+	_ = enc.Encode(indexQueryPageRecord{Type: indexQueryPageRecordType, TS: ts, Data: page})
+	_ = http.NewResponseController(w).Flush()
+}
+
+func formatStatsTime(t *time.Time) *string {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	s := t.UTC().Format(time.RFC3339)
+	return &s
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/indexquery"
+	"github.com/3leaps/gonimbus/pkg/indexreader"
+	"github.com/3leaps/gonimbus/pkg/indexstore"
+)
+
+const indexAPITestSetID = "idx_0123456789abcdef"
+
+type fakeIndexReader struct {
+	meta indexreader.Meta
+	db   *sql.DB
+	rows []indexstore.QueryResult
+}
+
+func (f *fakeIndexReader) Meta() indexreader.Meta { return f.meta }
+func (f *fakeIndexReader) SQLiteDB() *sql.DB      { return f.db }
+func (f *fakeIndexReader) Close() error           { return nil }
+
+func (f *fakeIndexReader) WalkObjects(_ context.Context, params indexstore.QueryParams, visit indexreader.VisitObject) (indexstore.QueryStats, error) {
+	visited := 0
+	for _, row := range f.rows {
+		if row.RelKey <= params.StartAfter {
+			continue
+		}
+		if params.Limit > 0 && visited == params.Limit {
+			break
+		}
+		if err := visit(row); err != nil {
+			return indexstore.QueryStats{}, err
+		}
+		visited++
+	}
+	return indexstore.QueryStats{}, nil
+}
+
+func (f *fakeIndexReader) QueryObjects(ctx context.Context, params indexstore.QueryParams) ([]indexstore.QueryResult, indexstore.QueryStats, error) {
+	var out []indexstore.QueryResult
+	stats, err := f.WalkObjects(ctx, params, func(r indexstore.QueryResult) error {
+		out = append(out, r)
+		return nil
+	})
+	return out, stats, err
+}
+
+func (f *fakeIndexReader) QueryObjectCount(ctx context.Context, params indexstore.QueryParams) (int64, error) {
+	out, _, err := f.QueryObjects(ctx, params)
+	return int64(len(out)), err
+}
+
+func (f *fakeIndexReader) QueryCanonicalObjects(_ context.Context, params indexstore.QueryParams) ([]indexstore.CanonicalOutputRecord, indexstore.CanonicalQueryStats, error) {
+	var out []indexstore.CanonicalOutputRecord
+	for _, row := range f.rows {
+		if row.RelKey <= params.StartAfter {
+			continue
+		}
+		if params.Limit > 0 && len(out) == params.Limit {
+			break
+		}
+		r := row
+		out = append(out, indexstore.CanonicalOutputRecord{Group: &indexstore.CanonicalObjectGroup{ETag: r.ETag, Canonical: r}})
+	}
+	return out, indexstore.CanonicalQueryStats{TotalRecords: len(out)}, nil
+}
+
+func (f *fakeIndexReader) LookupKeys(_ context.Context, keys []string) ([]indexstore.QueryResult, indexstore.QueryStats, error) {
+	want := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		want[key] = struct{}{}
+	}
+	var out []indexstore.QueryResult
+	for _, row := range f.rows {
+		if _, ok := want[row.RelKey]; ok {
+			out = append(out, row)
+		}
+	}
+	return out, indexstore.QueryStats{KeysLookedUp: int64(len(want))}, nil
+}
+
+func (f *fakeIndexReader) ResolveSinceRunFilter(context.Context, string) (*indexstore.SinceRunFilter, error) {
+	return nil, indexreader.ErrDurableSinceRunUnsupported
+}
+
+type fakeIndexResolver struct {
+	reader *fakeIndexReader
+	listed []indexreader.ListedIndex
+}
+
+func (f *fakeIndexResolver) List(context.Context) ([]indexreader.ListedIndex, error) {
+	return f.listed, nil
+}
+
+func (f *fakeIndexResolver) Open(_ context.Context, id string) (indexreader.Reader, error) {
+	if id != indexAPITestSetID {
+		return nil, os.ErrNotExist
+	}
+	return f.reader, nil
+}
+
+func (f *fakeIndexResolver) Options() indexreader.ResolveOptions {
+	return indexreader.ResolveOptions{}
+}
+
+func newIndexAPITestHandler(rows ...string) *IndexesHandler {
+	reader := &fakeIndexReader{meta: indexreader.Meta{
+		Format:     indexreader.FormatDurableV2,
+		IndexSetID: indexAPITestSetID,
+		BaseURI:    "s3://bucket/data/",
+		Provider:   "s3",
+	}}
+	for i, key := range rows {
+		reader.rows = append(reader.rows, indexstore.QueryResult{RelKey: key, SizeBytes: int64(i + 1), ETag: "etag-" + key})
+	}
+	return &IndexesHandler{indexes: &fakeIndexResolver{reader: reader}}
+}
+
+func serveIndexesRequest(h *IndexesHandler, method, target, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Get("/api/v1/indexes", h.List)
+	r.Get("/api/v1/indexes/{index_id}/stats", h.Stats)
+	r.Post("/api/v1/indexes/{index_id}/query", h.Query)
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// readIndexQueryPage splits a query response into its record lines and the
+// trailing page record.
+func readIndexQueryPage(t *testing.T, body string) ([]json.RawMessage, indexQueryPageData) {
+	t.Helper()
+	var lines []json.RawMessage
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		lines = append(lines, json.RawMessage(append([]byte(nil), scanner.Bytes()...)))
+	}
+	require.NoError(t, scanner.Err())
+	require.NotEmpty(t, lines)
+	var page indexQueryPageRecord
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &page))
+	require.Equal(t, indexQueryPageRecordType, page.Type)
+	return lines[:len(lines)-1], page.Data
+}
+
+func TestIndexesHandlerQueryPagesByCursor(t *testing.T) {
+	h := newIndexAPITestHandler("a/1.json", "a/2.json", "b/1.json", "b/2.json", "c/1.json")
+
+	var (
+		keys   []string
+		cursor string
+		pages  int
+	)
+	for {
+		body, err := json.Marshal(indexQueryRequest{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		rec := serveIndexesRequest(h, http.MethodPost, "/api/v1/indexes/"+indexAPITestSetID+"/query", string(body))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"))
+
+		records, page := readIndexQueryPage(t, rec.Body.String())
+		pages++
+		require.Equal(t, len(records), page.Records)
+		for _, raw := range records {
+			var record indexquery.ObjectRecord
+			require.NoError(t, json.Unmarshal(raw, &record))
+			require.Equal(t, indexquery.ObjectRecordType, record.Type)
+			require.Equal(t, "data/"+record.Data.RelKey, record.Data.Key)
+			keys = append(keys, record.Data.RelKey)
+		}
+		if !page.HasMore {
+			require.Empty(t, page.NextCursor)
+			break
+		}
+		require.Equal(t, keys[len(keys)-1], page.NextCursor)
+		cursor = page.NextCursor
+	}
+	require.Equal(t, 3, pages)
+	require.Equal(t, []string{"a/1.json", "a/2.json", "b/1.json", "b/2.json", "c/1.json"}, keys)
+}
+
+func TestIndexesHandlerQueryExactPageHasNoMore(t *testing.T) {
+	h := newIndexAPITestHandler("a", "b")
+	rec := serveIndexesRequest(h, http.MethodPost, "/api/v1/indexes/"+indexAPITestSetID+"/query", `{"limit":2}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	records, page := readIndexQueryPage(t, rec.Body.String())
+	require.Len(t, records, 2)
+	require.False(t, page.HasMore)
+
+	rec = serveIndexesRequest(h, http.MethodPost, "/api/v1/indexes/"+indexAPITestSetID+"/query", `{"cursor":"b"}`)
+	records, page = readIndexQueryPage(t, rec.Body.String())
+	require.Empty(t, records)
+	require.Equal(t, indexQueryPageData{IndexSetID: indexAPITestSetID}, page)
+}
+
+func TestIndexesHandlerQueryCanonicalPages(t *testing.T) {
+	h := newIndexAPITestHandler("a", "b", "c")
+	rec := serveIndexesRequest(h, http.MethodPost, "/api/v1/indexes/"+indexAPITestSetID+"/query",
+		`{"canonical_by_etag":true,"limit":1,"cursor":"a"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	records, page := readIndexQueryPage(t, rec.Body.String())
+	require.Len(t, records, 1)
+	var record indexquery.CanonicalRecord
+	require.NoError(t, json.Unmarshal(records[0], &record))
+	require.Equal(t, indexquery.CanonicalRecordType, record.Type)
+	require.Equal(t, "b", record.Data.Canonical.RelKey)
+	require.Equal(t, string(indexstore.CanonicalTieBreakMinKey), record.Data.TieBreakRule)
+	require.True(t, page.HasMore)
+	require.Equal(t, "b", page.NextCursor)
+}
+
+func TestIndexesHandlerQueryRejectsInvalidRequests(t *testing.T) {
+	h := newIndexAPITestHandler("a")
+	target := "/api/v1/indexes/" + indexAPITestSetID + "/query"
+	for name, body := range map[string]string{
+		"malformed json":          `{`,
+		"negative limit":          `{"limit":-1}`,
+		"oversized limit":         `{"limit":100001}`,
+		"bad pattern":             `{"pattern":"[a"}`,
+		"bad regex":               `{"key_regex":"("}`,
+		"bad size":                `{"min_size":"lots"}`,
+		"bad date":                `{"after":"yesterday"}`,
+		"empty storage class":     `{"storage_class":[""]}`,
+		"alternates without etag": `{"include_alternates":true}`,
+		"unknown tie break":       `{"canonical_by_etag":true,"canonical_tie_break":"newest"}`,
+		"deleted with since run":  `{"include_deleted":true,"since_run":"run_1"}`,
+		"since run on durable-v2": `{"since_run":"run_1"}`,
+	} {
+		rec := serveIndexesRequest(h, http.MethodPost, target, body)
+		require.Equal(t, http.StatusBadRequest, rec.Code, name)
+	}
+
+	rec := serveIndexesRequest(h, http.MethodPost, "/api/v1/indexes/idx_missing/query", `{}`)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestIndexesHandlerQueryWalkFailureBeforeFirstRecord(t *testing.T) {
+	h := newIndexAPITestHandler()
+	h.indexes.(*fakeIndexResolver).reader.rows = nil
+	h.indexes = &failingIndexResolver{fakeIndexResolver: h.indexes.(*fakeIndexResolver)}
+	rec := serveIndexesRequest(h, http.MethodPost, "/api/v1/indexes/"+indexAPITestSetID+"/query", `{}`)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+type failingIndexResolver struct {
+	*fakeIndexResolver
+}
+
+func (f *failingIndexResolver) Open(ctx context.Context, id string) (indexreader.Reader, error) {
+	reader, err := f.fakeIndexResolver.Open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &failingWalkReader{Reader: reader}, nil
+}
+
+type failingWalkReader struct {
+	indexreader.Reader
+}
+
+func (f *failingWalkReader) WalkObjects(context.Context, indexstore.QueryParams, indexreader.VisitObject) (indexstore.QueryStats, error) {
+	return indexstore.QueryStats{}, errors.New("segment digest mismatch")
+}
+
+func TestIndexesHandlerListAndSQLiteStats(t *testing.T) {
+	ctx := context.Background()
+	db, err := indexstore.Open(ctx, indexstore.Config{Path: ":memory:"})
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+	require.NoError(t, indexstore.Migrate(ctx, db))
+	indexSet, _, err := indexstore.FindOrCreateIndexSet(ctx, db, indexstore.IndexSetParams{
+		BaseURI:  "s3://bucket/data/",
+		Provider: "s3",
+		BuildParams: indexstore.BuildParams{
+			SourceType:    "crawl",
+			SchemaVersion: indexstore.SchemaVersion,
+		},
+	})
+	require.NoError(t, err)
+	run, err := indexstore.CreateIndexRun(ctx, db, indexSet.IndexSetID, "crawl")
+	require.NoError(t, err)
+	require.NoError(t, indexstore.UpdateIndexRunStatus(ctx, db, run.RunID, indexstore.RunStatusSuccess, nil))
+	now := time.Now().UTC()
+	require.NoError(t, indexstore.BatchUpsertObjects(ctx, db, []indexstore.ObjectRow{
+		{IndexSetID: indexSet.IndexSetID, RelKey: "a", SizeBytes: 100, LastSeenRunID: run.RunID, LastSeenAt: now},
+		{IndexSetID: indexSet.IndexSetID, RelKey: "b", SizeBytes: 100, LastSeenRunID: run.RunID, LastSeenAt: now},
+	}))
+
+	meta := indexreader.Meta{Format: indexreader.FormatSQLiteV1, IndexSetID: indexSet.IndexSetID, BaseURI: "s3://bucket/data/", Provider: "s3"}
+	h := &IndexesHandler{indexes: &fakeIndexResolver{
+		reader: &fakeIndexReader{meta: meta, db: db},
+		listed: []indexreader.ListedIndex{{Meta: meta, IdentityStatus: indexreader.IdentityStatusOK}},
+	}}
+
+	rec := serveIndexesRequest(h, http.MethodGet, "/api/v1/indexes", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list indexListEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Equal(t, 1, list.Total)
+	require.Equal(t, indexSet.IndexSetID, list.Indexes[0].IndexSetID)
+	require.Equal(t, string(indexreader.FormatSQLiteV1), list.Indexes[0].Format)
+
+	rec = serveIndexesRequest(h, http.MethodGet, "/api/v1/indexes/"+indexAPITestSetID+"/stats", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var stats indexStatsEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+	require.Equal(t, int64(2), stats.Objects.Active)
+	require.Equal(t, int64(200), stats.Objects.TotalSizeBytes)
+	require.NotNil(t, stats.LatestRun)
+	require.Equal(t, run.RunID, stats.LatestRun.RunID)
+	require.Equal(t, string(indexstore.RunStatusSuccess), stats.LatestRun.Status)
+	require.NotNil(t, stats.LatestRun.StartedAt)
+
+	rec = serveIndexesRequest(h, http.MethodGet, "/api/v1/indexes/idx_missing/stats", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	apperrors "github.com/3leaps/gonimbus/internal/errors"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/opcheckpoint"
+)
+
+// DrainedJob reports a job a draining server stopped. ResumeCommand is set
+// when the job's run left a checkpoint that --resume-run can pick up.
+type DrainedJob struct {
+	JobID         string `json:"job_id"`
+	State         string `json:"state"`
+	RunID         string `json:"run_id,omitempty"`
+	ForcedKill    bool   `json:"forced_kill"`
+	ResumeCommand string `json:"resume_command,omitempty"`
+}
+
+// BeginDrain closes job submissions. It waits for submissions already past
+// the check to finish starting, so ActiveJobs afterwards covers every job this
+// handler started.
+func (h *JobsHandler) BeginDrain() {
+	h.drainMu.Lock()
+	defer h.drainMu.Unlock()
+	h.draining = true
+}
+
+// Draining reports whether BeginDrain has closed job submissions.
+func (h *JobsHandler) Draining() bool {
+	h.drainMu.RLock()
+	defer h.drainMu.RUnlock()
+	return h.draining
+}
+
+// errSubmissionsDraining is the 503 every job-creating endpoint returns once a
+// drain has begun.
+func errSubmissionsDraining() error {
+	return apperrors.NewServiceUnavailableError("server is draining; job submissions are closed")
+}
+
+// TrackStarted records a job this server started outside Submit, such as a
+// scheduled run, so a drain covers it.
+func (h *JobsHandler) TrackStarted(jobID string) {
+	h.startedMu.Lock()
+	defer h.startedMu.Unlock()
+	h.started = append(h.started, jobID)
+}
+
+// ActiveJobs returns the jobs this handler started that are still queued,
+// running, or stopping. Jobs started elsewhere (the CLI, another server) on
+// the same jobs root are not this server's to drain, and jobs still waiting
+// for queue admission have no process; they stay queued for the next runner.
+func (h *JobsHandler) ActiveJobs() ([]jobregistry.JobRecord, error) {
+	h.startedMu.Lock()
+	ids := append([]string(nil), h.started...)
+	h.startedMu.Unlock()
+
+	var active []jobregistry.JobRecord
+	for _, id := range ids {
+		rec, err := h.store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if rec.AwaitingAdmission() {
+			continue
+		}
+		switch rec.State {
+		case jobregistry.JobStateQueued, jobregistry.JobStateRunning, jobregistry.JobStateStopping:
+			active = append(active, *rec)
+		}
+	}
+	return active, nil
+}
+
+// CheckpointJob stops a running job with SIGTERM, waiting up to wait before
+// killing it. An interrupted build records a resumable checkpoint where its
+// format supports one; the resume command is reported when the job has a run.
+func (h *JobsHandler) CheckpointJob(jobID string, wait time.Duration) (*DrainedJob, error) {
+	result, err := h.stopper.Stop(jobID, jobregistry.StopOptions{Signal: "term", WaitTimeout: wait})
+	if err != nil {
+		return nil, err
+	}
+	drained := &DrainedJob{JobID: result.JobID, State: result.State, ForcedKill: result.ForcedKill}
+	rec, err := h.store.Get(jobID)
+	if err != nil {
+		return drained, nil
+	}
+	drained.RunID = rec.RunID
+	if rec.RunID != "" && !result.ForcedKill {
+		if cmd, err := opcheckpoint.ResumeCommand("index-build", rec.RunID); err == nil {
+			drained.ResumeCommand = cmd
+		}
+	}
+	return drained, nil
+}
+
+// IsJobNotRunning reports whether a CheckpointJob error only means the job was
+// not (or no longer) running, such as a job still queued or already finished.
+func IsJobNotRunning(err error) bool {
+	return errors.Is(err, jobregistry.ErrJobNotRunning) || errors.Is(err, jobregistry.ErrJobNoPID)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+func TestJobsHandlerDrainRefusesSubmitsAndCheckpointsOwnJobs(t *testing.T) {
+	manifestPath := writeJobAPITestManifest(t)
+	store := &fakeJobStore{records: map[string]*jobregistry.JobRecord{
+		"job-1":   {JobID: "job-1", State: jobregistry.JobStateRunning, RunID: "run_7"},
+		"cli-job": {JobID: "cli-job", State: jobregistry.JobStateRunning},
+	}}
+	starter := &fakeJobStarter{job: &jobregistry.JobRecord{JobID: "job-1", State: jobregistry.JobStateRunning, CreatedAt: time.Now().UTC()}}
+	stopper := &fakeJobStopper{result: &jobregistry.StopResult{JobID: "job-1", Signal: "term", State: string(jobregistry.JobStateStopped)}}
+	h := newJobsHandlerForTest(store, starter, stopper)
+	body := fmt.Sprintf(`{"type":"index.build","manifest_path":%q}`, manifestPath)
+
+	require.Equal(t, http.StatusAccepted, serveJobsRequest(h, http.MethodPost, "/api/v1/jobs", body).Code)
+
+	h.BeginDrain()
+	starter.manifestPath = ""
+	rec := serveJobsRequest(h, http.MethodPost, "/api/v1/jobs", body)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Empty(t, starter.manifestPath, "a draining server starts nothing")
+	require.Equal(t, http.StatusOK, serveJobsRequest(h, http.MethodGet, "/api/v1/jobs/job-1", "").Code)
+
+	active, err := h.ActiveJobs()
+	require.NoError(t, err)
+	require.Len(t, active, 1, "jobs started elsewhere are not this server's to drain")
+	require.Equal(t, "job-1", active[0].JobID)
+
+	drained, err := h.CheckpointJob("job-1", time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "term", stopper.opts.Signal)
+	require.Equal(t, time.Second, stopper.opts.WaitTimeout)
+	require.Equal(t, &DrainedJob{JobID: "job-1", State: "stopped", RunID: "run_7", ResumeCommand: "gonimbus index build --resume-run run_7"}, drained)
+
+	store.records["job-1"].State = jobregistry.JobStateStopped
+	active, err = h.ActiveJobs()
+	require.NoError(t, err)
+	require.Empty(t, active)
+
+	stopper.err = jobregistry.ErrJobNotRunning
+	_, err = h.CheckpointJob("job-1", time.Second)
+	require.True(t, IsJobNotRunning(err))
+}
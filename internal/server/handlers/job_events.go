@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/3leaps/gonimbus/internal/errors"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/output"
+)
+
+// jobStreamPollInterval is how often a following stream checks its file for
+// new lines and the job for a terminal state. It matches `index jobs logs
+// --follow`.
+var jobStreamPollInterval = 250 * time.Millisecond
+
+// jobStreamKeepAlive is the idle gap after which a following stream sends an
+// SSE comment, so proxies do not reap a quiet connection.
+var jobStreamKeepAlive = 15 * time.Second
+
+// maxJobLogTail caps /logs?tail=N. seekJobStream keeps one offset per tailed
+// line, so the cap bounds what a single request can make the server allocate.
+const maxJobLogTail = 10000
+
+type jobFileReader interface {
+	OpenEventsRead(string) (*os.File, error)
+	OpenLogRead(string, string) (*os.File, error)
+}
+
+// jobEventNames maps the record types streamed from events.jsonl to SSE event
+// names. Other record types are skipped.
+var jobEventNames = map[string]string{
+	output.TypeProgress: "progress",
+	output.TypeError:    "error",
+	output.TypeSummary:  "summary",
+}
+
+type jobLogLine struct {
+	JobID  string `json:"job_id"`
+	Stream string `json:"stream"`
+	Line   string `json:"line"`
+}
+
+type jobStreamEnd struct {
+	JobID string               `json:"job_id"`
+	State jobregistry.JobState `json:"state"`
+}
+
+// Events streams a job's progress, error, and summary records as Server-Sent
+// Events until the job reaches a terminal state. Each event's data is the
+// record line exactly as the job wrote it (the output.Record envelope), and
+// its id is the byte offset just past that line, so a client reconnecting with
+// Last-Event-ID resumes without losing or repeating records. The stream ends
+// with an "end" event carrying the job's terminal state.
+func (h *JobsHandler) Events(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.streamJob(w, r)
+	if !ok {
+		return
+	}
+	offset, err := parseLastEventID(r)
+	if err != nil {
+		respondWithError(w, r, err)
+		return
+	}
+	h.streamJobFile(w, r, jobStream{
+		job:    job,
+		offset: offset,
+		follow: true,
+		open:   func() (*os.File, error) { return h.files.OpenEventsRead(job.JobID) },
+		event: func(line []byte) (string, []byte) {
+			var rec struct {
+				Type string `json:"type"`
+			}
+			if json.Unmarshal(line, &rec) != nil {
+				return "", nil
+			}
+			return jobEventNames[rec.Type], line
+		},
+	})
+}
+
+// Logs streams a job's stdout or stderr log as Server-Sent Events, one "log"
+// event per line. tail=N (at most maxJobLogTail) starts at the last N lines;
+// follow=true keeps the stream open until the job reaches a terminal state.
+// Event ids are byte offsets within the selected stream, and Last-Event-ID
+// takes precedence over tail when a client reconnects.
+func (h *JobsHandler) Logs(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.streamJob(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	stream := strings.ToLower(strings.TrimSpace(query.Get("stream")))
+	if stream == "" {
+		stream = "stdout"
+	}
+	if stream != "stdout" && stream != "stderr" {
+		respondWithError(w, r, apperrors.NewInvalidInputError("stream must be stdout or stderr"))
+		return
+	}
+	tailN := 0
+	if raw := strings.TrimSpace(query.Get("tail")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			respondWithError(w, r, apperrors.NewInvalidInputError("tail must be a non-negative integer"))
+			return
+		}
+		if n > maxJobLogTail {
+			respondWithError(w, r, apperrors.NewInvalidInputError(fmt.Sprintf("tail must be at most %d", maxJobLogTail)))
+			return
+		}
+		tailN = n
+	}
+	follow := false
+	if raw := strings.TrimSpace(query.Get("follow")); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			respondWithError(w, r, apperrors.NewInvalidInputError("follow must be true or false"))
+			return
+		}
+		follow = v
+	}
+	offset, err := parseLastEventID(r)
+	if err != nil {
+		respondWithError(w, r, err)
+		return
+	}
+	if offset > 0 {
+		tailN = 0
+	}
+
+	h.streamJobFile(w, r, jobStream{
+		job:    job,
+		offset: offset,
+		tail:   tailN,
+		follow: follow,
+		open:   func() (*os.File, error) { return h.files.OpenLogRead(job.JobID, stream+".log") },
+		event: func(line []byte) (string, []byte) {
+			data, err := json.Marshal(jobLogLine{JobID: job.JobID, Stream: stream, Line: string(line)})
+			if err != nil {
+				return "", nil
+			}
+			return "log", data
+		},
+	})
+}
+
+func (h *JobsHandler) streamJob(w http.ResponseWriter, r *http.Request) (*jobregistry.JobRecord, bool) {
+	if h.files == nil {
+		respondWithError(w, r, apperrors.NewInternalError("job streams are not configured"))
+		return nil, false
+	}
+	jobID := strings.TrimSpace(chi.URLParam(r, "job_id"))
+	if jobID == "" {
+		respondWithError(w, r, apperrors.NewInvalidInputError("job_id is required"))
+		return nil, false
+	}
+	job, err := h.store.Get(jobID)
+	if err != nil {
+		respondWithError(w, r, mapJobGetError(r, err))
+		return nil, false
+	}
+	return job, true
+}
+
+func parseLastEventID(r *http.Request) (int64, error) {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		return 0, nil
+	}
+	offset, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, apperrors.NewInvalidInputError("Last-Event-ID must be an event id from this stream")
+	}
+	return offset, nil
+}
+
+// jobStream describes one job file streamed as SSE. event maps a complete line
+// to an event name and data; an empty name skips the line but still advances
+// the resume offset past it.
+type jobStream struct {
+	job    *jobregistry.JobRecord
+	offset int64
+	tail   int
+	follow bool
+	open   func() (*os.File, error)
+	event  func(line []byte) (string, []byte)
+}
+
+func (h *JobsHandler) streamJobFile(w http.ResponseWriter, r *http.Request, s jobStream) {
+	f, err := s.open()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "open job stream"))
+		return
+	}
+	if f != nil {
+		defer func() { _ = f.Close() }()
+		start, err := seekJobStream(f, s.offset, s.tail)
+		if err != nil {
+			respondWithError(w, r, err)
+			return
+		}
+		s.offset = start
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's write timeout; clear it for this response.
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	var (
+		reader   *bufio.Reader
+		pending  []byte
+		lastSend = time.Now()
+	)
+	if f != nil {
+		reader = bufio.NewReader(f)
+	}
+	emit := func(line []byte) error {
+		s.offset += int64(len(line))
+		line = bytes.TrimRight(line, "\r\n")
+		name, data := s.event(line)
+		if name == "" {
+			return nil
+		}
+		lastSend = time.Now()
+		return writeSSE(w, strconv.FormatInt(s.offset, 10), name, data)
+	}
+
+	ticker := time.NewTicker(jobStreamPollInterval)
+	defer ticker.Stop()
+	for {
+		// Read the state before draining: a job writes its last records before
+		// it records a terminal state, so everything is on disk once we see one.
+		job := s.job
+		if latest, err := h.store.Get(s.job.JobID); err == nil {
+			job = latest
+		}
+		terminal := jobStreamTerminal(job.State)
+
+		if reader == nil {
+			// The file appears once a queued job starts; a resume offset from
+			// an earlier stream that no longer fits restarts from the top.
+			if late, err := s.open(); err == nil {
+				defer func() { _ = late.Close() }()
+				if st, statErr := late.Stat(); statErr == nil && s.offset > st.Size() {
+					s.offset = 0
+				}
+				if _, err := late.Seek(s.offset, io.SeekStart); err != nil {
+					return
+				}
+				reader = bufio.NewReader(late)
+			}
+		}
+		if reader != nil {
+			for {
+				chunk, err := reader.ReadBytes('\n')
+				pending = append(pending, chunk...)
+				if err != nil {
+					break
+				}
+				if emitErr := emit(pending); emitErr != nil {
+					return
+				}
+				pending = pending[:0]
+			}
+			if terminal && len(pending) > 0 {
+				if emitErr := emit(pending); emitErr != nil {
+					return
+				}
+				pending = pending[:0]
+			}
+		}
+		if !s.follow {
+			_ = rc.Flush()
+			return
+		}
+		if terminal {
+			data, _ := json.Marshal(jobStreamEnd{JobID: job.JobID, State: job.State})
+			_ = writeSSE(w, strconv.FormatInt(s.offset, 10), "end", data)
+			_ = rc.Flush()
+			return
+		}
+		if time.Since(lastSend) >= jobStreamKeepAlive {
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			lastSend = time.Now()
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.streamsClosed():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseStreams ends every following stream, now and later, so a graceful
+// shutdown does not wait on clients that would otherwise stay connected until
+// their job ends. Clients resume elsewhere with Last-Event-ID.
+func (h *JobsHandler) CloseStreams() {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+	if h.streamsDone == nil {
+		h.streamsDone = make(chan struct{})
+	}
+	select {
+	case <-h.streamsDone:
+	default:
+		close(h.streamsDone)
+	}
+}
+
+func (h *JobsHandler) streamsClosed() <-chan struct{} {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+	if h.streamsDone == nil {
+		h.streamsDone = make(chan struct{})
+	}
+	return h.streamsDone
+}
+
+// seekJobStream positions f at offset, or at the start of the last tail lines
+// when offset is zero and tail is set, and returns the resulting offset.
+func seekJobStream(f *os.File, offset int64, tail int) (int64, error) {
+	if offset > 0 {
+		st, err := f.Stat()
+		if err != nil {
+			return 0, apperrors.NewInternalError("stat job stream")
+		}
+		if offset > st.Size() {
+			return 0, apperrors.NewInvalidInputError("Last-Event-ID is past the end of the stream")
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return 0, apperrors.NewInternalError("seek job stream")
+		}
+		return offset, nil
+	}
+	if tail <= 0 {
+		return 0, nil
+	}
+
+	// starts is a ring of the last tail line offsets; lines counts every line,
+	// so the oldest kept offset is at lines%tail once the ring has wrapped.
+	starts := make([]int64, tail)
+	reader := bufio.NewReader(f)
+	var pos, lines int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			starts[lines%int64(tail)] = pos
+			lines++
+			pos += int64(len(line))
+		}
+		if err != nil {
+			break
+		}
+	}
+	start := int64(0)
+	if lines > int64(tail) {
+		start = starts[lines%int64(tail)]
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return 0, apperrors.NewInternalError("seek job stream")
+	}
+	return start, nil
+}
+
+func jobStreamTerminal(state jobregistry.JobState) bool {
+	switch state {
+	case jobregistry.JobStateQueued, jobregistry.JobStateRunning, jobregistry.JobStateStopping:
+		return false
+	default:
+		return true
+	}
+}
+
+func writeSSE(w io.Writer, id, event string, data []byte) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
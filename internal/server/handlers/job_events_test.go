@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/output"
+)
+
+const streamTestJobID = "44444444-4444-4444-8444-444444444444"
+
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+func parseSSE(t *testing.T, body string) []sseEvent {
+	t.Helper()
+	var (
+		events []sseEvent
+		cur    sseEvent
+	)
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if cur.Event != "" {
+				events = append(events, cur)
+			}
+			cur = sseEvent{}
+		case strings.HasPrefix(line, "id: "):
+			cur.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			cur.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			cur.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func newStreamTestStore(t *testing.T, state jobregistry.JobState) *jobregistry.Store {
+	t.Helper()
+	store := jobregistry.NewStore(filepath.Join(t.TempDir(), "jobs"))
+	require.NoError(t, store.Write(&jobregistry.JobRecord{
+		JobID:     streamTestJobID,
+		Type:      jobregistry.JobTypeIndexBuild,
+		State:     state,
+		CreatedAt: time.Now().UTC(),
+	}))
+	return store
+}
+
+func appendJobEvents(t *testing.T, store *jobregistry.Store, lines ...string) {
+	t.Helper()
+	f, err := store.OpenEvents(streamTestJobID)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	for _, line := range lines {
+		_, err := f.WriteString(line + "\n")
+		require.NoError(t, err)
+	}
+}
+
+func serveJobStream(h *JobsHandler, ctx context.Context, target string, header map[string]string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Get("/api/v1/jobs/{job_id}/events", h.Events)
+	r.Get("/api/v1/jobs/{job_id}/logs", h.Logs)
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestJobsHandlerEventsStreamsRecordsAndResumes(t *testing.T) {
+	store := newStreamTestStore(t, jobregistry.JobStateSuccess)
+	appendJobEvents(t, store,
+		`{"type":"`+output.TypeProgress+`","job_id":"j","data":{"objects_found":1}}`,
+		`{"type":"`+output.TypeObject+`","job_id":"j","data":{"key":"a"}}`,
+		`{"type":"`+output.TypeProgress+`","job_id":"j","data":{"objects_found":2}}`,
+		`{"type":"`+output.TypeSummary+`","job_id":"j","data":{"objects_found":2}}`,
+	)
+	h := &JobsHandler{store: store, files: store}
+
+	rec := serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/events", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	events := parseSSE(t, rec.Body.String())
+	require.Len(t, events, 4)
+	require.Equal(t, []string{"progress", "progress", "summary", "end"},
+		[]string{events[0].Event, events[1].Event, events[2].Event, events[3].Event})
+
+	var record output.Record
+	require.NoError(t, json.Unmarshal([]byte(events[1].Data), &record))
+	require.Equal(t, output.TypeProgress, record.Type)
+	require.JSONEq(t, `{"objects_found":2}`, string(record.Data))
+
+	var end jobStreamEnd
+	require.NoError(t, json.Unmarshal([]byte(events[3].Data), &end))
+	require.Equal(t, jobregistry.JobStateSuccess, end.State)
+
+	// Reconnecting after the first progress event replays only what followed.
+	rec = serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/events",
+		map[string]string{"Last-Event-ID": events[0].ID})
+	resumed := parseSSE(t, rec.Body.String())
+	require.Len(t, resumed, 3)
+	require.Equal(t, events[1], resumed[0])
+	require.Equal(t, events[2], resumed[1])
+}
+
+func TestJobsHandlerEventsFollowsRunningJobUntilTerminal(t *testing.T) {
+	previous := jobStreamPollInterval
+	jobStreamPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { jobStreamPollInterval = previous })
+
+	store := newStreamTestStore(t, jobregistry.JobStateRunning)
+	h := &JobsHandler{store: store, files: store}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		f, err := store.OpenEvents(streamTestJobID)
+		if err != nil {
+			return
+		}
+		_, _ = f.WriteString(`{"type":"` + output.TypeError + `","job_id":"j","data":{"code":"INTERNAL"}}` + "\n")
+		_ = f.Close()
+		rec, err := store.Get(streamTestJobID)
+		if err == nil {
+			rec.State = jobregistry.JobStateFailed
+			_ = store.Write(rec)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rec := serveJobStream(h, ctx, "/api/v1/jobs/"+streamTestJobID+"/events", nil)
+	require.NoError(t, ctx.Err(), "stream should end when the job fails")
+	events := parseSSE(t, rec.Body.String())
+	require.Len(t, events, 2)
+	require.Equal(t, "error", events[0].Event)
+	require.Equal(t, "end", events[1].Event)
+	require.Contains(t, events[1].Data, `"state":"failed"`)
+}
+
+func TestJobsHandlerLogsTailAndValidation(t *testing.T) {
+	store := newStreamTestStore(t, jobregistry.JobStateRunning)
+	f, err := store.OpenLog(streamTestJobID, "stderr.log", true)
+	require.NoError(t, err)
+	_, err = f.WriteString("one\ntwo\nthree\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	h := &JobsHandler{store: store, files: store}
+
+	rec := serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/logs?stream=stderr&tail=2", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	events := parseSSE(t, rec.Body.String())
+	require.Len(t, events, 2, "no end event without follow")
+	var line jobLogLine
+	require.NoError(t, json.Unmarshal([]byte(events[0].Data), &line))
+	require.Equal(t, jobLogLine{JobID: streamTestJobID, Stream: "stderr", Line: "two"}, line)
+	require.Equal(t, "8", events[0].ID)
+	require.Equal(t, "14", events[1].ID)
+
+	// A tail longer than the log starts at the beginning.
+	rec = serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/logs?stream=stderr&tail=5", nil)
+	require.Len(t, parseSSE(t, rec.Body.String()), 3)
+
+	// Last-Event-ID wins over tail.
+	rec = serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/logs?stream=stderr&tail=1",
+		map[string]string{"Last-Event-ID": "4"})
+	events = parseSSE(t, rec.Body.String())
+	require.Len(t, events, 2)
+	require.Contains(t, events[0].Data, `"line":"two"`)
+
+	for _, target := range []string{
+		"/api/v1/jobs/" + streamTestJobID + "/logs?stream=both",
+		"/api/v1/jobs/" + streamTestJobID + "/logs?tail=-1",
+		"/api/v1/jobs/" + streamTestJobID + "/logs?tail=999999999999",
+		"/api/v1/jobs/" + streamTestJobID + "/logs?follow=maybe",
+	} {
+		rec := serveJobStream(h, context.Background(), target, nil)
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+	rec = serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/logs?stream=stderr",
+		map[string]string{"Last-Event-ID": "999"})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = serveJobStream(h, context.Background(), "/api/v1/jobs/55555555-5555-4555-8555-555555555555/events", nil)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestJobsHandlerEventsEndsForFinishedJobWithoutEventsFile(t *testing.T) {
+	store := newStreamTestStore(t, jobregistry.JobStateStopped)
+	h := &JobsHandler{store: store, files: store}
+	_, err := os.Stat(filepath.Join(store.JobDir(streamTestJobID), jobregistry.EventsFileName))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	rec := serveJobStream(h, context.Background(), "/api/v1/jobs/"+streamTestJobID+"/events", nil)
+	events := parseSSE(t, rec.Body.String())
+	require.Len(t, events, 1)
+	require.Equal(t, "end", events[0].Event)
+}
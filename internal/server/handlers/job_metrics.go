@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+
+	apperrors "github.com/3leaps/gonimbus/internal/errors"
+	"github.com/3leaps/gonimbus/internal/metrics"
+	"github.com/3leaps/gonimbus/internal/observability"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+// Metrics serves the domain metrics of managed jobs in the Prometheus text
+// format. Each managed child writes its own textfile; this merges them oldest
+// job first, so counters are summed across jobs and gauges report the most
+// recent job's value for each provider/bucket/job-type series.
+//
+// Counters stay monotonic when job records are pruned: the handler keeps each
+// job's last counters and, once the job is gone, carries them in a pruned
+// total for the rest of the server's life. A restart starts the totals over,
+// which Prometheus reads as an ordinary counter reset. A pruned job's gauges
+// are dropped.
+func (h *JobsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if h.metrics == nil {
+		respondWithError(w, r, apperrors.NewInternalError("job metrics are not configured"))
+		return
+	}
+	jobs, err := h.store.List()
+	if err != nil {
+		respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "list jobs"))
+		return
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+	})
+
+	h.metricsMu.Lock()
+	if h.metricsByJob == nil {
+		h.metricsByJob = map[string]*metrics.Collector{}
+		h.metricsPruned = metrics.NewCollector()
+	}
+	collector := metrics.NewCollector()
+	collector.MergeCounters(h.metricsPruned)
+	present := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		present[job.JobID] = true
+		if jc := readJobMetrics(h.metrics, job); jc != nil {
+			h.metricsByJob[job.JobID] = jc
+		}
+		collector.MergeCollector(h.metricsByJob[job.JobID])
+	}
+	for id, jc := range h.metricsByJob {
+		if present[id] {
+			continue
+		}
+		h.metricsPruned.MergeCounters(jc)
+		collector.MergeCounters(jc)
+		delete(h.metricsByJob, id)
+	}
+	h.metricsMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	if err := collector.WriteText(w); err != nil && observability.ServerLogger != nil {
+		observability.ServerLogger.Warn("Failed to write job metrics response", zap.Error(err))
+	}
+}
+
+// readJobMetrics reads a job's textfile, or returns nil when it has none. A
+// job whose textfile cannot be read keeps the counters last read for it.
+func readJobMetrics(reader jobMetricsReader, job jobregistry.JobRecord) *metrics.Collector {
+	f, err := reader.OpenMetricsRead(job.JobID)
+	if err != nil {
+		// Jobs that never ran, or ran before metrics were exported, have no
+		// textfile. That is not an error for the scrape.
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+	jc := metrics.NewCollector()
+	if err := jc.Merge(f); err != nil {
+		if observability.ServerLogger != nil {
+			observability.ServerLogger.Warn("Failed to read job metrics",
+				zap.String("job_id", job.JobID),
+				zap.Error(err))
+		}
+		return nil
+	}
+	return jc
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/metrics"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+func TestJobsHandlerMetricsMergesJobTextfiles(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "jobs")
+	store := jobregistry.NewStore(root)
+	base := time.Now().UTC().Add(-time.Hour)
+	ids := []string{
+		"11111111-1111-4111-8111-111111111111",
+		"22222222-2222-4222-8222-222222222222",
+		"33333333-3333-4333-8333-333333333333",
+	}
+	for i, id := range ids {
+		require.NoError(t, store.Write(&jobregistry.JobRecord{
+			JobID:     id,
+			Type:      jobregistry.JobTypeIndexBuild,
+			State:     jobregistry.JobStateSuccess,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+		if i == 2 {
+			// The newest job never exported metrics.
+			continue
+		}
+		c := metrics.NewCollector()
+		tags := map[string]string{"job_type": "index_build", "provider": "s3"}
+		c.Counter("index_build_objects_observed_total", float64(10*(i+1)), tags)
+		c.Gauge("index_build_rows_published", float64(100*(i+1)), tags)
+		require.NoError(t, c.WriteTextfile(store.MetricsPath(id)))
+	}
+
+	h := &JobsHandler{store: store, metrics: store}
+	rec := httptest.NewRecorder()
+	h.Metrics(rec, httptest.NewRequest(http.MethodGet, "/metrics/jobs", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	body := rec.Body.String()
+	require.Contains(t, body, `gonimbus_index_build_objects_observed_total{job_type="index_build",provider="s3"} 30`)
+	require.Contains(t, body, `gonimbus_index_build_rows_published{job_type="index_build",provider="s3"} 200`)
+}
+
+func TestJobsHandlerMetricsCountersSurvivePrunedJobs(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "jobs")
+	store := jobregistry.NewStore(root)
+	base := time.Now().UTC().Add(-time.Hour)
+	ids := []string{
+		"11111111-1111-4111-8111-111111111111",
+		"22222222-2222-4222-8222-222222222222",
+	}
+	tags := map[string]string{"job_type": "index_build", "provider": "s3"}
+	for i, id := range ids {
+		require.NoError(t, store.Write(&jobregistry.JobRecord{
+			JobID:     id,
+			Type:      jobregistry.JobTypeIndexBuild,
+			State:     jobregistry.JobStateSuccess,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+		c := metrics.NewCollector()
+		c.Counter("index_build_objects_observed_total", float64(10*(i+1)), tags)
+		c.Gauge("index_build_rows_published", float64(100*(i+1)), tags)
+		require.NoError(t, c.WriteTextfile(store.MetricsPath(id)))
+	}
+
+	h := &JobsHandler{store: store, metrics: store}
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		h.Metrics(rec, httptest.NewRequest(http.MethodGet, "/metrics/jobs", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+	require.Contains(t, scrape(), `gonimbus_index_build_objects_observed_total{job_type="index_build",provider="s3"} 30`)
+
+	// Prune the newest job, as index jobs gc does.
+	require.NoError(t, os.RemoveAll(store.JobDir(ids[1])))
+	for range 2 {
+		body := scrape()
+		require.Contains(t, body, `gonimbus_index_build_objects_observed_total{job_type="index_build",provider="s3"} 30`)
+		require.Contains(t, body, `gonimbus_index_build_rows_published{job_type="index_build",provider="s3"} 100`)
+	}
+}
@@ -9,11 +9,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	apperrors "github.com/3leaps/gonimbus/internal/errors"
+	"github.com/3leaps/gonimbus/internal/metrics"
+	"github.com/3leaps/gonimbus/internal/tracing"
 	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/3leaps/gonimbus/pkg/match"
 )
 
 type jobStore interface {
@@ -29,19 +35,46 @@ type jobStopper interface {
 	Stop(string, jobregistry.StopOptions) (*jobregistry.StopResult, error)
 }
 
+type jobMetricsReader interface {
+	OpenMetricsRead(string) (*os.File, error)
+}
+
 type JobsHandler struct {
 	store      jobStore
 	starter    jobStarter
 	stopper    jobStopper
+	metrics    jobMetricsReader
+	files      jobFileReader
 	invocation *jobregistry.IndexBuildInvocation
+
+	// drainMu is held shared by submissions and exclusively by BeginDrain, so
+	// once a drain begins, started lists every job this handler will start.
+	drainMu   sync.RWMutex
+	draining  bool
+	startedMu sync.Mutex
+	started   []string
+
+	// streamsDone is closed by CloseStreams; following streams end when it is.
+	streamsMu   sync.Mutex
+	streamsDone chan struct{}
+
+	// metricsByJob is each listed job's last read metrics; metricsPruned
+	// carries the counters of jobs whose records have since been pruned.
+	metricsMu     sync.Mutex
+	metricsByJob  map[string]*metrics.Collector
+	metricsPruned *metrics.Collector
 }
 
-func NewJobsHandler(root string, invocation *jobregistry.IndexBuildInvocation) *JobsHandler {
-	store := jobregistry.NewStore(root)
+// NewJobsHandler returns a handler that starts jobs through executor, under
+// its queue limits.
+func NewJobsHandler(executor *jobregistry.Executor, invocation *jobregistry.IndexBuildInvocation) *JobsHandler {
+	store := executor.Store()
 	return &JobsHandler{
 		store:      store,
-		starter:    jobregistry.NewExecutor(root),
+		starter:    executor,
 		stopper:    store,
+		metrics:    store,
+		files:      store,
 		invocation: invocation,
 	}
 }
@@ -66,6 +99,10 @@ type submitJobRequest struct {
 	Since        string            `json:"since,omitempty"`
 	Dedupe       bool              `json:"dedupe,omitempty"`
 	Metadata     map[string]string `json:"metadata,omitempty"`
+	// Priority is the queue priority class: high, normal (default), or low.
+	Priority string `json:"priority,omitempty"`
+	// Memory is the memory the job reserves from the queue budget ("4GiB").
+	Memory string `json:"reserve_memory,omitempty"`
 }
 
 type jobEnvelope struct {
@@ -73,9 +110,16 @@ type jobEnvelope struct {
 }
 
 type jobListEnvelope struct {
-	Jobs    []jobregistry.JobRecord `json:"jobs"`
-	Total   int                     `json:"total"`
-	HasMore bool                    `json:"has_more"`
+	Jobs    []jobListItem `json:"jobs"`
+	Total   int           `json:"total"`
+	HasMore bool          `json:"has_more"`
+}
+
+// jobListItem is a listed job plus its queue position while it waits for
+// admission.
+type jobListItem struct {
+	jobregistry.JobRecord
+	QueuePosition int `json:"queue_position,omitempty"`
 }
 
 type cancelJobEnvelope struct {
@@ -86,6 +130,13 @@ type cancelJobEnvelope struct {
 }
 
 func (h *JobsHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	h.drainMu.RLock()
+	defer h.drainMu.RUnlock()
+	if h.draining {
+		respondWithError(w, r, errSubmissionsDraining())
+		return
+	}
+
 	var req submitJobRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondWithError(w, r, apperrors.WrapInvalidInput(r.Context(), err, "invalid job request JSON"))
@@ -96,6 +147,11 @@ func (h *JobsHandler) Submit(w http.ResponseWriter, r *http.Request) {
 		respondWithError(w, r, err)
 		return
 	}
+	priority, memoryBytes, err := submitJobQueueOptions(r.Context(), req)
+	if err != nil {
+		respondWithError(w, r, err)
+		return
+	}
 
 	if h.invocation == nil {
 		respondWithError(w, r, apperrors.NewInternalError("job runner invocation is not configured"))
@@ -104,16 +160,29 @@ func (h *JobsHandler) Submit(w http.ResponseWriter, r *http.Request) {
 	invocation := *h.invocation
 	invocation.Since = strings.TrimSpace(req.Since)
 	invocation.Name = strings.TrimSpace(req.Name)
+	// The submit span joins the caller's trace (traceparent header) and parents
+	// the background job, which receives it through the child environment.
+	ctx, span := tracing.Tracer().Start(tracing.ContextFromHTTP(r.Context(), r.Header), "jobs.submit",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("gonimbus.job.type", jobregistry.JobTypeIndexBuild)),
+	)
 	job, err := h.starter.StartIndexBuildBackground(manifestPath, strings.TrimSpace(req.Name), jobregistry.BackgroundOptions{
-		Dedupe:     req.Dedupe,
-		Since:      strings.TrimSpace(req.Since),
-		JobType:    jobregistry.JobTypeIndexBuild,
-		Invocation: &invocation,
+		Dedupe:      req.Dedupe,
+		Since:       strings.TrimSpace(req.Since),
+		JobType:     jobregistry.JobTypeIndexBuild,
+		Invocation:  &invocation,
+		TraceParent: tracing.TraceParent(ctx),
+		Priority:    priority,
+		MemoryBytes: memoryBytes,
 	})
 	if err != nil {
+		tracing.EndSpan(span, err)
 		respondWithError(w, r, mapJobStartError(r, err))
 		return
 	}
+	span.SetAttributes(attribute.String("gonimbus.job.id", job.JobID))
+	span.End()
+	h.TrackStarted(job.JobID)
 	writeJSON(w, http.StatusAccepted, jobEnvelope{Job: normalizeJobRecord(*job)})
 }
 
@@ -128,7 +197,8 @@ func (h *JobsHandler) List(w http.ResponseWriter, r *http.Request) {
 	typeFilter := strings.TrimSpace(r.URL.Query().Get("type"))
 	limit := parseJobListLimit(r.URL.Query().Get("limit"))
 
-	filtered := make([]jobregistry.JobRecord, 0, len(jobs))
+	positions := jobregistry.QueuePositions(jobs)
+	filtered := make([]jobListItem, 0, len(jobs))
 	for _, job := range jobs {
 		job = normalizeJobRecord(job)
 		if statusFilter != "" && string(job.State) != statusFilter {
@@ -137,7 +207,7 @@ func (h *JobsHandler) List(w http.ResponseWriter, r *http.Request) {
 		if typeFilter != "" && job.Type != typeFilter {
 			continue
 		}
-		filtered = append(filtered, job)
+		filtered = append(filtered, jobListItem{JobRecord: job, QueuePosition: positions[job.JobID]})
 	}
 
 	total := len(filtered)
@@ -218,6 +288,23 @@ func validateSubmitJobRequest(ctx context.Context, req submitJobRequest) (string
 	return cleanPath, metadata, nil
 }
 
+// submitJobQueueOptions validates the queue priority and memory reservation
+// of a job request.
+func submitJobQueueOptions(ctx context.Context, req submitJobRequest) (string, int64, error) {
+	priority, err := jobregistry.NormalizeQueuePriority(req.Priority)
+	if err != nil {
+		return "", 0, apperrors.WrapValidationError(ctx, err, "invalid priority")
+	}
+	var memoryBytes int64
+	if raw := strings.TrimSpace(req.Memory); raw != "" {
+		memoryBytes, err = match.ParseSize(raw)
+		if err != nil {
+			return "", 0, apperrors.WrapValidationError(ctx, err, "invalid reserve_memory")
+		}
+	}
+	return priority, memoryBytes, nil
+}
+
 func validateJobMetadata(metadata map[string]string) (map[string]string, error) {
 	if len(metadata) == 0 {
 		return nil, nil
@@ -48,6 +48,19 @@ func TestJobsHandlerSubmitStartsIndexBuild(t *testing.T) {
 	require.Equal(t, "job-1", body.Job.JobID)
 }
 
+func TestJobsHandlerSubmitForwardsQueueOptions(t *testing.T) {
+	manifestPath := writeJobAPITestManifest(t)
+	starter := &fakeJobStarter{job: &jobregistry.JobRecord{JobID: "job-1", State: jobregistry.JobStateQueued}}
+	h := newJobsHandlerForTest(&fakeJobStore{}, starter, &fakeJobStopper{})
+
+	reqBody := fmt.Sprintf(`{"type":"index.build","manifest_path":%q,"priority":"high","reserve_memory":"4GiB"}`, manifestPath)
+	rec := serveJobsRequest(h, http.MethodPost, "/api/v1/jobs", reqBody)
+
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	require.Equal(t, jobregistry.QueuePriorityHigh, starter.opts.Priority)
+	require.Equal(t, int64(4)<<30, starter.opts.MemoryBytes)
+}
+
 func TestJobsHandlerSubmitRejectsInvalidPayloads(t *testing.T) {
 	manifestPath := writeJobAPITestManifest(t)
 	tests := []struct {
@@ -61,6 +74,8 @@ func TestJobsHandlerSubmitRejectsInvalidPayloads(t *testing.T) {
 		{name: "metadata rejected", body: fmt.Sprintf(`{"type":"index.build","manifest_path":%q,"metadata":{"label":"value"}}`, manifestPath)},
 		{name: "signed material in name", body: fmt.Sprintf(`{"type":"index.build","manifest_path":%q,"name":"https://host/key?X-Amz-Signature=sentinel"}`, manifestPath)},
 		{name: "invalid since", body: fmt.Sprintf(`{"type":"index.build","manifest_path":%q,"since":"https://host/key?X-Amz-Signature=sentinel"}`, manifestPath)},
+		{name: "unknown priority", body: fmt.Sprintf(`{"type":"index.build","manifest_path":%q,"priority":"urgent"}`, manifestPath)},
+		{name: "invalid reserve_memory", body: fmt.Sprintf(`{"type":"index.build","manifest_path":%q,"reserve_memory":"lots"}`, manifestPath)},
 	}
 
 	for _, tt := range tests {
@@ -94,6 +109,25 @@ func TestJobsHandlerListFiltersAndLimits(t *testing.T) {
 	require.Equal(t, "job-1", body.Jobs[0].JobID)
 }
 
+func TestJobsHandlerListReportsQueuePosition(t *testing.T) {
+	enqueued := time.Now().UTC()
+	h := newJobsHandlerForTest(&fakeJobStore{jobs: []jobregistry.JobRecord{
+		{JobID: "job-1", State: jobregistry.JobStateRunning, Queue: &jobregistry.JobQueueEntry{Priority: "normal", AdmittedAt: &enqueued}},
+		{JobID: "job-2", State: jobregistry.JobStateQueued, Queue: &jobregistry.JobQueueEntry{Priority: "low", EnqueuedAt: enqueued}},
+		{JobID: "job-3", State: jobregistry.JobStateQueued, Queue: &jobregistry.JobQueueEntry{Priority: "high", EnqueuedAt: enqueued.Add(time.Second)}},
+	}}, &fakeJobStarter{}, &fakeJobStopper{})
+
+	rec := serveJobsRequest(h, http.MethodGet, "/api/v1/jobs", "")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body jobListEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.Len(t, body.Jobs, 3)
+	require.Zero(t, body.Jobs[0].QueuePosition)
+	require.Equal(t, 2, body.Jobs[1].QueuePosition)
+	require.Equal(t, 1, body.Jobs[2].QueuePosition)
+}
+
 func TestJobsHandlerStatus(t *testing.T) {
 	h := newJobsHandlerForTest(&fakeJobStore{records: map[string]*jobregistry.JobRecord{
 		"job-1": {JobID: "job-1", State: jobregistry.JobStateRunning, CreatedAt: time.Now().UTC()},
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/3leaps/gonimbus/internal/errors"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+type SchedulesHandler struct {
+	store *jobregistry.ScheduleStore
+	now   func() time.Time
+	// jobs, when set, closes schedule creation with job submissions on drain.
+	jobs *JobsHandler
+}
+
+func NewSchedulesHandler(root string, jobs *JobsHandler) *SchedulesHandler {
+	return &SchedulesHandler{store: jobregistry.NewScheduleStore(root), now: time.Now, jobs: jobs}
+}
+
+type createScheduleRequest struct {
+	Name     string           `json:"name,omitempty"`
+	Cron     string           `json:"cron"`
+	Timezone string           `json:"timezone,omitempty"`
+	Job      submitJobRequest `json:"job"`
+	Overlap  string           `json:"overlap,omitempty"`
+	Jitter   string           `json:"jitter,omitempty"`
+	CatchUp  bool             `json:"catch_up,omitempty"`
+}
+
+type scheduleEnvelope struct {
+	Schedule jobregistry.Schedule `json:"schedule"`
+}
+
+type scheduleListEnvelope struct {
+	Schedules []jobregistry.Schedule `json:"schedules"`
+	Total     int                    `json:"total"`
+}
+
+type deleteScheduleEnvelope struct {
+	ScheduleID string `json:"schedule_id"`
+	Deleted    bool   `json:"deleted"`
+}
+
+func (h *SchedulesHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if h.jobs != nil && h.jobs.Draining() {
+		respondWithError(w, r, errSubmissionsDraining())
+		return
+	}
+	var req createScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, apperrors.WrapInvalidInput(r.Context(), err, "invalid schedule request JSON"))
+		return
+	}
+	if req.Job.Dedupe {
+		respondWithError(w, r, apperrors.NewInvalidInputError("job.dedupe is not used by schedules; set overlap instead"))
+		return
+	}
+	manifestPath, _, err := validateSubmitJobRequest(r.Context(), req.Job)
+	if err != nil {
+		respondWithError(w, r, err)
+		return
+	}
+	priority, memoryBytes, err := submitJobQueueOptions(r.Context(), req.Job)
+	if err != nil {
+		respondWithError(w, r, err)
+		return
+	}
+	if err := jobregistry.ValidateManagedJobName(strings.TrimSpace(req.Name)); err != nil {
+		respondWithError(w, r, apperrors.WrapValidationError(r.Context(), err, "invalid name"))
+		return
+	}
+	overlap := strings.TrimSpace(req.Overlap)
+	if overlap == "" {
+		overlap = jobregistry.ScheduleOverlapSkip
+	}
+
+	sched := &jobregistry.Schedule{
+		Name:     strings.TrimSpace(req.Name),
+		Cron:     strings.TrimSpace(req.Cron),
+		Timezone: strings.TrimSpace(req.Timezone),
+		Job: jobregistry.ScheduleJob{
+			Type:         jobregistry.JobTypeIndexBuild,
+			ManifestPath: manifestPath,
+			Name:         strings.TrimSpace(req.Job.Name),
+			Since:        strings.TrimSpace(req.Job.Since),
+			Priority:     priority,
+			MemoryBytes:  memoryBytes,
+		},
+		Overlap: overlap,
+		Jitter:  strings.TrimSpace(req.Jitter),
+		CatchUp: req.CatchUp,
+	}
+	if err := sched.Validate(); err != nil {
+		respondWithError(w, r, apperrors.WrapValidationError(r.Context(), err, "invalid schedule"))
+		return
+	}
+	if err := h.store.Create(sched, h.now()); err != nil {
+		respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "create schedule"))
+		return
+	}
+	writeJSON(w, http.StatusCreated, scheduleEnvelope{Schedule: *sched})
+}
+
+func (h *SchedulesHandler) List(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.store.List()
+	if err != nil {
+		respondWithError(w, r, apperrors.WrapInternal(r.Context(), err, "list schedules"))
+		return
+	}
+	if schedules == nil {
+		schedules = []jobregistry.Schedule{}
+	}
+	writeJSON(w, http.StatusOK, scheduleListEnvelope{Schedules: schedules, Total: len(schedules)})
+}
+
+func (h *SchedulesHandler) Status(w http.ResponseWriter, r *http.Request) {
+	sched, err := h.store.Get(strings.TrimSpace(chi.URLParam(r, "schedule_id")))
+	if err != nil {
+		respondWithError(w, r, mapScheduleError(r, err, "read schedule"))
+		return
+	}
+	writeJSON(w, http.StatusOK, scheduleEnvelope{Schedule: *sched})
+}
+
+// Delete removes a schedule. Jobs it already started are not affected.
+func (h *SchedulesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	scheduleID := strings.TrimSpace(chi.URLParam(r, "schedule_id"))
+	if err := h.store.Delete(scheduleID); err != nil {
+		respondWithError(w, r, mapScheduleError(r, err, "delete schedule"))
+		return
+	}
+	writeJSON(w, http.StatusOK, deleteScheduleEnvelope{ScheduleID: scheduleID, Deleted: true})
+}
+
+func mapScheduleError(r *http.Request, err error, action string) error {
+	if errors.Is(err, jobregistry.ErrScheduleNotFound) {
+		return apperrors.WrapNotFound(r.Context(), err, "schedule not found")
+	}
+	return apperrors.WrapInternal(r.Context(), err, action)
+}
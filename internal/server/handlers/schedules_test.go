@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+func TestSchedulesHandlerCreateListStatusDelete(t *testing.T) {
+	manifestPath := writeJobAPITestManifest(t)
+	h := NewSchedulesHandler(t.TempDir(), nil)
+	h.now = func() time.Time { return time.Date(2026, time.March, 14, 10, 17, 0, 0, time.UTC) }
+
+	body := fmt.Sprintf(`{"name":"hourly-delta","cron":"@hourly","job":{"type":"index.build","manifest_path":%q,"since":"auto"},"overlap":"queue","catch_up":true}`, manifestPath)
+	rec := serveSchedulesRequest(h, http.MethodPost, "/api/v1/schedules", body)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created scheduleEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	sched := created.Schedule
+	require.NotEmpty(t, sched.ScheduleID)
+	require.Equal(t, jobregistry.ScheduleOverlapQueue, sched.Overlap)
+	require.Equal(t, "auto", sched.Job.Since)
+	require.True(t, sched.CatchUp)
+	require.Equal(t, time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC), *sched.NextRunAt)
+
+	rec = serveSchedulesRequest(h, http.MethodGet, "/api/v1/schedules", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list scheduleListEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Equal(t, 1, list.Total)
+
+	rec = serveSchedulesRequest(h, http.MethodGet, "/api/v1/schedules/"+sched.ScheduleID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serveSchedulesRequest(h, http.MethodDelete, "/api/v1/schedules/"+sched.ScheduleID, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serveSchedulesRequest(h, http.MethodGet, "/api/v1/schedules/"+sched.ScheduleID, "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serveSchedulesRequest(h, http.MethodDelete, "/api/v1/schedules/not-a-schedule", "")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSchedulesHandlerDefaultsOverlapToSkip(t *testing.T) {
+	manifestPath := writeJobAPITestManifest(t)
+	h := NewSchedulesHandler(t.TempDir(), nil)
+	rec := serveSchedulesRequest(h, http.MethodPost, "/api/v1/schedules",
+		fmt.Sprintf(`{"cron":"0 2 * * *","job":{"type":"index.build","manifest_path":%q}}`, manifestPath))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created scheduleEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+	require.Equal(t, jobregistry.ScheduleOverlapSkip, created.Schedule.Overlap)
+	require.Equal(t, "UTC", created.Schedule.Timezone)
+}
+
+func TestSchedulesHandlerRejectsInvalidPayloads(t *testing.T) {
+	manifestPath := writeJobAPITestManifest(t)
+	job := fmt.Sprintf(`{"type":"index.build","manifest_path":%q}`, manifestPath)
+	tests := map[string]string{
+		"bad cron":        fmt.Sprintf(`{"cron":"every hour","job":%s}`, job),
+		"bad overlap":     fmt.Sprintf(`{"cron":"@hourly","job":%s,"overlap":"replace"}`, job),
+		"bad jitter":      fmt.Sprintf(`{"cron":"@hourly","job":%s,"jitter":"soon"}`, job),
+		"bad timezone":    fmt.Sprintf(`{"cron":"@hourly","job":%s,"timezone":"Nowhere/Special"}`, job),
+		"unknown type":    fmt.Sprintf(`{"cron":"@hourly","job":{"type":"index.enrich","manifest_path":%q}}`, manifestPath),
+		"relative path":   `{"cron":"@hourly","job":{"type":"index.build","manifest_path":"job.yaml"}}`,
+		"dedupe in job":   fmt.Sprintf(`{"cron":"@hourly","job":{"type":"index.build","manifest_path":%q,"dedupe":true}}`, manifestPath),
+		"invalid request": `{"cron":`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := NewSchedulesHandler(t.TempDir(), nil)
+			rec := serveSchedulesRequest(h, http.MethodPost, "/api/v1/schedules", body)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestSchedulesHandlerCreateRejectedWhileDraining(t *testing.T) {
+	manifestPath := writeJobAPITestManifest(t)
+	jobs := &JobsHandler{}
+	h := NewSchedulesHandler(t.TempDir(), jobs)
+	jobs.BeginDrain()
+
+	rec := serveSchedulesRequest(h, http.MethodPost, "/api/v1/schedules",
+		fmt.Sprintf(`{"cron":"@hourly","job":{"type":"index.build","manifest_path":%q}}`, manifestPath))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "server is draining")
+
+	rec = serveSchedulesRequest(h, http.MethodGet, "/api/v1/schedules", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list scheduleListEnvelope
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Zero(t, list.Total, "no schedule is stored while draining")
+}
+
+func serveSchedulesRequest(h *SchedulesHandler, method, target, body string) *httptest.ResponseRecorder {
+	r := chi.NewRouter()
+	r.Post("/api/v1/schedules", h.Create)
+	r.Get("/api/v1/schedules", h.List)
+	r.Get("/api/v1/schedules/{schedule_id}", h.Status)
+	r.Delete("/api/v1/schedules/{schedule_id}", h.Delete)
+
+	var reader io.Reader
+	if body != "" {
+		reader = bytes.NewBufferString(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
@@ -28,6 +28,12 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Unwrap exposes the underlying writer to http.ResponseController, so streaming
+// handlers can flush and extend write deadlines through the metrics wrapper.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // getEndpointPattern extracts chi route pattern to avoid high-cardinality paths
 func getEndpointPattern(r *http.Request) string {
 	// Try to get chi route pattern
@@ -203,3 +203,17 @@ func TestRequestMetrics_DurationMeasurement(t *testing.T) {
 	assert.Greater(t, collector.CountMetricsByName("http_request_duration_ms"), 0,
 		"expected http_request_duration_ms metric to be emitted")
 }
+
+func TestRequestMetrics_ResponseControllerReachesUnderlyingWriter(t *testing.T) {
+	_ = setupTelemetry(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("data: x\n\n"))
+		require.NoError(t, http.NewResponseController(w).Flush())
+	})
+
+	rec := httptest.NewRecorder()
+	RequestMetrics(handler).ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+
+	assert.True(t, rec.Flushed, "expected flush to reach the recorder through the metrics wrapper")
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/3leaps/gonimbus/internal/observability"
 	"github.com/3leaps/gonimbus/internal/server/handlers"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
 )
 
 // registerRoutes registers all HTTP routes
@@ -32,11 +33,41 @@ func (s *Server) registerRoutes(opts Options) {
 	s.registerAdminEndpoint()
 
 	if strings.TrimSpace(opts.JobsRoot) != "" {
-		jobs := handlers.NewJobsHandler(opts.JobsRoot, opts.JobsInvocation)
+		executor := jobregistry.NewExecutor(opts.JobsRoot)
+		executor.Limits = opts.JobsQueue
+		if hooks := opts.JobHooks; hooks != nil {
+			// Delivered in the background so a slow receiver never delays a
+			// submission; the outcome is recorded on the job.
+			executor.OnEnqueued = func(rec *jobregistry.JobRecord) {
+				go func() { _ = hooks.Notify(executor.Store(), rec, jobregistry.HookEventQueued, "") }()
+			}
+		}
+		jobs := handlers.NewJobsHandler(executor, opts.JobsInvocation)
+		s.jobs = jobs
 		s.router.Post("/api/v1/jobs", jobs.Submit)
 		s.router.Get("/api/v1/jobs", jobs.List)
 		s.router.Get("/api/v1/jobs/{job_id}", jobs.Status)
 		s.router.Delete("/api/v1/jobs/{job_id}", jobs.Cancel)
+		s.router.Get("/api/v1/jobs/{job_id}/events", jobs.Events)
+		s.router.Get("/api/v1/jobs/{job_id}/logs", jobs.Logs)
+
+		// Domain metrics written by the managed jobs this server runs.
+		s.router.Get("/metrics/jobs", jobs.Metrics)
+
+		schedules := handlers.NewSchedulesHandler(opts.JobsRoot, jobs)
+		s.router.Post("/api/v1/schedules", schedules.Create)
+		s.router.Get("/api/v1/schedules", schedules.List)
+		s.router.Get("/api/v1/schedules/{schedule_id}", schedules.Status)
+		s.router.Delete("/api/v1/schedules/{schedule_id}", schedules.Delete)
+		s.scheduler = newScheduler(executor, opts.JobsInvocation, jobs)
+		s.queue = executor
+	}
+
+	if opts.Indexes != nil {
+		indexes := handlers.NewIndexesHandler(*opts.Indexes)
+		s.router.Get("/api/v1/indexes", indexes.List)
+		s.router.Get("/api/v1/indexes/{index_id}/stats", indexes.Stats)
+		s.router.Post("/api/v1/indexes/{index_id}/query", indexes.Query)
 	}
 }
 
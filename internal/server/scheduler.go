@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/3leaps/gonimbus/internal/observability"
+	"github.com/3leaps/gonimbus/internal/server/handlers"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+)
+
+// newScheduler builds the scheduler for the schedules under the executor's
+// jobs root. Scheduled runs are tracked by jobs, so a drain covers them like
+// submitted jobs.
+func newScheduler(executor *jobregistry.Executor, invocation *jobregistry.IndexBuildInvocation, jobs *handlers.JobsHandler) *jobregistry.Scheduler {
+	scheduler := jobregistry.NewScheduler(executor, invocation)
+	scheduler.OnRun = func(sched jobregistry.Schedule, job *jobregistry.JobRecord) {
+		fields := []zap.Field{
+			zap.String("schedule_id", sched.ScheduleID),
+			zap.String("outcome", sched.LastOutcome),
+		}
+		if job != nil {
+			jobs.TrackStarted(job.JobID)
+			fields = append(fields, zap.String("job_id", job.JobID))
+		}
+		if sched.LastError != "" {
+			fields = append(fields, zap.String("error", sched.LastError))
+		}
+		if observability.ServerLogger != nil {
+			observability.ServerLogger.Info("Scheduled run", fields...)
+		}
+	}
+	return scheduler
+}
+
+// StartScheduler starts running due schedules and admitting queued jobs in the
+// background. It is a no-op without a jobs root or once the server is
+// draining.
+func (s *Server) StartScheduler() {
+	s.schedulerMu.Lock()
+	defer s.schedulerMu.Unlock()
+	if s.scheduler == nil || s.schedulerStop != nil || s.Draining() {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	s.schedulerStop, s.schedulerDone = cancel, done
+	go func() {
+		defer close(done)
+		admitted := make(chan struct{})
+		go func() {
+			defer close(admitted)
+			s.queue.RunAdmission(ctx, 0)
+		}()
+		s.scheduler.Run(ctx)
+		<-admitted
+	}()
+}
+
+// StopScheduler stops the scheduler and queue admission and waits for an
+// in-flight pass, so no scheduled or queued job starts after it returns. Jobs
+// still waiting stay queued for the next runner.
+func (s *Server) StopScheduler() {
+	s.schedulerMu.Lock()
+	defer s.schedulerMu.Unlock()
+	if s.schedulerStop == nil {
+		return
+	}
+	s.schedulerStop()
+	<-s.schedulerDone
+	s.schedulerStop, s.schedulerDone = nil, nil
+}
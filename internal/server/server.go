@@ -2,8 +2,15 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -13,20 +20,44 @@ import (
 	"github.com/3leaps/gonimbus/internal/observability"
 	"github.com/3leaps/gonimbus/internal/server/handlers"
 	servermw "github.com/3leaps/gonimbus/internal/server/middleware"
+	"github.com/3leaps/gonimbus/pkg/indexreader"
 	"github.com/3leaps/gonimbus/pkg/jobregistry"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	router *chi.Mux
-	server *http.Server
-	host   string
-	port   int
+	router     *chi.Mux
+	server     *http.Server
+	serverOnce sync.Once
+	host       string
+	port       int
+
+	tls        *TLSOptions
+	unixSocket string
+	jobs       *handlers.JobsHandler
+	draining   atomic.Bool
+
+	scheduler     *jobregistry.Scheduler
+	queue         *jobregistry.Executor
+	schedulerMu   sync.Mutex
+	schedulerStop context.CancelFunc
+	schedulerDone chan struct{}
 }
 
 type Options struct {
 	JobsRoot       string
 	JobsInvocation *jobregistry.IndexBuildInvocation
+	// JobsQueue caps the jobs this server admits from the job queue.
+	JobsQueue jobregistry.QueueLimits
+	// JobHooks delivers the queued event for jobs this server enqueues. The
+	// managed children deliver their own running and terminal events.
+	JobHooks *jobregistry.Hooks
+	// Indexes enables the read-only index API over these local roots.
+	Indexes *indexreader.ResolveOptions
+	// TLS serves HTTPS instead of plain HTTP.
+	TLS *TLSOptions
+	// UnixSocket listens on this socket path instead of host:port.
+	UnixSocket string
 }
 
 // New creates a new HTTP server instance
@@ -60,9 +91,11 @@ func NewWithOptions(host string, port int, opts Options) *Server {
 	})
 
 	s := &Server{
-		router: r,
-		host:   host,
-		port:   port,
+		router:     r,
+		host:       host,
+		port:       port,
+		tls:        opts.TLS,
+		unixSocket: opts.UnixSocket,
 	}
 
 	// Ensure handlers use the centralized error responder
@@ -76,28 +109,121 @@ func NewWithOptions(host string, port int, opts Options) *Server {
 
 // Start starts the HTTP server
 func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%d", s.host, s.port)
-
-	s.server = &http.Server{
-		Addr:         addr,
-		Handler:      s.router,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+	ln, err := s.Listen()
+	if err != nil {
+		return err
 	}
+	return s.Serve(ln)
+}
+
+// Listen opens the server's listener: the Unix socket when one is configured,
+// else host:port, wrapped in TLS when TLS is configured.
+func (s *Server) Listen() (net.Listener, error) {
+	s.httpServer()
+	var tlsConfig *tls.Config
+	if s.tls != nil {
+		cfg, err := s.tls.config()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = cfg
+	}
+
+	var ln net.Listener
+	if s.unixSocket != "" {
+		l, err := listenUnixSocket(s.unixSocket)
+		if err != nil {
+			return nil, err
+		}
+		ln = l
+	} else {
+		l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.host, s.port))
+		if err != nil {
+			return nil, err
+		}
+		ln = l
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	return ln, nil
+}
+
+// Serve serves requests on ln until Shutdown.
+func (s *Server) Serve(ln net.Listener) error {
+	srv := s.httpServer()
 
-	observability.ServerLogger.Info("Starting HTTP server",
-		zap.String("host", s.host),
-		zap.Int("port", s.port),
-		zap.String("addr", addr))
+	scheme := "http"
+	if s.tls != nil {
+		scheme = "https"
+	}
+	if observability.ServerLogger != nil {
+		observability.ServerLogger.Info("Starting HTTP server",
+			zap.String("host", s.host),
+			zap.Int("port", s.port),
+			zap.String("network", ln.Addr().Network()),
+			zap.String("addr", ln.Addr().String()),
+			zap.String("scheme", scheme))
+	}
 
-	return s.server.ListenAndServe()
+	return srv.Serve(ln)
+}
+
+// httpServer builds the http.Server once. Listen builds it too, so a caller
+// that listens before serving in a goroutine can Shutdown without racing.
+func (s *Server) httpServer() *http.Server {
+	s.serverOnce.Do(func() {
+		s.server = &http.Server{
+			Handler:      s.router,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			IdleTimeout:  120 * time.Second,
+		}
+		if s.jobs != nil {
+			// Following job streams never finish on their own while a job
+			// runs; end them as shutdown begins so it does not wait them out.
+			s.server.RegisterOnShutdown(s.jobs.CloseStreams)
+		}
+	})
+	return s.server
+}
+
+// listenUnixSocket listens on path, replacing a stale socket left by a server
+// that did not shut down cleanly. Any other file at path is left alone. The
+// socket is owner-only; grant access through its directory.
+func listenUnixSocket(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("unix socket path %s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unix socket %s is in use by another server", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale unix socket: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("restrict unix socket permissions: %w", err)
+	}
+	return ln, nil
 }
 
 // Shutdown gracefully shuts down the HTTP server
 func (s *Server) Shutdown(ctx context.Context) error {
-	observability.ServerLogger.Info("Shutting down HTTP server")
-	return s.server.Shutdown(ctx)
+	if observability.ServerLogger != nil {
+		observability.ServerLogger.Info("Shutting down HTTP server")
+	}
+	s.StopScheduler()
+	return s.httpServer().Shutdown(ctx)
 }
 
 // Handler exposes the underlying router for testing and instrumentation
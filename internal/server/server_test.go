@@ -1,13 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	apperrors "github.com/3leaps/gonimbus/internal/errors"
 	"github.com/3leaps/gonimbus/internal/server/handlers"
+	"github.com/3leaps/gonimbus/pkg/jobregistry"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -150,3 +156,112 @@ func TestServer_AdminEndpointDisabledByDefault(t *testing.T) {
 // controlling appid.Get() return value which depends on global state.
 // The registerAdminEndpoint function is tested implicitly through
 // TestServer_AdminEndpointDisabledByDefault which covers the "no token" path.
+
+func TestServer_DrainFlipsReadinessAndClosesSubmissions(t *testing.T) {
+	handlers.InitHealthManager("test")
+	srv := NewWithOptions("127.0.0.1", 0, Options{JobsRoot: t.TempDir()})
+
+	_, err := srv.Drain(context.Background(), DrainOptions{Jobs: "later"})
+	require.Error(t, err)
+	require.False(t, srv.Draining())
+
+	result, err := srv.Drain(context.Background(), DrainOptions{Jobs: DrainJobsWait, Timeout: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, &DrainResult{}, result)
+	require.True(t, srv.Draining())
+
+	for path, want := range map[string]int{
+		"/health/live":  http.StatusOK,
+		"/health/ready": http.StatusServiceUnavailable,
+		"/api/v1/jobs":  http.StatusOK,
+	} {
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, rec.Code, path)
+	}
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(`{"type":"index.build"}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	handlers.GetHealthManager().SetDraining(false)
+}
+
+func TestServer_DrainStopsScheduler(t *testing.T) {
+	handlers.InitHealthManager("test")
+	srv := NewWithOptions("127.0.0.1", 0, Options{JobsRoot: t.TempDir()})
+
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/schedules", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	srv.StartScheduler()
+	srv.schedulerMu.Lock()
+	running := srv.schedulerStop != nil
+	srv.schedulerMu.Unlock()
+	require.True(t, running)
+
+	_, err := srv.Drain(context.Background(), DrainOptions{Jobs: DrainJobsLeave})
+	require.NoError(t, err)
+	srv.StartScheduler()
+	srv.schedulerMu.Lock()
+	running = srv.schedulerStop != nil
+	srv.schedulerMu.Unlock()
+	require.False(t, running, "a draining server never restarts its scheduler")
+	handlers.GetHealthManager().SetDraining(false)
+}
+
+// TestServer_DrainHoldsQueueAdmission drains with a job waiting in the queue:
+// no process admitting from the jobs root, such as a managed job finishing
+// during the drain, may start it.
+func TestServer_DrainHoldsQueueAdmission(t *testing.T) {
+	handlers.InitHealthManager("test")
+	root := t.TempDir()
+	srv := NewWithOptions("127.0.0.1", 0, Options{JobsRoot: root})
+	now := time.Now().UTC()
+	waiting := &jobregistry.JobRecord{
+		JobID: uuid.New().String(), Type: jobregistry.JobTypeIndexBuild, State: jobregistry.JobStateQueued,
+		ManifestPath: "/m.yaml", CreatedAt: now,
+		Queue: &jobregistry.JobQueueEntry{Priority: jobregistry.QueuePriorityNormal, EnqueuedAt: now},
+	}
+	require.NoError(t, jobregistry.NewStore(root).Write(waiting))
+
+	_, err := srv.Drain(context.Background(), DrainOptions{Jobs: DrainJobsLeave})
+	require.NoError(t, err)
+	started, err := jobregistry.NewExecutor(root).AdmitQueued()
+	require.NoError(t, err)
+	require.Empty(t, started)
+	rec, err := jobregistry.NewStore(root).Get(waiting.JobID)
+	require.NoError(t, err)
+	require.True(t, rec.AwaitingAdmission())
+	handlers.GetHealthManager().SetDraining(false)
+}
+
+// TestServerShutdownEndsFollowingJobStreams shuts down with a client following
+// a running job's events: the stream must end rather than hold shutdown until
+// its deadline.
+func TestServerShutdownEndsFollowingJobStreams(t *testing.T) {
+	root := t.TempDir()
+	jobID := "99999999-9999-4999-8999-999999999999"
+	require.NoError(t, jobregistry.NewStore(root).Write(&jobregistry.JobRecord{
+		JobID:     jobID,
+		Type:      jobregistry.JobTypeIndexBuild,
+		State:     jobregistry.JobStateRunning,
+		CreatedAt: time.Now().UTC(),
+	}))
+
+	srv := NewWithOptions("127.0.0.1", 0, Options{JobsRoot: root})
+	ln, err := srv.Listen()
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(ln) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/api/v1/jobs/" + jobID + "/events")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx), "shutdown must not wait out an open follower")
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err, "the follower sees its stream end cleanly")
+}
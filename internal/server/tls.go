@@ -0,0 +1,124 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/3leaps/gonimbus/internal/observability"
+)
+
+// certReloadInterval bounds how often the certificate files are checked for
+// changes; checks happen on TLS handshakes, not on a timer.
+const certReloadInterval = time.Second
+
+// TLSOptions configures HTTPS. The certificate and key are re-read when either
+// file changes, so a rotated certificate is served without a restart. With
+// ClientCAFile set, clients must present a certificate signed by one of its CAs.
+type TLSOptions struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+func (o TLSOptions) config() (*tls.Config, error) {
+	reloader, err := newCertReloader(o.CertFile, o.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	if o.ClientCAFile != "" {
+		pem, err := os.ReadFile(o.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA file %s holds no PEM certificates", o.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// certReloader serves a certificate pair from disk, reloading it when either
+// file's size or modification time changes. A pair that fails to load (for
+// example, a certificate rotated before its key) keeps the previous one in
+// service and is retried on the next check.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	stamp     [2]certFileStamp
+	checkedAt time.Time
+}
+
+type certFileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: certReloadInterval}
+	stamp, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS certificate: %w", err)
+	}
+	r.cert, r.stamp, r.checkedAt = &cert, stamp, time.Now()
+	return r, nil
+}
+
+func (r *certReloader) stat() ([2]certFileStamp, error) {
+	var out [2]certFileStamp
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return out, fmt.Errorf("stat TLS file: %w", err)
+		}
+		out[i] = certFileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return out, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checkedAt) < r.interval {
+		return r.cert, nil
+	}
+	r.checkedAt = time.Now()
+	stamp, err := r.stat()
+	if err != nil || stamp == r.stamp {
+		return r.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if observability.ServerLogger != nil {
+			observability.ServerLogger.Warn("TLS certificate changed but did not load; serving the previous one",
+				zap.String("cert_file", r.certFile), zap.Error(err))
+		}
+		return r.cert, nil
+	}
+	r.cert, r.stamp = &cert, stamp
+	if observability.ServerLogger != nil {
+		observability.ServerLogger.Info("Reloaded TLS certificate", zap.String("cert_file", r.certFile))
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,157 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// BundleMediaType identifies a detached signature bundle: a signed
+	// statement naming artifacts by digest, stored beside the artifact it
+	// covers rather than inside it.
+	BundleMediaType = "application/vnd.gonimbus.signature-bundle.v1+json"
+	// StatementType identifies the statement a bundle signs.
+	StatementType = "gonimbus.signed_artifacts.v1"
+	// BundleSuffix is appended to an artifact name to name its bundle, so
+	// manifest.json is covered by manifest.json.sig.json.
+	BundleSuffix = ".sig.json"
+)
+
+// ErrUntrustedKey reports a signature that verifies but was made by a key the
+// caller's trust policy does not list.
+var ErrUntrustedKey = errors.New("signing key is not trusted")
+
+// Subject names one artifact a statement covers by its SHA-256 and size.
+type Subject struct {
+	Name      string `json:"name"`
+	SHA256    string `json:"sha256"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// Statement is the signed body of a bundle. Claims bind the subjects to their
+// context (for hub artifacts, the index set and run) so a valid bundle cannot
+// be replayed beside another run's bytes.
+type Statement struct {
+	Type     string            `json:"type"`
+	Subjects []Subject         `json:"subjects"`
+	Claims   map[string]string `json:"claims,omitempty"`
+	SignedAt string            `json:"signed_at"`
+}
+
+// Subject returns the subject named name.
+func (s Statement) Subject(name string) (Subject, bool) {
+	for _, subject := range s.Subjects {
+		if subject.Name == name {
+			return subject, true
+		}
+	}
+	return Subject{}, false
+}
+
+// Bundle is a detached signature over a Statement, in the spirit of a Sigstore
+// bundle: the statement, its signature, and the public key travel together so
+// a verifier needs nothing but the bundle and its own trust policy.
+type Bundle struct {
+	MediaType string    `json:"media_type"`
+	Statement Statement `json:"statement"`
+	Signature Signature `json:"signature"`
+}
+
+// SignBundle signs stmt and returns the bundle that carries it. stmt.Type
+// defaults to StatementType.
+func SignBundle(key ed25519.PrivateKey, stmt Statement) (*Bundle, error) {
+	if stmt.Type == "" {
+		stmt.Type = StatementType
+	}
+	if len(stmt.Subjects) == 0 {
+		return nil, errors.New("statement has no subjects")
+	}
+	sig, err := Sign(key, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{MediaType: BundleMediaType, Statement: stmt, Signature: *sig}, nil
+}
+
+// ParseBundle decodes a bundle and rejects unknown media or statement types.
+// It does not verify the signature; call Verify.
+func ParseBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse signature bundle: %w", err)
+	}
+	if b.MediaType != BundleMediaType {
+		return nil, fmt.Errorf("unsupported signature bundle media type %q", b.MediaType)
+	}
+	if b.Statement.Type != StatementType {
+		return nil, fmt.Errorf("unsupported signed statement type %q", b.Statement.Type)
+	}
+	return &b, nil
+}
+
+// Verify checks the bundle's signature over its statement. Like Verify, it
+// says nothing about whether the signer is trusted.
+func (b *Bundle) Verify() error {
+	return Verify(&b.Signature, b.Statement)
+}
+
+// TrustedKeys is a trust policy: the ed25519 public keys whose signatures a
+// verifier accepts, keyed by KeyID.
+type TrustedKeys map[string]ed25519.PublicKey
+
+// LoadTrustedKeys reads one or more PEM "PUBLIC KEY" blocks, the format
+// `openssl pkey -pubout` writes, concatenated in a single file.
+func LoadTrustedKeys(path string) (TrustedKeys, error) {
+	raw, err := os.ReadFile(path) // #nosec G304 -- operator-supplied trust policy path.
+	if err != nil {
+		return nil, err
+	}
+	keys := TrustedKeys{}
+	for {
+		var block *pem.Block
+		block, raw = pem.Decode(raw)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		pub, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s: key is %T, want ed25519", path, parsed)
+		}
+		keys[KeyID(pub)] = pub
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no PEM PUBLIC KEY blocks found", path)
+	}
+	return keys, nil
+}
+
+// Trusts reports whether sig was made with a key in the policy. It compares
+// the full public key, not only the short KeyID.
+func (t TrustedKeys) Trusts(sig *Signature) bool {
+	if sig == nil {
+		return false
+	}
+	want, ok := t[sig.KeyID]
+	if !ok {
+		return false
+	}
+	pub, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(pub, want)
+}
@@ -0,0 +1,84 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTrustedKeysFile(t *testing.T, pubs ...ed25519.PublicKey) string {
+	t.Helper()
+	var data []byte
+	for _, pub := range pubs {
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		require.NoError(t, err)
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+	}
+	path := filepath.Join(t.TempDir(), "trusted.pem")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestBundleRoundTripAndTamper(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	bundle, err := SignBundle(key, Statement{
+		Subjects: []Subject{{Name: "manifest.json", SHA256: "ab", SizeBytes: 2}},
+		Claims:   map[string]string{"run_id": "run_1"},
+		SignedAt: "2026-10-18T00:00:00Z",
+	})
+	require.NoError(t, err)
+	data, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	parsed, err := ParseBundle(data)
+	require.NoError(t, err)
+	require.NoError(t, parsed.Verify())
+	subject, ok := parsed.Statement.Subject("manifest.json")
+	require.True(t, ok)
+	require.Equal(t, "ab", subject.SHA256)
+
+	parsed.Statement.Subjects[0].SHA256 = "cd"
+	require.ErrorIs(t, parsed.Verify(), ErrInvalidSignature)
+
+	_, err = ParseBundle([]byte(`{"media_type":"application/json"}`))
+	require.Error(t, err)
+}
+
+func TestTrustedKeysComparesWholeKey(t *testing.T) {
+	trustedPub, trustedKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys, err := LoadTrustedKeys(writeTrustedKeysFile(t, trustedPub))
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	sig, err := Sign(trustedKey, map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.True(t, keys.Trusts(sig))
+
+	other, err := Sign(otherKey, map[string]int{"a": 1})
+	require.NoError(t, err)
+	require.False(t, keys.Trusts(other))
+
+	// A key that claims a trusted KeyID is still rejected on its bytes.
+	other.KeyID = sig.KeyID
+	require.False(t, keys.Trusts(other))
+}
+
+func TestLoadTrustedKeysRejectsEmptyPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(path, []byte("# no keys\n"), 0o600))
+	_, err := LoadTrustedKeys(path)
+	require.Error(t, err)
+}
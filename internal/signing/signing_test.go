@@ -0,0 +1,56 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalJSONSortsKeysAndKeepsNumbers(t *testing.T) {
+	got, err := CanonicalJSON(struct {
+		Zeta  string `json:"zeta"`
+		Alpha int64  `json:"alpha"`
+		Mid   any    `json:"mid"`
+	}{Zeta: "<a&b>", Alpha: 9007199254740993, Mid: map[string]int{"b": 2, "a": 1}})
+	require.NoError(t, err)
+	require.Equal(t, `{"alpha":9007199254740993,"mid":{"a":1,"b":2},"zeta":"<a&b>"}`, string(got))
+}
+
+func TestSignVerifyRoundTripAndTamper(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pem")
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	loaded, err := LoadPrivateKey(path)
+	require.NoError(t, err)
+
+	payload := map[string]any{"total": 3, "verified": 3}
+	sig, err := Sign(loaded, payload)
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmEd25519, sig.Algorithm)
+	require.Len(t, sig.KeyID, 16)
+	require.NoError(t, Verify(sig, payload))
+
+	payload["verified"] = 2
+	require.ErrorIs(t, Verify(sig, payload), ErrInvalidSignature)
+
+	forged := *sig
+	forged.KeyID = "0000000000000000"
+	require.ErrorIs(t, Verify(&forged, map[string]any{"total": 3, "verified": 3}), ErrInvalidSignature)
+}
+
+func TestLoadPrivateKeyRejectsNonEd25519(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+	_, err := LoadPrivateKey(path)
+	require.Error(t, err)
+}
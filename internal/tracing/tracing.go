@@ -0,0 +1,210 @@
+// Package tracing wires optional OpenTelemetry tracing for gonimbus runs.
+//
+// Tracing is off unless an exporter is selected. Library packages (pkg/reflow,
+// pkg/indexbuild) start spans through the global TracerProvider only, so they
+// stay no-ops for embedders that never install one; this package installs the
+// provider for the CLI and server, and owns the two exporters we support:
+// OTLP over HTTP, and an offline JSON-lines file for runs without a collector.
+//
+// Span content is sterile by construction. Object keys, prefixes, and URIs are
+// never recorded; buckets appear only as the same truncated hash the domain
+// metrics use, and error messages pass through the reflow redaction rules.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/3leaps/gonimbus/pkg/reflow"
+)
+
+// Exporter names accepted by Config.Exporter.
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+// Environment variables read when the matching flag is not set. EnvTraceParent
+// and EnvTraceState follow the OpenTelemetry environment-carrier convention and
+// carry a submitter's trace context into a managed job child.
+const (
+	EnvExporter    = "GONIMBUS_TRACE_EXPORTER"
+	EnvFile        = "GONIMBUS_TRACE_FILE"
+	EnvEndpoint    = "GONIMBUS_TRACE_ENDPOINT"
+	EnvTraceParent = "TRACEPARENT"
+	EnvTraceState  = "TRACESTATE"
+)
+
+// InstrumentationName names the tracer used by the CLI, server, and provider
+// transport.
+const InstrumentationName = "github.com/3leaps/gonimbus"
+
+// Config selects a trace exporter.
+type Config struct {
+	// Exporter is ExporterNone, ExporterOTLP, or ExporterFile. Empty means none.
+	Exporter string
+
+	// Endpoint is the OTLP/HTTP endpoint URL. Empty defers to the standard
+	// OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_EXPORTER_OTLP_TRACES_ENDPOINT variables.
+	Endpoint string
+
+	// FilePath is the JSON-lines file the file exporter appends spans to.
+	FilePath string
+
+	// ServiceName and ServiceVersion populate the trace resource.
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Enabled reports whether cfg selects an exporter.
+func (c Config) Enabled() bool {
+	exporter := strings.ToLower(strings.TrimSpace(c.Exporter))
+	return exporter != "" && exporter != ExporterNone
+}
+
+// Setup installs a global TracerProvider and W3C trace-context propagator for
+// cfg. The returned shutdown flushes buffered spans and must be called before
+// the process exits. A disabled cfg returns a no-op shutdown.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enabled() {
+		return noop, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		file     *os.File
+	)
+	switch strings.ToLower(strings.TrimSpace(cfg.Exporter)) {
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if endpoint := strings.TrimSpace(cfg.Endpoint); endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return noop, fmt.Errorf("create OTLP trace exporter: %w", err)
+		}
+	case ExporterFile:
+		path := strings.TrimSpace(cfg.FilePath)
+		if path == "" {
+			return noop, fmt.Errorf("trace file exporter requires a file path")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return noop, fmt.Errorf("create trace file directory: %w", err)
+		}
+		// #nosec G304 -- operator-selected trace output path
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return noop, fmt.Errorf("open trace file: %w", err)
+		}
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return noop, fmt.Errorf("create trace file exporter: %w", err)
+		}
+	default:
+		return noop, fmt.Errorf("unknown trace exporter %q (expected %s, %s, or %s)", cfg.Exporter, ExporterNone, ExporterOTLP, ExporterFile)
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = "gonimbus"
+	}
+	attrs := []attribute.KeyValue{attribute.String("service.name", serviceName)}
+	if v := strings.TrimSpace(cfg.ServiceVersion); v != "" {
+		attrs = append(attrs, attribute.String("service.version", v))
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(Propagator())
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Propagator is the W3C trace-context propagator used for HTTP submissions and
+// managed job children. It is used directly, not through the global, so trace
+// context is carried into a child even when this process does not export.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.TraceContext{}
+}
+
+// Tracer returns the gonimbus tracer from the global TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// ContextFromEnv returns ctx carrying the remote span context found in
+// TRACEPARENT/TRACESTATE, or ctx unchanged when none is set.
+func ContextFromEnv(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{}
+	if v := strings.TrimSpace(os.Getenv(EnvTraceParent)); v != "" {
+		carrier["traceparent"] = v
+	}
+	if v := strings.TrimSpace(os.Getenv(EnvTraceState)); v != "" {
+		carrier["tracestate"] = v
+	}
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return Propagator().Extract(ctx, carrier)
+}
+
+// ContextFromHTTP returns ctx carrying the remote span context of an inbound
+// request's traceparent header, if any.
+func ContextFromHTTP(ctx context.Context, header http.Header) context.Context {
+	return Propagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// TraceParent renders the span context in ctx as a traceparent value, or ""
+// when ctx carries no valid span context.
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	Propagator().Inject(ctx, carrier)
+	return carrier["traceparent"]
+}
+
+// EndSpan records err on span and ends it. The status description is the
+// reflow-redacted message with object URIs reduced to their scheme, so neither
+// credentials nor bucket/key names reach the exporter.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, RedactMessage(err))
+	}
+	span.End()
+}
+
+var objectURIPattern = regexp.MustCompile(`\b(s3|gs|gcs|file)://[^\s"'<>]*`)
+
+// RedactMessage applies reflow.SanitizeOperationCauseMessage and then replaces
+// every object URI with "<scheme>://<redacted>".
+func RedactMessage(err error) string {
+	return objectURIPattern.ReplaceAllString(reflow.SanitizeOperationCauseMessage(err), "$1://<redacted>")
+}
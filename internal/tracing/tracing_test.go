@@ -0,0 +1,176 @@
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestSetupDisabledIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), Config{Exporter: "zipkin"})
+	require.ErrorContains(t, err, "unknown trace exporter")
+}
+
+func TestFileExporterWritesJSONLines(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces", "run.jsonl")
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, FilePath: path, ServiceVersion: "test"})
+	require.NoError(t, err)
+
+	ctx, parent := Tracer().Start(context.Background(), "gonimbus transfer reflow")
+	_, child := Tracer().Start(ctx, "provider.list")
+	child.End()
+	parent.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var span struct{ Name string }
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &span))
+		names = append(names, span.Name)
+	}
+	require.NoError(t, scanner.Err())
+	require.ElementsMatch(t, []string{"gonimbus transfer reflow", "provider.list"}, names)
+}
+
+func TestTraceParentRoundTripsThroughEnvironment(t *testing.T) {
+	recorder := useRecorder(t)
+	ctx, span := Tracer().Start(context.Background(), "jobs.submit")
+	traceParent := TraceParent(ctx)
+	span.End()
+	require.NotEmpty(t, traceParent)
+
+	t.Setenv(EnvTraceParent, traceParent)
+	_, child := Tracer().Start(ContextFromEnv(context.Background()), "gonimbus index build")
+	child.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	require.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+}
+
+func TestTraceParentPropagatesWithoutExporter(t *testing.T) {
+	// A server that does not export still forwards the caller's context.
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, span := noop.NewTracerProvider().Tracer("t").Start(ContextFromHTTP(context.Background(), header), "jobs.submit")
+	defer span.End()
+	require.Contains(t, TraceParent(ctx), "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestClassifyRequest(t *testing.T) {
+	newReq := func(method, rawURL string, header map[string]string) *http.Request {
+		req := httptest.NewRequest(method, rawURL, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"s3 list", newReq(http.MethodGet, "https://b.s3.amazonaws.com/?list-type=2&prefix=a%2F", nil), OpList},
+		{"s3 head", newReq(http.MethodHead, "https://b.s3.amazonaws.com/a/b.txt", nil), OpHead},
+		{"s3 get", newReq(http.MethodGet, "https://b.s3.amazonaws.com/a/o", nil), OpGet},
+		{"s3 range", newReq(http.MethodGet, "https://b.s3.amazonaws.com/a/b", map[string]string{"Range": "bytes=0-9"}), OpGetRange},
+		{"s3 put part", newReq(http.MethodPut, "https://b.s3.amazonaws.com/a/b?partNumber=2&uploadId=x", nil), OpPutPart},
+		{"s3 copy", newReq(http.MethodPut, "https://b.s3.amazonaws.com/a/b", map[string]string{"X-Amz-Copy-Source": "src/k"}), OpCopy},
+		{"s3 put", newReq(http.MethodPut, "https://b.s3.amazonaws.com/a/b", nil), OpPut},
+		{"s3 create multipart", newReq(http.MethodPost, "https://b.s3.amazonaws.com/a/b?uploads", nil), OpMultipartCreate},
+		{"s3 complete multipart", newReq(http.MethodPost, "https://b.s3.amazonaws.com/a/b?uploadId=x", nil), OpMultipartComplete},
+		{"s3 abort multipart", newReq(http.MethodDelete, "https://b.s3.amazonaws.com/a/b?uploadId=x", nil), OpMultipartAbort},
+		{"s3 delete", newReq(http.MethodDelete, "https://b.s3.amazonaws.com/a/b", nil), OpDelete},
+		{"gcs list", newReq(http.MethodGet, "https://storage.googleapis.com/storage/v1/b/bkt/o?prefix=a", nil), OpList},
+		{"gcs metadata", newReq(http.MethodGet, "https://storage.googleapis.com/storage/v1/b/bkt/o/a%2Fb?alt=json", nil), OpHead},
+		{"gcs media", newReq(http.MethodGet, "https://storage.googleapis.com/storage/v1/b/bkt/o/a%2Fb?alt=media", nil), OpGet},
+		{"gcs xml read", newReq(http.MethodGet, "https://storage.googleapis.com/bkt/a/b", nil), OpGet},
+		{"gcs upload", newReq(http.MethodPost, "https://storage.googleapis.com/upload/storage/v1/b/bkt/o?uploadType=resumable", nil), OpPut},
+		{"gcs upload chunk", newReq(http.MethodPut, "https://storage.googleapis.com/upload/storage/v1/b/bkt/o?uploadType=resumable&upload_id=x", nil), OpPutPart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ClassifyRequest(tt.req))
+		})
+	}
+}
+
+func TestTransportSpansCarryNoBucketOrKey(t *testing.T) {
+	recorder := useRecorder(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("<ListBucketResult/>"))
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: WrapTransport("s3", "secret-bucket")(nil)}
+	ctx, parent := Tracer().Start(context.Background(), "reflow.run")
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		req, err := http.NewRequestWithContext(ctx, method, srv.URL+"/secret-bucket/private/key.csv?list-type=2", nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, "provider.list", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	require.Equal(t, "provider.head", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Len(t, spans[1].Events(), 1)
+
+	for _, span := range spans {
+		rendered := fmt.Sprintf("%s %v %v", span.Name(), span.Attributes(), span.Status())
+		require.NotContains(t, rendered, "secret-bucket")
+		require.NotContains(t, rendered, "private/key.csv")
+	}
+}
+
+func TestRedactMessageStripsObjectURIsAndCredentials(t *testing.T) {
+	msg := RedactMessage(errors.New("copy s3://secret-bucket/private/key.csv failed: connection reset"))
+	require.Equal(t, "copy s3://<redacted> failed: connection reset", msg)
+
+	msg = RedactMessage(errors.New("GET https://x.example/o?X-Amz-Signature=abc failed"))
+	require.NotContains(t, msg, "abc")
+}
@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/3leaps/gonimbus/internal/metrics"
+)
+
+// Provider operation names recorded on provider spans. They classify a request
+// by method and query shape so LIST pages, HEADs, GETs, and multipart PUT parts
+// are separable without recording the request URL.
+const (
+	OpList              = "list"
+	OpHead              = "head"
+	OpGet               = "get"
+	OpGetRange          = "get_range"
+	OpPut               = "put"
+	OpPutPart           = "put_part"
+	OpCopy              = "copy"
+	OpMultipartCreate   = "multipart_create"
+	OpMultipartComplete = "multipart_complete"
+	OpMultipartAbort    = "multipart_abort"
+	OpDelete            = "delete"
+	OpOther             = "other"
+)
+
+// WrapTransport returns a transport wrapper that starts one client span per
+// provider HTTP request. It is installed through providerdispatch so every
+// S3 and GCS provider built by the CLI and server is traced.
+func WrapTransport(providerName, bucket string) func(http.RoundTripper) http.RoundTripper {
+	bucketHash := metrics.BucketHash(bucket)
+	return func(next http.RoundTripper) http.RoundTripper {
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		return &transport{next: next, provider: providerName, bucketHash: bucketHash}
+	}
+}
+
+type transport struct {
+	next       http.RoundTripper
+	provider   string
+	bucketHash string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := ClassifyRequest(req)
+	ctx, span := Tracer().Start(req.Context(), "provider."+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("gonimbus.provider", t.provider),
+			attribute.String("gonimbus.bucket_hash", t.bucketHash),
+			attribute.String("gonimbus.provider.operation", op),
+			attribute.String("http.request.method", req.Method),
+		),
+	)
+	if req.ContentLength > 0 {
+		span.SetAttributes(attribute.Int64("http.request.body.size", req.ContentLength))
+	}
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		EndSpan(span, err)
+		return resp, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.ContentLength >= 0 {
+		span.SetAttributes(attribute.Int64("http.response.body.size", resp.ContentLength))
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		span.AddEvent("throttled")
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	case resp.StatusCode >= 500:
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	span.End()
+	return resp, nil
+}
+
+// ClassifyRequest maps a provider HTTP request to an operation name from its
+// method, query parameter names, headers, and GCS API path shape. It covers the
+// S3 REST and GCS JSON/XML request shapes; the path itself is never recorded.
+func ClassifyRequest(req *http.Request) string {
+	query := req.URL.Query()
+	has := func(name string) bool {
+		_, ok := query[name]
+		return ok
+	}
+	switch req.Method {
+	case http.MethodHead:
+		return OpHead
+	case http.MethodGet:
+		switch {
+		case has("list-type"), gcsJSONPath(req) && strings.HasSuffix(req.URL.Path, "/o"):
+			return OpList
+		case req.Header.Get("Range") != "":
+			return OpGetRange
+		case gcsJSONPath(req) && query.Get("alt") != "media":
+			return OpHead
+		default:
+			return OpGet
+		}
+	case http.MethodPut:
+		switch {
+		case has("partNumber") && has("uploadId"):
+			return OpPutPart
+		case req.Header.Get("X-Amz-Copy-Source") != "":
+			return OpCopy
+		case has("upload_id"):
+			return OpPutPart
+		default:
+			return OpPut
+		}
+	case http.MethodPost:
+		switch {
+		case has("uploads"):
+			return OpMultipartCreate
+		case has("uploadId"):
+			return OpMultipartComplete
+		case strings.Contains(req.URL.Path, "/rewriteTo/") || strings.Contains(req.URL.Path, "/copyTo/"):
+			return OpCopy
+		case has("uploadType"):
+			return OpPut
+		default:
+			return OpOther
+		}
+	case http.MethodDelete:
+		if has("uploadId") {
+			return OpMultipartAbort
+		}
+		return OpDelete
+	default:
+		return OpOther
+	}
+}
+
+func gcsJSONPath(req *http.Request) bool {
+	return strings.HasPrefix(req.URL.Path, "/storage/v1/b/")
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/shard"
 )
 
 // Config configures crawler behavior.
@@ -74,6 +75,10 @@ type Summary struct {
 
 	// Prefixes lists the prefixes that were crawled.
 	Prefixes []string
+
+	// KeyRanges lists the key ranges that were crawled, when the crawl was
+	// split with WithKeyRanges.
+	KeyRanges []shard.KeyRange
 }
 
 // Crawler executes a crawl job against a cloud storage provider.
@@ -88,6 +93,7 @@ type Crawler struct {
 	jobID    string
 
 	prefixes []string
+	ranges   []shard.KeyRange
 
 	// budget is this crawler's handle on the request budget bounding its listing
 	// concurrency and request rate. It is leased from an injected RequestBudget
@@ -182,6 +188,16 @@ func (c *Crawler) WithPrefixes(prefixes []string) *Crawler {
 	return c
 }
 
+// WithKeyRanges splits the crawl into lexicographic key ranges, each listed
+// as its own unit under the request budget. Use it for flat prefixes that
+// delimiter sharding cannot split (see shard.SplitKeyRange).
+//
+// When set, the ranges replace WithPrefixes and matcher-derived prefixes.
+func (c *Crawler) WithKeyRanges(ranges []shard.KeyRange) *Crawler {
+	c.ranges = ranges
+	return c
+}
+
 // Run executes the crawl and returns summary statistics.
 //
 // Run blocks until the crawl completes, is cancelled via context, or
@@ -218,13 +234,23 @@ func (c *Crawler) Run(ctx context.Context) (*Summary, error) {
 		prefixes = []string{""}
 	}
 
+	units := make([]shard.KeyRange, 0, len(prefixes))
+	if len(c.ranges) > 0 {
+		units = c.ranges
+		prefixes = rangePrefixes(c.ranges)
+	} else {
+		for _, prefix := range prefixes {
+			units = append(units, shard.KeyRange{Prefix: prefix})
+		}
+	}
+
 	// Write initial progress
 	if err := c.writeProgress(ctx, output.PhaseStarting, ""); err != nil {
 		return nil, err
 	}
 
 	// Run the pipeline
-	if err := c.runPipeline(ctx, prefixes); err != nil {
+	if err := c.runPipeline(ctx, units); err != nil {
 		// Check if it's a context error (cancellation/timeout)
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			// Return partial summary on cancellation
@@ -252,7 +278,21 @@ func (c *Crawler) buildSummary(prefixes []string, duration time.Duration) *Summa
 		Duration:       duration,
 		Errors:         c.errorCount.Load(),
 		Prefixes:       prefixes,
+		KeyRanges:      c.ranges,
+	}
+}
+
+// rangePrefixes returns the distinct prefixes of ranges in first-seen order.
+func rangePrefixes(ranges []shard.KeyRange) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, r := range ranges {
+		if !seen[r.Prefix] {
+			seen[r.Prefix] = true
+			out = append(out, r.Prefix)
+		}
 	}
+	return out
 }
 
 // writeProgress emits a progress record.
@@ -308,7 +348,7 @@ type objectItem struct {
 }
 
 // runPipeline orchestrates the lister → matcher → writer pipeline.
-func (c *Crawler) runPipeline(ctx context.Context, prefixes []string) error {
+func (c *Crawler) runPipeline(ctx context.Context, units []shard.KeyRange) error {
 	// Create a cancellable context for the pipeline
 	pipeCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -327,7 +367,7 @@ func (c *Crawler) runPipeline(ctx context.Context, prefixes []string) error {
 	go func() {
 		defer wg.Done()
 		defer close(listCh)
-		if err := c.runListers(pipeCtx, prefixes, listCh); err != nil {
+		if err := c.runListers(pipeCtx, units, listCh); err != nil {
 			select {
 			case errCh <- err:
 			default:
@@ -369,21 +409,21 @@ func (c *Crawler) runPipeline(ctx context.Context, prefixes []string) error {
 	}
 }
 
-// runListers runs listing operations for all prefixes, bounded by the request
+// runListers runs listing operations for all units, bounded by the request
 // budget. The budget may be shared with other crawlers, in which case the
 // concurrency ceiling is the whole run's rather than this crawler's.
 //
-// The budget lease is retired once every prefix here has been listed, handing
+// The budget lease is retired once every unit here has been listed, handing
 // this crawler's reserved permit back so crawlers still working can draw on the
 // full budget instead of only the permits nobody reserved.
-func (c *Crawler) runListers(ctx context.Context, prefixes []string, out chan<- objectItem) error {
+func (c *Crawler) runListers(ctx context.Context, units []shard.KeyRange, out chan<- objectItem) error {
 	defer c.budget.retire()
 
 	var wg sync.WaitGroup
 	var firstErr error
 	var errOnce sync.Once
 
-	for _, prefix := range prefixes {
+	for _, unit := range units {
 		release, err := c.budget.acquireListSlot(ctx)
 		if err != nil {
 			// Context ended before a slot was available; no slot was taken.
@@ -391,26 +431,28 @@ func (c *Crawler) runListers(ctx context.Context, prefixes []string, out chan<-
 		}
 
 		wg.Add(1)
-		go func(p string, release func()) {
+		go func(u shard.KeyRange, release func()) {
 			defer wg.Done()
 			defer release()
 
-			if err := c.listPrefix(ctx, p, out); err != nil {
+			if err := c.listPrefix(ctx, u, out); err != nil {
 				// Capture first error
 				errOnce.Do(func() {
 					firstErr = err
 				})
 			}
-		}(prefix, release)
+		}(unit, release)
 	}
 
 	wg.Wait()
 	return firstErr
 }
 
-// listPrefix lists all objects with the given prefix and sends them to the channel.
-func (c *Crawler) listPrefix(ctx context.Context, prefix string, out chan<- objectItem) error {
-	var continuationToken string
+// listPrefix lists all objects in the unit's prefix and key range and sends
+// them to the channel.
+func (c *Crawler) listPrefix(ctx context.Context, unit shard.KeyRange, out chan<- objectItem) error {
+	prefix := unit.Prefix
+	opts := unit.ListOptions()
 
 	for {
 		// Check for cancellation
@@ -424,10 +466,7 @@ func (c *Crawler) listPrefix(ctx context.Context, prefix string, out chan<- obje
 		}
 
 		// List a page of objects
-		result, err := c.provider.List(ctx, provider.ListOptions{
-			Prefix:            prefix,
-			ContinuationToken: continuationToken,
-		})
+		result, err := c.provider.List(ctx, opts)
 		if err != nil {
 			// Classify the error
 			if provider.IsAccessDenied(err) {
@@ -452,6 +491,10 @@ func (c *Crawler) listPrefix(ctx context.Context, prefix string, out chan<- obje
 			return err
 		}
 
+		// Providers trim ranges themselves; trimming again keeps wrappers and
+		// providers without range support from leaking keys across units.
+		result = opts.TrimKeyRange(result)
+
 		// Send objects to the matcher channel
 		for _, obj := range result.Objects {
 			c.objectsListed.Add(1)
@@ -467,7 +510,7 @@ func (c *Crawler) listPrefix(ctx context.Context, prefix string, out chan<- obje
 		if !result.IsTruncated || result.ContinuationToken == "" {
 			break
 		}
-		continuationToken = result.ContinuationToken
+		opts.ContinuationToken = result.ContinuationToken
 	}
 
 	return nil
@@ -11,6 +11,7 @@ import (
 	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/shard"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -467,6 +468,34 @@ func TestCrawler_Run_OverridePrefixes(t *testing.T) {
 	assert.Equal(t, []string{"data/2024/"}, summary.Prefixes)
 }
 
+func TestCrawler_Run_KeyRanges(t *testing.T) {
+	p := newMockProvider()
+	p.addObjects("flat/",
+		provider.ObjectSummary{Key: "flat/0a", Size: 1},
+		provider.ObjectSummary{Key: "flat/3f", Size: 2},
+		provider.ObjectSummary{Key: "flat/7c", Size: 4},
+		provider.ObjectSummary{Key: "flat/b2", Size: 8},
+	)
+
+	m, err := match.New(match.Config{Includes: []string{"flat/**"}})
+	require.NoError(t, err)
+
+	w := newMockWriter()
+	ranges := []shard.KeyRange{
+		{Prefix: "flat/", EndBefore: "flat/3f\x00"},
+		{Prefix: "flat/", StartAfter: "flat/3f"},
+	}
+	// The mock ignores the range, so each object must still be emitted once.
+	summary, err := New(p, m, w, "job-123", DefaultConfig()).WithKeyRanges(ranges).Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), summary.ObjectsMatched)
+	assert.Equal(t, int64(15), summary.BytesTotal)
+	assert.Equal(t, []string{"flat/"}, summary.Prefixes)
+	assert.Equal(t, ranges, summary.KeyRanges)
+	assert.Len(t, w.getObjects(), 4)
+}
+
 func TestCrawler_Run_Summary(t *testing.T) {
 	p := newMockProvider()
 	p.addObjects("data/",
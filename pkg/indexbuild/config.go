@@ -61,6 +61,30 @@ type MatchConfig struct {
 	IncludeHidden bool
 }
 
+// ObservationSelector is the recorded form of a build's Match and Filter
+// settings, as stamped into a filtered snapshot's coverage. Patterns are
+// de-duplicated and sorted; Filter is the exact canonical form from
+// match.CompositeFilter.Canonical. A nil selector is the default universe:
+// every listed object that is not hidden.
+type ObservationSelector struct {
+	Includes      []string
+	Excludes      []string
+	IncludeHidden bool
+	Filter        string
+}
+
+func fromSubstrateSelector(in *indexsubstrate.ObservationSelector) *ObservationSelector {
+	if in == nil {
+		return nil
+	}
+	return &ObservationSelector{
+		Includes:      append([]string(nil), in.Includes...),
+		Excludes:      append([]string(nil), in.Excludes...),
+		IncludeHidden: in.IncludeHidden,
+		Filter:        in.Filter,
+	}
+}
+
 // PathConfig holds explicit engine storage locations.
 //
 // JournalDir and SegmentDir should be resolved by the adapter through the
@@ -180,6 +204,28 @@ type Config struct {
 	// it observed. Recovery from such a run must be handed the complete set: an
 	// omitted journal narrows the derived plan and refuses whole-run coverage.
 	MaxJournalLanes int
+	// CrawlKeyRanges splits a single-prefix crawl into sampled lexicographic key
+	// ranges, one lane each, for flat namespaces that have no plan entries to
+	// divide. Experimental.
+	//
+	// It applies only when the run lists exactly one prefix (an unscoped build
+	// or a one-entry CrawlPrefixes plan); the effective range count is the
+	// smallest of this value, MaxJournalLanes, and the crawl concurrency. Each
+	// lane's journal records the whole-run plan and its own key range, and
+	// recovery must be handed the complete set: the ranges must tile the
+	// keyspace or recovery refuses. Zero or one disables it.
+	CrawlKeyRanges int
+	// PrefixWeights, when non-empty, is the expected object count of each
+	// CrawlPrefixes entry, typically the previous run's per-prefix totals.
+	// Experimental.
+	//
+	// Multi-lane planning then bin-packs plan entries by weight, heaviest first
+	// onto the lightest lane, instead of round-robin over the sorted plan, so a
+	// giant partition does not share a lane with other large ones. Entries with
+	// no weight are planned at the mean of the known weights. Weights only
+	// decide lane membership: coverage, journal provenance, and recovery are
+	// unchanged, and Summary.Lanes reports predicted against observed sizes.
+	PrefixWeights map[string]int64
 	// CrawlPrefixes, when supplied, is the exact provider-prefix observation
 	// plan. It lets CLI adapters pass a manifest scope plan into the engine
 	// without making the engine import manifest or command packages. Entries
@@ -190,8 +236,10 @@ type Config struct {
 	// Build refuses before any side effect. When empty the effective plan is the
 	// full base prefix; either way the plan is sealed into the journal as
 	// recovery provenance and prior rows outside the attested plan are retained.
-	// Any durable build additionally rejects a reducing observation selector
-	// (see Match) so the sealed plan is a truthful record of what was observed.
+	// A Match or Filter that narrows the default universe is sealed beside the
+	// plan as the run's observation selector and stamped into the published
+	// coverage, so complete coverage attests every selected object under the
+	// plan. A verified parent published under a different selector is refused.
 	CrawlPrefixes []string
 	// ObservationSinks receive the same observed crawl stream as the durable
 	// journal materializer. This is the library-owned fanout boundary used by
@@ -215,6 +263,17 @@ type Config struct {
 	// Build acquires the same stable authority used by CLI and GC. A supplied
 	// lease remains caller-owned and is never released by Build.
 	Authority *indexcoord.Lease
+	// Resume continues RunID from the lane checkpoint its earlier attempt left in
+	// Paths.JournalDir instead of starting the run. Experimental.
+	//
+	// Lanes whose journals sealed and still verify are reused without listing
+	// them again; every other lane is listed into a fresh journal. The recorded
+	// lane assignment is kept, so concurrency, lane ceilings, and PrefixWeights
+	// may change between attempts, but the crawl plan may not: a changed base
+	// URI, provider, CrawlPrefixes, Match, or Filter refuses with
+	// ErrResumePlanChanged before any listing. A zero RunStartedAt adopts the
+	// recorded run start; any other value must equal it.
+	Resume bool
 
 	RunStartedAt         time.Time
 	CreatedAt            time.Time
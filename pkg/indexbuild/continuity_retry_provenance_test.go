@@ -22,7 +22,6 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
-	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/output"
 	"github.com/3leaps/gonimbus/pkg/provider"
 )
@@ -72,53 +71,6 @@ func TestBuildRefusesNonCanonicalCrawlPlan(t *testing.T) {
 	}
 }
 
-// TestBuildRefusesDurableSelectorReduction proves any durable build (scoped or
-// unscoped) refuses match/filter that would observe fewer objects than its
-// recorded plan attests complete, mirroring the CLI adapter's faithful-coverage
-// gate at the engine seam so a direct-library caller cannot authorize false
-// tombstones by filtering — and so the stamped plan is a truthful record of the
-// observation universe.
-func TestBuildRefusesDurableSelectorReduction(t *testing.T) {
-	ctx := context.Background()
-	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
-	objs := []provider.ObjectSummary{obj("data/siteA/a1.xml", `"a1"`, 1, base)}
-	cases := []struct {
-		name string
-		mut  func(cfg *Config)
-	}{
-		{"excludes", func(cfg *Config) { cfg.Match.Excludes = []string{"**/*.tmp"} }},
-		{"include_hidden", func(cfg *Config) { cfg.Match.IncludeHidden = true }},
-		{"non-default include", func(cfg *Config) { cfg.Match.Includes = []string{"data/siteA/**"} }},
-		{"post-list filter", func(cfg *Config) { cfg.Filter = &match.CompositeFilter{} }},
-	}
-	for _, scoped := range []bool{true, false} {
-		for _, tc := range cases {
-			name := tc.name
-			if scoped {
-				name = "scoped/" + name
-			} else {
-				name = "unscoped/" + name
-			}
-			t.Run(name, func(t *testing.T) {
-				setRoot := t.TempDir()
-				plan := []string{"data/siteA/"}
-				if !scoped {
-					plan = nil
-				}
-				cfg, prov := scopedCountingConfig(setRoot, "run1", objs, plan, base)
-				if !scoped {
-					// Unscoped: matcher-derived, base coverage.
-					cfg.CrawlPrefixes = nil
-					cfg.Coverage = []CoverageAttestation{{Scope: &Scope{Prefix: "data/"}, Basis: CoverageBasisConfirmed, Complete: true}}
-				}
-				tc.mut(&cfg)
-				_, err := NewRunner(cfg).Build(ctx)
-				requireRefusedBeforeSideEffects(t, cfg, prov, err, "durable build")
-			})
-		}
-	}
-}
-
 // TestBuildRefusesIneligibleCoverageForCrawlPlan proves exact-prefix but
 // ineligible coverage (inferred / incomplete / gapped) refuses before the crawl
 // and journal, not after — the faithful-attestation boundary is pre-side-effect.
@@ -138,7 +138,7 @@ func TestRetryWithLeaseRefusesInconsistentParentPlan(t *testing.T) {
 				BaseURI:      "s3://bucket/data/",
 				Paths:        childPaths,
 				JournalPaths: []string{filepath.Join(setRoot, "runs", "run2", "journals", "shard-0001.jsonl")},
-			}, plan, authority, lease)
+			}, plan, authority, lease, nil)
 			require.Error(t, err)
 			require.ErrorIs(t, err, indexsubstrate.ErrStaleParent)
 
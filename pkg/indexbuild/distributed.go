@@ -0,0 +1,205 @@
+package indexbuild
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/pkg/shard"
+)
+
+// LanePlan is one lane of a run's crawl plan, for callers that crawl lanes on
+// separate hosts. Experimental.
+//
+// A distributed run plans once with PlanLanes, crawls each lane anywhere with
+// CrawlLane, and publishes the complete set of sealed journals with Retry. The
+// journals carry the same lane-local or key-range provenance a multi-lane
+// Build seals, so publication and recovery apply the same coverage rules.
+type LanePlan struct {
+	// Ordinal is the 1-based lane position; it names the lane's journal.
+	Ordinal int
+	// Prefixes is what the lane lists: its own plan entries, or the run's one
+	// prefix for a key-range lane.
+	Prefixes []string
+	// KeyRange narrows a key-range lane to one slice of its prefix.
+	KeyRange *shard.KeyRange
+	// PredictedObjects is the planned size, as in LaneSummary.
+	PredictedObjects int64
+}
+
+// PlanLanes computes the lanes a run would crawl, without crawling.
+//
+// It plans exactly as Build does — weighted or round-robin plan-entry lanes,
+// else key-range lanes, else one whole-plan lane — except that the lane count
+// is bounded only by MaxJournalLanes: each lane is expected to run on its own
+// host, so this host's crawl concurrency is no limit. Key-range planning lists
+// the source to sample it.
+func PlanLanes(ctx context.Context, cfg Config) ([]LanePlan, error) {
+	lanesCfg, err := resolveLaneCrawlConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	lanes, err := planRunLanes(ctx, lanesCfg, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]LanePlan, len(lanes))
+	for i, ln := range lanes {
+		out[i] = LanePlan{
+			Ordinal:          ln.ordinal,
+			Prefixes:         append([]string(nil), ln.prefixes...),
+			PredictedObjects: ln.predictedObjects,
+		}
+		if ln.keyRange != nil {
+			kr := *ln.keyRange
+			out[i].KeyRange = &kr
+		}
+	}
+	return out, nil
+}
+
+// CrawlLane crawls one lane of a PlanLanes plan and seals its journal in
+// journalDir, returning the journal path and the lane's summary.
+//
+// cfg must describe the same run the plan was computed for: the same index
+// set, run ID, base URI, crawl plan, and RunStartedAt, which is required here
+// rather than defaulted so every host seals the same run start. The whole plan is
+// required, not just the one lane, because the provenance mode a journal seals
+// depends on the lane set and the plan is checked to partition the crawl plan
+// before anything is listed. CrawlLane takes no index-set authority and
+// publishes nothing; Paths is not consulted.
+func CrawlLane(ctx context.Context, cfg Config, plan []LanePlan, ordinal int, journalDir string) (string, LaneSummary, error) {
+	if strings.TrimSpace(cfg.RunID) == "" {
+		return "", LaneSummary{}, fmt.Errorf("run_id is required to crawl a planned lane")
+	}
+	if strings.TrimSpace(journalDir) == "" {
+		return "", LaneSummary{}, fmt.Errorf("journal dir is required")
+	}
+	if cfg.RunStartedAt.IsZero() {
+		return "", LaneSummary{}, fmt.Errorf("run_started_at is required to crawl a planned lane")
+	}
+	if err := indexsubstrate.ValidateAuthoritativeRunStartedAt(cfg.RunStartedAt); err != nil {
+		return "", LaneSummary{}, err
+	}
+	cfg.RunStartedAt = cfg.RunStartedAt.UTC()
+	lanesCfg, err := resolveLaneCrawlConfig(cfg)
+	if err != nil {
+		return "", LaneSummary{}, err
+	}
+	lanes, err := lanesFromPlan(lanesCfg, plan)
+	if err != nil {
+		return "", LaneSummary{}, err
+	}
+	idx := sort.Search(len(lanes), func(i int) bool { return lanes[i].ordinal >= ordinal })
+	if idx == len(lanes) || lanes[idx].ordinal != ordinal {
+		return "", LaneSummary{}, fmt.Errorf("lane %d is not in the plan", ordinal)
+	}
+	lanesCfg.build.Paths.JournalDir = journalDir
+	result, err := crawlPlannedLanes(ctx, lanesCfg, []lane{lanes[idx]}, laneCrawlPlanMode(lanes))
+	if err != nil {
+		return "", LaneSummary{}, err
+	}
+	return filepath.Join(journalDir, laneJournalFileName(ordinal)), result.lanes[0], nil
+}
+
+// resolveLaneCrawlConfig applies the Build validation a lane crawl depends on.
+// Publication inputs (paths, coverage, parent) belong to Retry and are not
+// checked here.
+func resolveLaneCrawlConfig(cfg Config) (crawlLanesConfig, error) {
+	if strings.TrimSpace(cfg.IndexSetID) == "" {
+		return crawlLanesConfig{}, fmt.Errorf("index_set_id is required")
+	}
+	if strings.TrimSpace(cfg.BaseURI) == "" {
+		return crawlLanesConfig{}, fmt.Errorf("base_uri is required")
+	}
+	if cfg.Source.Provider == nil {
+		return crawlLanesConfig{}, fmt.Errorf("source provider is required")
+	}
+	if err := validateCrawlPlanCanonical(cfg.CrawlPrefixes); err != nil {
+		return crawlLanesConfig{}, err
+	}
+	if cfg.CrawlKeyRanges < 0 || cfg.CrawlKeyRanges > MaxJournalLanesCeiling {
+		return crawlLanesConfig{}, fmt.Errorf("crawl key ranges must be between 0 and %d, got %d", MaxJournalLanesCeiling, cfg.CrawlKeyRanges)
+	}
+	for prefix, weight := range cfg.PrefixWeights {
+		if weight < 0 {
+			return crawlLanesConfig{}, fmt.Errorf("prefix weight for %q must not be negative, got %d", prefix, weight)
+		}
+	}
+	return resolveCrawlLanesConfig(cfg)
+}
+
+// lanesFromPlan admits a caller-held plan only if it is one PlanLanes could
+// have produced for this configuration: ordinals 1..N, and lanes that either
+// partition the crawl plan or tile its single prefix by key range.
+func lanesFromPlan(cfg crawlLanesConfig, plan []LanePlan) ([]lane, error) {
+	if len(plan) == 0 {
+		return nil, fmt.Errorf("lane plan is empty")
+	}
+	if len(plan) > MaxJournalLanesCeiling {
+		return nil, fmt.Errorf("lane plan has %d lanes; at most %d are supported", len(plan), MaxJournalLanesCeiling)
+	}
+	lanes := make([]lane, len(plan))
+	for i, lp := range plan {
+		lanes[i] = lane{ordinal: lp.Ordinal, prefixes: append([]string(nil), lp.Prefixes...), predictedObjects: lp.PredictedObjects}
+		if lp.KeyRange != nil {
+			kr := *lp.KeyRange
+			lanes[i].keyRange = &kr
+		}
+	}
+	sort.Slice(lanes, func(a, b int) bool { return lanes[a].ordinal < lanes[b].ordinal })
+	for i, ln := range lanes {
+		if ln.ordinal != i+1 {
+			return nil, fmt.Errorf("lane plan ordinals must run 1..%d", len(lanes))
+		}
+		if (ln.keyRange != nil) != (lanes[0].keyRange != nil) {
+			return nil, fmt.Errorf("lane plan mixes key-range and plan-entry lanes")
+		}
+	}
+
+	runPlan := crawlPlanSetKey(cfg.prefixes)
+	if lanes[0].keyRange != nil {
+		if len(lanes) < 2 {
+			return nil, fmt.Errorf("a key-range lane plan needs at least two lanes")
+		}
+		ranges := make([]shard.KeyRange, len(lanes))
+		for i, ln := range lanes {
+			if len(cfg.prefixes) != 1 || crawlPlanSetKey(ln.prefixes) != runPlan {
+				return nil, fmt.Errorf("key-range lane %d does not list the run's single prefix", ln.ordinal)
+			}
+			ranges[i] = shard.KeyRange{StartAfter: ln.keyRange.StartAfter, EndBefore: ln.keyRange.EndBefore}
+			lanes[i].keyRange.Prefix = cfg.prefixes[0]
+		}
+		sort.Slice(ranges, func(a, b int) bool { return ranges[a].StartAfter < ranges[b].StartAfter })
+		if err := shard.ValidateKeyRanges(ranges); err != nil {
+			return nil, fmt.Errorf("key-range lanes do not tile the crawl plan: %w", err)
+		}
+		return lanes, nil
+	}
+	if len(lanes) == 1 {
+		if crawlPlanSetKey(lanes[0].prefixes) != runPlan {
+			return nil, fmt.Errorf("single-lane plan does not list the whole crawl plan")
+		}
+		return lanes, nil
+	}
+	if len(cfg.build.CrawlPrefixes) < 2 {
+		return nil, fmt.Errorf("plan-entry lanes need an explicit multi-entry crawl plan")
+	}
+	var union []string
+	for _, ln := range lanes {
+		if len(ln.prefixes) == 0 {
+			return nil, fmt.Errorf("lane %d has no plan entries", ln.ordinal)
+		}
+		union = append(union, ln.prefixes...)
+	}
+	if err := validateJournalPlanCanonical(union); err != nil {
+		return nil, fmt.Errorf("%w: lane plans must be disjoint", err)
+	}
+	if crawlPlanSetKey(union) != runPlan {
+		return nil, fmt.Errorf("lane plans do not partition the crawl plan")
+	}
+	return lanes, nil
+}
@@ -0,0 +1,107 @@
+package indexbuild
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+)
+
+// TestCrawlLanePerHostPublishesThroughRetry crawls each planned lane into its
+// own directory, as separate hosts would, and publishes the gathered set.
+func TestCrawlLanePerHostPublishesThroughRetry(t *testing.T) {
+	cfg := laneTestConfig(t, "distributed", laneSitePrefixes(5))
+	cfg.MaxJournalLanes = 3
+	cfg.Crawl.Concurrency = 1
+
+	plan, err := PlanLanes(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, plan, 3, "local crawl concurrency does not bound distributed lanes")
+
+	journalPaths := make([]string, 0, len(plan))
+	var observed int64
+	for _, lp := range plan {
+		dir := filepath.Join(t.TempDir(), "host")
+		path, lane, err := CrawlLane(context.Background(), cfg, plan, lp.Ordinal, dir)
+		require.NoError(t, err)
+		require.Equal(t, lp.Ordinal, lane.Ordinal)
+		require.Equal(t, int64(2*len(lp.Prefixes)), lane.ObservedObjects)
+		observed += lane.ObservedObjects
+		journalPaths = append(journalPaths, path)
+	}
+	require.Equal(t, int64(10), observed)
+	for _, h := range readSealedJournalsAt(t, journalPaths) {
+		require.Equal(t, indexsubstrate.CrawlPlanModeLaneLocal, h.CrawlPlanMode)
+	}
+
+	summary, err := Retry(context.Background(), RetryConfig{
+		IndexSetID:   cfg.IndexSetID,
+		RunID:        cfg.RunID,
+		BaseURI:      cfg.BaseURI,
+		Paths:        cfg.Paths,
+		JournalPaths: journalPaths,
+		Coverage:     cfg.Coverage,
+		RunStartedAt: cfg.RunStartedAt,
+		CreatedAt:    cfg.CreatedAt,
+		Clock:        cfg.Clock,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 10, summary.Manifest.ActiveRows)
+
+	// Publication still refuses an incomplete set.
+	cfg2 := laneTestConfig(t, "distributed-partial", laneSitePrefixes(5))
+	_, err = Retry(context.Background(), RetryConfig{
+		IndexSetID:   cfg2.IndexSetID,
+		RunID:        cfg2.RunID,
+		BaseURI:      cfg2.BaseURI,
+		Paths:        cfg2.Paths,
+		JournalPaths: journalPaths[:2],
+		Coverage:     cfg2.Coverage,
+		RunStartedAt: cfg2.RunStartedAt,
+		CreatedAt:    cfg2.CreatedAt,
+		Clock:        cfg2.Clock,
+	})
+	require.Error(t, err)
+}
+
+func TestCrawlLaneRefusesPlanThatDoesNotPartitionTheRun(t *testing.T) {
+	cfg := laneTestConfig(t, "distributed-bad-plan", laneSitePrefixes(3))
+	dir := t.TempDir()
+
+	_, _, err := CrawlLane(context.Background(), cfg, []LanePlan{
+		{Ordinal: 1, Prefixes: []string{"data/siteA/"}},
+		{Ordinal: 2, Prefixes: []string{"data/siteB/"}},
+	}, 1, dir)
+	require.ErrorContains(t, err, "do not partition")
+
+	_, _, err = CrawlLane(context.Background(), cfg, []LanePlan{
+		{Ordinal: 1, Prefixes: []string{"data/siteA/", "data/siteB/"}},
+		{Ordinal: 2, Prefixes: []string{"data/siteB/", "data/siteC/"}},
+	}, 1, dir)
+	require.ErrorContains(t, err, "disjoint")
+
+	_, _, err = CrawlLane(context.Background(), cfg, []LanePlan{
+		{Ordinal: 1, Prefixes: []string{"data/siteA/", "data/siteB/"}},
+		{Ordinal: 3, Prefixes: []string{"data/siteC/"}},
+	}, 1, dir)
+	require.ErrorContains(t, err, "ordinals")
+
+	plan, err := PlanLanes(context.Background(), cfg)
+	require.NoError(t, err)
+	_, _, err = CrawlLane(context.Background(), cfg, plan, 9, dir)
+	require.ErrorContains(t, err, "not in the plan")
+}
+
+func readSealedJournalsAt(t *testing.T, paths []string) []indexsubstrate.JournalHeader {
+	t.Helper()
+	headers := make([]indexsubstrate.JournalHeader, 0, len(paths))
+	for _, path := range paths {
+		summary, err := indexsubstrate.ValidateJournalBounded(path, indexsubstrate.DefaultSpillMergeBudget().MaxRecordBytes)
+		require.NoError(t, err)
+		headers = append(headers, summary.Header)
+	}
+	return headers
+}
@@ -246,6 +246,32 @@ func TestLaneAdmissionRefusesUnrecognizedMode(t *testing.T) {
 	require.Contains(t, err.Error(), "unrecognized journal crawl plan mode")
 }
 
+// TestLaneAdmissionPairsKeyRangeWithMode pins that a key range is sealed only
+// under key-range provenance, and key-range provenance only with a range.
+func TestLaneAdmissionPairsKeyRangeWithMode(t *testing.T) {
+	base := journalWriterConfig{
+		Path:          filepath.Join(t.TempDir(), "shard-0001.jsonl"),
+		IndexSetID:    "idx_lane",
+		RunID:         "run_lane",
+		StartedAt:     time.Date(2026, 7, 27, 12, 0, 0, 0, time.UTC),
+		BaseURI:       "s3://bucket/data/",
+		BasePrefix:    "data/",
+		CrawlPrefixes: []string{"data/"},
+		LaneOrdinal:   1,
+	}
+
+	noRange := base
+	noRange.CrawlPlanMode = indexsubstrate.CrawlPlanModeKeyRange
+	_, err := newJournalWriter(noRange)
+	require.ErrorContains(t, err, "without a key range")
+
+	strayRange := base
+	strayRange.CrawlPlanMode = indexsubstrate.CrawlPlanModeLaneLocal
+	strayRange.KeyRange = &indexsubstrate.KeyRange{EndBefore: "data/m\x00"}
+	_, err = newJournalWriter(strayRange)
+	require.ErrorContains(t, err, "without key-range crawl plan mode")
+}
+
 // fullLaneCoverage is the legitimate whole-run attestation a recovery caller
 // supplies when it believes it holds the complete journal set.
 func fullLaneCoverage() []CoverageAttestation {
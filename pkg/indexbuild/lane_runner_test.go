@@ -2,6 +2,7 @@ package indexbuild
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -259,8 +260,8 @@ func TestMultiLaneOmittedJournalRefusesWholeRunCoverage(t *testing.T) {
 	require.Contains(t, err.Error(), "not in the crawl prefix plan")
 }
 
-// TestLaneCrawlFailureBlocksPublicationForWholeRun proves seal-all-or-none: one
-// failing lane leaves no published artifact and no sealed journal set.
+// TestLaneCrawlFailureBlocksPublicationForWholeRun proves a failing lane blocks
+// publication for the whole run and leaves its own journal unsealed.
 func TestLaneCrawlFailureBlocksPublicationForWholeRun(t *testing.T) {
 	cfg := laneTestConfig(t, "lanes-fail", laneSitePrefixes(4))
 	cfg.MaxJournalLanes = 4
@@ -274,7 +275,7 @@ func TestLaneCrawlFailureBlocksPublicationForWholeRun(t *testing.T) {
 	require.NoFileExists(t, cfg.Paths.ManifestPath)
 
 	// Every journal that was created must be unsealed: a sealed journal is a
-	// completeness claim, and this run completed nothing.
+	// completeness claim, and no lane of this run completed.
 	entries, err := os.ReadDir(cfg.Paths.JournalDir)
 	require.NoError(t, err)
 	for _, e := range entries {
@@ -640,3 +641,139 @@ func TestSingleLaneObservableStreamUnchanged(t *testing.T) {
 	// The crawler's own terminal record reaches the caller unrewritten.
 	require.Equal(t, output.PhaseComplete, sink.progress[len(sink.progress)-1].Phase)
 }
+
+// keyRangeTestConfig is an unscoped build over a flat prefix: one plan entry,
+// so only key-range lanes can divide it.
+func keyRangeTestConfig(t *testing.T, name string, n int) Config {
+	t.Helper()
+	cfg := testConfig(t, name)
+	base := time.Date(2026, 7, 7, 12, 0, 0, 0, time.UTC)
+	objs := make([]provider.ObjectSummary, 0, n)
+	for i := 0; i < n; i++ {
+		objs = append(objs, provider.ObjectSummary{Key: fmt.Sprintf("data/%02x.bin", i*17), Size: int64(i + 1), ETag: `"e"`, LastModified: base.Add(-time.Hour), StorageClass: "STANDARD"})
+	}
+	cfg.Source = Source{Provider: fakeProvider{objects: objs}, ProviderName: "s3"}
+	cfg.CrawlKeyRanges = 3
+	cfg.MaxJournalLanes = 4
+	cfg.Crawl.Concurrency = 4
+	return cfg
+}
+
+// TestKeyRangeBuildSealsTilingJournals proves a flat single-prefix build splits
+// into key-range lanes whose journals record the whole plan and tile the
+// keyspace, and that recovery accepts only the complete set.
+func TestKeyRangeBuildSealsTilingJournals(t *testing.T) {
+	cfg := keyRangeTestConfig(t, "lanes-key-range", 12)
+
+	summary, err := NewRunner(cfg).Build(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(12), summary.ObjectsObserved)
+
+	headers := readSealedJournals(t, cfg.Paths.JournalDir)
+	require.Len(t, headers, 3)
+	for i, h := range headers {
+		require.Equal(t, indexsubstrate.CrawlPlanModeKeyRange, h.CrawlPlanMode)
+		require.Equal(t, []string{"data/"}, h.CrawlPrefixes, "every key-range journal records the whole plan")
+		require.NotNil(t, h.KeyRange)
+		if i > 0 {
+			require.Equal(t, headers[i-1].KeyRange.EndBefore, h.KeyRange.StartAfter+"\x00")
+		}
+	}
+	require.Empty(t, headers[0].KeyRange.StartAfter)
+	require.Empty(t, headers[2].KeyRange.EndBefore)
+
+	maxRecord := indexsubstrate.DefaultSpillMergeBudget().MaxRecordBytes
+	all := []string{
+		filepath.Join(cfg.Paths.JournalDir, laneJournalFileName(3)),
+		filepath.Join(cfg.Paths.JournalDir, laneJournalFileName(1)),
+		filepath.Join(cfg.Paths.JournalDir, laneJournalFileName(2)),
+	}
+	boundPlan, err := boundCrawlPlanFromJournals(all, maxRecord)
+	require.NoError(t, err, "the complete set binds in any order")
+	require.Equal(t, []string{"data/"}, boundPlan)
+
+	_, err = boundCrawlPlanFromJournals(all[:2], maxRecord)
+	require.ErrorIs(t, err, indexsubstrate.ErrStaleParent, "an omitted key range must refuse, not narrow")
+	require.Contains(t, err.Error(), "do not cover the crawl plan")
+}
+
+// TestKeyRangeLanesRequireSinglePrefixPlan pins that key ranges never combine
+// with plan-entry lanes, and that a negative or oversized count is refused.
+func TestKeyRangeLanesRequireSinglePrefixPlan(t *testing.T) {
+	cfg := laneTestConfig(t, "lanes-key-range-multi", laneSitePrefixes(2))
+	cfg.CrawlKeyRanges = 4
+	cfg.MaxJournalLanes = 4
+	cfg.Crawl.Concurrency = 4
+	_, err := NewRunner(cfg).Build(context.Background())
+	require.NoError(t, err)
+	for _, h := range readSealedJournals(t, cfg.Paths.JournalDir) {
+		require.Equal(t, indexsubstrate.CrawlPlanModeLaneLocal, h.CrawlPlanMode)
+		require.Nil(t, h.KeyRange)
+	}
+
+	bad := keyRangeTestConfig(t, "lanes-key-range-bad", 4)
+	bad.CrawlKeyRanges = MaxJournalLanesCeiling + 1
+	_, err = NewRunner(bad).Build(context.Background())
+	require.ErrorContains(t, err, "crawl key ranges must be between")
+}
+
+// TestWeightedLanesBinPackHeaviestFirst pins the greedy assignment: the giant
+// entry gets a lane to itself where round-robin would pair it with others.
+func TestWeightedLanesBinPackHeaviestFirst(t *testing.T) {
+	plan := laneSitePrefixes(6)
+	weights := map[string]int64{
+		"data/siteA/": 100, "data/siteB/": 10, "data/siteC/": 10,
+		"data/siteD/": 10, "data/siteE/": 10, "data/siteF/": 60,
+	}
+	lanes := planWeightedLanes(plan, weights, 8, 2)
+	require.Len(t, lanes, 2)
+	require.Equal(t, []string{"data/siteA/"}, lanes[0].prefixes)
+	require.Equal(t, int64(100), lanes[0].predictedObjects)
+	require.Equal(t, []string{"data/siteB/", "data/siteC/", "data/siteD/", "data/siteE/", "data/siteF/"}, lanes[1].prefixes)
+	require.Equal(t, int64(100), lanes[1].predictedObjects)
+
+	shuffled := []string{"data/siteF/", "data/siteC/", "data/siteA/", "data/siteE/", "data/siteB/", "data/siteD/"}
+	require.Equal(t, lanes, planWeightedLanes(shuffled, weights, 8, 2), "caller order must not change assignment")
+
+	// An entry with no history is planned at the mean of the known weights.
+	partial := map[string]int64{"data/siteA/": 90, "data/siteB/": 30}
+	lanes = planWeightedLanes(laneSitePrefixes(3), partial, 8, 2)
+	require.Equal(t, []string{"data/siteA/"}, lanes[0].prefixes)
+	require.Equal(t, []string{"data/siteB/", "data/siteC/"}, lanes[1].prefixes)
+	require.Equal(t, int64(90), lanes[1].predictedObjects)
+
+	require.Equal(t, planLanes(plan, 8, 3), planWeightedLanes(plan, map[string]int64{"other/": 5}, 8, 3),
+		"weights that name no plan entry fall back to round-robin")
+}
+
+// TestWeightedBuildReportsPredictedAndObservedLanes proves weights reach lane
+// planning through Config and that the summary carries both sizes per lane.
+func TestWeightedBuildReportsPredictedAndObservedLanes(t *testing.T) {
+	cfg := laneTestConfig(t, "lanes-weighted", laneSitePrefixes(4))
+	cfg.MaxJournalLanes = 2
+	cfg.Crawl.Concurrency = 4
+	cfg.PrefixWeights = map[string]int64{"data/siteA/": 50, "data/siteB/": 20, "data/siteC/": 20, "data/siteD/": 10}
+
+	summary, err := NewRunner(cfg).Build(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []LaneSummary{
+		{Ordinal: 1, PlanEntries: 1, PredictedObjects: 50, ListedObjects: 2, ObservedObjects: 2},
+		{Ordinal: 2, PlanEntries: 3, PredictedObjects: 50, ListedObjects: 6, ObservedObjects: 6},
+	}, summary.Lanes)
+
+	headers := readSealedJournals(t, cfg.Paths.JournalDir)
+	require.Len(t, headers, 2)
+	require.Equal(t, []string{"data/siteA/"}, headers[0].CrawlPrefixes)
+
+	single := laneTestConfig(t, "lanes-weighted-single", laneSitePrefixes(2))
+	single.MaxJournalLanes = 1
+	single.PrefixWeights = map[string]int64{"data/siteA/": 5}
+	summary, err = NewRunner(single).Build(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, summary.Lanes, "a single-lane run reports no lanes")
+
+	bad := laneTestConfig(t, "lanes-weighted-bad", laneSitePrefixes(2))
+	bad.PrefixWeights = map[string]int64{"data/siteA/": -1}
+	_, err = NewRunner(bad).Build(context.Background())
+	require.ErrorContains(t, err, "must not be negative")
+}
@@ -8,10 +8,15 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
 	"github.com/3leaps/gonimbus/pkg/crawler"
 	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider"
+	"github.com/3leaps/gonimbus/pkg/shard"
 )
 
 const (
@@ -34,6 +39,11 @@ type lane struct {
 	// as the plan it attests. They are the same slice deliberately: the lane's
 	// coverage claim cannot drift from the work it actually did.
 	prefixes []string
+	// keyRange, when set, narrows the lane to one key slice of its single
+	// prefix. Such a lane attests the whole-run plan under key-range provenance.
+	keyRange *shard.KeyRange
+	// predictedObjects is the size the plan expected this lane to observe.
+	predictedObjects int64
 }
 
 // planLanes maps a crawl plan onto lanes.
@@ -51,34 +61,133 @@ type lane struct {
 // spreads lexicographically adjacent prefixes — which tend to correlate in
 // population — across lanes rather than concentrating that skew in one.
 func planLanes(crawlPrefixes []string, crawlConcurrency, maxLanes int) []lane {
-	if len(crawlPrefixes) < 2 {
+	effective := effectiveLaneCount(len(crawlPrefixes), crawlConcurrency, maxLanes)
+	if effective < 2 {
 		return nil
 	}
-	effective := len(crawlPrefixes)
-	if crawlConcurrency > 0 && crawlConcurrency < effective {
-		effective = crawlConcurrency
+
+	assignment := append([]string(nil), crawlPrefixes...)
+	sort.Strings(assignment)
+
+	lanes := make([]lane, effective)
+	for i := range lanes {
+		lanes[i].ordinal = i + 1
 	}
-	if maxLanes < effective {
-		effective = maxLanes
+	for i, prefix := range assignment {
+		target := i % effective
+		lanes[target].prefixes = append(lanes[target].prefixes, prefix)
 	}
+	return lanes
+}
+
+// planWeightedLanes maps a crawl plan onto lanes by expected size.
+//
+// It is the longest-processing-time greedy: entries are taken heaviest first
+// and each goes to the lane with the least predicted weight so far, ties to the
+// lane with fewer entries and then the lowest ordinal, so zero-weight entries
+// still spread. The order is fully determined by weight then prefix, so
+// assignment is as reproducible as planLanes. Each lane's prefixes are sorted
+// afterwards, matching the order round-robin produces. Entries with no weight
+// are planned at the mean of the known weights; with no usable weights at all
+// this is planLanes.
+func planWeightedLanes(crawlPrefixes []string, weights map[string]int64, crawlConcurrency, maxLanes int) []lane {
+	effective := effectiveLaneCount(len(crawlPrefixes), crawlConcurrency, maxLanes)
 	if effective < 2 {
 		return nil
 	}
+	var known, total int64
+	for _, prefix := range crawlPrefixes {
+		if w, ok := weights[prefix]; ok {
+			known++
+			total += w
+		}
+	}
+	if known == 0 {
+		return planLanes(crawlPrefixes, crawlConcurrency, maxLanes)
+	}
+	fallback := total / known
+	weightOf := func(prefix string) int64 {
+		if w, ok := weights[prefix]; ok {
+			return w
+		}
+		return fallback
+	}
 
 	assignment := append([]string(nil), crawlPrefixes...)
-	sort.Strings(assignment)
+	sort.Slice(assignment, func(i, j int) bool {
+		wi, wj := weightOf(assignment[i]), weightOf(assignment[j])
+		if wi != wj {
+			return wi > wj
+		}
+		return assignment[i] < assignment[j]
+	})
 
 	lanes := make([]lane, effective)
 	for i := range lanes {
 		lanes[i].ordinal = i + 1
 	}
-	for i, prefix := range assignment {
-		target := i % effective
+	for _, prefix := range assignment {
+		target := 0
+		for i := 1; i < len(lanes); i++ {
+			li, lt := lanes[i], lanes[target]
+			if li.predictedObjects < lt.predictedObjects ||
+				(li.predictedObjects == lt.predictedObjects && len(li.prefixes) < len(lt.prefixes)) {
+				target = i
+			}
+		}
 		lanes[target].prefixes = append(lanes[target].prefixes, prefix)
+		lanes[target].predictedObjects += weightOf(prefix)
+	}
+	for i := range lanes {
+		sort.Strings(lanes[i].prefixes)
 	}
 	return lanes
 }
 
+// effectiveLaneCount caps a plan of n entries by crawl concurrency and the lane
+// ceiling.
+func effectiveLaneCount(n, crawlConcurrency, maxLanes int) int {
+	if n < 2 {
+		return 0
+	}
+	effective := n
+	if crawlConcurrency > 0 && crawlConcurrency < effective {
+		effective = crawlConcurrency
+	}
+	if maxLanes < effective {
+		effective = maxLanes
+	}
+	return effective
+}
+
+// planKeyRangeLanes splits a single-prefix run into key-range lanes.
+//
+// The range count is capped like planLanes caps lanes, by crawl concurrency
+// and the lane ceiling. A split that yields fewer than two ranges — a prefix
+// small enough to list in one page, say — returns nil and the run stays
+// single-lane.
+func planKeyRangeLanes(ctx context.Context, p provider.Provider, prefixes []string, keyRanges, crawlConcurrency, maxLanes int) ([]lane, error) {
+	if len(prefixes) != 1 {
+		return nil, nil
+	}
+	effective := effectiveLaneCount(keyRanges, crawlConcurrency, maxLanes)
+	if effective < 2 {
+		return nil, nil
+	}
+	ranges, err := shard.SplitKeyRange(ctx, p, prefixes[0], shard.RangeConfig{Ranges: effective, ListConcurrency: crawlConcurrency})
+	if err != nil {
+		return nil, fmt.Errorf("split crawl into key ranges: %w", err)
+	}
+	if len(ranges) < 2 {
+		return nil, nil
+	}
+	lanes := make([]lane, len(ranges))
+	for i := range ranges {
+		lanes[i] = lane{ordinal: i + 1, prefixes: prefixes, keyRange: &ranges[i], predictedObjects: ranges[i].EstimatedObjects}
+	}
+	return lanes, nil
+}
+
 // resolveMaxJournalLanes turns the public control into an effective ceiling.
 // Zero selects the resolved default and one is the compatibility setting; a
 // value above the engine ceiling is refused rather than clamped, so an operator
@@ -399,6 +508,62 @@ type crawlLanesConfig struct {
 	maxLanes    int
 	journalPlan []string
 	prefixes    []string
+	// selector is the observation selector sealed into every lane's journal.
+	selector *indexsubstrate.ObservationSelector
+	// checkpoint records lane completion for resume; nil for a lane crawled on
+	// behalf of a distributed run, whose coordinator tracks completion itself.
+	checkpoint *laneCheckpoint
+}
+
+// resolveCrawlLanesConfig derives the crawl inputs every lane shares from a
+// normalized Config.
+func resolveCrawlLanesConfig(cfg Config) (crawlLanesConfig, error) {
+	basePrefix, err := basePrefixFromURI(cfg.BaseURI)
+	if err != nil {
+		return crawlLanesConfig{}, err
+	}
+	matcher, err := buildMatcher(basePrefix, cfg.Match)
+	if err != nil {
+		return crawlLanesConfig{}, err
+	}
+	// The sealed journal records the canonical observation plan so a later
+	// recovery re-publish can bind its coverage authority to what was actually
+	// crawled (scoped plan, or the full base prefix when unscoped).
+	journalPlan, err := journalCrawlPlan(basePrefix, cfg.CrawlPrefixes)
+	if err != nil {
+		return crawlLanesConfig{}, err
+	}
+	crawlCfg := cfg.Crawl
+	if crawlCfg.Concurrency <= 0 {
+		crawlCfg.Concurrency = crawler.DefaultConfig().Concurrency
+	}
+	if crawlCfg.ChannelBuffer <= 0 {
+		crawlCfg.ChannelBuffer = crawler.DefaultConfig().ChannelBuffer
+	}
+	if crawlCfg.ProgressEvery <= 0 {
+		crawlCfg.ProgressEvery = crawler.DefaultConfig().ProgressEvery
+	}
+	maxLanes, err := resolveMaxJournalLanes(cfg.MaxJournalLanes)
+	if err != nil {
+		return crawlLanesConfig{}, err
+	}
+	prefixes := append([]string(nil), cfg.CrawlPrefixes...)
+	if len(prefixes) == 0 {
+		prefixes = matcher.Prefixes()
+	}
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	return crawlLanesConfig{
+		build:       cfg,
+		basePrefix:  basePrefix,
+		matcher:     matcher,
+		crawl:       crawlCfg,
+		maxLanes:    maxLanes,
+		journalPlan: journalPlan,
+		prefixes:    prefixes,
+		selector:    observationSelector(cfg),
+	}, nil
 }
 
 // crawlLanesResult is what publication needs from a completed crawl.
@@ -408,11 +573,21 @@ type crawlLanesResult struct {
 	// cross-journal conflicts in.
 	journalPaths    []string
 	objectsObserved int64
+	// objectsListed counts every object LIST returned, before the selector, so
+	// a filtered run's observed count can be reconciled with the listing.
+	objectsListed   int64
 	prefixesCrawled []string
+	lanes           []LaneSummary
+	// sealedLanes counts the run's lanes with a sealed journal, including on
+	// failure, when it is what makes the run resumable.
+	sealedLanes int
+	// reusedLanes counts lanes a resumed run took from its lane checkpoint.
+	reusedLanes int
 }
 
 // runCrawlLanes crawls the plan across one or more journal-writing lanes and
-// seals them.
+// seals them. A resuming run continues from its lane checkpoint instead of
+// planning afresh.
 //
 // Publication is all-or-nothing across lanes: a run publishes only when every
 // admitted lane completed, observed no errors, and sealed. A partially sealed
@@ -420,13 +595,50 @@ type crawlLanesResult struct {
 // than the run's coverage claims, which is exactly the authority gap lane-local
 // provenance exists to close.
 func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult, error) {
-	lanes := planLanes(cfg.build.CrawlPrefixes, cfg.crawl.Concurrency, cfg.maxLanes)
+	if cfg.checkpoint != nil {
+		return resumeCrawlLanes(ctx, cfg)
+	}
+	lanes, err := planRunLanes(ctx, cfg, cfg.crawl.Concurrency)
+	if err != nil {
+		return crawlLanesResult{}, err
+	}
+	mode := laneCrawlPlanMode(lanes)
+	cfg.checkpoint, err = createLaneCheckpoint(cfg, lanes, mode)
+	if err != nil {
+		return crawlLanesResult{}, err
+	}
+	return crawlPlannedLanes(ctx, cfg, lanes, mode)
+}
+
+// planRunLanes chooses the run's lanes: plan-entry lanes (weighted when the
+// caller supplied weights), else key-range lanes, else one lane attesting the
+// whole plan. crawlConcurrency caps the lane count as well as the ceiling;
+// zero leaves only the ceiling.
+func planRunLanes(ctx context.Context, cfg crawlLanesConfig, crawlConcurrency int) ([]lane, error) {
+	var lanes []lane
+	if len(cfg.build.PrefixWeights) > 0 {
+		lanes = planWeightedLanes(cfg.build.CrawlPrefixes, cfg.build.PrefixWeights, crawlConcurrency, cfg.maxLanes)
+	} else {
+		lanes = planLanes(cfg.build.CrawlPrefixes, crawlConcurrency, cfg.maxLanes)
+	}
+	if len(lanes) == 0 {
+		var err error
+		lanes, err = planKeyRangeLanes(ctx, cfg.build.Source.Provider, cfg.prefixes, cfg.build.CrawlKeyRanges, crawlConcurrency, cfg.maxLanes)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if len(lanes) == 0 {
 		// One lane attesting the whole run plan: the pre-lane form, and the shape
 		// an unscoped or matcher-derived build always takes.
 		lanes = []lane{{ordinal: 1, prefixes: cfg.prefixes}}
 	}
-	mode := laneCrawlPlanMode(len(lanes))
+	return lanes, nil
+}
+
+// crawlPlannedLanes crawls lanes already planned and seals their journals under
+// the given provenance mode.
+func crawlPlannedLanes(ctx context.Context, cfg crawlLanesConfig, lanes []lane, mode string) (crawlLanesResult, error) {
 	shared := newSharedObservationSinks(cfg.build.ObservationSinks, len(lanes) > 1)
 	// Lane crawlers report errors through one caller-supplied sink concurrently;
 	// serialize that delivery rather than widening the sink's contract.
@@ -443,12 +655,16 @@ func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult,
 	// peers began listing could find nothing left to reserve.
 	for _, ln := range lanes {
 		journalPlan := ln.prefixes
-		if mode == "" {
+		var keyRange *indexsubstrate.KeyRange
+		if mode != indexsubstrate.CrawlPlanModeLaneLocal {
 			// The single-lane journal records the run's canonical plan, which for an
 			// unscoped build is the synthesized base-prefix stamp rather than the
-			// listing prefixes.
+			// listing prefixes. Key-range lanes record it too, beside their range.
 			journalPlan = cfg.journalPlan
 		}
+		if ln.keyRange != nil {
+			keyRange = &indexsubstrate.KeyRange{StartAfter: ln.keyRange.StartAfter, EndBefore: ln.keyRange.EndBefore}
+		}
 		path := filepath.Join(cfg.build.Paths.JournalDir, laneJournalFileName(ln.ordinal))
 		w, err := newJournalWriter(journalWriterConfig{
 			Path:          path,
@@ -459,6 +675,8 @@ func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult,
 			BasePrefix:    cfg.basePrefix,
 			CrawlPrefixes: journalPlan,
 			CrawlPlanMode: mode,
+			KeyRange:      keyRange,
+			Selector:      cfg.selector,
 			LaneOrdinal:   ln.ordinal,
 			Now:           cfg.build.Clock,
 			Events:        events,
@@ -474,6 +692,9 @@ func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult,
 		c := crawler.New(cfg.build.Source.Provider, cfg.matcher, laneWriter, cfg.build.RunID, cfg.crawl).
 			WithRequestBudget(budget).
 			WithPrefixes(ln.prefixes)
+		if ln.keyRange != nil {
+			c = c.WithKeyRanges([]shard.KeyRange{*ln.keyRange})
+		}
 		if cfg.build.Filter != nil {
 			c = c.WithFilter(cfg.build.Filter)
 		}
@@ -490,12 +711,27 @@ func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult,
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			summaries[i], errs[i] = crawlers[i].Run(ctx)
+			laneCtx, span := tracer.Start(ctx, "indexbuild.crawl_lane", trace.WithAttributes(
+				attribute.Int("gonimbus.index.lane", lanes[i].ordinal),
+				attribute.Int("gonimbus.index.lane_prefixes", len(lanes[i].prefixes)),
+				attribute.Int64("gonimbus.index.lane_predicted_objects", lanes[i].predictedObjects),
+			))
+			summaries[i], errs[i] = crawlers[i].Run(laneCtx)
+			if summaries[i] != nil {
+				span.SetAttributes(attribute.Int64("gonimbus.index.objects_listed", summaries[i].ObjectsListed))
+			}
+			endSpan(span, errs[i])
 		}(i)
 	}
 	wg.Wait()
 	runWall := time.Since(runStart)
 
+	// Seal each lane that completed cleanly, even when a peer did not. A sealed
+	// lane journal attests only its own assignment, so it cannot stand in for a
+	// peer's coverage, and a resumed run reuses it instead of listing it again.
+	// Publication below still requires every lane.
+	sealed, sealErr := sealCompleteLanes(cfg, lanes, writers, summaries, errs)
+
 	// A failure in any lane blocks publication for the whole run, not just its own
 	// journal.
 	var crawlErr error
@@ -513,13 +749,16 @@ func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult,
 			RunID:   cfg.build.RunID,
 			Message: crawlErr.Error(),
 		})
+		if closeErr == nil {
+			closeErr = sealErr
+		}
 		if closeErr == nil {
 			closeErr = sharedCloseErr
 		}
 		if closeErr != nil {
-			return crawlLanesResult{}, fmt.Errorf("crawl failed: %w; close journal: %v", crawlErr, closeErr)
+			return crawlLanesResult{sealedLanes: sealed}, fmt.Errorf("crawl failed: %w; close journal: %v", crawlErr, closeErr)
 		}
-		return crawlLanesResult{}, fmt.Errorf("crawl failed: %w", crawlErr)
+		return crawlLanesResult{sealedLanes: sealed}, fmt.Errorf("crawl failed: %w", crawlErr)
 	}
 
 	var observedErrors int64
@@ -530,52 +769,110 @@ func runCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult,
 	}
 	if observedErrors > 0 {
 		closeErr := closeLaneWriters(writers)
+		if closeErr == nil {
+			closeErr = sealErr
+		}
 		if sharedErr := shared.close(); closeErr == nil {
 			closeErr = sharedErr
 		}
 		if closeErr != nil {
-			return crawlLanesResult{}, closeErr
+			return crawlLanesResult{sealedLanes: sealed}, closeErr
 		}
-		return crawlLanesResult{}, fmt.Errorf("crawl completed with %d errors; snapshot not published", observedErrors)
+		return crawlLanesResult{sealedLanes: sealed}, fmt.Errorf("crawl completed with %d errors; snapshot not published", observedErrors)
 	}
-
-	// Seal every lane, or none: a sealed journal is a completeness claim, so a
-	// lane that could not seal must not leave its peers looking authoritative.
-	for _, w := range writers {
-		if err := w.journal.Seal(); err != nil {
-			_ = closeLaneWriters(writers)
-			_ = shared.close()
-			return crawlLanesResult{}, err
-		}
+	if sealErr != nil {
+		_ = closeLaneWriters(writers)
+		_ = shared.close()
+		return crawlLanesResult{sealedLanes: sealed}, sealErr
 	}
 	if err := closeLaneWriters(writers); err != nil {
 		_ = shared.close()
-		return crawlLanesResult{}, err
+		return crawlLanesResult{sealedLanes: sealed}, err
 	}
 	// The run's single terminal progress record, emitted only now that every lane
 	// is genuinely terminal, so its counts are the run's finals.
 	if err := shared.emitTerminalProgress(ctx); err != nil {
 		_ = shared.close()
-		return crawlLanesResult{}, err
+		return crawlLanesResult{sealedLanes: sealed}, err
 	}
 	if err := shared.emitRunSummary(ctx, runWall); err != nil {
 		_ = shared.close()
-		return crawlLanesResult{}, err
+		return crawlLanesResult{sealedLanes: sealed}, err
 	}
 	if err := shared.close(); err != nil {
-		return crawlLanesResult{}, err
+		return crawlLanesResult{sealedLanes: sealed}, err
 	}
 
-	return crawlLanesResult{
+	result := crawlLanesResult{
 		journalPaths:    journalPaths,
 		objectsObserved: objectsObserved,
+		objectsListed:   listedObjects(summaries),
 		prefixesCrawled: crawledPrefixes(lanes, summaries),
-	}, nil
+		sealedLanes:     sealed,
+	}
+	result.lanes = make([]LaneSummary, len(lanes))
+	for i, ln := range lanes {
+		result.lanes[i] = LaneSummary{
+			Ordinal:          ln.ordinal,
+			PlanEntries:      len(ln.prefixes),
+			PredictedObjects: ln.predictedObjects,
+			ListedObjects:    summaries[i].ObjectsListed,
+			ObservedObjects:  writers[i].journal.ObjectCount(),
+		}
+	}
+	return result, nil
+}
+
+// sealCompleteLanes seals every lane that finished without a crawl error or an
+// observed error and records each in the run's lane checkpoint, when it keeps
+// one. It returns how many lanes sealed and the first seal failure; a lane that
+// could not seal stays unsealed and is listed again on resume.
+func sealCompleteLanes(cfg crawlLanesConfig, lanes []lane, writers []*laneObservationWriter, summaries []*crawler.Summary, errs []error) (int, error) {
+	sealed := 0
+	var firstErr error
+	for i, w := range writers {
+		if errs[i] != nil || w.journal.ErrorCount() > 0 {
+			continue
+		}
+		if err := w.journal.Seal(); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		sealed++
+		if cfg.checkpoint == nil {
+			continue
+		}
+		var listed int64
+		if summaries[i] != nil {
+			listed = summaries[i].ObjectsListed
+		}
+		if err := cfg.checkpoint.markSealed(lanes[i].ordinal, listed, w.journal.ObjectCount()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return sealed, firstErr
+}
+
+// listedObjects sums the objects every lane's LIST returned.
+func listedObjects(summaries []*crawler.Summary) int64 {
+	var n int64
+	for _, s := range summaries {
+		if s != nil {
+			n += s.ObjectsListed
+		}
+	}
+	return n
 }
 
 // crawledPrefixes reports what the run actually listed, in lane-ordinal order,
-// preferring each lane's own reported prefixes over its assignment.
+// preferring each lane's own reported prefixes over its assignment. Key-range
+// lanes all list the same prefix, so it is reported once.
 func crawledPrefixes(lanes []lane, summaries []*crawler.Summary) []string {
+	if len(lanes) > 0 && lanes[0].keyRange != nil {
+		return append([]string(nil), lanes[0].prefixes...)
+	}
 	out := make([]string, 0)
 	for i, ln := range lanes {
 		if i < len(summaries) && summaries[i] != nil && len(summaries[i].Prefixes) > 0 {
@@ -609,10 +906,14 @@ func laneJournalFileName(ordinal int) string {
 //
 // A single-lane run records no mode at all: its journal carries the whole run
 // plan, which is the pre-lane contract, and the header stays byte-identical.
-// Only a genuinely multi-lane run declares lane-local provenance.
-func laneCrawlPlanMode(totalLanes int) string {
-	if totalLanes < 2 {
+// Only a genuinely multi-lane run declares lane-local provenance, or key-range
+// provenance when its lanes split one prefix by key.
+func laneCrawlPlanMode(lanes []lane) string {
+	if len(lanes) < 2 {
 		return ""
 	}
+	if lanes[0].keyRange != nil {
+		return indexsubstrate.CrawlPlanModeKeyRange
+	}
 	return indexsubstrate.CrawlPlanModeLaneLocal
 }
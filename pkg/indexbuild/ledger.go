@@ -0,0 +1,85 @@
+package indexbuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+)
+
+const (
+	ledgerOperationBuild = "build"
+	ledgerOperationRetry = "retry"
+)
+
+// runLedger records one run's lifecycle in the durable run ledger beside the
+// segment set. Complete markers only cover published runs; the ledger is what
+// keeps failed, resumable, and aborted runs visible to stats and tooling.
+type runLedger struct {
+	segmentRoot string
+	entry       indexsubstrate.RunLedgerEntry
+	clock       Clock
+}
+
+// newRunLedger prepares the run's entry. startedAt is the run's authoritative
+// start, so a publish retry keeps the start of the build it recovers.
+func newRunLedger(indexSetID, runID, operation string, paths PathConfig, startedAt time.Time, clock Clock) *runLedger {
+	clock = normalizeClock(clock)
+	if startedAt.IsZero() {
+		startedAt = clock()
+	}
+	return &runLedger{
+		segmentRoot: filepath.Dir(paths.LatestPath),
+		clock:       clock,
+		entry: indexsubstrate.RunLedgerEntry{
+			IndexSetID: indexSetID,
+			RunID:      runID,
+			Operation:  operation,
+			Status:     indexsubstrate.RunLedgerRunning,
+			StartedAt:  startedAt.UTC(),
+		},
+	}
+}
+
+// start records the run as running. A crash before finish leaves it there,
+// which is the honest state: the run began and never reported an outcome.
+func (l *runLedger) start() error {
+	if err := indexsubstrate.WriteRunLedgerEntry(l.segmentRoot, l.entry); err != nil {
+		return fmt.Errorf("record run start: %w", err)
+	}
+	return nil
+}
+
+// finish records the run's outcome and returns runErr with any ledger failure
+// joined. sealedJournals is the number of sealed journals on disk; a failure
+// with any sealed is resumable: once every lane sealed a publish retry needs
+// nothing else, and before that a resumed build reuses the lanes that did.
+//
+// A ledger failure after a successful publish is not reported: latest has
+// advanced and the run's complete marker already records it as published.
+func (l *runLedger) finish(ctx context.Context, runErr error, sealedJournals int) error {
+	ended := l.clock().UTC()
+	entry := l.entry
+	entry.EndedAt = &ended
+	entry.SealedJournals = sealedJournals
+	switch {
+	case runErr == nil:
+		entry.Status = indexsubstrate.RunLedgerSuccess
+	case errors.Is(runErr, context.Canceled) || ctx.Err() != nil:
+		entry.Status = indexsubstrate.RunLedgerAborted
+	case sealedJournals > 0:
+		entry.Status = indexsubstrate.RunLedgerFailedResumable
+	default:
+		entry.Status = indexsubstrate.RunLedgerFailed
+	}
+	if runErr != nil {
+		entry.Reason = sanitizeMessage(runErr.Error())
+	}
+	if err := indexsubstrate.WriteRunLedgerEntry(l.segmentRoot, entry); err != nil && runErr != nil {
+		return errors.Join(runErr, fmt.Errorf("record run outcome: %w", err))
+	}
+	return runErr
+}
@@ -0,0 +1,132 @@
+package indexbuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/pkg/match"
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+func selectorTestFilter(t *testing.T) *match.CompositeFilter {
+	t.Helper()
+	f, err := match.NewFilterFromConfig(&match.FilterConfig{Size: &match.SizeFilterConfig{Min: "10"}})
+	require.NoError(t, err)
+	return f
+}
+
+// TestBuildRecordsObservationSelector proves a durable build accepts excludes,
+// include_hidden, and a metadata filter, journals only the selected objects,
+// and records the selector in the journal header and published coverage while
+// reporting the full listing count for parity.
+func TestBuildRecordsObservationSelector(t *testing.T) {
+	ctx := context.Background()
+	setRoot := t.TempDir()
+	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	objs := []provider.ObjectSummary{
+		obj("data/a.parquet", `"a"`, 100, base),
+		obj("data/small.parquet", `"s"`, 1, base),
+		obj("data/_temporary/t.parquet", `"t"`, 100, base),
+		obj("data/.hidden/h.parquet", `"h"`, 100, base),
+	}
+	cfg := contConfig(setRoot, "run1", objs, base)
+	cfg.Match.Excludes = []string{"**/_temporary/**", "**/_temporary/**"}
+	cfg.Match.IncludeHidden = true
+	cfg.Filter = selectorTestFilter(t)
+
+	sum, err := NewRunner(cfg).Build(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, sum.ObjectsListed)
+	require.EqualValues(t, 2, sum.ObjectsObserved)
+
+	want := &ObservationSelector{Excludes: []string{"**/_temporary/**"}, IncludeHidden: true, Filter: cfg.Filter.Canonical()}
+	require.Equal(t, want, sum.Manifest.Selector)
+
+	manifest, rows, err := ReadLatest(cfg.Paths.LatestPath)
+	require.NoError(t, err)
+	require.Equal(t, want, manifest.Selector)
+	var keys []string
+	for _, r := range rows {
+		keys = append(keys, r.RelKey)
+	}
+	require.ElementsMatch(t, []string{"a.parquet", ".hidden/h.parquet"}, keys)
+
+	journal, err := indexsubstrate.ValidateJournalBounded(sum.JournalPaths[0], indexsubstrate.DefaultSpillMergeBudget().MaxRecordBytes)
+	require.NoError(t, err)
+	require.NotNil(t, journal.Header.Selector)
+	require.Equal(t, want.Excludes, journal.Header.Selector.Excludes)
+
+	// Recovery re-publishes under the sealed selector, not the caller's.
+	_, err = Retry(ctx, retryConfigFor(cfg, sum))
+	require.NoError(t, err)
+	manifest, _, err = ReadLatest(cfg.Paths.LatestPath)
+	require.NoError(t, err)
+	require.Equal(t, want, manifest.Selector)
+}
+
+// TestBuildUnfilteredRecordsNoSelector proves the default universe publishes
+// coverage without a selector, so existing snapshots keep their coverage
+// digests.
+func TestBuildUnfilteredRecordsNoSelector(t *testing.T) {
+	setRoot := t.TempDir()
+	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	cfg := contConfig(setRoot, "run1", []provider.ObjectSummary{obj("data/a", `"a"`, 1, base)}, base)
+	cfg.Filter = &match.CompositeFilter{}
+
+	sum, err := NewRunner(cfg).Build(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, sum.Manifest.Selector)
+	require.Equal(t, sum.ObjectsObserved, sum.ObjectsListed)
+}
+
+// TestBuildRefusesSelectorChangeOverParent proves a build whose selector
+// differs from the verified parent's refuses before listing, so a filtered run
+// can never tombstone rows an unfiltered parent observed (or the reverse).
+func TestBuildRefusesSelectorChangeOverParent(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC)
+	objs := []provider.ObjectSummary{obj("data/siteA/a1.xml", `"a1"`, 1, base)}
+	cases := []struct {
+		name   string
+		parent func(cfg *Config)
+		child  func(cfg *Config)
+	}{
+		{"adds excludes", func(*Config) {}, func(cfg *Config) { cfg.Match.Excludes = []string{"**/*.tmp"} }},
+		{"adds include_hidden", func(*Config) {}, func(cfg *Config) { cfg.Match.IncludeHidden = true }},
+		{"narrows includes", func(*Config) {}, func(cfg *Config) { cfg.Match.Includes = []string{"siteA/**"} }},
+		{"adds filter", func(*Config) {}, func(cfg *Config) { cfg.Filter = selectorTestFilter(t) }},
+		{"drops filter", func(cfg *Config) { cfg.Filter = selectorTestFilter(t) }, func(cfg *Config) { cfg.Filter = nil }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			setRoot := t.TempDir()
+			parent := contConfig(setRoot, "run1", objs, base)
+			tc.parent(&parent)
+			_, err := NewRunner(parent).Build(ctx)
+			require.NoError(t, err)
+
+			child, prov := scopedCountingConfig(setRoot, "run2", objs, nil, base.Add(time.Hour))
+			child.CrawlPrefixes = nil
+			child.Coverage = parent.Coverage
+			tc.parent(&child)
+			tc.child(&child)
+			_, err = NewRunner(child).Build(ctx)
+			require.ErrorContains(t, err, "a differently filtered build must publish a new index set")
+			require.Zero(t, prov.lists.Load(), "refusal must precede any provider crawl")
+			require.NoDirExists(t, child.Paths.JournalDir)
+		})
+	}
+}
+
+func TestIsDefaultObservationIncludesTrimsSpace(t *testing.T) {
+	require.True(t, isDefaultObservationIncludes(nil))
+	require.True(t, isDefaultObservationIncludes([]string{"**"}))
+	require.True(t, isDefaultObservationIncludes([]string{" ** "}))
+	require.True(t, isDefaultObservationIncludes([]string{" "}))
+	require.False(t, isDefaultObservationIncludes([]string{"logs/**"}))
+	require.False(t, isDefaultObservationIncludes([]string{"**", "**"}))
+}
@@ -5,6 +5,10 @@ type Summary struct {
 	RunID           string
 	JournalPaths    []string
 	ObjectsObserved int64
+	// ObjectsListed is every object LIST returned, before the match and filter
+	// selector. It equals ObjectsObserved for an unfiltered build; the
+	// difference is what the selector dropped.
+	ObjectsListed   int64
 	PrefixesCrawled []string
 	// ManifestSHA256 is the digest written into the durable complete marker at
 	// publish time. Prefer this over re-hashing the manifest path after commit.
@@ -14,6 +18,27 @@ type Summary struct {
 	// during the durable merge (0 when nothing spilled or on the SQLite path).
 	// Observational capacity evidence for sizing successive builds.
 	PeakWorkspaceBytes int64
+	// Lanes describes each journal lane of a multi-lane run in ordinal order;
+	// nil for a single-lane run.
+	Lanes []LaneSummary
+	// ReusedLanes is how many lanes a resumed build took from sealed journals of
+	// its earlier attempt instead of listing them again. Experimental.
+	ReusedLanes int
+}
+
+// LaneSummary compares a lane's planned size with what it observed.
+type LaneSummary struct {
+	Ordinal int
+	// PlanEntries is the number of crawl-plan prefixes the lane listed.
+	PlanEntries int
+	// PredictedObjects is the planned size: the summed PrefixWeights of its plan
+	// entries, or the sampled estimate of a key-range lane. Zero when the run
+	// was planned without weights.
+	PredictedObjects int64
+	// ListedObjects is what the lane's LIST returned; ObservedObjects is what
+	// its selector kept and journaled.
+	ListedObjects   int64
+	ObservedObjects int64
 }
 
 type ManifestSummary struct {
@@ -22,6 +47,9 @@ type ManifestSummary struct {
 	Tombstones    int
 	DistinctETags int
 	Segments      []SegmentSummary
+	// Selector is the observation selector the snapshot's coverage records,
+	// nil for an unfiltered snapshot.
+	Selector *ObservationSelector
 }
 
 type SegmentSummary struct {
@@ -0,0 +1,492 @@
+package indexbuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
+	"github.com/3leaps/gonimbus/pkg/shard"
+)
+
+const (
+	laneCheckpointType     = "gonimbus.index.lane_checkpoint.v1"
+	laneCheckpointFileName = "lanes.json"
+	// resumePlanVersion is the encoding version of the hashed resume plan. A
+	// change to resumePlanPayload must bump it, or a resume comparing digests
+	// across versions reads an encoding change as a plan change.
+	resumePlanVersion = 1
+	// maxLaneCheckpointBytes bounds reads of a lane checkpoint. It carries the
+	// crawl plan, so it is sized like a journal header rather than a marker.
+	maxLaneCheckpointBytes = 64 << 20
+)
+
+// ErrResumePlanChanged reports a resume whose crawl plan no longer matches the
+// plan the run recorded. Experimental.
+//
+// Reusing a sealed lane is only sound when it attests the same work the resumed
+// run would plan: a changed base URI, scope, or selector would publish journals
+// that cover a different universe than the run's coverage claims.
+var ErrResumePlanChanged = errors.New("resume plan changed")
+
+// ResumeState summarizes the lane checkpoint a durable build records in its
+// journal directory. Experimental.
+type ResumeState struct {
+	IndexSetID   string
+	RunID        string
+	RunStartedAt time.Time
+	// PlanDigest identifies the crawl plan the run recorded. A resume is refused
+	// when the resumed configuration plans differently.
+	PlanDigest  string
+	Lanes       int
+	SealedLanes int
+}
+
+// ReadResumeState reads the lane checkpoint of the run whose journals live in
+// journalDir. ok is false when the run recorded none, which is the case for a
+// build that failed before its crawl started.
+func ReadResumeState(journalDir string) (state ResumeState, ok bool, err error) {
+	doc, err := readLaneCheckpoint(journalDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ResumeState{}, false, nil
+		}
+		return ResumeState{}, false, err
+	}
+	return ResumeState{
+		IndexSetID:   doc.IndexSetID,
+		RunID:        doc.RunID,
+		RunStartedAt: doc.RunStartedAt,
+		PlanDigest:   doc.PlanDigest,
+		Lanes:        len(doc.Lanes),
+		SealedLanes:  doc.sealedLanes(),
+	}, true, nil
+}
+
+// laneCheckpointDoc is the on-disk lane checkpoint. It is written before any
+// lane lists and updated as each lane seals, so a crash leaves the lanes that
+// finished marked and the rest not.
+type laneCheckpointDoc struct {
+	Type          string               `json:"type"`
+	IndexSetID    string               `json:"index_set_id"`
+	RunID         string               `json:"run_id"`
+	RunStartedAt  time.Time            `json:"run_started_at"`
+	PlanVersion   int                  `json:"plan_version"`
+	PlanDigest    string               `json:"plan_digest"`
+	CrawlPlanMode string               `json:"crawl_plan_mode,omitempty"`
+	Lanes         []laneCheckpointLane `json:"lanes"`
+}
+
+// laneCheckpointLane records one lane's assignment and whether its journal
+// sealed. The assignment is kept rather than replanned on resume: weighted and
+// key-range planning depend on prior runs and on sampling the source, and a
+// resumed lane must attest exactly the slice its peers were planned around.
+type laneCheckpointLane struct {
+	Ordinal          int                      `json:"ordinal"`
+	Prefixes         []string                 `json:"prefixes"`
+	KeyRange         *indexsubstrate.KeyRange `json:"key_range,omitempty"`
+	PredictedObjects int64                    `json:"predicted_objects,omitempty"`
+	Sealed           bool                     `json:"sealed"`
+	ListedObjects    int64                    `json:"listed_objects,omitempty"`
+	ObservedObjects  int64                    `json:"observed_objects,omitempty"`
+}
+
+func (d laneCheckpointDoc) sealedLanes() int {
+	n := 0
+	for _, ln := range d.Lanes {
+		if ln.Sealed {
+			n++
+		}
+	}
+	return n
+}
+
+// lanes returns the recorded assignment in ordinal order.
+func (d laneCheckpointDoc) lanes() []lane {
+	out := make([]lane, len(d.Lanes))
+	for i, ln := range d.Lanes {
+		out[i] = lane{ordinal: ln.Ordinal, prefixes: append([]string(nil), ln.Prefixes...), predictedObjects: ln.PredictedObjects}
+		if ln.KeyRange != nil {
+			out[i].keyRange = &shard.KeyRange{Prefix: ln.Prefixes[0], StartAfter: ln.KeyRange.StartAfter, EndBefore: ln.KeyRange.EndBefore, EstimatedObjects: ln.PredictedObjects}
+		}
+	}
+	return out
+}
+
+// validate refuses a checkpoint whose lane assignment could not have been
+// written by planRunLanes: ordinals must run 1..n, every lane must own a plan,
+// and key ranges must be all-or-none and agree with the recorded mode.
+func (d laneCheckpointDoc) validate() error {
+	if d.Type != laneCheckpointType {
+		return fmt.Errorf("lane checkpoint type mismatch")
+	}
+	if d.PlanVersion != resumePlanVersion {
+		return fmt.Errorf("lane checkpoint plan version %d is not supported", d.PlanVersion)
+	}
+	if len(d.Lanes) == 0 {
+		return fmt.Errorf("lane checkpoint records no lanes")
+	}
+	if err := indexsubstrate.ValidateAuthoritativeRunStartedAt(d.RunStartedAt); err != nil {
+		return fmt.Errorf("lane checkpoint: %w", err)
+	}
+	keyRanges := 0
+	for i, ln := range d.Lanes {
+		if ln.Ordinal != i+1 {
+			return fmt.Errorf("lane checkpoint lane %d is out of order", ln.Ordinal)
+		}
+		if len(ln.Prefixes) == 0 {
+			return fmt.Errorf("lane checkpoint lane %d has an empty plan", ln.Ordinal)
+		}
+		if ln.KeyRange != nil {
+			keyRanges++
+		}
+	}
+	switch {
+	case d.CrawlPlanMode == indexsubstrate.CrawlPlanModeKeyRange && keyRanges != len(d.Lanes):
+		return fmt.Errorf("lane checkpoint key-range mode requires a key range on every lane")
+	case d.CrawlPlanMode != indexsubstrate.CrawlPlanModeKeyRange && keyRanges != 0:
+		return fmt.Errorf("lane checkpoint has key ranges outside key-range mode")
+	}
+	return nil
+}
+
+// laneCheckpoint is the live checkpoint of one run. Lanes seal concurrently,
+// so updates are serialized and each rewrite replaces the file whole.
+type laneCheckpoint struct {
+	path string
+	mu   sync.Mutex
+	doc  laneCheckpointDoc
+}
+
+// createLaneCheckpoint records a freshly planned run before any lane lists.
+func createLaneCheckpoint(cfg crawlLanesConfig, lanes []lane, mode string) (*laneCheckpoint, error) {
+	digest, err := cfg.planDigest()
+	if err != nil {
+		return nil, err
+	}
+	doc := laneCheckpointDoc{
+		Type:          laneCheckpointType,
+		IndexSetID:    cfg.build.IndexSetID,
+		RunID:         cfg.build.RunID,
+		RunStartedAt:  cfg.build.RunStartedAt,
+		PlanVersion:   resumePlanVersion,
+		PlanDigest:    digest,
+		CrawlPlanMode: mode,
+		Lanes:         make([]laneCheckpointLane, len(lanes)),
+	}
+	for i, ln := range lanes {
+		doc.Lanes[i] = laneCheckpointLane{Ordinal: ln.ordinal, Prefixes: append([]string(nil), ln.prefixes...), PredictedObjects: ln.predictedObjects}
+		if ln.keyRange != nil {
+			doc.Lanes[i].KeyRange = &indexsubstrate.KeyRange{StartAfter: ln.keyRange.StartAfter, EndBefore: ln.keyRange.EndBefore}
+		}
+	}
+	cp := &laneCheckpoint{path: filepath.Join(cfg.build.Paths.JournalDir, laneCheckpointFileName), doc: doc}
+	if err := ensureDir(cfg.build.Paths.JournalDir); err != nil {
+		return nil, err
+	}
+	if err := cp.saveLocked(); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// openResumeCheckpoint loads the checkpoint Build resumes from and binds it to
+// the run. callerStartedAt is the caller's un-defaulted RunStartedAt: zero
+// adopts the recorded start, anything else must equal it, because the sealed
+// journals being reused carry that start in their headers.
+func openResumeCheckpoint(cfg Config, callerStartedAt time.Time) (*laneCheckpoint, error) {
+	doc, err := readLaneCheckpoint(cfg.Paths.JournalDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("run %s recorded no lane checkpoint; it cannot be resumed", cfg.RunID)
+		}
+		return nil, err
+	}
+	if doc.IndexSetID != cfg.IndexSetID || doc.RunID != cfg.RunID {
+		return nil, fmt.Errorf("lane checkpoint belongs to another run")
+	}
+	if !callerStartedAt.IsZero() && !callerStartedAt.Equal(doc.RunStartedAt) {
+		return nil, fmt.Errorf("resume run_started_at differs from the recorded run start")
+	}
+	if _, err := os.Stat(cfg.Paths.CompletePath); err == nil {
+		return nil, fmt.Errorf("run %s is already published; nothing to resume", cfg.RunID)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("check complete marker: %w", err)
+	}
+	return &laneCheckpoint{path: filepath.Join(cfg.Paths.JournalDir, laneCheckpointFileName), doc: doc}, nil
+}
+
+func readLaneCheckpoint(journalDir string) (laneCheckpointDoc, error) {
+	if strings.TrimSpace(journalDir) == "" {
+		return laneCheckpointDoc{}, fmt.Errorf("journal directory is required")
+	}
+	path := filepath.Join(journalDir, laneCheckpointFileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		return laneCheckpointDoc{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return laneCheckpointDoc{}, fmt.Errorf("lane checkpoint is not a regular file")
+	}
+	if info.Size() > maxLaneCheckpointBytes {
+		return laneCheckpointDoc{}, fmt.Errorf("lane checkpoint size %d exceeds limit %d", info.Size(), maxLaneCheckpointBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return laneCheckpointDoc{}, fmt.Errorf("read lane checkpoint: %w", err)
+	}
+	var doc laneCheckpointDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return laneCheckpointDoc{}, fmt.Errorf("parse lane checkpoint: %w", err)
+	}
+	if err := doc.validate(); err != nil {
+		return laneCheckpointDoc{}, err
+	}
+	return doc, nil
+}
+
+// markSealed records that a lane's journal sealed.
+func (c *laneCheckpoint) markSealed(ordinal int, listed, observed int64) error {
+	return c.update(ordinal, func(ln *laneCheckpointLane) {
+		ln.Sealed = true
+		ln.ListedObjects = listed
+		ln.ObservedObjects = observed
+	})
+}
+
+// markUnsealed withdraws a lane whose sealed journal no longer verifies, so a
+// crash during its relisting does not leave it claimed.
+func (c *laneCheckpoint) markUnsealed(ordinal int) error {
+	return c.update(ordinal, func(ln *laneCheckpointLane) {
+		ln.Sealed = false
+		ln.ListedObjects = 0
+		ln.ObservedObjects = 0
+	})
+}
+
+func (c *laneCheckpoint) update(ordinal int, apply func(*laneCheckpointLane)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.doc.Lanes {
+		if c.doc.Lanes[i].Ordinal == ordinal {
+			apply(&c.doc.Lanes[i])
+			return c.saveLocked()
+		}
+	}
+	return fmt.Errorf("lane %d is not in the lane checkpoint", ordinal)
+}
+
+func (c *laneCheckpoint) saveLocked() error {
+	data, err := json.MarshalIndent(c.doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode lane checkpoint: %w", err)
+	}
+	data = append(data, '\n')
+	temp, err := os.CreateTemp(filepath.Dir(c.path), ".lanes-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("create temporary lane checkpoint: %w", err)
+	}
+	tempPath := temp.Name()
+	defer func() { _ = os.Remove(tempPath) }()
+	if _, err := temp.Write(data); err != nil {
+		_ = temp.Close()
+		return fmt.Errorf("write lane checkpoint: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		_ = temp.Close()
+		return fmt.Errorf("sync lane checkpoint: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("close lane checkpoint: %w", err)
+	}
+	if err := os.Rename(tempPath, c.path); err != nil {
+		return fmt.Errorf("commit lane checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resumePlanPayload is the hashed form of what a run's lanes attest. It is the
+// plan before lane assignment: the recorded assignment is reused on resume,
+// so concurrency, lane ceilings, and prefix weights may change between
+// attempts without invalidating the sealed lanes.
+//
+// The covered Config inputs are exactly:
+//   - IndexSetID, which callers derive from the source identity (base URI,
+//     provider, storage and cloud provider, region, endpoint) and the index
+//     build params;
+//   - BaseURI and Source.ProviderName;
+//   - the journal plan: CrawlPrefixes, or the base prefix when unscoped;
+//   - the listed prefixes: CrawlPrefixes, or the prefixes Match derives;
+//   - the observation selector: Match includes, excludes, and IncludeHidden,
+//     and the canonical Filter, when they narrow the default universe.
+//
+// Everything else is deliberately outside it. Crawl tuning (Concurrency,
+// RateLimit, ChannelBuffer, ProgressEvery), MaxJournalLanes, CrawlKeyRanges, and
+// PrefixWeights only decide how the recorded lanes are listed;
+// TargetRowsPerSegment, Spill, Paths, and the event sinks shape how the run
+// executes and packs, not what it observes.
+type resumePlanPayload struct {
+	Version      int                                 `json:"version"`
+	IndexSetID   string                              `json:"index_set_id"`
+	BaseURI      string                              `json:"base_uri"`
+	ProviderName string                              `json:"provider_name"`
+	JournalPlan  []string                            `json:"journal_plan"`
+	Prefixes     []string                            `json:"prefixes"`
+	Selector     *indexsubstrate.ObservationSelector `json:"selector,omitempty"`
+}
+
+// planDigest hashes the run's crawl plan for resume identity; see
+// resumePlanPayload for the inputs it covers.
+func (c crawlLanesConfig) planDigest() (string, error) {
+	data, err := json.Marshal(resumePlanPayload{
+		Version:      resumePlanVersion,
+		IndexSetID:   c.build.IndexSetID,
+		BaseURI:      c.build.BaseURI,
+		ProviderName: c.build.Source.ProviderName,
+		JournalPlan:  c.journalPlan,
+		Prefixes:     c.prefixes,
+		Selector:     c.selector,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode resume plan: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// admitResumePlan refuses a resume whose configuration plans differently than
+// the run it continues.
+func (c *laneCheckpoint) admitResumePlan(cfg crawlLanesConfig) error {
+	digest, err := cfg.planDigest()
+	if err != nil {
+		return err
+	}
+	if digest != c.doc.PlanDigest {
+		return fmt.Errorf("%w: run %s recorded plan %s, this configuration plans %s; start a new build instead",
+			ErrResumePlanChanged, c.doc.RunID, abbreviateDigest(c.doc.PlanDigest), abbreviateDigest(digest))
+	}
+	return nil
+}
+
+func abbreviateDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+// resumeCrawlLanes continues a run from its lane checkpoint. Lanes whose
+// journals sealed and still verify against their recorded assignment are
+// reused as they are; every other lane is listed again into a fresh journal.
+func resumeCrawlLanes(ctx context.Context, cfg crawlLanesConfig) (crawlLanesResult, error) {
+	cp := cfg.checkpoint
+	lanes := cp.doc.lanes()
+	mode := cp.doc.CrawlPlanMode
+	budget, err := cfg.build.Spill.resolveBudget()
+	if err != nil {
+		return crawlLanesResult{}, err
+	}
+	reused := make(map[int]LaneSummary, len(lanes))
+	pending := make([]lane, 0, len(lanes))
+	for i, ln := range lanes {
+		entry := cp.doc.Lanes[i]
+		if entry.Sealed {
+			observed, err := verifySealedLane(cfg, ln, mode, budget.MaxRecordBytes)
+			if err == nil {
+				reused[ln.ordinal] = LaneSummary{
+					Ordinal:          ln.ordinal,
+					PlanEntries:      len(ln.prefixes),
+					PredictedObjects: ln.predictedObjects,
+					ListedObjects:    entry.ListedObjects,
+					ObservedObjects:  observed,
+				}
+				continue
+			}
+			_ = emitEvent(ctx, cfg.build.Events, Event{
+				Type:    EventTypeCrawlError,
+				RunID:   cfg.build.RunID,
+				Message: fmt.Sprintf("lane %d sealed journal no longer verifies; listing it again: %v", ln.ordinal, err),
+			})
+			if err := cp.markUnsealed(ln.ordinal); err != nil {
+				return crawlLanesResult{sealedLanes: len(reused)}, err
+			}
+		}
+		pending = append(pending, ln)
+	}
+
+	var crawled crawlLanesResult
+	if len(pending) > 0 {
+		crawled, err = crawlPlannedLanes(ctx, cfg, pending, mode)
+		if err != nil {
+			crawled.sealedLanes += len(reused)
+			return crawled, err
+		}
+	} else if err := newSharedObservationSinks(cfg.build.ObservationSinks, false).close(); err != nil {
+		return crawlLanesResult{sealedLanes: len(reused)}, err
+	}
+
+	crawledLanes := make(map[int]LaneSummary, len(crawled.lanes))
+	for _, ls := range crawled.lanes {
+		crawledLanes[ls.Ordinal] = ls
+	}
+	result := crawlLanesResult{
+		journalPaths:    make([]string, 0, len(lanes)),
+		prefixesCrawled: crawledPrefixes(lanes, nil),
+		lanes:           make([]LaneSummary, 0, len(lanes)),
+		sealedLanes:     len(lanes),
+		reusedLanes:     len(reused),
+	}
+	for _, ln := range lanes {
+		ls, ok := reused[ln.ordinal]
+		if !ok {
+			ls = crawledLanes[ln.ordinal]
+		}
+		result.journalPaths = append(result.journalPaths, filepath.Join(cfg.build.Paths.JournalDir, laneJournalFileName(ln.ordinal)))
+		result.lanes = append(result.lanes, ls)
+		result.objectsObserved += ls.ObservedObjects
+		result.objectsListed += ls.ListedObjects
+	}
+	return result, nil
+}
+
+// verifySealedLane checks that a lane's journal is sealed, intact, and attests
+// the lane's recorded assignment for this run, and returns its record count.
+func verifySealedLane(cfg crawlLanesConfig, ln lane, mode string, maxRecordBytes int64) (int64, error) {
+	summary, err := indexsubstrate.ValidateJournalBounded(filepath.Join(cfg.build.Paths.JournalDir, laneJournalFileName(ln.ordinal)), maxRecordBytes)
+	if err != nil {
+		return 0, err
+	}
+	h := summary.Header
+	wantPlan := cfg.journalPlan
+	if mode == indexsubstrate.CrawlPlanModeLaneLocal {
+		wantPlan = ln.prefixes
+	}
+	var wantRange *indexsubstrate.KeyRange
+	if ln.keyRange != nil {
+		wantRange = &indexsubstrate.KeyRange{StartAfter: ln.keyRange.StartAfter, EndBefore: ln.keyRange.EndBefore}
+	}
+	switch {
+	case h.IndexSetID != cfg.build.IndexSetID || h.RunID != cfg.build.RunID:
+		return 0, fmt.Errorf("journal belongs to another run")
+	case h.JournalID != fmt.Sprintf("jrn_%s_%04d", cfg.build.RunID, ln.ordinal):
+		return 0, fmt.Errorf("journal identity does not match lane %d", ln.ordinal)
+	case !h.StartedAt.Equal(cfg.build.RunStartedAt):
+		return 0, fmt.Errorf("journal run start does not match")
+	case h.CrawlPlanMode != mode || !slices.Equal(h.CrawlPrefixes, wantPlan):
+		return 0, fmt.Errorf("journal crawl plan does not match lane %d", ln.ordinal)
+	case (h.KeyRange == nil) != (wantRange == nil) || (wantRange != nil && *h.KeyRange != *wantRange):
+		return 0, fmt.Errorf("journal key range does not match lane %d", ln.ordinal)
+	case h.Selector.String() != cfg.selector.String():
+		return 0, fmt.Errorf("journal selector does not match the run")
+	}
+	return int64(summary.Records), nil
+}
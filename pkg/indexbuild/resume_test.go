@@ -0,0 +1,134 @@
+package indexbuild
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// laneListingProvider fails every LIST under failPrefix and counts the LISTs
+// each prefix receives, so a resume can be shown not to list a reused lane.
+type laneListingProvider struct {
+	fakeProvider
+	failPrefix string
+
+	mu    sync.Mutex
+	lists map[string]int
+}
+
+func (p *laneListingProvider) List(ctx context.Context, opts provider.ListOptions) (*provider.ListResult, error) {
+	p.mu.Lock()
+	if p.lists == nil {
+		p.lists = map[string]int{}
+	}
+	p.lists[opts.Prefix]++
+	p.mu.Unlock()
+	if p.failPrefix != "" && strings.HasPrefix(opts.Prefix, p.failPrefix) {
+		return nil, os.ErrPermission
+	}
+	return p.fakeProvider.List(ctx, opts)
+}
+
+func (p *laneListingProvider) listed(prefix string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lists[prefix]
+}
+
+// failOneLane runs a four-lane build whose third lane cannot list, leaving the
+// other three sealed.
+func failOneLane(t *testing.T, name string) Config {
+	t.Helper()
+	cfg := laneTestConfig(t, name, laneSitePrefixes(4))
+	cfg.MaxJournalLanes = 4
+	cfg.Crawl.Concurrency = 4
+	objects := cfg.Source.Provider.(fakeProvider).objects
+	cfg.Source = Source{Provider: &laneListingProvider{fakeProvider: fakeProvider{objects: objects}, failPrefix: "data/siteC/"}, ProviderName: "s3"}
+
+	_, err := NewRunner(cfg).Build(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "crawl failed")
+	require.NoFileExists(t, cfg.Paths.LatestPath)
+
+	state, ok, err := ReadResumeState(cfg.Paths.JournalDir)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "run_test", state.RunID)
+	require.Equal(t, 4, state.Lanes)
+	require.Equal(t, 3, state.SealedLanes)
+	require.Equal(t, cfg.RunStartedAt, state.RunStartedAt)
+
+	cfg.Source = Source{Provider: &laneListingProvider{fakeProvider: fakeProvider{objects: objects}}, ProviderName: "s3"}
+	return cfg
+}
+
+// TestResumeReusesSealedLanes pins the resume contract: a lane that sealed in
+// the failed attempt is not listed again, the failed lane is, and the resumed
+// run publishes the whole run's coverage.
+func TestResumeReusesSealedLanes(t *testing.T) {
+	cfg := failOneLane(t, "resume-reuse")
+	cfg.Resume = true
+	// Lane assignment comes from the checkpoint, so a narrower concurrency on
+	// resume must not replan the run into fewer lanes.
+	cfg.Crawl.Concurrency = 1
+
+	summary, err := NewRunner(cfg).Build(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, summary.ReusedLanes)
+	require.Len(t, summary.Lanes, 4)
+	require.Equal(t, int64(8), summary.ObjectsObserved)
+	require.Equal(t, 8, summary.Manifest.Rows)
+	require.FileExists(t, cfg.Paths.LatestPath)
+
+	p := cfg.Source.Provider.(*laneListingProvider)
+	require.Equal(t, 1, p.listed("data/siteC/"), "the failed lane is listed again")
+	for _, site := range []string{"data/siteA/", "data/siteB/", "data/siteD/"} {
+		require.Zero(t, p.listed(site), "sealed lane %s must be reused, not relisted", site)
+	}
+	require.Len(t, readSealedJournals(t, cfg.Paths.JournalDir), 4)
+
+	// A published run has nothing left to resume.
+	_, err = NewRunner(cfg).Build(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already published")
+}
+
+// TestResumeRefusesChangedPlan pins that sealed lanes are reused only under the
+// plan they attest.
+func TestResumeRefusesChangedPlan(t *testing.T) {
+	cfg := failOneLane(t, "resume-plan")
+	cfg.Resume = true
+	cfg.Match = MatchConfig{Includes: []string{"**/a.xml"}}
+
+	_, err := NewRunner(cfg).Build(context.Background())
+	require.ErrorIs(t, err, ErrResumePlanChanged)
+	require.NoFileExists(t, cfg.Paths.LatestPath)
+	p := cfg.Source.Provider.(*laneListingProvider)
+	require.Zero(t, p.listed("data/siteC/"), "a refused resume lists nothing")
+
+	state, ok, err := ReadResumeState(cfg.Paths.JournalDir)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 3, state.SealedLanes, "a refused resume leaves the checkpoint intact")
+}
+
+// TestResumeWithoutCheckpointRefuses pins that a run which never reached its
+// crawl cannot be resumed.
+func TestResumeWithoutCheckpointRefuses(t *testing.T) {
+	cfg := testConfig(t, "resume-none")
+	cfg.Resume = true
+
+	_, err := NewRunner(cfg).Build(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no lane checkpoint")
+
+	_, ok, err := ReadResumeState(cfg.Paths.JournalDir)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
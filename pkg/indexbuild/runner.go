@@ -6,16 +6,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
-	"github.com/3leaps/gonimbus/pkg/crawler"
 	"github.com/3leaps/gonimbus/pkg/indexcoord"
 	"github.com/3leaps/gonimbus/pkg/match"
+	"github.com/3leaps/gonimbus/pkg/shard"
 	"github.com/3leaps/gonimbus/pkg/uri"
 )
 
@@ -39,6 +42,14 @@ func (r *Runner) Build(ctx context.Context) (summary Summary, buildErr error) {
 	if err != nil {
 		return Summary{}, err
 	}
+	ctx, span := tracer.Start(ctx, "indexbuild.build")
+	defer func() {
+		span.SetAttributes(
+			attribute.Int64("gonimbus.index.objects_observed", summary.ObjectsObserved),
+			attribute.Int("gonimbus.index.rows", summary.Manifest.Rows),
+		)
+		endSpan(span, buildErr)
+	}()
 	if err := emitEvent(ctx, cfg.Events, Event{
 		Type:  EventTypeRunStart,
 		RunID: cfg.RunID,
@@ -80,59 +91,56 @@ func (r *Runner) Build(ctx context.Context) (summary Summary, buildErr error) {
 	if err := plan.preflightContinuityLayout(cfg.RunID, cfg.Paths); err != nil {
 		return Summary{}, err
 	}
-
-	basePrefix, err := basePrefixFromURI(cfg.BaseURI)
-	if err != nil {
-		return Summary{}, err
-	}
-	matcher, err := buildMatcher(basePrefix, cfg.Match)
-	if err != nil {
-		return Summary{}, err
+	var checkpoint *laneCheckpoint
+	if cfg.Resume {
+		// The checkpoint is bound before the ledger so a refused resume leaves the
+		// failed attempt's entry as it was.
+		checkpoint, err = openResumeCheckpoint(cfg, r.config.RunStartedAt)
+		if err != nil {
+			return Summary{}, err
+		}
+		cfg.RunStartedAt = checkpoint.doc.RunStartedAt
+		if r.config.CreatedAt.IsZero() {
+			cfg.CreatedAt = cfg.RunStartedAt
+		}
 	}
-	// The sealed journal records the canonical observation plan so a later
-	// recovery re-publish can bind its coverage authority to what was actually
-	// crawled (scoped plan, or the full base prefix when unscoped).
-	journalPlan, err := journalCrawlPlan(basePrefix, cfg.CrawlPrefixes)
-	if err != nil {
+	// From here the run is on record: every exit below lands in the ledger, and
+	// sealed journals mark a failure as resumable.
+	ledger := newRunLedger(cfg.IndexSetID, cfg.RunID, ledgerOperationBuild, cfg.Paths, cfg.RunStartedAt, cfg.Clock)
+	if err := ledger.start(); err != nil {
 		return Summary{}, err
 	}
-	crawlCfg := cfg.Crawl
-	if crawlCfg.Concurrency <= 0 {
-		crawlCfg.Concurrency = crawler.DefaultConfig().Concurrency
-	}
-	if crawlCfg.ChannelBuffer <= 0 {
-		crawlCfg.ChannelBuffer = crawler.DefaultConfig().ChannelBuffer
+	sealedJournals := 0
+	if checkpoint != nil {
+		sealedJournals = checkpoint.doc.sealedLanes()
 	}
-	if crawlCfg.ProgressEvery <= 0 {
-		crawlCfg.ProgressEvery = crawler.DefaultConfig().ProgressEvery
-	}
-	maxLanes, err := resolveMaxJournalLanes(cfg.MaxJournalLanes)
+	defer func() { buildErr = ledger.finish(ctx, buildErr, sealedJournals) }()
+
+	lanesCfg, err := resolveCrawlLanesConfig(cfg)
 	if err != nil {
 		return Summary{}, err
 	}
-	prefixes := append([]string(nil), cfg.CrawlPrefixes...)
-	if len(prefixes) == 0 {
-		prefixes = matcher.Prefixes()
+	if checkpoint != nil {
+		if err := checkpoint.admitResumePlan(lanesCfg); err != nil {
+			return Summary{}, err
+		}
+		lanesCfg.checkpoint = checkpoint
 	}
-	if len(prefixes) == 0 {
-		prefixes = []string{""}
+	// A selector change is refused here, before the crawl, rather than at
+	// publication after the whole listing.
+	if err := plan.admitSelector(lanesCfg.selector); err != nil {
+		return Summary{}, err
 	}
-
-	crawlResult, err := runCrawlLanes(ctx, crawlLanesConfig{
-		build:       cfg,
-		basePrefix:  basePrefix,
-		matcher:     matcher,
-		crawl:       crawlCfg,
-		maxLanes:    maxLanes,
-		journalPlan: journalPlan,
-		prefixes:    prefixes,
-	})
+	prefixes := lanesCfg.prefixes
+	crawlResult, err := runCrawlLanes(ctx, lanesCfg)
 	if err != nil {
+		sealedJournals = crawlResult.sealedLanes
 		return Summary{}, err
 	}
 	if len(crawlResult.prefixesCrawled) > 0 {
 		prefixes = crawlResult.prefixesCrawled
 	}
+	sealedJournals = len(crawlResult.journalPaths)
 
 	retryCfg := RetryConfig{
 		IndexSetID:           cfg.IndexSetID,
@@ -153,12 +161,17 @@ func (r *Runner) Build(ctx context.Context) (summary Summary, buildErr error) {
 	}
 	// Private path: reuse the held Build lease (never a public forgeable guard)
 	// and the parent captured once above (no second latest reopen).
-	result, err := retryWithLease(ctx, retryCfg, plan, authority, lease)
+	result, err := retryWithLease(ctx, retryCfg, plan, authority, lease, lanesCfg.selector)
 	if err != nil {
 		return Summary{}, err
 	}
 	result.PrefixesCrawled = append([]string(nil), prefixes...)
 	result.ObjectsObserved = crawlResult.objectsObserved
+	result.ObjectsListed = crawlResult.objectsListed
+	if len(crawlResult.lanes) > 1 {
+		result.Lanes = crawlResult.lanes
+	}
+	result.ReusedLanes = crawlResult.reusedLanes
 	return result, nil
 }
 
@@ -197,6 +210,11 @@ func Retry(ctx context.Context, cfg RetryConfig) (summary Summary, retryErr erro
 	if err := plan.preflightContinuityLayout(cfg.RunID, cfg.Paths); err != nil {
 		return Summary{}, err
 	}
+	ledger := newRunLedger(cfg.IndexSetID, cfg.RunID, ledgerOperationRetry, cfg.Paths, cfg.RunStartedAt, cfg.Clock)
+	if err := ledger.start(); err != nil {
+		return Summary{}, err
+	}
+	defer func() { retryErr = ledger.finish(ctx, retryErr, len(cfg.JournalPaths)) }()
 	// Bind coverage authority to sealed-journal provenance before any publish
 	// side effect. Coverage authorizes tombstones over the verified-parent rows,
 	// so the plan it must match comes from the journals (what was actually
@@ -211,14 +229,14 @@ func Retry(ctx context.Context, cfg RetryConfig) (summary Summary, retryErr erro
 	if err != nil {
 		return Summary{}, err
 	}
-	boundPlan, err := boundCrawlPlanFromJournals(cfg.JournalPaths, budget.MaxRecordBytes)
+	provenance, err := boundJournalProvenance(cfg.JournalPaths, budget.MaxRecordBytes)
 	if err != nil {
 		return Summary{}, err
 	}
-	if err := validateCoverageMatchesCrawlPlan(cfg.BaseURI, boundPlan, cfg.Coverage); err != nil {
+	if err := validateCoverageMatchesCrawlPlan(cfg.BaseURI, provenance.plan, cfg.Coverage); err != nil {
 		return Summary{}, fmt.Errorf("retry coverage authority: %w", err)
 	}
-	return retryWithLease(ctx, cfg, plan, authority, lease)
+	return retryWithLease(ctx, cfg, plan, authority, lease, provenance.selector)
 }
 
 // joinAuthorityRelease releases an authority this package owns and folds a
@@ -255,8 +273,10 @@ func acquireIndexSetAuthority(ctx context.Context, held *indexcoord.Lease, segme
 // retryWithLease publishes under an already-held package-owned lease, using the
 // parent captured once by the caller (Build or public Retry). It does not reopen
 // latest to re-derive the parent; the publish-time CAS revalidation is the sole
-// later read of the authoritative latest pointer.
-func retryWithLease(ctx context.Context, cfg RetryConfig, plan *verifiedParentPlan, authority *indexcoord.Lease, lease *indexsubstrate.WriteLease) (Summary, error) {
+// later read of the authoritative latest pointer. selector is the run's
+// observation selector (from the crawl, or from the sealed journals on
+// recovery) and is stamped into every published coverage entry.
+func retryWithLease(ctx context.Context, cfg RetryConfig, plan *verifiedParentPlan, authority *indexcoord.Lease, lease *indexsubstrate.WriteLease, selector *indexsubstrate.ObservationSelector) (Summary, error) {
 	if authority == nil {
 		return Summary{}, fmt.Errorf("index set authority is required")
 	}
@@ -277,6 +297,9 @@ func retryWithLease(ctx context.Context, cfg RetryConfig, plan *verifiedParentPl
 	if err := plan.validate(); err != nil {
 		return Summary{}, err
 	}
+	if err := plan.admitSelector(selector); err != nil {
+		return Summary{}, err
+	}
 	coverage, err := normalizeCoverageForBaseURI(cfg.BaseURI, cfg.Coverage)
 	if err != nil {
 		return Summary{}, err
@@ -306,13 +329,18 @@ func retryWithLease(ctx context.Context, cfg RetryConfig, plan *verifiedParentPl
 		return Summary{}, fmt.Errorf("index set authority: %w", err)
 	}
 
-	result, err := indexsubstrate.PublishSnapshotContext(ctx, indexsubstrate.PublishConfig{
+	// Publication is where compaction spills and merges; its span separates that
+	// local work from the provider crawl.
+	publishCtx, span := tracer.Start(ctx, "indexbuild.publish", trace.WithAttributes(
+		attribute.Int("gonimbus.index.journals", len(cfg.JournalPaths)),
+	))
+	result, err := indexsubstrate.PublishSnapshotContext(publishCtx, indexsubstrate.PublishConfig{
 		IndexSetID:           cfg.IndexSetID,
 		RunID:                cfg.RunID,
 		RunStartedAt:         cfg.RunStartedAt,
 		CreatedAt:            cfg.CreatedAt,
 		JournalPaths:         append([]string(nil), cfg.JournalPaths...),
-		Coverage:             toSubstrateCoverage(coverage),
+		Coverage:             toSubstrateCoverage(coverage, selector),
 		SegmentDir:           cfg.Paths.SegmentDir,
 		ManifestPath:         cfg.Paths.ManifestPath,
 		CompletePath:         cfg.Paths.CompletePath,
@@ -331,6 +359,10 @@ func retryWithLease(ctx context.Context, cfg RetryConfig, plan *verifiedParentPl
 		SpillRoot:         cfg.Spill.Root,
 		OnSegmentProgress: toSubstrateSegmentProgress(cfg.OnSegmentProgress),
 	})
+	if err == nil {
+		span.SetAttributes(attribute.Int64("gonimbus.index.spill_peak_bytes", result.Compaction.PeakWorkspaceBytes))
+	}
+	endSpan(span, err)
 	if err != nil {
 		return Summary{}, err
 	}
@@ -422,6 +454,25 @@ func (p *verifiedParentPlan) validate() error {
 	return nil
 }
 
+// admitSelector refuses a publication whose observation selector differs from
+// the verified parent's. Complete coverage under one selector says nothing
+// about the rows a parent kept under another: publishing over it would
+// tombstone rows this run chose not to observe, and keep rows it filtered out.
+// A proven first publication admits any selector.
+func (p *verifiedParentPlan) admitSelector(selector *indexsubstrate.ObservationSelector) error {
+	if p == nil || p.snapshot == nil {
+		return nil
+	}
+	parent, err := indexsubstrate.CoverageSelector(p.snapshot.Manifest.Coverage)
+	if err != nil {
+		return fmt.Errorf("verified parent: %w", err)
+	}
+	if !indexsubstrate.SameObservationSelector(parent, selector) {
+		return fmt.Errorf("observation selector (%s) differs from the verified parent's (%s); a differently filtered build must publish a new index set", selector, parent)
+	}
+	return nil
+}
+
 // captureVerifiedParent opens the canonical latest once under the caller's held
 // authority/write lease and returns the verified-parent plan. The captured
 // snapshot must belong to the requested index set (indexSetID); a validly
@@ -824,7 +875,12 @@ func journalCrawlPlan(basePrefix string, crawlPrefixes []string) ([]string, erro
 //     was actually supplied: omitting a lane narrows the derived plan, and the
 //     caller's whole-run coverage then refuses rather than authorizing tombstones
 //     over rows no supplied journal observed;
-//  3. anything else — a mode mixed across the set, or a mode value this reader
+//  3. every mode key-range — each journal records the whole-run plan and one key
+//     range of it, so every plan must agree as in the legacy form and the
+//     ranges must tile the keyspace exactly. A missing, overlapping, or gapped
+//     range refuses: a key range is not a plan entry, so an omitted journal
+//     cannot narrow the derived plan and must not be read as covering it;
+//  4. anything else — a mode mixed across the set, or a mode value this reader
 //     does not recognize — refuses. An unrecognized value is refused rather than
 //     defaulted, so a form written by a newer writer cannot be silently read
 //     under legacy rules.
@@ -838,36 +894,71 @@ func journalCrawlPlan(basePrefix string, crawlPrefixes []string) ([]string, erro
 // SpillMergeBudgetExhausted here — before any publish work — rather than
 // allocating unbounded during provenance binding.
 func boundCrawlPlanFromJournals(journalPaths []string, maxRecordBytes int64) ([]string, error) {
+	provenance, err := boundJournalProvenance(journalPaths, maxRecordBytes)
+	if err != nil {
+		return nil, err
+	}
+	return provenance.plan, nil
+}
+
+// journalProvenance is what a sealed journal set attests about its run: the
+// crawl-prefix plan and the observation selector applied under it.
+type journalProvenance struct {
+	plan     []string
+	selector *indexsubstrate.ObservationSelector
+}
+
+// boundJournalProvenance applies the boundCrawlPlanFromJournals rules and also
+// binds the observation selector: every journal of a run must record the same
+// one, since a run applies one selector, and recovery re-publishes under it
+// rather than under a caller's.
+func boundJournalProvenance(journalPaths []string, maxRecordBytes int64) (journalProvenance, error) {
 	if len(journalPaths) == 0 {
-		return nil, fmt.Errorf("journal paths are required")
+		return journalProvenance{}, fmt.Errorf("journal paths are required")
 	}
 	type journalPlan struct {
 		mode     string
 		prefixes []string
+		keyRange *indexsubstrate.KeyRange
 	}
 	plans := make([]journalPlan, 0, len(journalPaths))
+	var selector *indexsubstrate.ObservationSelector
 	for i, path := range journalPaths {
 		summary, err := indexsubstrate.ValidateJournalBounded(path, maxRecordBytes)
 		if err != nil {
-			return nil, fmt.Errorf("read journal header for coverage provenance: %w", err)
+			return journalProvenance{}, fmt.Errorf("read journal header for coverage provenance: %w", err)
 		}
 		if strings.TrimSpace(summary.ContentSHA256) == "" {
-			return nil, fmt.Errorf("%w: journal %d is not content-integrity sealed; recovery cannot trust its crawl-plan provenance", indexsubstrate.ErrStaleParent, i)
+			return journalProvenance{}, fmt.Errorf("%w: journal %d is not content-integrity sealed; recovery cannot trust its crawl-plan provenance", indexsubstrate.ErrStaleParent, i)
 		}
 		if len(summary.Header.CrawlPrefixes) == 0 {
-			return nil, fmt.Errorf("%w: journal %d predates crawl-plan provenance; recovery cannot validate coverage authority", indexsubstrate.ErrStaleParent, i)
+			return journalProvenance{}, fmt.Errorf("%w: journal %d predates crawl-plan provenance; recovery cannot validate coverage authority", indexsubstrate.ErrStaleParent, i)
 		}
 		if err := validateJournalPlanCanonical(summary.Header.CrawlPrefixes); err != nil {
-			return nil, fmt.Errorf("%w: journal %d: %v", indexsubstrate.ErrStaleParent, i, err)
+			return journalProvenance{}, fmt.Errorf("%w: journal %d: %v", indexsubstrate.ErrStaleParent, i, err)
 		}
 		mode := summary.Header.CrawlPlanMode
-		if mode != "" && mode != indexsubstrate.CrawlPlanModeLaneLocal {
-			return nil, fmt.Errorf("%w: journal %d records unrecognized crawl_plan_mode %q; recovery cannot interpret its crawl-plan provenance", indexsubstrate.ErrStaleParent, i, mode)
+		switch mode {
+		case "", indexsubstrate.CrawlPlanModeLaneLocal:
+			if summary.Header.KeyRange != nil {
+				return journalProvenance{}, fmt.Errorf("%w: journal %d records a key range outside key-range crawl-plan provenance", indexsubstrate.ErrStaleParent, i)
+			}
+		case indexsubstrate.CrawlPlanModeKeyRange:
+			if summary.Header.KeyRange == nil {
+				return journalProvenance{}, fmt.Errorf("%w: journal %d records key-range crawl-plan provenance without a key range", indexsubstrate.ErrStaleParent, i)
+			}
+		default:
+			return journalProvenance{}, fmt.Errorf("%w: journal %d records unrecognized crawl_plan_mode %q; recovery cannot interpret its crawl-plan provenance", indexsubstrate.ErrStaleParent, i, mode)
 		}
 		if len(plans) > 0 && mode != plans[0].mode {
-			return nil, fmt.Errorf("%w: sealed journals mix legacy whole-plan and lane-local crawl-plan provenance", indexsubstrate.ErrStaleParent)
+			return journalProvenance{}, fmt.Errorf("%w: sealed journals mix %s crawl-plan provenance", indexsubstrate.ErrStaleParent, describeCrawlPlanModes(plans[0].mode, mode))
 		}
-		plans = append(plans, journalPlan{mode: mode, prefixes: append([]string(nil), summary.Header.CrawlPrefixes...)})
+		if i == 0 {
+			selector = summary.Header.Selector
+		} else if !indexsubstrate.SameObservationSelector(selector, summary.Header.Selector) {
+			return journalProvenance{}, fmt.Errorf("%w: sealed journals disagree on their observation selector", indexsubstrate.ErrStaleParent)
+		}
+		plans = append(plans, journalPlan{mode: mode, prefixes: append([]string(nil), summary.Header.CrawlPrefixes...), keyRange: summary.Header.KeyRange})
 	}
 	if plans[0].mode == indexsubstrate.CrawlPlanModeLaneLocal {
 		union := make([]string, 0, len(plans))
@@ -875,22 +966,49 @@ func boundCrawlPlanFromJournals(journalPaths []string, maxRecordBytes int64) ([]
 		for i, p := range plans {
 			for _, prefix := range p.prefixes {
 				if j, dup := claimedBy[prefix]; dup {
-					return nil, fmt.Errorf("%w: crawl-plan entry %q is claimed by journals %d and %d; lane-local plans must be disjoint", indexsubstrate.ErrStaleParent, prefix, j, i)
+					return journalProvenance{}, fmt.Errorf("%w: crawl-plan entry %q is claimed by journals %d and %d; lane-local plans must be disjoint", indexsubstrate.ErrStaleParent, prefix, j, i)
 				}
 				claimedBy[prefix] = i
 				union = append(union, prefix)
 			}
 		}
 		sort.Strings(union)
-		return union, nil
+		return journalProvenance{plan: union, selector: selector.Clone()}, nil
 	}
 	planKey := crawlPlanSetKey(plans[0].prefixes)
 	for i := 1; i < len(plans); i++ {
 		if crawlPlanSetKey(plans[i].prefixes) != planKey {
-			return nil, fmt.Errorf("%w: sealed journals disagree on their crawl-prefix plan", indexsubstrate.ErrStaleParent)
+			return journalProvenance{}, fmt.Errorf("%w: sealed journals disagree on their crawl-prefix plan", indexsubstrate.ErrStaleParent)
+		}
+	}
+	if plans[0].mode == indexsubstrate.CrawlPlanModeKeyRange {
+		ranges := make([]shard.KeyRange, len(plans))
+		for i, p := range plans {
+			ranges[i] = shard.KeyRange{StartAfter: p.keyRange.StartAfter, EndBefore: p.keyRange.EndBefore}
+		}
+		// An open start sorts first; the rest order by their start bound.
+		sort.Slice(ranges, func(a, b int) bool { return ranges[a].StartAfter < ranges[b].StartAfter })
+		if err := shard.ValidateKeyRanges(ranges); err != nil {
+			return journalProvenance{}, fmt.Errorf("%w: key-range journals do not cover the crawl plan: %v", indexsubstrate.ErrStaleParent, err)
 		}
 	}
-	return append([]string(nil), plans[0].prefixes...), nil
+	return journalProvenance{plan: append([]string(nil), plans[0].prefixes...), selector: selector.Clone()}, nil
+}
+
+// describeCrawlPlanModes names two differing provenance modes in a fixed
+// order, so the diagnosis does not depend on which journal came first.
+func describeCrawlPlanModes(a, b string) string {
+	rank := map[string]int{"": 0, indexsubstrate.CrawlPlanModeLaneLocal: 1, indexsubstrate.CrawlPlanModeKeyRange: 2}
+	if rank[b] < rank[a] {
+		a, b = b, a
+	}
+	name := func(mode string) string {
+		if mode == "" {
+			return "legacy whole-plan"
+		}
+		return mode
+	}
+	return name(a) + " and " + name(b)
 }
 
 // crawlPlanSetKey is an order-independent identity for a canonical plan so
@@ -941,33 +1059,38 @@ func validateCrawlPlanCanonical(crawlPrefixes []string) error {
 	return nil
 }
 
-// validateDurableObservationSelector refuses any durable build whose match or
-// filter would observe fewer objects than its recorded plan attests complete.
-// Coverage is destructive authority over verified-parent rows, and the sealed
-// crawl plan is the observation universe recovery re-publishes against — so an
-// exclude, non-default include, hidden inclusion, or post-list filter would make
-// the plan a false record of what was observed and authorize tombstones over
-// rows the run intentionally skipped. The check is unconditional (scoped and
-// unscoped): an unscoped build stamps its base prefix as the plan, so the base
-// prefix must genuinely be the complete observation universe. This mirrors the
-// CLI adapter's faithful-coverage match gate at the engine seam; it intentionally
-// narrows the Experimental library API — restrictive match/filter selection is
-// no longer a durable-build surface.
-func validateDurableObservationSelector(cfg Config) error {
-	const reason = "coverage and the sealed crawl plan attest complete observation"
-	if len(cfg.Match.Excludes) > 0 {
-		return fmt.Errorf("a durable build does not support match excludes: %s", reason)
-	}
-	if cfg.Match.IncludeHidden {
-		return fmt.Errorf("a durable build does not support include_hidden: %s", reason)
-	}
-	if !isDefaultObservationIncludes(cfg.Match.Includes) {
-		return fmt.Errorf("a durable build supports only the default include: %s", reason)
+// observationSelector returns the recorded form of cfg's match and filter
+// settings, or nil when they are the default universe (every listed
+// non-hidden object). A selected build's coverage attests complete
+// observation of the objects the selector matched under its plan, so the
+// selector is sealed into the journals and stamped into the published
+// coverage: recovery re-publishes under the sealed selector, and a verified
+// parent observed under a different one is refused rather than tombstoned
+// against. Patterns are de-duplicated and sorted, since include and exclude
+// sets do not depend on order.
+func observationSelector(cfg Config) *indexsubstrate.ObservationSelector {
+	selector := &indexsubstrate.ObservationSelector{
+		Excludes:      canonicalPatterns(cfg.Match.Excludes),
+		IncludeHidden: cfg.Match.IncludeHidden,
+	}
+	if includes := canonicalPatterns(cfg.Match.Includes); !isDefaultObservationIncludes(includes) {
+		selector.Includes = includes
 	}
 	if cfg.Filter != nil {
-		return fmt.Errorf("a durable build does not support a post-list filter: %s", reason)
+		selector.Filter = cfg.Filter.Canonical()
 	}
-	return nil
+	if len(selector.Includes) == 0 && len(selector.Excludes) == 0 && !selector.IncludeHidden && selector.Filter == "" {
+		return nil
+	}
+	return selector
+}
+
+// canonicalPatterns de-duplicates and sorts match patterns. It does not trim:
+// the recorded selector must be the exact patterns the matcher applies.
+func canonicalPatterns(patterns []string) []string {
+	out := append([]string(nil), patterns...)
+	sort.Strings(out)
+	return slices.Compact(out)
 }
 
 // isDefaultObservationIncludes reports whether includes is the unrestricted
@@ -1022,15 +1145,20 @@ func normalizeConfig(cfg Config) (Config, error) {
 	if err := validateCrawlPlanCanonical(cfg.CrawlPrefixes); err != nil {
 		return Config{}, err
 	}
-	if err := validateDurableObservationSelector(cfg); err != nil {
-		return Config{}, err
-	}
 	// Refuse an out-of-range lane count here, before any event, sink, or crawl
 	// side effect, so an operator asking for more lanes than publication can stage
 	// learns that instead of silently receiving fewer.
 	if _, err := resolveMaxJournalLanes(cfg.MaxJournalLanes); err != nil {
 		return Config{}, err
 	}
+	if cfg.CrawlKeyRanges < 0 || cfg.CrawlKeyRanges > MaxJournalLanesCeiling {
+		return Config{}, fmt.Errorf("crawl key ranges must be between 0 and %d, got %d", MaxJournalLanesCeiling, cfg.CrawlKeyRanges)
+	}
+	for prefix, weight := range cfg.PrefixWeights {
+		if weight < 0 {
+			return Config{}, fmt.Errorf("prefix weight for %q must not be negative, got %d", prefix, weight)
+		}
+	}
 	effectivePlan, err := journalCrawlPlan(basePrefix, cfg.CrawlPrefixes)
 	if err != nil {
 		return Config{}, err
@@ -1193,6 +1321,11 @@ func manifestSummary(manifest indexsubstrate.InternalManifest) ManifestSummary {
 		DistinctETags: manifest.Counts.DistinctETags,
 		Segments:      make([]SegmentSummary, 0, len(manifest.Segments)),
 	}
+	// Publication refuses coverage entries that disagree, so the first entry
+	// speaks for the snapshot.
+	if len(manifest.Coverage) > 0 {
+		out.Selector = fromSubstrateSelector(manifest.Coverage[0].Selector)
+	}
 	for _, segment := range manifest.Segments {
 		out.Segments = append(out.Segments, SegmentSummary{
 			SegmentID:  segment.SegmentID,
@@ -1208,7 +1341,7 @@ func manifestSummary(manifest indexsubstrate.InternalManifest) ManifestSummary {
 	return out
 }
 
-func toSubstrateCoverage(in []CoverageAttestation) []indexsubstrate.CoverageAttestation {
+func toSubstrateCoverage(in []CoverageAttestation, selector *indexsubstrate.ObservationSelector) []indexsubstrate.CoverageAttestation {
 	out := make([]indexsubstrate.CoverageAttestation, 0, len(in))
 	for _, entry := range in {
 		out = append(out, indexsubstrate.CoverageAttestation{
@@ -1216,6 +1349,7 @@ func toSubstrateCoverage(in []CoverageAttestation) []indexsubstrate.CoverageAtte
 			Basis:    indexsubstrate.CoverageBasis(entry.Basis),
 			Complete: entry.Complete,
 			Gaps:     toSubstrateScopes(entry.Gaps),
+			Selector: selector.Clone(),
 		})
 	}
 	return out
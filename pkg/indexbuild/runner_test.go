@@ -287,7 +287,7 @@ func TestRetryWithLeaseRejectsNilPlan(t *testing.T) {
 		Paths:        PathConfig{LatestPath: latestPath},
 		JournalPaths: []string{filepath.Join(root, "shard-0001.jsonl")},
 	}
-	_, err = retryWithLease(ctx, cfg, nil, authority, lease)
+	_, err = retryWithLease(ctx, cfg, nil, authority, lease, nil)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "verified parent plan is required")
 	require.NoFileExists(t, latestPath)
@@ -321,6 +321,43 @@ func TestRunnerDoesNotPublishWhenObservationSinkFails(t *testing.T) {
 	require.NoFileExists(t, cfg.Paths.LatestPath)
 }
 
+func TestBuildRecordsRunOutcomeInLedger(t *testing.T) {
+	ledgerEntry := func(t *testing.T, cfg Config) indexsubstrate.RunLedgerEntry {
+		t.Helper()
+		entries, err := indexsubstrate.ListRunLedger(filepath.Dir(cfg.Paths.LatestPath), cfg.IndexSetID, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		return entries[0]
+	}
+
+	published := testConfig(t, "ledger-success")
+	_, err := NewRunner(published).Build(context.Background())
+	require.NoError(t, err)
+	entry := ledgerEntry(t, published)
+	require.Equal(t, indexsubstrate.RunLedgerSuccess, entry.Status)
+	require.Equal(t, published.RunStartedAt, entry.StartedAt)
+	require.NotNil(t, entry.EndedAt)
+
+	crawlFailed := testConfig(t, "ledger-failed")
+	crawlFailed.ObservationSinks = []output.Writer{failingObservationSink{}}
+	_, err = NewRunner(crawlFailed).Build(context.Background())
+	require.Error(t, err)
+	entry = ledgerEntry(t, crawlFailed)
+	require.Equal(t, indexsubstrate.RunLedgerFailed, entry.Status)
+	require.Zero(t, entry.SealedJournals)
+	require.NotEmpty(t, entry.Reason)
+
+	// A publish failure after the journals sealed is resumable by Retry.
+	publishFailed := testConfig(t, "ledger-resumable")
+	require.NoError(t, os.MkdirAll(filepath.Dir(publishFailed.Paths.ManifestPath), 0o700))
+	require.NoError(t, os.WriteFile(publishFailed.Paths.ManifestPath, []byte("{}\n"), 0o600))
+	_, err = NewRunner(publishFailed).Build(context.Background())
+	require.Error(t, err)
+	entry = ledgerEntry(t, publishFailed)
+	require.Equal(t, indexsubstrate.RunLedgerFailedResumable, entry.Status)
+	require.Positive(t, entry.SealedJournals)
+}
+
 func TestPartialCrawlJournalIsNotPublishableByRetry(t *testing.T) {
 	cfg := testConfig(t, "partial-journal")
 	cfg.Source.Provider = partialListProvider{
@@ -0,0 +1,21 @@
+package indexbuild
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer resolves through the global TracerProvider, so build spans are no-ops
+// unless the embedding process installs one. Spans carry counts and lane
+// ordinals only — never prefixes, keys, or base URIs.
+var tracer = otel.Tracer("github.com/3leaps/gonimbus/pkg/indexbuild")
+
+// endSpan marks span failed without echoing err, whose message may name keys
+// or local paths.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(codes.Error, "index build stage failed")
+	}
+	span.End()
+}
@@ -25,8 +25,14 @@ type journalWriterConfig struct {
 	CrawlPrefixes []string
 	// CrawlPlanMode is the recovery discriminator sealed into the header. Empty is
 	// the legacy/single-lane whole-plan form; indexsubstrate.CrawlPlanModeLaneLocal
-	// marks a lane subset. See indexsubstrate.JournalHeader.CrawlPlanMode.
+	// marks a lane subset; indexsubstrate.CrawlPlanModeKeyRange marks one key
+	// range of the whole plan. See indexsubstrate.JournalHeader.CrawlPlanMode.
 	CrawlPlanMode string
+	// KeyRange is the lane's key slice, required under CrawlPlanModeKeyRange and
+	// refused under any other mode.
+	KeyRange *indexsubstrate.KeyRange
+	// Selector is the run's recorded observation selector, nil when unfiltered.
+	Selector *indexsubstrate.ObservationSelector
 	// LaneOrdinal is the 1-based canonical lane position, which is what derives the
 	// stable journal ID and shard. It is never the lane's completion order: journal
 	// identity orders cross-journal conflict resolution during compaction, so it
@@ -49,7 +55,16 @@ func newJournalWriter(cfg journalWriterConfig) (*journalWriter, error) {
 	if cfg.LaneOrdinal < 1 {
 		return nil, fmt.Errorf("journal lane ordinal must be 1 or greater, got %d", cfg.LaneOrdinal)
 	}
-	if cfg.CrawlPlanMode != "" && cfg.CrawlPlanMode != indexsubstrate.CrawlPlanModeLaneLocal {
+	switch cfg.CrawlPlanMode {
+	case "", indexsubstrate.CrawlPlanModeLaneLocal:
+		if cfg.KeyRange != nil {
+			return nil, fmt.Errorf("journal lane %d has a key range without key-range crawl plan mode", cfg.LaneOrdinal)
+		}
+	case indexsubstrate.CrawlPlanModeKeyRange:
+		if cfg.KeyRange == nil {
+			return nil, fmt.Errorf("journal lane %d admitted in key-range mode without a key range", cfg.LaneOrdinal)
+		}
+	default:
 		return nil, fmt.Errorf("unrecognized journal crawl plan mode %q", cfg.CrawlPlanMode)
 	}
 	// Refuse an empty plan here, at admission, rather than letting it seal. A
@@ -72,6 +87,8 @@ func newJournalWriter(cfg journalWriterConfig) (*journalWriter, error) {
 		Scope:              &indexsubstrate.Scope{Prefix: cfg.BasePrefix},
 		CrawlPrefixes:      append([]string(nil), cfg.CrawlPrefixes...),
 		CrawlPlanMode:      cfg.CrawlPlanMode,
+		KeyRange:           cfg.KeyRange,
+		Selector:           cfg.Selector.Clone(),
 		IndexSchemaVersion: indexsubstrate.IndexSchemaVersion,
 		StartedAt:          cfg.StartedAt,
 	})
@@ -3,13 +3,11 @@ package indexenrich
 import (
 	"time"
 
+	"github.com/3leaps/gonimbus/internal/indexsubstrate"
 	"github.com/3leaps/gonimbus/pkg/indexcoord"
 	"github.com/3leaps/gonimbus/pkg/provider"
 )
 
-// DefaultMaxPriorRows is the hard pre-materialization budget for this cut.
-const DefaultMaxPriorRows = 2_000_000
-
 // StateEvent is one per-candidate HEAD observation for audit sinks.
 // The library does not render CLI JSON; adapters encode the established
 // gonimbus.index.enrich_with_head.state.v1 envelope from this value.
@@ -61,8 +59,10 @@ type Config struct {
 	// sink errors fail closed (no publish).
 	StateSink StateSink
 
-	// MaxPriorRows defaults to DefaultMaxPriorRows when <= 0.
-	MaxPriorRows int
+	// Spill bounds the streaming merge that publishes the enrich-only snapshot.
+	// The parent is streamed segment by segment and HEAD results are spilled
+	// through the enrich journal, so memory does not grow with the set size.
+	Spill SpillConfig
 	// Clock defaults to time.Now.UTC when nil. Run identity is independent of Clock.
 	Clock func() time.Time
 
@@ -71,6 +71,30 @@ type Config struct {
 	MaxManifestBytes int64
 }
 
+// SpillConfig is host/operator capacity configuration for the streaming merge,
+// with the same semantics as indexbuild.SpillConfig. It never enters
+// manifests, receipts, or committed digests.
+type SpillConfig struct {
+	// WorkspaceBytes overrides the merge's live on-disk workspace ceiling. The
+	// merge stages the parent's full current state, so size it to the set. Zero
+	// uses the substrate default; a sub-1 explicit value is rejected.
+	WorkspaceBytes int64
+	// Root overrides the scratch directory (resolved absolute, real,
+	// non-symlink, operator-exclusive at publish). Empty co-locates beside the
+	// enrich journal.
+	Root string
+	// RecordBytes overrides the max single journal-record size. Zero uses the
+	// substrate default; a sub-1 explicit value is rejected.
+	RecordBytes int64
+}
+
+func (s SpillConfig) budget() indexsubstrate.SpillMergeBudget {
+	return indexsubstrate.SpillMergeBudget{
+		MaxWorkspaceBytes: s.WorkspaceBytes,
+		MaxRecordBytes:    s.RecordBytes,
+	}
+}
+
 // QueryOptions mirrors enrich CLI filters without Cobra coupling.
 type QueryOptions struct {
 	Pattern        string
@@ -100,11 +124,14 @@ type Result struct {
 	// Published is true when LatestAdvanced is true (committed enrich snapshot).
 	Published bool
 
-	ParentRunID        string
-	ParentManifestSHA  string
-	ParentCoverageSHA  string
-	ManifestSHA256     string
-	Rows               int
+	ParentRunID       string
+	ParentManifestSHA string
+	ParentCoverageSHA string
+	ManifestSHA256    string
+	Rows              int
+	// PeakSpillBytes is the streaming merge's peak on-disk workspace, zero
+	// when nothing was published.
+	PeakSpillBytes     int64
 	Status             string
 	StorageFiltered    bool
 	ClassificationNote string
@@ -1,6 +1,9 @@
 package indexenrich
 
 import (
+	"context"
+	"errors"
+	"io"
 	"testing"
 	"time"
 
@@ -9,7 +12,7 @@ import (
 	"github.com/3leaps/gonimbus/internal/indexsubstrate"
 )
 
-func TestCandidatesFromParentRowsFilterMatrix(t *testing.T) {
+func TestParentCandidatesFilterMatrix(t *testing.T) {
 	standard := "STANDARD"
 	glacier := "GLACIER"
 	deletedAt := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
@@ -125,7 +128,7 @@ func TestCandidatesFromParentRowsFilterMatrix(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := candidatesFromParentRows(rows, tc.opts)
+			got, err := drainParentCandidates(rows, tc.opts)
 			if tc.wantErr != "" {
 				require.Error(t, err)
 				require.Contains(t, err.Error(), tc.wantErr)
@@ -141,7 +144,7 @@ func TestCandidatesFromParentRowsFilterMatrix(t *testing.T) {
 	}
 }
 
-func TestCandidatesFromParentRowsResumeSkipsAlreadyEnriched(t *testing.T) {
+func TestParentCandidatesResumeSkipsAlreadyEnriched(t *testing.T) {
 	// Resume is applied in executeHeads after filtering; this proves filtering
 	// still returns already-enriched rows so the worker can skip them.
 	standard := "STANDARD"
@@ -150,10 +153,31 @@ func TestCandidatesFromParentRowsResumeSkipsAlreadyEnriched(t *testing.T) {
 		{RelKey: "hot/a.xml", SizeBytes: 10, StorageClass: &standard},
 		{RelKey: "hot/done.xml", SizeBytes: 10, StorageClass: &standard, HeadEnrichedAt: &enrichedAt},
 	}
-	got, err := candidatesFromParentRows(rows, QueryOptions{Pattern: "hot/**"})
+	got, err := drainParentCandidates(rows, QueryOptions{Pattern: "hot/**"})
 	require.NoError(t, err)
 	require.Len(t, got, 2)
 	require.Nil(t, got[0].HeadEnrichedAt)
 	require.NotNil(t, got[1].HeadEnrichedAt)
 	require.Equal(t, "hot/done.xml", got[1].RelKey)
 }
+
+// drainParentCandidates runs rows through the streamed candidate path enrich
+// uses against a published parent.
+func drainParentCandidates(rows []indexsubstrate.CurrentObjectRow, opts QueryOptions) ([]enrichCandidate, error) {
+	filter, err := newCandidateFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	next := parentCandidates(indexsubstrate.NewSliceParentRows(rows), filter)
+	var out []enrichCandidate
+	for {
+		c, err := next(context.Background())
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -109,10 +110,6 @@ func run(ctx context.Context, cfg Config, hooks runHooks) (result Result, runErr
 		return base, fmt.Errorf("parent snapshot index_set_id %q does not match config %q", snap.Complete.IndexSetID, cfg.IndexSetID)
 	}
 
-	priorRows, err := indexsubstrate.ReadManifestRowsBounded(snap.SegmentDir, snap.Manifest, cfg.MaxPriorRows)
-	if err != nil {
-		return base, fmt.Errorf("read parent rows: %w", err)
-	}
 	if len(snap.Manifest.Coverage) == 0 {
 		return base, fmt.Errorf("parent snapshot has no coverage evidence; refuse enrich publish without inherited coverage")
 	}
@@ -127,7 +124,7 @@ func run(ctx context.Context, cfg Config, hooks runHooks) (result Result, runErr
 		CoverageSHA256: covDigest,
 	}
 
-	candidates, err := candidatesFromParentRows(priorRows, cfg.Query)
+	filter, err := newCandidateFilter(cfg.Query)
 	if err != nil {
 		return base, err
 	}
@@ -139,7 +136,16 @@ func run(ctx context.Context, cfg Config, hooks runHooks) (result Result, runErr
 	}
 	runStartedAt := cfg.Clock()
 
-	summary, updates, headErr := executeHeads(ctx, cfg, candidates)
+	// Candidates stream from the parent segment by segment, and each successful
+	// HEAD is appended to the enrich journal as it completes. Neither the parent
+	// rows nor the update set is ever held in memory.
+	parentRows := indexsubstrate.NewPublishedParentRowSource(snap)
+	defer func() { _ = parentRows.Close() }()
+	journal := newEnrichJournal(cfg, runID, runStartedAt)
+	summary, headErr := executeHeads(ctx, cfg, parentCandidates(parentRows, filter), journal.append)
+	if closeErr := parentRows.Close(); closeErr != nil && headErr == nil {
+		headErr = fmt.Errorf("read parent rows: %w", closeErr)
+	}
 	res := Result{
 		IndexSetID:         cfg.IndexSetID,
 		RunID:              runID,
@@ -158,30 +164,35 @@ func run(ctx context.Context, cfg Config, hooks runHooks) (result Result, runErr
 	}
 	if headErr != nil {
 		res.Status = "failed"
-		return res, headErr
+		return res, errors.Join(headErr, journal.discard())
 	}
 	if summary.failed > 0 {
 		res.Status = "partial"
-		return res, fmt.Errorf("HEAD enrichment completed with %d failure(s); durable latest unchanged", summary.failed)
+		// Atomic: the spilled updates are discarded with the journal.
+		return res, errors.Join(fmt.Errorf("HEAD enrichment completed with %d failure(s); durable latest unchanged", summary.failed), journal.discard())
 	}
-	if len(updates) == 0 {
+	if journal.records == 0 {
 		// zero candidates or all resume-skipped: success, no publish
 		return res, nil
 	}
 	if err := authority.AssertHeldFor(cfg.IndexSetID, cfg.SegmentSetRoot); err != nil {
-		return res, fmt.Errorf("index set authority at publish: %w", err)
+		return res, errors.Join(fmt.Errorf("index set authority at publish: %w", err), journal.discard())
+	}
+	if err := journal.seal(); err != nil {
+		return res, errors.Join(err, journal.discard())
 	}
 
-	pub, pubErr := publishEnrich(ctx, cfg, snap, priorRows, parentToken, updates, runID, runStartedAt, lease, hooks)
+	pub, pubErr := publishEnrich(ctx, cfg, snap, parentToken, journal.path, runID, runStartedAt, lease, hooks)
 	res.LatestAdvanced = pub.LatestAdvanced
 	res.Published = pub.LatestAdvanced
 	res.ManifestSHA256 = pub.ManifestSHA256
+	res.PeakSpillBytes = pub.Compaction.PeakWorkspaceBytes
 	if pub.Manifest.Counts.Rows > 0 || pub.LatestAdvanced {
 		res.Rows = pub.Manifest.Counts.Rows
 	}
 	if pub.LatestAdvanced {
 		res.Status = "success"
-		res.Committed = int64(len(updates))
+		res.Committed = journal.records
 		// HeadSucceeded already truthful from executeHeads
 	} else if pubErr != nil {
 		res.Status = "failed"
@@ -218,8 +229,10 @@ func normalizeConfig(cfg Config) (Config, error) {
 	if cfg.Parallel <= 0 {
 		cfg.Parallel = 32
 	}
-	if cfg.MaxPriorRows <= 0 {
-		cfg.MaxPriorRows = DefaultMaxPriorRows
+	cfg.Spill.Root = strings.TrimSpace(cfg.Spill.Root)
+	// Refuse an invalid budget before the lease, the parent open, or any HEAD.
+	if _, err := indexsubstrate.ResolveSpillMergeBudget(cfg.Spill.budget()); err != nil {
+		return cfg, err
 	}
 	if cfg.Clock == nil {
 		cfg.Clock = func() time.Time { return time.Now().UTC() }
@@ -257,11 +270,21 @@ func newRunID() (string, error) {
 	return id, nil
 }
 
-func candidatesFromParentRows(rows []indexsubstrate.CurrentObjectRow, opts QueryOptions) ([]enrichCandidate, error) {
-	var keyRe *regexp.Regexp
+// candidateFilter applies QueryOptions to parent rows. It is built once per run
+// so invalid patterns and sizes refuse before any HEAD.
+type candidateFilter struct {
+	opts       QueryOptions
+	keyRe      *regexp.Regexp
+	minSize    int64
+	maxSize    int64
+	storageSet map[string]struct{}
+}
+
+func newCandidateFilter(opts QueryOptions) (*candidateFilter, error) {
+	f := &candidateFilter{opts: opts, storageSet: map[string]struct{}{}}
 	if opts.KeyRegex != "" {
 		var err error
-		keyRe, err = regexp.Compile(opts.KeyRegex)
+		f.keyRe, err = regexp.Compile(opts.KeyRegex)
 		if err != nil {
 			return nil, fmt.Errorf("invalid key regex: %w", err)
 		}
@@ -269,64 +292,85 @@ func candidatesFromParentRows(rows []indexsubstrate.CurrentObjectRow, opts Query
 	if opts.Pattern != "" && !doublestar.ValidatePattern(opts.Pattern) {
 		return nil, fmt.Errorf("invalid glob pattern: %s", opts.Pattern)
 	}
-	var minSize, maxSize int64
 	var err error
 	if opts.MinSize != "" {
-		minSize, err = match.ParseSize(opts.MinSize)
+		f.minSize, err = match.ParseSize(opts.MinSize)
 		if err != nil {
 			return nil, fmt.Errorf("invalid min size: %w", err)
 		}
 	}
 	if opts.MaxSize != "" {
-		maxSize, err = match.ParseSize(opts.MaxSize)
+		f.maxSize, err = match.ParseSize(opts.MaxSize)
 		if err != nil {
 			return nil, fmt.Errorf("invalid max size: %w", err)
 		}
 	}
-	storageSet := map[string]struct{}{}
 	for _, sc := range opts.StorageClasses {
-		storageSet[sc] = struct{}{}
+		f.storageSet[sc] = struct{}{}
 	}
-	out := make([]enrichCandidate, 0, len(rows))
-	for _, row := range rows {
-		if !opts.IncludeDeleted && row.DeletedAt != nil {
-			continue
-		}
-		if opts.Pattern != "" {
-			ok, matchErr := doublestar.Match(opts.Pattern, row.RelKey)
-			if matchErr != nil {
-				return nil, matchErr
-			}
-			if !ok {
-				continue
-			}
+	return f, nil
+}
+
+// candidate reports whether row is HEAD work under the filter.
+func (f *candidateFilter) candidate(row indexsubstrate.CurrentObjectRow) (enrichCandidate, bool, error) {
+	if !f.opts.IncludeDeleted && row.DeletedAt != nil {
+		return enrichCandidate{}, false, nil
+	}
+	if f.opts.Pattern != "" {
+		ok, matchErr := doublestar.Match(f.opts.Pattern, row.RelKey)
+		if matchErr != nil {
+			return enrichCandidate{}, false, matchErr
 		}
-		if keyRe != nil && !keyRe.MatchString(row.RelKey) {
-			continue
+		if !ok {
+			return enrichCandidate{}, false, nil
 		}
-		if minSize > 0 && row.SizeBytes < minSize {
-			continue
+	}
+	if f.keyRe != nil && !f.keyRe.MatchString(row.RelKey) {
+		return enrichCandidate{}, false, nil
+	}
+	if f.minSize > 0 && row.SizeBytes < f.minSize {
+		return enrichCandidate{}, false, nil
+	}
+	if f.maxSize > 0 && row.SizeBytes > f.maxSize {
+		return enrichCandidate{}, false, nil
+	}
+	if len(f.storageSet) > 0 {
+		sc := ""
+		if row.StorageClass != nil {
+			sc = *row.StorageClass
 		}
-		if maxSize > 0 && row.SizeBytes > maxSize {
-			continue
+		if _, ok := f.storageSet[sc]; !ok {
+			return enrichCandidate{}, false, nil
 		}
-		if len(storageSet) > 0 {
-			sc := ""
-			if row.StorageClass != nil {
-				sc = *row.StorageClass
+	}
+	return enrichCandidate{
+		RelKey:         row.RelKey,
+		SizeBytes:      row.SizeBytes,
+		StorageClass:   row.StorageClass,
+		HeadEnrichedAt: row.HeadEnrichedAt,
+	}, true, nil
+}
+
+// candidateSource yields candidates in parent order and io.EOF after the last.
+type candidateSource func(ctx context.Context) (enrichCandidate, error)
+
+// parentCandidates filters a streamed parent into a candidateSource.
+func parentCandidates(parent indexsubstrate.ParentRowSource, filter *candidateFilter) candidateSource {
+	return func(ctx context.Context) (enrichCandidate, error) {
+		for {
+			row, err := parent.Next(ctx)
+			if err != nil {
+				return enrichCandidate{}, err
+			}
+			c, ok, err := filter.candidate(row)
+			if err != nil {
+				return enrichCandidate{}, err
 			}
-			if _, ok := storageSet[sc]; !ok {
-				continue
+			if ok {
+				return c, nil
 			}
 		}
-		out = append(out, enrichCandidate{
-			RelKey:         row.RelKey,
-			SizeBytes:      row.SizeBytes,
-			StorageClass:   row.StorageClass,
-			HeadEnrichedAt: row.HeadEnrichedAt,
-		})
 	}
-	return out, nil
 }
 
 type headSummary struct {
@@ -348,11 +392,15 @@ type headResult struct {
 	publicErr  error // sanitized error for StateSink and returned fatal errors
 }
 
-func executeHeads(ctx context.Context, cfg Config, candidates []enrichCandidate) (headSummary, []enrichUpdate, error) {
+// executeHeads runs HEAD over every candidate next yields and hands each
+// successful update to record as it completes. It returns a non-nil error for
+// a fatal stop (sink, record, parent read, or resumable provider failure); on
+// any error or failed candidate the recorded updates must be discarded.
+func executeHeads(ctx context.Context, cfg Config, next candidateSource, record func(enrichUpdate) error) (headSummary, error) {
 	workCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	summary := headSummary{candidates: int64(len(candidates))}
+	var summary headSummary
 	jobs := make(chan enrichCandidate)
 	results := make(chan headResult)
 
@@ -370,13 +418,25 @@ func executeHeads(ctx context.Context, cfg Config, candidates []enrichCandidate)
 			}
 		}()
 	}
+	// The feeder owns candidates and feedErr until results is closed.
+	var candidates int64
+	var feedErr error
 	go func() {
 		defer close(jobs)
-		for _, c := range candidates {
+		for {
+			c, err := next(workCtx)
+			if err != nil {
+				if !errors.Is(err, io.EOF) && workCtx.Err() == nil {
+					feedErr = fmt.Errorf("read parent rows: %w", err)
+					cancel()
+				}
+				return
+			}
 			select {
 			case <-workCtx.Done():
 				return
 			case jobs <- c:
+				candidates++
 			}
 		}
 	}()
@@ -385,14 +445,18 @@ func executeHeads(ctx context.Context, cfg Config, candidates []enrichCandidate)
 		close(results)
 	}()
 
-	var updates []enrichUpdate
 	var fatalErr error
 	for result := range results {
 		summary.headCalls += result.headCalls
 		switch result.status {
 		case "success":
 			summary.headSucceeded++
-			updates = append(updates, result.update)
+			if fatalErr == nil {
+				if err := record(result.update); err != nil {
+					fatalErr = fmt.Errorf("spill enrich update: %w", err)
+					cancel()
+				}
+			}
 		case "resume_skipped":
 			summary.resumeSkipped++
 		default:
@@ -416,17 +480,18 @@ func executeHeads(ctx context.Context, cfg Config, candidates []enrichCandidate)
 			}
 		}
 	}
+	summary.candidates = candidates
+	if feedErr != nil && fatalErr == nil {
+		fatalErr = feedErr
+	}
 	if fatalErr != nil {
-		// Atomic: discard updates; keep truthful HeadSucceeded count in summary.
-		return summary, nil, fatalErr
+		// Keep truthful HeadSucceeded count in summary; the caller discards updates.
+		return summary, fatalErr
 	}
 	if ctx.Err() != nil {
-		return summary, nil, ctx.Err()
-	}
-	if summary.failed > 0 {
-		return summary, nil, nil // atomic: discard updates
+		return summary, ctx.Err()
 	}
-	return summary, updates, nil
+	return summary, nil
 }
 
 func isResumable(err error) bool {
@@ -567,63 +632,95 @@ func emitState(cfg Config, result headResult) error {
 	return cfg.StateSink(ev)
 }
 
+// enrichJournal spills successful HEAD updates to the run's enrich-only
+// journal as they complete. It is created on the first update, so a run with
+// nothing to commit leaves no journal; discard removes it when the run does not
+// publish.
+type enrichJournal struct {
+	cfg       Config
+	runID     string
+	startedAt time.Time
+	dir       string
+	path      string
+	w         *indexsubstrate.JournalWriter
+	records   int64
+}
+
+func newEnrichJournal(cfg Config, runID string, startedAt time.Time) *enrichJournal {
+	dir := filepath.Join(cfg.JournalRoot, runID)
+	return &enrichJournal{cfg: cfg, runID: runID, startedAt: startedAt, dir: dir, path: filepath.Join(dir, "enrich.jsonl")}
+}
+
+func (j *enrichJournal) append(update enrichUpdate) error {
+	if j.w == nil {
+		if err := ensureDir(j.dir); err != nil {
+			return err
+		}
+		w, err := indexsubstrate.CreateJournal(j.path, indexsubstrate.JournalHeader{
+			Type:               indexsubstrate.JournalHeaderType,
+			JournalID:          "jrn_" + uuid.NewString(),
+			IndexSetID:         j.cfg.IndexSetID,
+			RunID:              j.runID,
+			Shard:              "enrich",
+			IndexSchemaVersion: indexsubstrate.IndexSchemaVersion,
+			StartedAt:          j.startedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("create enrich journal: %w", err)
+		}
+		j.w = w
+	}
+	enrichedAt := update.HeadEnrichedAt
+	if enrichedAt.IsZero() {
+		enrichedAt = j.cfg.Clock()
+	}
+	if _, err := j.w.Append(indexsubstrate.ObjectRecord{
+		Type:           indexsubstrate.ObjectRecordType,
+		Op:             indexsubstrate.ObjectRecordOpEnrich,
+		RelKey:         update.RelKey,
+		ObservedAt:     enrichedAt,
+		ContentType:    update.ContentType,
+		ArchiveStatus:  update.ArchiveStatus,
+		RestoreState:   update.RestoreState,
+		RestoreExpiry:  update.RestoreExpiry,
+		HeadEnrichedAt: &enrichedAt,
+	}); err != nil {
+		return err
+	}
+	j.records++
+	return nil
+}
+
+func (j *enrichJournal) seal() error {
+	if err := j.w.Seal(j.cfg.Clock()); err != nil {
+		return err
+	}
+	return j.w.Close()
+}
+
+// discard closes and removes an unpublished journal.
+func (j *enrichJournal) discard() error {
+	if j.w == nil {
+		return nil
+	}
+	_ = j.w.Close()
+	if err := os.RemoveAll(j.dir); err != nil {
+		return fmt.Errorf("remove unpublished enrich journal: %w", err)
+	}
+	return nil
+}
+
 func publishEnrich(
 	ctx context.Context,
 	cfg Config,
 	snap indexsubstrate.PublishedSnapshot,
-	priorRows []indexsubstrate.CurrentObjectRow,
 	parentToken indexsubstrate.ExpectedParentToken,
-	updates []enrichUpdate,
+	journalPath string,
 	runID string,
 	runStartedAt time.Time,
 	lease *indexsubstrate.WriteLease,
 	hooks runHooks,
 ) (indexsubstrate.PublishResult, error) {
-	journalDir := filepath.Join(cfg.JournalRoot, runID)
-	if err := ensureDir(journalDir); err != nil {
-		return indexsubstrate.PublishResult{}, err
-	}
-	journalPath := filepath.Join(journalDir, "enrich.jsonl")
-	jw, err := indexsubstrate.CreateJournal(journalPath, indexsubstrate.JournalHeader{
-		Type:               indexsubstrate.JournalHeaderType,
-		JournalID:          "jrn_" + uuid.NewString(),
-		IndexSetID:         cfg.IndexSetID,
-		RunID:              runID,
-		Shard:              "enrich",
-		IndexSchemaVersion: indexsubstrate.IndexSchemaVersion,
-		StartedAt:          runStartedAt,
-	})
-	if err != nil {
-		return indexsubstrate.PublishResult{}, fmt.Errorf("create enrich journal: %w", err)
-	}
-	for _, update := range updates {
-		enrichedAt := update.HeadEnrichedAt
-		if enrichedAt.IsZero() {
-			enrichedAt = cfg.Clock()
-		}
-		if _, err := jw.Append(indexsubstrate.ObjectRecord{
-			Type:           indexsubstrate.ObjectRecordType,
-			Op:             indexsubstrate.ObjectRecordOpEnrich,
-			RelKey:         update.RelKey,
-			ObservedAt:     enrichedAt,
-			ContentType:    update.ContentType,
-			ArchiveStatus:  update.ArchiveStatus,
-			RestoreState:   update.RestoreState,
-			RestoreExpiry:  update.RestoreExpiry,
-			HeadEnrichedAt: &enrichedAt,
-		}); err != nil {
-			_ = jw.Close()
-			return indexsubstrate.PublishResult{}, err
-		}
-	}
-	if err := jw.Seal(cfg.Clock()); err != nil {
-		_ = jw.Close()
-		return indexsubstrate.PublishResult{}, err
-	}
-	if err := jw.Close(); err != nil {
-		return indexsubstrate.PublishResult{}, err
-	}
-
 	runDir := filepath.Join(cfg.SegmentSetRoot, "runs", runID)
 	if err := ensureDir(runDir); err != nil {
 		return indexsubstrate.PublishResult{}, err
@@ -649,7 +746,9 @@ func publishEnrich(
 			RunID:          parentToken.RunID,
 			ManifestSHA256: parentToken.ManifestSHA256,
 		}},
-		PriorRows:            priorRows,
+		// A second pass over the same verified parent; the merge streams it
+		// into its workspace rather than materializing it.
+		ParentSource:         indexsubstrate.NewPublishedParentRowSource(snap),
 		JournalPaths:         []string{journalPath},
 		Coverage:             coverage,
 		SegmentDir:           runDir,
@@ -660,6 +759,8 @@ func publishEnrich(
 		ExpectedParent:       &parentToken,
 		WriteLease:           lease,
 		TargetRowsPerSegment: snap.Manifest.SegmentSizing.TargetRowsPerSegment,
+		SpillRoot:            cfg.Spill.Root,
+		SpillBudget:          cfg.Spill.budget(),
 		AfterStep:            after,
 	})
 	if err != nil {
@@ -405,6 +405,82 @@ func TestRunAllResumeSkippedNoPublish(t *testing.T) {
 	require.Equal(t, before, after)
 }
 
+// TestRunStreamsMultiSegmentParent proves candidates stream from every parent
+// segment and the enrich-only snapshot keeps every parent row.
+func TestRunStreamsMultiSegmentParent(t *testing.T) {
+	root := t.TempDir()
+	id := "idx_5555555555555555555555555555555555555555555555555555555555555555"
+	standard := "STANDARD"
+	rows := make([]indexsubstrate.CurrentObjectRow, 0, 250)
+	for i := 0; i < 250; i++ {
+		rows = append(rows, indexsubstrate.CurrentObjectRow{
+			IndexSetID: id, RelKey: fmt.Sprintf("hot/%04d.xml", i), SizeBytes: int64(i), ETag: fmt.Sprintf(`"%d"`, i), StorageClass: &standard,
+		})
+	}
+	seg, latest := seedDurableParent(t, root, id, rows)
+	parent, err := indexsubstrate.OpenLatestPublishedSnapshot(latest)
+	require.NoError(t, err)
+	require.Greater(t, len(parent.Manifest.Segments), 1)
+
+	prov := &fakeHeadProvider{}
+	res, err := Run(context.Background(), Config{
+		IndexSetID: id, BaseURI: "s3://bucket/data/", Provider: prov,
+		SegmentSetRoot: seg, JournalRoot: filepath.Join(root, "journals", id),
+		Query: QueryOptions{MinSize: "100"}, Parallel: 4,
+	})
+	require.NoError(t, err)
+	require.True(t, res.Published)
+	require.Equal(t, int64(150), res.Candidates)
+	require.Equal(t, int64(150), res.Committed)
+	require.Equal(t, 250, res.Rows)
+	require.Positive(t, res.PeakSpillBytes)
+
+	_, published, err := indexsubstrate.ReadLatestPublishedRows(latest)
+	require.NoError(t, err)
+	require.Len(t, published, 250)
+	for _, r := range published {
+		require.Equal(t, r.SizeBytes >= 100, r.HeadEnrichedAt != nil, r.RelKey)
+	}
+}
+
+func TestRunRefusesInvalidSpillBudgetBeforeHead(t *testing.T) {
+	root := t.TempDir()
+	id := "idx_6666666666666666666666666666666666666666666666666666666666666666"
+	standard := "STANDARD"
+	seg, _ := seedDurableParent(t, root, id, []indexsubstrate.CurrentObjectRow{
+		{IndexSetID: id, RelKey: "hot/a.xml", SizeBytes: 10, ETag: `"a"`, StorageClass: &standard},
+	})
+	prov := &fakeHeadProvider{}
+	_, err := Run(context.Background(), Config{
+		IndexSetID: id, BaseURI: "s3://bucket/data/", Provider: prov,
+		SegmentSetRoot: seg, JournalRoot: filepath.Join(root, "journals", id),
+		Spill: SpillConfig{WorkspaceBytes: -1},
+	})
+	var spillErr *indexsubstrate.SpillMergeError
+	require.ErrorAs(t, err, &spillErr)
+	require.Equal(t, indexsubstrate.SpillMergeInvalidConfig, spillErr.Category)
+	require.Zero(t, prov.headCalls.Load())
+}
+
+func TestRunDiscardsSpilledUpdatesOnHeadFailure(t *testing.T) {
+	root := t.TempDir()
+	id := "idx_7777777777777777777777777777777777777777777777777777777777777777"
+	standard := "STANDARD"
+	seg, _ := seedDurableParent(t, root, id, []indexsubstrate.CurrentObjectRow{
+		{IndexSetID: id, RelKey: "hot/a.xml", SizeBytes: 10, ETag: `"a"`, StorageClass: &standard},
+		{IndexSetID: id, RelKey: "hot/b.xml", SizeBytes: 20, ETag: `"b"`, StorageClass: &standard},
+	})
+	prov := &fakeHeadProvider{errs: map[string]error{"data/hot/b.xml": provider.ErrAccessDenied}}
+	journalRoot := filepath.Join(root, "journals", id)
+	res, err := Run(context.Background(), Config{
+		IndexSetID: id, BaseURI: "s3://bucket/data/", Provider: prov,
+		SegmentSetRoot: seg, JournalRoot: journalRoot, Parallel: 1,
+	})
+	require.Error(t, err)
+	require.Equal(t, int64(1), res.HeadSucceeded)
+	require.NoDirExists(t, filepath.Join(journalRoot, res.RunID))
+}
+
 func TestRunPreservesTombstonesAndNonHeadFields(t *testing.T) {
 	root := t.TempDir()
 	id := "idx_3333333333333333333333333333333333333333333333333333333333333333"
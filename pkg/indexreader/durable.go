@@ -3,12 +3,15 @@ package indexreader
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
@@ -23,6 +26,17 @@ type durableReader struct {
 	opts             ResolveOptions
 	snap             indexsubstrate.PublishedSnapshot
 	segmentCacheRoot string
+
+	// keyIndex is loaded on the first LookupKeys call and kept for the life of
+	// the reader. It stays nil for snapshots published without one.
+	keyIndexOnce sync.Once
+	keyIndex     *indexsubstrate.KeyIndexDocument
+	keyIndexErr  error
+
+	// segments holds each segment a lookup has verified, by manifest position,
+	// open until Close so later lookups seek without hashing it again.
+	segmentsMu sync.Mutex
+	segments   map[int]*indexsubstrate.VerifiedSegment
 }
 
 func openDurableReader(opts ResolveOptions, c candidate) (*durableReader, error) {
@@ -35,6 +49,10 @@ func openDurableReader(opts ResolveOptions, c candidate) (*durableReader, error)
 	meta.IndexSetID = snap.Manifest.IndexSetID
 	meta.RunID = snap.Manifest.RunID
 	meta.SourcePath = c.latest
+	meta.Selector, err = snapshotSelector(snap.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("open durable snapshot: %w", err)
+	}
 	return &durableReader{
 		meta:             meta,
 		opts:             opts,
@@ -77,6 +95,10 @@ func openPinnedDurableRun(opts ResolveOptions, target ResolveTarget) (*durableRe
 	if err != nil {
 		return nil, fmt.Errorf("open pinned durable run %s/%s: %w", fullID, target.RunID, err)
 	}
+	selector, err := snapshotSelector(snap.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("open pinned durable run %s/%s: %w", fullID, target.RunID, err)
+	}
 	identityDir := ""
 	baseURI := ""
 	provider := ""
@@ -118,6 +140,7 @@ func openPinnedDurableRun(opts ResolveOptions, target ResolveTarget) (*durableRe
 			IdentityDir: identityDir,
 			SourcePath:  completePath,
 			RunID:       snap.Manifest.RunID,
+			Selector:    selector,
 		},
 		opts:             opts,
 		snap:             snap,
@@ -125,6 +148,20 @@ func openPinnedDurableRun(opts ResolveOptions, target ResolveTarget) (*durableRe
 	}, nil
 }
 
+// snapshotSelector renders the manifest's observation selector, or "" for an
+// unfiltered snapshot. Coverage entries that disagree on the selector leave
+// the snapshot's universe unknown, so that is an error.
+func snapshotSelector(manifest indexsubstrate.InternalManifest) (string, error) {
+	selector, err := indexsubstrate.CoverageSelector(manifest.Coverage)
+	if err != nil {
+		return "", err
+	}
+	if selector == nil {
+		return "", nil
+	}
+	return selector.String(), nil
+}
+
 // validatePinnedRunID requires a single safe path component at the package seam
 // so ResolveTarget.RunID cannot traverse out of the runs/ directory. CLI
 // validateRunID is stricter (schema form) but is not the only call path.
@@ -152,7 +189,35 @@ func (r *durableReader) Meta() Meta { return r.meta }
 
 func (r *durableReader) SQLiteDB() *sql.DB { return nil }
 
-func (r *durableReader) Close() error { return nil }
+func (r *durableReader) Close() error {
+	r.segmentsMu.Lock()
+	defer r.segmentsMu.Unlock()
+	var errs []error
+	for _, segment := range r.segments {
+		errs = append(errs, segment.Close())
+	}
+	r.segments = nil
+	return errors.Join(errs...)
+}
+
+// verifiedSegment returns segment i of the snapshot, verifying it against its
+// manifest digest the first time it is asked for.
+func (r *durableReader) verifiedSegment(i int) (*indexsubstrate.VerifiedSegment, error) {
+	r.segmentsMu.Lock()
+	defer r.segmentsMu.Unlock()
+	if segment, ok := r.segments[i]; ok {
+		return segment, nil
+	}
+	segment, err := indexsubstrate.OpenSegmentVerified(r.snap.SegmentDir, r.snap.Manifest.Segments[i])
+	if err != nil {
+		return nil, err
+	}
+	if r.segments == nil {
+		r.segments = map[int]*indexsubstrate.VerifiedSegment{}
+	}
+	r.segments[i] = segment
+	return segment, nil
+}
 
 func (r *durableReader) ResolveSinceRunFilter(ctx context.Context, runID string) (*indexstore.SinceRunFilter, error) {
 	_ = ctx
@@ -255,14 +320,84 @@ func (r *durableReader) QueryCanonicalObjects(ctx context.Context, params indexs
 	// O(distinct non-empty ETags) plus alternates for multi-member groups.
 	filterParams := params
 	filterParams.Limit = 0
+	filterParams.StartAfter = ""
 	results, queryStats, err := r.QueryObjects(ctx, filterParams)
 	if err != nil {
 		return nil, indexstore.CanonicalQueryStats{}, err
 	}
-	outputs, stats := groupCanonical(results, rule, params.Limit, queryStats)
+	outputs, stats := groupCanonical(results, rule, params.StartAfter, params.Limit, queryStats)
 	return outputs, stats, nil
 }
 
+func (r *durableReader) LookupKeys(ctx context.Context, keys []string) ([]indexstore.QueryResult, indexstore.QueryStats, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.keyIndexOnce.Do(func() {
+		doc, ok, err := indexsubstrate.ReadPublishedKeyIndex(r.snap)
+		if err != nil {
+			r.keyIndexErr = fmt.Errorf("read key index: %w", err)
+		} else if ok {
+			r.keyIndex = &doc
+		}
+	})
+	if r.keyIndexErr != nil {
+		return nil, indexstore.QueryStats{}, r.keyIndexErr
+	}
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	// Segment rows carry typed timestamps and fail to decode rather than
+	// yielding unparseable ones, so only the key count applies here.
+	stats := indexstore.QueryStats{KeysLookedUp: int64(len(keys))}
+	var results []indexstore.QueryResult
+	for i, segment := range r.snap.Manifest.Segments {
+		if err := ctx.Err(); err != nil {
+			return nil, indexstore.QueryStats{}, err
+		}
+		var index *indexsubstrate.SegmentKeyIndex
+		if r.keyIndex != nil {
+			// ReadPublishedKeyIndex binds entries to segments in manifest order.
+			index = &r.keyIndex.Segments[i]
+		}
+		candidates := index.Candidates(keysInSegmentRange(keys, segment))
+		if len(candidates) == 0 {
+			continue
+		}
+		verified, err := r.verifiedSegment(i)
+		if err != nil {
+			return nil, indexstore.QueryStats{}, err
+		}
+		if err := verified.LookupKeys(index, candidates, func(row indexsubstrate.CurrentObjectRow) error {
+			result, ok, err := filterCurrentRow(row, nil, indexstore.QueryParams{})
+			if err != nil || !ok {
+				return err
+			}
+			results = append(results, result)
+			return nil
+		}); err != nil {
+			return nil, indexstore.QueryStats{}, err
+		}
+	}
+	return results, stats, nil
+}
+
+// keysInSegmentRange returns the sub-slice of sorted keys that fall within the
+// segment's [MinRelKey, MaxRelKey]. Descriptors without bounds keep every key.
+func keysInSegmentRange(keys []string, segment indexsubstrate.SegmentDescriptor) []string {
+	lo, hi := 0, len(keys)
+	if segment.MinRelKey != "" {
+		lo = sort.SearchStrings(keys, segment.MinRelKey)
+	}
+	if segment.MaxRelKey != "" {
+		hi = sort.Search(len(keys), func(i int) bool { return keys[i] > segment.MaxRelKey })
+	}
+	if lo >= hi {
+		return nil
+	}
+	return keys[lo:hi]
+}
+
 var errStopWalk = fmt.Errorf("stop walk")
 
 func (r *durableReader) walkFiltered(ctx context.Context, filter *rowFilter, params indexstore.QueryParams, visit func(indexstore.QueryResult) error) error {
@@ -310,6 +445,7 @@ type rowFilter struct {
 	enrichedAfter  time.Time
 	includeDeleted bool
 	sinceRun       *indexstore.SinceRunFilter
+	startAfter     string
 }
 
 func compileRowFilter(params indexstore.QueryParams) (*rowFilter, error) {
@@ -322,6 +458,7 @@ func compileRowFilter(params indexstore.QueryParams) (*rowFilter, error) {
 		enrichedAfter:  params.EnrichedAfter,
 		includeDeleted: params.IncludeDeleted,
 		sinceRun:       params.SinceRun,
+		startAfter:     params.StartAfter,
 	}
 	if params.Pattern != "" {
 		if !doublestar.ValidatePattern(params.Pattern) {
@@ -349,7 +486,14 @@ func compileRowFilter(params indexstore.QueryParams) (*rowFilter, error) {
 }
 
 func segmentMayMatch(segment indexsubstrate.SegmentDescriptor, filter *rowFilter) bool {
-	if filter == nil || filter.prefix == "" {
+	if filter == nil {
+		return true
+	}
+	// Resumed walks skip every segment that ends at or before the cursor.
+	if filter.startAfter != "" && segment.MaxRelKey != "" && segment.MaxRelKey <= filter.startAfter {
+		return false
+	}
+	if filter.prefix == "" {
 		return true
 	}
 	// Safe skip: every key in the segment is lexicographically before the prefix,
@@ -368,6 +512,9 @@ func filterCurrentRow(row indexsubstrate.CurrentObjectRow, filter *rowFilter, pa
 	if !filter.includeDeleted && row.DeletedAt != nil {
 		return indexstore.QueryResult{}, false, nil
 	}
+	if filter.startAfter != "" && row.RelKey <= filter.startAfter {
+		return indexstore.QueryResult{}, false, nil
+	}
 	if filter.prefix != "" && !strings.HasPrefix(row.RelKey, filter.prefix) {
 		return indexstore.QueryResult{}, false, nil
 	}
@@ -456,7 +603,7 @@ func changeKind(result indexstore.QueryResult, filter *indexstore.SinceRunFilter
 	return ""
 }
 
-func groupCanonical(results []indexstore.QueryResult, rule indexstore.CanonicalTieBreak, limit int, queryStats indexstore.QueryStats) ([]indexstore.CanonicalOutputRecord, indexstore.CanonicalQueryStats) {
+func groupCanonical(results []indexstore.QueryResult, rule indexstore.CanonicalTieBreak, startAfter string, limit int, queryStats indexstore.QueryStats) ([]indexstore.CanonicalOutputRecord, indexstore.CanonicalQueryStats) {
 	groups := map[string][]indexstore.QueryResult{}
 	outputs := make([]indexstore.CanonicalOutputRecord, 0, len(results))
 	for _, result := range results {
@@ -474,6 +621,10 @@ func groupCanonical(results []indexstore.QueryResult, rule indexstore.CanonicalT
 	sort.SliceStable(outputs, func(i, j int) bool {
 		return canonicalRelKey(outputs[i]) < canonicalRelKey(outputs[j])
 	})
+	if startAfter != "" {
+		skip := sort.Search(len(outputs), func(i int) bool { return canonicalRelKey(outputs[i]) > startAfter })
+		outputs = outputs[skip:]
+	}
 	if limit > 0 && len(outputs) > limit {
 		outputs = outputs[:limit]
 	}
@@ -3,6 +3,7 @@ package indexreader
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -126,6 +127,158 @@ func TestDurableQuery_CanonicalByETag(t *testing.T) {
 	require.Len(t, out, 2)
 }
 
+func TestDurableQuery_StartAfter(t *testing.T) {
+	ctx := context.Background()
+	mod := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	env := setupDurableTestEnv(t, []indexsubstrate.CurrentObjectRow{
+		durableRow("a/1.txt", 1, "e1", mod),
+		durableRow("a/2.txt", 2, "e2", mod),
+		durableRow("b/1.txt", 3, "e3", mod),
+	})
+	reader, err := ResolveIndexReader(ctx, env.opts, ResolveTarget{IndexSetID: env.indexSetID})
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	var keys []string
+	_, err = reader.WalkObjects(ctx, indexstore.QueryParams{
+		IndexSetID: env.indexSetID,
+		StartAfter: "a/1.txt",
+	}, func(result indexstore.QueryResult) error {
+		keys = append(keys, result.RelKey)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a/2.txt", "b/1.txt"}, keys)
+
+	count, err := reader.QueryObjectCount(ctx, indexstore.QueryParams{IndexSetID: env.indexSetID, StartAfter: "b/1.txt"})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// Canonical output pages by each record's canonical rel_key.
+	out, stats, err := reader.QueryCanonicalObjects(ctx, indexstore.QueryParams{
+		IndexSetID:        env.indexSetID,
+		CanonicalTieBreak: indexstore.CanonicalTieBreakMinKey,
+		StartAfter:        "a/1.txt",
+		Limit:             1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.TotalRecords)
+	require.Len(t, out, 1)
+	require.NotNil(t, out[0].Group)
+	require.Equal(t, "a/2.txt", out[0].Group.Canonical.RelKey)
+}
+
+// TestDurableLookupKeys_OpensOnlyCandidateSegments pins that point lookups
+// prune by key range and the published bloom filters: a corrupt segment that
+// no requested key can be in is never read.
+func TestDurableLookupKeys_OpensOnlyCandidateSegments(t *testing.T) {
+	ctx := context.Background()
+	mod := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []indexsubstrate.CurrentObjectRow
+	for i := 0; i < 250; i++ {
+		rows = append(rows, durableRow(fmt.Sprintf("k/%04d", i*2), int64(i), fmt.Sprintf("e%d", i), mod))
+	}
+	env := setupDurableTestEnv(t, rows)
+	reader, err := ResolveIndexReader(ctx, env.opts, ResolveTarget{IndexSetID: env.indexSetID})
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+
+	runDir := filepath.Join(env.segmentRoot, "runs", env.runID)
+	manifest, err := indexsubstrate.ReadInternalManifestFile(filepath.Join(runDir, "manifest.json"))
+	require.NoError(t, err)
+	require.Len(t, manifest.Segments, 3)
+	require.NotNil(t, manifest.KeyIndex)
+	middle := filepath.Join(runDir, manifest.Segments[1].Path)
+	require.NoError(t, os.Chmod(middle, 0o600))
+	require.NoError(t, os.WriteFile(middle, []byte("corrupt"), 0o600))
+
+	// k/0201 sits inside the middle segment's range but is not indexed.
+	results, stats, err := reader.LookupKeys(ctx, []string{"k/0498", "k/0000", "k/0201", "k/0000", "absent"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.EqualValues(t, 4, stats.KeysLookedUp, "the repeated k/0000 counts once")
+	require.Equal(t, "k/0000", results[0].RelKey)
+	require.Equal(t, "e0", results[0].ETag)
+	require.Equal(t, "k/0498", results[1].RelKey)
+
+	_, _, err = reader.LookupKeys(ctx, []string{"k/0200"})
+	require.ErrorContains(t, err, "segment digest mismatch")
+}
+
+// TestDurableLookupKeys_VerifiesEachSegmentOncePerReader pins that a reader
+// hashes a segment on its first lookup only and serves later lookups from the
+// handle it verified, while a fresh reader verifies again.
+func TestDurableLookupKeys_VerifiesEachSegmentOncePerReader(t *testing.T) {
+	ctx := context.Background()
+	mod := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	env := setupDurableTestEnv(t, []indexsubstrate.CurrentObjectRow{
+		durableRow("a.txt", 1, "ea", mod),
+		durableRow("b.txt", 2, "eb", mod),
+	})
+	reader, err := ResolveIndexReader(ctx, env.opts, ResolveTarget{IndexSetID: env.indexSetID})
+	require.NoError(t, err)
+	defer func() { _ = reader.Close() }()
+	results, _, err := reader.LookupKeys(ctx, []string{"a.txt"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// Swap the segment for different bytes under the same name. The open
+	// reader never re-reads the path; a new one rejects the swap.
+	runDir := filepath.Join(env.segmentRoot, "runs", env.runID)
+	manifest, err := indexsubstrate.ReadInternalManifestFile(filepath.Join(runDir, "manifest.json"))
+	require.NoError(t, err)
+	segmentPath := filepath.Join(runDir, manifest.Segments[0].Path)
+	swapped := segmentPath + ".swap"
+	require.NoError(t, os.WriteFile(swapped, []byte("corrupt"), 0o600))
+	require.NoError(t, os.Rename(swapped, segmentPath))
+
+	results, _, err = reader.LookupKeys(ctx, []string{"b.txt"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, "eb", results[0].ETag)
+
+	fresh, err := ResolveIndexReader(ctx, env.opts, ResolveTarget{IndexSetID: env.indexSetID})
+	require.NoError(t, err)
+	defer func() { _ = fresh.Close() }()
+	_, _, err = fresh.LookupKeys(ctx, []string{"b.txt"})
+	require.ErrorContains(t, err, "segment digest mismatch")
+}
+
+// TestDurableOpenRefusesDisagreeingCoverageSelectors keeps a snapshot whose
+// coverage records two observation selectors from opening, rather than
+// reporting the conflict as its selector.
+func TestDurableOpenRefusesDisagreeingCoverageSelectors(t *testing.T) {
+	ctx := context.Background()
+	env := setupDurableTestEnv(t, []indexsubstrate.CurrentObjectRow{
+		durableRow("a.txt", 1, "e", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	})
+	runDir := filepath.Join(env.segmentRoot, "runs", env.runID)
+	manifestPath := filepath.Join(runDir, "manifest.json")
+	manifest, err := indexsubstrate.ReadInternalManifestFile(manifestPath)
+	require.NoError(t, err)
+	manifest.Coverage = []indexsubstrate.CoverageAttestation{
+		{Basis: indexsubstrate.CoverageBasisConfirmed, Complete: true, Selector: &indexsubstrate.ObservationSelector{Includes: []string{"a/**"}}},
+		{Basis: indexsubstrate.CoverageBasisConfirmed, Complete: true, Selector: &indexsubstrate.ObservationSelector{Includes: []string{"b/**"}}},
+	}
+	require.NoError(t, os.Remove(manifestPath))
+	require.NoError(t, indexsubstrate.WriteInternalManifestFile(manifestPath, manifest))
+	manifestSHA, err := hashFileSHA256(manifestPath)
+	require.NoError(t, err)
+	completePath := filepath.Join(runDir, "complete.json")
+	var complete map[string]any
+	raw, err := os.ReadFile(completePath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &complete))
+	complete["manifest_sha256"] = manifestSHA
+	writeJSON(t, completePath, complete)
+
+	_, err = ResolveIndexReader(ctx, env.opts, ResolveTarget{IndexSetID: env.indexSetID})
+	require.ErrorIs(t, err, indexsubstrate.ErrInvalidCoverage)
+
+	_, err = ResolveIndexReader(ctx, env.opts, ResolveTarget{IndexSetID: env.indexSetID, RunID: env.runID})
+	require.ErrorIs(t, err, indexsubstrate.ErrInvalidCoverage)
+}
+
 func TestDurablePreferredWhenBothPresent(t *testing.T) {
 	ctx := context.Background()
 	env := setupDurableTestEnv(t, []indexsubstrate.CurrentObjectRow{
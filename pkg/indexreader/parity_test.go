@@ -181,6 +181,20 @@ func TestDurableSQLiteFullQueryResultParity(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, len(sqlRows), sqlCount)
 
+	// Point lookups return the same rows; tombstones and unknown keys are absent.
+	lookupKeys := []string{"gone.txt", "missing.json", "data/b.xml", "data/a.json", "data/a.json"}
+	sqlLookup, sqlLookupStats, err := sqliteReader.LookupKeys(ctx, lookupKeys)
+	require.NoError(t, err)
+	durLookup, durLookupStats, err := durableReader.LookupKeys(ctx, lookupKeys)
+	require.NoError(t, err)
+	require.Equal(t, sqlLookupStats, durLookupStats)
+	require.EqualValues(t, 4, durLookupStats.KeysLookedUp)
+	require.Len(t, sqlLookup, 2)
+	require.Len(t, durLookup, len(sqlLookup))
+	for i := range sqlLookup {
+		assertQueryResultEqual(t, sqlLookup[i], durLookup[i])
+	}
+
 	// Canonical tie-break min-key + alternates envelope.
 	canonParams := indexstore.QueryParams{
 		IndexSetID:        env.indexSetID,
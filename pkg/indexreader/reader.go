@@ -32,6 +32,12 @@ type Meta struct {
 	SourcePath string
 	// RunID is the durable snapshot run id when Format is durable-v2.
 	RunID string
+	// Selector describes the observation selector a filtered durable-v2
+	// snapshot was built under (match excludes, non-default includes,
+	// include_hidden, or filters). Empty when the snapshot is unfiltered:
+	// objects the selector dropped were never indexed, so their absence is not
+	// evidence that they do not exist.
+	Selector string
 }
 
 // VisitObject is invoked for each matching row in rel_key order.
@@ -62,6 +68,13 @@ type Reader interface {
 	// O(matched rows) with selection/output bounded by O(distinct non-empty
 	// ETags) plus alternates storage for non-canonical group members.
 	QueryCanonicalObjects(ctx context.Context, params indexstore.QueryParams) ([]indexstore.CanonicalOutputRecord, indexstore.CanonicalQueryStats, error)
+	// LookupKeys returns the current, non-deleted rows for the given rel_keys
+	// in rel_key order; keys that are not indexed are absent from the result.
+	// On durable-v2, segments outside a key's min/max range or excluded by the
+	// published key index are never opened, and each remaining segment seeks
+	// to its sparse key samples instead of scanning. Snapshots published
+	// before the key index existed fall back to range-pruned scans.
+	LookupKeys(ctx context.Context, keys []string) ([]indexstore.QueryResult, indexstore.QueryStats, error)
 	// ResolveSinceRunFilter validates a --since-run boundary for this index.
 	// Durable-v2 currently fails closed: use sqlite-v1 or build with --format both.
 	ResolveSinceRunFilter(ctx context.Context, runID string) (*indexstore.SinceRunFilter, error)
@@ -104,6 +104,17 @@ func (r *sqliteReader) QueryCanonicalObjects(ctx context.Context, params indexst
 	return results, stats, err
 }
 
+func (r *sqliteReader) LookupKeys(ctx context.Context, keys []string) ([]indexstore.QueryResult, indexstore.QueryStats, error) {
+	if err := r.snapshot.Check(); err != nil {
+		return nil, indexstore.QueryStats{}, err
+	}
+	results, stats, err := indexstore.LookupObjects(ctx, r.db, r.meta.IndexSetID, keys)
+	if err == nil {
+		err = r.snapshot.Check()
+	}
+	return results, stats, err
+}
+
 func (r *sqliteReader) ResolveSinceRunFilter(ctx context.Context, runID string) (*indexstore.SinceRunFilter, error) {
 	if err := r.snapshot.Check(); err != nil {
 		return nil, err
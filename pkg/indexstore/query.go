@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -62,6 +63,12 @@ type QueryParams struct {
 	// historical reconstruction.
 	SinceRun *SinceRunFilter
 
+	// StartAfter limits results to rel_keys strictly greater than this value,
+	// so a paged reader can resume a walk from the last key it received.
+	// Optional. Canonical (group-by-ETag) queries apply it after grouping, to
+	// each output's canonical rel_key: grouping needs the full filtered set.
+	StartAfter string
+
 	// Limit caps the number of results returned.
 	// Optional. Zero means no limit.
 	Limit int
@@ -146,6 +153,10 @@ type QueryStats struct {
 	// TimestampParseErrors is the count of rows with unparseable timestamps.
 	// These rows are included in results but with nil timestamp fields.
 	TimestampParseErrors int64
+
+	// KeysLookedUp is the number of distinct keys a point lookup probed, after
+	// duplicates collapse. Zero for filtered queries.
+	KeysLookedUp int64
 }
 
 // HeadEnrichmentCandidate is an object row selected for HEAD enrichment.
@@ -196,10 +207,7 @@ func QueryObjects(ctx context.Context, db *sql.DB, params QueryParams) ([]QueryR
 	needsClientFilter := params.Pattern != "" || keyRe != nil
 
 	// Build SQL query with filters that can be pushed to DB
-	query := `SELECT rel_key, size_bytes, last_modified, etag, storage_class,
-		       archive_status, restore_state, restore_expiry, content_type, head_enriched_at,
-		       first_seen_run_id, first_seen_at, last_changed_run_id, last_changed_at,
-		       deleted_at
+	query := `SELECT ` + queryResultColumns + `
 		FROM objects_current
 		WHERE index_set_id = ?`
 	args := []interface{}{params.IndexSetID}
@@ -246,6 +254,7 @@ func QueryObjects(ctx context.Context, db *sql.DB, params QueryParams) ([]QueryR
 		args = append(args, timeString(params.EnrichedAfter))
 	}
 	query = appendSinceRunFilterSQL(query, &args, params.SinceRun)
+	query = appendStartAfterFilterSQL(query, &args, params.StartAfter)
 
 	query += ` ORDER BY rel_key`
 
@@ -263,33 +272,14 @@ func QueryObjects(ctx context.Context, db *sql.DB, params QueryParams) ([]QueryR
 
 	var results []QueryResult
 	for rows.Next() {
-		var (
-			relKey           string
-			sizeBytes        int64
-			lastModified     sql.NullString
-			etag             sql.NullString
-			storageClass     sql.NullString
-			archiveStatus    sql.NullString
-			restoreState     sql.NullString
-			restoreExpiry    sql.NullString
-			contentType      sql.NullString
-			headEnrichedAt   sql.NullString
-			firstSeenRunID   sql.NullString
-			firstSeenAt      sql.NullString
-			lastChangedRunID sql.NullString
-			lastChangedAt    sql.NullString
-			deletedAt        sql.NullString
-		)
-
-		if err := rows.Scan(&relKey, &sizeBytes, &lastModified, &etag, &storageClass,
-			&archiveStatus, &restoreState, &restoreExpiry, &contentType, &headEnrichedAt,
-			&firstSeenRunID, &firstSeenAt, &lastChangedRunID, &lastChangedAt, &deletedAt); err != nil {
-			return nil, stats, fmt.Errorf("scan row: %w", err)
+		var scanned queryResultRow
+		if err := scanned.scan(rows); err != nil {
+			return nil, stats, err
 		}
 
 		// Apply glob pattern filter (client-side, after prefix pushdown)
 		if params.Pattern != "" {
-			matched, err := doublestar.Match(params.Pattern, relKey)
+			matched, err := doublestar.Match(params.Pattern, scanned.relKey)
 			if err != nil {
 				return nil, stats, fmt.Errorf("match pattern: %w", err)
 			}
@@ -299,76 +289,13 @@ func QueryObjects(ctx context.Context, db *sql.DB, params QueryParams) ([]QueryR
 		}
 
 		// Apply regex filter (client-side)
-		if keyRe != nil && !keyRe.MatchString(relKey) {
+		if keyRe != nil && !keyRe.MatchString(scanned.relKey) {
 			continue
 		}
 
-		result := QueryResult{
-			RelKey:    relKey,
-			SizeBytes: sizeBytes,
-		}
-
-		// Parse timestamps - warn+skip on failure (don't fail the query)
-		// This handles varied timestamp formats from different providers gracefully.
-		if lastModified.Valid {
-			if t, err := parseTimestamp(lastModified.String); err == nil {
-				result.LastModified = &t
-			} else {
-				stats.TimestampParseErrors++
-			}
-		}
-
-		if etag.Valid {
-			result.ETag = etag.String
-		}
-		result.StorageClass = nullStringPtr(storageClass)
-		result.ArchiveStatus = nullStringPtr(archiveStatus)
-		result.RestoreState = nullStringPtr(restoreState)
-		result.ContentType = nullStringPtr(contentType)
-		if restoreExpiry.Valid {
-			if t, err := parseTimestamp(restoreExpiry.String); err == nil {
-				result.RestoreExpiry = &t
-			} else {
-				stats.TimestampParseErrors++
-			}
-		}
-		if headEnrichedAt.Valid {
-			if t, err := parseTimestamp(headEnrichedAt.String); err == nil {
-				result.HeadEnrichedAt = &t
-			} else {
-				stats.TimestampParseErrors++
-			}
-		}
-		if firstSeenRunID.Valid {
-			result.FirstSeenRunID = firstSeenRunID.String
-		}
-		if firstSeenAt.Valid {
-			if t, err := parseTimestamp(firstSeenAt.String); err == nil {
-				result.FirstSeenAt = &t
-			} else {
-				stats.TimestampParseErrors++
-			}
-		}
-		if lastChangedRunID.Valid {
-			result.LastChangedRunID = lastChangedRunID.String
-		}
-		if lastChangedAt.Valid {
-			if t, err := parseTimestamp(lastChangedAt.String); err == nil {
-				result.LastChangedAt = &t
-			} else {
-				stats.TimestampParseErrors++
-			}
-		}
+		result := scanned.result(&stats)
 		result.ChangeKind = queryChangeKind(result, params.SinceRun)
 
-		if deletedAt.Valid {
-			if t, err := parseTimestamp(deletedAt.String); err == nil {
-				result.DeletedAt = &t
-			} else {
-				stats.TimestampParseErrors++
-			}
-		}
-
 		results = append(results, result)
 
 		// Apply limit after all client-side filters
@@ -384,6 +311,162 @@ func QueryObjects(ctx context.Context, db *sql.DB, params QueryParams) ([]QueryR
 	return results, stats, nil
 }
 
+// lookupBatchSize bounds the rel_key IN (...) list of one LookupObjects
+// statement, well under SQLite's bound-parameter limit.
+const lookupBatchSize = 500
+
+// LookupObjects returns the current, non-deleted rows whose rel_key is one of
+// keys, in rel_key order. Keys not in the index are simply absent from the
+// result; duplicate keys are looked up once. Each batch of keys is a primary
+// key probe, so cost follows len(keys) rather than the index size.
+func LookupObjects(ctx context.Context, db *sql.DB, indexSetID string, keys []string) ([]QueryResult, QueryStats, error) {
+	var stats QueryStats
+	if indexSetID == "" {
+		return nil, stats, fmt.Errorf("index_set_id is required")
+	}
+	keys = slices.Clone(keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	stats.KeysLookedUp = int64(len(keys))
+	var results []QueryResult
+	for start := 0; start < len(keys); start += lookupBatchSize {
+		batch := keys[start:min(start+lookupBatchSize, len(keys))]
+		args := make([]interface{}, 0, len(batch)+1)
+		args = append(args, indexSetID)
+		for _, key := range batch {
+			args = append(args, key)
+		}
+		query := `SELECT ` + queryResultColumns + `
+		FROM objects_current
+		WHERE index_set_id = ? AND deleted_at IS NULL
+		  AND rel_key IN (?` + strings.Repeat(`, ?`, len(batch)-1) + `)
+		ORDER BY rel_key`
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, stats, fmt.Errorf("lookup objects: %w", err)
+		}
+		for rows.Next() {
+			var scanned queryResultRow
+			if err := scanned.scan(rows); err != nil {
+				_ = rows.Close()
+				return nil, stats, err
+			}
+			results = append(results, scanned.result(&stats))
+		}
+		err = rows.Err()
+		_ = rows.Close()
+		if err != nil {
+			return nil, stats, fmt.Errorf("iterate rows: %w", err)
+		}
+	}
+	return results, stats, nil
+}
+
+// queryResultColumns is the objects_current projection scanned by
+// queryResultRow.
+const queryResultColumns = `rel_key, size_bytes, last_modified, etag, storage_class,
+		       archive_status, restore_state, restore_expiry, content_type, head_enriched_at,
+		       first_seen_run_id, first_seen_at, last_changed_run_id, last_changed_at,
+		       deleted_at`
+
+// queryResultRow holds one scanned queryResultColumns row. Timestamps stay raw
+// until result so client-side key filters run before parse failures count.
+type queryResultRow struct {
+	relKey           string
+	sizeBytes        int64
+	lastModified     sql.NullString
+	etag             sql.NullString
+	storageClass     sql.NullString
+	archiveStatus    sql.NullString
+	restoreState     sql.NullString
+	restoreExpiry    sql.NullString
+	contentType      sql.NullString
+	headEnrichedAt   sql.NullString
+	firstSeenRunID   sql.NullString
+	firstSeenAt      sql.NullString
+	lastChangedRunID sql.NullString
+	lastChangedAt    sql.NullString
+	deletedAt        sql.NullString
+}
+
+func (r *queryResultRow) scan(rows *sql.Rows) error {
+	if err := rows.Scan(&r.relKey, &r.sizeBytes, &r.lastModified, &r.etag, &r.storageClass,
+		&r.archiveStatus, &r.restoreState, &r.restoreExpiry, &r.contentType, &r.headEnrichedAt,
+		&r.firstSeenRunID, &r.firstSeenAt, &r.lastChangedRunID, &r.lastChangedAt, &r.deletedAt); err != nil {
+		return fmt.Errorf("scan row: %w", err)
+	}
+	return nil
+}
+
+// result converts the row, counting unparseable timestamps in stats. Those
+// fields are left nil rather than failing the query.
+func (r *queryResultRow) result(stats *QueryStats) QueryResult {
+	result := QueryResult{
+		RelKey:    r.relKey,
+		SizeBytes: r.sizeBytes,
+	}
+
+	// Parse timestamps - warn+skip on failure (don't fail the query)
+	// This handles varied timestamp formats from different providers gracefully.
+	if r.lastModified.Valid {
+		if t, err := parseTimestamp(r.lastModified.String); err == nil {
+			result.LastModified = &t
+		} else {
+			stats.TimestampParseErrors++
+		}
+	}
+
+	if r.etag.Valid {
+		result.ETag = r.etag.String
+	}
+	result.StorageClass = nullStringPtr(r.storageClass)
+	result.ArchiveStatus = nullStringPtr(r.archiveStatus)
+	result.RestoreState = nullStringPtr(r.restoreState)
+	result.ContentType = nullStringPtr(r.contentType)
+	if r.restoreExpiry.Valid {
+		if t, err := parseTimestamp(r.restoreExpiry.String); err == nil {
+			result.RestoreExpiry = &t
+		} else {
+			stats.TimestampParseErrors++
+		}
+	}
+	if r.headEnrichedAt.Valid {
+		if t, err := parseTimestamp(r.headEnrichedAt.String); err == nil {
+			result.HeadEnrichedAt = &t
+		} else {
+			stats.TimestampParseErrors++
+		}
+	}
+	if r.firstSeenRunID.Valid {
+		result.FirstSeenRunID = r.firstSeenRunID.String
+	}
+	if r.firstSeenAt.Valid {
+		if t, err := parseTimestamp(r.firstSeenAt.String); err == nil {
+			result.FirstSeenAt = &t
+		} else {
+			stats.TimestampParseErrors++
+		}
+	}
+	if r.lastChangedRunID.Valid {
+		result.LastChangedRunID = r.lastChangedRunID.String
+	}
+	if r.lastChangedAt.Valid {
+		if t, err := parseTimestamp(r.lastChangedAt.String); err == nil {
+			result.LastChangedAt = &t
+		} else {
+			stats.TimestampParseErrors++
+		}
+	}
+	if r.deletedAt.Valid {
+		if t, err := parseTimestamp(r.deletedAt.String); err == nil {
+			result.DeletedAt = &t
+		} else {
+			stats.TimestampParseErrors++
+		}
+	}
+	return result
+}
+
 // QueryHeadEnrichmentCandidates returns post-filter rows that are eligible for
 // HEAD enrichment. Storage class, size, deleted, glob, and regex semantics match
 // QueryObjects; rows are returned in rel_key order.
@@ -433,6 +516,7 @@ func QueryHeadEnrichmentCandidates(ctx context.Context, db *sql.DB, params Query
 	}
 	query = appendStorageClassFilterSQL(query, &args, params.StorageClasses)
 	query = appendSinceRunFilterSQL(query, &args, params.SinceRun)
+	query = appendStartAfterFilterSQL(query, &args, params.StartAfter)
 	query += ` ORDER BY rel_key`
 
 	rows, err := db.QueryContext(ctx, query, args...)
@@ -497,6 +581,7 @@ func QueryCanonicalObjects(ctx context.Context, db *sql.DB, params QueryParams)
 
 	filterParams := params
 	filterParams.Limit = 0
+	filterParams.StartAfter = ""
 	results, queryStats, err := QueryObjects(ctx, db, filterParams)
 	if err != nil {
 		return nil, CanonicalQueryStats{}, err
@@ -522,6 +607,11 @@ func QueryCanonicalObjects(ctx context.Context, db *sql.DB, params QueryParams)
 		return canonicalOutputRelKey(outputs[i]) < canonicalOutputRelKey(outputs[j])
 	})
 
+	if params.StartAfter != "" {
+		skip := sort.Search(len(outputs), func(i int) bool { return canonicalOutputRelKey(outputs[i]) > params.StartAfter })
+		outputs = outputs[skip:]
+	}
+
 	if params.Limit > 0 && len(outputs) > params.Limit {
 		outputs = outputs[:params.Limit]
 	}
@@ -763,6 +853,7 @@ func queryCountFast(ctx context.Context, db *sql.DB, params QueryParams) (int64,
 		args = append(args, timeString(params.EnrichedAfter))
 	}
 	query = appendSinceRunFilterSQL(query, &args, params.SinceRun)
+	query = appendStartAfterFilterSQL(query, &args, params.StartAfter)
 
 	var count int64
 	if err := db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
@@ -814,6 +905,7 @@ func queryCountStreaming(ctx context.Context, db *sql.DB, params QueryParams, ke
 		args = append(args, timeString(params.EnrichedAfter))
 	}
 	query = appendSinceRunFilterSQL(query, &args, params.SinceRun)
+	query = appendStartAfterFilterSQL(query, &args, params.StartAfter)
 
 	// No ORDER BY for counting - unnecessary overhead at scale
 
@@ -888,6 +980,14 @@ func appendStorageClassFilterSQL(query string, args *[]interface{}, values []str
 	return query
 }
 
+func appendStartAfterFilterSQL(query string, args *[]interface{}, startAfter string) string {
+	if startAfter == "" {
+		return query
+	}
+	*args = append(*args, startAfter)
+	return query + ` AND rel_key > ?`
+}
+
 func appendSinceRunFilterSQL(query string, args *[]interface{}, filter *SinceRunFilter) string {
 	if filter == nil {
 		return query
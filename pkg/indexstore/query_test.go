@@ -3,6 +3,8 @@ package indexstore
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -302,6 +304,32 @@ func TestQueryObjectsSinceRunForwardDelta(t *testing.T) {
 	require.Len(t, canonical, 2)
 }
 
+func TestLookupObjectsProbesKeysAcrossBatches(t *testing.T) {
+	ctx, db, indexSetID, runID := setupQueryTestDB(t, "lookup")
+	defer func() { _ = db.Close() }()
+	insertQueryTestObject(t, ctx, db, indexSetID, runID, "a/one.json", 10, "2025-01-01T00:00:00Z", "etag-one", "")
+	insertQueryTestObject(t, ctx, db, indexSetID, runID, "b/two.json", 20, "2025-01-02T00:00:00Z", "etag-two", "")
+	insertQueryTestObject(t, ctx, db, indexSetID, runID, "b/gone.json", 30, "2025-01-03T00:00:00Z", "etag-gone", "2025-02-01T00:00:00Z")
+	insertQueryTestObject(t, ctx, db, indexSetID, runID, "z/late.json", 40, "2025-01-04T00:00:00Z", "etag-late", "")
+
+	keys := []string{"z/late.json", "b/two.json", "b/gone.json", "missing.json", "a/one.json", "b/two.json"}
+	for i := 0; i < lookupBatchSize; i++ {
+		keys = append(keys, fmt.Sprintf("pad/%04d", i))
+	}
+	results, stats, err := LookupObjects(ctx, db, indexSetID, keys)
+	require.NoError(t, err)
+	require.Len(t, results, 3, "deleted and missing keys are absent; duplicates collapse")
+	require.EqualValues(t, len(keys)-1, stats.KeysLookedUp, "the repeated b/two.json counts once")
+	require.Equal(t, "a/one.json", results[0].RelKey)
+	require.Equal(t, "etag-one", results[0].ETag)
+	require.Equal(t, "b/two.json", results[1].RelKey)
+	require.EqualValues(t, 20, results[1].SizeBytes)
+	require.Equal(t, "z/late.json", results[2].RelKey, "the second batch follows the first in key order")
+
+	_, _, err = LookupObjects(ctx, db, "", keys)
+	require.ErrorContains(t, err, "index_set_id is required")
+}
+
 func TestResolveSinceRunFilterValidation(t *testing.T) {
 	ctx, db, indexSetID, _ := setupQueryTestDB(t, "since-run-validation")
 	otherIndexSetID := "idx_other_since_run_validation"
@@ -1011,6 +1039,50 @@ func TestQueryObjects_StorageClassFilter(t *testing.T) {
 	}
 }
 
+func TestQueryObjects_StartAfterResumesPagedWalk(t *testing.T) {
+	ctx, db, indexSetID, runID := setupQueryTestDB(t, "start-after")
+	defer func() { _ = db.Close() }()
+
+	for _, key := range []string{"a/1.txt", "a/2.txt", "b/1.txt", "b/2.txt", "c/1.txt"} {
+		insertQueryTestObject(t, ctx, db, indexSetID, runID, key, 10, "2025-01-01T00:00:00Z", "etag-"+key, "")
+	}
+
+	var pages [][]string
+	cursor := ""
+	for {
+		results, _, err := QueryObjects(ctx, db, QueryParams{IndexSetID: indexSetID, StartAfter: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("QueryObjects: %v", err)
+		}
+		if len(results) == 0 {
+			break
+		}
+		var page []string
+		for _, r := range results {
+			page = append(page, r.RelKey)
+		}
+		pages = append(pages, page)
+		cursor = results[len(results)-1].RelKey
+	}
+	want := [][]string{{"a/1.txt", "a/2.txt"}, {"b/1.txt", "b/2.txt"}, {"c/1.txt"}}
+	if len(pages) != len(want) {
+		t.Fatalf("expected %d pages, got %v", len(want), pages)
+	}
+	for i := range want {
+		if strings.Join(pages[i], ",") != strings.Join(want[i], ",") {
+			t.Fatalf("page %d: expected %v, got %v", i, want[i], pages[i])
+		}
+	}
+
+	count, err := QueryObjectCount(ctx, db, QueryParams{IndexSetID: indexSetID, Pattern: "b/**", StartAfter: "b/1.txt"})
+	if err != nil {
+		t.Fatalf("QueryObjectCount: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected count 1 after cursor, got %d", count)
+	}
+}
+
 func TestQueryObjects_HeadEnrichmentFieldsAndFilter(t *testing.T) {
 	ctx, db, indexSetID, runID := setupQueryTestDB(t, "head-enrichment-query")
 	defer func() { _ = db.Close() }()
@@ -0,0 +1,53 @@
+package jobregistry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	base := time.Date(2026, time.March, 14, 10, 17, 30, 0, time.UTC) // a Saturday
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"@hourly", time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.March, 14, 10, 30, 0, 0, time.UTC)},
+		{"5,45 9-17 * * *", time.Date(2026, time.March, 14, 10, 45, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, time.March, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * MON-FRI", time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches (the 20th or a Monday).
+		{"0 0 20 * 1", time.Date(2026, time.March, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		cron, err := ParseCron(tc.expr)
+		require.NoError(t, err, tc.expr)
+		require.Equal(t, tc.want, cron.Next(base), tc.expr)
+	}
+}
+
+func TestCronScheduleNextHonorsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*3600)
+	cron, err := ParseCron("@daily")
+	require.NoError(t, err)
+	next := cron.Next(time.Date(2026, time.March, 14, 3, 0, 0, 0, time.UTC).In(loc))
+	require.Equal(t, time.Date(2026, time.March, 14, 5, 0, 0, 0, time.UTC), next.UTC())
+}
+
+func TestCronScheduleNeverMatches(t *testing.T) {
+	cron, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	require.True(t, cron.Next(time.Now()).IsZero())
+}
+
+func TestParseCronRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "@often"} {
+		_, err := ParseCron(expr)
+		require.Error(t, err, expr)
+	}
+}
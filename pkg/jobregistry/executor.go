@@ -1,6 +1,7 @@
 package jobregistry
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -19,10 +20,22 @@ type Executor struct {
 	store       *Store
 	newCommand  func(string, ...string) *exec.Cmd
 	afterQueued func(*JobRecord) error
+
+	// Limits caps the jobs this executor admits from the queue. The zero value
+	// starts every job at once.
+	Limits QueueLimits
+
+	// OnEnqueued, if set, is called with a copy of each job this executor
+	// enqueues, before admission. It is where the queued lifecycle hook fires.
+	OnEnqueued func(*JobRecord)
 }
 
 const enqueueOwnershipTTL = 30 * time.Second
 
+// ErrDuplicateRunningJob is returned by a deduped start when an active job has
+// the same invocation fingerprint.
+var ErrDuplicateRunningJob = errors.New("duplicate running job exists")
+
 func NewExecutor(root string) *Executor {
 	return &Executor{store: NewStore(root), newCommand: exec.Command}
 }
@@ -45,13 +58,28 @@ type BackgroundOptions struct {
 	JobType    string
 	Metadata   map[string]string
 	Invocation *IndexBuildInvocation
+	// TraceParent is the W3C traceparent of the submitting request. The child
+	// receives it as TRACEPARENT so its spans join the submitter's trace. It is
+	// not part of the invocation fingerprint.
+	TraceParent string
+	// ScheduleID links the job to the schedule that started it. It is not part
+	// of the invocation fingerprint.
+	ScheduleID string
+	// Priority is the queue priority class (high, normal, low); empty means
+	// normal.
+	Priority string
+	// MemoryBytes is the memory the job reserves from the queue's memory
+	// budget while it runs.
+	MemoryBytes int64
 }
 
-// StartIndexBuildBackground spawns a managed child process running:
+// StartIndexBuildBackground enqueues a managed index build and admits
+// waiting jobs under the executor's limits. An admitted job's child runs:
 //
 //	gonimbus index build --job <manifest> --_managed-job-id <job_id>
 //
-// It returns after the child successfully starts.
+// It returns after the child successfully starts, or with the job still
+// queued (PID 0) when the limits hold it back.
 func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, opts BackgroundOptions) (*JobRecord, error) {
 	if e == nil || e.store == nil {
 		return nil, fmt.Errorf("executor is not initialized")
@@ -59,6 +87,13 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 	if err := validateBackgroundMetadata(opts.Metadata); err != nil {
 		return nil, err
 	}
+	priority, err := NormalizeQueuePriority(opts.Priority)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MemoryBytes < 0 {
+		return nil, fmt.Errorf("queue memory reservation must not be negative")
+	}
 
 	requestedInvocation := opts.Invocation
 	if requestedInvocation == nil {
@@ -77,7 +112,7 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 	}
 	jobID := uuid.New().String()
 	now := time.Now().UTC()
-	enqueueExpiresAt := now.Add(enqueueOwnershipTTL)
+	bucket, endpoint := indexBuildQueueKeys(inv)
 	rec := &JobRecord{
 		JobID:                 jobID,
 		Type:                  normalizeJobType(opts.JobType),
@@ -85,13 +120,20 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 		State:                 JobStateQueued,
 		ManifestPath:          inv.ManifestPath,
 		CreatedAt:             now,
-		EnqueueOwnerPID:       os.Getpid(),
-		EnqueueExpiresAt:      &enqueueExpiresAt,
 		StdoutPath:            e.StdoutPath(jobID),
 		StderrPath:            e.StderrPath(jobID),
 		Metadata:              indexBuildBackgroundMetadata(opts),
 		Invocation:            inv,
 		InvocationFingerprint: fingerprint,
+		ScheduleID:            strings.TrimSpace(opts.ScheduleID),
+		Queue: &JobQueueEntry{
+			Priority:    priority,
+			Bucket:      bucket,
+			Endpoint:    endpoint,
+			MemoryBytes: opts.MemoryBytes,
+			EnqueuedAt:  now,
+			TraceParent: strings.TrimSpace(opts.TraceParent),
+		},
 	}
 	if err := e.store.withStartLock(func() error {
 		// Under the start lock: never call List/Get/Write — they may take the
@@ -106,7 +148,7 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 			}
 			for _, j := range existing {
 				if j.InvocationFingerprint == fingerprint && activeJobState(j.State) {
-					return fmt.Errorf("duplicate running job exists: %s", j.JobID)
+					return fmt.Errorf("%w: %s", ErrDuplicateRunningJob, j.JobID)
 				}
 			}
 		}
@@ -119,17 +161,50 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 			return nil, err
 		}
 	}
+	if e.OnEnqueued != nil {
+		queued := *rec
+		e.OnEnqueued(&queued)
+	}
 
+	admitted, err := e.admitLocked()
+	if err != nil {
+		markJobStartFailed(e.store, rec)
+		return nil, err
+	}
+	// Start every admitted job, not just this one: a slot freed since the last
+	// admission pass may admit jobs queued ahead of it.
+	var startErr error
+	for _, next := range admitted {
+		err := e.spawn(next)
+		if next.JobID == jobID {
+			rec, startErr = next, err
+		}
+	}
+	if startErr != nil {
+		return nil, startErr
+	}
+	return rec, nil
+}
+
+// spawn starts the managed child of an admitted job and sets rec.PID. A job
+// that fails to start is marked failed.
+func (e *Executor) spawn(rec *JobRecord) error {
+	jobID := rec.JobID
+	inv := rec.Invocation
+	if inv == nil {
+		markJobStartFailed(e.store, rec)
+		return fmt.Errorf("managed job %s has no effective invocation", jobID)
+	}
 	stdoutFile, err := e.store.OpenLog(jobID, "stdout.log", true)
 	if err != nil {
 		markJobStartFailed(e.store, rec)
-		return nil, fmt.Errorf("create stdout log: %w", err)
+		return fmt.Errorf("create stdout log: %w", err)
 	}
 	stderrFile, err := e.store.OpenLog(jobID, "stderr.log", true)
 	if err != nil {
 		_ = stdoutFile.Close()
 		markJobStartFailed(e.store, rec)
-		return nil, fmt.Errorf("create stderr log: %w", err)
+		return fmt.Errorf("create stderr log: %w", err)
 	}
 
 	exe, err := os.Executable()
@@ -137,7 +212,7 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 		_ = stdoutFile.Close()
 		_ = stderrFile.Close()
 		markJobStartFailed(e.store, rec)
-		return nil, fmt.Errorf("resolve executable: %w", err)
+		return fmt.Errorf("resolve executable: %w", err)
 	}
 	args := indexBuildInvocationArgs(*inv, jobID)
 	newCommand := e.newCommand
@@ -153,12 +228,23 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 	if inv.DataRoot != "" {
 		cmd.Env = replaceEnv(cmd.Env, "GONIMBUS_DATA_DIR", inv.DataRoot)
 	}
+	if metricsPath := e.store.MetricsPath(jobID); metricsPath != "" {
+		cmd.Env = replaceEnv(cmd.Env, "GONIMBUS_METRICS_TEXTFILE", metricsPath)
+	}
+	if tracePath := e.store.TracePath(jobID); tracePath != "" {
+		cmd.Env = replaceEnv(cmd.Env, "GONIMBUS_TRACE_FILE", tracePath)
+	}
+	// Never let the server's own ambient trace context parent an unrelated job.
+	cmd.Env = removeEnv(cmd.Env, "TRACEPARENT", "TRACESTATE")
+	if rec.Queue != nil && rec.Queue.TraceParent != "" {
+		cmd.Env = append(cmd.Env, "TRACEPARENT="+rec.Queue.TraceParent)
+	}
 
 	if err := cmd.Start(); err != nil {
 		_ = stdoutFile.Close()
 		_ = stderrFile.Close()
 		markJobStartFailed(e.store, rec)
-		return nil, fmt.Errorf("start managed index build: %w", err)
+		return fmt.Errorf("start managed index build: %w", err)
 	}
 	// The child owns queued -> running -> terminal transitions. The parent must
 	// not overwrite a fast child after cmd.Start. PID is returned for immediate
@@ -170,8 +256,7 @@ func (e *Executor) StartIndexBuildBackground(manifestPath string, name string, o
 
 	_ = stdoutFile.Close()
 	_ = stderrFile.Close()
-
-	return rec, nil
+	return nil
 }
 
 func activeJobState(state JobState) bool {
@@ -202,14 +287,24 @@ func recoverExpiredQueuedJobsLocked(store *Store, now time.Time) error {
 }
 
 func replaceEnv(env []string, key, value string) []string {
-	prefix := key + "="
+	return append(removeEnv(env, key), key+"="+value)
+}
+
+func removeEnv(env []string, keys ...string) []string {
 	out := make([]string, 0, len(env)+1)
 	for _, item := range env {
-		if !strings.HasPrefix(item, prefix) {
+		drop := false
+		for _, key := range keys {
+			if strings.HasPrefix(item, key+"=") {
+				drop = true
+				break
+			}
+		}
+		if !drop {
 			out = append(out, item)
 		}
 	}
-	return append(out, prefix+value)
+	return out
 }
 
 func markJobStartFailed(store *Store, rec *JobRecord) {
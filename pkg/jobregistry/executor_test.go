@@ -156,6 +156,35 @@ func TestStartIndexBuildBackgroundFastChildCannotBeClobbered(t *testing.T) {
 	require.NotZero(t, stored.PID)
 }
 
+func TestStartIndexBuildBackgroundPassesTraceContextAndTelemetryPaths(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-11111111111111111111111111111111-2222222222222222-01")
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte("version: 1\n"), 0o600))
+	e := NewExecutor(root)
+	helper := helperCommand(t, root, filepath.Join(t.TempDir(), "args.json"), 0)
+	var spawned *exec.Cmd
+	e.newCommand = func(name string, args ...string) *exec.Cmd {
+		spawned = helper(name, args...)
+		return spawned
+	}
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	rec, err := e.StartIndexBuildBackground(manifestPath, "traced", BackgroundOptions{TraceParent: traceParent})
+	require.NoError(t, err)
+	waitHelperCompletion(t, e.Store(), rec)
+
+	require.NotNil(t, spawned)
+	var traceParents []string
+	for _, item := range spawned.Env {
+		if strings.HasPrefix(item, "TRACEPARENT=") {
+			traceParents = append(traceParents, item)
+		}
+	}
+	require.Equal(t, []string{"TRACEPARENT=" + traceParent}, traceParents, "the server's ambient trace context must not leak into the job")
+	require.Contains(t, spawned.Env, "GONIMBUS_TRACE_FILE="+e.Store().TracePath(rec.JobID))
+	require.Contains(t, spawned.Env, "GONIMBUS_METRICS_TEXTFILE="+e.Store().MetricsPath(rec.JobID))
+}
+
 func TestStartIndexBuildBackgroundDedupeIsAtomic(t *testing.T) {
 	root := t.TempDir()
 	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
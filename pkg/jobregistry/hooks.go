@@ -0,0 +1,469 @@
+package jobregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job lifecycle events delivered to hooks.
+const (
+	HookEventQueued          = "queued"
+	HookEventRunning         = "running"
+	HookEventSucceeded       = "succeeded"
+	HookEventPartial         = "partial"
+	HookEventFailedResumable = "failed_resumable"
+	HookEventFailed          = "failed"
+)
+
+// HookEvents lists every job lifecycle event in lifecycle order.
+var HookEvents = []string{
+	HookEventQueued, HookEventRunning, HookEventSucceeded,
+	HookEventPartial, HookEventFailedResumable, HookEventFailed,
+}
+
+// Hook delivery status values recorded on the job.
+const (
+	HookDeliveryPending   = "pending"
+	HookDeliveryDelivered = "delivered"
+	HookDeliveryFailed    = "failed"
+)
+
+// HookPayloadType is the type of the JSON document sent to hooks.
+const HookPayloadType = "gonimbus.job.event.v1"
+
+const (
+	DefaultHookMaxAttempts = 5
+	DefaultHookTimeout     = 30 * time.Second
+	hookBackoffBase        = time.Second
+	hookBackoffMax         = time.Minute
+	hookErrorMaxLen        = 512
+)
+
+// HookConfig is one configured job lifecycle hook: a webhook (URL) or a local
+// command (Command), never both.
+type HookConfig struct {
+	// Name identifies the hook in delivery records and replays.
+	Name string
+	// Events filters the events delivered; empty means every event.
+	Events []string
+	// URL receives the payload as an HTTP POST.
+	URL string
+	// Secret is the HMAC-SHA256 key used to sign webhook payloads. It is
+	// resolved by the caller (typically from the environment) and never
+	// persisted.
+	Secret string
+	// Command is run with the payload on stdin.
+	Command []string
+	// MaxAttempts caps delivery attempts; zero means DefaultHookMaxAttempts.
+	MaxAttempts int
+	// Timeout bounds each attempt; zero means DefaultHookTimeout.
+	Timeout time.Duration
+}
+
+// Validate checks the hook's shape.
+func (c HookConfig) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("hook name is required")
+	}
+	hasURL, hasCommand := strings.TrimSpace(c.URL) != "", len(c.Command) > 0
+	if hasURL == hasCommand {
+		return fmt.Errorf("hook %s: exactly one of url or command is required", c.Name)
+	}
+	if hasURL {
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hook %s: invalid url (want http or https)", c.Name)
+		}
+	}
+	if hasCommand && strings.TrimSpace(c.Command[0]) == "" {
+		return fmt.Errorf("hook %s: command program is required", c.Name)
+	}
+	for _, event := range c.Events {
+		if !validHookEvent(event) {
+			return fmt.Errorf("hook %s: unknown event %q (want one of %s)", c.Name, event, strings.Join(HookEvents, ", "))
+		}
+	}
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("hook %s: max_attempts must be >= 0", c.Name)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("hook %s: timeout must be >= 0", c.Name)
+	}
+	return nil
+}
+
+func validHookEvent(event string) bool {
+	for _, known := range HookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+func (c HookConfig) wants(event string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// HookDelivery is the audit record of one event delivered to one hook. The
+// payload is kept verbatim so a delivery can be replayed byte for byte.
+type HookDelivery struct {
+	DeliveryID string          `json:"delivery_id"`
+	Hook       string          `json:"hook"`
+	Event      string          `json:"event"`
+	Status     string          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	StatusCode int             `json:"status_code,omitempty"`
+	LastError  string          `json:"last_error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// HookPayload is the document delivered to hooks. Receipt is the job's
+// recorded receipt identity, not the full build receipt.
+type HookPayload struct {
+	Type       string                `json:"type"`
+	Event      string                `json:"event"`
+	DeliveryID string                `json:"delivery_id"`
+	OccurredAt time.Time             `json:"occurred_at"`
+	Job        HookPayloadJob        `json:"job"`
+	Receipt    *BuildReceiptIdentity `json:"receipt,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// HookPayloadJob is the job summary carried by a hook payload.
+type HookPayloadJob struct {
+	JobID        string     `json:"job_id"`
+	Type         string     `json:"type,omitempty"`
+	Name         string     `json:"name,omitempty"`
+	State        JobState   `json:"state"`
+	ManifestPath string     `json:"manifest_path"`
+	IndexSetID   string     `json:"index_set_id,omitempty"`
+	RunID        string     `json:"run_id,omitempty"`
+	ScheduleID   string     `json:"schedule_id,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+}
+
+// Hooks delivers job lifecycle events to the configured hooks and records
+// each delivery on the job.
+type Hooks struct {
+	configs []HookConfig
+
+	client *http.Client
+	now    func() time.Time
+	sleep  func(time.Duration)
+}
+
+// NewHooks validates configs and returns a dispatcher. It returns nil when
+// no hooks are configured; a nil *Hooks delivers nothing.
+func NewHooks(configs []HookConfig) (*Hooks, error) {
+	seen := map[string]bool{}
+	for _, c := range configs {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("duplicate hook name %q", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	return &Hooks{
+		configs: append([]HookConfig(nil), configs...),
+		client:  &http.Client{},
+		now:     func() time.Time { return time.Now().UTC() },
+		sleep:   time.Sleep,
+	}, nil
+}
+
+// Notify delivers event for rec to every hook that wants it, retrying with
+// exponential backoff, and records each delivery on the job. detail is the
+// failure message for failed events. It returns the deliveries that failed.
+func (h *Hooks) Notify(store *Store, rec *JobRecord, event, detail string) error {
+	if h == nil || rec == nil {
+		return nil
+	}
+	var errs []error
+	for _, c := range h.configs {
+		if !c.wants(event) {
+			continue
+		}
+		d, err := h.newDelivery(c, rec, event, detail)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := h.deliver(store, rec.JobID, c, d); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Replay re-sends a recorded delivery's payload to its hook under the same
+// delivery ID, so receivers can deduplicate, and updates the record.
+func (h *Hooks) Replay(store *Store, jobID, deliveryID string) (*HookDelivery, error) {
+	rec, err := store.Get(jobID)
+	if err != nil {
+		return nil, err
+	}
+	var d *HookDelivery
+	for i := range rec.HookDeliveries {
+		if rec.HookDeliveries[i].DeliveryID == deliveryID {
+			d = &rec.HookDeliveries[i]
+			break
+		}
+	}
+	if d == nil {
+		return nil, fmt.Errorf("job %s has no hook delivery %s", jobID, deliveryID)
+	}
+	var cfg *HookConfig
+	if h != nil {
+		for i := range h.configs {
+			if h.configs[i].Name == d.Hook {
+				cfg = &h.configs[i]
+				break
+			}
+		}
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("hook %q is no longer configured", d.Hook)
+	}
+	err = h.deliver(store, jobID, *cfg, d)
+	return d, err
+}
+
+func (h *Hooks) newDelivery(c HookConfig, rec *JobRecord, event, detail string) (*HookDelivery, error) {
+	now := h.now()
+	payload := HookPayload{
+		Type:       HookPayloadType,
+		Event:      event,
+		DeliveryID: uuid.New().String(),
+		OccurredAt: now,
+		Job: HookPayloadJob{
+			JobID: rec.JobID, Type: rec.Type, Name: rec.Name, State: rec.State,
+			ManifestPath: rec.ManifestPath, IndexSetID: rec.IndexSetID, RunID: rec.RunID,
+			ScheduleID: rec.ScheduleID, CreatedAt: rec.CreatedAt,
+			StartedAt: rec.StartedAt, EndedAt: rec.EndedAt,
+		},
+		Receipt: rec.Receipt,
+		Error:   detail,
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("hook %s: encode payload: %w", c.Name, err)
+	}
+	return &HookDelivery{
+		DeliveryID: payload.DeliveryID, Hook: c.Name, Event: event,
+		Status: HookDeliveryPending, CreatedAt: now, UpdatedAt: now, Payload: b,
+	}, nil
+}
+
+// deliver attempts d until it succeeds, fails permanently, or exhausts its
+// attempts. The delivery is recorded before the first attempt and after the
+// last, so a process that dies mid-delivery leaves it pending for replay.
+func (h *Hooks) deliver(store *Store, jobID string, c HookConfig, d *HookDelivery) error {
+	maxAttempts := c.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultHookMaxAttempts
+	}
+	d.Status = HookDeliveryPending
+	d.UpdatedAt = h.now()
+	_ = store.RecordHookDelivery(jobID, *d)
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			h.sleep(hookBackoff(attempt - 1))
+		}
+		d.Attempts++
+		code, retry, err := h.attempt(c, d)
+		d.StatusCode = code
+		lastErr = err
+		if err == nil {
+			d.Status = HookDeliveryDelivered
+			d.LastError = ""
+			break
+		}
+		d.Status = HookDeliveryFailed
+		d.LastError = truncateHookError(err.Error())
+		if !retry {
+			break
+		}
+	}
+	d.UpdatedAt = h.now()
+	if err := store.RecordHookDelivery(jobID, *d); err != nil && lastErr == nil {
+		lastErr = fmt.Errorf("record delivery: %w", err)
+	}
+	if lastErr != nil {
+		return fmt.Errorf("hook %s: %s delivery %s: %w", c.Name, d.Event, d.DeliveryID, lastErr)
+	}
+	return nil
+}
+
+// hookBackoff is the wait before retry n (1-based): 1s, 2s, 4s, ... capped.
+func hookBackoff(n int) time.Duration {
+	wait := hookBackoffBase
+	for i := 1; i < n && wait < hookBackoffMax; i++ {
+		wait *= 2
+	}
+	return min(wait, hookBackoffMax)
+}
+
+func truncateHookError(msg string) string {
+	if len(msg) > hookErrorMaxLen {
+		return msg[:hookErrorMaxLen]
+	}
+	return msg
+}
+
+// attempt makes one delivery attempt. It reports the HTTP status code, if
+// any, and whether a failure is worth retrying.
+func (h *Hooks) attempt(c HookConfig, d *HookDelivery) (int, bool, error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if len(c.Command) > 0 {
+		return 0, true, runHookCommand(ctx, c, d)
+	}
+	return h.post(ctx, c, d)
+}
+
+func (h *Hooks) post(ctx context.Context, c HookConfig, d *HookDelivery) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, false, err
+	}
+	ts := strconv.FormatInt(h.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gonimbus-hooks/1")
+	req.Header.Set("X-Gonimbus-Event", d.Event)
+	req.Header.Set("X-Gonimbus-Delivery", d.DeliveryID)
+	req.Header.Set("X-Gonimbus-Timestamp", ts)
+	if c.Secret != "" {
+		req.Header.Set("X-Gonimbus-Signature", SignHookPayload(c.Secret, ts, d.Payload))
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry := resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+}
+
+// SignHookPayload returns the X-Gonimbus-Signature value for a webhook body:
+// "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+// Receivers recompute it from the X-Gonimbus-Timestamp header and the raw
+// body, and should reject stale timestamps.
+func SignHookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func runHookCommand(ctx context.Context, c HookConfig, d *HookDelivery) error {
+	var jobID string
+	var payload HookPayload
+	if err := json.Unmarshal(d.Payload, &payload); err == nil {
+		jobID = payload.Job.JobID
+	}
+	cmd := exec.CommandContext(ctx, c.Command[0], c.Command[1:]...) // #nosec G204 -- the command is operator configuration.
+	cmd.Stdin = bytes.NewReader(d.Payload)
+	cmd.Env = append(os.Environ(),
+		"GONIMBUS_HOOK_EVENT="+d.Event,
+		"GONIMBUS_HOOK_DELIVERY="+d.DeliveryID,
+		"GONIMBUS_JOB_ID="+jobID,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// RecordHookDelivery inserts or updates a delivery on the job record under
+// the start lock.
+func (s *Store) RecordHookDelivery(jobID string, d HookDelivery) error {
+	return s.withStartLock(func() error {
+		rec, err := s.getReadOnlyStrict(jobID)
+		if err != nil {
+			return err
+		}
+		rec.HookDeliveries = mergeHookDeliveries(rec.HookDeliveries, []HookDelivery{d})
+		return s.writeRecord(rec)
+	})
+}
+
+// mergeHookDeliveries unions two delivery lists by delivery ID, keeping the
+// most recently updated copy. Write merges with the persisted record so a
+// writer holding an older copy of the job does not drop deliveries recorded
+// since it read it.
+func mergeHookDeliveries(persisted, incoming []HookDelivery) []HookDelivery {
+	if len(persisted) == 0 {
+		return incoming
+	}
+	byID := make(map[string]HookDelivery, len(persisted)+len(incoming))
+	for _, d := range persisted {
+		byID[d.DeliveryID] = d
+	}
+	for _, d := range incoming {
+		if prev, ok := byID[d.DeliveryID]; !ok || !d.UpdatedAt.Before(prev.UpdatedAt) {
+			byID[d.DeliveryID] = d
+		}
+	}
+	out := make([]HookDelivery, 0, len(byID))
+	for _, d := range byID {
+		out = append(out, d)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].DeliveryID < out[j].DeliveryID
+	})
+	return out
+}
@@ -0,0 +1,199 @@
+package jobregistry
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHooks(t *testing.T, configs ...HookConfig) *Hooks {
+	t.Helper()
+	hooks, err := NewHooks(configs)
+	require.NoError(t, err)
+	hooks.sleep = func(time.Duration) {}
+	return hooks
+}
+
+func hookTestJob(t *testing.T, store *Store) *JobRecord {
+	t.Helper()
+	now := time.Now().UTC()
+	rec := &JobRecord{
+		JobID: uuid.New().String(), Type: JobTypeIndexBuild, State: JobStateSuccess,
+		ManifestPath: "/m.yaml", CreatedAt: now, EndedAt: &now, IndexSetID: "set-1", RunID: "run-1",
+		Receipt: &BuildReceiptIdentity{Type: "gonimbus.index.build_result.v1", Status: "success", IndexSetID: "set-1", RunID: "run-1"},
+	}
+	require.NoError(t, store.Write(rec))
+	return rec
+}
+
+func TestHooksWebhookSignsAndRetries(t *testing.T) {
+	var calls atomic.Int32
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	store := NewStore(t.TempDir())
+	rec := hookTestJob(t, store)
+	hooks := newTestHooks(t, HookConfig{Name: "downstream", URL: srv.URL, Secret: "s3cret", Events: []string{HookEventSucceeded}})
+
+	require.NoError(t, hooks.Notify(store, rec, HookEventQueued, ""), "an unwanted event is not delivered")
+	require.NoError(t, hooks.Notify(store, rec, HookEventSucceeded, ""))
+	require.EqualValues(t, 2, calls.Load())
+
+	require.Equal(t, HookEventSucceeded, header.Get("X-Gonimbus-Event"))
+	require.Equal(t, SignHookPayload("s3cret", header.Get("X-Gonimbus-Timestamp"), body), header.Get("X-Gonimbus-Signature"))
+	var payload HookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	require.Equal(t, HookPayloadType, payload.Type)
+	require.Equal(t, header.Get("X-Gonimbus-Delivery"), payload.DeliveryID)
+	require.Equal(t, rec.JobID, payload.Job.JobID)
+	require.Equal(t, "run-1", payload.Receipt.RunID)
+
+	got, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.Len(t, got.HookDeliveries, 1)
+	d := got.HookDeliveries[0]
+	require.Equal(t, HookDeliveryDelivered, d.Status)
+	require.Equal(t, 2, d.Attempts)
+	require.Equal(t, http.StatusOK, d.StatusCode)
+	require.JSONEq(t, string(body), string(d.Payload))
+}
+
+func TestHooksWebhookClientErrorIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	store := NewStore(t.TempDir())
+	rec := hookTestJob(t, store)
+	hooks := newTestHooks(t, HookConfig{Name: "downstream", URL: srv.URL})
+
+	require.ErrorContains(t, hooks.Notify(store, rec, HookEventFailed, "boom"), "HTTP 400")
+	require.EqualValues(t, 1, calls.Load())
+	got, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.Equal(t, HookDeliveryFailed, got.HookDeliveries[0].Status)
+	require.Equal(t, http.StatusBadRequest, got.HookDeliveries[0].StatusCode)
+}
+
+func TestHooksReplayResendsSameDelivery(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	var deliveryIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveryIDs = append(deliveryIDs, r.Header.Get("X-Gonimbus-Delivery"))
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	store := NewStore(t.TempDir())
+	rec := hookTestJob(t, store)
+	hooks := newTestHooks(t, HookConfig{Name: "downstream", URL: srv.URL, MaxAttempts: 2})
+	require.Error(t, hooks.Notify(store, rec, HookEventSucceeded, ""))
+
+	got, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	deliveryID := got.HookDeliveries[0].DeliveryID
+
+	fail.Store(false)
+	d, err := hooks.Replay(store, rec.JobID, deliveryID)
+	require.NoError(t, err)
+	require.Equal(t, HookDeliveryDelivered, d.Status)
+	require.Equal(t, 3, d.Attempts)
+	require.Equal(t, []string{deliveryID, deliveryID, deliveryID}, deliveryIDs)
+
+	got, err = store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.Len(t, got.HookDeliveries, 1)
+	require.Equal(t, HookDeliveryDelivered, got.HookDeliveries[0].Status)
+
+	_, err = newTestHooks(t, HookConfig{Name: "other", URL: srv.URL}).Replay(store, rec.JobID, deliveryID)
+	require.ErrorContains(t, err, "no longer configured")
+}
+
+func TestHooksCommandReceivesPayload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("command hook test uses sh")
+	}
+	out := filepath.Join(t.TempDir(), "payload.json")
+	store := NewStore(t.TempDir())
+	rec := hookTestJob(t, store)
+	hooks := newTestHooks(t, HookConfig{
+		Name:    "local",
+		Command: []string{"sh", "-c", `cat > "$0" && test "$GONIMBUS_HOOK_EVENT" = succeeded && test -n "$GONIMBUS_JOB_ID"`, out},
+	})
+
+	require.NoError(t, hooks.Notify(store, rec, HookEventSucceeded, ""))
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var payload HookPayload
+	require.NoError(t, json.Unmarshal(b, &payload))
+	require.Equal(t, rec.JobID, payload.Job.JobID)
+}
+
+func TestStoreWriteKeepsRecordedHookDeliveries(t *testing.T) {
+	store := NewStore(t.TempDir())
+	rec := hookTestJob(t, store)
+	stale := *rec
+
+	now := time.Now().UTC()
+	require.NoError(t, store.RecordHookDelivery(rec.JobID, HookDelivery{
+		DeliveryID: "d1", Hook: "h", Event: HookEventRunning, Status: HookDeliveryDelivered, CreatedAt: now, UpdatedAt: now,
+	}))
+	stale.State = JobStateFailed
+	require.NoError(t, store.Write(&stale))
+
+	got, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.Equal(t, JobStateFailed, got.State)
+	require.Len(t, got.HookDeliveries, 1, "a writer holding an older copy must not drop deliveries")
+}
+
+func TestNewHooksRejectsBadConfig(t *testing.T) {
+	for name, c := range map[string]HookConfig{
+		"no name":      {URL: "https://example.com"},
+		"no target":    {Name: "h"},
+		"both targets": {Name: "h", URL: "https://example.com", Command: []string{"true"}},
+		"bad scheme":   {Name: "h", URL: "ftp://example.com"},
+		"bad event":    {Name: "h", URL: "https://example.com", Events: []string{"done"}},
+	} {
+		_, err := NewHooks([]HookConfig{c})
+		require.Error(t, err, name)
+	}
+	_, err := NewHooks([]HookConfig{{Name: "h", URL: "https://a.example"}, {Name: "h", URL: "https://b.example"}})
+	require.ErrorContains(t, err, "duplicate hook name")
+
+	hooks, err := NewHooks(nil)
+	require.NoError(t, err)
+	require.Nil(t, hooks)
+	require.NoError(t, hooks.Notify(NewStore(t.TempDir()), &JobRecord{JobID: "x"}, HookEventQueued, ""))
+}
+
+func TestHookBackoffDoublesToCap(t *testing.T) {
+	require.Equal(t, time.Second, hookBackoff(1))
+	require.Equal(t, 4*time.Second, hookBackoff(3))
+	require.Equal(t, hookBackoffMax, hookBackoff(20))
+}
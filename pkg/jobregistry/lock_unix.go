@@ -43,6 +43,12 @@ func ensureJobDirNoFollow(root, jobID string) error {
 }
 
 func writeJobRecordAtomic(root, jobID string, data []byte) error {
+	return writeBoundFileAtomic(root, jobID, "job.json", data)
+}
+
+// writeBoundFileAtomic replaces <root>/<jobID>/<name> through the bound
+// directory, so a swapped directory or symlink cannot redirect the write.
+func writeBoundFileAtomic(root, jobID, name string, data []byte) error {
 	rootFD, jobFD, err := openBoundJobDir(root, jobID, true)
 	if err != nil {
 		return fmt.Errorf("bind job directory: %w", err)
@@ -82,7 +88,7 @@ func writeJobRecordAtomic(root, jobID string, data []byte) error {
 	if err := verifyBoundJobDir(rootFD, jobFD, jobID); err != nil {
 		return err
 	}
-	if err := unix.Renameat(jobFD, tmpName, jobFD, "job.json"); err != nil {
+	if err := unix.Renameat(jobFD, tmpName, jobFD, name); err != nil {
 		return fmt.Errorf("replace bound job record: %w", err)
 	}
 	if err := unix.Fsync(jobFD); err != nil {
@@ -203,6 +203,12 @@ func ensureJobDirNoFollow(root, jobID string) error {
 }
 
 func writeJobRecordAtomic(root, jobID string, data []byte) error {
+	return writeBoundFileAtomic(root, jobID, "job.json", data)
+}
+
+// writeBoundFileAtomic replaces <root>/<jobID>/<name> through the bound
+// directory, so a swapped directory or symlink cannot redirect the write.
+func writeBoundFileAtomic(root, jobID, name string, data []byte) error {
 	rootHandle, jobHandle, err := openBoundJobDirectoryWindows(root, jobID, true)
 	if err != nil {
 		return fmt.Errorf("bind job directory: %w", err)
@@ -236,7 +242,7 @@ func writeJobRecordAtomic(root, jobID string, data []byte) error {
 	if err := verifyBoundJobDirectoryWindows(rootHandle, jobHandle, jobID); err != nil {
 		return err
 	}
-	if err := renameRelativeFileWindows(windows.Handle(tmp.Fd()), jobHandle, "job.json"); err != nil {
+	if err := renameRelativeFileWindows(windows.Handle(tmp.Fd()), jobHandle, name); err != nil {
 		return fmt.Errorf("replace bound job record: %w", err)
 	}
 	return verifyBoundJobDirectoryWindows(rootHandle, jobHandle, jobID)
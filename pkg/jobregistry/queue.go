@@ -0,0 +1,391 @@
+package jobregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Queue priority classes. Higher classes are admitted first; within a class
+// jobs are admitted in enqueue order.
+const (
+	QueuePriorityHigh   = "high"
+	QueuePriorityNormal = "normal"
+	QueuePriorityLow    = "low"
+)
+
+// DefaultQueueAdmitInterval is how often RunAdmission admits waiting jobs.
+const DefaultQueueAdmitInterval = 5 * time.Second
+
+// QueueLimits caps the jobs the queue admits. Zero fields are unlimited, so
+// the zero value admits every job at once.
+type QueueLimits struct {
+	// MaxRunning caps admitted jobs across the jobs root.
+	MaxRunning int
+	// MaxPerBucket and MaxPerEndpoint cap admitted jobs sharing a source
+	// bucket or storage endpoint. A job blocked only by these is passed over
+	// for later jobs that fit.
+	MaxPerBucket   int
+	MaxPerEndpoint int
+	// MemoryBytes is the budget shared by admitted jobs' memory reservations.
+	// A job that does not fit holds back the jobs behind it, so large jobs are
+	// not starved; a job larger than the whole budget runs alone.
+	MemoryBytes int64
+}
+
+// NormalizeQueuePriority validates a priority class; empty means normal.
+func NormalizeQueuePriority(priority string) (string, error) {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	switch priority {
+	case "":
+		return QueuePriorityNormal, nil
+	case QueuePriorityHigh, QueuePriorityNormal, QueuePriorityLow:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("invalid queue priority %q (want high, normal, or low)", priority)
+	}
+}
+
+func queuePriorityRank(priority string) int {
+	switch priority {
+	case QueuePriorityHigh:
+		return 0
+	case QueuePriorityLow:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// queueOrder returns the jobs awaiting admission in admission order.
+func queueOrder(jobs []JobRecord) []*JobRecord {
+	var waiting []*JobRecord
+	for i := range jobs {
+		if jobs[i].AwaitingAdmission() {
+			waiting = append(waiting, &jobs[i])
+		}
+	}
+	sort.SliceStable(waiting, func(i, j int) bool {
+		a, b := waiting[i].Queue, waiting[j].Queue
+		if ra, rb := queuePriorityRank(a.Priority), queuePriorityRank(b.Priority); ra != rb {
+			return ra < rb
+		}
+		if !a.EnqueuedAt.Equal(b.EnqueuedAt) {
+			return a.EnqueuedAt.Before(b.EnqueuedAt)
+		}
+		return waiting[i].JobID < waiting[j].JobID
+	})
+	return waiting
+}
+
+// QueuePositions maps each job awaiting admission to its 1-based position in
+// the queue.
+func QueuePositions(jobs []JobRecord) map[string]int {
+	positions := map[string]int{}
+	for i, rec := range queueOrder(jobs) {
+		positions[rec.JobID] = i + 1
+	}
+	return positions
+}
+
+// queueUsage is what the admitted, still-active jobs hold against the limits.
+type queueUsage struct {
+	running   int
+	buckets   map[string]int
+	endpoints map[string]int
+	memory    int64
+}
+
+// newQueueUsage sums the capacity held by active jobs. A job whose process
+// is gone (for example, after a host restart) holds nothing, even before a
+// reader demotes its record.
+func newQueueUsage(jobs []JobRecord) *queueUsage {
+	usage := &queueUsage{buckets: map[string]int{}, endpoints: map[string]int{}}
+	for i := range jobs {
+		rec := &jobs[i]
+		if !activeJobState(rec.State) || rec.AwaitingAdmission() {
+			continue
+		}
+		if rec.State != JobStateQueued && rec.PID > 0 && !isProcessAlive(rec.PID) {
+			continue
+		}
+		usage.add(rec.Queue)
+	}
+	return usage
+}
+
+func (u *queueUsage) add(entry *JobQueueEntry) {
+	u.running++
+	if entry == nil {
+		return
+	}
+	if entry.Bucket != "" {
+		u.buckets[entry.Bucket]++
+	}
+	if entry.Endpoint != "" {
+		u.endpoints[entry.Endpoint]++
+	}
+	u.memory += entry.MemoryBytes
+}
+
+type queueFit int
+
+const (
+	queueAdmit queueFit = iota
+	// queuePass leaves the job waiting but lets later jobs be admitted.
+	queuePass
+	// queueHold leaves the job and every job behind it waiting.
+	queueHold
+)
+
+func (l QueueLimits) fit(u *queueUsage, entry *JobQueueEntry) queueFit {
+	if l.MaxRunning > 0 && u.running >= l.MaxRunning {
+		return queueHold
+	}
+	if l.MemoryBytes > 0 && u.running > 0 && u.memory+entry.MemoryBytes > l.MemoryBytes {
+		return queueHold
+	}
+	if l.MaxPerBucket > 0 && entry.Bucket != "" && u.buckets[entry.Bucket] >= l.MaxPerBucket {
+		return queuePass
+	}
+	if l.MaxPerEndpoint > 0 && entry.Endpoint != "" && u.endpoints[entry.Endpoint] >= l.MaxPerEndpoint {
+		return queuePass
+	}
+	return queueAdmit
+}
+
+// AdmitQueued admits the waiting jobs that fit the executor's limits and
+// starts their managed children. It returns the started jobs. Admission is
+// serialized by the jobs root start lock, so every process that admits jobs
+// from the same root honours one set of limits. While an admission hold is in
+// place it admits nothing.
+//
+// An admitted job whose child cannot be spawned (its invocation is missing,
+// its logs cannot be created, or the executable will not start) is marked
+// failed rather than returned to the queue: the cause does not clear on
+// retry, and a requeued job would take the same slot on every pass.
+func (e *Executor) AdmitQueued() ([]*JobRecord, error) {
+	if e == nil || e.store == nil {
+		return nil, fmt.Errorf("executor is not initialized")
+	}
+	admitted, err := e.admitLocked()
+	if err != nil {
+		return nil, err
+	}
+	var started []*JobRecord
+	var errs []error
+	for _, rec := range admitted {
+		if err := e.spawn(rec); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", rec.JobID, err))
+			continue
+		}
+		started = append(started, rec)
+	}
+	return started, errors.Join(errs...)
+}
+
+// admitLocked marks the waiting jobs that fit as admitted. Each admitted job
+// takes enqueue ownership, so a job whose child never claims it fails once the
+// ownership expires instead of holding its slot forever.
+func (e *Executor) admitLocked() ([]*JobRecord, error) {
+	var admitted []*JobRecord
+	err := e.store.withStartLock(func() error {
+		held, err := e.store.admissionHeldLocked()
+		if err != nil || held {
+			return err
+		}
+		now := time.Now().UTC()
+		if err := recoverExpiredQueuedJobsLocked(e.store, now); err != nil {
+			return err
+		}
+		jobs, err := e.store.ListReadOnlyStrict()
+		if err != nil {
+			return err
+		}
+		usage := newQueueUsage(jobs)
+		for _, rec := range queueOrder(jobs) {
+			switch e.Limits.fit(usage, rec.Queue) {
+			case queueHold:
+				return nil
+			case queuePass:
+				continue
+			}
+			admittedAt := now
+			enqueueExpiresAt := now.Add(enqueueOwnershipTTL)
+			rec.Queue.AdmittedAt = &admittedAt
+			rec.EnqueueOwnerPID = os.Getpid()
+			rec.EnqueueExpiresAt = &enqueueExpiresAt
+			if err := e.store.writeRecord(rec); err != nil {
+				return fmt.Errorf("admit queued job %s: %w", rec.JobID, err)
+			}
+			usage.add(rec.Queue)
+			admitted = append(admitted, rec)
+		}
+		return nil
+	})
+	return admitted, err
+}
+
+// admissionHoldName is the jobs root file that holds admission. It records
+// the holder's PID, so a hold left by a process that exited is ignored.
+const admissionHoldName = ".admission-hold"
+
+// HoldAdmission stops every process that admits from the jobs root,
+// including managed jobs admitting their successor as they exit, until
+// ReleaseAdmission or until this process exits. A draining server holds
+// admission so no queued job starts behind it; waiting jobs stay queued for
+// the next runner.
+func (e *Executor) HoldAdmission() error {
+	if e == nil || e.store == nil {
+		return fmt.Errorf("executor is not initialized")
+	}
+	return e.store.withStartLock(func() error {
+		f, err := openFileNoFollow(e.store.admissionHoldPath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("hold job admission: %w", err)
+		}
+		_, err = f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+		return errors.Join(err, f.Close())
+	})
+}
+
+// ReleaseAdmission removes a hold this process placed. A hold placed by
+// another live process is left in place.
+func (e *Executor) ReleaseAdmission() error {
+	if e == nil || e.store == nil {
+		return fmt.Errorf("executor is not initialized")
+	}
+	return e.store.withStartLock(func() error {
+		pid, err := e.store.admissionHolderLocked()
+		if err != nil || pid != os.Getpid() {
+			return err
+		}
+		return removeAdmissionHold(e.store.admissionHoldPath())
+	})
+}
+
+func (s *Store) admissionHoldPath() string {
+	return filepath.Join(s.root, admissionHoldName)
+}
+
+// admissionHolderLocked returns the PID recorded by the admission hold, or 0
+// when there is none. The caller holds the start lock.
+func (s *Store) admissionHolderLocked() (int, error) {
+	f, err := openFileNoFollow(s.admissionHoldPath(), os.O_RDONLY, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read job admission hold: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	raw, err := io.ReadAll(io.LimitReader(f, 32))
+	if err != nil {
+		return 0, fmt.Errorf("read job admission hold: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid job admission hold %q", strings.TrimSpace(string(raw)))
+	}
+	return pid, nil
+}
+
+// admissionHeldLocked reports whether a live process holds admission. A hold
+// whose holder has exited is removed. The caller holds the start lock.
+func (s *Store) admissionHeldLocked() (bool, error) {
+	pid, err := s.admissionHolderLocked()
+	if err != nil || pid == 0 {
+		return false, err
+	}
+	if isProcessAlive(pid) {
+		return true, nil
+	}
+	return false, removeAdmissionHold(s.admissionHoldPath())
+}
+
+func removeAdmissionHold(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("release job admission hold: %w", err)
+	}
+	return nil
+}
+
+// RunAdmission admits waiting jobs every interval until ctx is done. The first
+// pass runs at once so jobs left waiting by a stopped runner start on restart.
+func (e *Executor) RunAdmission(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultQueueAdmitInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_, _ = e.AdmitQueued()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueManifestConnection is the part of an index manifest the queue keys
+// on. It is decoded directly rather than through pkg/manifest, whose schema
+// validation would pull its dependencies into every jobregistry importer.
+type queueManifestConnection struct {
+	Connection struct {
+		Provider string `yaml:"provider"`
+		Bucket   string `yaml:"bucket"`
+		BaseURI  string `yaml:"base_uri"`
+		Region   string `yaml:"region"`
+		Endpoint string `yaml:"endpoint"`
+	} `yaml:"connection"`
+}
+
+// indexBuildQueueKeys derives the bucket and endpoint concurrency keys from
+// the manifest connection. A manifest that cannot be read yields no keys; the
+// child reports the manifest error when it runs.
+func indexBuildQueueKeys(inv *IndexBuildInvocation) (bucket, endpoint string) {
+	data, _, err := ReadManifestBytesAndSHA256(inv.ManifestPath)
+	if err != nil {
+		return "", ""
+	}
+	var m queueManifestConnection
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return "", ""
+	}
+	conn := m.Connection
+	provider := strings.ToLower(strings.TrimSpace(conn.Provider))
+	if b := strings.TrimSpace(conn.Bucket); b != "" {
+		bucket = provider + ":" + b
+	} else if u, err := url.Parse(strings.TrimSpace(conn.BaseURI)); err == nil && u.Host != "" {
+		bucket = provider + ":" + u.Host
+	}
+
+	host := strings.TrimSpace(inv.EndpointHost)
+	if host == "" {
+		if u, err := url.Parse(strings.TrimSpace(conn.Endpoint)); err == nil {
+			host = u.Host
+		}
+	}
+	switch {
+	case host != "":
+		endpoint = provider + ":" + strings.ToLower(host)
+	case strings.TrimSpace(inv.Region) != "":
+		endpoint = provider + ":" + strings.TrimSpace(inv.Region)
+	case strings.TrimSpace(conn.Region) != "":
+		endpoint = provider + ":" + strings.TrimSpace(conn.Region)
+	default:
+		endpoint = provider
+	}
+	return bucket, endpoint
+}
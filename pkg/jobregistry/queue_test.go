@@ -0,0 +1,263 @@
+package jobregistry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// queueJob writes a job awaiting admission, enqueued offset after a fixed
+// base time.
+func queueJob(t *testing.T, store *Store, priority string, offset time.Duration, mutate func(*JobQueueEntry)) *JobRecord {
+	t.Helper()
+	base := time.Date(2026, time.March, 14, 10, 0, 0, 0, time.UTC)
+	rec := &JobRecord{
+		JobID: uuid.New().String(), Type: JobTypeIndexBuild, State: JobStateQueued,
+		ManifestPath: "/m.yaml", CreatedAt: base.Add(offset),
+		Queue: &JobQueueEntry{Priority: priority, EnqueuedAt: base.Add(offset)},
+	}
+	if mutate != nil {
+		mutate(rec.Queue)
+	}
+	require.NoError(t, store.Write(rec))
+	return rec
+}
+
+// runningJob writes an admitted job that holds queue capacity.
+func runningJob(t *testing.T, store *Store, entry *JobQueueEntry) *JobRecord {
+	t.Helper()
+	now := time.Now().UTC()
+	rec := &JobRecord{
+		JobID: uuid.New().String(), Type: JobTypeIndexBuild, State: JobStateRunning,
+		PID: os.Getpid(), ManifestPath: "/m.yaml", CreatedAt: now, StartedAt: &now,
+		LastHeartbeat: &now, Queue: entry,
+	}
+	require.NoError(t, store.Write(rec))
+	return rec
+}
+
+func admittedIDs(t *testing.T, e *Executor) []string {
+	t.Helper()
+	admitted, err := e.admitLocked()
+	require.NoError(t, err)
+	var ids []string
+	for _, rec := range admitted {
+		require.NotNil(t, rec.Queue.AdmittedAt)
+		require.NotNil(t, rec.EnqueueExpiresAt)
+		ids = append(ids, rec.JobID)
+	}
+	return ids
+}
+
+func TestQueuePositionsOrderByPriorityThenEnqueueTime(t *testing.T) {
+	store := NewStore(t.TempDir())
+	lowFirst := queueJob(t, store, QueuePriorityLow, 0, nil)
+	normal := queueJob(t, store, QueuePriorityNormal, time.Minute, nil)
+	high := queueJob(t, store, QueuePriorityHigh, 2*time.Minute, nil)
+	normalLater := queueJob(t, store, QueuePriorityNormal, 3*time.Minute, nil)
+	runningJob(t, store, &JobQueueEntry{Priority: QueuePriorityHigh})
+
+	jobs, err := store.List()
+	require.NoError(t, err)
+	positions := QueuePositions(jobs)
+	require.Len(t, positions, 4, "only waiting jobs have a position")
+	require.Equal(t, 1, positions[high.JobID])
+	require.Equal(t, 2, positions[normal.JobID])
+	require.Equal(t, 3, positions[normalLater.JobID])
+	require.Equal(t, 4, positions[lowFirst.JobID])
+}
+
+func TestAdmitQueuedHonoursMaxRunning(t *testing.T) {
+	root := t.TempDir()
+	e := NewExecutor(root)
+	e.Limits = QueueLimits{MaxRunning: 2}
+	runningJob(t, e.Store(), &JobQueueEntry{Priority: QueuePriorityNormal})
+	normal := queueJob(t, e.Store(), QueuePriorityNormal, 0, nil)
+	high := queueJob(t, e.Store(), QueuePriorityHigh, time.Minute, nil)
+
+	require.Equal(t, []string{high.JobID}, admittedIDs(t, e))
+	require.Empty(t, admittedIDs(t, e), "the cap is full until a job ends")
+
+	rec, err := e.Store().Get(normal.JobID)
+	require.NoError(t, err)
+	require.True(t, rec.AwaitingAdmission())
+}
+
+func TestAdmitQueuedIgnoresJobsWhoseProcessIsGone(t *testing.T) {
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, exited.Run())
+
+	e := NewExecutor(t.TempDir())
+	e.Limits = QueueLimits{MaxRunning: 1}
+	crashed := runningJob(t, e.Store(), &JobQueueEntry{Priority: QueuePriorityNormal})
+	crashed.PID = exited.Process.Pid
+	require.NoError(t, e.Store().Write(crashed))
+	waiting := queueJob(t, e.Store(), QueuePriorityNormal, 0, nil)
+
+	require.Equal(t, []string{waiting.JobID}, admittedIDs(t, e))
+}
+
+func TestAdmissionHoldStopsAdmissionUntilReleased(t *testing.T) {
+	e := NewExecutor(t.TempDir())
+	waiting := queueJob(t, e.Store(), QueuePriorityNormal, 0, nil)
+
+	require.NoError(t, e.HoldAdmission())
+	require.Empty(t, admittedIDs(t, e), "a live hold admits nothing")
+	jobs, err := e.Store().ListReadOnlyStrict()
+	require.NoError(t, err, "the hold file is a known jobs root entry")
+	require.Len(t, jobs, 1)
+
+	require.NoError(t, e.ReleaseAdmission())
+	require.Equal(t, []string{waiting.JobID}, admittedIDs(t, e))
+}
+
+func TestAdmissionHoldOfExitedProcessIsIgnored(t *testing.T) {
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, exited.Run())
+
+	e := NewExecutor(t.TempDir())
+	waiting := queueJob(t, e.Store(), QueuePriorityNormal, 0, nil)
+	holdPath := filepath.Join(e.Store().RootDir(), admissionHoldName)
+	require.NoError(t, os.WriteFile(holdPath, []byte(strconv.Itoa(exited.Process.Pid)+"\n"), 0o600))
+
+	require.Equal(t, []string{waiting.JobID}, admittedIDs(t, e))
+	require.NoFileExists(t, holdPath, "a stale hold is removed")
+}
+
+func TestAdmitQueuedPassesOverFullBucket(t *testing.T) {
+	e := NewExecutor(t.TempDir())
+	e.Limits = QueueLimits{MaxPerBucket: 1, MaxPerEndpoint: 2}
+	runningJob(t, e.Store(), &JobQueueEntry{Bucket: "s3:a", Endpoint: "s3:us-east-1"})
+	sameBucket := queueJob(t, e.Store(), QueuePriorityHigh, 0, func(q *JobQueueEntry) {
+		q.Bucket, q.Endpoint = "s3:a", "s3:us-east-1"
+	})
+	otherBucket := queueJob(t, e.Store(), QueuePriorityNormal, time.Minute, func(q *JobQueueEntry) {
+		q.Bucket, q.Endpoint = "s3:b", "s3:us-east-1"
+	})
+	fullEndpoint := queueJob(t, e.Store(), QueuePriorityNormal, 2*time.Minute, func(q *JobQueueEntry) {
+		q.Bucket, q.Endpoint = "s3:c", "s3:us-east-1"
+	})
+
+	require.Equal(t, []string{otherBucket.JobID}, admittedIDs(t, e))
+	for _, id := range []string{sameBucket.JobID, fullEndpoint.JobID} {
+		rec, err := e.Store().Get(id)
+		require.NoError(t, err)
+		require.True(t, rec.AwaitingAdmission(), id)
+	}
+}
+
+func TestAdmitQueuedMemoryBudgetHoldsTheLine(t *testing.T) {
+	const gib = int64(1) << 30
+	e := NewExecutor(t.TempDir())
+	e.Limits = QueueLimits{MemoryBytes: 8 * gib}
+	running := runningJob(t, e.Store(), &JobQueueEntry{MemoryBytes: 6 * gib})
+	large := queueJob(t, e.Store(), QueuePriorityNormal, 0, func(q *JobQueueEntry) { q.MemoryBytes = 4 * gib })
+	small := queueJob(t, e.Store(), QueuePriorityNormal, time.Minute, func(q *JobQueueEntry) { q.MemoryBytes = gib })
+
+	require.Empty(t, admittedIDs(t, e), "a small job must not overtake a large one waiting for memory")
+
+	running.State = JobStateSuccess
+	require.NoError(t, e.Store().Write(running))
+	require.Equal(t, []string{large.JobID, small.JobID}, admittedIDs(t, e))
+
+	// A job larger than the whole budget still runs, alone.
+	huge := queueJob(t, e.Store(), QueuePriorityNormal, 2*time.Minute, func(q *JobQueueEntry) { q.MemoryBytes = 16 * gib })
+	require.Empty(t, admittedIDs(t, e))
+	jobs, err := e.Store().List()
+	require.NoError(t, err)
+	for i := range jobs {
+		if jobs[i].JobID != huge.JobID {
+			jobs[i].State = JobStateSuccess
+			require.NoError(t, e.Store().Write(&jobs[i]))
+		}
+	}
+	require.Equal(t, []string{huge.JobID}, admittedIDs(t, e))
+}
+
+func TestStartIndexBuildBackgroundQueuesBeyondLimits(t *testing.T) {
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte("version: 1\n"), 0o600))
+	e := NewExecutor(root)
+	e.Limits = QueueLimits{MaxRunning: 1}
+	e.newCommand = helperCommand(t, root, "", 300*time.Millisecond)
+
+	first, err := e.StartIndexBuildBackground(manifestPath, "first", BackgroundOptions{})
+	require.NoError(t, err)
+	require.Greater(t, first.PID, 0)
+	second, err := e.StartIndexBuildBackground(manifestPath, "second", BackgroundOptions{Priority: "high"})
+	require.NoError(t, err)
+	require.True(t, second.AwaitingAdmission())
+	require.Zero(t, second.PID)
+	require.Equal(t, QueuePriorityHigh, second.Queue.Priority)
+
+	waitHelperCompletion(t, e.Store(), first)
+	started, err := e.AdmitQueued()
+	require.NoError(t, err)
+	require.Len(t, started, 1)
+	require.Equal(t, second.JobID, started[0].JobID)
+	waitHelperCompletion(t, e.Store(), started[0])
+}
+
+func TestStartIndexBuildBackgroundRejectsUnknownPriority(t *testing.T) {
+	root := t.TempDir()
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte("version: 1\n"), 0o600))
+	_, err := NewExecutor(root).StartIndexBuildBackground(manifestPath, "", BackgroundOptions{Priority: "urgent"})
+	require.ErrorContains(t, err, "invalid queue priority")
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestStopDequeuesWaitingJob(t *testing.T) {
+	store := NewStore(t.TempDir())
+	rec := queueJob(t, store, QueuePriorityNormal, 0, nil)
+
+	result, err := store.Stop(rec.JobID, StopOptions{})
+	require.NoError(t, err)
+	require.Equal(t, string(JobStateStopped), result.State)
+	stopped, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.Equal(t, JobStateStopped, stopped.State)
+	require.NotNil(t, stopped.EndedAt)
+}
+
+func TestClaimQueuedRejectsJobAwaitingAdmission(t *testing.T) {
+	store := NewStore(t.TempDir())
+	rec := queueJob(t, store, QueuePriorityNormal, 0, nil)
+
+	_, err := store.ClaimQueued(rec.JobID, os.Getpid(), nil)
+	require.ErrorContains(t, err, "has not been admitted")
+	still, err := store.Get(rec.JobID)
+	require.NoError(t, err)
+	require.True(t, still.AwaitingAdmission(), "a refused claim leaves the job queued")
+}
+
+func TestIndexBuildQueueKeysFromManifest(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(`version: "1.0"
+connection:
+  provider: s3
+  bucket: logs
+  base_uri: s3://logs/app/
+  endpoint: https://S3.Example.com:9000
+`), 0o600))
+	bucket, endpoint := indexBuildQueueKeys(&IndexBuildInvocation{ManifestPath: manifestPath})
+	require.Equal(t, "s3:logs", bucket)
+	require.Equal(t, "s3:s3.example.com:9000", endpoint)
+
+	bucket, endpoint = indexBuildQueueKeys(&IndexBuildInvocation{ManifestPath: manifestPath, EndpointHost: "other.example.com"})
+	require.Equal(t, "s3:logs", bucket)
+	require.Equal(t, "s3:other.example.com", endpoint)
+
+	bucket, endpoint = indexBuildQueueKeys(&IndexBuildInvocation{ManifestPath: filepath.Join(t.TempDir(), "missing.yaml")})
+	require.Empty(t, bucket)
+	require.Empty(t, endpoint)
+}
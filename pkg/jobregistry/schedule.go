@@ -0,0 +1,315 @@
+package jobregistry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schedule overlap policies: what a due run does when a job with the same
+// invocation fingerprint (the --dedupe identity) is still active.
+const (
+	// ScheduleOverlapSkip drops the due run.
+	ScheduleOverlapSkip = "skip"
+	// ScheduleOverlapQueue holds the due run and starts it once the active job
+	// ends. At most one run is held per schedule.
+	ScheduleOverlapQueue = "queue"
+	// ScheduleOverlapCancelPrevious stops the active job with SIGTERM, then
+	// starts the due run.
+	ScheduleOverlapCancelPrevious = "cancel_previous"
+)
+
+// Schedule run outcomes recorded in Schedule.LastOutcome.
+const (
+	ScheduleOutcomeStarted = "started"
+	ScheduleOutcomeSkipped = "skipped"
+	ScheduleOutcomeQueued  = "queued"
+	ScheduleOutcomeMissed  = "missed"
+	ScheduleOutcomeFailed  = "failed"
+)
+
+// ScheduleJob is the job template a schedule starts on every run.
+type ScheduleJob struct {
+	Type         string `json:"type"`
+	ManifestPath string `json:"manifest_path"`
+	Name         string `json:"name,omitempty"`
+	Since        string `json:"since,omitempty"`
+	// Priority and MemoryBytes are the queue priority class and memory
+	// reservation of each run.
+	Priority    string `json:"priority,omitempty"`
+	MemoryBytes int64  `json:"memory_bytes,omitempty"`
+}
+
+// Schedule is the persistent record of a recurring job, written to
+// <root>/schedules/<schedule_id>/schedule.json.
+//
+// The schema is designed for backward-compatible extension (additive fields).
+type Schedule struct {
+	ScheduleID string      `json:"schedule_id"`
+	Name       string      `json:"name,omitempty"`
+	Cron       string      `json:"cron"`
+	Timezone   string      `json:"timezone"`
+	Job        ScheduleJob `json:"job"`
+	Overlap    string      `json:"overlap"`
+	// Jitter delays each run by a random duration in [0, Jitter), as a Go
+	// duration string ("90s", "5m").
+	Jitter string `json:"jitter,omitempty"`
+	// CatchUp runs once for missed runs (for example, while the server was
+	// down) instead of waiting for the next one.
+	CatchUp   bool      `json:"catch_up"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// ScheduledFor is the cron time of the next run; NextRunAt is that time
+	// plus jitter.
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	NextRunAt    *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time `json:"last_run_at,omitempty"`
+	LastJobID    string     `json:"last_job_id,omitempty"`
+	LastOutcome  string     `json:"last_outcome,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	// QueuedSince is set while a run is held by ScheduleOverlapQueue.
+	QueuedSince *time.Time `json:"queued_since,omitempty"`
+	MissedRuns  int        `json:"missed_runs,omitempty"`
+}
+
+// Validate checks a schedule's cron expression, timezone, template, overlap
+// policy, and jitter.
+func (s *Schedule) Validate() error {
+	if s == nil {
+		return fmt.Errorf("schedule is nil")
+	}
+	if _, _, err := s.compile(); err != nil {
+		return err
+	}
+	if strings.TrimSpace(s.Job.Type) != JobTypeIndexBuild {
+		return fmt.Errorf("schedule job type must be %s", JobTypeIndexBuild)
+	}
+	if strings.TrimSpace(s.Job.ManifestPath) == "" {
+		return fmt.Errorf("schedule job manifest_path is required")
+	}
+	if err := ValidateManagedJobName(s.Job.Name); err != nil {
+		return err
+	}
+	if err := ValidateIndexBuildSince(s.Job.Since); err != nil {
+		return err
+	}
+	if _, err := NormalizeQueuePriority(s.Job.Priority); err != nil {
+		return err
+	}
+	if s.Job.MemoryBytes < 0 {
+		return fmt.Errorf("schedule job memory_bytes must not be negative")
+	}
+	switch s.Overlap {
+	case ScheduleOverlapSkip, ScheduleOverlapQueue, ScheduleOverlapCancelPrevious:
+	default:
+		return fmt.Errorf("invalid overlap %q (want skip, queue, or cancel_previous)", s.Overlap)
+	}
+	if _, err := s.jitter(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Schedule) compile() (*CronSchedule, *time.Location, error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return nil, nil, err
+	}
+	tz := strings.TrimSpace(s.Timezone)
+	if tz == "" {
+		tz = "UTC"
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return cron, loc, nil
+}
+
+func (s *Schedule) jitter() (time.Duration, error) {
+	if strings.TrimSpace(s.Jitter) == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s.Jitter))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid jitter %q: want a non-negative duration such as 5m", s.Jitter)
+	}
+	return d, nil
+}
+
+// ScheduleStore persists Schedules beside the jobs they start.
+//
+// Directory layout:
+//
+//	<root>/schedules/<schedule_id>/schedule.json
+//	<root>/schedules/.lock
+//
+// Store.List skips the schedules directory, and Store.ListReadOnlyStrict
+// accepts it only as a real directory.
+type ScheduleStore struct {
+	dir string
+}
+
+const (
+	schedulesDirName = "schedules"
+	scheduleFileName = "schedule.json"
+)
+
+// ErrScheduleNotFound is returned for an unknown schedule id.
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+func NewScheduleStore(root string) *ScheduleStore {
+	root = NewStore(root).RootDir()
+	if root == "" {
+		return &ScheduleStore{}
+	}
+	return &ScheduleStore{dir: filepath.Join(root, schedulesDirName)}
+}
+
+func (s *ScheduleStore) Dir() string {
+	return s.dir
+}
+
+// withLock serializes schedule mutations and scheduler ticks across
+// processes sharing the jobs root, so two servers never fire the same run.
+func (s *ScheduleStore) withLock(fn func() error) error {
+	if strings.TrimSpace(s.dir) == "" {
+		return fmt.Errorf("schedule store dir is empty")
+	}
+	if err := mkdirSecure(s.dir); err != nil {
+		return err
+	}
+	f, err := openFileNoFollow(filepath.Join(s.dir, ".lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("open schedule lock: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := lockFileExclusive(f); err != nil {
+		return fmt.Errorf("lock schedules: %w", err)
+	}
+	defer func() { _ = unlockFile(f) }()
+	return fn()
+}
+
+// Create validates sched, assigns its id and first run, and persists it.
+func (s *ScheduleStore) Create(sched *Schedule, now time.Time) error {
+	if err := sched.Validate(); err != nil {
+		return err
+	}
+	sched.ScheduleID = uuid.New().String()
+	sched.CreatedAt = now.UTC()
+	if strings.TrimSpace(sched.Timezone) == "" {
+		sched.Timezone = "UTC"
+	}
+	if err := scheduleNextRun(sched, now, rand.Int64N); err != nil {
+		return err
+	}
+	return s.withLock(func() error { return s.write(sched) })
+}
+
+func (s *ScheduleStore) Get(scheduleID string) (*Schedule, error) {
+	if err := validateJobID(scheduleID); err != nil {
+		return nil, ErrScheduleNotFound
+	}
+	return s.read(scheduleID)
+}
+
+// List returns schedules ordered by creation time, oldest first.
+func (s *ScheduleStore) List() ([]Schedule, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read schedules dir: %w", err)
+	}
+	out := make([]Schedule, 0, len(entries))
+	for _, entry := range entries {
+		id := entry.Name()
+		if !entry.IsDir() || entry.Type()&os.ModeSymlink != 0 || validateJobID(id) != nil {
+			continue
+		}
+		sched, err := s.read(id)
+		if err != nil {
+			continue
+		}
+		out = append(out, *sched)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *ScheduleStore) Delete(scheduleID string) error {
+	if err := validateJobID(scheduleID); err != nil {
+		return ErrScheduleNotFound
+	}
+	return s.withLock(func() error {
+		path := filepath.Join(s.dir, scheduleID)
+		if err := rejectFinalSymlink(path); err != nil {
+			if os.IsNotExist(err) {
+				return ErrScheduleNotFound
+			}
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("delete schedule: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *ScheduleStore) read(scheduleID string) (*Schedule, error) {
+	f, err := openJobFileNoFollow(s.dir, scheduleID, scheduleFileName, os.O_RDONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("open schedule: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	var sched Schedule
+	if err := json.NewDecoder(f).Decode(&sched); err != nil {
+		return nil, fmt.Errorf("decode schedule %s: %w", scheduleID, err)
+	}
+	return &sched, nil
+}
+
+// write replaces a schedule record atomically. Caller must hold withLock.
+func (s *ScheduleStore) write(sched *Schedule) error {
+	data, err := json.MarshalIndent(sched, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode schedule: %w", err)
+	}
+	return writeBoundFileAtomic(s.dir, sched.ScheduleID, scheduleFileName, append(data, '\n'))
+}
+
+// scheduleNextRun sets sched's next cron time after now and its jittered run
+// time. randN returns a value in [0, n).
+func scheduleNextRun(sched *Schedule, now time.Time, randN func(int64) int64) error {
+	cron, loc, err := sched.compile()
+	if err != nil {
+		return err
+	}
+	next := cron.Next(now.In(loc))
+	if next.IsZero() {
+		sched.ScheduledFor, sched.NextRunAt = nil, nil
+		return nil
+	}
+	next = next.UTC()
+	runAt := next
+	if jitter, err := sched.jitter(); err != nil {
+		return err
+	} else if jitter > 0 {
+		runAt = runAt.Add(time.Duration(randN(int64(jitter))))
+	}
+	sched.ScheduledFor, sched.NextRunAt = &next, &runAt
+	return nil
+}
@@ -0,0 +1,248 @@
+package jobregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultScheduleTickInterval is how often a Scheduler checks for due runs.
+	DefaultScheduleTickInterval = 15 * time.Second
+	// ScheduleMissedGrace is how late a run may start before it counts as
+	// missed. Missed runs are dropped unless the schedule catches up.
+	ScheduleMissedGrace = time.Minute
+	// maxCountedMissedRuns bounds the missed-run count after a long outage.
+	maxCountedMissedRuns = 10000
+)
+
+// Scheduler starts the runs of persisted Schedules as managed index builds.
+// Every tick holds the schedule lock, so servers sharing a jobs root never
+// fire the same run twice.
+type Scheduler struct {
+	schedules  *ScheduleStore
+	jobs       *Store
+	invocation *IndexBuildInvocation
+	start      func(string, string, BackgroundOptions) (*JobRecord, error)
+	now        func() time.Time
+	randN      func(int64) int64
+
+	// Interval is the tick interval; zero means DefaultScheduleTickInterval.
+	Interval time.Duration
+	// StopTimeout is how long a cancel_previous run waits for the previous job
+	// to exit after SIGTERM; zero uses the Store.Stop default.
+	StopTimeout time.Duration
+	// OnRun, when set, is called after each due or held run with the updated
+	// schedule and the job it started (nil when none started).
+	OnRun func(Schedule, *JobRecord)
+}
+
+// NewScheduler returns a Scheduler for the schedules under the executor's
+// jobs root. Runs are enqueued through executor, under its queue limits, with
+// invocation as the base build invocation, as a server submission would.
+func NewScheduler(executor *Executor, invocation *IndexBuildInvocation) *Scheduler {
+	return &Scheduler{
+		schedules:  NewScheduleStore(executor.Store().RootDir()),
+		jobs:       executor.Store(),
+		invocation: invocation,
+		start:      executor.StartIndexBuildBackground,
+		now:        time.Now,
+		randN:      rand.Int64N,
+	}
+}
+
+// Run ticks until ctx is done. The first tick runs at once so runs missed
+// while no scheduler was running are handled on startup.
+func (s *Scheduler) Run(ctx context.Context) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultScheduleTickInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		_ = s.Tick()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick starts every due or held run once.
+func (s *Scheduler) Tick() error {
+	if _, err := os.Lstat(s.schedules.dir); os.IsNotExist(err) {
+		return nil
+	}
+	now := s.now().UTC()
+	return s.schedules.withLock(func() error {
+		list, err := s.schedules.List()
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for i := range list {
+			sched := &list[i]
+			job, ran := s.tickSchedule(sched, now)
+			if !ran {
+				continue
+			}
+			if err := s.schedules.write(sched); err != nil {
+				errs = append(errs, fmt.Errorf("schedule %s: %w", sched.ScheduleID, err))
+				continue
+			}
+			if s.OnRun != nil {
+				s.OnRun(*sched, job)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// tickSchedule runs sched if it is due or holds a queued run. It reports
+// whether sched changed.
+func (s *Scheduler) tickSchedule(sched *Schedule, now time.Time) (*JobRecord, bool) {
+	if sched.QueuedSince != nil {
+		job, ran := s.fire(sched, now, true)
+		if ran {
+			return job, true
+		}
+	}
+	if sched.NextRunAt == nil || now.Before(*sched.NextRunAt) {
+		return nil, false
+	}
+
+	missed := s.countMissed(sched, now)
+	late := now.Sub(*sched.NextRunAt) > ScheduleMissedGrace
+	if err := scheduleNextRun(sched, now, s.randN); err != nil {
+		sched.LastOutcome, sched.LastError = ScheduleOutcomeFailed, err.Error()
+		sched.NextRunAt, sched.ScheduledFor = nil, nil
+		return nil, true
+	}
+	if late && !sched.CatchUp {
+		sched.MissedRuns += missed + 1
+		sched.LastOutcome, sched.LastError = ScheduleOutcomeMissed, ""
+		return nil, true
+	}
+	sched.MissedRuns += missed
+	job, _ := s.fire(sched, now, false)
+	return job, true
+}
+
+// countMissed counts cron times after the due one that also passed by now;
+// a catch-up run coalesces them.
+func (s *Scheduler) countMissed(sched *Schedule, now time.Time) int {
+	cron, loc, err := sched.compile()
+	if err != nil || sched.ScheduledFor == nil {
+		return 0
+	}
+	n := 0
+	for t := cron.Next(sched.ScheduledFor.In(loc)); !t.IsZero() && !t.After(now) && n < maxCountedMissedRuns; t = cron.Next(t) {
+		n++
+	}
+	return n
+}
+
+// fire applies the overlap policy and starts the run. A held run (queued by
+// an earlier tick) waits silently while the previous job is active; it
+// reports whether it resolved.
+func (s *Scheduler) fire(sched *Schedule, now time.Time, held bool) (*JobRecord, bool) {
+	fail := func(err error) (*JobRecord, bool) {
+		sched.LastRunAt = &now
+		sched.LastOutcome, sched.LastError = ScheduleOutcomeFailed, err.Error()
+		sched.QueuedSince = nil
+		return nil, true
+	}
+	inv := IndexBuildInvocation{}
+	if s.invocation != nil {
+		inv = *s.invocation
+	}
+	inv.Since = strings.TrimSpace(sched.Job.Since)
+	inv.Name = strings.TrimSpace(sched.Job.Name)
+	_, fingerprint, err := PrepareIndexBuildInvocation(sched.Job.ManifestPath, sched.Job.Name, &inv)
+	if err != nil {
+		return fail(err)
+	}
+	active, err := s.activeJobs(fingerprint)
+	if err != nil {
+		return fail(err)
+	}
+	if len(active) > 0 {
+		switch {
+		case held:
+			return nil, false
+		case sched.Overlap == ScheduleOverlapCancelPrevious:
+			for _, job := range active {
+				_, err := s.jobs.Stop(job.JobID, StopOptions{Signal: "term", WaitTimeout: s.StopTimeout})
+				if err != nil && !errors.Is(err, ErrJobNotRunning) {
+					return fail(fmt.Errorf("cancel previous job %s: %w", job.JobID, err))
+				}
+			}
+		default:
+			return s.overlapped(sched, now, active[0].JobID)
+		}
+	}
+
+	job, err := s.start(sched.Job.ManifestPath, sched.Job.Name, BackgroundOptions{
+		Dedupe:      true,
+		Since:       inv.Since,
+		JobType:     JobTypeIndexBuild,
+		Invocation:  &inv,
+		ScheduleID:  sched.ScheduleID,
+		Priority:    sched.Job.Priority,
+		MemoryBytes: sched.Job.MemoryBytes,
+	})
+	if err != nil {
+		if errors.Is(err, ErrDuplicateRunningJob) {
+			if held {
+				return nil, false
+			}
+			if sched.Overlap != ScheduleOverlapCancelPrevious {
+				return s.overlapped(sched, now, "")
+			}
+		}
+		return fail(err)
+	}
+	sched.LastRunAt = &now
+	sched.LastJobID = job.JobID
+	sched.LastOutcome, sched.LastError = ScheduleOutcomeStarted, ""
+	sched.QueuedSince = nil
+	return job, true
+}
+
+// overlapped records a due run that found its previous job still active.
+func (s *Scheduler) overlapped(sched *Schedule, now time.Time, activeJobID string) (*JobRecord, bool) {
+	sched.LastRunAt = &now
+	sched.LastError = ""
+	if activeJobID != "" {
+		sched.LastError = "previous job still active: " + activeJobID
+	}
+	if sched.Overlap == ScheduleOverlapQueue {
+		sched.LastOutcome = ScheduleOutcomeQueued
+		if sched.QueuedSince == nil {
+			sched.QueuedSince = &now
+		}
+		return nil, true
+	}
+	sched.LastOutcome = ScheduleOutcomeSkipped
+	return nil, true
+}
+
+func (s *Scheduler) activeJobs(fingerprint string) ([]JobRecord, error) {
+	jobs, err := s.jobs.List()
+	if err != nil {
+		return nil, err
+	}
+	var out []JobRecord
+	for _, job := range jobs {
+		if job.InvocationFingerprint == fingerprint && activeJobState(job.State) {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,247 @@
+package jobregistry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// schedulerHarness drives a Scheduler over a temp jobs root with a fake start
+// that records a job with the real invocation fingerprint.
+type schedulerHarness struct {
+	t         *testing.T
+	root      string
+	manifest  string
+	now       time.Time
+	scheduler *Scheduler
+	started   []JobRecord
+	state     JobState
+	pid       int
+}
+
+func newSchedulerHarness(t *testing.T) *schedulerHarness {
+	t.Helper()
+	root := t.TempDir()
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte("version: \"1.0\"\n"), 0o600))
+	h := &schedulerHarness{
+		t: t, root: root, manifest: manifest,
+		now:   time.Date(2026, time.March, 14, 10, 0, 0, 0, time.UTC),
+		state: JobStateRunning, pid: os.Getpid(),
+	}
+	h.scheduler = NewScheduler(NewExecutor(root), nil)
+	h.scheduler.now = func() time.Time { return h.now }
+	h.scheduler.randN = func(n int64) int64 { return n / 2 }
+	h.scheduler.start = func(manifestPath, name string, opts BackgroundOptions) (*JobRecord, error) {
+		inv, fingerprint, err := PrepareIndexBuildInvocation(manifestPath, name, opts.Invocation)
+		if err != nil {
+			return nil, err
+		}
+		rec := &JobRecord{
+			JobID: uuid.New().String(), Type: JobTypeIndexBuild, State: h.state, PID: h.pid,
+			ManifestPath: inv.ManifestPath, CreatedAt: h.now, Invocation: inv,
+			InvocationFingerprint: fingerprint, ScheduleID: opts.ScheduleID,
+		}
+		require.NoError(t, h.scheduler.jobs.Write(rec))
+		h.started = append(h.started, *rec)
+		return rec, nil
+	}
+	return h
+}
+
+func (h *schedulerHarness) create(overlap string, mutate func(*Schedule)) *Schedule {
+	h.t.Helper()
+	sched := &Schedule{
+		Cron:    "@hourly",
+		Job:     ScheduleJob{Type: JobTypeIndexBuild, ManifestPath: h.manifest, Since: "auto"},
+		Overlap: overlap,
+	}
+	if mutate != nil {
+		mutate(sched)
+	}
+	require.NoError(h.t, h.scheduler.schedules.Create(sched, h.now))
+	return sched
+}
+
+func (h *schedulerHarness) tickAt(at time.Time) *Schedule {
+	h.t.Helper()
+	h.now = at
+	require.NoError(h.t, h.scheduler.Tick())
+	list, err := h.scheduler.schedules.List()
+	require.NoError(h.t, err)
+	require.Len(h.t, list, 1)
+	return &list[0]
+}
+
+func (h *schedulerHarness) finish(jobID string) {
+	h.t.Helper()
+	rec, err := h.scheduler.jobs.Get(jobID)
+	require.NoError(h.t, err)
+	rec.State = JobStateSuccess
+	require.NoError(h.t, h.scheduler.jobs.Write(rec))
+}
+
+func TestScheduleStoreCreatePersistsNextRun(t *testing.T) {
+	h := newSchedulerHarness(t)
+	sched := h.create(ScheduleOverlapSkip, func(s *Schedule) { s.Jitter = "10m" })
+
+	got, err := NewScheduleStore(h.root).Get(sched.ScheduleID)
+	require.NoError(t, err)
+	require.Equal(t, "UTC", got.Timezone)
+	require.Equal(t, time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC), *got.ScheduledFor)
+	require.True(t, !got.NextRunAt.Before(*got.ScheduledFor) && got.NextRunAt.Before(got.ScheduledFor.Add(10*time.Minute)))
+
+	jobs, err := NewStore(h.root).List()
+	require.NoError(t, err)
+	require.Empty(t, jobs, "the schedules dir must not list as a job")
+	jobs, err = NewStore(h.root).ListReadOnlyStrict()
+	require.NoError(t, err, "strict listing accepts the schedules dir")
+	require.Empty(t, jobs)
+
+	require.NoError(t, NewScheduleStore(h.root).Delete(sched.ScheduleID))
+	_, err = NewScheduleStore(h.root).Get(sched.ScheduleID)
+	require.ErrorIs(t, err, ErrScheduleNotFound)
+}
+
+func TestListReadOnlyStrictRejectsSymlinkedSchedulesDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Symlink(t.TempDir(), filepath.Join(root, schedulesDirName)); err != nil {
+		t.Skipf("symlink unavailable: %v", err)
+	}
+	_, err := NewStore(root).ListReadOnlyStrict()
+	require.ErrorContains(t, err, "invalid job registry schedules directory")
+}
+
+func TestScheduleValidateRejectsBadFields(t *testing.T) {
+	valid := func() *Schedule {
+		return &Schedule{Cron: "@daily", Overlap: ScheduleOverlapSkip, Job: ScheduleJob{Type: JobTypeIndexBuild, ManifestPath: "/m.yaml"}}
+	}
+	require.NoError(t, valid().Validate())
+	for name, mutate := range map[string]func(*Schedule){
+		"cron":     func(s *Schedule) { s.Cron = "bad" },
+		"timezone": func(s *Schedule) { s.Timezone = "Nowhere/Special" },
+		"type":     func(s *Schedule) { s.Job.Type = "index.enrich" },
+		"manifest": func(s *Schedule) { s.Job.ManifestPath = "" },
+		"overlap":  func(s *Schedule) { s.Overlap = "replace" },
+		"jitter":   func(s *Schedule) { s.Jitter = "-1m" },
+		"since":    func(s *Schedule) { s.Job.Since = "yesterday" },
+	} {
+		s := valid()
+		mutate(s)
+		require.Error(t, s.Validate(), name)
+	}
+}
+
+func TestSchedulerStartsDueRunAndAdvances(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.create(ScheduleOverlapSkip, nil)
+
+	sched := h.tickAt(time.Date(2026, time.March, 14, 10, 30, 0, 0, time.UTC))
+	require.Empty(t, h.started, "not due yet")
+	require.Empty(t, sched.LastOutcome)
+
+	sched = h.tickAt(time.Date(2026, time.March, 14, 11, 0, 20, 0, time.UTC))
+	require.Len(t, h.started, 1)
+	require.Equal(t, ScheduleOutcomeStarted, sched.LastOutcome)
+	require.Equal(t, h.started[0].JobID, sched.LastJobID)
+	require.Equal(t, sched.ScheduleID, h.started[0].ScheduleID)
+	require.Equal(t, "auto", h.started[0].Invocation.Since)
+	require.Equal(t, time.Date(2026, time.March, 14, 12, 0, 0, 0, time.UTC), *sched.ScheduledFor)
+}
+
+func TestSchedulerSkipsWhilePreviousRunActive(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.create(ScheduleOverlapSkip, nil)
+	h.tickAt(time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC))
+
+	sched := h.tickAt(time.Date(2026, time.March, 14, 12, 0, 0, 0, time.UTC))
+	require.Len(t, h.started, 1)
+	require.Equal(t, ScheduleOutcomeSkipped, sched.LastOutcome)
+	require.Contains(t, sched.LastError, h.started[0].JobID)
+}
+
+func TestSchedulerQueuesRunUntilPreviousEnds(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.create(ScheduleOverlapQueue, nil)
+	h.tickAt(time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC))
+
+	sched := h.tickAt(time.Date(2026, time.March, 14, 12, 0, 0, 0, time.UTC))
+	require.Equal(t, ScheduleOutcomeQueued, sched.LastOutcome)
+	require.NotNil(t, sched.QueuedSince)
+
+	sched = h.tickAt(time.Date(2026, time.March, 14, 12, 1, 0, 0, time.UTC))
+	require.Len(t, h.started, 1, "held while the previous job is active")
+	require.NotNil(t, sched.QueuedSince)
+
+	h.finish(h.started[0].JobID)
+	sched = h.tickAt(time.Date(2026, time.March, 14, 12, 2, 0, 0, time.UTC))
+	require.Len(t, h.started, 2)
+	require.Equal(t, ScheduleOutcomeStarted, sched.LastOutcome)
+	require.Nil(t, sched.QueuedSince)
+}
+
+func TestSchedulerCancelsPreviousRun(t *testing.T) {
+	cmd := exec.Command("sleep", "30")
+	require.NoError(t, cmd.Start())
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-done
+	})
+
+	h := newSchedulerHarness(t)
+	h.pid = cmd.Process.Pid
+	h.scheduler.StopTimeout = 5 * time.Second
+	h.create(ScheduleOverlapCancelPrevious, nil)
+	h.tickAt(time.Date(2026, time.March, 14, 11, 0, 0, 0, time.UTC))
+
+	h.pid = os.Getpid()
+	sched := h.tickAt(time.Date(2026, time.March, 14, 12, 0, 0, 0, time.UTC))
+	require.Len(t, h.started, 2)
+	require.Equal(t, ScheduleOutcomeStarted, sched.LastOutcome, sched.LastError)
+	previous, err := h.scheduler.jobs.Get(h.started[0].JobID)
+	require.NoError(t, err)
+	require.Equal(t, JobStateStopped, previous.State)
+}
+
+func TestSchedulerMissedRunsWithoutCatchUp(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.create(ScheduleOverlapSkip, nil)
+
+	// The server was down from 10:00 until 13:30: the 11:00, 12:00, and 13:00
+	// runs were missed.
+	sched := h.tickAt(time.Date(2026, time.March, 14, 13, 30, 0, 0, time.UTC))
+	require.Empty(t, h.started)
+	require.Equal(t, ScheduleOutcomeMissed, sched.LastOutcome)
+	require.Equal(t, 3, sched.MissedRuns)
+	require.Equal(t, time.Date(2026, time.March, 14, 14, 0, 0, 0, time.UTC), *sched.ScheduledFor)
+}
+
+func TestSchedulerCatchUpRunsOnceForMissedRuns(t *testing.T) {
+	h := newSchedulerHarness(t)
+	h.create(ScheduleOverlapSkip, func(s *Schedule) { s.CatchUp = true })
+
+	sched := h.tickAt(time.Date(2026, time.March, 14, 13, 30, 0, 0, time.UTC))
+	require.Len(t, h.started, 1)
+	require.Equal(t, ScheduleOutcomeStarted, sched.LastOutcome)
+	require.Equal(t, 2, sched.MissedRuns, "two later runs coalesce into the catch-up run")
+}
+
+func TestSchedulerAppliesJitter(t *testing.T) {
+	h := newSchedulerHarness(t)
+	created := h.create(ScheduleOverlapSkip, func(s *Schedule) { s.Jitter = "10m" })
+	runAt := *created.NextRunAt
+
+	h.tickAt(runAt.Add(-time.Second))
+	require.Empty(t, h.started, "a jittered run waits for its run time")
+
+	sched := h.tickAt(runAt)
+	require.Len(t, h.started, 1)
+	require.Equal(t, time.Date(2026, time.March, 14, 12, 5, 0, 0, time.UTC), *sched.NextRunAt)
+}
@@ -50,6 +50,9 @@ func (s *Store) Stop(jobID string, opts StopOptions) (*StopResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	if rec.AwaitingAdmission() {
+		return s.dequeue(jobID, sigStr)
+	}
 	if rec.PID <= 0 {
 		return nil, ErrJobNoPID
 	}
@@ -109,3 +112,29 @@ func (s *Store) markStopped(rec *JobRecord, sigStr string, forced bool) *StopRes
 		State:      string(rec.State),
 	}
 }
+
+// dequeue stops a job still waiting in the queue. It has no process, so it is
+// marked stopped under the start lock, where admission cannot race it. No
+// terminal hook event fires: those come from the managed child, and this job
+// never had one.
+func (s *Store) dequeue(jobID, sigStr string) (*StopResult, error) {
+	var result *StopResult
+	err := s.withStartLock(func() error {
+		rec, err := s.getReadOnlyStrict(jobID)
+		if err != nil {
+			return err
+		}
+		if !rec.AwaitingAdmission() {
+			return fmt.Errorf("%w (state=%s, admitted)", ErrJobNotRunning, rec.State)
+		}
+		now := time.Now().UTC()
+		rec.State = JobStateStopped
+		rec.EndedAt = &now
+		if err := s.writeRecord(rec); err != nil {
+			return err
+		}
+		result = &StopResult{JobID: jobID, Signal: sigStr, State: string(JobStateStopped)}
+		return nil
+	})
+	return result, err
+}
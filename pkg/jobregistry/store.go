@@ -27,6 +27,10 @@ var (
 //	<root>/<job_id>/job.json
 //	<root>/<job_id>/stdout.log
 //	<root>/<job_id>/stderr.log
+//	<root>/<job_id>/metrics.prom
+//	<root>/<job_id>/trace.jsonl
+//	<root>/<job_id>/events.jsonl
+//	<root>/schedules/ (see ScheduleStore)
 //
 // Root is expected to be under the app data dir.
 type Store struct {
@@ -110,6 +114,7 @@ func (s *Store) Write(record *JobRecord) error {
 			if fenceErr := recoveryFenceViolation(existing, record); fenceErr != nil {
 				return fenceErr
 			}
+			record.HookDeliveries = mergeHookDeliveries(existing.HookDeliveries, record.HookDeliveries)
 		}
 		return s.writeRecord(record)
 	})
@@ -235,6 +240,20 @@ func (s *Store) ListReadOnlyStrict() ([]JobRecord, error) {
 			}
 			continue
 		}
+		if entry.Name() == admissionHoldName {
+			info, infoErr := os.Lstat(filepath.Join(root, entry.Name()))
+			if infoErr != nil || info.Mode()&os.ModeSymlink != 0 || !info.Mode().IsRegular() {
+				return nil, fmt.Errorf("invalid job registry admission hold")
+			}
+			continue
+		}
+		if entry.Name() == schedulesDirName {
+			info, infoErr := os.Lstat(filepath.Join(root, entry.Name()))
+			if infoErr != nil || info.Mode()&os.ModeSymlink != 0 || !info.IsDir() {
+				return nil, fmt.Errorf("invalid job registry schedules directory")
+			}
+			continue
+		}
 		if entry.Type()&os.ModeSymlink != 0 || !entry.IsDir() {
 			return nil, fmt.Errorf("unrecognized job registry entry %q", entry.Name())
 		}
@@ -390,6 +409,75 @@ func newRecordTempName() (string, error) {
 	return "job.json.tmp." + hex.EncodeToString(random[:]), nil
 }
 
+// MetricsFileName is the Prometheus textfile a managed child writes its domain
+// metrics to.
+const MetricsFileName = "metrics.prom"
+
+// MetricsPath returns the canonical domain-metrics textfile path for a job.
+func (s *Store) MetricsPath(jobID string) string {
+	dir := s.JobDir(jobID)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, MetricsFileName)
+}
+
+// TraceFileName is the JSON-lines span file a managed child writes when the
+// server traces with the file exporter.
+const TraceFileName = "trace.jsonl"
+
+// TracePath returns the canonical trace file path for a job.
+func (s *Store) TracePath(jobID string) string {
+	dir := s.JobDir(jobID)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, TraceFileName)
+}
+
+// OpenMetricsRead opens a job's domain-metrics textfile without following a
+// final symlink.
+func (s *Store) OpenMetricsRead(jobID string) (*os.File, error) {
+	if err := s.ensureRoot(); err != nil {
+		return nil, err
+	}
+	if err := validateJobID(jobID); err != nil {
+		return nil, err
+	}
+	return openJobFileNoFollow(s.root, jobID, MetricsFileName, os.O_RDONLY, 0)
+}
+
+// EventsFileName is the JSONL file of progress, error, and summary records a
+// managed child appends as it runs. The server streams it to clients.
+const EventsFileName = "events.jsonl"
+
+// OpenEvents creates or opens a job's events file for appending without
+// following a final symlink. The caller owns the returned handle.
+func (s *Store) OpenEvents(jobID string) (*os.File, error) {
+	if err := validateJobID(jobID); err != nil {
+		return nil, err
+	}
+	if err := s.ensureRoot(); err != nil {
+		return nil, err
+	}
+	if err := ensureJobDirNoFollow(s.root, jobID); err != nil {
+		return nil, err
+	}
+	return openJobFileNoFollow(s.root, jobID, EventsFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+}
+
+// OpenEventsRead opens a job's events file for reading without following a
+// final symlink.
+func (s *Store) OpenEventsRead(jobID string) (*os.File, error) {
+	if err := s.ensureRoot(); err != nil {
+		return nil, err
+	}
+	if err := validateJobID(jobID); err != nil {
+		return nil, err
+	}
+	return openJobFileNoFollow(s.root, jobID, EventsFileName, os.O_RDONLY, 0)
+}
+
 // OpenLogRead opens a canonical registry-owned log without following a final
 // symlink. Callers must not use persisted record paths as read authority.
 func (s *Store) OpenLogRead(jobID, name string) (*os.File, error) {
@@ -418,6 +506,9 @@ func (s *Store) ClaimQueued(jobID string, pid int, validate func(*JobRecord) err
 		if rec.State != JobStateQueued {
 			return fmt.Errorf("managed job %s is not queued", jobID)
 		}
+		if rec.AwaitingAdmission() {
+			return fmt.Errorf("managed job %s has not been admitted from the queue", jobID)
+		}
 		failQueued := func(cause error) error {
 			now := time.Now().UTC()
 			rec.State = JobStateFailed
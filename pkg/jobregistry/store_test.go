@@ -1,6 +1,7 @@
 package jobregistry
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -323,6 +324,53 @@ func TestStoreOpenLogReadRejectsSwappedSymlink(t *testing.T) {
 	}
 }
 
+func TestStoreEventsAppendAndRejectSwappedSymlink(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.Write(&JobRecord{JobID: testJobID1, CreatedAt: time.Now().UTC()}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"one\n", "two\n"} {
+		f, err := store.OpenEvents(testJobID1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.WriteString(line); err != nil {
+			t.Fatal(err)
+		}
+		_ = f.Close()
+	}
+	f, err := store.OpenEventsRead(testJobID1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	_ = f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "one\ntwo\n" {
+		t.Fatalf("events = %q, want appended lines", data)
+	}
+
+	eventsPath := filepath.Join(store.JobDir(testJobID1), EventsFileName)
+	if err := os.Remove(eventsPath); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "outside.jsonl")
+	if err := os.WriteFile(outside, []byte("outside"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, eventsPath); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if _, err := store.OpenEventsRead(testJobID1); err == nil {
+		t.Fatal("expected swapped events symlink rejection")
+	}
+	if _, err := store.OpenEvents(testJobID1); err == nil {
+		t.Fatal("expected swapped events symlink rejection on append")
+	}
+}
+
 func TestStoreGetAndLogReadRejectSymlinkedJobDirectory(t *testing.T) {
 	store := NewStore(t.TempDir())
 	outside := t.TempDir()
@@ -187,6 +187,37 @@ type JobRecord struct {
 	Invocation            *IndexBuildInvocation `json:"effective_invocation,omitempty"`
 	InvocationFingerprint string                `json:"invocation_fingerprint,omitempty"`
 	Receipt               *BuildReceiptIdentity `json:"terminal_receipt,omitempty"`
+	// ScheduleID is the schedule that started the job, if any.
+	ScheduleID string `json:"schedule_id,omitempty"`
+	// Queue is the job's admission state. Jobs enqueued before the queue
+	// existed have none and were started at once.
+	Queue *JobQueueEntry `json:"queue,omitempty"`
+	// HookDeliveries audits the lifecycle events delivered to configured
+	// hooks, with the payload each received.
+	HookDeliveries []HookDelivery `json:"hook_deliveries,omitempty"`
+}
+
+// JobQueueEntry is the admission state of a queued job. A job waits in state
+// queued with AdmittedAt unset until the queue admits it under its limits; the
+// entry stays on the record afterwards so running jobs count against them.
+type JobQueueEntry struct {
+	Priority string `json:"priority"`
+	// Bucket and Endpoint are the concurrency keys derived from the manifest
+	// connection ("provider:bucket" and "provider:host" or "provider:region").
+	Bucket   string `json:"bucket,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+	// MemoryBytes is the memory the job reserves from the queue's budget.
+	MemoryBytes int64      `json:"memory_bytes,omitempty"`
+	EnqueuedAt  time.Time  `json:"enqueued_at"`
+	AdmittedAt  *time.Time `json:"admitted_at,omitempty"`
+	// TraceParent is passed to the child as TRACEPARENT when it is admitted.
+	TraceParent string `json:"traceparent,omitempty"`
+}
+
+// AwaitingAdmission reports whether the job is waiting in the queue and has
+// no process yet.
+func (r *JobRecord) AwaitingAdmission() bool {
+	return r != nil && r.State == JobStateQueued && r.Queue != nil && r.Queue.AdmittedAt == nil
 }
 
 // RecoveryIntentStalled is the durable fence label for managed stalled recovery.
@@ -178,6 +178,16 @@ type IndexCrawlBuildConfig struct {
 	// A progress record is emitted every N indexed objects.
 	// Default: 1000.
 	ProgressEvery int `json:"progress_every,omitempty" yaml:"progress_every,omitempty"`
+
+	// KeyRanges splits a single-prefix crawl into this many sampled
+	// lexicographic key ranges listed concurrently, for flat namespaces with
+	// no delimiters. Zero or one disables it.
+	KeyRanges int `json:"key_ranges,omitempty" yaml:"key_ranges,omitempty"`
+
+	// BalanceLanes weights multi-lane planning of a scoped durable build by
+	// the previous run's per-prefix object counts, so large prefixes do not
+	// share a lane. Without a prior run the plan stays round-robin.
+	BalanceLanes bool `json:"balance_lanes,omitempty" yaml:"balance_lanes,omitempty"`
 }
 
 // PathDateConfig configures to date extraction from object key paths.
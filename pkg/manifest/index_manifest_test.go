@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -21,3 +22,21 @@ connection:
 	require.Equal(t, "gs://test-bucket/base/", m.Connection.BaseURI)
 	require.Equal(t, "test-project", m.Connection.Project)
 }
+
+func TestLoadIndexManifestCrawlKeyRanges(t *testing.T) {
+	raw := `version: "1.0"
+connection:
+  provider: s3
+  bucket: test-bucket
+  base_uri: s3://test-bucket/flat/
+build:
+  crawl:
+    key_ranges: %s
+`
+	m, err := LoadIndexManifestFromBytes([]byte(strings.Replace(raw, "%s", "8", 1)), "index.yaml")
+	require.NoError(t, err)
+	require.Equal(t, 8, m.Build.Crawl.KeyRanges)
+
+	_, err = LoadIndexManifestFromBytes([]byte(strings.Replace(raw, "%s", "33", 1)), "index.yaml")
+	require.Error(t, err, "key ranges beyond the journal lane ceiling are rejected")
+}
@@ -149,6 +149,11 @@ type CrawlConfig struct {
 	// Default: 1000.
 	ProgressEvery int `json:"progress_every,omitempty" yaml:"progress_every,omitempty"`
 
+	// KeyRanges splits each listing prefix into this many sampled
+	// lexicographic key ranges, listed concurrently. Use it for flat prefixes
+	// that have no delimiters to shard on. Zero or one disables it.
+	KeyRanges int `json:"key_ranges,omitempty" yaml:"key_ranges,omitempty"`
+
 	// Preflight configures permission checks and provider probes.
 	//
 	// This is part of the plan/inspect/execute model for long-running jobs.
@@ -84,7 +84,8 @@ type DedupConfig struct {
 	Strategy string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
 }
 
-// ShardingConfig configures delimiter-based prefix sharding for enumeration.
+// ShardingConfig configures delimiter-based prefix sharding and key-range
+// splitting for enumeration.
 //
 // This allows large buckets to be enumerated in parallel without relying on a
 // single flat list stream.
@@ -94,6 +95,11 @@ type ShardingConfig struct {
 	MaxShards       int    `json:"max_shards,omitempty" yaml:"max_shards,omitempty"`
 	ListConcurrency int    `json:"list_concurrency,omitempty" yaml:"list_concurrency,omitempty"`
 	Delimiter       string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+
+	// KeyRanges splits each listing prefix into this many sampled
+	// lexicographic key ranges. It works without delimiters, so it suits flat
+	// namespaces such as hash-named keys. Zero or one disables it.
+	KeyRanges int `json:"key_ranges,omitempty" yaml:"key_ranges,omitempty"`
 }
 
 const (
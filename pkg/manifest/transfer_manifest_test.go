@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,6 +49,17 @@ func TestLoadTransferFromBytes_YAML(t *testing.T) {
 	assert.True(t, m.Output.ProgressEnabled())
 }
 
+func TestLoadTransferFromBytes_KeyRangeSharding(t *testing.T) {
+	raw := validTransferManifestYAML() + `  sharding:
+    key_ranges: 8
+`
+	raw = strings.Replace(raw, "transfer: {}\n", "transfer:\n", 1)
+	m, err := LoadTransferFromBytes([]byte(raw), "transfer.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, 8, m.Transfer.Sharding.KeyRanges)
+	assert.False(t, m.Transfer.Sharding.Enabled)
+}
+
 func TestLoadTransferFromBytes_UnknownFieldRejected(t *testing.T) {
 	bad := `version: "1.0"
 source:
@@ -349,6 +349,37 @@ func (f *CompositeFilter) String() string {
 	return strings.Join(parts, ", ")
 }
 
+// Canonical returns an exact, lossless description of the filter suitable
+// for recording and comparison. Unlike String, sizes are rendered in bytes and
+// dates as RFC 3339 instants, so two filters with the same canonical form
+// select the same objects.
+func (f *CompositeFilter) Canonical() string {
+	if f == nil || len(f.filters) == 0 {
+		return ""
+	}
+	parts := make([]string, len(f.filters))
+	for i, filter := range f.filters {
+		switch typed := filter.(type) {
+		case *SizeFilter:
+			parts[i] = fmt.Sprintf("size_min=%d;size_max=%d", typed.min, typed.max)
+		case *DateFilter:
+			parts[i] = fmt.Sprintf("modified_after=%s;modified_before=%s", canonicalFilterTime(typed.after), canonicalFilterTime(typed.before))
+		case *RegexFilter:
+			parts[i] = "key_regex=" + strconv.Quote(typed.raw)
+		default:
+			parts[i] = filter.String()
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+func canonicalFilterTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
 // Filters returns the underlying filters.
 func (f *CompositeFilter) Filters() []Filter {
 	return f.filters
@@ -531,6 +531,26 @@ func TestCompositeFilter_SingleFilter(t *testing.T) {
 	assert.False(t, composite.Match(smallObj))
 }
 
+func TestCompositeFilter_Canonical(t *testing.T) {
+	assert.Empty(t, (*CompositeFilter)(nil).Canonical())
+
+	a, err := NewFilterFromConfig(&FilterConfig{
+		Size:     &SizeFilterConfig{Min: "1KB"},
+		Modified: &DateFilterConfig{After: "2024-01-01T06:00:00Z"},
+		KeyRegex: `\.json$`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `size_min=1000;size_max=-1;modified_after=2024-01-01T06:00:00Z;modified_before=;key_regex="\\.json$"`, a.Canonical())
+
+	// String renders dates by day, so these describe alike but select different objects.
+	b, err := NewFilterFromConfig(&FilterConfig{Modified: &DateFilterConfig{After: "2024-01-01T06:00:00Z"}})
+	require.NoError(t, err)
+	c, err := NewFilterFromConfig(&FilterConfig{Modified: &DateFilterConfig{After: "2024-01-01T07:00:00Z"}})
+	require.NoError(t, err)
+	assert.Equal(t, b.String(), c.String())
+	assert.NotEqual(t, b.Canonical(), c.Canonical())
+}
+
 func TestNewFilterFromConfig(t *testing.T) {
 	t.Run("nil config", func(t *testing.T) {
 		f, err := NewFilterFromConfig(nil)
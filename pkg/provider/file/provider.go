@@ -124,11 +124,19 @@ func (p *Provider) List(ctx context.Context, opts provider.ListOptions) (*provid
 	}
 	sort.Strings(keys)
 
+	if opts.EndBefore != "" {
+		keys = keys[:sort.SearchStrings(keys, opts.EndBefore)]
+	}
+
 	start := 0
-	if opts.ContinuationToken != "" {
+	after := opts.ContinuationToken
+	if opts.StartAfter > after {
+		after = opts.StartAfter
+	}
+	if after != "" {
 		// Start strictly after the last returned key.
-		idx := sort.SearchStrings(keys, opts.ContinuationToken)
-		for idx < len(keys) && keys[idx] <= opts.ContinuationToken {
+		idx := sort.SearchStrings(keys, after)
+		for idx < len(keys) && keys[idx] <= after {
 			idx++
 		}
 		start = idx
@@ -155,6 +155,25 @@ func TestListSkipsSymlinksByDefault(t *testing.T) {
 	require.Equal(t, "nested/keep.txt", res.Objects[0].Key)
 }
 
+func TestListHonorsKeyRange(t *testing.T) {
+	ctx := context.Background()
+	baseDir := t.TempDir()
+	for _, name := range []string{"0a", "3f", "7c", "b2"} {
+		require.NoError(t, os.WriteFile(filepath.Join(baseDir, name), []byte(name), 0o600))
+	}
+	p, err := New(Config{BaseDir: baseDir})
+	require.NoError(t, err)
+
+	res, err := p.List(ctx, provider.ListOptions{StartAfter: "0a", EndBefore: "b2", MaxKeys: 1})
+	require.NoError(t, err)
+	require.Equal(t, "3f", res.Objects[0].Key)
+	require.True(t, res.IsTruncated)
+	res, err = p.List(ctx, provider.ListOptions{StartAfter: "0a", EndBefore: "b2", MaxKeys: 1, ContinuationToken: res.ContinuationToken})
+	require.NoError(t, err)
+	require.Equal(t, "7c", res.Objects[0].Key)
+	require.False(t, res.IsTruncated)
+}
+
 func TestReadMethodsRejectSymlinksByDefault(t *testing.T) {
 	ctx := context.Background()
 	baseDir := t.TempDir()
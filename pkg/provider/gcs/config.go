@@ -6,6 +6,7 @@ package gcs
 
 import (
 	"fmt"
+	"net/http"
 
 	"cloud.google.com/go/storage"
 	"golang.org/x/oauth2"
@@ -72,6 +73,11 @@ type Config struct {
 	// leaves SDK defaults.
 	MaxConnsPerHost int
 
+	// WrapTransport optionally wraps the HTTP transport every request passes
+	// through, for example to trace provider operations. Nil leaves the SDK
+	// transport unwrapped.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
 	// WriterChunkSizeBytes optionally overrides storage.Writer.ChunkSize for
 	// high-concurrency write paths. Zero keeps the SDK default; positive values
 	// are rounded by the SDK to a 256 KiB multiple; negative values are invalid.
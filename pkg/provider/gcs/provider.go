@@ -102,7 +102,7 @@ func clientOptionsForConfig(ctx context.Context, cfg Config) ([]option.ClientOpt
 }
 
 func transportTunedHTTPClient(cfg Config) *http.Client {
-	if cfg.MaxIdleConnsPerHost <= 0 && cfg.MaxConnsPerHost <= 0 {
+	if cfg.MaxIdleConnsPerHost <= 0 && cfg.MaxConnsPerHost <= 0 && cfg.WrapTransport == nil {
 		return nil
 	}
 	tr := http.DefaultTransport.(*http.Transport).Clone()
@@ -115,12 +115,21 @@ func transportTunedHTTPClient(cfg Config) *http.Client {
 	if cfg.MaxConnsPerHost > 0 {
 		tr.MaxConnsPerHost = cfg.MaxConnsPerHost
 	}
+	if cfg.WrapTransport != nil {
+		return &http.Client{Transport: cfg.WrapTransport(tr)}
+	}
 	return &http.Client{Transport: tr}
 }
 
 // List returns a page of objects with the given prefix.
 func (p *Provider) List(ctx context.Context, opts provider.ListOptions) (*provider.ListResult, error) {
-	iter := p.client.Bucket(p.bucket).Objects(ctx, &storage.Query{Prefix: opts.Prefix})
+	query := &storage.Query{Prefix: opts.Prefix, StartOffset: opts.StartAfter}
+	if !strings.ContainsRune(opts.EndBefore, 0) {
+		// A NUL-terminated bound (the successor of a key) cannot be sent as a
+		// query parameter; TrimKeyRange enforces it below instead.
+		query.EndOffset = opts.EndBefore
+	}
+	iter := p.client.Bucket(p.bucket).Objects(ctx, query)
 	pager := iterator.NewPager(iter, clampMaxKeys(opts.MaxKeys, p.maxKeys), opts.ContinuationToken)
 
 	var attrs []*storage.ObjectAttrs
@@ -134,11 +143,12 @@ func (p *Provider) List(ctx context.Context, opts provider.ListOptions) (*provid
 		objects = append(objects, objectSummaryFromAttrs(attr))
 	}
 
-	return &provider.ListResult{
+	// StartOffset is inclusive; TrimKeyRange drops the StartAfter key itself.
+	return opts.TrimKeyRange(&provider.ListResult{
 		Objects:           objects,
 		ContinuationToken: nextToken,
 		IsTruncated:       nextToken != "",
-	}, nil
+	}), nil
 }
 
 // Head returns metadata for a single object.
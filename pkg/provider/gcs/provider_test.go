@@ -121,6 +121,26 @@ func TestProviderDelimiterListingWithFakeServer(t *testing.T) {
 	require.ElementsMatch(t, []string{"root/child/", "root/other/"}, delimited.CommonPrefixes)
 }
 
+func TestProviderListKeyRangeWithFakeServer(t *testing.T) {
+	ctx := context.Background()
+	p := newFakeProvider(t, []fakestorage.Object{
+		{ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "bucket", Name: "flat/0a"}, Content: []byte("a")},
+		{ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "bucket", Name: "flat/3f"}, Content: []byte("b")},
+		{ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "bucket", Name: "flat/7c"}, Content: []byte("c")},
+		{ObjectAttrs: fakestorage.ObjectAttrs{BucketName: "bucket", Name: "flat/b2"}, Content: []byte("d")},
+	})
+
+	res, err := p.List(ctx, provider.ListOptions{Prefix: "flat/", StartAfter: "flat/0a", EndBefore: "flat/b2"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"flat/3f", "flat/7c"}, summaryKeys(res.Objects))
+
+	// A NUL-terminated bound includes the key it extends.
+	res, err = p.List(ctx, provider.ListOptions{Prefix: "flat/", StartAfter: "flat/3f", EndBefore: "flat/b2\x00"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"flat/7c", "flat/b2"}, summaryKeys(res.Objects))
+	require.False(t, res.IsTruncated)
+}
+
 func TestProviderReadOperationsMapNotFound(t *testing.T) {
 	ctx := context.Background()
 	p := newFakeProvider(t, []fakestorage.Object{
@@ -300,6 +320,19 @@ func TestTransportTunedHTTPClientUsesClonedTransport(t *testing.T) {
 	require.NotSame(t, http.DefaultTransport, tr)
 }
 
+func TestTransportTunedHTTPClientAppliesWrapTransport(t *testing.T) {
+	var wrapped http.RoundTripper
+	client := transportTunedHTTPClient(Config{WrapTransport: func(next http.RoundTripper) http.RoundTripper {
+		wrapped = next
+		return http.NewFileTransport(http.Dir(t.TempDir()))
+	}})
+	require.NotNil(t, client)
+	_, ok := wrapped.(*http.Transport)
+	require.True(t, ok, "the cloned transport is the wrapped base")
+	_, ok = client.Transport.(*http.Transport)
+	require.False(t, ok, "the client uses the wrapper")
+}
+
 func TestClientOptionsForConfigKeepsUntunedADCOnSDKDefaultPath(t *testing.T) {
 	old := adcTokenSource
 	t.Cleanup(func() { adcTokenSource = old })
@@ -45,6 +45,53 @@ type ListOptions struct {
 	// MaxKeys limits the number of objects returned per page.
 	// Zero uses provider default (typically 1000).
 	MaxKeys int
+
+	// StartAfter, when set, returns only keys that sort strictly after it.
+	// It maps to S3 StartAfter and to GCS StartOffset (made exclusive).
+	StartAfter string
+
+	// EndBefore, when set, returns only keys that sort strictly before it, and
+	// ends the listing (empty ContinuationToken, IsTruncated false) once a key
+	// at or past it is reached. It maps to GCS EndOffset; S3 has no upper bound
+	// and is trimmed client-side. Keep the same StartAfter and EndBefore on every
+	// page of one listing.
+	//
+	// Ordering is byte-wise (Go string comparison), which is the order S3 and
+	// GCS list in.
+	EndBefore string
+}
+
+// InKeyRange reports whether key lies within StartAfter and EndBefore.
+func (o ListOptions) InKeyRange(key string) bool {
+	return (o.StartAfter == "" || key > o.StartAfter) && !o.PastKeyRange(key)
+}
+
+// PastKeyRange reports whether key sorts at or after EndBefore, meaning a
+// listing in key order has left the requested range.
+func (o ListOptions) PastKeyRange(key string) bool {
+	return o.EndBefore != "" && key >= o.EndBefore
+}
+
+// TrimKeyRange drops objects outside StartAfter and EndBefore from a page
+// listed in key order. Once a key past EndBefore appears the listing is over,
+// so the result is marked complete.
+func (o ListOptions) TrimKeyRange(res *ListResult) *ListResult {
+	if res == nil || (o.StartAfter == "" && o.EndBefore == "") {
+		return res
+	}
+	kept := res.Objects[:0]
+	for _, obj := range res.Objects {
+		if o.PastKeyRange(obj.Key) {
+			res.IsTruncated = false
+			res.ContinuationToken = ""
+			break
+		}
+		if o.InKeyRange(obj.Key) {
+			kept = append(kept, obj)
+		}
+	}
+	res.Objects = kept
+	return res
 }
 
 // ListResult contains a page of objects from a List operation.
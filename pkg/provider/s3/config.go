@@ -2,6 +2,7 @@ package s3
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 )
@@ -91,6 +92,11 @@ type Config struct {
 	// MaxConnsPerHost optionally caps total HTTP connections per host. Zero
 	// leaves SDK defaults.
 	MaxConnsPerHost int
+
+	// WrapTransport optionally wraps the HTTP transport every request passes
+	// through, for example to trace provider operations. Nil leaves the SDK
+	// transport unwrapped.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 // DefaultMaxKeys is the default page size for List operations.
@@ -138,10 +138,10 @@ func loadAWSConfig(ctx context.Context, cfg Config) (aws.Config, error) {
 }
 
 func transportTunedHTTPClient(cfg Config) aws.HTTPClient {
-	if cfg.MaxIdleConnsPerHost <= 0 && cfg.MaxConnsPerHost <= 0 {
+	if cfg.MaxIdleConnsPerHost <= 0 && cfg.MaxConnsPerHost <= 0 && cfg.WrapTransport == nil {
 		return nil
 	}
-	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+	client := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
 		if cfg.MaxIdleConnsPerHost > 0 {
 			tr.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
 			if tr.MaxIdleConns < cfg.MaxIdleConnsPerHost {
@@ -152,6 +152,10 @@ func transportTunedHTTPClient(cfg Config) aws.HTTPClient {
 			tr.MaxConnsPerHost = cfg.MaxConnsPerHost
 		}
 	})
+	if cfg.WrapTransport == nil {
+		return client
+	}
+	return &http.Client{Transport: cfg.WrapTransport(client.GetTransport()), Timeout: client.GetTimeout()}
 }
 
 // List returns a page of objects with the given prefix.
@@ -171,6 +175,10 @@ func (p *Provider) List(ctx context.Context, opts provider.ListOptions) (*provid
 		input.ContinuationToken = aws.String(opts.ContinuationToken)
 	}
 
+	if opts.StartAfter != "" {
+		input.StartAfter = aws.String(opts.StartAfter)
+	}
+
 	output, err := p.client.ListObjectsV2(ctx, input)
 	if err != nil {
 		return nil, p.wrapError("List", "", err)
@@ -196,7 +204,8 @@ func (p *Provider) List(ctx context.Context, opts provider.ListOptions) (*provid
 		result.ContinuationToken = *output.NextContinuationToken
 	}
 
-	return result, nil
+	// ListObjectsV2 has no upper bound; EndBefore is applied to the page.
+	return opts.TrimKeyRange(result), nil
 }
 
 func (p *Provider) ListWithDelimiter(ctx context.Context, opts provider.ListWithDelimiterOptions) (*provider.ListWithDelimiterResult, error) {
@@ -215,6 +215,24 @@ func TestTransportTunedHTTPClientUsesClonedTransport(t *testing.T) {
 	require.NotEqual(t, 32, defaultTr.MaxIdleConnsPerHost)
 }
 
+func TestTransportTunedHTTPClientAppliesWrapTransport(t *testing.T) {
+	var wrapped http.RoundTripper
+	client := transportTunedHTTPClient(Config{WrapTransport: func(next http.RoundTripper) http.RoundTripper {
+		wrapped = next
+		return roundTripFunc(next.RoundTrip)
+	}})
+	require.NotNil(t, client)
+	httpClient, ok := client.(*http.Client)
+	require.True(t, ok)
+	require.IsType(t, roundTripFunc(nil), httpClient.Transport)
+	_, ok = wrapped.(*http.Transport)
+	require.True(t, ok, "the SDK transport is the wrapped base")
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
 func TestNewValidationErrorDoesNotLeakExplicitCredentials(t *testing.T) {
 	const (
 		accessKey = "AKIAERROR000000001"
@@ -480,6 +498,47 @@ func TestProviderListCapturesStorageClass(t *testing.T) {
 	require.Empty(t, delimited.Objects[1].StorageClass)
 }
 
+func TestProviderListKeyRange(t *testing.T) {
+	ctx := context.Background()
+	var gotStartAfter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotStartAfter = r.URL.Query().Get("start-after")
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>test-bucket</Name>
+  <KeyCount>3</KeyCount>
+  <MaxKeys>1000</MaxKeys>
+  <IsTruncated>true</IsTruncated>
+  <NextContinuationToken>next</NextContinuationToken>
+  <Contents><Key>flat/3f</Key><Size>1</Size></Contents>
+  <Contents><Key>flat/7c</Key><Size>1</Size></Contents>
+  <Contents><Key>flat/b2</Key><Size>1</Size></Contents>
+</ListBucketResult>`))
+	}))
+	defer server.Close()
+
+	p, err := New(ctx, Config{
+		Bucket:          "test-bucket",
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIATEST0000000001",
+		SecretAccessKey: "test-secret",
+		ForcePathStyle:  true,
+	})
+	require.NoError(t, err)
+	defer func() { _ = p.Close() }()
+
+	result, err := p.List(ctx, provider.ListOptions{Prefix: "flat/", StartAfter: "flat/0a", EndBefore: "flat/b2"})
+	require.NoError(t, err)
+	require.Equal(t, "flat/0a", gotStartAfter)
+	require.Len(t, result.Objects, 2)
+	require.Equal(t, "flat/7c", result.Objects[1].Key)
+	// Reaching EndBefore ends the listing even though S3 reported more pages.
+	require.False(t, result.IsTruncated)
+	require.Empty(t, result.ContinuationToken)
+}
+
 func TestObjectMeta_Embedding(t *testing.T) {
 	now := time.Now()
 	meta := provider.ObjectMeta{
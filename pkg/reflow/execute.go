@@ -9,6 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/3leaps/gonimbus/internal/reflowprobe"
 	"github.com/3leaps/gonimbus/pkg/match"
 	"github.com/3leaps/gonimbus/pkg/partition"
@@ -785,7 +788,13 @@ func (r *Runner) drivePlannedRecords(ctx context.Context, layout DestLayout, sta
 				if poolCtx.Err() != nil {
 					continue // drain remaining tasks after cancellation
 				}
-				if err := r.copyAndEmit(poolCtx, task.src, layout, stats, stages, capability, limiter, arbiter, task.in, task.destRel, task.destKey, task.destURI); err != nil {
+				objCtx, span := tracer.Start(poolCtx, "reflow.object", trace.WithAttributes(
+					attribute.Int64("gonimbus.reflow.source_size", task.in.SourceSize),
+					attribute.String("gonimbus.reflow.routing_class", task.in.RoutingClass),
+				))
+				err := r.copyAndEmit(objCtx, task.src, layout, stats, stages, capability, limiter, arbiter, task.in, task.destRel, task.destKey, task.destURI)
+				endSpan(span, err)
+				if err != nil {
 					recordFatal(err)
 				}
 			}
@@ -807,7 +816,9 @@ func (r *Runner) drivePlannedRecords(ctx context.Context, layout DestLayout, sta
 	// cancelPool) or a parent-context cancellation interrupts context-aware
 	// producer enumeration and I/O promptly, not only at the next stopped() poll.
 	// A producer-returned error does not cancel the pool, so admitted work drains.
-	producerErr := producer(poolCtx, deps)
+	enumCtx, enumSpan := tracer.Start(poolCtx, "reflow.enumerate")
+	producerErr := producer(enumCtx, deps)
+	endSpan(enumSpan, producerErr)
 	close(tasks)
 	wg.Wait()
 
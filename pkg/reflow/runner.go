@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrNotImplemented is returned by Runner.Run for source forms or scenarios that
@@ -59,7 +62,15 @@ func (r *Runner) Run(ctx context.Context, src Source) (Summary, error) {
 	if src == nil {
 		return Summary{}, errors.New("reflow: source is required")
 	}
-	return r.run(ctx, src)
+	ctx, span := tracer.Start(ctx, "reflow.run", trace.WithAttributes(
+		attribute.String("gonimbus.reflow.source_form", sourceForm(src)),
+		attribute.Bool("gonimbus.reflow.dry_run", r.cfg.DryRun),
+		attribute.Int("gonimbus.reflow.parallel_ceiling", r.cfg.Concurrency.EffectiveCeiling),
+	))
+	summary, err := r.run(ctx, src)
+	span.SetAttributes(attribute.Int64("gonimbus.reflow.errors", summary.Errors))
+	endSpan(span, err)
+	return summary, err
 }
 
 // Summary is the typed result of a reflow Run, mirroring the
@@ -0,0 +1,36 @@
+package reflow
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer resolves through the global TracerProvider, so engine spans are no-ops
+// unless the embedding process installs one. Spans carry counts, sizes, and
+// source forms only — never keys, URIs, or bucket names.
+var tracer = otel.Tracer("github.com/3leaps/gonimbus/pkg/reflow")
+
+// endSpan marks span failed without echoing err, whose message may name keys.
+// ErrNotImplemented is a command-layer fallback, not a failure.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNotImplemented) {
+		span.SetStatus(codes.Error, reflowErrCode(err))
+	}
+	span.End()
+}
+
+func sourceForm(src Source) string {
+	switch src.(type) {
+	case RecordStreamSource:
+		return "record_stream"
+	case ObjectSource:
+		return "object"
+	case PrefixSource:
+		return "prefix"
+	default:
+		return "other"
+	}
+}
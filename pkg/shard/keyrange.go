@@ -0,0 +1,401 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// KeyRange is a lexicographic slice of the keys under Prefix: keys that sort
+// strictly after StartAfter and strictly before EndBefore. Empty bounds are
+// open.
+//
+// Ranges produced by SplitKeyRange tile the prefix exactly: each range ends at
+// boundary+"\x00" and the next starts after boundary, so every key belongs to
+// exactly one range.
+type KeyRange struct {
+	Prefix     string `json:"prefix"`
+	StartAfter string `json:"start_after,omitempty"`
+	EndBefore  string `json:"end_before,omitempty"`
+
+	// EstimatedObjects is the splitter's object count estimate for the range.
+	// Zero means unknown.
+	EstimatedObjects int64 `json:"estimated_objects,omitempty"`
+}
+
+// ListOptions returns list options scoped to the range.
+func (r KeyRange) ListOptions() provider.ListOptions {
+	return provider.ListOptions{Prefix: r.Prefix, StartAfter: r.StartAfter, EndBefore: r.EndBefore}
+}
+
+// Contains reports whether key falls within the range.
+func (r KeyRange) Contains(key string) bool {
+	return strings.HasPrefix(key, r.Prefix) && r.ListOptions().InKeyRange(key)
+}
+
+// String renders the range as prefix(start_after, end_before) for logs and
+// progress records.
+func (r KeyRange) String() string {
+	return fmt.Sprintf("%s(%q,%q)", r.Prefix, r.StartAfter, r.EndBefore)
+}
+
+// ValidateKeyRanges checks that ranges share one prefix and tile it in order
+// with no gaps or overlaps.
+func ValidateKeyRanges(ranges []KeyRange) error {
+	if len(ranges) == 0 {
+		return fmt.Errorf("key ranges are empty")
+	}
+	if ranges[0].StartAfter != "" {
+		return fmt.Errorf("first key range must start at the beginning of the prefix")
+	}
+	if ranges[len(ranges)-1].EndBefore != "" {
+		return fmt.Errorf("last key range must run to the end of the prefix")
+	}
+	for i := 1; i < len(ranges); i++ {
+		prev, cur := ranges[i-1], ranges[i]
+		if cur.Prefix != prev.Prefix {
+			return fmt.Errorf("key range %d prefix %q differs from %q", i, cur.Prefix, prev.Prefix)
+		}
+		if cur.StartAfter == "" || prev.EndBefore != cur.StartAfter+"\x00" {
+			return fmt.Errorf("key range %d does not continue where range %d ends", i, i-1)
+		}
+	}
+	return nil
+}
+
+// RangeConfig controls SplitKeyRange.
+type RangeConfig struct {
+	// Ranges is the number of ranges to produce. Values below 2 return the
+	// whole prefix as one range.
+	Ranges int
+
+	// Probes is the number of sample listings spread across the keyspace.
+	// Zero uses DefaultRangeProbes.
+	Probes int
+
+	// PageSize is the MaxKeys used per probe. Zero uses DefaultRangePageSize.
+	PageSize int
+
+	// ListConcurrency bounds concurrent probe listings.
+	ListConcurrency int
+}
+
+const (
+	DefaultRangeProbes   = 64
+	DefaultRangePageSize = 1000
+
+	// rangeKeyDigits bounds how many key bytes feed the position estimate.
+	rangeKeyDigits = 8
+)
+
+// SplitKeyRange splits the keys under prefix into balanced lexicographic
+// ranges without listing them all.
+//
+// It lists one page at the start of the prefix; if that page holds every key
+// the split is exact. Otherwise it learns the key alphabet from that page,
+// probes Probes evenly spaced points of the keyspace with one StartAfter page
+// each, and estimates the object density between probes. Boundaries fall on
+// sampled keys where the samples reach them and on interpolated keys
+// elsewhere, so the result is balanced by estimate, not guaranteed.
+//
+// The provider must honor ListOptions.StartAfter.
+func SplitKeyRange(ctx context.Context, p provider.Provider, prefix string, cfg RangeConfig) ([]KeyRange, error) {
+	whole := []KeyRange{{Prefix: prefix}}
+	if cfg.Ranges < 2 {
+		return whole, nil
+	}
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultRangePageSize
+	}
+	probes := cfg.Probes
+	if probes <= 0 {
+		probes = DefaultRangeProbes
+	}
+	if probes < cfg.Ranges {
+		probes = cfg.Ranges
+	}
+
+	first, err := probeKeys(ctx, p, prefix, "", pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if !first.truncated {
+		return splitExact(prefix, first.keys, cfg.Ranges), nil
+	}
+
+	space := newKeySpace(prefix, first.keys)
+	starts := []string{""}
+	for j := 1; j < probes; j++ {
+		s := space.key(float64(j) / float64(probes))
+		if s > starts[len(starts)-1] && s > first.keys[0] {
+			starts = append(starts, s)
+		}
+	}
+
+	pages := make([]probePage, len(starts))
+	pages[0] = first
+	if err := runProbes(ctx, p, prefix, starts, pages, pageSize, cfg.ListConcurrency); err != nil {
+		return nil, err
+	}
+
+	intervals := make([]keyInterval, len(starts))
+	var total float64
+	for j := range starts {
+		hi := 1.0
+		next := ""
+		if j+1 < len(starts) {
+			next = starts[j+1]
+			hi = space.pos(next)
+		}
+		intervals[j] = space.interval(starts[j], next, hi, pages[j])
+		total += intervals[j].count
+	}
+
+	boundaries := make([]string, 0, cfg.Ranges-1)
+	counts := make([]int64, 0, cfg.Ranges)
+	var cum, prevTarget float64
+	j := 0
+	for i := 1; i < cfg.Ranges; i++ {
+		target := total * float64(i) / float64(cfg.Ranges)
+		for j < len(intervals)-1 && cum+intervals[j].count < target {
+			cum += intervals[j].count
+			j++
+		}
+		b := intervals[j].boundary(space, target-cum)
+		if b == "" || (len(boundaries) > 0 && b <= boundaries[len(boundaries)-1]) {
+			continue
+		}
+		boundaries = append(boundaries, b)
+		counts = append(counts, int64(math.Round(target-prevTarget)))
+		prevTarget = target
+	}
+	counts = append(counts, int64(math.Round(total-prevTarget)))
+	return rangesFromBoundaries(prefix, boundaries, counts), nil
+}
+
+func splitExact(prefix string, keys []string, n int) []KeyRange {
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+	if n > len(keys) {
+		n = len(keys)
+	}
+	if n < 2 {
+		return []KeyRange{{Prefix: prefix, EstimatedObjects: int64(len(keys))}}
+	}
+	boundaries := make([]string, 0, n-1)
+	counts := make([]int64, 0, n)
+	prev := 0
+	for i := 1; i < n; i++ {
+		idx := i * len(keys) / n
+		boundaries = append(boundaries, keys[idx-1])
+		counts = append(counts, int64(idx-prev))
+		prev = idx
+	}
+	counts = append(counts, int64(len(keys)-prev))
+	return rangesFromBoundaries(prefix, boundaries, counts)
+}
+
+// rangesFromBoundaries builds ranges ending inclusively at each boundary.
+func rangesFromBoundaries(prefix string, boundaries []string, counts []int64) []KeyRange {
+	out := make([]KeyRange, 0, len(boundaries)+1)
+	start := ""
+	for i, b := range boundaries {
+		out = append(out, KeyRange{Prefix: prefix, StartAfter: start, EndBefore: b + "\x00", EstimatedObjects: counts[i]})
+		start = b
+	}
+	return append(out, KeyRange{Prefix: prefix, StartAfter: start, EstimatedObjects: counts[len(counts)-1]})
+}
+
+type probePage struct {
+	keys      []string
+	truncated bool
+}
+
+func probeKeys(ctx context.Context, p provider.Provider, prefix, startAfter string, pageSize int) (probePage, error) {
+	res, err := p.List(ctx, provider.ListOptions{Prefix: prefix, StartAfter: startAfter, MaxKeys: pageSize})
+	if err != nil {
+		return probePage{}, err
+	}
+	page := probePage{keys: make([]string, 0, len(res.Objects)), truncated: res.IsTruncated && res.ContinuationToken != ""}
+	for _, obj := range res.Objects {
+		if startAfter != "" && obj.Key <= startAfter {
+			return probePage{}, fmt.Errorf("provider does not support key range listing: key %q returned after %q", obj.Key, startAfter)
+		}
+		page.keys = append(page.keys, obj.Key)
+	}
+	return page, nil
+}
+
+func runProbes(ctx context.Context, p provider.Provider, prefix string, starts []string, pages []probePage, pageSize, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := make(chan int, len(starts))
+	for j := 1; j < len(starts); j++ {
+		work <- j
+	}
+	close(work)
+
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range work {
+				if ctx.Err() != nil {
+					return
+				}
+				page, err := probeKeys(ctx, p, prefix, starts[j], pageSize)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+				pages[j] = page
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// keySpace maps key suffixes onto [0, 1) in key order so probe points can be
+// spaced evenly and densities interpolated. Digits come from the byte
+// alphabet seen in sampled keys; bytes outside it map between neighbours.
+type keySpace struct {
+	prefix   string
+	alphabet []byte
+	base     float64
+	digits   int
+}
+
+func newKeySpace(prefix string, sample []string) *keySpace {
+	var seen [256]bool
+	for _, k := range sample {
+		for i := len(prefix); i < len(k); i++ {
+			seen[k[i]] = true
+		}
+	}
+	var alphabet []byte
+	for b := 0; b < 256; b++ {
+		if seen[b] {
+			alphabet = append(alphabet, byte(b))
+		}
+	}
+	if len(alphabet) < 2 {
+		alphabet = alphabet[:0]
+		for b := byte(0x20); b < 0x7f; b++ {
+			alphabet = append(alphabet, b)
+		}
+	}
+	return &keySpace{prefix: prefix, alphabet: alphabet, base: float64(len(alphabet)), digits: rangeKeyDigits}
+}
+
+// digit maps alphabet[i] to i, and a byte sorting just before alphabet[i] to
+// i-0.5 (clamped at zero).
+func (s *keySpace) digit(b byte) float64 {
+	i := sort.Search(len(s.alphabet), func(i int) bool { return s.alphabet[i] >= b })
+	if i < len(s.alphabet) && s.alphabet[i] == b {
+		return float64(i)
+	}
+	return math.Max(float64(i)-0.5, 0)
+}
+
+func (s *keySpace) pos(key string) float64 {
+	suffix := strings.TrimPrefix(key, s.prefix)
+	var x float64
+	scale := 1.0
+	for i := 0; i < len(suffix) && i < s.digits; i++ {
+		scale /= s.base
+		x += s.digit(suffix[i]) * scale
+	}
+	return x
+}
+
+// key returns a key whose position is at or just above x.
+func (s *keySpace) key(x float64) string {
+	var sb strings.Builder
+	sb.WriteString(s.prefix)
+	for i := 0; i < s.digits && x > 0; i++ {
+		x *= s.base
+		d := math.Floor(x)
+		x -= d
+		idx := int(d)
+		if idx >= len(s.alphabet) {
+			idx = len(s.alphabet) - 1
+		}
+		sb.WriteByte(s.alphabet[idx])
+	}
+	return sb.String()
+}
+
+// keyInterval is the estimated key population in (start, next].
+type keyInterval struct {
+	keys    []string
+	exact   bool
+	count   float64
+	lastPos float64
+	density float64
+}
+
+func (s *keySpace) interval(start, next string, hi float64, page probePage) keyInterval {
+	iv := keyInterval{}
+	for _, k := range page.keys {
+		if next != "" && k > next {
+			iv.exact = true
+			break
+		}
+		iv.keys = append(iv.keys, k)
+	}
+	if !page.truncated {
+		iv.exact = true
+	}
+	iv.count = float64(len(iv.keys))
+	if iv.exact || len(iv.keys) == 0 {
+		return iv
+	}
+	lo := s.pos(start)
+	iv.lastPos = s.pos(iv.keys[len(iv.keys)-1])
+	span := iv.lastPos - lo
+	if span <= 0 || hi <= iv.lastPos {
+		return iv
+	}
+	iv.density = iv.count / span
+	iv.count += iv.density * (hi - iv.lastPos)
+	return iv
+}
+
+// boundary returns the key at offset objects into the interval: a sampled key
+// when the samples reach that far, otherwise an interpolated key.
+func (iv keyInterval) boundary(s *keySpace, offset float64) string {
+	idx := int(math.Ceil(offset)) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx < len(iv.keys) {
+		return iv.keys[idx]
+	}
+	if iv.density <= 0 {
+		if len(iv.keys) == 0 {
+			return ""
+		}
+		return iv.keys[len(iv.keys)-1]
+	}
+	return s.key(iv.lastPos + (offset-float64(len(iv.keys)))/iv.density)
+}
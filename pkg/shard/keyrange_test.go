@@ -0,0 +1,145 @@
+package shard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/provider"
+)
+
+// flatProvider lists sorted in-memory keys and honors StartAfter/EndBefore.
+type flatProvider struct {
+	keys       []string
+	ignoreFrom bool
+	lists      atomic.Int64
+}
+
+func (f *flatProvider) List(_ context.Context, opts provider.ListOptions) (*provider.ListResult, error) {
+	f.lists.Add(1)
+	start := 0
+	if opts.StartAfter != "" && !f.ignoreFrom {
+		start = sort.Search(len(f.keys), func(i int) bool { return f.keys[i] > opts.StartAfter })
+	}
+	if opts.ContinuationToken != "" {
+		_, _ = fmt.Sscanf(opts.ContinuationToken, "%d", &start)
+	}
+	res := &provider.ListResult{}
+	for i := start; i < len(f.keys); i++ {
+		if !strings.HasPrefix(f.keys[i], opts.Prefix) {
+			continue
+		}
+		if opts.MaxKeys > 0 && len(res.Objects) == opts.MaxKeys {
+			res.IsTruncated = true
+			res.ContinuationToken = fmt.Sprint(i)
+			break
+		}
+		res.Objects = append(res.Objects, provider.ObjectSummary{Key: f.keys[i]})
+	}
+	if f.ignoreFrom {
+		return res, nil
+	}
+	return opts.TrimKeyRange(res), nil
+}
+
+func (f *flatProvider) Head(_ context.Context, _ string) (*provider.ObjectMeta, error) {
+	panic("not used")
+}
+
+func (f *flatProvider) Close() error { return nil }
+
+func hashKeys(prefix string, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		sum := sha256.Sum256([]byte(fmt.Sprint(i)))
+		keys[i] = prefix + hex.EncodeToString(sum[:16])
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// listRange lists every key in r, following continuation tokens.
+func listRange(t *testing.T, p provider.Provider, r KeyRange) []string {
+	t.Helper()
+	var out []string
+	opts := r.ListOptions()
+	opts.MaxKeys = 500
+	for {
+		res, err := p.List(context.Background(), opts)
+		require.NoError(t, err)
+		for _, obj := range res.Objects {
+			out = append(out, obj.Key)
+		}
+		if !res.IsTruncated {
+			return out
+		}
+		opts.ContinuationToken = res.ContinuationToken
+	}
+}
+
+func TestSplitKeyRange_HashNamedKeysAreBalanced(t *testing.T) {
+	keys := hashKeys("flat/", 20000)
+	p := &flatProvider{keys: keys}
+
+	ranges, err := SplitKeyRange(context.Background(), p, "flat/", RangeConfig{Ranges: 8, PageSize: 100, ListConcurrency: 4})
+	require.NoError(t, err)
+	require.Len(t, ranges, 8)
+	require.NoError(t, ValidateKeyRanges(ranges))
+	require.Equal(t, int64(DefaultRangeProbes), p.lists.Load(), "one page per probe")
+
+	var covered []string
+	for _, r := range ranges {
+		got := listRange(t, p, r)
+		require.InDelta(t, 2500, len(got), 1000, "range %s", r)
+		require.NotZero(t, r.EstimatedObjects)
+		for _, k := range got {
+			require.True(t, r.Contains(k))
+		}
+		covered = append(covered, got...)
+	}
+	require.Equal(t, keys, covered, "ranges tile the prefix exactly")
+}
+
+func TestSplitKeyRange_ExactWhenPrefixFitsOnePage(t *testing.T) {
+	keys := []string{"p/a", "p/b", "p/c", "p/d", "p/e"}
+	p := &flatProvider{keys: keys}
+
+	ranges, err := SplitKeyRange(context.Background(), p, "p/", RangeConfig{Ranges: 2})
+	require.NoError(t, err)
+	require.Equal(t, []KeyRange{
+		{Prefix: "p/", EndBefore: "p/b\x00", EstimatedObjects: 2},
+		{Prefix: "p/", StartAfter: "p/b", EstimatedObjects: 3},
+	}, ranges)
+	require.Equal(t, []string{"p/a", "p/b"}, listRange(t, p, ranges[0]))
+
+	ranges, err = SplitKeyRange(context.Background(), p, "p/", RangeConfig{Ranges: 10})
+	require.NoError(t, err)
+	require.Len(t, ranges, 5)
+
+	ranges, err = SplitKeyRange(context.Background(), p, "p/", RangeConfig{Ranges: 1})
+	require.NoError(t, err)
+	require.Equal(t, []KeyRange{{Prefix: "p/"}}, ranges)
+}
+
+func TestSplitKeyRange_RejectsProviderIgnoringStartAfter(t *testing.T) {
+	p := &flatProvider{keys: hashKeys("", 500), ignoreFrom: true}
+	_, err := SplitKeyRange(context.Background(), p, "", RangeConfig{Ranges: 4, PageSize: 10})
+	require.ErrorContains(t, err, "does not support key range listing")
+}
+
+func TestValidateKeyRanges(t *testing.T) {
+	require.NoError(t, ValidateKeyRanges([]KeyRange{{Prefix: "p/"}}))
+	require.Error(t, ValidateKeyRanges(nil))
+	require.Error(t, ValidateKeyRanges([]KeyRange{{Prefix: "p/", EndBefore: "p/m\x00"}}))
+	require.Error(t, ValidateKeyRanges([]KeyRange{
+		{Prefix: "p/", EndBefore: "p/m\x00"},
+		{Prefix: "p/", StartAfter: "p/n"},
+	}))
+}
@@ -22,6 +22,8 @@ type Open struct {
 	Range *ByteRange `json:"range,omitempty"`
 }
 
+// ByteRange is the span of an object a stream carries. End is inclusive; an
+// empty range (a suffix of a zero-byte object) has End = Start - 1.
 type ByteRange struct {
 	Start int64 `json:"start"`
 	End   int64 `json:"end"`
@@ -44,6 +44,13 @@ func (sw *Writer) WriteClose(ctx context.Context, closeRec *Close) error {
 	return sw.writeJSON(ctx, TypeStreamClose, closeRec)
 }
 
+// WriteError emits a gonimbus.error.v1 record under the same lock as the
+// stream records, so an error from one stream never lands between another
+// stream's chunk header and its bytes.
+func (sw *Writer) WriteError(ctx context.Context, errRec *output.ErrorRecord) error {
+	return sw.writeJSON(ctx, output.TypeError, errRec)
+}
+
 // WriteChunk writes a chunk header record and then copies exactly hdr.NBytes
 // bytes from body.
 //
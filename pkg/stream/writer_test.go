@@ -93,3 +93,18 @@ func TestWriter_ClosePreventsWrites(t *testing.T) {
 	require.NoError(t, sw.Close())
 	require.ErrorIs(t, sw.WriteOpen(context.Background(), &Open{StreamID: "s1", URI: "s3://b/k"}), output.ErrWriterClosed)
 }
+
+func TestWriter_WriteErrorIsInBandRecord(t *testing.T) {
+	var buf bytes.Buffer
+	sw := NewWriter(&buf, "job-1", "s3")
+	require.NoError(t, sw.WriteError(context.Background(), &output.ErrorRecord{Code: output.ErrCodeNotFound, Message: "gone", Key: "k"}))
+
+	ev, err := NewDecoder(&buf).Next()
+	require.NoError(t, err)
+	require.Equal(t, EventRecord, ev.Kind)
+	require.Equal(t, output.TypeError, ev.Record.Type)
+	var rec output.ErrorRecord
+	require.NoError(t, json.Unmarshal(ev.Record.Data, &rec))
+	require.Equal(t, output.ErrCodeNotFound, rec.Code)
+	require.Equal(t, "k", rec.Key)
+}
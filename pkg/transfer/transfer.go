@@ -38,6 +38,11 @@ type ShardingConfig struct {
 	MaxShards       int
 	ListConcurrency int
 	Delimiter       string
+
+	// KeyRanges, when > 1, splits each listing prefix (after delimiter
+	// discovery, if enabled) into this many lexicographic key ranges using
+	// shard.SplitKeyRange. Use it for flat namespaces with no delimiters.
+	KeyRanges int
 }
 
 type DedupConfig struct {
@@ -138,14 +143,14 @@ func (t *Transfer) Run(ctx context.Context) (*Summary, error) {
 	errCh := make(chan error, 1)
 
 	// Listing stage: bounded prefix enumeration.
-	prefixCh := make(chan string, 1024)
+	prefixCh := make(chan shard.KeyRange, 1024)
 	var listWg sync.WaitGroup
 	for i := 0; i < t.cfg.Sharding.ListConcurrency; i++ {
 		listWg.Add(1)
 		go func() {
 			defer listWg.Done()
-			for prefix := range prefixCh {
-				if err := t.listPrefix(ctx, prefix, listCh); err != nil {
+			for unit := range prefixCh {
+				if err := t.listPrefix(ctx, unit, listCh); err != nil {
 					select {
 					case errCh <- err:
 					default:
@@ -160,7 +165,9 @@ func (t *Transfer) Run(ctx context.Context) (*Summary, error) {
 		defer close(prefixCh)
 		for _, pfx := range prefixes {
 			for _, shardPrefix := range t.expandShardPrefixes(ctx, pfx) {
-				prefixCh <- shardPrefix
+				for _, unit := range t.splitKeyRanges(ctx, shardPrefix) {
+					prefixCh <- unit
+				}
 			}
 		}
 	}()
@@ -241,21 +248,37 @@ func (t *Transfer) expandShardPrefixes(ctx context.Context, basePrefix string) [
 	return shards
 }
 
-func (t *Transfer) listPrefix(ctx context.Context, prefix string, out chan<- objectItem) error {
-	var token string
+// splitKeyRanges splits a shard prefix into key ranges when configured.
+func (t *Transfer) splitKeyRanges(ctx context.Context, prefix string) []shard.KeyRange {
+	if t.cfg.Sharding.KeyRanges < 2 {
+		return []shard.KeyRange{{Prefix: prefix}}
+	}
+	ranges, err := shard.SplitKeyRange(ctx, t.src, prefix, shard.RangeConfig{
+		Ranges:          t.cfg.Sharding.KeyRanges,
+		ListConcurrency: t.cfg.Sharding.ListConcurrency,
+	})
+	if err != nil {
+		// Best-effort, like delimiter discovery: list the prefix whole.
+		return []shard.KeyRange{{Prefix: prefix}}
+	}
+	return ranges
+}
+
+func (t *Transfer) listPrefix(ctx context.Context, unit shard.KeyRange, out chan<- objectItem) error {
+	opts := unit.ListOptions()
 	for {
-		res, err := t.src.List(ctx, provider.ListOptions{Prefix: prefix, ContinuationToken: token})
+		res, err := t.src.List(ctx, opts)
 		if err != nil {
 			return err
 		}
-		for _, obj := range res.Objects {
+		for _, obj := range opts.TrimKeyRange(res).Objects {
 			t.listed.Add(1)
-			out <- objectItem{summary: obj, prefix: prefix}
+			out <- objectItem{summary: obj, prefix: unit.Prefix}
 		}
 		if !res.IsTruncated || res.ContinuationToken == "" {
 			return nil
 		}
-		token = res.ContinuationToken
+		opts.ContinuationToken = res.ContinuationToken
 	}
 }
 
@@ -0,0 +1,43 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/3leaps/gonimbus/pkg/match"
+	"github.com/3leaps/gonimbus/pkg/output"
+	"github.com/3leaps/gonimbus/pkg/provider/file"
+)
+
+func TestTransferRunKeyRangesCopyEachObjectOnce(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	for i := 0; i < 40; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(srcDir, fmt.Sprintf("%02x", i*6)), []byte{byte(i)}, 0o600))
+	}
+	src, err := file.New(file.Config{BaseDir: srcDir})
+	require.NoError(t, err)
+	dst, err := file.New(file.Config{BaseDir: dstDir})
+	require.NoError(t, err)
+	matcher, err := match.New(match.Config{Includes: []string{"**"}})
+	require.NoError(t, err)
+
+	tfer := New(src, dst, matcher, output.NewJSONLWriter(io.Discard, "job-test", "file"), "job-test", Config{
+		Concurrency: 2,
+		Dedup:       DedupConfig{Strategy: "none"},
+		Sharding:    ShardingConfig{KeyRanges: 4, ListConcurrency: 2},
+	})
+	summary, err := tfer.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(40), summary.ObjectsListed)
+	require.Equal(t, int64(40), summary.ObjectsTransferred)
+
+	entries, err := os.ReadDir(dstDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 40)
+}